@@ -0,0 +1,138 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// WithDefaultAccount scopes GetBalance to accountKey instead of the client-wide /balances/me aggregate
+// Change it later with SetDefaultAccount (REST client only)
+func WithDefaultAccount(accountKey string) Option {
+	return func(o *ClientOptions) {
+		o.DefaultAccount = accountKey
+	}
+}
+
+// SetDefaultAccount selects the account GetBalance reports, "" = all accounts (/balances/me)
+func (sbc *SaxoBrokerClient) SetDefaultAccount(accountKey string) {
+	sbc.defaultAccountMu.Lock()
+	defer sbc.defaultAccountMu.Unlock()
+	sbc.defaultAccount = accountKey
+}
+
+// DefaultAccount returns the account GetBalance is scoped to, "" = all accounts
+func (sbc *SaxoBrokerClient) DefaultAccount() string {
+	sbc.defaultAccountMu.RLock()
+	defer sbc.defaultAccountMu.RUnlock()
+	return sbc.defaultAccount
+}
+
+// GetBalanceForAccount retrieves the balance of one account
+// Endpoint: GET /port/v1/balances?AccountKey={accountKey}&ClientKey={clientKey}
+// clientKey "" uses the WithClientKey client, else the logged-in client's key. Not cached - per-account balances bypass CacheBalance
+func (sbc *SaxoBrokerClient) GetBalanceForAccount(ctx context.Context, clientKey, accountKey string) (*SaxoBalance, error) {
+	if accountKey == "" {
+		return nil, fmt.Errorf("account key is required")
+	}
+	if !sbc.authClient.IsAuthenticated() {
+		return nil, sbc.notAuthenticated()
+	}
+	if clientKey == "" {
+		var err error
+		if clientKey, err = sbc.scopedClientKey(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get ClientKey for account balance: %w", err)
+		}
+	}
+
+	query := url.Values{}
+	query.Set("AccountKey", accountKey)
+	query.Set("ClientKey", clientKey)
+	req, err := http.NewRequestWithContext(ctx, "GET", sbc.baseURL+"/port/v1/balances?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := sbc.doRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account balance: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, sbc.handleErrorResponse(resp)
+	}
+
+	var balance SaxoBalance
+	if err := json.NewDecoder(resp.Body).Decode(&balance); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	sbc.logger.Info("Retrieved account balance",
+		"function", "GetBalanceForAccount",
+		"account_key", accountKey,
+		"total_value", balance.TotalValue,
+		"currency", balance.Currency,
+		"margin_available", balance.MarginAvailableForTrading)
+	return &balance, nil
+}
+
+// AccountSummary is one account with its balance (GetAccountSummaries)
+type AccountSummary struct {
+	AccountInfo
+	Balance *Balance // nil when the account's balance request failed
+}
+
+// GetAccountSummaries returns every account of the client with its own balance
+// Accounts come from the cached GetAccounts, balances from GetBalanceForAccount (a few at a time).
+// Failed balances are joined into the error next to the summaries that have one
+func (sbc *SaxoBrokerClient) GetAccountSummaries(ctx context.Context) ([]AccountSummary, error) {
+	accounts, err := sbc.GetAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clientKey, err := sbc.scopedClientKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClientKey for account balances: %w", err)
+	}
+
+	summaries := make([]AccountSummary, len(accounts.Data))
+	errs := make([]error, len(accounts.Data))
+	sem := make(chan struct{}, uicChunkConcurrency)
+	var wg sync.WaitGroup
+	for i, account := range accounts.Data {
+		summaries[i].AccountInfo = account
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// Accounts of a sub-client carry their own ClientKey
+			key := account.ClientKey
+			if key == "" {
+				key = clientKey
+			}
+			balance, err := sbc.GetBalanceForAccount(ctx, key, account.AccountKey)
+			if err != nil {
+				errs[i] = fmt.Errorf("balance of account %s: %w", account.AccountKey, err)
+				return
+			}
+			summaries[i].Balance = (*Balance)(balance)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		sbc.logger.Error("Account balances incomplete",
+			"function", "GetAccountSummaries",
+			"accounts", len(summaries),
+			"error", err)
+		return summaries, err
+	}
+	return summaries, nil
+}
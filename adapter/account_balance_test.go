@@ -0,0 +1,119 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSaxoBrokerClient_GetBalanceForAccount(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	mockServer.SetResponse("GET", "/port/v1/users/me", http.StatusOK, SaxoClientInfo{ClientKey: "client1"})
+	mockServer.SetResponse("GET", "/port/v1/balances", http.StatusOK, SaxoBalance{Currency: "EUR", TotalValue: 2500})
+	mockServer.SetResponse("GET", "/port/v1/balances/me", http.StatusOK, SaxoBalance{Currency: "EUR", TotalValue: 10000})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithDefaultAccount("acc2"))
+
+	balance, err := client.GetBalance(context.Background())
+	if err != nil {
+		t.Fatalf("GetBalance failed: %v", err)
+	}
+	if balance.TotalValue != 2500 {
+		t.Errorf("Expected the default account's balance 2500, got %v", balance.TotalValue)
+	}
+	requests := mockServer.AssertRequested(t, "GET", "/port/v1/balances", 1)
+	if len(requests) == 1 {
+		query, _ := url.ParseQuery(requests[0].Query)
+		if query.Get("AccountKey") != "acc2" || query.Get("ClientKey") != "client1" {
+			t.Errorf("Unexpected balance query %q", requests[0].Query)
+		}
+	}
+
+	// Clearing the default account goes back to the client-wide aggregate
+	client.SetDefaultAccount("")
+	balance, err = client.GetBalance(context.Background())
+	if err != nil {
+		t.Fatalf("GetBalance failed: %v", err)
+	}
+	if balance.TotalValue != 10000 {
+		t.Errorf("Expected the aggregate balance 10000, got %v", balance.TotalValue)
+	}
+
+	if _, err := client.GetBalanceForAccount(context.Background(), "", ""); err == nil {
+		t.Error("Expected an error without account key")
+	}
+}
+
+func TestSaxoBrokerClient_GetAccountInfoAndSummaries(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	mockServer.SetResponse("GET", "/port/v1/users/me", http.StatusOK, SaxoClientInfo{ClientKey: "client1"})
+	mockServer.SetResponse("GET", "/port/v1/accounts/me", http.StatusOK, SaxoAccountResponse{Data: []SaxoAccountInfo{
+		{AccountKey: "acc1", AccountType: "Normal", Currency: "EUR", ClientKey: "client1"},
+		{AccountKey: "acc2", AccountType: "Normal", Currency: "USD"},
+		{AccountKey: "acc3", AccountType: "Normal", Currency: "CHF", ClientKey: "client1"},
+	}})
+	mockServer.SetHandler("GET", "/port/v1/balances", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("ClientKey") != "client1" {
+			http.Error(w, "unknown client", http.StatusBadRequest)
+			return
+		}
+		switch query.Get("AccountKey") {
+		case "acc1":
+			json.NewEncoder(w).Encode(SaxoBalance{Currency: "EUR", TotalValue: 1000, MarginUsedByCurrentPositions: 100})
+		case "acc2":
+			json.NewEncoder(w).Encode(SaxoBalance{Currency: "USD", TotalValue: 2000})
+		default:
+			http.Error(w, "unknown account", http.StatusNotFound)
+		}
+	})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+	ctx := context.Background()
+
+	info, err := client.GetAccountInfo(ctx)
+	if err != nil || info.AccountKey != "acc1" || info.Currency != "EUR" {
+		t.Fatalf("Expected the first account, got %+v (err %v)", info, err)
+	}
+	client.SetDefaultAccount("acc2")
+	if info, err = client.GetAccountInfo(ctx); err != nil || info.AccountKey != "acc2" {
+		t.Fatalf("Expected the default account, got %+v (err %v)", info, err)
+	}
+	client.SetDefaultAccount("missing")
+	if _, err = client.GetAccountInfo(ctx); err == nil {
+		t.Error("Expected an error for an unknown default account")
+	}
+	mockServer.AssertRequested(t, "GET", "/port/v1/accounts/me", 1)
+
+	// acc3's balance fails - the others are still returned
+	summaries, err := client.GetAccountSummaries(ctx)
+	if err == nil || !strings.Contains(err.Error(), "acc3") {
+		t.Errorf("Expected the acc3 balance error, got %v", err)
+	}
+	if len(summaries) != 3 {
+		t.Fatalf("Expected 3 summaries, got %d", len(summaries))
+	}
+	if s := summaries[0]; s.AccountKey != "acc1" || s.Balance == nil || s.Balance.TotalValue != 1000 || s.Balance.MarginUsedByCurrentPositions != 100 {
+		t.Errorf("Unexpected acc1 summary %+v", s)
+	}
+	if s := summaries[1]; s.Currency != "USD" || s.Balance == nil || s.Balance.TotalValue != 2000 {
+		t.Errorf("Unexpected acc2 summary %+v", s)
+	}
+	if summaries[2].Balance != nil {
+		t.Errorf("Expected no balance for acc3, got %+v", summaries[2].Balance)
+	}
+}
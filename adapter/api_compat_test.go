@@ -0,0 +1,219 @@
+package saxo
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var updateAPI = flag.Bool("update-api", false, "rewrite testdata/api/*.txt from the current exported API")
+
+// apiPackages are the packages covered by the v1 compatibility promise, relative to this directory
+var apiPackages = map[string]string{
+	"saxo":      ".",
+	"websocket": "websocket",
+}
+
+// TestAPICompatibility fails when an exported identifier of a v1 package was removed or its
+// signature changed, and when a new one is missing from the golden file so every addition is
+// recorded with the change that makes it. After an intended change run
+//
+//	go test ./adapter -run TestAPICompatibility -update-api
+func TestAPICompatibility(t *testing.T) {
+	for name, dir := range apiPackages {
+		t.Run(name, func(t *testing.T) {
+			current, err := exportedAPI(dir)
+			if err != nil {
+				t.Fatalf("Failed to read exported API: %v", err)
+			}
+			golden := filepath.Join("testdata", "api", name+".txt")
+			if *updateAPI {
+				if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, []byte(strings.Join(current, "\n")+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			data, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read %s (run with -update-api to create it): %v", golden, err)
+			}
+			have := make(map[string]bool, len(current))
+			for _, line := range current {
+				have[line] = true
+			}
+			want := make(map[string]bool)
+			for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+				want[line] = true
+				if !have[line] {
+					t.Errorf("Removed or changed: %s", line)
+				}
+			}
+			removed := t.Failed()
+			for _, line := range current {
+				if !want[line] {
+					t.Errorf("Added but not in %s: %s", golden, line)
+				}
+			}
+			if removed {
+				t.Log("Breaking changes need a new major version - keep the old identifier as a Deprecated wrapper instead")
+			}
+			if t.Failed() {
+				t.Log("Record intended changes with: go test ./adapter -run TestAPICompatibility -update-api")
+			}
+		})
+	}
+}
+
+// exportedAPI returns one sorted line per exported identifier, field and method of the package in dir
+// Parameter names are left out - renaming them is compatible
+func exportedAPI(dir string) ([]string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				lines = append(lines, declAPI(fset, decl)...)
+			}
+		}
+	}
+	sort.Strings(lines)
+	return lines, nil
+}
+
+func declAPI(fset *token.FileSet, decl ast.Decl) []string {
+	var lines []string
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if !d.Name.IsExported() {
+			return nil
+		}
+		if d.Recv == nil {
+			return []string{"func " + d.Name.Name + signature(fset, d.Type)}
+		}
+		recv := nodeString(fset, d.Recv.List[0].Type)
+		if !ast.IsExported(strings.TrimLeft(strings.SplitN(recv, "[", 2)[0], "*")) {
+			return nil
+		}
+		return []string{"method (" + recv + ") " + d.Name.Name + signature(fset, d.Type)}
+
+	case *ast.GenDecl:
+		var lastType string // Implicit repetition in const groups
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				if s.Name.IsExported() {
+					lines = append(lines, typeAPI(fset, s)...)
+				}
+			case *ast.ValueSpec:
+				if s.Type != nil {
+					lastType = nodeString(fset, s.Type)
+				} else if len(s.Values) > 0 {
+					lastType = ""
+				}
+				for _, name := range s.Names {
+					if name.IsExported() {
+						lines = append(lines, strings.TrimSpace(d.Tok.String()+" "+name.Name+" "+lastType))
+					}
+				}
+			}
+		}
+	}
+	return lines
+}
+
+func typeAPI(fset *token.FileSet, spec *ast.TypeSpec) []string {
+	name := spec.Name.Name
+	if spec.TypeParams != nil {
+		name += "[" + fieldTypes(fset, spec.TypeParams) + "]"
+	}
+	if spec.Assign.IsValid() {
+		return []string{"type " + name + " = " + nodeString(fset, spec.Type)}
+	}
+
+	switch typ := spec.Type.(type) {
+	case *ast.StructType:
+		lines := []string{"type " + name + " struct"}
+		for _, field := range typ.Fields.List {
+			fieldType := nodeString(fset, field.Type)
+			if len(field.Names) == 0 {
+				lines = append(lines, "field "+name+" embeds "+fieldType)
+			}
+			for _, fieldName := range field.Names {
+				if fieldName.IsExported() {
+					lines = append(lines, "field "+name+"."+fieldName.Name+" "+fieldType)
+				}
+			}
+		}
+		return lines
+	case *ast.InterfaceType:
+		lines := []string{"type " + name + " interface"}
+		for _, method := range typ.Methods.List {
+			if len(method.Names) == 0 {
+				lines = append(lines, "method "+name+" embeds "+nodeString(fset, method.Type))
+				continue
+			}
+			if funcType, ok := method.Type.(*ast.FuncType); ok && method.Names[0].IsExported() {
+				lines = append(lines, "method "+name+"."+method.Names[0].Name+signature(fset, funcType))
+			}
+		}
+		return lines
+	}
+	return []string{"type " + name + " " + nodeString(fset, spec.Type)}
+}
+
+// signature prints a function type without parameter names, e.g. "(context.Context, string) (*Order, error)"
+func signature(fset *token.FileSet, fn *ast.FuncType) string {
+	sig := ""
+	if fn.TypeParams != nil {
+		sig += "[" + fieldTypes(fset, fn.TypeParams) + "]"
+	}
+	sig += "(" + fieldTypes(fset, fn.Params) + ")"
+	switch {
+	case fn.Results == nil:
+	case len(fn.Results.List) == 1 && len(fn.Results.List[0].Names) <= 1:
+		sig += " " + fieldTypes(fset, fn.Results)
+	default:
+		sig += " (" + fieldTypes(fset, fn.Results) + ")"
+	}
+	return sig
+}
+
+// fieldTypes lists the types of a parameter list, once per parameter
+func fieldTypes(fset *token.FileSet, list *ast.FieldList) string {
+	var types []string
+	for _, field := range list.List {
+		typ := nodeString(fset, field.Type)
+		for range max(len(field.Names), 1) {
+			types = append(types, typ)
+		}
+	}
+	return strings.Join(types, ", ")
+}
+
+// nodeString prints node on one line
+func nodeString(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return "?"
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
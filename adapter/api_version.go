@@ -0,0 +1,9 @@
+package saxo
+
+// APIVersion is the semantic version of the exported API of this package and adapter/websocket
+//
+// The v1 promise covers BrokerClient, AuthClient, WebSocketClient, the generic types they use and
+// the factory functions. Minor versions only add identifiers; a renamed or changed function keeps
+// its old form as a Deprecated wrapper (see deprecated.go) until the next major version.
+// TestAPICompatibility fails when an exported signature changes unintentionally
+const APIVersion = "1.0.0"
@@ -0,0 +1,91 @@
+package saxo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Saxo asset types with explicit chart and order handling (see assetTypeHandlers)
+// Other asset types are passed through to Saxo unchanged
+const (
+	AssetTypeFxSpot          = "FxSpot"
+	AssetTypeContractFutures = "ContractFutures"
+	AssetTypeCfdOnIndex      = "CfdOnIndex"
+	AssetTypeCfdOnFutures    = "CfdOnFutures"
+	AssetTypeCfdOnStock      = "CfdOnStock"
+	AssetTypeStock           = "Stock"
+	AssetTypeEtf             = "Etf"
+	AssetTypeMutualFund      = "MutualFund"
+)
+
+// Order amount types for OrderRequest.OrderAmountType
+const (
+	AmountTypeQuantity   = "Quantity"   // Amount is a number of units/contracts
+	AmountTypeCashAmount = "CashAmount" // Amount is a value in the account currency
+)
+
+// assetTypeHandler describes how an asset type's chart data and orders differ
+type assetTypeHandler struct {
+	bidAsk      bool     // Chart data is quoted as OpenBid/OpenAsk... instead of traded Open/High/Low/Close
+	volume      bool     // Chart data carries traded Volume
+	amountTypes []string // Accepted order AmountType values, the first is sent by default
+}
+
+// assetTypeHandlers is keyed by the lower-cased Saxo asset type
+// FX and CFDs are priced by Saxo as market maker (bid/ask), futures and stocks trade on an exchange
+var assetTypeHandlers = map[string]assetTypeHandler{
+	"fxspot":          {bidAsk: true, amountTypes: []string{AmountTypeQuantity}},
+	"contractfutures": {volume: true, amountTypes: []string{AmountTypeQuantity}},
+	"cfdonindex":      {bidAsk: true, amountTypes: []string{AmountTypeQuantity}},
+	"cfdonfutures":    {bidAsk: true, amountTypes: []string{AmountTypeQuantity}},
+	"cfdonstock":      {bidAsk: true, amountTypes: []string{AmountTypeQuantity}},
+	"stock":           {volume: true, amountTypes: []string{AmountTypeQuantity, AmountTypeCashAmount}},
+	"etf":             {volume: true, amountTypes: []string{AmountTypeQuantity, AmountTypeCashAmount}},
+	"mutualfund":      {amountTypes: []string{AmountTypeQuantity, AmountTypeCashAmount}},
+}
+
+// assetTypeHandlerFor returns the handling of assetType, ok=false for asset types without one
+func assetTypeHandlerFor(assetType string) (assetTypeHandler, bool) {
+	h, ok := assetTypeHandlers[strings.ToLower(assetType)]
+	return h, ok
+}
+
+// ohlc converts a chart point to open/high/low/close/volume
+// Bid/ask quoted points use mid prices; a point without bid/ask fields falls back to the traded values
+func (h assetTypeHandler) ohlc(p SaxoChartData) (open, high, low, close, volume float64) {
+	if h.bidAsk && (p.CloseBid != 0 || p.CloseAsk != 0) {
+		open = (p.OpenBid + p.OpenAsk) / 2
+		high = (p.HighBid + p.HighAsk) / 2
+		low = (p.LowBid + p.LowAsk) / 2
+		close = (p.CloseBid + p.CloseAsk) / 2
+	} else {
+		open, high, low, close = p.Open, p.High, p.Low, p.Close
+	}
+	if h.volume {
+		volume = p.Volume
+	}
+	return open, high, low, close, volume
+}
+
+// sides returns the bid, ask and mid series of a chart point, ok=false when it has no bid/ask fields
+func (h assetTypeHandler) sides(p SaxoChartData) (bid, ask, mid OHLC, ok bool) {
+	if !h.bidAsk || (p.CloseBid == 0 && p.CloseAsk == 0) {
+		return OHLC{}, OHLC{}, OHLC{}, false
+	}
+	bid = OHLC{Open: p.OpenBid, High: p.HighBid, Low: p.LowBid, Close: p.CloseBid}
+	ask = OHLC{Open: p.OpenAsk, High: p.HighAsk, Low: p.LowAsk, Close: p.CloseAsk}
+	mid = OHLC{Open: (bid.Open + ask.Open) / 2, High: (bid.High + ask.High) / 2, Low: (bid.Low + ask.Low) / 2, Close: (bid.Close + ask.Close) / 2}
+	return bid, ask, mid, true
+}
+
+// amountType resolves an order's AmountType: "" = the asset type's default
+func (h assetTypeHandler) amountType(assetType, requested string) (string, error) {
+	if requested == "" {
+		return h.amountTypes[0], nil
+	}
+	if !slices.Contains(h.amountTypes, requested) {
+		return "", fmt.Errorf("AmountType %q not supported for %s (supported: %s)", requested, assetType, strings.Join(h.amountTypes, ", "))
+	}
+	return requested, nil
+}
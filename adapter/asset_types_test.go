@@ -0,0 +1,113 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaxoBrokerClient_HistoricalDataByAssetType(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+
+	// Chart points carry both shapes - the asset type decides which one is read
+	mockServer.SetResponse("GET", "/chart/v3/charts", http.StatusOK, SaxoPriceResponse{Data: []SaxoChartData{{
+		Time: "2026-01-05T00:00:00Z",
+		Open: 100, High: 110, Low: 90, Close: 105, Volume: 5000,
+		OpenBid: 19999, OpenAsk: 20001, HighBid: 20099, HighAsk: 20101,
+		LowBid: 19899, LowAsk: 19901, CloseBid: 20049, CloseAsk: 20051,
+	}}})
+
+	tests := []struct {
+		assetType string
+		uic       int
+		wantClose float64
+		wantVol   float64
+	}{
+		{AssetTypeCfdOnIndex, 4912, 20050, 0},
+		{AssetTypeCfdOnFutures, 4913, 20050, 0},
+		{AssetTypeCfdOnStock, 4914, 20050, 0},
+		{AssetTypeStock, 4915, 105, 5000},
+		{AssetTypeContractFutures, 4916, 105, 5000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.assetType, func(t *testing.T) {
+			instrument := Instrument{Ticker: tt.assetType, Uic: tt.uic, AssetType: tt.assetType}
+			data, err := client.GetHistoricalData(context.Background(), instrument, 1, time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("GetHistoricalData failed: %v", err)
+			}
+			if len(data) != 1 || data[0].Close != tt.wantClose || data[0].Volume != tt.wantVol {
+				t.Errorf("Expected close %v volume %v, got %+v", tt.wantClose, tt.wantVol, data)
+			}
+		})
+	}
+}
+
+func TestAssetTypeHandler_CfdWithoutBidAskFallsBack(t *testing.T) {
+	handler, ok := assetTypeHandlerFor("cfdonindex")
+	if !ok {
+		t.Fatal("Expected a handler for CfdOnIndex (case-insensitive)")
+	}
+	_, _, _, close, _ := handler.ohlc(SaxoChartData{Open: 1, High: 2, Low: 0.5, Close: 1.5})
+	if close != 1.5 {
+		t.Errorf("Expected traded close when bid/ask are missing, got %v", close)
+	}
+}
+
+func TestSaxoBrokerClient_PlaceOrderAmountType(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+	ctx := context.Background()
+
+	lastAmountType := func() interface{} {
+		t.Helper()
+		requests := mockServer.RequestsTo("POST", "/trade/v2/orders")
+		if len(requests) == 0 {
+			t.Fatal("No order sent")
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal([]byte(requests[len(requests)-1].Body), &body); err != nil {
+			t.Fatalf("Invalid body: %v", err)
+		}
+		return body["AmountType"]
+	}
+
+	// CFDs default to Quantity
+	order := OrderRequest{
+		Instrument: createTestInstrument("US500.I", 4913, AssetTypeCfdOnIndex),
+		AccountKey: "acc1", Side: "Buy", Size: 1, OrderType: "Market",
+	}
+	if _, err := client.PlaceOrder(ctx, order); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	if got := lastAmountType(); got != AmountTypeQuantity {
+		t.Errorf("Expected AmountType Quantity, got %v", got)
+	}
+
+	// CashAmount is rejected for CFDs before sending, accepted for stocks
+	order.OrderAmountType, order.CashAmount = AmountTypeCashAmount, 1000
+	if _, err := client.PlaceOrder(ctx, order); err == nil || !strings.Contains(err.Error(), "CashAmount") {
+		t.Errorf("Expected AmountType error for CfdOnIndex, got %v", err)
+	}
+	order.Instrument = createTestInstrument("AAPL:xnas", 211, AssetTypeStock)
+	if _, err := client.PlaceOrder(ctx, order); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	if got := lastAmountType(); got != AmountTypeCashAmount {
+		t.Errorf("Expected AmountType CashAmount, got %v", got)
+	}
+}
@@ -0,0 +1,101 @@
+package saxo
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrReauthenticationRequired means the refresh token has expired or was rejected: no call succeeds
+// until Login (or ExchangeCodeForToken) stores a new token
+var ErrReauthenticationRequired = errors.New("re-authentication required: refresh token expired")
+
+// WithOnAuthExpired calls fn once per refresh token when it expires or is rejected (auth client only)
+// fn runs on its own goroutine and receives a TokenAuthExpired event, e.g. to page an operator or
+// start a headless login. TokenEvents() subscribers get the same event
+func WithOnAuthExpired(fn func(TokenEvent)) Option {
+	return func(o *ClientOptions) {
+		o.OnAuthExpired = fn
+	}
+}
+
+// authExpiredState remembers the refresh token TokenAuthExpired was published for
+type authExpiredState struct {
+	expired      bool
+	refreshToken string
+}
+
+// RequiresReauthentication reports whether the refresh token has expired or was rejected, so that
+// only a new login helps
+func (sac *SaxoAuthClient) RequiresReauthentication() bool {
+	sac.expiredMu.Lock()
+	expired := sac.authExpired.expired
+	sac.expiredMu.Unlock()
+	if expired {
+		return true
+	}
+	sac.tokenMutex.RLock()
+	refreshExpiry := sac.currentToken.RefreshExpiry
+	sac.tokenMutex.RUnlock()
+	return !refreshExpiry.IsZero() && !sac.clock.Now().Before(refreshExpiry)
+}
+
+// reauthenticationRequired returns ErrReauthenticationRequired (wrapping cause) and publishes
+// TokenAuthExpired the first time it is reached for token's refresh token
+func (sac *SaxoAuthClient) reauthenticationRequired(token TokenInfo, cause error) error {
+	err := ErrReauthenticationRequired
+	if cause != nil {
+		err = fmt.Errorf("%w: %w", ErrReauthenticationRequired, cause)
+	}
+
+	sac.expiredMu.Lock()
+	first := !sac.authExpired.expired || sac.authExpired.refreshToken != token.RefreshToken
+	sac.authExpired = authExpiredState{expired: true, refreshToken: token.RefreshToken}
+	sac.expiredMu.Unlock()
+	if !first {
+		return err
+	}
+
+	sac.logger.Error("Refresh token expired - log in again",
+		"function", "reauthenticationRequired",
+		"provider", sac.provider,
+		"refresh_expiry", token.RefreshExpiry,
+		"error", err)
+	sac.publishTokenEvent(TokenAuthExpired, token, err)
+	if sac.onAuthExpired != nil {
+		event := TokenEvent{
+			Type:          TokenAuthExpired,
+			Expiry:        token.Expiry,
+			RefreshExpiry: token.RefreshExpiry,
+			Err:           err,
+			Timestamp:     sac.clock.Now(),
+		}
+		go sac.onAuthExpired(event)
+	}
+	return err
+}
+
+// resetAuthExpired forgets the expiry once a different refresh token is stored (a new login)
+func (sac *SaxoAuthClient) resetAuthExpired(token TokenInfo) {
+	sac.expiredMu.Lock()
+	if sac.authExpired.refreshToken != token.RefreshToken {
+		sac.authExpired = authExpiredState{}
+	}
+	sac.expiredMu.Unlock()
+}
+
+// isInvalidGrant reports whether the token endpoint rejected the refresh token itself
+func isInvalidGrant(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	return errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant"
+}
+
+// notAuthenticated is the error of broker calls made without a usable token, wrapping
+// ErrReauthenticationRequired when the auth client reports an expired refresh token
+func (sbc *SaxoBrokerClient) notAuthenticated() error {
+	if auth, ok := sbc.authClient.(interface{ RequiresReauthentication() bool }); ok && auth.RequiresReauthentication() {
+		return fmt.Errorf("not authenticated with broker: %w", ErrReauthenticationRequired)
+	}
+	return fmt.Errorf("not authenticated with broker")
+}
@@ -0,0 +1,110 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestSaxoAuthClient_AuthExpired(t *testing.T) {
+	var tokenCalls int32
+	server := newTestAuthServer(t, &tokenCalls, new(int32))
+	defer server.Close()
+
+	expired := make(chan TokenEvent, 4)
+	past := time.Now().Add(-time.Minute)
+	token := TokenInfo{
+		Provider:      "saxo",
+		AccessToken:   "expired_token",
+		RefreshToken:  "refresh_token",
+		Expiry:        past,
+		RefreshExpiry: past,
+	}
+	sac := newTestSaxoAuthClient(t, server.URL, token, WithOnAuthExpired(func(event TokenEvent) { expired <- event }))
+	if err := sac.tokenStorage.SaveToken(sac.getTokenFilename("saxo"), &token); err != nil {
+		t.Fatalf("SaveToken failed: %v", err)
+	}
+	events := sac.TokenEvents()
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, server.Client())
+	for i := 0; i < 2; i++ {
+		if err := sac.RefreshToken(ctx); !errors.Is(err, ErrReauthenticationRequired) {
+			t.Fatalf("Expected ErrReauthenticationRequired, got %v", err)
+		}
+	}
+	if atomic.LoadInt32(&tokenCalls) != 0 {
+		t.Errorf("Expected no refresh attempt with an expired refresh token, got %d", tokenCalls)
+	}
+
+	select {
+	case event := <-expired:
+		if event.Type != TokenAuthExpired || !errors.Is(event.Err, ErrReauthenticationRequired) {
+			t.Errorf("Unexpected callback event %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for OnAuthExpired")
+	}
+	if event := <-events; event.Type != TokenAuthExpired {
+		t.Errorf("Expected TokenAuthExpired on TokenEvents, got %s", event.Type)
+	}
+	select {
+	case event := <-expired:
+		t.Errorf("Expected OnAuthExpired once, got a second %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Broker calls fail with the sentinel instead of an opaque error
+	broker := NewSaxoBrokerClient(sac, server.URL, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if _, err := broker.GetAccounts(context.Background()); !errors.Is(err, ErrReauthenticationRequired) {
+		t.Errorf("Expected GetAccounts to fail with ErrReauthenticationRequired, got %v", err)
+	}
+
+	// A new login clears the state
+	if err := sac.storeToken(TokenInfo{Provider: "saxo", AccessToken: "new", RefreshToken: "new_refresh",
+		Expiry: time.Now().Add(20 * time.Minute), RefreshExpiry: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("storeToken failed: %v", err)
+	}
+	if sac.RequiresReauthentication() {
+		t.Error("Expected a new token to clear RequiresReauthentication")
+	}
+}
+
+func TestSaxoAuthClient_RefreshTokenRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant","error_description":"refresh token revoked"}`))
+	}))
+	defer server.Close()
+
+	fired := make(chan TokenEvent, 1)
+	sac := newTestSaxoAuthClient(t, server.URL, TokenInfo{
+		Provider:      "saxo",
+		AccessToken:   "expired_token",
+		RefreshToken:  "revoked",
+		Expiry:        time.Now().Add(-time.Minute),
+		RefreshExpiry: time.Now().Add(24 * time.Hour),
+	}, WithOnAuthExpired(func(event TokenEvent) { fired <- event }))
+
+	err := sac.RefreshToken(context.Background())
+	var retrieveErr *oauth2.RetrieveError
+	if !errors.Is(err, ErrReauthenticationRequired) || !errors.As(err, &retrieveErr) {
+		t.Fatalf("Expected ErrReauthenticationRequired wrapping the token error, got %v", err)
+	}
+	if !sac.RequiresReauthentication() || sac.IsAuthenticated() {
+		t.Error("Expected the rejected refresh token to require a login")
+	}
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for OnAuthExpired")
+	}
+}
@@ -0,0 +1,119 @@
+package saxo
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// BarCutoff decides where daily bars end when GetHistoricalData is called with a zero cutoffTime
+// The zero value anchors on the next UTC midnight, which splits futures sessions (CME closes 21:00 UTC)
+// and FX weeks (17:00 New York) in the middle
+type BarCutoff struct {
+	Offset   time.Duration // Daily close as time of day in UTC, e.g. 21*time.Hour for CME; 0 = midnight
+	Schedule bool          // Next session close from GetTradingSchedule; Offset when the schedule has none
+}
+
+// WithBarCutoff sets the daily bar cutoff of instruments without their own (REST client only)
+func WithBarCutoff(cutoff BarCutoff) Option {
+	return func(o *ClientOptions) {
+		o.BarCutoff = cutoff
+	}
+}
+
+// WithInstrumentBarCutoff sets the daily bar cutoff of one UIC, overriding WithBarCutoff (REST client only)
+func WithInstrumentBarCutoff(uic int, cutoff BarCutoff) Option {
+	return func(o *ClientOptions) {
+		if o.InstrumentBarCutoffs == nil {
+			o.InstrumentBarCutoffs = make(map[int]BarCutoff)
+		}
+		o.InstrumentBarCutoffs[uic] = cutoff
+	}
+}
+
+// NextBarCutoff returns the end of instrument's current daily bar - the cutoffTime GetHistoricalData
+// uses when given none. A failed schedule lookup falls back to the cutoff's Offset
+func (sbc *SaxoBrokerClient) NextBarCutoff(ctx context.Context, instrument Instrument) time.Time {
+	uic := instrument.Uic
+	if uic == 0 {
+		uic = instrument.Identifier
+	}
+	cutoff, ok := sbc.instrumentBarCutoffs[uic]
+	if !ok {
+		cutoff = sbc.barCutoff
+	}
+	now := sbc.clock.Now()
+
+	if cutoff.Schedule {
+		instrument.Identifier = uic
+		schedule, err := sbc.cachedTradingSchedule(ctx, instrument)
+		if err == nil {
+			if close, ok := schedule.NextClose(now); ok {
+				return close.UTC()
+			}
+		}
+		sbc.logger.Warn("No session close in trading schedule, using offset cutoff",
+			"function", "NextBarCutoff",
+			"ticker", instrument.Ticker,
+			"offset", cutoff.Offset,
+			"error", err)
+	}
+	return cutoff.next(now)
+}
+
+// next returns the first daily close at Offset (UTC) after now
+func (c BarCutoff) next(now time.Time) time.Time {
+	offset := c.Offset % (24 * time.Hour)
+	if offset < 0 {
+		offset += 24 * time.Hour
+	}
+	now = now.UTC()
+	at := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).Add(offset)
+	if !at.After(now) {
+		at = at.AddDate(0, 0, 1)
+	}
+	return at
+}
+
+// alignDailyBars stamps each daily bar with the first session close at or after its Time, using the
+// time of day of close, and merges bars ending at the same close (e.g. a Sunday FX open into Monday)
+// Bars already on the boundary, such as midnight bars with the zero cutoff, keep their Time
+func alignDailyBars(bars []HistoricalDataPoint, close time.Time) []HistoricalDataPoint {
+	close = close.UTC()
+	cutoff := BarCutoff{Offset: close.Sub(time.Date(close.Year(), close.Month(), close.Day(), 0, 0, 0, 0, time.UTC))}
+
+	aligned := make([]HistoricalDataPoint, 0, len(bars))
+	for _, bar := range bars {
+		bar.Time = cutoff.next(bar.Time.Add(-time.Nanosecond))
+		if n := len(aligned); n > 0 && aligned[n-1].Time.Equal(bar.Time) {
+			mergeDailyBar(&aligned[n-1], bar)
+			continue
+		}
+		aligned = append(aligned, bar)
+	}
+	return aligned
+}
+
+// mergeDailyBar folds the later bar next into bar
+func mergeDailyBar(bar *HistoricalDataPoint, next HistoricalDataPoint) {
+	bar.High = math.Max(bar.High, next.High)
+	bar.Low = math.Min(bar.Low, next.Low)
+	bar.Close = next.Close
+	bar.Volume += next.Volume
+	bar.Bid = mergeOHLC(bar.Bid, next.Bid)
+	bar.Ask = mergeOHLC(bar.Ask, next.Ask)
+	bar.Mid = mergeOHLC(bar.Mid, next.Mid)
+}
+
+// mergeOHLC folds next into a copy of ohlc; nil when either side is missing
+func mergeOHLC(ohlc, next *OHLC) *OHLC {
+	if ohlc == nil || next == nil {
+		return nil
+	}
+	return &OHLC{
+		Open:  ohlc.Open,
+		High:  math.Max(ohlc.High, next.High),
+		Low:   math.Min(ohlc.Low, next.Low),
+		Close: next.Close,
+	}
+}
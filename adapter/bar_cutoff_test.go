@@ -0,0 +1,138 @@
+package saxo
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestBarCutoff_Next(t *testing.T) {
+	now := time.Date(2026, 10, 16, 22, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		cutoff BarCutoff
+		want   time.Time
+	}{
+		{"UTC midnight", BarCutoff{}, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"CME close passed today", BarCutoff{Offset: 21 * time.Hour}, time.Date(2026, 10, 17, 21, 0, 0, 0, time.UTC)},
+		{"later today", BarCutoff{Offset: 23 * time.Hour}, time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)},
+		{"negative offset", BarCutoff{Offset: -time.Hour}, time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cutoff.next(now); !got.Equal(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSaxoBrokerClient_HistoricalDataBarCutoff(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SetResponse("GET", "/chart/v3/charts", http.StatusOK, SaxoPriceResponse{Data: []SaxoChartData{{
+		Time: "2026-10-15T00:00:00Z", Open: 100, High: 110, Low: 90, Close: 105,
+	}}})
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	sessionClose := time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)
+	mockServer.SetTradingScheduleResponse(4916, AssetTypeContractFutures, SaxoTradingSchedule{Phases: []SaxoTradingPhase{
+		{StartTime: now.Add(-4 * time.Hour), EndTime: sessionClose, State: "AutomatedTrading"},
+		{StartTime: sessionClose, EndTime: sessionClose.Add(time.Hour), State: "Closed"},
+	}})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger,
+		WithClock(mocktesting.NewFakeClock(now)),
+		WithBarCutoff(BarCutoff{Offset: 21 * time.Hour}),
+		WithInstrumentBarCutoff(4916, BarCutoff{Schedule: true}),
+		WithInstrumentBarCutoff(4917, BarCutoff{Schedule: true, Offset: 22 * time.Hour}))
+
+	tests := []struct {
+		name       string
+		instrument Instrument
+		want       time.Time
+	}{
+		{"client offset", Instrument{Ticker: "EURUSD", Uic: 21, AssetType: AssetTypeFxSpot}, time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)},
+		{"session close", Instrument{Ticker: "ES", Uic: 4916, AssetType: AssetTypeContractFutures}, sessionClose},
+		{"no schedule falls back to offset", Instrument{Ticker: "NQ", Uic: 4917, AssetType: AssetTypeContractFutures}, time.Date(2026, 10, 16, 22, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer.ClearRequests()
+			bars, err := client.GetHistoricalData(context.Background(), tt.instrument, 1, time.Time{})
+			if err != nil {
+				t.Fatalf("GetHistoricalData failed: %v", err)
+			}
+			// The midnight bar is stamped on its session close
+			wantBar := time.Date(2026, 10, 15, tt.want.Hour(), 0, 0, 0, time.UTC)
+			if len(bars) != 1 || !bars[0].Time.Equal(wantBar) {
+				t.Errorf("Expected one bar at %s, got %+v", wantBar.Format(time.RFC3339), bars)
+			}
+			requests := mockServer.AssertRequested(t, "GET", "/chart/v3/charts", 1)
+			if len(requests) != 1 {
+				return
+			}
+			query, _ := url.ParseQuery(requests[0].Query)
+			if got := query.Get("Time"); got != tt.want.Format(time.RFC3339) {
+				t.Errorf("Expected cutoff %s, got %s", tt.want.Format(time.RFC3339), got)
+			}
+		})
+	}
+
+	// An explicit cutoff is sent as given
+	mockServer.ClearRequests()
+	explicit := time.Date(2026, 10, 10, 21, 0, 0, 0, time.UTC)
+	bars, err := client.GetHistoricalData(context.Background(), Instrument{Ticker: "GBPUSD", Uic: 31, AssetType: AssetTypeFxSpot}, 1, explicit)
+	if err != nil {
+		t.Fatalf("GetHistoricalData failed: %v", err)
+	}
+	if len(bars) != 1 || !bars[0].Time.Equal(time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected bars of an explicit cutoff unchanged, got %+v", bars)
+	}
+	if requests := mockServer.AssertRequested(t, "GET", "/chart/v3/charts", 1); len(requests) == 1 {
+		if query, _ := url.ParseQuery(requests[0].Query); query.Get("Time") != explicit.Format(time.RFC3339) {
+			t.Errorf("Expected the explicit cutoff, got %s", query.Get("Time"))
+		}
+	}
+}
+
+func TestAlignDailyBars(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2026, 10, d, h, 0, 0, 0, time.UTC) }
+	bars := []HistoricalDataPoint{
+		{Time: day(9, 0), Open: 1.10, High: 1.12, Low: 1.09, Close: 1.11, Volume: 10},
+		// Sunday evening open and Monday share the Monday 21:00 close
+		{Time: day(11, 22), Open: 1.11, High: 1.13, Low: 1.10, Close: 1.12, Volume: 1, Mid: &OHLC{Open: 1.11, High: 1.13, Low: 1.10, Close: 1.12}},
+		{Time: day(12, 0), Open: 1.12, High: 1.15, Low: 1.08, Close: 1.14, Volume: 20, Mid: &OHLC{Open: 1.12, High: 1.15, Low: 1.08, Close: 1.14}},
+		{Time: day(13, 21), Open: 1.14, High: 1.16, Low: 1.13, Close: 1.15},
+	}
+
+	got := alignDailyBars(bars, day(16, 21))
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 bars, got %d: %+v", len(got), got)
+	}
+	for i, want := range []time.Time{day(9, 21), day(12, 21), day(13, 21)} {
+		if !got[i].Time.Equal(want) {
+			t.Errorf("Expected bar %d at %s, got %s", i, want.Format(time.RFC3339), got[i].Time.Format(time.RFC3339))
+		}
+	}
+	merged := got[1]
+	if merged.Open != 1.11 || merged.High != 1.15 || merged.Low != 1.08 || merged.Close != 1.14 || merged.Volume != 21 {
+		t.Errorf("Expected merged Monday bar, got %+v", merged)
+	}
+	if merged.Mid == nil || *merged.Mid != (OHLC{Open: 1.11, High: 1.15, Low: 1.08, Close: 1.14}) {
+		t.Errorf("Expected merged mid series, got %+v", merged.Mid)
+	}
+
+	// Midnight cutoff keeps midnight bars as they are
+	if got := alignDailyBars(bars[:1], day(17, 0)); !got[0].Time.Equal(day(9, 0)) {
+		t.Errorf("Expected midnight bar unchanged, got %s", got[0].Time.Format(time.RFC3339))
+	}
+}
@@ -0,0 +1,365 @@
+// Package brokerclienttest provides a conformance suite for saxo.BrokerClient implementations
+// Run it from your own tests to keep other brokers (Alpaca, IBKR, ...) behaviorally aligned with Saxo:
+//
+//	func TestMyBroker(t *testing.T) {
+//		brokerclienttest.Run(t, brokerclienttest.Harness{
+//			New:          func(t *testing.T) saxo.BrokerClient { return newSandboxClient(t) },
+//			Instrument:   saxo.Instrument{Ticker: "EURUSD", Identifier: 21, Uic: 21, AssetType: "FxSpot"},
+//			RestingPrice: 0.5,
+//		})
+//	}
+package brokerclienttest
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// DefaultSize is the order size used when Harness.Size is 0
+const DefaultSize = 1000
+
+// Harness describes the implementation under test
+type Harness struct {
+	// New returns an authenticated client on an account with no open orders
+	// Called once per scenario, so scenarios never see each other's orders
+	New func(t *testing.T) saxo.BrokerClient
+
+	// Instrument must be tradable - set Identifier and Uic, plus AssetType
+	Instrument saxo.Instrument
+
+	// RestingPrice is a limit buy price that must not fill (far below the market)
+	RestingPrice float64
+
+	Size int // Order size, default DefaultSize
+
+	// Skip maps scenario names to the reason the implementation does not support them
+	Skip map[string]string
+}
+
+// Scenario is one behavior every BrokerClient must show
+type Scenario struct {
+	Name string
+	Run  func(t *testing.T, h Harness, client saxo.BrokerClient)
+}
+
+// Scenarios returns the suite in the order Run executes it
+func Scenarios() []Scenario {
+	return []Scenario{
+		{Name: "Accounts", Run: testAccounts},
+		{Name: "Balance", Run: testBalance},
+		{Name: "NoOpenOrdersInitially", Run: testNoOpenOrders},
+		{Name: "LimitOrderLifecycle", Run: testLimitOrderLifecycle},
+		{Name: "OrderStatusWhileWorking", Run: testOrderStatus},
+		{Name: "ModifyPriceAndAmount", Run: testModifyPriceAndAmount},
+		{Name: "ModifyRejectsGoodTillDateWithoutExpiry", Run: testModifyRejectsGoodTillDate},
+		{Name: "FilterByInstrument", Run: testFilterByInstrument},
+		{Name: "CancelUnknownOrder", Run: testCancelUnknownOrder},
+		{Name: "RejectsInvalidSide", Run: testRejectsInvalidSide},
+		{Name: "RejectsNonPositiveSize", Run: testRejectsNonPositiveSize},
+		{Name: "RejectsMissingInstrument", Run: testRejectsMissingInstrument},
+		{Name: "CancelledContextPlacesNothing", Run: testCancelledContext},
+	}
+}
+
+// Run executes every scenario as a subtest against a fresh client from h.New
+func Run(t *testing.T, h Harness) {
+	t.Helper()
+	if h.New == nil {
+		t.Fatal("brokerclienttest: Harness.New is required")
+	}
+	if h.Size == 0 {
+		h.Size = DefaultSize
+	}
+
+	for _, scenario := range Scenarios() {
+		t.Run(scenario.Name, func(t *testing.T) {
+			if reason, ok := h.Skip[scenario.Name]; ok {
+				t.Skip(reason)
+			}
+			scenario.Run(t, h, h.New(t))
+		})
+	}
+}
+
+func testAccounts(t *testing.T, h Harness, client saxo.BrokerClient) {
+	accounts, err := client.GetAccounts(context.Background())
+	if err != nil {
+		t.Fatalf("GetAccounts failed: %v", err)
+	}
+	if accounts == nil || len(accounts.Data) == 0 {
+		t.Fatal("GetAccounts returned no accounts")
+	}
+	for i, account := range accounts.Data {
+		if account.AccountKey == "" {
+			t.Errorf("Account %d has no AccountKey", i)
+		}
+	}
+}
+
+func testBalance(t *testing.T, h Harness, client saxo.BrokerClient) {
+	balance, err := client.GetBalance(context.Background())
+	if err != nil {
+		t.Fatalf("GetBalance failed: %v", err)
+	}
+	if balance == nil {
+		t.Fatal("GetBalance returned nil without error")
+	}
+	if balance.Currency == "" {
+		t.Error("Balance has no Currency")
+	}
+}
+
+func testNoOpenOrders(t *testing.T, h Harness, client saxo.BrokerClient) {
+	orders, err := client.GetOpenOrders(context.Background())
+	if err != nil {
+		t.Fatalf("GetOpenOrders failed: %v", err)
+	}
+	if len(orders) != 0 {
+		t.Errorf("Expected no open orders on a fresh account, got %d", len(orders))
+	}
+}
+
+// testLimitOrderLifecycle: place -> listed as open -> cancel -> gone, and a second cancel fails
+func testLimitOrderLifecycle(t *testing.T, h Harness, client saxo.BrokerClient) {
+	ctx := context.Background()
+	accountKey := accountKey(t, client)
+
+	placed := placeRestingOrder(t, h, client, accountKey)
+	order := findOrder(t, client, placed.OrderID)
+	if order == nil {
+		t.Fatalf("Placed order %s is not listed by GetOpenOrders", placed.OrderID)
+	}
+	if order.Uic != uic(h.Instrument) {
+		t.Errorf("Open order Uic = %d, expected %d", order.Uic, uic(h.Instrument))
+	}
+	if !strings.EqualFold(order.BuySell, "Buy") {
+		t.Errorf("Open order BuySell = %q, expected Buy", order.BuySell)
+	}
+	if order.Amount != float64(h.Size) {
+		t.Errorf("Open order Amount = %v, expected %d", order.Amount, h.Size)
+	}
+
+	cancel := saxo.CancelOrderRequest{OrderID: placed.OrderID, AccountKey: accountKey}
+	if err := client.CancelOrder(ctx, cancel); err != nil {
+		t.Fatalf("CancelOrder failed: %v", err)
+	}
+	if findOrder(t, client, placed.OrderID) != nil {
+		t.Errorf("Cancelled order %s is still listed as open", placed.OrderID)
+	}
+	if err := client.CancelOrder(ctx, cancel); err == nil {
+		t.Error("Cancelling an already cancelled order should fail")
+	}
+}
+
+func testOrderStatus(t *testing.T, h Harness, client saxo.BrokerClient) {
+	placed := placeRestingOrder(t, h, client, accountKey(t, client))
+
+	status, err := client.GetOrderStatus(context.Background(), placed.OrderID)
+	if err != nil {
+		t.Fatalf("GetOrderStatus failed: %v", err)
+	}
+	if status.OrderID != placed.OrderID {
+		t.Errorf("Status OrderID = %q, expected %q", status.OrderID, placed.OrderID)
+	}
+	if status.Status != "Working" {
+		t.Errorf("Resting limit order status = %q, expected Working", status.Status)
+	}
+	if status.Size != h.Size {
+		t.Errorf("Status Size = %d, expected %d", status.Size, h.Size)
+	}
+}
+
+// testModifyPriceAndAmount moves a resting order further from the market and doubles it
+func testModifyPriceAndAmount(t *testing.T, h Harness, client saxo.BrokerClient) {
+	accountKey := accountKey(t, client)
+	placed := placeRestingOrder(t, h, client, accountKey)
+
+	newPrice := h.RestingPrice * 0.9
+	req := modification(h, accountKey, placed.OrderID)
+	req.OrderPrice = strconv.FormatFloat(newPrice, 'f', -1, 64)
+	req.Amount = float64(2 * h.Size)
+	req.OrderDuration = saxo.GoodTillDate(time.Now().AddDate(0, 1, 0))
+	if _, err := client.ModifyOrder(context.Background(), req); err != nil {
+		t.Fatalf("ModifyOrder failed: %v", err)
+	}
+
+	order := findOrder(t, client, placed.OrderID)
+	if order == nil {
+		t.Fatalf("Modified order %s is not listed by GetOpenOrders", placed.OrderID)
+	}
+	if order.Amount != float64(2*h.Size) {
+		t.Errorf("Modified order Amount = %v, expected %d", order.Amount, 2*h.Size)
+	}
+	if math.Abs(order.Price-newPrice) > 1e-9 {
+		t.Errorf("Modified order Price = %v, expected %v", order.Price, newPrice)
+	}
+}
+
+func testModifyRejectsGoodTillDate(t *testing.T, h Harness, client saxo.BrokerClient) {
+	accountKey := accountKey(t, client)
+	placed := placeRestingOrder(t, h, client, accountKey)
+
+	req := modification(h, accountKey, placed.OrderID)
+	req.OrderDuration = saxo.Duration(saxo.DurationGoodTillDate)
+	if _, err := client.ModifyOrder(context.Background(), req); err == nil {
+		t.Error("ModifyOrder accepted GoodTillDate without expiry")
+	}
+}
+
+func testFilterByInstrument(t *testing.T, h Harness, client saxo.BrokerClient) {
+	ctx := context.Background()
+	placed := placeRestingOrder(t, h, client, accountKey(t, client))
+
+	matching, err := client.GetOpenOrdersFiltered(ctx, saxo.OpenOrdersParams{Uic: uic(h.Instrument)})
+	if err != nil {
+		t.Fatalf("GetOpenOrdersFiltered failed: %v", err)
+	}
+	if len(matching) != 1 || matching[0].OrderID != placed.OrderID {
+		t.Errorf("Filter on the order's Uic returned %d orders, expected only %s", len(matching), placed.OrderID)
+	}
+
+	other, err := client.GetOpenOrdersFiltered(ctx, saxo.OpenOrdersParams{Uic: uic(h.Instrument) + 1})
+	if err != nil {
+		t.Fatalf("GetOpenOrdersFiltered failed: %v", err)
+	}
+	if len(other) != 0 {
+		t.Errorf("Filter on another Uic returned %d orders, expected none", len(other))
+	}
+}
+
+func testCancelUnknownOrder(t *testing.T, h Harness, client saxo.BrokerClient) {
+	err := client.CancelOrder(context.Background(), saxo.CancelOrderRequest{
+		OrderID:    "999999999",
+		AccountKey: accountKey(t, client),
+	})
+	if err == nil {
+		t.Error("Cancelling an unknown order should fail")
+	}
+}
+
+func testRejectsInvalidSide(t *testing.T, h Harness, client saxo.BrokerClient) {
+	req := restingOrder(h, accountKey(t, client))
+	req.Side = "Hold"
+	expectRejected(t, client, req)
+}
+
+func testRejectsNonPositiveSize(t *testing.T, h Harness, client saxo.BrokerClient) {
+	accountKey := accountKey(t, client)
+	for _, size := range []int{0, -h.Size} {
+		req := restingOrder(h, accountKey)
+		req.Size = size
+		expectRejected(t, client, req)
+	}
+}
+
+func testRejectsMissingInstrument(t *testing.T, h Harness, client saxo.BrokerClient) {
+	req := restingOrder(h, accountKey(t, client))
+	req.Instrument = saxo.Instrument{Ticker: h.Instrument.Ticker, AssetType: h.Instrument.AssetType}
+	expectRejected(t, client, req)
+}
+
+func testCancelledContext(t *testing.T, h Harness, client saxo.BrokerClient) {
+	req := restingOrder(h, accountKey(t, client))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.PlaceOrder(ctx, req); err == nil {
+		t.Error("PlaceOrder with a cancelled context should fail")
+	}
+	orders, err := client.GetOpenOrders(context.Background())
+	if err != nil {
+		t.Fatalf("GetOpenOrders failed: %v", err)
+	}
+	if len(orders) != 0 {
+		t.Errorf("Cancelled context still placed %d orders", len(orders))
+	}
+}
+
+// Helpers
+
+func accountKey(t *testing.T, client saxo.BrokerClient) string {
+	t.Helper()
+	accounts, err := client.GetAccounts(context.Background())
+	if err != nil {
+		t.Fatalf("GetAccounts failed: %v", err)
+	}
+	if accounts == nil || len(accounts.Data) == 0 {
+		t.Fatal("GetAccounts returned no accounts")
+	}
+	return accounts.Data[0].AccountKey
+}
+
+func restingOrder(h Harness, accountKey string) saxo.OrderRequest {
+	return saxo.OrderRequest{
+		Instrument: h.Instrument,
+		Side:       "Buy",
+		Size:       h.Size,
+		Price:      h.RestingPrice,
+		OrderType:  "Limit",
+		Duration:   saxo.Duration(saxo.DurationGoodTillCancel),
+		AccountKey: accountKey,
+	}
+}
+
+// modification is a no-op change of the resting order, for scenarios to adjust
+func modification(h Harness, accountKey, orderID string) saxo.OrderModificationRequest {
+	req := saxo.OrderModificationRequest{
+		OrderID:    orderID,
+		AccountKey: accountKey,
+		OrderType:  "Limit",
+		AssetType:  h.Instrument.AssetType,
+	}
+	req.OrderDuration = saxo.Duration(saxo.DurationGoodTillCancel)
+	return req
+}
+
+func placeRestingOrder(t *testing.T, h Harness, client saxo.BrokerClient, accountKey string) *saxo.OrderResponse {
+	t.Helper()
+	placed, err := client.PlaceOrder(context.Background(), restingOrder(h, accountKey))
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	if placed == nil || placed.OrderID == "" {
+		t.Fatalf("PlaceOrder returned no OrderID: %+v", placed)
+	}
+	return placed
+}
+
+func expectRejected(t *testing.T, client saxo.BrokerClient, req saxo.OrderRequest) {
+	t.Helper()
+	if placed, err := client.PlaceOrder(context.Background(), req); err == nil {
+		t.Errorf("PlaceOrder accepted invalid order (side %q, size %d), got %+v", req.Side, req.Size, placed)
+	}
+	if orders, err := client.GetOpenOrders(context.Background()); err == nil && len(orders) != 0 {
+		t.Errorf("Rejected order left %d open orders", len(orders))
+	}
+}
+
+// findOrder returns the open order with orderID, nil when it is not listed
+func findOrder(t *testing.T, client saxo.BrokerClient, orderID string) *saxo.LiveOrder {
+	t.Helper()
+	orders, err := client.GetOpenOrders(context.Background())
+	if err != nil {
+		t.Fatalf("GetOpenOrders failed: %v", err)
+	}
+	for i := range orders {
+		if orders[i].OrderID == orderID {
+			return &orders[i]
+		}
+	}
+	return nil
+}
+
+// uic returns the instrument's UIC - brokers enrich either Uic or Identifier
+func uic(instrument saxo.Instrument) int {
+	if instrument.Uic != 0 {
+		return instrument.Uic
+	}
+	return instrument.Identifier
+}
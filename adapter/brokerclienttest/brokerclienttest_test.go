@@ -0,0 +1,56 @@
+package brokerclienttest
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/paper"
+)
+
+var eurusd = saxo.Instrument{Ticker: "EURUSD", Identifier: 21, Uic: 21, AssetType: "FxSpot"}
+
+// fakeAuth is an always-authenticated AuthClient for the mock server
+type fakeAuth struct {
+	saxo.AuthClient
+}
+
+func (fakeAuth) IsAuthenticated() bool           { return true }
+func (fakeAuth) GetAccessToken() (string, error) { return "test_token_123", nil }
+func (fakeAuth) GetHTTPClient(ctx context.Context) (*http.Client, error) {
+	return http.DefaultClient, nil
+}
+
+func TestSaxoBrokerClient(t *testing.T) {
+	Run(t, Harness{
+		New: func(t *testing.T) saxo.BrokerClient {
+			mock := saxo.NewMockSaxoServer()
+			t.Cleanup(mock.Close)
+			mock.SimulateOrders("mock-account", 100000)
+
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			return saxo.NewSaxoBrokerClient(fakeAuth{}, mock.GetBaseURL(), logger)
+		},
+		Instrument:   eurusd,
+		RestingPrice: 0.5,
+	})
+}
+
+func TestPaperBrokerClient(t *testing.T) {
+	Run(t, Harness{
+		New: func(t *testing.T) saxo.BrokerClient {
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			broker := paper.NewPaperBrokerClient(paper.Config{}, logger)
+			t.Cleanup(func() { broker.Shutdown(context.Background()) })
+
+			// A quote far above RestingPrice, so limit buys rest instead of filling
+			broker.UpdatePrice(saxo.PriceUpdate{Uic: 21, Bid: 1.1000, Ask: 1.1002, Mid: 1.1001})
+			return broker
+		},
+		Instrument:   eurusd,
+		RestingPrice: 0.5,
+	})
+}
@@ -0,0 +1,377 @@
+// Package candles builds OHLC bars from the price stream
+// CandleAggregator consumes PriceUpdates and emits one Candle per instrument and interval when the
+// interval (or the trading session) ends, optionally with flat bars for intervals without prices
+package candles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// Source selects the price a candle is built from
+type Source string
+
+const (
+	SourceMid Source = "Mid"
+	SourceBid Source = "Bid"
+	SourceAsk Source = "Ask"
+)
+
+// DefaultBufferSize is the capacity of the Candles channel
+const DefaultBufferSize = 256
+
+// openStates are the trading phase states in which candles are built
+var openStates = []string{"AutomatedTrading", "Open"}
+
+// Candle is one OHLC bar of an instrument
+type Candle struct {
+	Uic      int
+	Interval time.Duration
+	Source   Source
+	Start    time.Time // Inclusive; after the interval boundary when the session opens inside the interval
+	End      time.Time // Exclusive; before the next boundary when the session closes inside the interval
+
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64 // Number of price updates - Saxo quotes carry no traded volume
+	Gap    bool    // No price update in the bar: Open, High, Low and Close repeat the previous close
+}
+
+// DataPoint converts the candle for code written against GetHistoricalData
+func (c Candle) DataPoint(ticker string) saxo.HistoricalDataPoint {
+	return saxo.HistoricalDataPoint{
+		Ticker: ticker,
+		Time:   c.Start,
+		Open:   c.Open,
+		High:   c.High,
+		Low:    c.Low,
+		Close:  c.Close,
+		Volume: c.Volume,
+	}
+}
+
+// Config configures a CandleAggregator
+type Config struct {
+	Intervals []time.Duration // Bar lengths, e.g. time.Second, time.Minute, 5*time.Minute (default 1m)
+	Source    Source          // Default SourceMid
+	FillGaps  bool            // Emit Gap candles for intervals without prices, within trading sessions only
+
+	// FlushEvery is how often Run closes bars whose interval has passed on the wall clock, so a
+	// quiet instrument still gets its bar on time. Default 1s; negative for replayed streams, whose
+	// bars then close when a later price arrives or on Advance
+	FlushEvery time.Duration
+	BufferSize int // Candles channel capacity, default DefaultBufferSize
+}
+
+// ScheduleSource provides trading schedules - saxo.BrokerClient implements it
+type ScheduleSource interface {
+	GetTradingSchedule(ctx context.Context, params saxo.TradingScheduleParams) (*saxo.TradingSchedule, error)
+}
+
+type seriesKey struct {
+	uic      int
+	interval time.Duration
+}
+
+// series is the bar being built for one instrument and interval
+type series struct {
+	bar       *Candle
+	lastEnd   time.Time // End of the last emitted candle
+	lastClose float64
+}
+
+// CandleAggregator turns PriceUpdates into Candles, safe for concurrent use
+// Instruments without a schedule (SetSchedule, LoadSchedules) are treated as always open
+type CandleAggregator struct {
+	config Config
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	series    map[seriesKey]*series
+	schedules map[int]*saxo.TradingSchedule
+
+	candles chan Candle
+	late    atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// NewCandleAggregator creates an aggregator without instruments - series start with the first price
+func NewCandleAggregator(config Config, logger *slog.Logger) *CandleAggregator {
+	if len(config.Intervals) == 0 {
+		config.Intervals = []time.Duration{time.Minute}
+	}
+	if config.Source == "" {
+		config.Source = SourceMid
+	}
+	if config.FlushEvery == 0 {
+		config.FlushEvery = time.Second
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultBufferSize
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &CandleAggregator{
+		config:    config,
+		logger:    logger,
+		series:    make(map[seriesKey]*series),
+		schedules: make(map[int]*saxo.TradingSchedule),
+		candles:   make(chan Candle, config.BufferSize),
+	}
+}
+
+// SetSchedule limits the candles of uic to the open phases of schedule, nil removes the limit
+// Bars are cut at session open and close. Saxo schedules cover a few days - refresh them daily
+func (ca *CandleAggregator) SetSchedule(uic int, schedule *saxo.TradingSchedule) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if schedule == nil {
+		delete(ca.schedules, uic)
+		return
+	}
+	ca.schedules[uic] = schedule
+}
+
+// LoadSchedules fetches and sets the trading schedule of every instrument
+// Instruments whose schedule fails keep their previous one; the errors are returned together
+func (ca *CandleAggregator) LoadSchedules(ctx context.Context, source ScheduleSource, instruments []saxo.Instrument) error {
+	var errs []error
+	for _, instrument := range instruments {
+		schedule, err := source.GetTradingSchedule(ctx, saxo.TradingScheduleParams{Uic: instrument.Identifier, AssetType: instrument.AssetType})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("trading schedule of %s: %w", instrument.Ticker, err))
+			continue
+		}
+		ca.SetSchedule(instrument.Identifier, schedule)
+	}
+	return errors.Join(errs...)
+}
+
+// Run applies prices until ctx is done or prices is closed, closing due bars every FlushEvery
+// Open bars are not emitted on return
+func (ca *CandleAggregator) Run(ctx context.Context, prices <-chan saxo.PriceUpdate) {
+	var flush <-chan time.Time
+	if ca.config.FlushEvery > 0 {
+		ticker := time.NewTicker(ca.config.FlushEvery)
+		defer ticker.Stop()
+		flush = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-flush:
+			ca.Advance(now)
+		case update, ok := <-prices:
+			if !ok {
+				return
+			}
+			ca.ApplyPrice(update)
+		}
+	}
+}
+
+// ApplyPrice adds a price to the bars of its instrument, emitting the bars (and gaps) it ends
+// The bar is chosen by update.Timestamp (now when zero). Prices outside the trading session are
+// ignored; prices older than the current bar are counted by Late and ignored
+func (ca *CandleAggregator) ApplyPrice(update saxo.PriceUpdate) {
+	price := ca.price(update)
+	if price <= 0 {
+		return
+	}
+	at := update.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	schedule := ca.schedules[update.Uic]
+	for _, interval := range ca.config.Intervals {
+		start, end, open := window(at, interval, schedule)
+		if !open {
+			continue
+		}
+		key := seriesKey{uic: update.Uic, interval: interval}
+		s, ok := ca.series[key]
+		if !ok {
+			s = &series{}
+			ca.series[key] = s
+		}
+
+		if s.bar != nil && !start.Equal(s.bar.Start) {
+			if start.Before(s.bar.Start) {
+				ca.late.Add(1)
+				continue
+			}
+			ca.emitLocked(s)
+		}
+		if s.bar == nil {
+			if at.Before(s.lastEnd) {
+				ca.late.Add(1)
+				continue
+			}
+			ca.fillGapsLocked(key, s, start, schedule)
+			s.bar = &Candle{Uic: update.Uic, Interval: interval, Source: ca.config.Source, Start: start, End: end,
+				Open: price, High: price, Low: price}
+		}
+		s.bar.High = max(s.bar.High, price)
+		s.bar.Low = min(s.bar.Low, price)
+		s.bar.Close = price
+		s.bar.Volume++
+	}
+}
+
+// Advance emits every bar that ended at or before now, and with FillGaps the gap bars up to now
+// Run calls it every FlushEvery; call it directly when replaying, with the replay time
+func (ca *CandleAggregator) Advance(now time.Time) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	for key, s := range ca.series {
+		if s.bar != nil && !now.Before(s.bar.End) {
+			ca.emitLocked(s)
+		}
+		if s.bar == nil {
+			ca.fillGapsLocked(key, s, now, ca.schedules[key.uic])
+		}
+	}
+}
+
+// Candles delivers finished bars; candles are dropped (counted by Dropped) when nobody reads
+func (ca *CandleAggregator) Candles() <-chan Candle {
+	return ca.candles
+}
+
+// Late returns the number of prices ignored because their bar was already emitted
+func (ca *CandleAggregator) Late() uint64 {
+	return ca.late.Load()
+}
+
+// Dropped returns the number of candles discarded because the Candles channel was full
+func (ca *CandleAggregator) Dropped() uint64 {
+	return ca.dropped.Load()
+}
+
+// price picks the configured side, falling back to the mid of bid and ask
+func (ca *CandleAggregator) price(update saxo.PriceUpdate) float64 {
+	switch ca.config.Source {
+	case SourceBid:
+		return update.Bid
+	case SourceAsk:
+		return update.Ask
+	}
+	if update.Mid > 0 {
+		return update.Mid
+	}
+	if update.Bid > 0 && update.Ask > 0 {
+		return (update.Bid + update.Ask) / 2
+	}
+	return 0
+}
+
+// emitLocked publishes the open bar of s
+func (ca *CandleAggregator) emitLocked(s *series) {
+	ca.publish(*s.bar)
+	s.lastEnd, s.lastClose = s.bar.End, s.bar.Close
+	s.bar = nil
+}
+
+// fillGapsLocked emits Gap candles for the open intervals between the last candle of s and until
+// Only bars ending at or before until are emitted; nothing is filled before the first candle
+func (ca *CandleAggregator) fillGapsLocked(key seriesKey, s *series, until time.Time, schedule *saxo.TradingSchedule) {
+	if !ca.config.FillGaps || s.lastEnd.IsZero() {
+		return
+	}
+	for next, ok := nextOpen(s.lastEnd, schedule); ok && next.Before(until); next, ok = nextOpen(s.lastEnd, schedule) {
+		start, end, _ := window(next, key.interval, schedule)
+		if end.After(until) {
+			return
+		}
+		ca.publish(Candle{Uic: key.uic, Interval: key.interval, Source: ca.config.Source, Start: start, End: end,
+			Open: s.lastClose, High: s.lastClose, Low: s.lastClose, Close: s.lastClose, Gap: true})
+		s.lastEnd = end
+	}
+}
+
+func (ca *CandleAggregator) publish(candle Candle) {
+	select {
+	case ca.candles <- candle:
+	default:
+		if ca.dropped.Add(1)%100 == 1 {
+			ca.logger.Warn("Candle channel full, dropping candles",
+				"function", "CandleAggregator.publish",
+				"uic", candle.Uic,
+				"interval", candle.Interval,
+				"dropped_total", ca.dropped.Load())
+		}
+	}
+}
+
+// window returns the bar of interval containing t, cut to the open phase of schedule containing t
+// open is false when schedule has no open phase at t
+func window(t time.Time, interval time.Duration, schedule *saxo.TradingSchedule) (start, end time.Time, open bool) {
+	start = t.Truncate(interval)
+	end = start.Add(interval)
+	if schedule == nil {
+		return start, end, true
+	}
+	for _, phase := range phases(schedule) {
+		if isOpen(phase) && !t.Before(phase.StartTime) && t.Before(phase.EndTime) {
+			if start.Before(phase.StartTime) {
+				start = phase.StartTime
+			}
+			if end.After(phase.EndTime) {
+				end = phase.EndTime
+			}
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// nextOpen returns t when schedule is open at t, else the start of the next open phase
+func nextOpen(t time.Time, schedule *saxo.TradingSchedule) (time.Time, bool) {
+	if schedule == nil {
+		return t, true
+	}
+	var next time.Time
+	for _, phase := range phases(schedule) {
+		if !isOpen(phase) || !t.Before(phase.EndTime) {
+			continue
+		}
+		if !t.Before(phase.StartTime) {
+			return t, true
+		}
+		if next.IsZero() || phase.StartTime.Before(next) {
+			next = phase.StartTime
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// phases returns Sessions, or Phases when Saxo filled those instead
+func phases(schedule *saxo.TradingSchedule) []saxo.SaxoTradingPhase {
+	if len(schedule.Sessions) > 0 {
+		return schedule.Sessions
+	}
+	return schedule.Phases
+}
+
+func isOpen(phase saxo.SaxoTradingPhase) bool {
+	for _, state := range openStates {
+		if phase.State == state {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package candles
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// drain returns the candles emitted so far
+func drain(ca *CandleAggregator) []Candle {
+	var candles []Candle
+	for {
+		select {
+		case candle := <-ca.Candles():
+			candles = append(candles, candle)
+		default:
+			return candles
+		}
+	}
+}
+
+func TestCandleAggregator_BarsAndGaps(t *testing.T) {
+	ca := NewCandleAggregator(Config{Intervals: []time.Duration{time.Minute}, FillGaps: true, FlushEvery: -1}, nil)
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	price := func(offset time.Duration, mid float64) {
+		ca.ApplyPrice(saxo.PriceUpdate{Uic: 21, Bid: mid - 0.0001, Ask: mid + 0.0001, Mid: mid, Timestamp: at.Add(offset)})
+	}
+
+	price(5*time.Second, 1.10)
+	price(20*time.Second, 1.12)
+	price(40*time.Second, 1.09)
+	price(59*time.Second, 1.11)
+	if candles := drain(ca); len(candles) != 0 {
+		t.Fatalf("Expected no candle before the minute ends, got %+v", candles)
+	}
+	price(3*time.Minute+time.Second, 1.13) // 09:01 and 09:02 have no prices
+	price(30*time.Second, 1.2)             // Late
+	ca.Advance(at.Add(4 * time.Minute))
+
+	candles := drain(ca)
+	if len(candles) != 4 {
+		t.Fatalf("Expected 09:00, two gaps and 09:03, got %+v", candles)
+	}
+	first := candles[0]
+	if !first.Start.Equal(at) || !first.End.Equal(at.Add(time.Minute)) || first.Open != 1.10 || first.High != 1.12 ||
+		first.Low != 1.09 || first.Close != 1.11 || first.Volume != 4 || first.Gap || first.Source != SourceMid {
+		t.Errorf("Unexpected 09:00 candle %+v", first)
+	}
+	for i, gap := range candles[1:3] {
+		if !gap.Gap || gap.Open != 1.11 || gap.Close != 1.11 || gap.Volume != 0 || !gap.Start.Equal(at.Add(time.Duration(i+1)*time.Minute)) {
+			t.Errorf("Unexpected gap candle %+v", gap)
+		}
+	}
+	if last := candles[3]; last.Open != 1.13 || last.Volume != 1 || !last.Start.Equal(at.Add(3*time.Minute)) {
+		t.Errorf("Unexpected 09:03 candle %+v", last)
+	}
+	if ca.Late() != 1 {
+		t.Errorf("Expected 1 late price, got %d", ca.Late())
+	}
+	if point := first.DataPoint("EURUSD"); point.Ticker != "EURUSD" || point.Close != 1.11 || !point.Time.Equal(at) {
+		t.Errorf("Unexpected data point %+v", point)
+	}
+}
+
+type staticSchedules map[int]*saxo.TradingSchedule
+
+func (s staticSchedules) GetTradingSchedule(ctx context.Context, params saxo.TradingScheduleParams) (*saxo.TradingSchedule, error) {
+	if schedule, ok := s[params.Uic]; ok {
+		return schedule, nil
+	}
+	return nil, errors.New("no schedule")
+}
+
+func TestCandleAggregator_SessionBoundaries(t *testing.T) {
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	// Open 09:30-16:00 and again from 16:15
+	schedule := &saxo.TradingSchedule{Phases: []saxo.SaxoTradingPhase{
+		{StartTime: day, EndTime: day.Add(9*time.Hour + 30*time.Minute), State: "Closed"},
+		{StartTime: day.Add(9*time.Hour + 30*time.Minute), EndTime: day.Add(16 * time.Hour), State: "AutomatedTrading"},
+		{StartTime: day.Add(16 * time.Hour), EndTime: day.Add(16*time.Hour + 15*time.Minute), State: "Closed"},
+		{StartTime: day.Add(16*time.Hour + 15*time.Minute), EndTime: day.Add(24 * time.Hour), State: "AutomatedTrading"},
+	}}
+	ca := NewCandleAggregator(Config{Intervals: []time.Duration{time.Hour}, Source: SourceBid, FillGaps: true, FlushEvery: -1}, nil)
+	err := ca.LoadSchedules(context.Background(), staticSchedules{211: schedule}, []saxo.Instrument{
+		{Ticker: "AAPL", Identifier: 211, AssetType: "Stock"}, {Ticker: "MSFT", Identifier: 212, AssetType: "Stock"},
+	})
+	if err == nil {
+		t.Error("Expected the error of MSFT")
+	}
+
+	ca.ApplyPrice(saxo.PriceUpdate{Uic: 211, Bid: 99, Ask: 101, Timestamp: day.Add(9 * time.Hour)}) // Pre-market, ignored
+	ca.ApplyPrice(saxo.PriceUpdate{Uic: 211, Bid: 100, Ask: 102, Timestamp: day.Add(9*time.Hour + 45*time.Minute)})
+	ca.ApplyPrice(saxo.PriceUpdate{Uic: 211, Bid: 104, Ask: 106, Timestamp: day.Add(15*time.Hour + 50*time.Minute)})
+	ca.Advance(day.Add(17 * time.Hour))
+
+	candles := drain(ca)
+	// 09:30-10:00, gaps 10:00..15:00, 15:00-16:00, then 16:15-17:00 as a gap after the break
+	if len(candles) != 8 {
+		t.Fatalf("Expected 8 candles, got %d: %+v", len(candles), candles)
+	}
+	if first := candles[0]; !first.Start.Equal(day.Add(9*time.Hour+30*time.Minute)) || !first.End.Equal(day.Add(10*time.Hour)) || first.Open != 100 {
+		t.Errorf("Expected the first bar cut at the session open, got %+v", first)
+	}
+	if closing := candles[6]; closing.Gap || closing.Close != 104 || !closing.End.Equal(day.Add(16*time.Hour)) {
+		t.Errorf("Expected the 15:00 bar with the last bid, got %+v", closing)
+	}
+	if reopen := candles[7]; !reopen.Gap || !reopen.Start.Equal(day.Add(16*time.Hour+15*time.Minute)) || !reopen.End.Equal(day.Add(17*time.Hour)) {
+		t.Errorf("Expected a gap bar from the reopening, got %+v", reopen)
+	}
+}
+
+func TestCandleAggregator_Run(t *testing.T) {
+	ca := NewCandleAggregator(Config{Intervals: []time.Duration{time.Second, time.Minute}, FlushEvery: 10 * time.Millisecond}, nil)
+	prices := make(chan saxo.PriceUpdate)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ca.Run(ctx, prices)
+
+	prices <- saxo.PriceUpdate{Uic: 21, Bid: 1.1, Ask: 1.1002, Timestamp: time.Now()}
+	select {
+	case candle := <-ca.Candles():
+		if candle.Interval != time.Second || candle.Close != 1.1001 {
+			t.Errorf("Expected the 1s candle from the mid, got %+v", candle)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected the 1s candle to be flushed without further prices")
+	}
+}
@@ -0,0 +1,161 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Cash transfer types in CashTransfer.Type
+const (
+	CashTransferDeposit    = "Deposit"
+	CashTransferWithdrawal = "Withdrawal"
+	CashTransferInternal   = "Transfer" // Between the client's own accounts
+)
+
+// CashTransfer is a funding event - money moved into or out of an account, not trading P/L
+type CashTransfer struct {
+	ID          string    `json:"id"`
+	AccountID   string    `json:"account_id"`
+	Date        time.Time `json:"date"`
+	ValueDate   time.Time `json:"value_date"`
+	Amount      float64   `json:"amount"` // Positive = into the account, negative = out of it
+	Currency    string    `json:"currency"`
+	Type        string    `json:"type"` // CashTransferDeposit, CashTransferWithdrawal or CashTransferInternal
+	Description string    `json:"description"`
+}
+
+// NetCashTransfers sums transfers per currency, e.g. to subtract funding from an equity curve
+func NetCashTransfers(transfers []CashTransfer) map[string]float64 {
+	net := make(map[string]float64)
+	for _, transfer := range transfers {
+		net[transfer.Currency] += transfer.Amount
+	}
+	return net
+}
+
+// saxoBooking is one entry of the bookings report
+type saxoBooking struct {
+	BookingID        string  `json:"BookingId"`
+	AccountID        string  `json:"AccountId"`
+	Amount           float64 `json:"Amount"`
+	BkAmountTypeName string  `json:"BkAmountTypeName"` // e.g. "Cash deposit", "Cash withdrawal", "Commission"
+	CurrencyCode     string  `json:"CurrencyCode"`
+	Date             string  `json:"Date"`
+	ValueDate        string  `json:"ValueDate"`
+	Text             string  `json:"Text"`
+}
+
+// cashTransferType classifies a booking, "" for anything that is not a funding event
+func cashTransferType(booking saxoBooking) string {
+	name := strings.ToLower(booking.BkAmountTypeName)
+	switch {
+	case strings.Contains(name, "deposit"):
+		return CashTransferDeposit
+	case strings.Contains(name, "withdraw"):
+		return CashTransferWithdrawal
+	case strings.Contains(name, "transfer"):
+		return CashTransferInternal
+	}
+	return ""
+}
+
+// GetCashTransfers returns deposits, withdrawals and internal transfers of accountKey between from and to (inclusive dates)
+// Built from the bookings report, which also holds trading cash flows; only funding events are returned
+// Reference: Saxo API GET /cs/v1/reports/bookings/{ClientKey}
+func (sbc *SaxoBrokerClient) GetCashTransfers(ctx context.Context, accountKey string, from, to time.Time) ([]CashTransfer, error) {
+	if !sbc.authClient.IsAuthenticated() {
+		return nil, sbc.notAuthenticated()
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid date range: %s is before %s", to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+
+	clientKey, err := sbc.scopedClientKey(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClientKey for cash transfers: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("FromDate", from.Format("2006-01-02"))
+	query.Set("ToDate", to.Format("2006-01-02"))
+	if accountKey != "" {
+		query.Set("AccountKey", accountKey)
+	}
+	pageURL := fmt.Sprintf("%s/cs/v1/reports/bookings/%s?%s", sbc.baseURL, url.PathEscape(clientKey), query.Encode())
+
+	var transfers []CashTransfer
+	for pageURL != "" {
+		var page struct {
+			Next string        `json:"__next"`
+			Data []saxoBooking `json:"Data"`
+		}
+		if err := sbc.getCashTransferPage(ctx, pageURL, &page); err != nil {
+			return nil, err
+		}
+		for _, booking := range page.Data {
+			transferType := cashTransferType(booking)
+			if transferType == "" {
+				continue
+			}
+			transfer := CashTransfer{
+				ID:          booking.BookingID,
+				AccountID:   booking.AccountID,
+				Amount:      booking.Amount,
+				Currency:    booking.CurrencyCode,
+				Type:        transferType,
+				Description: booking.Text,
+			}
+			transfer.Date, _ = time.Parse("2006-01-02", booking.Date)
+			transfer.ValueDate, _ = time.Parse("2006-01-02", booking.ValueDate)
+			transfers = append(transfers, transfer)
+		}
+		if pageURL, err = sbc.nextPageURL(pageURL, page.Next); err != nil {
+			return nil, fmt.Errorf("cash transfers: %w", err)
+		}
+	}
+
+	sbc.logger.Info("Retrieved cash transfers",
+		"function", "GetCashTransfers",
+		"account_key", accountKey,
+		"from", from.Format("2006-01-02"),
+		"to", to.Format("2006-01-02"),
+		"count", len(transfers))
+	return transfers, nil
+}
+
+func (sbc *SaxoBrokerClient) getCashTransferPage(ctx context.Context, pageURL string, page interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := sbc.doRequest(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to get cash transfers: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return sbc.handleErrorResponse(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(page); err != nil {
+		return fmt.Errorf("failed to decode cash transfers response: %w", err)
+	}
+	return nil
+}
+
+// nextPageURL validates a Saxo __next link; "" when there are no more pages
+// __next is absolute - it is only followed on our own gateway so the token never leaves it
+func (sbc *SaxoBrokerClient) nextPageURL(current, next string) (string, error) {
+	if next == "" || next == current {
+		return "", nil
+	}
+	if !strings.HasPrefix(next, sbc.baseURL+"/") {
+		return "", fmt.Errorf("refusing __next outside %s: %s", sbc.baseURL, next)
+	}
+	return next, nil
+}
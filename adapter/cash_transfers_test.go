@@ -0,0 +1,74 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSaxoBrokerClient_GetCashTransfers(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	mockServer.SetResponse("GET", "/port/v1/users/me", http.StatusOK, SaxoClientInfo{ClientKey: "client1"})
+	mockServer.SetHandler("GET", "/cs/v1/reports/bookings/{clientKey}", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"__next": mockServer.GetBaseURL() + "/cs/v1/reports/bookings/client1?page=2",
+				"Data": []map[string]interface{}{
+					{"BookingId": "1", "AccountId": "acc", "Amount": 10000, "BkAmountTypeName": "Cash deposit", "CurrencyCode": "EUR", "Date": "2026-01-05", "ValueDate": "2026-01-05"},
+					{"BookingId": "2", "AccountId": "acc", "Amount": -3.5, "BkAmountTypeName": "Commission", "CurrencyCode": "EUR", "Date": "2026-01-06", "ValueDate": "2026-01-08"},
+				},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"Data": []map[string]interface{}{
+				{"BookingId": "3", "AccountId": "acc", "Amount": -2500, "BkAmountTypeName": "Cash withdrawal", "CurrencyCode": "EUR", "Date": "2026-02-01", "ValueDate": "2026-02-02", "Text": "To bank"},
+				{"BookingId": "4", "AccountId": "acc", "Amount": 120, "BkAmountTypeName": "Share amount", "CurrencyCode": "USD", "Date": "2026-02-03", "ValueDate": "2026-02-05"},
+			},
+		})
+	})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	transfers, err := client.GetCashTransfers(context.Background(), "acc-key", from, to)
+	if err != nil {
+		t.Fatalf("GetCashTransfers: %v", err)
+	}
+	if len(transfers) != 2 {
+		t.Fatalf("Expected deposit and withdrawal only, got %+v", transfers)
+	}
+	if transfers[0].Type != CashTransferDeposit || transfers[0].Amount != 10000 || !transfers[0].Date.Equal(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected deposit: %+v", transfers[0])
+	}
+	if transfers[1].Type != CashTransferWithdrawal || transfers[1].Amount != -2500 || transfers[1].Description != "To bank" {
+		t.Errorf("Unexpected withdrawal: %+v", transfers[1])
+	}
+	if net := NetCashTransfers(transfers); net["EUR"] != 7500 {
+		t.Errorf("Expected net EUR 7500, got %v", net)
+	}
+
+	for _, req := range mockServer.GetRequests() {
+		query, _ := url.ParseQuery(req.Query)
+		if req.Path != "/cs/v1/reports/bookings/client1" || query.Get("page") != "" {
+			continue
+		}
+		if query.Get("AccountKey") != "acc-key" || query.Get("FromDate") != "2026-01-01" || query.Get("ToDate") != "2026-03-31" {
+			t.Errorf("Unexpected query: %v", req.Query)
+		}
+	}
+
+	if _, err := client.GetCashTransfers(context.Background(), "acc-key", to, from); err == nil {
+		t.Error("Expected error for reversed date range")
+	}
+}
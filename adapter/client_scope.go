@@ -0,0 +1,162 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// WithClientKey scopes the client's portfolio queries to clientKey instead of the token owner
+// (REST client only). For IB and white-label partners whose login manages other clients - Saxo
+// answers 403 unless the app and the login have the privileges for that client.
+// Orders are placed on the AccountKey of the request, which must belong to clientKey
+func WithClientKey(clientKey string) Option {
+	return func(o *ClientOptions) {
+		o.ClientKey = clientKey
+	}
+}
+
+// ClientKey returns the client the portfolio queries are scoped to, "" = the token owner
+func (sbc *SaxoBrokerClient) ClientKey() string {
+	return sbc.clientKey
+}
+
+// scopedClientKey returns the WithClientKey client, else the token owner's ClientKey
+func (sbc *SaxoBrokerClient) scopedClientKey(ctx context.Context) (string, error) {
+	if sbc.clientKey != "" {
+		return sbc.clientKey, nil
+	}
+	clientInfo, err := sbc.GetClientInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	return clientInfo.ClientKey, nil
+}
+
+// portfolioURL builds a /port/v1/{resource} URL: /{resource}/me for the token owner,
+// /{resource}?ClientKey= when scoped with WithClientKey
+func (sbc *SaxoBrokerClient) portfolioURL(resource string, query url.Values) string {
+	if query == nil {
+		query = url.Values{}
+	}
+	endpoint := sbc.baseURL + "/port/v1/" + resource + "/me"
+	if sbc.clientKey != "" {
+		endpoint = sbc.baseURL + "/port/v1/" + resource
+		query.Set("ClientKey", sbc.clientKey)
+	}
+	if len(query) == 0 {
+		return endpoint
+	}
+	return endpoint + "?" + query.Encode()
+}
+
+// GetClients lists the clients under ownerKey, "" = the token owner's client (partner logins)
+// Endpoint: GET /port/v1/clients?OwnerKey={ownerKey}, following __next
+func (sbc *SaxoBrokerClient) GetClients(ctx context.Context, ownerKey string) ([]SaxoClientDetails, error) {
+	if !sbc.authClient.IsAuthenticated() {
+		return nil, sbc.notAuthenticated()
+	}
+	if ownerKey == "" {
+		clientInfo, err := sbc.GetClientInfo(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ClientKey for clients: %w", err)
+		}
+		ownerKey = clientInfo.ClientKey
+	}
+
+	query := url.Values{}
+	query.Set("OwnerKey", ownerKey)
+	pageURL := sbc.baseURL + "/port/v1/clients?" + query.Encode()
+
+	var clients []SaxoClientDetails
+	for pageURL != "" {
+		var page struct {
+			Next string              `json:"__next"`
+			Data []SaxoClientDetails `json:"Data"`
+		}
+		if err := sbc.getPortfolioJSON(ctx, pageURL, "clients", &page); err != nil {
+			return nil, err
+		}
+		clients = append(clients, page.Data...)
+
+		var err error
+		if pageURL, err = sbc.nextPageURL(pageURL, page.Next); err != nil {
+			return nil, fmt.Errorf("clients: %w", err)
+		}
+	}
+
+	sbc.logger.Info("Retrieved clients",
+		"function", "GetClients",
+		"owner_key", ownerKey,
+		"count", len(clients))
+	return clients, nil
+}
+
+// GetClientDetailsFor returns the client record of clientKey (not cached)
+// Endpoint: GET /port/v1/clients/details?ClientKey={clientKey}
+func (sbc *SaxoBrokerClient) GetClientDetailsFor(ctx context.Context, clientKey string) (*SaxoClientDetails, error) {
+	if clientKey == "" {
+		return nil, fmt.Errorf("client key is required")
+	}
+	query := url.Values{}
+	query.Set("ClientKey", clientKey)
+
+	var details SaxoClientDetails
+	if err := sbc.getPortfolioJSON(ctx, sbc.baseURL+"/port/v1/clients/details?"+query.Encode(), "client details", &details); err != nil {
+		return nil, err
+	}
+	return &details, nil
+}
+
+// GetAccountGroups lists the account groups of clientKey, "" = the scoped client (WithClientKey)
+// or the token owner
+// Endpoint: GET /port/v1/accountgroups?ClientKey={clientKey}
+func (sbc *SaxoBrokerClient) GetAccountGroups(ctx context.Context, clientKey string) ([]SaxoAccountGroup, error) {
+	if !sbc.authClient.IsAuthenticated() {
+		return nil, sbc.notAuthenticated()
+	}
+	if clientKey == "" {
+		var err error
+		if clientKey, err = sbc.scopedClientKey(ctx); err != nil {
+			return nil, fmt.Errorf("failed to get ClientKey for account groups: %w", err)
+		}
+	}
+
+	query := url.Values{}
+	query.Set("ClientKey", clientKey)
+	var response struct {
+		Data []SaxoAccountGroup `json:"Data"`
+	}
+	if err := sbc.getPortfolioJSON(ctx, sbc.baseURL+"/port/v1/accountgroups?"+query.Encode(), "account groups", &response); err != nil {
+		return nil, err
+	}
+
+	sbc.logger.Info("Retrieved account groups",
+		"function", "GetAccountGroups",
+		"client_key", clientKey,
+		"count", len(response.Data))
+	return response.Data, nil
+}
+
+// getPortfolioJSON GETs requestURL and decodes the response into target; what names it in errors
+func (sbc *SaxoBrokerClient) getPortfolioJSON(ctx context.Context, requestURL, what string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := sbc.doRequest(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to get %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return sbc.handleErrorResponse(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", what, err)
+	}
+	return nil
+}
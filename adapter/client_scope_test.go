@@ -0,0 +1,116 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+)
+
+func TestSaxoBrokerClient_GetClients(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	mockServer.SetResponse("GET", "/port/v1/users/me", http.StatusOK, SaxoClientInfo{ClientKey: "partner"})
+	mockServer.SetHandler("GET", "/port/v1/clients", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("$skip") == "" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"__next": mockServer.GetBaseURL() + "/port/v1/clients?OwnerKey=partner&$skip=1",
+				"Data":   []map[string]interface{}{{"ClientKey": "c1", "Name": "Client One"}},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"Data": []map[string]interface{}{{"ClientKey": "c2", "Name": "Client Two"}},
+		})
+	})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+
+	clients, err := client.GetClients(context.Background(), "")
+	if err != nil {
+		t.Fatalf("GetClients: %v", err)
+	}
+	if len(clients) != 2 || clients[0].ClientKey != "c1" || clients[1].ClientKey != "c2" {
+		t.Fatalf("Expected both pages, got %+v", clients)
+	}
+	requests := mockServer.AssertRequested(t, "GET", "/port/v1/clients", 2)
+	if len(requests) > 0 {
+		query, _ := url.ParseQuery(requests[0].Query)
+		if query.Get("OwnerKey") != "partner" {
+			t.Errorf("Expected the token owner as OwnerKey, got %q", requests[0].Query)
+		}
+	}
+}
+
+func TestSaxoBrokerClient_GetAccountGroups(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	mockServer.SetResponse("GET", "/port/v1/accountgroups", http.StatusOK, map[string]interface{}{
+		"Data": []SaxoAccountGroup{{AccountGroupKey: "g1", AccountGroupName: "Hedge"}},
+	})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithClientKey("c1"))
+
+	groups, err := client.GetAccountGroups(context.Background(), "")
+	if err != nil {
+		t.Fatalf("GetAccountGroups: %v", err)
+	}
+	if len(groups) != 1 || groups[0].AccountGroupName != "Hedge" {
+		t.Fatalf("Unexpected account groups %+v", groups)
+	}
+	// The scoped client needs no /users/me lookup
+	mockServer.AssertRequested(t, "GET", "/port/v1/users/me", 0)
+	requests := mockServer.AssertRequested(t, "GET", "/port/v1/accountgroups", 1)
+	if len(requests) == 1 {
+		query, _ := url.ParseQuery(requests[0].Query)
+		if query.Get("ClientKey") != "c1" {
+			t.Errorf("Expected ClientKey c1, got %q", requests[0].Query)
+		}
+	}
+}
+
+func TestSaxoBrokerClient_WithClientKeyScopesPortfolio(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	mockServer.SetResponse("GET", "/port/v1/positions", http.StatusOK, map[string]interface{}{"Data": []interface{}{}})
+	mockServer.SetResponse("GET", "/port/v1/balances", http.StatusOK, SaxoBalance{Currency: "EUR", TotalValue: 500})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithClientKey("c1"))
+
+	if client.ClientKey() != "c1" {
+		t.Fatalf("Expected ClientKey c1, got %q", client.ClientKey())
+	}
+	if _, err := client.GetOpenPositions(context.Background()); err != nil {
+		t.Fatalf("GetOpenPositions: %v", err)
+	}
+	balance, err := client.GetBalance(context.Background())
+	if err != nil {
+		t.Fatalf("GetBalance: %v", err)
+	}
+	if balance.TotalValue != 500 {
+		t.Errorf("Expected the scoped client's balance, got %v", balance.TotalValue)
+	}
+
+	mockServer.AssertRequested(t, "GET", "/port/v1/positions/me", 0)
+	mockServer.AssertRequested(t, "GET", "/port/v1/balances/me", 0)
+	for _, path := range []string{"/port/v1/positions", "/port/v1/balances"} {
+		for _, req := range mockServer.AssertRequested(t, "GET", path, 1) {
+			query, _ := url.ParseQuery(req.Query)
+			if query.Get("ClientKey") != "c1" {
+				t.Errorf("%s: expected ClientKey c1, got %q", path, req.Query)
+			}
+		}
+	}
+}
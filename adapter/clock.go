@@ -0,0 +1,27 @@
+package saxo
+
+import "time"
+
+// Clock is the time source for token refresh, reconnect backoff, subscription monitoring and cache expiry
+// Only stdlib types in the signatures, so fakes (websocket/mocktesting.FakeClock) need not import this package.
+// Network deadlines and shutdown safety timeouts always use real time
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the real wall clock, used unless WithClock overrides it
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// WithClock replaces SystemClock, e.g. with mocktesting.NewFakeClock to test timing deterministically
+// Applies to the auth, REST and streaming clients
+func WithClock(clock Clock) Option {
+	return func(o *ClientOptions) {
+		o.Clock = clock
+	}
+}
@@ -3,6 +3,7 @@ package saxo
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 // TestConfig manages test environment configuration following legacy pattern
@@ -13,6 +14,7 @@ type TestConfig struct {
 	SaxoClientID         string
 	SaxoClientSecret     string
 	SaxoBaseURL          string
+	Providers            []string // Registered providers with SIM credentials set, see IntegrationProviders
 }
 
 // LoadTestConfig loads test configuration from environment variables
@@ -41,6 +43,7 @@ func LoadTestConfig() TestConfig {
 		SaxoClientID:         os.Getenv("SAXO_CLIENT_ID"),
 		SaxoClientSecret:     os.Getenv("SAXO_CLIENT_SECRET"),
 		SaxoBaseURL:          baseURL,
+		Providers:            IntegrationProviders(),
 	}
 }
 
@@ -56,3 +59,32 @@ func (tc TestConfig) GetSIMCredentials() (clientID, clientSecret, baseURL string
 	}
 	return tc.SaxoClientID, tc.SaxoClientSecret, tc.SaxoBaseURL
 }
+
+// IntegrationProviders returns registered providers whose credentials are set and whose environment is SIM
+// INTEGRATION_PROVIDERS (comma separated) narrows the list, e.g. INTEGRATION_PROVIDERS=saxo
+func IntegrationProviders() []string {
+	wanted := map[string]bool{}
+	for _, name := range strings.Split(os.Getenv("INTEGRATION_PROVIDERS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			wanted[name] = true
+		}
+	}
+
+	var names []string
+	for _, name := range RegisteredProviders() {
+		if len(wanted) > 0 && !wanted[name] {
+			continue
+		}
+		provider, _ := LookupProvider(name)
+		prefix := provider.EnvPrefix
+		if os.Getenv(prefix+"_CLIENT_ID") == "" || os.Getenv(prefix+"_CLIENT_SECRET") == "" {
+			continue
+		}
+		// Never LIVE in tests
+		if env := os.Getenv(prefix + "_ENVIRONMENT"); env != "" && SaxoEnvironment(env) != SaxoSIM {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
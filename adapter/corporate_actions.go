@@ -0,0 +1,346 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Corporate action types in CorporateAction.Type; other events keep their ISO 15022 code (e.g. "RHTS")
+const (
+	CorporateActionCashDividend  = "CashDividend"
+	CorporateActionStockDividend = "StockDividend"
+	CorporateActionSplit         = "Split"
+	CorporateActionReverseSplit  = "ReverseSplit"
+	CorporateActionMerger        = "Merger"
+	CorporateActionSpinOff       = "SpinOff"
+)
+
+// corporateActionTypes maps ISO 15022 event codes used by Saxo to CorporateAction types
+var corporateActionTypes = map[string]string{
+	"DVCA": CorporateActionCashDividend,
+	"DVSE": CorporateActionStockDividend,
+	"DVOP": CorporateActionStockDividend,
+	"SPLF": CorporateActionSplit,
+	"SPLR": CorporateActionReverseSplit,
+	"MRGR": CorporateActionMerger,
+	"SOFF": CorporateActionSpinOff,
+}
+
+// CorporateAction is a dividend, split or other event affecting positions in an instrument
+type CorporateAction struct {
+	EventID     string    `json:"event_id"`
+	Uic         int       `json:"uic"`
+	AssetType   string    `json:"asset_type"`
+	Symbol      string    `json:"symbol"`
+	Type        string    `json:"type"`       // CorporateActionCashDividend, CorporateActionSplit, ...
+	EventCode   string    `json:"event_code"` // ISO 15022 code as sent by Saxo, e.g. "DVCA"
+	Description string    `json:"description"`
+	ExDate      time.Time `json:"ex_date"`      // First day trading without the entitlement - positions are adjusted here
+	RecordDate  time.Time `json:"record_date"`  // Zero if not announced
+	PaymentDate time.Time `json:"payment_date"` // Zero if not announced
+	Amount      float64   `json:"amount"`       // Per share for dividends, 0 otherwise
+	Currency    string    `json:"currency"`
+	Ratio       string    `json:"ratio"` // e.g. "2:1" for splits, "" otherwise
+	Voluntary   bool      `json:"voluntary"`
+}
+
+// Ref returns the instrument of the event as an InstrumentRef
+func (ca CorporateAction) Ref() InstrumentRef {
+	return InstrumentRef{Uic: ca.Uic, AssetType: ca.AssetType}
+}
+
+// saxoCorporateActionEvent is one entry of GET /ca/v2/events
+type saxoCorporateActionEvent struct {
+	EventID              string  `json:"EventId"`
+	EventType            string  `json:"EventType"`
+	EventTypeDescription string  `json:"EventTypeDescription"`
+	Uic                  int     `json:"Uic"`
+	AssetType            string  `json:"AssetType"`
+	Symbol               string  `json:"Symbol"`
+	ExDate               string  `json:"ExDate"`
+	RecordDate           string  `json:"RecordDate"`
+	PaymentDate          string  `json:"PaymentDate"`
+	GrossAmount          float64 `json:"GrossAmount"`
+	Currency             string  `json:"Currency"`
+	Ratio                string  `json:"Ratio"`
+	IsVoluntary          bool    `json:"IsVoluntary"`
+}
+
+// parseEventDate accepts Saxo's date-only and RFC3339 forms, zero time when absent
+func parseEventDate(value string) time.Time {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t
+	}
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
+
+func (event saxoCorporateActionEvent) toCorporateAction() CorporateAction {
+	action := CorporateAction{
+		EventID:     event.EventID,
+		Uic:         event.Uic,
+		AssetType:   event.AssetType,
+		Symbol:      event.Symbol,
+		Type:        event.EventType,
+		EventCode:   event.EventType,
+		Description: event.EventTypeDescription,
+		ExDate:      parseEventDate(event.ExDate),
+		RecordDate:  parseEventDate(event.RecordDate),
+		PaymentDate: parseEventDate(event.PaymentDate),
+		Amount:      event.GrossAmount,
+		Currency:    event.Currency,
+		Ratio:       event.Ratio,
+		Voluntary:   event.IsVoluntary,
+	}
+	if actionType, ok := corporateActionTypes[strings.ToUpper(event.EventType)]; ok {
+		action.Type = actionType
+	}
+	return action
+}
+
+// GetUpcomingCorporateActions returns corporate actions with an ex-date (or payment date) from today on,
+// for the given UICs (all instruments when uics is empty), sorted by ex-date
+// Reference: Saxo API GET /ca/v2/events
+func (sbc *SaxoBrokerClient) GetUpcomingCorporateActions(ctx context.Context, uics []int) ([]CorporateAction, error) {
+	if !sbc.authClient.IsAuthenticated() {
+		return nil, sbc.notAuthenticated()
+	}
+
+	clientInfo, err := sbc.GetClientInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClientKey for corporate actions: %w", err)
+	}
+
+	wanted := make(map[int]bool, len(uics))
+	for _, uic := range uics {
+		wanted[uic] = true
+	}
+	now := sbc.clock.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	query := url.Values{}
+	query.Set("ClientKey", clientInfo.ClientKey)
+	pageURL := sbc.baseURL + "/ca/v2/events?" + query.Encode()
+
+	var actions []CorporateAction
+	for pageURL != "" {
+		var page struct {
+			Next string                     `json:"__next"`
+			Data []saxoCorporateActionEvent `json:"Data"`
+		}
+		if err := sbc.getCorporateActionPage(ctx, pageURL, &page); err != nil {
+			return nil, err
+		}
+		for _, event := range page.Data {
+			if len(wanted) > 0 && !wanted[event.Uic] {
+				continue
+			}
+			action := event.toCorporateAction()
+			effective := action.ExDate
+			if effective.IsZero() {
+				effective = action.PaymentDate
+			}
+			if effective.Before(today) {
+				continue
+			}
+			actions = append(actions, action)
+		}
+		if pageURL, err = sbc.nextPageURL(pageURL, page.Next); err != nil {
+			return nil, fmt.Errorf("corporate actions: %w", err)
+		}
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool { return actions[i].ExDate.Before(actions[j].ExDate) })
+
+	sbc.logger.Info("Retrieved upcoming corporate actions",
+		"function", "GetUpcomingCorporateActions",
+		"uics", len(uics),
+		"count", len(actions))
+	return actions, nil
+}
+
+func (sbc *SaxoBrokerClient) getCorporateActionPage(ctx context.Context, pageURL string, page interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := sbc.doRequest(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to get corporate actions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return sbc.handleErrorResponse(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(page); err != nil {
+		return fmt.Errorf("failed to decode corporate actions response: %w", err)
+	}
+	return nil
+}
+
+// CorporateActionProvider supplies corporate actions - SaxoBrokerClient satisfies it
+type CorporateActionProvider interface {
+	GetUpcomingCorporateActions(ctx context.Context, uics []int) ([]CorporateAction, error)
+}
+
+// CorporateActionNotifier polls for corporate actions and emits new or revised ones on a channel
+type CorporateActionNotifier struct {
+	provider CorporateActionProvider
+	interval time.Duration
+	clock    Clock // The SaxoBrokerClient's clock (WithClock), else SystemClock
+	logger   *slog.Logger
+
+	mu     sync.Mutex
+	uics   []int
+	seen   map[string]CorporateAction // By EventID, as last delivered
+	events chan<- CorporateAction
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewCorporateActionNotifier creates a notifier polling uics every interval (default 6h), timed
+// by the provider's Clock when it is a SaxoBrokerClient
+// Announcements change at most a few times a day, so frequent polling only costs rate limit
+func NewCorporateActionNotifier(provider CorporateActionProvider, uics []int, interval time.Duration, logger *slog.Logger) *CorporateActionNotifier {
+	if interval <= 0 {
+		interval = 6 * time.Hour
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	clock := SystemClock
+	if client, ok := provider.(*SaxoBrokerClient); ok && client.clock != nil {
+		clock = client.clock
+	}
+	return &CorporateActionNotifier{
+		provider: provider,
+		interval: interval,
+		clock:    clock,
+		logger:   logger,
+		uics:     append([]int(nil), uics...),
+		seen:     make(map[string]CorporateAction),
+	}
+}
+
+// SetEventChannel registers the channel receiving corporate actions (non-blocking send)
+// An action that does not fit is retried on the next poll
+func (n *CorporateActionNotifier) SetEventChannel(ch chan<- CorporateAction) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.events = ch
+}
+
+// SetUics replaces the watched instruments, e.g. after positions change; applies from the next poll
+func (n *CorporateActionNotifier) SetUics(uics []int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.uics = append([]int(nil), uics...)
+}
+
+// Start polls immediately, then every interval
+func (n *CorporateActionNotifier) Start(ctx context.Context) error {
+	n.mu.Lock()
+	if n.cancel != nil {
+		n.mu.Unlock()
+		return fmt.Errorf("corporate action notifier already started")
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+	n.cancel = cancel
+	n.done = make(chan struct{})
+	n.mu.Unlock()
+
+	if err := n.poll(ctx); err != nil {
+		n.logger.Warn("Initial corporate action poll failed",
+			"function", "Start",
+			"error", err)
+	}
+
+	go n.run(runCtx)
+	return nil
+}
+
+// Shutdown implements Shutdowner - stops the polling loop
+func (n *CorporateActionNotifier) Shutdown(ctx context.Context) error {
+	n.mu.Lock()
+	cancel, done := n.cancel, n.done
+	n.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("corporate action notifier did not stop: %w", ctx.Err())
+	}
+}
+
+func (n *CorporateActionNotifier) run(ctx context.Context) {
+	defer close(n.done)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-n.clock.After(n.interval):
+			if err := n.poll(ctx); err != nil {
+				n.logger.Warn("Corporate action poll failed",
+					"function", "run",
+					"error", err)
+			}
+		}
+	}
+}
+
+// poll fetches upcoming actions and emits those not delivered before or changed since
+func (n *CorporateActionNotifier) poll(ctx context.Context) error {
+	n.mu.Lock()
+	uics := append([]int(nil), n.uics...)
+	n.mu.Unlock()
+	if len(uics) == 0 {
+		return nil
+	}
+
+	actions, err := n.provider.GetUpcomingCorporateActions(ctx, uics)
+	if err != nil {
+		return fmt.Errorf("failed to get corporate actions: %w", err)
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.events == nil {
+		return nil
+	}
+	for _, action := range actions {
+		if previous, ok := n.seen[action.EventID]; ok && previous == action {
+			continue
+		}
+		select {
+		case n.events <- action:
+			n.seen[action.EventID] = action
+			n.logger.Info("Corporate action announced",
+				"function", "poll",
+				"event_id", action.EventID,
+				"uic", action.Uic,
+				"type", action.Type,
+				"ex_date", action.ExDate.Format("2006-01-02"))
+		default:
+			n.logger.Warn("Corporate action channel full, retrying next poll",
+				"function", "poll",
+				"event_id", action.EventID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestSaxoBrokerClient_GetUpcomingCorporateActions(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	mockServer.SetResponse("GET", "/port/v1/users/me", http.StatusOK, SaxoClientInfo{ClientKey: "client1"})
+	mockServer.SetHandler("GET", "/ca/v2/events", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"Data": []map[string]interface{}{
+			{"EventId": "split", "EventType": "SPLF", "Uic": 211, "AssetType": "CfdOnStock", "Symbol": "AAPL:xnas", "ExDate": "2026-11-02", "Ratio": "4:1"},
+			{"EventId": "div", "EventType": "DVCA", "Uic": 211, "AssetType": "CfdOnStock", "ExDate": "2026-10-20T00:00:00Z", "PaymentDate": "2026-10-30", "GrossAmount": 0.26, "Currency": "USD"},
+			{"EventId": "past", "EventType": "DVCA", "Uic": 211, "AssetType": "CfdOnStock", "ExDate": "2026-09-01"},
+			{"EventId": "other", "EventType": "DVCA", "Uic": 999, "AssetType": "Stock", "ExDate": "2026-12-01"},
+		}})
+	})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	clock := mocktesting.NewFakeClock(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithClock(clock))
+
+	actions, err := client.GetUpcomingCorporateActions(context.Background(), []int{211})
+	if err != nil {
+		t.Fatalf("GetUpcomingCorporateActions: %v", err)
+	}
+	if len(actions) != 2 {
+		t.Fatalf("Expected 2 upcoming actions for uic 211, got %+v", actions)
+	}
+	dividend, split := actions[0], actions[1]
+	if dividend.Type != CorporateActionCashDividend || dividend.Amount != 0.26 || dividend.PaymentDate.Day() != 30 {
+		t.Errorf("Unexpected dividend: %+v", dividend)
+	}
+	if split.Type != CorporateActionSplit || split.EventCode != "SPLF" || split.Ratio != "4:1" {
+		t.Errorf("Unexpected split: %+v", split)
+	}
+
+	all, err := client.GetUpcomingCorporateActions(context.Background(), nil)
+	if err != nil || len(all) != 3 {
+		t.Errorf("Expected 3 upcoming actions without a UIC filter, got %d (%v)", len(all), err)
+	}
+}
+
+type staticCorporateActions struct {
+	actions []CorporateAction
+}
+
+func (s *staticCorporateActions) GetUpcomingCorporateActions(ctx context.Context, uics []int) ([]CorporateAction, error) {
+	return s.actions, nil
+}
+
+func TestCorporateActionNotifier_EmitsNewAndRevisedActions(t *testing.T) {
+	provider := &staticCorporateActions{actions: []CorporateAction{{EventID: "div", Uic: 211, Amount: 0.25}}}
+	notifier := NewCorporateActionNotifier(provider, []int{211}, time.Hour, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	events := make(chan CorporateAction, 1)
+	notifier.SetEventChannel(events)
+
+	if err := notifier.poll(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-events; got.EventID != "div" {
+		t.Fatalf("Unexpected event: %+v", got)
+	}
+
+	// Unchanged - not emitted again
+	notifier.poll(context.Background())
+	if len(events) != 0 {
+		t.Fatalf("Unchanged action emitted twice")
+	}
+
+	// Revised amount plus a new event while the channel only fits one: the second waits for the next poll
+	provider.actions = []CorporateAction{{EventID: "div", Uic: 211, Amount: 0.26}, {EventID: "split", Uic: 211}}
+	notifier.poll(context.Background())
+	if got := <-events; got.Amount != 0.26 {
+		t.Fatalf("Expected revised dividend, got %+v", got)
+	}
+	notifier.poll(context.Background())
+	if got := <-events; got.EventID != "split" {
+		t.Fatalf("Expected deferred split, got %+v", got)
+	}
+}
+
+func TestCorporateActionNotifier_RunPollsEveryInterval(t *testing.T) {
+	provider := &staticCorporateActions{actions: []CorporateAction{{EventID: "div", Uic: 211, Amount: 0.25}}}
+	notifier := NewCorporateActionNotifier(provider, []int{211}, time.Hour, nil)
+	clock := mocktesting.NewFakeClock(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	notifier.clock = clock
+	events := make(chan CorporateAction, 1)
+	notifier.SetEventChannel(events)
+
+	if err := notifier.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer notifier.Shutdown(context.Background())
+	if got := <-events; got.EventID != "div" {
+		t.Fatalf("Expected the initial poll to emit div, got %+v", got)
+	}
+
+	if err := clock.WaitForWaiters(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	provider.actions = []CorporateAction{{EventID: "split", Uic: 211}}
+	clock.Advance(time.Hour)
+	select {
+	case got := <-events:
+		if got.EventID != "split" {
+			t.Errorf("Expected split from the interval poll, got %+v", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No poll after the interval elapsed on the clock")
+	}
+
+	if err := notifier.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+}
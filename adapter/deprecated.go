@@ -0,0 +1,3 @@
+package saxo
+
+// Deprecated wrappers for identifiers replaced after v1, removed in v2
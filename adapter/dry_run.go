@@ -0,0 +1,172 @@
+package saxo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// WithDryRun makes PlaceOrder, ModifyOrder, CancelOrder and ClosePosition validate and log the
+// order, then return a synthetic success (OrderResponse.DryRun) without touching the trading endpoints.
+// Read-only endpoints keep working against live data.
+// precheck=true sends new orders to /trade/v2/orders/precheck first for real cost and margin numbers;
+// orders Saxo would reject then fail like they would live
+func WithDryRun(precheck bool) Option {
+	return func(o *ClientOptions) {
+		o.DryRun = true
+		o.DryRunPrecheck = precheck
+	}
+}
+
+// dryRunSeq numbers synthetic order IDs, shared by all clients so IDs never collide
+var dryRunSeq atomic.Int64
+
+func nextDryRunOrderID() string {
+	return fmt.Sprintf("dryrun-%d", dryRunSeq.Add(1))
+}
+
+// dryRunOrder answers a new order (PlaceOrder, ClosePosition) without placing it
+// saxoOrder is the payload that would have been POSTed to /trade/v2/orders
+func (sbc *SaxoBrokerClient) dryRunOrder(ctx context.Context, function string, saxoOrder interface{}, orderType, side string, relatedOrders int) (*OrderResponse, error) {
+	payload, err := json.Marshal(saxoOrder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp := &OrderResponse{
+		OrderID:   nextDryRunOrderID(),
+		Status:    "Working",
+		Timestamp: sbc.clock.Now().Format(time.RFC3339),
+		DryRun:    true,
+	}
+	if orderType == "Market" {
+		resp.Status = "Filled"
+	}
+	for i := 0; i < relatedOrders; i++ {
+		resp.RelatedOrderIDs = append(resp.RelatedOrderIDs, nextDryRunOrderID())
+	}
+
+	if sbc.dryRunPrecheck {
+		precheck, err := sbc.precheck(ctx, payload, side)
+		if err != nil {
+			return nil, err
+		}
+		resp.Precheck = precheck
+	}
+
+	sbc.logger.Info("Dry run: order not sent",
+		"function", function,
+		"order_id", resp.OrderID,
+		"status", resp.Status,
+		"payload", string(payload))
+	return resp, nil
+}
+
+// validateDryRunOrder repeats the basic checks Saxo does server-side, which a dry run never reaches
+func validateDryRunOrder(req OrderRequest) error {
+	switch {
+	case req.Instrument.Identifier <= 0 || req.Instrument.AssetType == "":
+		return fmt.Errorf("instrument Identifier and AssetType are required")
+	case req.Side != "Buy" && req.Side != "Sell":
+		return fmt.Errorf("invalid side %q", req.Side)
+	case req.IsCashAmount() && req.CashAmount <= 0:
+		return fmt.Errorf("cash amount must be positive, got %v", req.CashAmount)
+	case !req.IsCashAmount() && req.Size <= 0:
+		return fmt.Errorf("size must be positive, got %d", req.Size)
+	case req.OrderType == "":
+		return fmt.Errorf("order type is required")
+	case req.OrderType != "Market" && req.Price <= 0:
+		return fmt.Errorf("%s order requires a price", req.OrderType)
+	}
+	return nil
+}
+
+// PrecheckOrder asks Saxo what req would cost without placing it (POST /trade/v2/orders/precheck)
+// A rejection (insufficient margin, invalid price, ...) is returned as an error
+func (sbc *SaxoBrokerClient) PrecheckOrder(ctx context.Context, req OrderRequest) (*OrderPrecheck, error) {
+	if !sbc.authClient.IsAuthenticated() {
+		return nil, sbc.notAuthenticated()
+	}
+	saxoReq, err := sbc.convertToSaxoOrder(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert order request: %w", err)
+	}
+	payload, err := json.Marshal(saxoReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return sbc.precheck(ctx, payload, req.Side)
+}
+
+func (sbc *SaxoBrokerClient) precheck(ctx context.Context, payload []byte, side string) (*OrderPrecheck, error) {
+	// FieldGroups adds cost and margin estimates to the result
+	var body map[string]interface{}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("failed to build precheck request: %w", err)
+	}
+	body["FieldGroups"] = []string{"Costs", "MarginImpactBuySell"}
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal precheck request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST",
+		sbc.baseURL+"/trade/v2/orders/precheck", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := sbc.doRequest(ctx, httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("precheck request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		return nil, sbc.handleErrorResponse(resp)
+	}
+
+	var saxoResp SaxoPrecheckResponse
+	if err := json.Unmarshal(bodyBytes, &saxoResp); err != nil {
+		return nil, fmt.Errorf("failed to decode precheck response: %w", err)
+	}
+	if saxoResp.PreCheckResult != "Ok" {
+		if saxoResp.ErrorInfo != nil {
+			return nil, fmt.Errorf("precheck rejected order: %s: %s", saxoResp.ErrorInfo.ErrorCode, saxoResp.ErrorInfo.Message)
+		}
+		return nil, fmt.Errorf("precheck rejected order: %s", saxoResp.PreCheckResult)
+	}
+
+	precheck := &OrderPrecheck{
+		Result:                saxoResp.PreCheckResult,
+		EstimatedCashRequired: saxoResp.EstimatedCashRequired,
+		Currency:              saxoResp.EstimatedCashRequiredCurrency,
+		Warnings:              advisoryWarnings(saxoResp.MarketState, saxoResp.ErrorInfo, ""),
+	}
+	if margin := saxoResp.MarginImpactBuySell; margin != nil {
+		precheck.InitialMarginAvailable = margin.InitialMarginAvailableBuy
+		if strings.EqualFold(side, "Sell") {
+			precheck.InitialMarginAvailable = margin.InitialMarginAvailableSell
+		}
+	}
+
+	sbc.logger.Info("Order precheck",
+		"function", "PrecheckOrder",
+		"result", precheck.Result,
+		"estimated_cash_required", precheck.EstimatedCashRequired,
+		"currency", precheck.Currency,
+		"initial_margin_available", precheck.InitialMarginAvailable)
+	return precheck, nil
+}
@@ -0,0 +1,128 @@
+package saxo
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func newDryRunClient(t *testing.T, mockServer *MockSaxoServer, precheck bool) *SaxoBrokerClient {
+	t.Helper()
+	authClient := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "mock_token",
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	return NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithDryRun(precheck))
+}
+
+func dryRunOrder() OrderRequest {
+	return OrderRequest{
+		Instrument: createTestInstrument("EURUSD", 21, "FxSpot"),
+		Side:       "Buy",
+		Size:       1000,
+		Price:      1.0850,
+		OrderType:  "Limit",
+		AccountKey: "test_account_key",
+		RelatedOrders: []RelatedOrderRequest{
+			{Side: "Sell", OrderType: "Limit", Price: 1.0950, Duration: Duration(DurationGoodTillCancel)},
+			{Side: "Sell", OrderType: "StopIfTraded", Price: 1.0800, Duration: Duration(DurationGoodTillCancel)},
+		},
+	}
+}
+
+func TestSaxoBrokerClient_DryRun(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SimulateOrders("test_account_key", 100000)
+	client := newDryRunClient(t, mockServer, false)
+	ctx := context.Background()
+
+	placed, err := client.PlaceOrder(ctx, dryRunOrder())
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	if !placed.DryRun || !strings.HasPrefix(placed.OrderID, "dryrun-") || placed.Status != "Working" {
+		t.Errorf("Expected synthetic working order, got %+v", placed)
+	}
+	if len(placed.RelatedOrderIDs) != 2 {
+		t.Errorf("Expected 2 synthetic related order IDs, got %v", placed.RelatedOrderIDs)
+	}
+
+	modify := OrderModificationRequest{OrderID: placed.OrderID, OrderPrice: "1.0840", OrderType: "Limit", AssetType: "FxSpot"}
+	if resp, err := client.ModifyOrder(ctx, modify); err != nil || !resp.DryRun {
+		t.Errorf("Expected dry-run modification, got %+v (%v)", resp, err)
+	}
+	if err := client.CancelOrder(ctx, CancelOrderRequest{OrderID: placed.OrderID, AccountKey: "test_account_key"}); err != nil {
+		t.Errorf("CancelOrder failed: %v", err)
+	}
+	closed, err := client.ClosePosition(ctx, ClosePositionRequest{
+		PositionID: "1", AccountKey: "test_account_key", Uic: 21, AssetType: "FxSpot", Amount: 1000, BuySell: "Buy",
+	})
+	if err != nil || closed.Status != "Filled" || !closed.DryRun {
+		t.Errorf("Expected dry-run market close, got %+v (%v)", closed, err)
+	}
+
+	// Validation still applies
+	invalid := dryRunOrder()
+	invalid.Side = "Hold"
+	if _, err := client.PlaceOrder(ctx, invalid); err == nil {
+		t.Error("Expected invalid order to fail in dry run")
+	}
+
+	// Reads still hit Saxo, trading endpoints never do
+	if _, err := client.GetBalance(ctx); err != nil {
+		t.Errorf("GetBalance failed in dry run: %v", err)
+	}
+	mockServer.AssertRequested(t, "GET", "/port/v1/balances/me", 1)
+	for _, req := range mockServer.GetRequests() {
+		if strings.Contains(req.Path, "/trade/") {
+			t.Errorf("Dry run sent %s %s", req.Method, req.Path)
+		}
+	}
+}
+
+func TestSaxoBrokerClient_DryRunPrecheck(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	client := newDryRunClient(t, mockServer, true)
+	ctx := context.Background()
+
+	mockServer.SetResponse("POST", "/trade/v2/orders/precheck", 200, map[string]interface{}{
+		"PreCheckResult":                "Ok",
+		"EstimatedCashRequired":         125.5,
+		"EstimatedCashRequiredCurrency": "EUR",
+		"MarginImpactBuySell": map[string]float64{
+			"InitialMarginAvailableBuy":  9000,
+			"InitialMarginAvailableSell": 9500,
+		},
+	})
+
+	placed, err := client.PlaceOrder(ctx, dryRunOrder())
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	precheck := placed.Precheck
+	if precheck == nil || precheck.EstimatedCashRequired != 125.5 || precheck.Currency != "EUR" {
+		t.Fatalf("Expected precheck numbers on the response, got %+v", precheck)
+	}
+	if precheck.InitialMarginAvailable != 9000 {
+		t.Errorf("Expected buy-side margin 9000, got %v", precheck.InitialMarginAvailable)
+	}
+	requests := mockServer.AssertRequested(t, "POST", "/trade/v2/orders/precheck", 1)
+	if len(requests) == 1 && !strings.Contains(requests[0].Body, "MarginImpactBuySell") {
+		t.Errorf("Expected FieldGroups in precheck body, got %s", requests[0].Body)
+	}
+	mockServer.AssertRequested(t, "POST", "/trade/v2/orders", 0)
+
+	// A precheck rejection fails the dry-run order like it would fail live
+	mockServer.SetResponse("POST", "/trade/v2/orders/precheck", 200, map[string]interface{}{
+		"PreCheckResult": "Error",
+		"ErrorInfo":      map[string]string{"ErrorCode": "InsufficientMargin", "Message": "Not enough margin"},
+	})
+	if _, err := client.PlaceOrder(ctx, dryRunOrder()); err == nil || !strings.Contains(err.Error(), "InsufficientMargin") {
+		t.Errorf("Expected precheck rejection, got %v", err)
+	}
+}
@@ -0,0 +1,381 @@
+// Package exposure provides ExposureTracker, a live in-memory view of open exposure per instrument and currency
+// A REST snapshot (Refresh) is kept current with streaming position, order, balance and price updates,
+// so queries never recompute from the raw Saxo responses
+package exposure
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket"
+)
+
+// Exposure is the tracked state of one instrument
+type Exposure struct {
+	Uic         int
+	AssetType   string
+	Currency    string  // Exposure currency (Saxo ExposureCurrency, else the instrument currency)
+	NetAmount   float64 // Signed open position in units
+	PendingBuy  float64 // Working entry orders in units
+	PendingSell float64
+	Price       float64 // Latest price used for Value
+	Value       float64 // NetAmount x Price (x contract size, derived from the snapshot) in Currency
+	UpdatedAt   time.Time
+}
+
+// ExposureEvent reports a change of NetAmount, PendingBuy or PendingSell
+// Price ticks only revalue exposure and do not produce events
+type ExposureEvent struct {
+	Uic      int
+	Previous Exposure // Zero value when the instrument was not tracked before
+	Current  Exposure // NetAmount and pending zero when the instrument is no longer held
+	Source   string   // "snapshot", "position" or "order"
+}
+
+type instrumentState struct {
+	assetType string
+	currency  string
+	price     float64
+	factor    float64 // Value per unit per price point, 1 unless the snapshot says otherwise
+}
+
+type positionState struct {
+	uic       int
+	assetType string
+	amount    float64
+}
+
+type orderState struct {
+	uic      int
+	side     string
+	amount   float64
+	relation string
+}
+
+// ExposureTracker maintains exposure per instrument, safe for concurrent use
+// Call Refresh at startup (and after reconnects), then feed the streams:
+// ApplyPosition (/port/v1/positions), ApplyOrder, ApplyPortfolio and UpdatePrice
+type ExposureTracker struct {
+	broker saxo.BrokerClient
+	logger *slog.Logger
+
+	mu          sync.RWMutex
+	instruments map[int]*instrumentState
+	positions   map[string]positionState
+	orders      map[string]orderState
+	exposures   map[int]Exposure
+	marginUsed  float64
+
+	events chan ExposureEvent
+}
+
+// NewExposureTracker creates an empty tracker; snapshots are read from broker
+func NewExposureTracker(broker saxo.BrokerClient, logger *slog.Logger) *ExposureTracker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ExposureTracker{
+		broker:      broker,
+		logger:      logger,
+		instruments: make(map[int]*instrumentState),
+		positions:   make(map[string]positionState),
+		orders:      make(map[string]orderState),
+		exposures:   make(map[int]Exposure),
+		events:      make(chan ExposureEvent, 64),
+	}
+}
+
+// Refresh replaces the tracked state with a REST snapshot
+// Reads open positions (per-position amounts for stream deltas), net positions (price, currency,
+// contract size), open orders and the balance
+func (et *ExposureTracker) Refresh(ctx context.Context) error {
+	positions, err := et.broker.GetOpenPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get open positions: %w", err)
+	}
+	netPositions, err := et.broker.GetNetPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get net positions: %w", err)
+	}
+	orders, err := et.broker.GetOpenOrders(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get open orders: %w", err)
+	}
+	balance, err := et.broker.GetBalance(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	et.mu.Lock()
+	defer et.mu.Unlock()
+
+	touched := make(map[int]bool)
+	for uic := range et.exposures {
+		touched[uic] = true
+	}
+
+	et.positions = make(map[string]positionState)
+	for _, position := range positions.Data {
+		base := position.PositionBase
+		et.positions[position.PositionID] = positionState{uic: base.Uic, assetType: base.AssetType, amount: base.Amount}
+		et.instrumentLocked(base.Uic, base.AssetType)
+		touched[base.Uic] = true
+	}
+
+	for _, net := range netPositions.Data {
+		base, view := net.NetPositionBase, net.NetPositionView
+		instrument := et.instrumentLocked(base.Uic, base.AssetType)
+		instrument.currency = view.ExposureCurrency
+		if instrument.currency == "" {
+			instrument.currency = net.DisplayAndFormat.Currency
+		}
+		if view.CurrentPrice != 0 {
+			instrument.price = view.CurrentPrice
+			if base.Amount != 0 && view.Exposure != 0 {
+				instrument.factor = view.Exposure / (base.Amount * view.CurrentPrice)
+			}
+		}
+		touched[base.Uic] = true
+	}
+
+	et.orders = make(map[string]orderState)
+	for _, order := range orders {
+		et.orders[order.OrderID] = orderState{uic: order.Uic, side: order.BuySell, amount: order.Amount, relation: order.OrderRelation}
+		et.instrumentLocked(order.Uic, order.AssetType)
+		touched[order.Uic] = true
+	}
+
+	et.marginUsed = balance.MarginUsedByCurrentPositions
+
+	for uic := range touched {
+		et.recomputeLocked(uic, "snapshot")
+	}
+	et.logger.Info("Exposure snapshot loaded",
+		"function", "ExposureTracker.Refresh",
+		"positions", len(et.positions),
+		"orders", len(et.orders),
+		"instruments", len(et.exposures))
+	return nil
+}
+
+// ApplyPosition merges one streaming position delta (see websocket.DecodePositions)
+func (et *ExposureTracker) ApplyPosition(position websocket.StreamingPosition) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+
+	current, known := et.positions[position.PositionID]
+	if position.MetaDeleted != nil && *position.MetaDeleted {
+		if known {
+			delete(et.positions, position.PositionID)
+			et.recomputeLocked(current.uic, "position")
+		}
+		return
+	}
+
+	if base := position.PositionBase; base != nil {
+		if base.Uic != nil {
+			current.uic = *base.Uic
+		}
+		if base.AssetType != nil {
+			current.assetType = *base.AssetType
+		}
+		if base.Amount != nil {
+			current.amount = *base.Amount
+		}
+	}
+	if current.uic == 0 {
+		et.logger.Debug("Position delta for unknown position without Uic, skipping",
+			"function", "ExposureTracker.ApplyPosition",
+			"position_id", position.PositionID)
+		return
+	}
+	et.positions[position.PositionID] = current
+
+	instrument := et.instrumentLocked(current.uic, current.assetType)
+	if view := position.PositionView; view != nil && view.CurrentPrice != nil && *view.CurrentPrice != 0 {
+		instrument.price = *view.CurrentPrice
+	}
+	et.recomputeLocked(current.uic, "position")
+}
+
+// ApplyOrder merges an order stream update; terminal (OrderState.IsTerminal) and deleted orders stop counting as pending
+func (et *ExposureTracker) ApplyOrder(update saxo.OrderUpdate) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+
+	current, known := et.orders[update.OrderId]
+	if (update.MetaDeleted != nil && *update.MetaDeleted) || update.State().IsTerminal() {
+		if known {
+			delete(et.orders, update.OrderId)
+			et.recomputeLocked(current.uic, "order")
+		}
+		return
+	}
+
+	if update.Uic != nil {
+		current.uic = *update.Uic
+	}
+	if update.Amount != nil {
+		current.amount = float64(*update.Amount)
+	}
+	if update.BuySell != "" {
+		current.side = update.BuySell
+	}
+	if update.OrderRelation != "" {
+		current.relation = update.OrderRelation
+	}
+	et.orders[update.OrderId] = current
+	if current.uic != 0 {
+		et.instrumentLocked(current.uic, "")
+		et.recomputeLocked(current.uic, "order")
+	}
+}
+
+// ApplyPortfolio records margin from the balance stream
+func (et *ExposureTracker) ApplyPortfolio(update saxo.PortfolioUpdate) {
+	et.mu.Lock()
+	et.marginUsed = update.MarginUsed
+	et.mu.Unlock()
+}
+
+// UpdatePrice revalues a tracked instrument; prices for untracked instruments are ignored
+func (et *ExposureTracker) UpdatePrice(update saxo.PriceUpdate) {
+	price := update.Mid
+	if price == 0 {
+		price = (update.Bid + update.Ask) / 2
+	}
+	if price <= 0 {
+		return
+	}
+
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	instrument, ok := et.instruments[update.Uic]
+	if !ok {
+		return
+	}
+	instrument.price = price
+	if exposure, ok := et.exposures[update.Uic]; ok {
+		exposure.Price = price
+		exposure.Value = exposure.NetAmount * price * instrument.factor
+		et.exposures[update.Uic] = exposure
+	}
+}
+
+// CurrentExposure returns the exposure of uic; false when nothing is held or pending
+func (et *ExposureTracker) CurrentExposure(uic int) (Exposure, bool) {
+	et.mu.RLock()
+	defer et.mu.RUnlock()
+	exposure, ok := et.exposures[uic]
+	return exposure, ok
+}
+
+// Exposures returns every tracked instrument, sorted by Uic
+func (et *ExposureTracker) Exposures() []Exposure {
+	et.mu.RLock()
+	defer et.mu.RUnlock()
+	result := make([]Exposure, 0, len(et.exposures))
+	for _, exposure := range et.exposures {
+		result = append(result, exposure)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Uic < result[j].Uic })
+	return result
+}
+
+// ExposureByCurrency sums Value per exposure currency (no FX conversion)
+func (et *ExposureTracker) ExposureByCurrency() map[string]float64 {
+	et.mu.RLock()
+	defer et.mu.RUnlock()
+	result := make(map[string]float64)
+	for _, exposure := range et.exposures {
+		if exposure.NetAmount != 0 {
+			result[exposure.Currency] += exposure.Value
+		}
+	}
+	return result
+}
+
+// TotalMarginUsed returns margin used by current positions from the last snapshot or balance update
+func (et *ExposureTracker) TotalMarginUsed() float64 {
+	et.mu.RLock()
+	defer et.mu.RUnlock()
+	return et.marginUsed
+}
+
+// Events delivers ExposureEvents; events are dropped when nobody reads
+func (et *ExposureTracker) Events() <-chan ExposureEvent {
+	return et.events
+}
+
+func (et *ExposureTracker) instrumentLocked(uic int, assetType string) *instrumentState {
+	instrument, ok := et.instruments[uic]
+	if !ok {
+		instrument = &instrumentState{factor: 1}
+		et.instruments[uic] = instrument
+	}
+	if assetType != "" {
+		instrument.assetType = assetType
+	}
+	return instrument
+}
+
+// recomputeLocked rebuilds the exposure of uic from positions and orders and publishes a change event
+func (et *ExposureTracker) recomputeLocked(uic int, source string) {
+	instrument := et.instrumentLocked(uic, "")
+	next := Exposure{
+		Uic:       uic,
+		AssetType: instrument.assetType,
+		Currency:  instrument.currency,
+		Price:     instrument.price,
+		UpdatedAt: time.Now(),
+	}
+	for _, position := range et.positions {
+		if position.uic == uic {
+			next.NetAmount += position.amount
+		}
+	}
+	for _, order := range et.orders {
+		if order.uic != uic || !isEntry(order.relation) {
+			continue
+		}
+		switch order.side {
+		case "Buy":
+			next.PendingBuy += order.amount
+		case "Sell":
+			next.PendingSell += order.amount
+		}
+	}
+	next.Value = next.NetAmount * next.Price * instrument.factor
+
+	previous, existed := et.exposures[uic]
+	if next.NetAmount == 0 && next.PendingBuy == 0 && next.PendingSell == 0 {
+		delete(et.exposures, uic)
+		if !existed {
+			return
+		}
+	} else {
+		et.exposures[uic] = next
+	}
+	if existed && previous.NetAmount == next.NetAmount &&
+		previous.PendingBuy == next.PendingBuy && previous.PendingSell == next.PendingSell {
+		return
+	}
+
+	select {
+	case et.events <- ExposureEvent{Uic: uic, Previous: previous, Current: next, Source: source}:
+	default:
+		et.logger.Warn("Exposure event dropped - channel full",
+			"function", "ExposureTracker.recomputeLocked",
+			"uic", uic)
+	}
+}
+
+// isEntry reports whether an order adds exposure - exit legs close the entry they belong to
+func isEntry(relation string) bool {
+	return relation == "" || relation == "StandAlone" || relation == "IfDoneMaster"
+}
@@ -0,0 +1,153 @@
+package exposure
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket"
+)
+
+// snapshotBroker serves fixed REST snapshots; other BrokerClient methods are not used
+type snapshotBroker struct {
+	saxo.BrokerClient
+	positions saxo.OpenPositionsResponse
+	net       saxo.NetPositionsResponse
+	orders    []saxo.LiveOrder
+	balance   saxo.Balance
+}
+
+func (b *snapshotBroker) GetOpenPositions(ctx context.Context) (*saxo.OpenPositionsResponse, error) {
+	return &b.positions, nil
+}
+func (b *snapshotBroker) GetNetPositions(ctx context.Context) (*saxo.NetPositionsResponse, error) {
+	return &b.net, nil
+}
+func (b *snapshotBroker) GetOpenOrders(ctx context.Context) ([]saxo.LiveOrder, error) {
+	return b.orders, nil
+}
+func (b *snapshotBroker) GetBalance(ctx context.Context) (*saxo.Balance, error) {
+	return &b.balance, nil
+}
+
+func newSnapshotBroker() *snapshotBroker {
+	b := &snapshotBroker{}
+
+	var position saxo.SaxoOpenPosition
+	position.PositionID = "p1"
+	position.PositionBase.Uic = 21
+	position.PositionBase.AssetType = "FxSpot"
+	position.PositionBase.Amount = 10000
+	b.positions.Data = []saxo.SaxoOpenPosition{position}
+
+	var net saxo.SaxoNetPosition
+	net.NetPositionBase.Uic = 21
+	net.NetPositionBase.AssetType = "FxSpot"
+	net.NetPositionBase.Amount = 10000
+	net.NetPositionView.CurrentPrice = 1.1
+	net.NetPositionView.Exposure = 11000
+	net.NetPositionView.ExposureCurrency = "USD"
+	b.net.Data = []saxo.SaxoNetPosition{net}
+
+	b.orders = []saxo.LiveOrder{
+		{OrderID: "o1", Uic: 21, AssetType: "FxSpot", BuySell: "Sell", Amount: 5000, OrderRelation: "StandAlone"},
+		{OrderID: "o2", Uic: 21, AssetType: "FxSpot", BuySell: "Sell", Amount: 10000, OrderRelation: "IfDoneSlave"},
+	}
+	b.balance.MarginUsedByCurrentPositions = 366
+	return b
+}
+
+func newTestTracker(t *testing.T) *ExposureTracker {
+	t.Helper()
+	tracker := NewExposureTracker(newSnapshotBroker(), slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	if err := tracker.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	return tracker
+}
+
+func expectEvent(t *testing.T, tracker *ExposureTracker, source string) ExposureEvent {
+	t.Helper()
+	select {
+	case event := <-tracker.Events():
+		if event.Source != source {
+			t.Fatalf("Expected %s event, got %+v", source, event)
+		}
+		return event
+	default:
+		t.Fatalf("Expected %s event", source)
+	}
+	return ExposureEvent{}
+}
+
+func TestExposureTracker_Snapshot(t *testing.T) {
+	tracker := newTestTracker(t)
+	expectEvent(t, tracker, "snapshot")
+
+	exposure, ok := tracker.CurrentExposure(21)
+	if !ok {
+		t.Fatal("Expected exposure for 21")
+	}
+	if exposure.NetAmount != 10000 || exposure.Value != 11000 || exposure.Currency != "USD" {
+		t.Errorf("Unexpected exposure %+v", exposure)
+	}
+	// Exit legs add no exposure
+	if exposure.PendingSell != 5000 || exposure.PendingBuy != 0 {
+		t.Errorf("Expected only the entry order pending, got %+v", exposure)
+	}
+	if got := tracker.ExposureByCurrency()["USD"]; got != 11000 {
+		t.Errorf("Expected 11000 USD exposure, got %v", got)
+	}
+	if tracker.TotalMarginUsed() != 366 {
+		t.Errorf("Expected margin 366, got %v", tracker.TotalMarginUsed())
+	}
+}
+
+func TestExposureTracker_StreamingUpdates(t *testing.T) {
+	tracker := newTestTracker(t)
+	expectEvent(t, tracker, "snapshot")
+
+	// Price ticks revalue without events
+	tracker.UpdatePrice(saxo.PriceUpdate{Uic: 21, Mid: 1.2})
+	if exposure, _ := tracker.CurrentExposure(21); exposure.Value != 12000 {
+		t.Errorf("Expected value 12000 after tick, got %v", exposure.Value)
+	}
+	select {
+	case event := <-tracker.Events():
+		t.Errorf("Price tick should not publish, got %+v", event)
+	default:
+	}
+
+	// Entry order fills: new position streams in, order is deleted
+	uic, amount, deleted := 21, -5000.0, true
+	tracker.ApplyPosition(websocket.StreamingPosition{
+		PositionID:   "p2",
+		PositionBase: &websocket.StreamingPositionBase{Uic: &uic, Amount: &amount},
+	})
+	event := expectEvent(t, tracker, "position")
+	if event.Previous.NetAmount != 10000 || event.Current.NetAmount != 5000 {
+		t.Errorf("Unexpected position event %+v", event)
+	}
+	tracker.ApplyOrder(saxo.OrderUpdate{OrderId: "o1", MetaDeleted: &deleted})
+	if event := expectEvent(t, tracker, "order"); event.Current.PendingSell != 0 {
+		t.Errorf("Expected no pending sell after fill, got %+v", event.Current)
+	}
+
+	// Closing both positions removes the instrument
+	tracker.ApplyPosition(websocket.StreamingPosition{PositionID: "p1", MetaDeleted: &deleted})
+	expectEvent(t, tracker, "position")
+	tracker.ApplyPosition(websocket.StreamingPosition{PositionID: "p2", MetaDeleted: &deleted})
+	if event := expectEvent(t, tracker, "position"); event.Current.NetAmount != 0 {
+		t.Errorf("Expected flat exposure, got %+v", event.Current)
+	}
+	if _, ok := tracker.CurrentExposure(21); ok {
+		t.Error("Expected no exposure once flat")
+	}
+
+	tracker.ApplyPortfolio(saxo.PortfolioUpdate{MarginUsed: 0})
+	if tracker.TotalMarginUsed() != 0 {
+		t.Errorf("Expected margin from balance stream, got %v", tracker.TotalMarginUsed())
+	}
+}
@@ -0,0 +1,333 @@
+// Package fills provides FillTracker, which turns the cumulative FilledAmount and status strings of
+// the order stream into discrete fill events and exactly one terminal resolution per order
+package fills
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// resolvedRetention is how long a resolved order is remembered, so late messages of it
+// (typically the __meta_deleted after a Filled status) are not taken for a new order
+const resolvedRetention = 10 * time.Minute
+
+// Resolution is how an order left the market
+type Resolution string
+
+const (
+	ResolutionFilled    Resolution = "Filled"
+	ResolutionCancelled Resolution = "Cancelled"
+	ResolutionExpired   Resolution = "Expired"
+	ResolutionRejected  Resolution = "Rejected"
+	// ResolutionRemoved: the order was deleted from the stream without a final status or a full fill,
+	// e.g. an entry whose exit orders took over. Check positions or GetOrderStatus
+	ResolutionRemoved Resolution = "Removed"
+)
+
+// FillEvent reports a fill of an order, its resolution, or both when the last fill completes it
+type FillEvent struct {
+	OrderId    string
+	Uic        int // 0 when no message of the order carried it
+	Ticker     string
+	Symbol     string
+	BuySell    string
+	AccountKey string
+
+	Quantity   float64 // Units filled since the previous event of the order, 0 on a resolution without fill
+	Cumulative float64 // Units filled in total
+	Amount     float64 // Order amount, 0 when unknown
+
+	// Saxo's order stream carries no execution price. The estimate is the limit price of limit
+	// orders, else the last bid (sells) or ask (buys) passed to UpdatePrice, else the order price
+	EstimatedPrice float64
+	PriceSource    string // "order", "quote" or "" when nothing is known
+
+	Resolution Resolution // Set on the order's last event, "" while it works
+	Time       time.Time  // UpdatedAt of the order update
+}
+
+// Partial reports whether the event is a fill that leaves part of the order open
+func (e FillEvent) Partial() bool {
+	return e.Quantity > 0 && e.Resolution == "" && (e.Amount == 0 || e.Cumulative < e.Amount)
+}
+
+type orderState struct {
+	uic        int
+	ticker     string
+	symbol     string
+	buySell    string
+	accountKey string
+	orderType  string
+	price      float64
+	amount     float64
+	filled     float64
+}
+
+// FillTracker follows order updates and publishes FillEvents, safe for concurrent use
+// Feed it every OrderUpdate (ApplyOrder or Run) and, for price estimates, price updates (UpdatePrice)
+type FillTracker struct {
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	clock    saxo.Clock // Stamps updates without UpdatedAt (SetClock)
+	orders   map[string]*orderState
+	resolved map[string]time.Time
+	quotes   map[int]saxo.PriceUpdate
+
+	// Events wait in queue until read - a fill is never dropped. One forwarder goroutine at a
+	// time drains it in order and exits when it runs empty
+	queueMu    sync.Mutex
+	queue      []FillEvent
+	forwarding bool
+	events     chan FillEvent
+}
+
+// NewFillTracker creates a tracker without orders
+func NewFillTracker(logger *slog.Logger) *FillTracker {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &FillTracker{
+		logger:   logger,
+		clock:    saxo.SystemClock,
+		orders:   make(map[string]*orderState),
+		resolved: make(map[string]time.Time),
+		quotes:   make(map[int]saxo.PriceUpdate),
+		events:   make(chan FillEvent, 64),
+	}
+}
+
+// SetClock replaces saxo.SystemClock, e.g. with the clients' WithClock clock; nil restores it
+func (ft *FillTracker) SetClock(clock saxo.Clock) {
+	if clock == nil {
+		clock = saxo.SystemClock
+	}
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	ft.clock = clock
+}
+
+// Seed records orders that were open before the stream started (GetOpenOrders), so their first
+// stream messages have amount, side and price. Nothing is assumed filled
+func (ft *FillTracker) Seed(orders []saxo.LiveOrder) {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	for _, order := range orders {
+		state, ok := ft.orders[order.OrderID]
+		if !ok {
+			state = &orderState{}
+			ft.orders[order.OrderID] = state
+		}
+		state.uic = order.Uic
+		state.ticker = order.Ticker
+		state.symbol = order.DisplayAndFormat.Symbol
+		state.buySell = order.BuySell
+		state.accountKey = order.AccountKey
+		state.orderType = order.OrderType
+		state.price = order.Price
+		state.amount = order.Amount
+	}
+}
+
+// Run applies updates until ctx is done or updates is closed
+func (ft *FillTracker) Run(ctx context.Context, updates <-chan saxo.OrderUpdate) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			ft.ApplyOrder(update)
+		}
+	}
+}
+
+// ApplyOrder compares update with the order's previous state and publishes a FillEvent when the
+// filled amount grew or the order reached a final status
+// A Filled status without FilledAmount fills the remaining amount; a deletion without final status
+// resolves as Filled when the order was fully filled, else as Removed
+func (ft *FillTracker) ApplyOrder(update saxo.OrderUpdate) {
+	deleted := update.MetaDeleted != nil && *update.MetaDeleted
+
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+	now := update.UpdatedAt
+	if now.IsZero() {
+		now = ft.clock.Now()
+	}
+	ft.pruneLocked(now)
+	if _, done := ft.resolved[update.OrderId]; done {
+		return
+	}
+	state, known := ft.orders[update.OrderId]
+	if !known {
+		if deleted {
+			return // Nothing was seen of the order, nothing to resolve
+		}
+		state = &orderState{}
+		ft.orders[update.OrderId] = state
+	}
+	state.merge(update)
+
+	var quantity float64
+	if update.FilledSize > state.filled {
+		quantity = update.FilledSize - state.filled
+		state.filled = update.FilledSize
+	}
+	resolution := resolutionOf(update.Status)
+	if resolution == ResolutionFilled && update.FilledSize == 0 && state.amount > state.filled {
+		quantity += state.amount - state.filled
+		state.filled = state.amount
+	}
+	if deleted && resolution == "" {
+		resolution = ResolutionRemoved
+		if state.amount > 0 && state.filled >= state.amount {
+			resolution = ResolutionFilled
+		}
+	}
+	if quantity == 0 && resolution == "" {
+		return
+	}
+
+	event := FillEvent{
+		OrderId:    update.OrderId,
+		Uic:        state.uic,
+		Ticker:     state.ticker,
+		Symbol:     state.symbol,
+		BuySell:    state.buySell,
+		AccountKey: state.accountKey,
+		Quantity:   quantity,
+		Cumulative: state.filled,
+		Amount:     state.amount,
+		Resolution: resolution,
+		Time:       now,
+	}
+	if quantity > 0 {
+		event.EstimatedPrice, event.PriceSource = ft.estimateLocked(state)
+	}
+	if resolution != "" {
+		delete(ft.orders, update.OrderId)
+		ft.resolved[update.OrderId] = now
+	}
+
+	ft.publish(event) // Under ft.mu, so events queue in the order updates were applied
+}
+
+// publish queues event for Events and starts the forwarder unless it runs
+func (ft *FillTracker) publish(event FillEvent) {
+	ft.queueMu.Lock()
+	defer ft.queueMu.Unlock()
+	ft.queue = append(ft.queue, event)
+	if n := len(ft.queue); n%1000 == 0 {
+		ft.logger.Warn("Fill events piling up - nobody reads Events",
+			"function", "FillTracker.publish",
+			"queued", n)
+	}
+	if !ft.forwarding {
+		ft.forwarding = true
+		go ft.forward()
+	}
+}
+
+// forward delivers queued events in order until the queue is empty
+func (ft *FillTracker) forward() {
+	for {
+		ft.queueMu.Lock()
+		if len(ft.queue) == 0 {
+			ft.queue = nil
+			ft.forwarding = false
+			ft.queueMu.Unlock()
+			return
+		}
+		event := ft.queue[0]
+		ft.queue = ft.queue[1:]
+		ft.queueMu.Unlock()
+
+		ft.events <- event
+	}
+}
+
+// UpdatePrice records the last quote of an instrument for fill price estimates
+func (ft *FillTracker) UpdatePrice(update saxo.PriceUpdate) {
+	if update.Bid <= 0 && update.Ask <= 0 && update.Mid <= 0 {
+		return
+	}
+	ft.mu.Lock()
+	ft.quotes[update.Uic] = update
+	ft.mu.Unlock()
+}
+
+// Events delivers FillEvents in order; events queue until read, none are dropped
+func (ft *FillTracker) Events() <-chan FillEvent {
+	return ft.events
+}
+
+// estimateLocked returns the estimated execution price of a fill of state and its source
+func (ft *FillTracker) estimateLocked(state *orderState) (float64, string) {
+	if state.orderType == "Limit" && state.price > 0 {
+		return state.price, "order" // Filled at the limit or better
+	}
+	if quote, ok := ft.quotes[state.uic]; ok && state.uic != 0 {
+		price := quote.Mid
+		switch {
+		case state.buySell == "Buy" && quote.Ask > 0:
+			price = quote.Ask
+		case state.buySell == "Sell" && quote.Bid > 0:
+			price = quote.Bid
+		}
+		if price > 0 {
+			return price, "quote"
+		}
+	}
+	if state.price > 0 {
+		return state.price, "order"
+	}
+	return 0, ""
+}
+
+// pruneLocked forgets orders resolved more than resolvedRetention before now
+func (ft *FillTracker) pruneLocked(now time.Time) {
+	for orderID, at := range ft.resolved {
+		if now.Sub(at) > resolvedRetention {
+			delete(ft.resolved, orderID)
+		}
+	}
+}
+
+// merge takes the fields update carries; the streaming client already fills most context of deltas
+func (s *orderState) merge(update saxo.OrderUpdate) {
+	if update.Uic != nil {
+		s.uic = *update.Uic
+	}
+	if update.Amount != nil {
+		s.amount = float64(*update.Amount)
+	}
+	if update.OrderPrice != 0 {
+		s.price = update.OrderPrice
+	}
+	setString(&s.ticker, update.Ticker)
+	setString(&s.symbol, update.Symbol)
+	setString(&s.buySell, update.BuySell)
+	setString(&s.accountKey, update.AccountKey)
+	setString(&s.orderType, update.OpenOrderType)
+}
+
+func setString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+// resolutionOf maps a terminal Saxo order status, "" for others
+func resolutionOf(status string) Resolution {
+	if state := saxo.ParseOrderState(status); state.IsTerminal() {
+		return Resolution(state)
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package fills
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func intPtr(value int) *int { return &value }
+
+func boolPtr(value bool) *bool { return &value }
+
+// drain returns the events published so far, once the forwarder has delivered them all
+func drain(ft *FillTracker) []FillEvent {
+	var events []FillEvent
+	for {
+		select {
+		case event := <-ft.Events():
+			events = append(events, event)
+		default:
+			ft.queueMu.Lock()
+			idle := !ft.forwarding
+			ft.queueMu.Unlock()
+			if idle && len(ft.events) == 0 {
+				return events
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestFillTracker_PartialFills(t *testing.T) {
+	ft := NewFillTracker(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "1", Status: "Working", OpenOrderType: "Limit", OrderPrice: 1.085,
+		Uic: intPtr(21), Amount: intPtr(1000), BuySell: "Buy", Symbol: "EURUSD", AccountKey: "acc-1", UpdatedAt: at})
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "1", Status: "Working", FilledSize: 300, UpdatedAt: at.Add(time.Second)})
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "1", Status: "Working", FilledSize: 300, UpdatedAt: at.Add(2 * time.Second)}) // Repeat
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "1", Status: "Filled", FilledSize: 1000, UpdatedAt: at.Add(3 * time.Second)})
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "1", MetaDeleted: boolPtr(true), UpdatedAt: at.Add(4 * time.Second)})
+
+	events := drain(ft)
+	if len(events) != 2 {
+		t.Fatalf("Expected one partial fill and one final fill, got %+v", events)
+	}
+	partial, final := events[0], events[1]
+	if partial.Quantity != 300 || partial.Cumulative != 300 || !partial.Partial() || partial.Resolution != "" {
+		t.Errorf("Unexpected partial fill %+v", partial)
+	}
+	if partial.EstimatedPrice != 1.085 || partial.PriceSource != "order" || partial.Uic != 21 || partial.Symbol != "EURUSD" || partial.AccountKey != "acc-1" {
+		t.Errorf("Expected the order's context and limit price, got %+v", partial)
+	}
+	if final.Quantity != 700 || final.Cumulative != 1000 || final.Resolution != ResolutionFilled || final.Partial() {
+		t.Errorf("Unexpected final fill %+v", final)
+	}
+}
+
+func TestFillTracker_Resolutions(t *testing.T) {
+	ft := NewFillTracker(nil)
+	clock := mocktesting.NewFakeClock(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	ft.SetClock(clock) // Stamps updates without UpdatedAt
+	ft.Seed([]saxo.LiveOrder{{OrderID: "m", Uic: 31, OrderType: "Market", BuySell: "Sell", Amount: 5000}})
+	ft.UpdatePrice(saxo.PriceUpdate{Uic: 31, Bid: 1.301, Ask: 1.302, Mid: 1.3015})
+
+	// Filled without FilledAmount fills the seeded amount at the bid
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "m", Status: "FinalFill"}) // Last fill of an order event
+	// Cancelled after a partial fill
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "c", Status: "Working", Uic: intPtr(21), Amount: intPtr(1000), BuySell: "Buy", OpenOrderType: "StopIfTraded", OrderPrice: 1.09})
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "c", FilledSize: 400})
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "c", Status: "Cancelled", FilledSize: 400})
+	// Expired and deleted without any fill
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "e", Status: "Working", Amount: intPtr(10)})
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "e", Status: "Expired"})
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "d", Status: "Working", Amount: intPtr(10)})
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "d", MetaDeleted: boolPtr(true)})
+	// Deletion of an order never seen is ignored
+	ft.ApplyOrder(saxo.OrderUpdate{OrderId: "x", MetaDeleted: boolPtr(true)})
+
+	events := drain(ft)
+	if len(events) != 5 {
+		t.Fatalf("Expected 5 events, got %+v", events)
+	}
+	if m := events[0]; m.Quantity != 5000 || m.Resolution != ResolutionFilled || m.EstimatedPrice != 1.301 || m.PriceSource != "quote" || !m.Time.Equal(clock.Now()) {
+		t.Errorf("Unexpected market fill %+v", m)
+	}
+	if c := events[1]; c.Quantity != 400 || c.EstimatedPrice != 1.09 || c.PriceSource != "order" {
+		t.Errorf("Expected the stop price without a quote, got %+v", c)
+	}
+	if c := events[2]; c.Quantity != 0 || c.Cumulative != 400 || c.Resolution != ResolutionCancelled {
+		t.Errorf("Unexpected cancellation %+v", c)
+	}
+	if e := events[3]; e.OrderId != "e" || e.Resolution != ResolutionExpired || e.EstimatedPrice != 0 {
+		t.Errorf("Unexpected expiry %+v", e)
+	}
+	if d := events[4]; d.OrderId != "d" || d.Resolution != ResolutionRemoved {
+		t.Errorf("Unexpected removal %+v", d)
+	}
+}
+
+func TestFillTracker_QueuesEventsUntilRead(t *testing.T) {
+	ft := NewFillTracker(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// More fills than the channel buffers, nobody reading
+	const fills = 200
+	for i := 1; i <= fills; i++ {
+		ft.ApplyOrder(saxo.OrderUpdate{OrderId: "1", Status: "Working", Amount: intPtr(fills), FilledSize: float64(i)})
+	}
+
+	events := drain(ft)
+	if len(events) != fills {
+		t.Fatalf("Expected %d events, got %d", fills, len(events))
+	}
+	for i, event := range events {
+		if event.Cumulative != float64(i+1) {
+			t.Fatalf("Expected events in order, event %d has cumulative %v", i, event.Cumulative)
+		}
+	}
+}
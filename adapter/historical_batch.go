@@ -0,0 +1,102 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// historyBatchConcurrency bounds the parallel chart requests of GetHistoricalDataBatch
+const historyBatchConcurrency = 4
+
+// historyBatchRetries is how often GetHistoricalDataBatch retries a request that failed into a rate-limit pause
+const historyBatchRetries = 2
+
+// HistoricalBatchResult is the outcome of one instrument of GetHistoricalDataBatch
+type HistoricalBatchResult struct {
+	Instrument Instrument
+	Data       []HistoricalDataPoint // nil when Err is set
+	Err        error
+}
+
+// GetHistoricalDataBatch runs GetHistoricalData (days daily bars up to the configured bar cutoff) for
+// many instruments, historyBatchConcurrency requests at a time
+// Cached series are returned without a request. The others wait while the client's rate limits
+// (RateLimits) report an exhausted dimension or a 429, and a request failing into such a pause is
+// retried once it ends. Results are in the order of instruments; failed instruments are joined into
+// the error as *HistoryDownloadError while the other results are still returned
+func (sbc *SaxoBrokerClient) GetHistoricalDataBatch(ctx context.Context, instruments []Instrument, days int) ([]HistoricalBatchResult, error) {
+	side, err := sbc.historicalPriceSide(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]HistoricalBatchResult, len(instruments))
+	errs := make([]error, len(instruments))
+	sem := make(chan struct{}, historyBatchConcurrency)
+	var wg sync.WaitGroup
+	var cached int
+	for i, instrument := range instruments {
+		results[i].Instrument = instrument
+		if data, ok := sbc.cachedHistory(historyCacheKey(sbc.enrichFromStore(instrument).Uic, days, side), days); ok {
+			results[i].Data = data
+			cached++
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i].Err = ctx.Err()
+				errs[i] = &HistoryDownloadError{Instrument: instrument, Err: ctx.Err()}
+				return
+			}
+			defer func() { <-sem }()
+
+			data, err := sbc.historyWithinRateLimits(ctx, instrument, days)
+			if err != nil {
+				results[i].Err = err
+				errs[i] = &HistoryDownloadError{Instrument: instrument, Err: err}
+				return
+			}
+			results[i].Data = data
+		}()
+	}
+	wg.Wait()
+
+	var failed int
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	sbc.logger.Info("Historical data batch finished",
+		"function", "GetHistoricalDataBatch",
+		"instruments", len(instruments),
+		"cached", cached,
+		"failed", failed,
+		"days", days)
+	return results, errors.Join(errs...)
+}
+
+// historyWithinRateLimits calls GetHistoricalData after any rate-limit pause, retrying a failure that
+// left one behind (typically a 429) up to historyBatchRetries times
+func (sbc *SaxoBrokerClient) historyWithinRateLimits(ctx context.Context, instrument Instrument, days int) ([]HistoricalDataPoint, error) {
+	for attempt := 0; ; attempt++ {
+		if err := sbc.waitRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		data, err := sbc.GetHistoricalData(ctx, instrument, days, time.Time{})
+		if err == nil || attempt == historyBatchRetries || ctx.Err() != nil || sbc.rateLimits.wait(sbc.clock.Now()) == 0 {
+			return data, err
+		}
+		sbc.logger.Warn("History request hit the rate limit, retrying",
+			"function", "GetHistoricalDataBatch",
+			"ticker", instrument.Ticker,
+			"attempt", attempt+1,
+			"error", err)
+	}
+}
@@ -0,0 +1,88 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestSaxoBrokerClient_GetHistoricalDataBatch(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	var inFlight, maxInFlight, requests atomic.Int32
+	mockServer.SetHandler("GET", "/chart/v3/charts", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if n <= peak || maxInFlight.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		count, _ := strconv.Atoi(r.URL.Query().Get("Count"))
+		uic, _ := strconv.Atoi(r.URL.Query().Get("Uic"))
+		end, _ := time.Parse(time.RFC3339, r.URL.Query().Get("Time"))
+		var data []SaxoChartData
+		for i := count; i > 0; i-- {
+			day := end.AddDate(0, 0, -i)
+			price := float64(uic)
+			data = append(data, SaxoChartData{Time: day.Format(time.RFC3339), Open: price, High: price, Low: price, Close: price})
+		}
+		json.NewEncoder(w).Encode(SaxoPriceResponse{Data: data})
+	})
+
+	clock := mocktesting.NewFakeClock(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	advanceClock(t, clock)
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), slog.New(slog.NewTextHandler(io.Discard, nil)), WithClock(clock))
+
+	var instruments []Instrument
+	for uic := 1; uic <= 8; uic++ {
+		instruments = append(instruments, Instrument{Ticker: "FX" + strconv.Itoa(uic), Uic: uic, AssetType: AssetTypeFxSpot})
+	}
+	instruments = append(instruments, Instrument{Ticker: "UNKNOWN", AssetType: AssetTypeFxSpot})
+
+	// FX1 is cached already; the first batch request is throttled with a 429 and retried
+	if _, err := client.GetHistoricalData(context.Background(), instruments[0], 5, time.Time{}); err != nil {
+		t.Fatalf("GetHistoricalData failed: %v", err)
+	}
+	mockServer.FailNext(http.StatusTooManyRequests)
+	results, err := client.GetHistoricalDataBatch(context.Background(), instruments, 5)
+
+	var downloadErr *HistoryDownloadError
+	if !errors.As(err, &downloadErr) || downloadErr.Instrument.Ticker != "UNKNOWN" {
+		t.Fatalf("Expected a HistoryDownloadError for UNKNOWN only, got %v", err)
+	}
+	if len(results) != len(instruments) {
+		t.Fatalf("Expected %d results, got %d", len(instruments), len(results))
+	}
+	for i, result := range results[:8] {
+		if result.Err != nil || len(result.Data) != 5 || result.Data[0].Close != float64(i+1) || result.Instrument.Ticker != instruments[i].Ticker {
+			t.Errorf("Unexpected result %d: %d bars, err %v", i, len(result.Data), result.Err)
+		}
+	}
+	if results[8].Err == nil || results[8].Data != nil {
+		t.Errorf("Expected UNKNOWN to fail, got %+v", results[8])
+	}
+
+	// 1 initial + 7 uncached (the 429 never reaches the handler); FX1 came from the cache
+	if got := requests.Load(); got != 8 {
+		t.Errorf("Expected 8 chart requests, got %d", got)
+	}
+	if peak := maxInFlight.Load(); peak > historyBatchConcurrency || peak < 2 {
+		t.Errorf("Expected 2..%d parallel requests, got %d", historyBatchConcurrency, peak)
+	}
+}
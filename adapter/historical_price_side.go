@@ -0,0 +1,65 @@
+package saxo
+
+import (
+	"context"
+	"fmt"
+)
+
+// PriceSide selects which series of bid/ask quoted chart data (FX, CFDs) fills the primary
+// Open/High/Low/Close of HistoricalDataPoint. Traded asset types have a single series and ignore it
+type PriceSide string
+
+const (
+	PriceSideMid PriceSide = "Mid" // (bid+ask)/2 - the default
+	PriceSideBid PriceSide = "Bid"
+	PriceSideAsk PriceSide = "Ask"
+)
+
+// OHLC is one bar of a single price series
+type OHLC struct {
+	Open  float64
+	High  float64
+	Low   float64
+	Close float64
+}
+
+// WithHistoricalPriceSide sets the default primary series of GetHistoricalData (REST client only)
+// "" or PriceSideMid keeps mid prices; WithPriceSide overrides it per call
+func WithHistoricalPriceSide(side PriceSide) Option {
+	return func(o *ClientOptions) {
+		o.HistoricalPriceSide = side
+	}
+}
+
+type priceSideKey struct{}
+
+// WithPriceSide makes GetHistoricalData calls made with the returned ctx use side as the primary OHLC
+func WithPriceSide(ctx context.Context, side PriceSide) context.Context {
+	return context.WithValue(ctx, priceSideKey{}, side)
+}
+
+// historicalPriceSide resolves the primary series of a GetHistoricalData call: ctx, then client default, then mid
+func (sbc *SaxoBrokerClient) historicalPriceSide(ctx context.Context) (PriceSide, error) {
+	side, _ := ctx.Value(priceSideKey{}).(PriceSide)
+	if side == "" {
+		side = sbc.priceSide
+	}
+	switch side {
+	case "":
+		return PriceSideMid, nil
+	case PriceSideMid, PriceSideBid, PriceSideAsk:
+		return side, nil
+	}
+	return "", fmt.Errorf("unknown price side %q (supported: %s, %s, %s)", side, PriceSideMid, PriceSideBid, PriceSideAsk)
+}
+
+// pick returns the series of side
+func (side PriceSide) pick(bid, ask, mid OHLC) OHLC {
+	switch side {
+	case PriceSideBid:
+		return bid
+	case PriceSideAsk:
+		return ask
+	}
+	return mid
+}
@@ -0,0 +1,72 @@
+package saxo
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSaxoBrokerClient_HistoricalDataPriceSide(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithHistoricalPriceSide(PriceSideBid))
+
+	mockServer.SetResponse("GET", "/chart/v3/charts", http.StatusOK, SaxoPriceResponse{Data: []SaxoChartData{{
+		Time:    "2026-01-05T00:00:00Z",
+		OpenBid: 1.1000, OpenAsk: 1.1002, HighBid: 1.1100, HighAsk: 1.1102,
+		LowBid: 1.0900, LowAsk: 1.0902, CloseBid: 1.1050, CloseAsk: 1.1052,
+	}}})
+	fx := Instrument{Ticker: "EURUSD", Uic: 21, AssetType: AssetTypeFxSpot}
+	cutoff := time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		wantSide  PriceSide
+		wantClose float64
+	}{
+		{"client default", context.Background(), PriceSideBid, 1.1050},
+		{"ask", WithPriceSide(context.Background(), PriceSideAsk), PriceSideAsk, 1.1052},
+		{"mid", WithPriceSide(context.Background(), PriceSideMid), PriceSideMid, 1.1051},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := client.GetHistoricalData(tt.ctx, fx, 1, cutoff)
+			if err != nil {
+				t.Fatalf("GetHistoricalData failed: %v", err)
+			}
+			point := data[0]
+			if point.Side != tt.wantSide || point.Close != tt.wantClose {
+				t.Errorf("Expected %s close %v, got %s %v", tt.wantSide, tt.wantClose, point.Side, point.Close)
+			}
+			if point.Bid == nil || point.Ask == nil || point.Mid == nil {
+				t.Fatalf("Expected all series, got %+v", point)
+			}
+			if point.Bid.High != 1.1100 || point.Ask.Low != 1.0902 || point.Mid.Open != 1.1001 {
+				t.Errorf("Unexpected series bid %+v ask %+v mid %+v", *point.Bid, *point.Ask, *point.Mid)
+			}
+		})
+	}
+
+	if _, err := client.GetHistoricalData(WithPriceSide(context.Background(), "Last"), fx, 1, cutoff); err == nil {
+		t.Error("Expected an error for an unknown price side")
+	}
+
+	// Traded asset types have a single series
+	mockServer.SetResponse("GET", "/chart/v3/charts", http.StatusOK, SaxoPriceResponse{Data: []SaxoChartData{{
+		Time: "2026-01-05T00:00:00Z", Open: 100, High: 110, Low: 90, Close: 105, Volume: 5000,
+	}}})
+	data, err := client.GetHistoricalData(context.Background(), Instrument{Ticker: "ES", Uic: 4916, AssetType: AssetTypeContractFutures}, 1, cutoff)
+	if err != nil {
+		t.Fatalf("GetHistoricalData failed: %v", err)
+	}
+	if point := data[0]; point.Close != 105 || point.Bid != nil || point.Side != "" {
+		t.Errorf("Expected traded close without bid/ask series, got %+v", point)
+	}
+}
@@ -0,0 +1,275 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MaxChartCount is the most bars Saxo returns per /chart/v3/charts request
+const MaxChartCount = 1200
+
+// HistoryDownloadConfig configures a HistoryDownloader; zero values use the noted defaults
+type HistoryDownloadConfig struct {
+	Horizon     int           // Bar length in minutes, default 1440 (daily)
+	ChunkSize   int           // Bars per request, default and maximum MaxChartCount
+	MinInterval time.Duration // Minimum gap between requests, default 500ms
+	MaxRetries  int           // Attempts after a failed chunk, default 3
+	RetryDelay  time.Duration // Wait before the first retry, doubled per attempt, default 2s
+	Side        PriceSide     // Primary OHLC of bid/ask quoted data, "" = the client's (WithHistoricalPriceSide)
+
+	// OnProgress is called on the downloading goroutine after every chunk, retry and finished instrument
+	OnProgress func(HistoryProgress)
+}
+
+// HistoryProgress reports the state of one instrument of a download
+type HistoryProgress struct {
+	Instrument  Instrument
+	Index       int       // Position of Instrument in the download, from 0
+	Instruments int       // Instruments in the download
+	Chunks      int       // Chunks written for Instrument so far
+	Bars        int       // Bars written for Instrument so far
+	Through     time.Time // Time of the last bar written
+	Retry       int       // Attempt number when a failed chunk is retried, 0 otherwise
+	Done        bool      // Instrument finished - Err set when it failed
+	Err         error
+}
+
+// HistorySink receives downloaded bars: one call per chunk, in time order per instrument
+// An error aborts the download
+type HistorySink interface {
+	WriteBars(ctx context.Context, instrument Instrument, bars []HistoricalDataPoint) error
+}
+
+// HistorySinkFunc adapts a function to HistorySink
+type HistorySinkFunc func(ctx context.Context, instrument Instrument, bars []HistoricalDataPoint) error
+
+func (f HistorySinkFunc) WriteBars(ctx context.Context, instrument Instrument, bars []HistoricalDataPoint) error {
+	return f(ctx, instrument, bars)
+}
+
+// HistoryDownloadResult summarizes a download
+type HistoryDownloadResult struct {
+	Bars     int          // Bars written to the sink
+	Requests int          // Chart requests sent, retries included
+	Retries  int          // Failed requests that were retried
+	Failed   []Instrument // Instruments given up on after MaxRetries
+}
+
+// HistoryDownloadError reports an instrument given up on; the other instruments were still downloaded
+type HistoryDownloadError struct {
+	Instrument Instrument
+	Err        error
+}
+
+func (e *HistoryDownloadError) Error() string {
+	return fmt.Sprintf("history of %s (uic %d): %v", e.Instrument.Ticker, e.Instrument.Uic, e.Err)
+}
+
+func (e *HistoryDownloadError) Unwrap() error {
+	return e.Err
+}
+
+// HistoryDownloader fetches long bar histories for many instruments, e.g. to build a backtest dataset
+// Requests go out one at a time, at least MinInterval apart, and pause while Saxo's rate-limit
+// headers report an exhausted dimension or a 429 asked to retry later (see RateLimits)
+type HistoryDownloader struct {
+	client *SaxoBrokerClient
+	sink   HistorySink
+	config HistoryDownloadConfig
+
+	lastRequest time.Time
+}
+
+// NewHistoryDownloader creates a downloader writing to sink
+func NewHistoryDownloader(client *SaxoBrokerClient, sink HistorySink, config HistoryDownloadConfig) *HistoryDownloader {
+	if config.Horizon <= 0 {
+		config.Horizon = 1440
+	}
+	if config.ChunkSize <= 0 || config.ChunkSize > MaxChartCount {
+		config.ChunkSize = MaxChartCount
+	}
+	if config.MinInterval <= 0 {
+		config.MinInterval = 500 * time.Millisecond
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = 3
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = 2 * time.Second
+	}
+	return &HistoryDownloader{client: client, sink: sink, config: config}
+}
+
+// Download writes the bars of every instrument from from (inclusive) to to (exclusive) to the sink
+// A failed instrument is retried per chunk, then skipped and reported as *HistoryDownloadError;
+// a sink error or a cancelled ctx stops the whole download
+func (d *HistoryDownloader) Download(ctx context.Context, instruments []Instrument, from, to time.Time) (HistoryDownloadResult, error) {
+	var result HistoryDownloadResult
+	if !to.After(from) {
+		return result, fmt.Errorf("empty range %s - %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+	if !d.client.authClient.IsAuthenticated() {
+		return result, d.client.notAuthenticated()
+	}
+	side, err := d.client.historicalPriceSide(WithPriceSide(ctx, d.config.Side))
+	if err != nil {
+		return result, err
+	}
+
+	d.client.logger.Info("Downloading history",
+		"function", "HistoryDownloader.Download",
+		"instruments", len(instruments),
+		"from", from.Format(time.RFC3339),
+		"to", to.Format(time.RFC3339),
+		"horizon", d.config.Horizon)
+
+	var errs []error
+	for i, instrument := range instruments {
+		progress := HistoryProgress{Instrument: instrument, Index: i, Instruments: len(instruments)}
+		err := d.downloadInstrument(ctx, &progress, from, to, side, &result)
+		if err != nil && (ctx.Err() != nil || errors.Is(err, errSinkFailed)) {
+			return result, err
+		}
+		if err != nil {
+			result.Failed = append(result.Failed, instrument)
+			errs = append(errs, &HistoryDownloadError{Instrument: instrument, Err: err})
+			d.client.logger.Warn("History download failed",
+				"function", "HistoryDownloader.Download",
+				"ticker", instrument.Ticker,
+				"bars", progress.Bars,
+				"error", err)
+		}
+		progress.Done, progress.Retry, progress.Err = true, 0, err
+		d.report(progress)
+	}
+
+	d.client.logger.Info("History download finished",
+		"function", "HistoryDownloader.Download",
+		"bars", result.Bars,
+		"requests", result.Requests,
+		"retries", result.Retries,
+		"failed", len(result.Failed))
+	return result, errors.Join(errs...)
+}
+
+// errSinkFailed marks sink errors, which end the download instead of skipping the instrument
+var errSinkFailed = errors.New("history sink failed")
+
+// downloadInstrument pages through one instrument's range, ChunkSize bars per request
+func (d *HistoryDownloader) downloadInstrument(ctx context.Context, progress *HistoryProgress, from, to time.Time, side PriceSide, result *HistoryDownloadResult) error {
+	instrument := d.client.enrichFromStore(progress.Instrument)
+	if instrument.Uic == 0 {
+		instrument.Uic = instrument.Identifier
+	}
+	if instrument.Uic == 0 || instrument.AssetType == "" {
+		return fmt.Errorf("instrument %s not enriched: UIC and asset type are required", instrument.Ticker)
+	}
+
+	cursor := from
+	for {
+		query := chartQuery{Horizon: d.config.Horizon, Count: d.config.ChunkSize, Mode: "From", Time: cursor}
+		bars, err := d.fetchWithRetry(ctx, instrument, query, side, progress, result)
+		if err != nil {
+			return err
+		}
+
+		// Mode=From includes the bar at cursor, already written with the previous chunk
+		done := len(bars) < query.Count // Saxo has no more
+		fresh := make([]HistoricalDataPoint, 0, len(bars))
+		for _, bar := range bars {
+			switch {
+			case !bar.Time.Before(to):
+				done = true // The range ends inside this chunk
+			case bar.Time.Before(cursor), progress.Chunks > 0 && !bar.Time.After(cursor):
+			default:
+				fresh = append(fresh, bar)
+			}
+		}
+		if len(fresh) == 0 {
+			return nil
+		}
+
+		if err := d.sink.WriteBars(ctx, instrument, fresh); err != nil {
+			return fmt.Errorf("%w: %w", errSinkFailed, err)
+		}
+		cursor = fresh[len(fresh)-1].Time
+		progress.Chunks++
+		progress.Bars += len(fresh)
+		progress.Through = cursor
+		progress.Retry, progress.Err = 0, nil
+		result.Bars += len(fresh)
+		d.report(*progress)
+
+		if done {
+			return nil
+		}
+	}
+}
+
+// fetchWithRetry requests one chunk, retrying failures with exponential backoff up to MaxRetries
+func (d *HistoryDownloader) fetchWithRetry(ctx context.Context, instrument Instrument, query chartQuery, side PriceSide, progress *HistoryProgress, result *HistoryDownloadResult) ([]HistoricalDataPoint, error) {
+	delay := d.config.RetryDelay
+	for attempt := 0; ; attempt++ {
+		if err := d.pace(ctx); err != nil {
+			return nil, err
+		}
+		result.Requests++
+		bars, err := d.client.fetchChart(ctx, instrument, query, side)
+		if err == nil {
+			return bars, nil
+		}
+		if ctx.Err() != nil || attempt == d.config.MaxRetries {
+			return nil, err
+		}
+
+		result.Retries++
+		progress.Retry, progress.Err = attempt+1, err
+		d.report(*progress)
+		d.client.logger.Warn("History chunk failed, retrying",
+			"function", "HistoryDownloader.fetchWithRetry",
+			"ticker", instrument.Ticker,
+			"from", query.Time.Format(time.RFC3339),
+			"attempt", attempt+1,
+			"delay", delay,
+			"error", err)
+		if err := d.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		delay *= 2
+	}
+}
+
+// pace waits for MinInterval since the previous request and for any rate-limit pause Saxo reported
+func (d *HistoryDownloader) pace(ctx context.Context) error {
+	now := d.client.clock.Now()
+	wait := d.client.rateLimits.wait(now)
+	if !d.lastRequest.IsZero() {
+		wait = max(wait, d.lastRequest.Add(d.config.MinInterval).Sub(now))
+	}
+	if err := d.sleep(ctx, wait); err != nil {
+		return err
+	}
+	d.lastRequest = d.client.clock.Now()
+	return nil
+}
+
+// sleep waits d on the client clock, returning early with ctx's error
+func (d *HistoryDownloader) sleep(ctx context.Context, wait time.Duration) error {
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-d.client.clock.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *HistoryDownloader) report(progress HistoryProgress) {
+	if d.config.OnProgress != nil {
+		d.config.OnProgress(progress)
+	}
+}
@@ -0,0 +1,168 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+// serveDailyBars answers chart requests in Mode=From with one daily bar per day from first to last,
+// reporting the ChartMinute rate limit as exhausted on the first response
+func serveDailyBars(t *testing.T, mockServer *MockSaxoServer, first, last time.Time) {
+	t.Helper()
+	var requests atomic.Int32
+	mockServer.SetHandler("GET", "/chart/v3/charts", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		from, err := time.Parse(time.RFC3339, query.Get("Time"))
+		if err != nil || query.Get("Mode") != "From" || query.Get("Horizon") != "1440" {
+			http.Error(w, "bad query", http.StatusBadRequest)
+			return
+		}
+		count, _ := strconv.Atoi(query.Get("Count"))
+
+		var data []SaxoChartData
+		day := from
+		if day.Before(first) {
+			day = first
+		}
+		for ; !day.After(last) && len(data) < count; day = day.AddDate(0, 0, 1) {
+			price := float64(day.Day())
+			data = append(data, SaxoChartData{Time: day.Format(time.RFC3339), Open: price, High: price, Low: price, Close: price})
+		}
+
+		remaining := 0
+		if requests.Add(1) > 1 {
+			remaining = 10
+		}
+		w.Header().Set("X-RateLimit-ChartMinute-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("X-RateLimit-ChartMinute-Reset", "60")
+		json.NewEncoder(w).Encode(SaxoPriceResponse{Data: data})
+	})
+}
+
+// advanceClock keeps firing clock's timers until the test ends
+func advanceClock(t *testing.T, clock *mocktesting.FakeClock) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(stop)
+		<-done
+	})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Millisecond):
+				if clock.Waiters() > 0 {
+					clock.Advance(time.Second)
+				}
+			}
+		}
+	}()
+}
+
+func TestHistoryDownloader_Download(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	serveDailyBars(t, mockServer, first, time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC))
+
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	clock := mocktesting.NewFakeClock(start)
+	advanceClock(t, clock)
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), slog.New(slog.NewTextHandler(io.Discard, nil)), WithClock(clock))
+
+	written := make(map[string][]HistoricalDataPoint)
+	var progress []HistoryProgress
+	sink := HistorySinkFunc(func(ctx context.Context, instrument Instrument, bars []HistoricalDataPoint) error {
+		written[instrument.Ticker] = append(written[instrument.Ticker], bars...)
+		return nil
+	})
+	downloader := NewHistoryDownloader(client, sink, HistoryDownloadConfig{
+		ChunkSize:  7,
+		RetryDelay: time.Second,
+		OnProgress: func(p HistoryProgress) { progress = append(progress, p) },
+	})
+
+	// EURUSD fails once with 429, US500 has no UIC
+	instruments := []Instrument{
+		{Ticker: "EURUSD", Uic: 21, AssetType: AssetTypeFxSpot},
+		{Ticker: "US500", AssetType: AssetTypeCfdOnIndex},
+	}
+	mockServer.FailNext(http.StatusTooManyRequests)
+	result, err := downloader.Download(context.Background(), instruments, first.AddDate(0, 0, 2), time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC))
+
+	var downloadErr *HistoryDownloadError
+	if !errors.As(err, &downloadErr) || downloadErr.Instrument.Ticker != "US500" {
+		t.Fatalf("Expected a HistoryDownloadError for US500, got %v", err)
+	}
+	bars := written["EURUSD"]
+	if len(bars) != 17 || bars[0].Time.Day() != 3 || bars[len(bars)-1].Time.Day() != 19 {
+		t.Fatalf("Expected Jan 3-19 without gaps or duplicates, got %d bars", len(bars))
+	}
+	for i := 1; i < len(bars); i++ {
+		if !bars[i].Time.After(bars[i-1].Time) {
+			t.Fatalf("Bars out of order at %d: %v after %v", i, bars[i].Time, bars[i-1].Time)
+		}
+	}
+	if result.Bars != 17 || result.Retries != 1 || len(result.Failed) != 1 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	// The 429 and the exhausted ChartMinute dimension both paused the download
+	if elapsed := clock.Now().Sub(start); elapsed < 60*time.Second {
+		t.Errorf("Expected the download to wait for the rate-limit reset, only %v passed", elapsed)
+	}
+	limits := client.RateLimits()
+	if len(limits) != 1 || limits[0].Dimension != "Chartminute" || limits[0].Remaining != 10 {
+		t.Errorf("Unexpected rate limits %+v", limits)
+	}
+
+	last := progress[len(progress)-1]
+	if !last.Done || last.Instrument.Ticker != "US500" || last.Err == nil || last.Index != 1 || last.Instruments != 2 {
+		t.Errorf("Unexpected final progress %+v", last)
+	}
+	var retried, finished bool
+	for _, p := range progress {
+		retried = retried || (p.Retry == 1 && p.Err != nil)
+		finished = finished || (p.Done && p.Instrument.Ticker == "EURUSD" && p.Err == nil && p.Bars == 17 && p.Through.Day() == 19)
+	}
+	if !retried || !finished {
+		t.Errorf("Expected retry and completion reports, got %+v", progress)
+	}
+}
+
+func TestHistoryDownloader_SinkErrorStops(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	serveDailyBars(t, mockServer, first, first.AddDate(0, 0, 5))
+
+	clock := mocktesting.NewFakeClock(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	advanceClock(t, clock)
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), slog.New(slog.NewTextHandler(io.Discard, nil)), WithClock(clock))
+
+	diskFull := errors.New("disk full")
+	downloader := NewHistoryDownloader(client, HistorySinkFunc(func(context.Context, Instrument, []HistoricalDataPoint) error {
+		return diskFull
+	}), HistoryDownloadConfig{})
+
+	instruments := []Instrument{{Ticker: "EURUSD", Uic: 21, AssetType: AssetTypeFxSpot}, {Ticker: "GBPUSD", Uic: 31, AssetType: AssetTypeFxSpot}}
+	result, err := downloader.Download(context.Background(), instruments, first, first.AddDate(0, 0, 10))
+	if !errors.Is(err, diskFull) || result.Requests != 1 {
+		t.Errorf("Expected the sink error after one request, got %v (%+v)", err, result)
+	}
+}
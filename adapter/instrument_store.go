@@ -0,0 +1,405 @@
+package saxo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// InstrumentMetadata is what InstrumentStore knows about one UIC
+type InstrumentMetadata struct {
+	Uic         int       `json:"uic"`
+	Ticker      string    `json:"ticker"` // Application ticker, e.g. "EURUSD" or "6E", unique within a store
+	Symbol      string    `json:"symbol"` // Saxo symbol, e.g. "EURUSD" or "6EZ6"
+	Description string    `json:"description"`
+	AssetType   string    `json:"asset_type"`
+	Exchange    string    `json:"exchange,omitempty"`
+	Currency    string    `json:"currency,omitempty"`
+	TickSize    float64   `json:"tick_size,omitempty"`
+	Decimals    int       `json:"decimals,omitempty"`
+	ExpiryDate  time.Time `json:"expiry_date,omitempty"` // Zero for instruments without expiry
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Instrument converts the metadata into an enriched Instrument
+func (m InstrumentMetadata) Instrument() Instrument {
+	return Instrument{
+		Ticker:      m.Ticker,
+		Exchange:    m.Exchange,
+		AssetType:   m.AssetType,
+		Identifier:  m.Uic,
+		Uic:         m.Uic,
+		Symbol:      m.Symbol,
+		Description: m.Description,
+		Currency:    m.Currency,
+		TickSize:    m.TickSize,
+		Decimals:    m.Decimals,
+	}
+}
+
+// InstrumentChange is delivered to InstrumentStore listeners
+// Previous is nil for new instruments, Current is nil for deleted ones
+type InstrumentChange struct {
+	Uic      int
+	Previous *InstrumentMetadata
+	Current  *InstrumentMetadata
+}
+
+// InstrumentListener receives InstrumentStore changes, synchronously after the store is updated
+type InstrumentListener func(change InstrumentChange)
+
+type namedInstrumentListener struct {
+	name     string
+	listener InstrumentListener
+}
+
+// InstrumentStore is the UIC <-> ticker and constraint mapping shared by the REST client, the
+// WebSocket client and application enrichment. Safe for concurrent use.
+// Pass one store to both clients with WithInstrumentStore
+type InstrumentStore struct {
+	path      string // "" = memory only
+	logger    *slog.Logger
+	persistMu sync.Mutex // Serializes file writes
+
+	mu       sync.RWMutex
+	clock    Clock // Stamps UpdatedAt (SetClock)
+	byUic    map[int]InstrumentMetadata
+	byTicker map[string]int // Upper-cased ticker -> UIC
+
+	listenersMu sync.Mutex
+	listeners   map[int]namedInstrumentListener
+	nextID      int
+}
+
+// NewInstrumentStore creates an empty in-memory store
+func NewInstrumentStore(logger *slog.Logger) *InstrumentStore {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &InstrumentStore{
+		logger:    logger,
+		clock:     SystemClock,
+		byUic:     make(map[int]InstrumentMetadata),
+		byTicker:  make(map[string]int),
+		listeners: make(map[int]namedInstrumentListener),
+	}
+}
+
+// OpenInstrumentStore loads the store persisted at path (a missing file is an empty store)
+// and writes it back to path after every change
+func OpenInstrumentStore(path string, logger *slog.Logger) (*InstrumentStore, error) {
+	store := NewInstrumentStore(logger)
+	store.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read instrument store: %w", err)
+	}
+	var instruments []InstrumentMetadata
+	if err := json.Unmarshal(data, &instruments); err != nil {
+		return nil, fmt.Errorf("failed to parse instrument store %s: %w", path, err)
+	}
+	for _, meta := range instruments {
+		store.index(meta)
+	}
+
+	store.logger.Info("Instrument store loaded",
+		"function", "OpenInstrumentStore",
+		"path", path,
+		"count", len(instruments))
+	return store, nil
+}
+
+// SetClock replaces SystemClock for UpdatedAt stamps, e.g. with the clients' WithClock clock
+func (s *InstrumentStore) SetClock(clock Clock) {
+	if clock == nil {
+		clock = SystemClock
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clock = clock
+}
+
+// now reads the store's clock
+func (s *InstrumentStore) now() time.Time {
+	s.mu.RLock()
+	clock := s.clock
+	s.mu.RUnlock()
+	return clock.Now()
+}
+
+// Put stores meta, replacing what is known about meta.Uic
+// A ticker already used by another UIC moves to meta.Uic; listeners get a change clearing the
+// other UIC's ticker before the change for meta.Uic
+func (s *InstrumentStore) Put(meta InstrumentMetadata) error {
+	if meta.Uic == 0 {
+		return fmt.Errorf("instrument metadata requires a UIC")
+	}
+	if meta.UpdatedAt.IsZero() {
+		meta.UpdatedAt = s.now()
+	}
+	return s.update(meta.Uic, func(InstrumentMetadata, bool) (InstrumentMetadata, bool) {
+		return meta, true
+	})
+}
+
+// MergeDetail copies tick size, decimals and expiry from a GetInstrumentDetails result
+// Unknown UICs are added without ticker; known ones keep their ticker and names (missing names are filled)
+func (s *InstrumentStore) MergeDetail(detail InstrumentDetail) error {
+	if detail.Uic == 0 {
+		return fmt.Errorf("instrument detail requires a UIC")
+	}
+	return s.update(detail.Uic, func(meta InstrumentMetadata, exists bool) (InstrumentMetadata, bool) {
+		namesKnown := (meta.Symbol != "" || detail.Symbol == "") && (meta.Description != "" || detail.Description == "") &&
+			(meta.AssetType != "" || detail.AssetType == "")
+		if exists && namesKnown && meta.TickSize == detail.TickSize && meta.Decimals == detail.Decimals && meta.ExpiryDate.Equal(detail.ExpiryDate) {
+			return meta, false
+		}
+		merged := meta
+		merged.Uic = detail.Uic
+		merged.TickSize = detail.TickSize
+		merged.Decimals = detail.Decimals
+		merged.ExpiryDate = detail.ExpiryDate
+		if merged.Symbol == "" {
+			merged.Symbol = detail.Symbol
+		}
+		if merged.Description == "" {
+			merged.Description = detail.Description
+		}
+		if merged.AssetType == "" {
+			merged.AssetType = detail.AssetType
+		}
+		merged.UpdatedAt = s.clock.Now() // fn runs under s.mu
+		return merged, true
+	})
+}
+
+// Delete removes uic from the store
+func (s *InstrumentStore) Delete(uic int) error {
+	s.mu.Lock()
+	previous, ok := s.byUic[uic]
+	if ok {
+		delete(s.byUic, uic)
+		if previous.Ticker != "" {
+			delete(s.byTicker, strings.ToUpper(previous.Ticker))
+		}
+	}
+	s.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	err := s.persist()
+	s.notify(InstrumentChange{Uic: uic, Previous: &previous})
+	return err
+}
+
+// Get returns the metadata stored for uic
+func (s *InstrumentStore) Get(uic int) (InstrumentMetadata, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	meta, ok := s.byUic[uic]
+	return meta, ok
+}
+
+// Lookup returns the metadata stored for ticker (case-insensitive)
+func (s *InstrumentStore) Lookup(ticker string) (InstrumentMetadata, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	uic, ok := s.byTicker[strings.ToUpper(ticker)]
+	if !ok {
+		return InstrumentMetadata{}, false
+	}
+	return s.byUic[uic], true
+}
+
+// Ticker returns the ticker of uic, "" if unknown
+func (s *InstrumentStore) Ticker(uic int) string {
+	meta, _ := s.Get(uic)
+	return meta.Ticker
+}
+
+// All returns every stored instrument, sorted by UIC
+func (s *InstrumentStore) All() []InstrumentMetadata {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	all := make([]InstrumentMetadata, 0, len(s.byUic))
+	for _, meta := range s.byUic {
+		all = append(all, meta)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Uic < all[j].Uic })
+	return all
+}
+
+// Enrich fills a missing UIC, asset type and constraints of instrument from the store (matched by ticker,
+// or by UIC when set) and reports whether it was found. Fields already set are kept
+func (s *InstrumentStore) Enrich(instrument Instrument) (Instrument, bool) {
+	uic := instrument.Uic
+	if uic == 0 {
+		uic = instrument.Identifier
+	}
+	meta, ok := s.Get(uic)
+	if !ok || uic == 0 {
+		if meta, ok = s.Lookup(instrument.Ticker); !ok {
+			return instrument, false
+		}
+	}
+
+	stored := meta.Instrument()
+	if instrument.Uic == 0 {
+		instrument.Uic = stored.Uic
+	}
+	if instrument.Identifier == 0 {
+		instrument.Identifier = stored.Identifier
+	}
+	if instrument.Ticker == "" {
+		instrument.Ticker = stored.Ticker
+	}
+	if instrument.AssetType == "" {
+		instrument.AssetType = stored.AssetType
+	}
+	if instrument.Exchange == "" {
+		instrument.Exchange = stored.Exchange
+	}
+	if instrument.Symbol == "" {
+		instrument.Symbol = stored.Symbol
+	}
+	if instrument.Description == "" {
+		instrument.Description = stored.Description
+	}
+	if instrument.Currency == "" {
+		instrument.Currency = stored.Currency
+	}
+	if instrument.TickSize == 0 {
+		instrument.TickSize = stored.TickSize
+	}
+	if instrument.Decimals == 0 {
+		instrument.Decimals = stored.Decimals
+	}
+	return instrument, true
+}
+
+// Subscribe registers listener for store changes
+// name is only used for logging. The returned func unsubscribes and is safe to call more than once
+func (s *InstrumentStore) Subscribe(name string, listener InstrumentListener) (unsubscribe func()) {
+	s.listenersMu.Lock()
+	id := s.nextID
+	s.nextID++
+	s.listeners[id] = namedInstrumentListener{name: name, listener: listener}
+	s.listenersMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.listenersMu.Lock()
+			delete(s.listeners, id)
+			s.listenersMu.Unlock()
+		})
+	}
+}
+
+// update applies fn to the current metadata of uic; fn returns false to leave the store unchanged
+func (s *InstrumentStore) update(uic int, fn func(current InstrumentMetadata, exists bool) (InstrumentMetadata, bool)) error {
+	s.mu.Lock()
+	previous, existed := s.byUic[uic]
+	next, changed := fn(previous, existed)
+	if !changed {
+		s.mu.Unlock()
+		return nil
+	}
+	if existed && previous.Ticker != "" && !strings.EqualFold(previous.Ticker, next.Ticker) {
+		delete(s.byTicker, strings.ToUpper(previous.Ticker))
+	}
+	displaced := s.index(next)
+	s.mu.Unlock()
+
+	err := s.persist()
+	if displaced != nil {
+		s.logger.Info("Ticker moved to another UIC",
+			"function", "InstrumentStore.update",
+			"ticker", next.Ticker,
+			"from_uic", displaced.Uic,
+			"to_uic", uic)
+		s.notify(*displaced)
+	}
+	change := InstrumentChange{Uic: uic, Current: &next}
+	if existed {
+		change.Previous = &previous
+	}
+	s.notify(change)
+	return err
+}
+
+// index adds meta to both maps; a ticker held by another UIC is taken over and the returned
+// change clears it there (nil if none). Caller holds mu (or owns s)
+func (s *InstrumentStore) index(meta InstrumentMetadata) *InstrumentChange {
+	var displaced *InstrumentChange
+	if meta.Ticker != "" {
+		key := strings.ToUpper(meta.Ticker)
+		if owner, ok := s.byTicker[key]; ok && owner != meta.Uic {
+			previous := s.byUic[owner]
+			current := previous
+			current.Ticker = ""
+			current.UpdatedAt = meta.UpdatedAt
+			s.byUic[owner] = current
+			displaced = &InstrumentChange{Uic: owner, Previous: &previous, Current: &current}
+		}
+		s.byTicker[key] = meta.Uic
+	}
+	s.byUic[meta.Uic] = meta
+	return displaced
+}
+
+// persist writes the store to path via a temporary file, so a crash never leaves a truncated store
+func (s *InstrumentStore) persist() error {
+	if s.path == "" {
+		return nil
+	}
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+
+	data, err := json.MarshalIndent(s.All(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal instrument store: %w", err)
+	}
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create instrument store directory: %w", err)
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write instrument store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace instrument store: %w", err)
+	}
+	return nil
+}
+
+func (s *InstrumentStore) notify(change InstrumentChange) {
+	s.listenersMu.Lock()
+	listeners := make([]namedInstrumentListener, 0, len(s.listeners))
+	for _, l := range s.listeners {
+		listeners = append(listeners, l)
+	}
+	s.listenersMu.Unlock()
+
+	for _, l := range listeners {
+		s.logger.Debug("Notifying instrument listener",
+			"function", "InstrumentStore.notify",
+			"listener", l.name,
+			"uic", change.Uic)
+		l.listener(change)
+	}
+}
@@ -0,0 +1,128 @@
+package saxo
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInstrumentStore_MappingPersistenceAndNotifications(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "instruments.json")
+	store, err := OpenInstrumentStore(path, nil)
+	if err != nil {
+		t.Fatalf("OpenInstrumentStore on missing file: %v", err)
+	}
+
+	var changes []InstrumentChange
+	unsubscribe := store.Subscribe("test", func(change InstrumentChange) {
+		changes = append(changes, change)
+	})
+
+	store.Put(InstrumentMetadata{Uic: 21, Ticker: "EURUSD", AssetType: "FxSpot"})
+	store.Put(InstrumentMetadata{Uic: 3001, Ticker: "6E", AssetType: "ContractFutures"})
+	if meta, ok := store.Lookup("eurusd"); !ok || meta.Uic != 21 {
+		t.Errorf("Expected case-insensitive ticker lookup, got %+v %v", meta, ok)
+	}
+
+	// Roll: the ticker moves to the new contract and the old one keeps no ticker
+	store.Put(InstrumentMetadata{Uic: 3002, Ticker: "6E", AssetType: "ContractFutures"})
+	if meta, _ := store.Lookup("6E"); meta.Uic != 3002 {
+		t.Errorf("Expected 6E on 3002 after the roll, got %d", meta.Uic)
+	}
+	if ticker := store.Ticker(3001); ticker != "" {
+		t.Errorf("Expected expired contract without ticker, got %q", ticker)
+	}
+
+	expiry := time.Date(2026, 12, 14, 0, 0, 0, 0, time.UTC)
+	store.MergeDetail(InstrumentDetail{Uic: 3002, TickSize: 0.00005, Decimals: 5, ExpiryDate: expiry})
+	store.MergeDetail(InstrumentDetail{Uic: 3002, TickSize: 0.00005, Decimals: 5, ExpiryDate: expiry}) // unchanged, no event
+	if meta, _ := store.Get(3002); meta.Ticker != "6E" || meta.TickSize != 0.00005 || !meta.ExpiryDate.Equal(expiry) {
+		t.Errorf("Expected details merged into 6E, got %+v", meta)
+	}
+
+	store.Delete(21)
+	unsubscribe()
+	store.Put(InstrumentMetadata{Uic: 31, Ticker: "GBPUSD"})
+
+	if len(changes) != 6 {
+		t.Fatalf("Expected 6 changes (3 puts, 1 ticker takeover, 1 merge, 1 delete), got %d", len(changes))
+	}
+	if taken := changes[2]; taken.Uic != 3001 || taken.Previous == nil || taken.Previous.Ticker != "6E" || taken.Current == nil || taken.Current.Ticker != "" {
+		t.Errorf("Expected 3001 notified of losing 6E before the roll, got %+v", taken)
+	}
+	if changes[3].Uic != 3002 || changes[3].Previous != nil || changes[4].Previous == nil || changes[5].Current != nil {
+		t.Errorf("Unexpected change shapes: %+v", changes)
+	}
+
+	reopened, err := OpenInstrumentStore(path, nil)
+	if err != nil {
+		t.Fatalf("Reopen: %v", err)
+	}
+	if got := len(reopened.All()); got != 3 {
+		t.Errorf("Expected 3 persisted instruments, got %d", got)
+	}
+	if meta, ok := reopened.Lookup("6E"); !ok || meta.Decimals != 5 {
+		t.Errorf("Expected persisted 6E with decimals, got %+v %v", meta, ok)
+	}
+
+	instrument, ok := reopened.Enrich(Instrument{Ticker: "6E"})
+	if !ok || instrument.Uic != 3002 || instrument.Identifier != 3002 || instrument.AssetType != "ContractFutures" {
+		t.Errorf("Unexpected enrichment: %+v %v", instrument, ok)
+	}
+}
+
+func TestSaxoBrokerClient_InstrumentDetailsUpdateStore(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SetResponse("GET", "/ref/v1/instruments/details", http.StatusOK, map[string]interface{}{
+		"Data": []map[string]interface{}{{"Identifier": 21, "AssetType": "FxSpot", "TickSize": 0.00001, "Format": map[string]interface{}{"Decimals": 5}}},
+	})
+
+	store := NewInstrumentStore(nil)
+	store.Put(InstrumentMetadata{Uic: 21, Ticker: "EURUSD", AssetType: "FxSpot"})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithInstrumentStore(store))
+
+	if _, err := client.GetInstrumentDetails(context.Background(), []int{21}); err != nil {
+		t.Fatalf("GetInstrumentDetails: %v", err)
+	}
+	if meta, _ := store.Get(21); meta.Ticker != "EURUSD" || meta.TickSize != 0.00001 {
+		t.Errorf("Expected EURUSD with tick size from details, got %+v", meta)
+	}
+}
+
+func TestSaxoBrokerClient_GetInstrumentPriceEnrichesFromStore(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SetResponse("GET", "/trade/v1/infoprices", http.StatusOK, map[string]interface{}{
+		"Uic": 21, "AssetType": "FxSpot",
+		"Quote": map[string]interface{}{"Bid": 1.1000, "Ask": 1.1002},
+	})
+
+	store := NewInstrumentStore(nil)
+	store.Put(InstrumentMetadata{Uic: 21, Ticker: "EURUSD", AssetType: "FxSpot"})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithInstrumentStore(store))
+
+	// Ticker only - UIC and asset type come from the store
+	price, err := client.GetInstrumentPrice(context.Background(), Instrument{Ticker: "EURUSD"})
+	if err != nil {
+		t.Fatalf("GetInstrumentPrice: %v", err)
+	}
+	if price.Bid != 1.1000 || price.Ask != 1.1002 {
+		t.Errorf("Expected the EURUSD quote, got %+v", price)
+	}
+	requests := mockServer.AssertRequested(t, "GET", "/trade/v1/infoprices", 1)
+	if len(requests) == 1 && !strings.Contains(requests[0].Query, "Uic=21") {
+		t.Errorf("Expected the stored UIC in the query, got %s", requests[0].Query)
+	}
+}
@@ -1,6 +1,7 @@
 package saxo
 
 import (
+	"log/slog"
 	"testing"
 )
 
@@ -22,3 +23,29 @@ func TestSaxoIntegration_GetPrice(t *testing.T) {
 	// 3. Test price retrieval for EURUSD
 	// 4. Validate response format
 }
+
+// TestProviderIntegration_Config screens every registered provider with SIM credentials
+func TestProviderIntegration_Config(t *testing.T) {
+	config := LoadTestConfig()
+	if config.SkipIntegrationTests || len(config.Providers) == 0 {
+		t.Skip("Integration tests disabled - set {PREFIX}_CLIENT_ID and {PREFIX}_CLIENT_SECRET for a registered provider")
+	}
+
+	for _, provider := range config.Providers {
+		t.Run(provider, func(t *testing.T) {
+			authClient, err := CreateSaxoAuthClient(providerConfigFromEnv(provider), slog.Default())
+			if err != nil {
+				t.Fatalf("CreateSaxoAuthClient(%s) failed: %v", provider, err)
+			}
+			if authClient.Provider() != provider {
+				t.Errorf("Provider() = %s, want %s", authClient.Provider(), provider)
+			}
+			if authClient.GetOAuthConfig(provider) == nil {
+				t.Errorf("No OAuth config registered for %s", provider)
+			}
+			if authClient.GetBaseURL() == "" {
+				t.Errorf("Empty base URL for %s", provider)
+			}
+		})
+	}
+}
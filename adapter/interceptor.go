@@ -0,0 +1,212 @@
+package saxo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultRedactedFields are JSON keys and headers replaced by "[REDACTED]" before interceptors see them
+// Matching is case-insensitive; extend per interceptor with Interceptor.Redact
+var DefaultRedactedFields = []string{
+	"Authorization", "Cookie", "Set-Cookie",
+	"access_token", "refresh_token", "client_secret", "password",
+}
+
+const redacted = "[REDACTED]"
+
+// HTTPRequestInfo is a sanitized copy of an outgoing REST request
+type HTTPRequestInfo struct {
+	Method    string
+	URL       string // Full URL including query
+	Path      string
+	RequestID string // X-Request-ID, "" for reads
+	Header    http.Header
+	Body      []byte
+	Time      time.Time
+}
+
+// HTTPResponseInfo is a sanitized copy of the response to Request
+// Err is set instead of StatusCode when the request never got a response
+type HTTPResponseInfo struct {
+	Request    *HTTPRequestInfo
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Duration   time.Duration
+	Err        error
+}
+
+// Interceptor observes every REST call made by SaxoBrokerClient, e.g. for a compliance audit trail
+// Hooks run synchronously on the calling goroutine, in registration order - keep them fast
+type Interceptor struct {
+	// OnRequest runs before the request is sent; a non-nil error aborts the call with that error
+	// (fail closed when the audit sink is unavailable)
+	OnRequest func(ctx context.Context, req *HTTPRequestInfo) error
+
+	// OnResponse runs once the response body has been read, or after a transport error
+	OnResponse func(ctx context.Context, resp *HTTPResponseInfo)
+
+	// Redact adds field names to DefaultRedactedFields for this interceptor
+	Redact []string
+}
+
+// WithInterceptor adds an OnRequest/OnResponse hook pair to the REST client
+// Can be given multiple times; has no effect on the streaming client
+func WithInterceptor(interceptor Interceptor) Option {
+	return func(o *ClientOptions) {
+		o.Interceptors = append(o.Interceptors, interceptor)
+	}
+}
+
+// interceptRequest runs OnRequest hooks and returns the raw request data for interceptResponse
+func (sbc *SaxoBrokerClient) interceptRequest(ctx context.Context, req *http.Request, requestID string) (*rawExchange, error) {
+	exchange := &rawExchange{
+		method:    req.Method,
+		url:       req.URL.String(),
+		path:      req.URL.Path,
+		requestID: requestID,
+		header:    req.Header.Clone(),
+		start:     time.Now(),
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			exchange.body, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	for _, interceptor := range sbc.interceptors {
+		if interceptor.OnRequest == nil {
+			continue
+		}
+		if err := interceptor.OnRequest(ctx, exchange.requestInfo(interceptor)); err != nil {
+			return nil, err
+		}
+	}
+	return exchange, nil
+}
+
+// interceptResponse buffers the response body, runs OnResponse hooks and hands back a rewound body
+// resp is nil when the request failed with err
+func (sbc *SaxoBrokerClient) interceptResponse(ctx context.Context, exchange *rawExchange, resp *http.Response, err error) error {
+	var body []byte
+	if resp != nil {
+		var readErr error
+		body, readErr = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if readErr != nil {
+			err = readErr
+		}
+	}
+
+	for _, interceptor := range sbc.interceptors {
+		if interceptor.OnResponse == nil {
+			continue
+		}
+		info := &HTTPResponseInfo{
+			Request:  exchange.requestInfo(interceptor),
+			Duration: time.Since(exchange.start),
+			Err:      err,
+		}
+		if resp != nil {
+			names := redactedFields(interceptor)
+			info.StatusCode = resp.StatusCode
+			info.Header = redactHeader(resp.Header, names)
+			info.Body = redactJSON(body, names)
+		}
+		interceptor.OnResponse(ctx, info)
+	}
+	return err
+}
+
+// rawExchange holds unsanitized request data; each interceptor gets its own sanitized copy
+type rawExchange struct {
+	method    string
+	url       string
+	path      string
+	requestID string
+	header    http.Header
+	body      []byte
+	start     time.Time
+}
+
+func (e *rawExchange) requestInfo(interceptor Interceptor) *HTTPRequestInfo {
+	names := redactedFields(interceptor)
+	return &HTTPRequestInfo{
+		Method:    e.method,
+		URL:       e.url,
+		Path:      e.path,
+		RequestID: e.requestID,
+		Header:    redactHeader(e.header, names),
+		Body:      redactJSON(e.body, names),
+		Time:      e.start,
+	}
+}
+
+func redactedFields(interceptor Interceptor) []string {
+	return append(append([]string{}, DefaultRedactedFields...), interceptor.Redact...)
+}
+
+func isRedacted(name string, names []string) bool {
+	for _, candidate := range names {
+		if strings.EqualFold(name, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeader returns a copy of header with sensitive values replaced
+func redactHeader(header http.Header, names []string) http.Header {
+	clone := header.Clone()
+	for name := range clone {
+		if isRedacted(name, names) {
+			clone[name] = []string{redacted}
+		}
+	}
+	return clone
+}
+
+// redactJSON replaces sensitive fields at any depth; bodies that are not JSON are returned as a copy
+func redactJSON(body []byte, names []string) []byte {
+	if len(body) == 0 {
+		return nil
+	}
+	// UseNumber keeps prices exactly as sent (1.0850 stays 1.0850)
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return append([]byte(nil), body...)
+	}
+	sanitized, err := json.Marshal(redactValue(value, names))
+	if err != nil {
+		return append([]byte(nil), body...)
+	}
+	return sanitized
+}
+
+func redactValue(value interface{}, names []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range v {
+			if isRedacted(key, names) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(inner, names)
+			}
+		}
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = redactValue(inner, names)
+		}
+	}
+	return value
+}
@@ -0,0 +1,123 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSaxoBrokerClient_InterceptorAuditTrail(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SimulateOrders("test_account_key", 100000)
+
+	var requests []*HTTPRequestInfo
+	var responses []*HTTPResponseInfo
+	audit := Interceptor{
+		OnRequest: func(ctx context.Context, req *HTTPRequestInfo) error {
+			requests = append(requests, req)
+			return nil
+		},
+		OnResponse: func(ctx context.Context, resp *HTTPResponseInfo) {
+			responses = append(responses, resp)
+		},
+		Redact: []string{"AccountKey"},
+	}
+
+	authClient := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "mock_token",
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	// Injected HTTP client puts the bearer token on the request itself
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger,
+		WithHTTPClient(http.DefaultClient), WithInterceptor(audit))
+
+	resp, err := client.PlaceOrder(context.Background(), OrderRequest{
+		Instrument: createTestInstrument("EURUSD", 21, "FxSpot"),
+		Side:       "Buy",
+		Size:       1000,
+		Price:      1.0850,
+		OrderType:  "Limit",
+		AccountKey: "test_account_key",
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	if resp.OrderID == "" {
+		t.Fatal("Response body should still reach the caller after interception")
+	}
+
+	if len(requests) != 1 || len(responses) != 1 {
+		t.Fatalf("Expected 1 request and 1 response, got %d and %d", len(requests), len(responses))
+	}
+	req := requests[0]
+	if req.Method != "POST" || !strings.HasSuffix(req.Path, "/trade/v2/orders") {
+		t.Errorf("Unexpected request %s %s", req.Method, req.Path)
+	}
+	if req.RequestID == "" {
+		t.Error("Expected the X-Request-ID on mutating requests")
+	}
+	if got := req.Header.Get("Authorization"); got != redacted {
+		t.Errorf("Expected Authorization redacted, got %q", got)
+	}
+	body := string(req.Body)
+	if !strings.Contains(body, `"OrderPrice":1.085`) || !strings.Contains(body, `"Uic":21`) {
+		t.Errorf("Expected order fields in audited body, got %s", body)
+	}
+	if strings.Contains(body, "test_account_key") {
+		t.Errorf("Expected AccountKey redacted in audited body, got %s", body)
+	}
+
+	response := responses[0]
+	if response.StatusCode != http.StatusCreated || response.Err != nil {
+		t.Errorf("Expected 201 without error, got %d (%v)", response.StatusCode, response.Err)
+	}
+	if !strings.Contains(string(response.Body), resp.OrderID) {
+		t.Errorf("Expected order ID in audited response body, got %s", response.Body)
+	}
+	if response.Request.RequestID != req.RequestID {
+		t.Error("Response should reference its request")
+	}
+}
+
+func TestSaxoBrokerClient_InterceptorRejectsRequest(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	sinkDown := errors.New("audit sink unavailable")
+	authClient := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "mock_token",
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger,
+		WithInterceptor(Interceptor{
+			OnRequest: func(ctx context.Context, req *HTTPRequestInfo) error { return sinkDown },
+		}))
+
+	err := client.CancelOrder(context.Background(), CancelOrderRequest{OrderID: "1", AccountKey: "test_account_key"})
+	if !errors.Is(err, sinkDown) {
+		t.Fatalf("Expected interceptor error, got %v", err)
+	}
+	mockServer.AssertRequested(t, "DELETE", "/trade/v2/orders/{orderId}", 0)
+}
+
+func TestRedactJSON(t *testing.T) {
+	body := []byte(`{"access_token":"secret","Orders":[{"Password":"x","OrderPrice":1.0850}]}`)
+	got := string(redactJSON(body, DefaultRedactedFields))
+
+	if strings.Contains(got, "secret") || strings.Contains(got, `"x"`) {
+		t.Errorf("Expected nested secrets redacted, got %s", got)
+	}
+	if !strings.Contains(got, "1.0850") {
+		t.Errorf("Expected numbers kept verbatim, got %s", got)
+	}
+	if plain := string(redactJSON([]byte("grant_type=refresh"), DefaultRedactedFields)); plain != "grant_type=refresh" {
+		t.Errorf("Expected non-JSON body unchanged, got %s", plain)
+	}
+}
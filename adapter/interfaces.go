@@ -26,6 +26,14 @@ type AuthClient interface {
 	BuildRedirectURL(host string, provider string) string
 	GenerateAuthURL(provider string, state string) (string, error)
 	ExchangeCodeForToken(ctx context.Context, code string, provider string) error
+
+	// Token lifecycle observability - lets consumers schedule instead of assuming 20 minutes
+	GetTokenExpiry() time.Time
+	GetRefreshExpiry() time.Time
+	TokenEvents() <-chan TokenEvent
+
+	// SubscribeTokenRefresh registers listener for access token rotations (see TokenCoordinator)
+	SubscribeTokenRefresh(name string, listener TokenListener) (unsubscribe func())
 }
 
 // BrokerClient defines the interface for direct broker operations
@@ -40,6 +48,7 @@ type BrokerClient interface {
 
 	// Order and position queries
 	GetOpenOrders(ctx context.Context) ([]LiveOrder, error)
+	GetOpenOrdersFiltered(ctx context.Context, params OpenOrdersParams) ([]LiveOrder, error)
 	GetOpenPositions(ctx context.Context) (*OpenPositionsResponse, error)
 	GetNetPositions(ctx context.Context) (*NetPositionsResponse, error)
 	GetClosedPositions(ctx context.Context) (*ClosedPositionsResponse, error)
@@ -51,9 +60,11 @@ type BrokerClient interface {
 	GetMarginOverview(ctx context.Context, clientKey string) (*MarginOverview, error)
 	GetClientInfo(ctx context.Context) (*ClientInfo, error)
 	GetTradingSchedule(ctx context.Context, params TradingScheduleParams) (*TradingSchedule, error)
+	IsMarketOpen(ctx context.Context, instrument Instrument) (bool, error) // Schedule cached per instrument per day
 
 	// Instrument search and metadata (Tier 2 - The Usual Suspects)
-	SearchInstruments(ctx context.Context, params InstrumentSearchParams) ([]Instrument, error)
+	SearchInstruments(ctx context.Context, params InstrumentSearchParams) (*InstrumentSearchResult, error)
+	// Any number of UICs; the result may be partial alongside a non-nil error (see UicChunkError)
 	GetInstrumentDetails(ctx context.Context, uics []int) ([]InstrumentDetail, error)
 	GetInstrumentPrices(ctx context.Context, uics []int, fieldGroups string, assetType string) ([]InstrumentPriceInfo, error)
 
@@ -63,10 +74,24 @@ type BrokerClient interface {
 	GetAccountInfo(ctx context.Context) (*AccountInfo, error)
 
 	// Session management
-	// SetSessionCapabilities requests a trade level upgrade (e.g., "FullTradingAndChat" for real-time data).
-	// Call this when GetSessionEventChannel() delivers an event with TradeLevel != "FullTradingAndChat".
-	// Reference: Saxo API PATCH /root/v1/sessions/capabilities
-	SetSessionCapabilities(ctx context.Context, tradeLevel string) error
+	// The adapter never changes the trade level itself. Taking FullTradingAndChat downgrades the
+	// user's other sessions, so read-only dashboards should not call SetTradeLevel.
+	// Reference: Saxo API GET/PATCH /root/v1/sessions/capabilities
+	GetSessionCapabilities(ctx context.Context) (*SessionCapabilities, error)
+	SetTradeLevel(ctx context.Context, level string) error               // TradeLevelFullTradingAndChat or TradeLevelOrdersOnly, read back after the PATCH
+	SetSessionCapabilities(ctx context.Context, tradeLevel string) error // PATCH only, kept for compatibility
+}
+
+// ClientInfoProvider supplies account identity (ClientKey) for order and portfolio subscriptions
+// BrokerClient satisfies it - pass the existing one to the WebSocket client instead of creating another
+type ClientInfoProvider interface {
+	GetClientInfo(ctx context.Context) (*ClientInfo, error)
+}
+
+// InstrumentDetailsProvider supplies instrument details, e.g. symbols for UICs seen on a stream
+// BrokerClient satisfies it
+type InstrumentDetailsProvider interface {
+	GetInstrumentDetails(ctx context.Context, uics []int) ([]InstrumentDetail, error)
 }
 
 // WebSocketClient defines real-time data streaming interface
@@ -77,15 +102,29 @@ type WebSocketClient interface {
 	SubscribeToPortfolio(ctx context.Context) error
 	// SubscribeToSessionEvents subscribes to session state events.
 	// The snapshot from the HTTP POST response is pushed as the first event to the session channel.
-	// Consumers should read GetSessionEventChannel() and call SetSessionCapabilities("FullTradingAndChat") when needed.
+	// Consumers should read GetSessionEventChannel() and decide whether to call SetTradeLevel.
 	SubscribeToSessionEvents(ctx context.Context) error
 	GetPriceUpdateChannel() <-chan PriceUpdate
 	GetOrderUpdateChannel() <-chan OrderUpdate
 	GetPortfolioUpdateChannel() <-chan PortfolioUpdate
 	GetSessionEventChannel() <-chan SessionUpdate
+	// SetStateChannels registers channels receiving connected state and context ID changes
+	// (re-authorization itself is driven by AuthClient.SubscribeTokenRefresh)
+	SetStateChannels(stateChannel chan<- bool, contextIDChannel chan<- string)
+	// IsConnected reports whether the stream is currently up (false while reconnecting)
+	IsConnected() bool
+	// Close is idempotent and safe to call before Connect
 	Close() error
 }
 
+// DepthStreamer is implemented by WebSocket clients that can stream order book depth
+// Kept separate from WebSocketClient so replay/paper implementations need not support it
+type DepthStreamer interface {
+	// SubscribeToDepth streams the bid/ask ladder for one instrument (Saxo allows one UIC per depth subscription)
+	SubscribeToDepth(ctx context.Context, uic int, assetType string) error
+	GetDepthUpdateChannel() <-chan DepthUpdate
+}
+
 // ============================================================================
 // GENERIC DATA TYPES - Simple types for broker-agnostic operations
 // ============================================================================
@@ -114,8 +153,8 @@ type OrderRequest struct {
 	Side       string // "Buy" or "Sell"
 	Size       int
 	Price      float64
-	OrderType  string // "Limit", "Market", "StopIfTraded", "StopLimit", etc.
-	Duration   string // "GoodTillDate", "DayOrder", etc.
+	OrderType  string        // "Limit", "Market", "StopIfTraded", "StopLimit", etc.
+	Duration   OrderDuration // Zero value = DayOrder; GoodTillDate(day) / GoodTillTime(t) for expiring orders
 
 	// Multi-leg order support (for complex/OCO orders)
 	// Related orders inherit AccountKey, Uic, and AssetType from main order
@@ -123,16 +162,25 @@ type OrderRequest struct {
 
 	// Optional fields for specific order types
 	StopLimitPrice float64 // For StopLimit orders (futures)
+
+	// OrderAmountType AmountTypeCashAmount places the order by value: CashAmount in the account
+	// currency is invested and Size is ignored. "" = AmountTypeQuantity (Size units)
+	OrderAmountType string
+	CashAmount      float64
+
+	// ManualOrder tells Saxo whether a person entered the order, nil = client default (WithManualOrders)
+	// Related orders inherit it
+	ManualOrder *bool
 }
 
 // RelatedOrderRequest represents a related order in multi-leg order structures
 // Used for complex orders (entry + OCO exit) and OCO orders (target + stop)
 // Per Saxo API: Related orders inherit AccountKey, Uic, AssetType from parent order
 type RelatedOrderRequest struct {
-	Side      string  // "Buy" or "Sell"
-	OrderType string  // "Limit", "StopIfTraded", etc.
-	Price     float64 // Order price
-	Duration  string  // "DayOrder", "GoodTillDate", etc.
+	Side      string        // "Buy" or "Sell"
+	OrderType string        // "Limit", "StopIfTraded", etc.
+	Price     float64       // Order price
+	Duration  OrderDuration // Zero value = DayOrder
 }
 
 // OrderResponse represents broker order response
@@ -141,6 +189,20 @@ type OrderResponse struct {
 	Status          string
 	Timestamp       string
 	RelatedOrderIDs []string // Child order IDs in placement sequence: [0]=Target(Limit), [1]=Stop
+
+	DryRun   bool           // Synthetic response, nothing was sent to the trading endpoint
+	Precheck *OrderPrecheck // Set when the order was prechecked (dry run with precheck)
+
+	Warnings []OrderWarning // Saxo advisories on the accepted order, e.g. market closed
+}
+
+// OrderPrecheck is the broker's estimate of an order's cost and margin impact
+type OrderPrecheck struct {
+	Result                 string         // "Ok" when the order would be accepted
+	EstimatedCashRequired  float64        // Cash needed to place the order
+	Currency               string         // Currency of EstimatedCashRequired
+	InitialMarginAvailable float64        // Initial margin left after the order, 0 when not reported
+	Warnings               []OrderWarning // Advisories Saxo would attach when placing, e.g. market closed
 }
 
 // OrderModificationRequest represents order modification parameters
@@ -150,9 +212,21 @@ type OrderModificationRequest struct {
 	OrderPrice    string
 	OrderType     string
 	AssetType     string
-	OrderDuration struct {
-		DurationType string
-	}
+	Amount        float64                    // New order size, 0 keeps the current amount
+	OrderDuration OrderDuration              // Always sent - Saxo requires it on modification; GoodTillDate needs an expiry
+	RelatedOrders []RelatedOrderModification // Attached orders moved in the same request
+	ManualOrder   *bool                      // nil = client default (WithManualOrders), related orders inherit it
+}
+
+// RelatedOrderModification moves an order attached to the modified order,
+// e.g. the target or stop of an OCO pair after the entry filled
+// Per Saxo API: related orders inherit AccountKey and AssetType from the parent modification
+type RelatedOrderModification struct {
+	OrderID       string
+	OrderPrice    string
+	OrderType     string
+	Amount        float64 // 0 keeps the current amount
+	OrderDuration OrderDuration
 }
 
 // CancelOrderRequest represents a request to cancel an order
@@ -170,6 +244,7 @@ type ClosePositionRequest struct {
 	AssetType     string
 	Amount        float64
 	BuySell       string
+	ManualOrder   *bool // nil = client default (WithManualOrders)
 }
 
 // OrderStatus represents current order status
@@ -229,10 +304,64 @@ type RelatedOrder struct {
 // PriceUpdate represents a price update from market data
 // Uses Saxo's native UIC (Universal Instrument Code) for matching
 type PriceUpdate struct {
-	Uic       int // Saxo's Universal Instrument Code (matches Instrument.Identifier)
+	Uic          int // Saxo's Universal Instrument Code (matches Instrument.Identifier)
+	Bid          float64
+	Ask          float64
+	Mid          float64
+	Timestamp    time.Time     // ExchangeTime, or RecvTime when absent or selected (websocket SetPriceTimestampSource)
+	ExchangeTime time.Time     // Saxo's LastUpdated, zero when the message has none
+	RecvTime     time.Time     // When the adapter read the message
+	Snapshot     bool          // true for the subscription snapshot (quote at subscribe time), false for streamed updates
+	Symbol       string        // Saxo symbol, e.g. "EURUSD" - "" until resolved (WithInstrumentStore, SetSymbolResolver)
+	Description  string        // Instrument name, e.g. "Euro/US Dollar" - "" until resolved
+	Display      *PriceDisplay // Display strings, nil unless a PriceFormatter was applied
+	Details      *PriceDetails // Extra field groups, nil unless the subscription requested them
+}
+
+// PriceDetails carries the optional price field groups (InstrumentPriceDetails, PriceInfo,
+// PriceInfoDetails, Commissions) of a price subscription. Saxo streams deltas; the adapter merges
+// them per UIC, so every field holds the latest value received. Zero = not received yet
+type PriceDetails struct {
+	OpenInterest   float64 // InstrumentPriceDetails - futures and options
+	LastTraded     float64
+	LastTradedSize float64
+	LastClose      float64
+	Open           float64 // PriceInfoDetails
+	Volume         float64
+	High           float64 // PriceInfo
+	Low            float64
+	NetChange      float64
+	PercentChange  float64
+	CommissionBuy  float64 // Commissions - estimated cost for the subscription Amount
+	CommissionSell float64
+	IsMarketOpen   bool // InstrumentPriceDetails
+}
+
+// InstrumentRef identifies an instrument for streaming across asset types
+// The same UIC can exist under several asset types (e.g. FxSpot and FxForwards)
+type InstrumentRef struct {
+	Uic       int
+	AssetType string // "FxSpot", "ContractFutures", etc.
+}
+
+// DepthLevel is one price level of an order book ladder
+type DepthLevel struct {
+	Price  float64
+	Size   float64
+	Orders int // Number of orders at the level (0 when the venue does not report it)
+}
+
+// DepthUpdate carries the full order book for an instrument after applying a streamed delta
+// Bids and Asks are ordered best price first; BidSize/AskSize are the top-of-book sizes
+type DepthUpdate struct {
+	Uic       int
+	AssetType string
 	Bid       float64
 	Ask       float64
-	Mid       float64
+	BidSize   float64
+	AskSize   float64
+	Bids      []DepthLevel
+	Asks      []DepthLevel
 	Timestamp time.Time
 }
 
@@ -248,13 +377,21 @@ type PriceData struct {
 
 // HistoricalDataPoint represents OHLC historical data
 type HistoricalDataPoint struct {
-	Ticker string
-	Time   time.Time // Time of the data point (consistent with PriceUpdate)
-	Open   float64
-	High   float64
-	Low    float64
-	Close  float64
-	Volume float64
+	Ticker  string
+	Time    time.Time // Time of the data point (consistent with PriceUpdate)
+	Open    float64
+	High    float64
+	Low     float64
+	Close   float64
+	Volume  float64
+	Display *OHLCDisplay // Display strings, nil unless a PriceFormatter was applied
+
+	// Bid/ask quoted asset types (FX, CFDs) only, nil otherwise. Open/High/Low/Close hold the
+	// series selected by WithPriceSide / WithHistoricalPriceSide, Side names it
+	Bid  *OHLC
+	Ask  *OHLC
+	Mid  *OHLC
+	Side PriceSide
 }
 
 // Balance represents generic account balance information
@@ -320,6 +457,7 @@ type OrderUpdate struct {
 	OrderPrice    float64 `json:"Price,omitempty"`
 	Uic           *int    `json:"Uic,omitempty"`
 	Amount        *int    `json:"Amount,omitempty"`
+	BuySell       string  `json:"BuySell,omitempty"`
 	OrderRelation string  `json:"OrderRelation,omitempty"` // "IfDoneMaster", "IfDoneSlaveOco", "Oco", "StandAlone"
 
 	// Phase 1: Nested structure (entry order with related exit orders)
@@ -328,26 +466,87 @@ type OrderUpdate struct {
 
 	// Order deletion marker (Phase 2: when entry fills)
 	MetaDeleted *bool `json:"__meta_deleted,omitempty"`
+
+	// Instrument and account context. The streaming client carries these forward from earlier
+	// messages of the same order, so deltas that only change Status or FilledAmount still have them
+	AssetType   string `json:"AssetType,omitempty"`
+	AccountKey  string `json:"AccountKey,omitempty"`
+	Ticker      string `json:"-"` // InstrumentStore ticker, "" when the UIC is not in the store
+	Symbol      string `json:"-"` // Saxo symbol, e.g. "EURUSD"
+	Description string `json:"-"` // Instrument name, e.g. "Euro/US Dollar"
 }
 
 // PortfolioUpdate represents real-time balance and position changes
+// Streamed values are the merged balance state per subscription - fields Saxo did not resend keep their last value
 type PortfolioUpdate struct {
-	Balance    float64   `json:"balance"`
-	MarginUsed float64   `json:"margin_used"`
-	MarginFree float64   `json:"margin_free"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	Balance              float64   `json:"balance"`     // TotalValue
+	MarginUsed           float64   `json:"margin_used"` // MarginUsedByCurrentPositions
+	MarginFree           float64   `json:"margin_free"` // MarginAvailableForTrading
+	CashBalance          float64   `json:"cash_balance"`
+	UnrealizedProfitLoss float64   `json:"unrealized_profit_loss"` // UnrealizedMarginProfitLoss
+	MarginUtilizationPct float64   `json:"margin_utilization_pct"` // Saxo force-closes positions near 100
+	Currency             string    `json:"currency"`
+	UpdatedAt            time.Time `json:"updated_at"`
 }
 
-// InstrumentSearchParams represents parameters for instrument search
+// InstrumentSearchParams represents parameters for instrument search - zero values mean no filter
 type InstrumentSearchParams struct {
 	Keywords  string `json:"keywords"`
-	AssetType string `json:"asset_type"`
+	AssetType string `json:"asset_type"` // One or more, comma-separated ("FxSpot,CfdOnIndex")
 	Exchange  string `json:"exchange"`
+	Uics      []int  `json:"uics,omitempty"`
+
+	IncludeNonTradable            bool `json:"include_non_tradable,omitempty"`               // Default: tradable instruments only
+	CanParticipateInMultiLegOrder bool `json:"can_participate_in_multi_leg_order,omitempty"` // Only instruments usable in multi-leg orders
+
+	Top        int `json:"top,omitempty"`         // Page size per request (0 = Saxo default, max 1000)
+	MaxResults int `json:"max_results,omitempty"` // Stop following __next after this many (0 = all pages)
+}
+
+// InstrumentSearchResult is the merged result of every page of an instrument search
+type InstrumentSearchResult struct {
+	Instruments []Instrument `json:"instruments"`
+	TotalCount  int          `json:"total_count"` // Matches reported by Saxo (__count); exceeds len(Instruments) when MaxResults cut it short
+}
+
+// OpenOrdersParams filters GetOpenOrdersFiltered - zero values mean no filter
+type OpenOrdersParams struct {
+	Status      []string // Saxo order status filter, e.g. "Working", "Filled", "All" (empty = Saxo default)
+	AccountKey  string   // Only orders on this account
+	ClientKey   string   // Orders of this client - WithClientKey or GetClientInfo when empty and AccountKey is set
+	Uic         int      // Only orders for this instrument
+	AssetType   string   // Only orders of this asset type
+	FieldGroups []string // Default DisplayAndFormat, ExchangeInfo - fewer groups = smaller response
+}
+
+// Matches reports whether order passes the Status, AccountKey, Uic and AssetType filters
+func (p OpenOrdersParams) Matches(order LiveOrder) bool {
+	if p.AccountKey != "" && order.AccountKey != p.AccountKey {
+		return false
+	}
+	if p.Uic != 0 && order.Uic != p.Uic {
+		return false
+	}
+	if p.AssetType != "" && order.AssetType != p.AssetType {
+		return false
+	}
+	if len(p.Status) == 0 {
+		return true
+	}
+	for _, status := range p.Status {
+		if status == "All" || status == order.Status {
+			return true
+		}
+	}
+	return false
 }
 
 // InstrumentDetail represents detailed instrument information
 type InstrumentDetail struct {
 	Uic                   int       `json:"uic"`
+	AssetType             string    `json:"asset_type"`
+	Symbol                string    `json:"symbol"`
+	Description           string    `json:"description"`
 	TickSize              float64   `json:"tick_size"`
 	Decimals              int       `json:"decimals"`
 	OrderDecimals         int       `json:"order_decimals"`
@@ -356,6 +555,10 @@ type InstrumentDetail struct {
 	PriceToContractFactor float64   `json:"price_to_contract_factor"`
 	Format                string    `json:"format"` // "ModernFractions", "Normal", etc.
 	NumeratorDecimals     int       `json:"numerator_decimals"`
+	LotSize               float64   `json:"lot_size"`           // Amount must be a multiple, 0 = any
+	MinimumLotSize        float64   `json:"minimum_lot_size"`   // 0 = no minimum
+	MinimumTradeSize      float64   `json:"minimum_trade_size"` // 0 = no minimum
+	AmountDecimals        int       `json:"amount_decimals"`
 }
 
 // InstrumentPriceInfo represents price information for instrument selection
@@ -0,0 +1,211 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrKillSwitchActive is returned by PlaceOrder and ModifyOrder while the kill switch is triggered
+var ErrKillSwitchActive = errors.New("kill switch active")
+
+// KillSwitchAction is what Trigger does besides blocking new orders
+type KillSwitchAction int
+
+const (
+	KillBlockOnly    KillSwitchAction = iota // Block new orders, leave everything working
+	KillCancelOrders                         // Also cancel working entry orders - stops/targets keep protecting open positions
+	KillFlatten                              // Also cancel every order and close every position at market
+)
+
+func (a KillSwitchAction) String() string {
+	switch a {
+	case KillCancelOrders:
+		return "CancelOrders"
+	case KillFlatten:
+		return "Flatten"
+	default:
+		return "BlockOnly"
+	}
+}
+
+// KillSwitchEvent reports a Trigger (Active) or Reset (not Active)
+type KillSwitchEvent struct {
+	Active          bool
+	Reason          string
+	Action          KillSwitchAction
+	Time            time.Time
+	CancelledOrders []string
+	ClosedPositions []string
+	Errors          []error // Cancellations or closes that failed - check the account manually
+}
+
+// KillSwitch is an emergency stop for the trading path, safe for concurrent use
+// While triggered, PlaceOrder and ModifyOrder fail fast with ErrKillSwitchActive.
+// CancelOrder and ClosePosition keep working so positions can always be reduced
+type KillSwitch struct {
+	broker BrokerClient
+	clock  Clock // Stamps events (WithClock)
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	active bool
+	reason string
+
+	events chan KillSwitchEvent
+}
+
+// newKillSwitch creates the switch for broker; cancellations and closes go through broker
+func newKillSwitch(broker BrokerClient, clock Clock, logger *slog.Logger) *KillSwitch {
+	return &KillSwitch{
+		broker: broker,
+		clock:  clock,
+		logger: logger,
+		events: make(chan KillSwitchEvent, 16),
+	}
+}
+
+// KillSwitch returns the client's kill switch
+func (sbc *SaxoBrokerClient) KillSwitch() *KillSwitch {
+	return sbc.killSwitch
+}
+
+// Trigger blocks new orders immediately, then performs action
+// Can be called again to escalate (e.g. KillBlockOnly, then KillFlatten); the latest reason wins
+func (k *KillSwitch) Trigger(ctx context.Context, reason string, action KillSwitchAction) KillSwitchEvent {
+	k.mu.Lock()
+	k.active = true
+	k.reason = reason
+	k.mu.Unlock()
+
+	k.logger.Error("Kill switch triggered",
+		"function", "KillSwitch.Trigger",
+		"reason", reason,
+		"action", action.String())
+
+	event := KillSwitchEvent{Active: true, Reason: reason, Action: action, Time: k.clock.Now()}
+	ctx = withoutRequestID(ctx) // Every cancel and close needs its own key
+	if action >= KillCancelOrders {
+		k.cancelOrders(ctx, action == KillFlatten, &event)
+	}
+	if action == KillFlatten {
+		k.closePositions(ctx, &event)
+	}
+
+	k.logger.Warn("Kill switch actions completed",
+		"function", "KillSwitch.Trigger",
+		"cancelled_orders", len(event.CancelledOrders),
+		"closed_positions", len(event.ClosedPositions),
+		"errors", len(event.Errors))
+	k.publish(event)
+	return event
+}
+
+// Reset allows orders again
+func (k *KillSwitch) Reset(reason string) {
+	k.mu.Lock()
+	wasActive := k.active
+	k.active = false
+	k.reason = ""
+	k.mu.Unlock()
+
+	if !wasActive {
+		return
+	}
+	k.logger.Warn("Kill switch reset",
+		"function", "KillSwitch.Reset",
+		"reason", reason)
+	k.publish(KillSwitchEvent{Reason: reason, Time: k.clock.Now()})
+}
+
+// Active reports whether orders are blocked, and why
+func (k *KillSwitch) Active() (bool, string) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active, k.reason
+}
+
+// Events delivers Trigger and Reset events; events are dropped when nobody reads
+func (k *KillSwitch) Events() <-chan KillSwitchEvent {
+	return k.events
+}
+
+// check returns ErrKillSwitchActive (wrapped with the reason) while triggered
+func (k *KillSwitch) check() error {
+	if active, reason := k.Active(); active {
+		return fmt.Errorf("%w: %s", ErrKillSwitchActive, reason)
+	}
+	return nil
+}
+
+func (k *KillSwitch) publish(event KillSwitchEvent) {
+	select {
+	case k.events <- event:
+	default:
+		k.logger.Warn("Kill switch event dropped - channel full",
+			"function", "KillSwitch.publish",
+			"active", event.Active)
+	}
+}
+
+// cancelOrders cancels entry orders; all=true also cancels related stops and targets
+func (k *KillSwitch) cancelOrders(ctx context.Context, all bool, event *KillSwitchEvent) {
+	orders, err := k.broker.GetOpenOrders(ctx)
+	if err != nil {
+		event.Errors = append(event.Errors, fmt.Errorf("failed to get open orders: %w", err))
+		return
+	}
+	for _, order := range orders {
+		isEntry := order.OrderRelation == "" || order.OrderRelation == "StandAlone" || order.OrderRelation == "IfDoneMaster"
+		if !isEntry && !all {
+			continue
+		}
+		err := k.broker.CancelOrder(ctx, CancelOrderRequest{OrderID: order.OrderID, AccountKey: order.AccountKey})
+		if err != nil {
+			// Related orders go with their entry - they may already be gone
+			if !isEntry {
+				continue
+			}
+			event.Errors = append(event.Errors, fmt.Errorf("failed to cancel order %s: %w", order.OrderID, err))
+			continue
+		}
+		event.CancelledOrders = append(event.CancelledOrders, order.OrderID)
+	}
+}
+
+// closePositions closes every open position at market
+func (k *KillSwitch) closePositions(ctx context.Context, event *KillSwitchEvent) {
+	positions, err := k.broker.GetOpenPositions(ctx)
+	if err != nil {
+		event.Errors = append(event.Errors, fmt.Errorf("failed to get open positions: %w", err))
+		return
+	}
+	for _, position := range positions.Data {
+		base := position.PositionBase
+		if base.Amount == 0 {
+			continue
+		}
+		side := "Buy"
+		if base.Amount < 0 {
+			side = "Sell"
+		}
+		_, err := k.broker.ClosePosition(ctx, ClosePositionRequest{
+			PositionID:    position.PositionID,
+			NetPositionID: position.NetPositionID,
+			AccountKey:    base.AccountKey,
+			Uic:           base.Uic,
+			AssetType:     base.AssetType,
+			Amount:        math.Abs(base.Amount),
+			BuySell:       side,
+		})
+		if err != nil {
+			event.Errors = append(event.Errors, fmt.Errorf("failed to close position %s: %w", position.PositionID, err))
+			continue
+		}
+		event.ClosedPositions = append(event.ClosedPositions, position.PositionID)
+	}
+}
@@ -0,0 +1,121 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestKillSwitch_BlockFlattenReset(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SimulateOrders("test_account_key", 100000)
+
+	var positions SaxoOpenPositionsResponse
+	positions.Data = make([]SaxoOpenPosition, 1)
+	positions.Data[0].PositionID = "P1"
+	positions.Data[0].PositionBase.AccountKey = "test_account_key"
+	positions.Data[0].PositionBase.Uic = 21
+	positions.Data[0].PositionBase.AssetType = "FxSpot"
+	positions.Data[0].PositionBase.Amount = -5000
+	mockServer.SetResponse("GET", "/port/v1/positions/me", 200, positions)
+
+	authClient := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "mock_token",
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+	killSwitch := client.KillSwitch()
+	ctx := context.Background()
+
+	order := OrderRequest{
+		Instrument: createTestInstrument("EURUSD", 21, "FxSpot"),
+		Side:       "Buy",
+		Size:       1000,
+		Price:      1.0850,
+		OrderType:  "Limit",
+		AccountKey: "test_account_key",
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.PlaceOrder(ctx, order); err != nil {
+			t.Fatalf("PlaceOrder failed: %v", err)
+		}
+	}
+	mockServer.ClearRequests()
+
+	// Block only: new orders fail fast, nothing else changes
+	killSwitch.Trigger(ctx, "drawdown limit", KillBlockOnly)
+	if _, err := client.PlaceOrder(ctx, order); !errors.Is(err, ErrKillSwitchActive) || !strings.Contains(err.Error(), "drawdown limit") {
+		t.Errorf("Expected ErrKillSwitchActive with reason, got %v", err)
+	}
+	if _, err := client.ModifyOrder(ctx, OrderModificationRequest{OrderID: "1"}); !errors.Is(err, ErrKillSwitchActive) {
+		t.Errorf("Expected ModifyOrder blocked, got %v", err)
+	}
+	if event := <-killSwitch.Events(); !event.Active || event.Reason != "drawdown limit" {
+		t.Errorf("Unexpected trigger event %+v", event)
+	}
+	if len(mockServer.GetRequests()) != 0 {
+		t.Errorf("Blocked switch sent %d requests", len(mockServer.GetRequests()))
+	}
+
+	// Escalate: cancel everything and close the short at market
+	event := killSwitch.Trigger(ctx, "monitoring alert", KillFlatten)
+	if len(event.CancelledOrders) != 2 || len(event.ClosedPositions) != 1 || len(event.Errors) != 0 {
+		t.Fatalf("Expected 2 cancels and 1 close without errors, got %+v", event)
+	}
+	if orders, _ := client.GetOpenOrders(ctx); len(orders) != 1 {
+		// The closing market order rests in the simulated book
+		t.Errorf("Expected only the closing order left, got %d orders", len(orders))
+	}
+	closes := mockServer.AssertRequested(t, "POST", "/trade/v2/orders", 1)
+	if len(closes) == 1 && !strings.Contains(closes[0].Body, `"BuySell":"Buy"`) {
+		t.Errorf("Expected a buy to close the short, got %s", closes[0].Body)
+	}
+	<-killSwitch.Events()
+
+	killSwitch.Reset("all clear")
+	if active, _ := killSwitch.Active(); active {
+		t.Error("Expected switch inactive after Reset")
+	}
+	if event := <-killSwitch.Events(); event.Active {
+		t.Errorf("Expected reset event, got %+v", event)
+	}
+	if _, err := client.PlaceOrder(ctx, order); err != nil {
+		t.Errorf("PlaceOrder after Reset failed: %v", err)
+	}
+}
+
+func TestKillSwitch_ConcurrentTrigger(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SimulateOrders("test_account_key", 100000)
+
+	authClient := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "mock_token",
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			client.KillSwitch().Trigger(context.Background(), "stop", KillBlockOnly)
+		}()
+		go func() {
+			defer wg.Done()
+			client.KillSwitch().Active()
+		}()
+	}
+	wg.Wait()
+	if active, reason := client.KillSwitch().Active(); !active || reason != "stop" {
+		t.Errorf("Expected active switch, got %v %q", active, reason)
+	}
+}
@@ -0,0 +1,260 @@
+// Package ledger provides TradeLedger, realized P/L per closed trade including costs, in account currency
+// Closed positions come from REST (Load) and the /port/v1/closedpositions stream (ApplyStream)
+package ledger
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// Trade is one closed round trip, broker-agnostic
+// Costs are positive amounts; NetProfitLoss = GrossProfitLoss - Costs
+type Trade struct {
+	ID         string    `json:"id"`
+	Uic        int       `json:"uic"`
+	AssetType  string    `json:"asset_type"`
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"` // Side of the opening trade, "Buy" or "Sell"
+	Amount     float64   `json:"amount"`
+	OpenPrice  float64   `json:"open_price"`
+	ClosePrice float64   `json:"close_price"`
+	OpenTime   time.Time `json:"open_time"`
+	CloseTime  time.Time `json:"close_time"`
+
+	// Instrument currency
+	Currency        string  `json:"currency"`
+	GrossProfitLoss float64 `json:"gross_profit_loss"`
+	Costs           float64 `json:"costs"`
+	NetProfitLoss   float64 `json:"net_profit_loss"`
+
+	// Account currency
+	AccountCurrency    string  `json:"account_currency"`
+	ConversionRate     float64 `json:"conversion_rate"` // Instrument to account currency
+	RealizedProfitLoss float64 `json:"realized_profit_loss"`
+}
+
+// TradeLedger collects closed trades without duplicates, safe for concurrent use
+type TradeLedger struct {
+	broker saxo.BrokerClient
+	logger *slog.Logger
+
+	mu              sync.RWMutex
+	accountCurrency string
+	trades          map[string]Trade
+}
+
+// NewTradeLedger creates an empty ledger; Load reads closed positions from broker
+// accountCurrency may be empty - Load then takes it from GetBalance
+func NewTradeLedger(broker saxo.BrokerClient, accountCurrency string, logger *slog.Logger) *TradeLedger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &TradeLedger{
+		broker:          broker,
+		logger:          logger,
+		accountCurrency: accountCurrency,
+		trades:          make(map[string]Trade),
+	}
+}
+
+// Load adds every closed position from GET /port/v1/closedpositions (already known trades are kept)
+func (tl *TradeLedger) Load(ctx context.Context) error {
+	tl.mu.RLock()
+	needCurrency := tl.accountCurrency == ""
+	tl.mu.RUnlock()
+	if needCurrency {
+		balance, err := tl.broker.GetBalance(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get account currency: %w", err)
+		}
+		tl.mu.Lock()
+		tl.accountCurrency = balance.Currency
+		tl.mu.Unlock()
+	}
+
+	closed, err := tl.broker.GetClosedPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get closed positions: %w", err)
+	}
+	added := tl.Add(closed.Data...)
+	tl.logger.Info("Closed positions loaded",
+		"function", "TradeLedger.Load",
+		"closed_positions", len(closed.Data),
+		"new_trades", added)
+	return nil
+}
+
+// ApplyStream adds the closed positions of a /port/v1/closedpositions stream message (array or single object)
+// Deletion markers are ignored - a closed trade stays in the ledger
+func (tl *TradeLedger) ApplyStream(payload []byte) error {
+	var positions []saxo.SaxoClosedPosition
+	if err := json.Unmarshal(payload, &positions); err != nil {
+		var position saxo.SaxoClosedPosition
+		if err := json.Unmarshal(payload, &position); err != nil {
+			return fmt.Errorf("failed to unmarshal closed position data: %w", err)
+		}
+		positions = []saxo.SaxoClosedPosition{position}
+	}
+
+	// Stream deltas without a closing price are updates of fields we do not track
+	complete := positions[:0]
+	for _, position := range positions {
+		if position.ClosedPosition.ClosingPrice != 0 && position.ClosedPosition.Uic != 0 {
+			complete = append(complete, position)
+		}
+	}
+	tl.Add(complete...)
+	return nil
+}
+
+// Add normalizes closed positions into trades and returns how many were new
+func (tl *TradeLedger) Add(positions ...saxo.SaxoClosedPosition) int {
+	tl.mu.Lock()
+	defer tl.mu.Unlock()
+
+	added := 0
+	for _, position := range positions {
+		trade := tl.toTradeLocked(position)
+		if _, exists := tl.trades[trade.ID]; exists {
+			continue
+		}
+		tl.trades[trade.ID] = trade
+		added++
+	}
+	return added
+}
+
+// Trades returns all trades ordered by close time
+func (tl *TradeLedger) Trades() []Trade {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+
+	trades := make([]Trade, 0, len(tl.trades))
+	for _, trade := range tl.trades {
+		trades = append(trades, trade)
+	}
+	sort.Slice(trades, func(i, j int) bool {
+		if trades[i].CloseTime.Equal(trades[j].CloseTime) {
+			return trades[i].ID < trades[j].ID
+		}
+		return trades[i].CloseTime.Before(trades[j].CloseTime)
+	})
+	return trades
+}
+
+// RealizedProfitLoss sums realized P/L after costs in account currency
+func (tl *TradeLedger) RealizedProfitLoss() float64 {
+	tl.mu.RLock()
+	defer tl.mu.RUnlock()
+	total := 0.0
+	for _, trade := range tl.trades {
+		total += trade.RealizedProfitLoss
+	}
+	return total
+}
+
+// tradeCSVColumns is the header of WriteCSV
+var tradeCSVColumns = []string{
+	"id", "uic", "asset_type", "symbol", "side", "amount",
+	"open_price", "close_price", "open_time", "close_time",
+	"currency", "gross_profit_loss", "costs", "net_profit_loss",
+	"account_currency", "conversion_rate", "realized_profit_loss",
+}
+
+// WriteCSV writes all trades with a header line
+func (tl *TradeLedger) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(tradeCSVColumns); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, trade := range tl.Trades() {
+		row := []string{
+			trade.ID, strconv.Itoa(trade.Uic), trade.AssetType, trade.Symbol, trade.Side, formatFloat(trade.Amount),
+			formatFloat(trade.OpenPrice), formatFloat(trade.ClosePrice),
+			trade.OpenTime.Format(time.RFC3339), trade.CloseTime.Format(time.RFC3339),
+			trade.Currency, formatFloat(trade.GrossProfitLoss), formatFloat(trade.Costs), formatFloat(trade.NetProfitLoss),
+			trade.AccountCurrency, formatFloat(trade.ConversionRate), formatFloat(trade.RealizedProfitLoss),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write trade %s: %w", trade.ID, err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes all trades as a JSON array
+func (tl *TradeLedger) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(tl.Trades()); err != nil {
+		return fmt.Errorf("failed to encode trades: %w", err)
+	}
+	return nil
+}
+
+// toTradeLocked normalizes one Saxo closed position
+// Gross P/L is Saxo's ClosedProfitLoss, recomputed from prices when Saxo reports none.
+// The conversion rate is derived from Saxo's base currency amounts; 1 when the instrument trades in account currency
+func (tl *TradeLedger) toTradeLocked(position saxo.SaxoClosedPosition) Trade {
+	closed := position.ClosedPosition
+	trade := Trade{
+		ID:              position.ClosedPositionUniqueID,
+		Uic:             closed.Uic,
+		AssetType:       closed.AssetType,
+		Symbol:          position.DisplayAndFormat.Symbol,
+		Side:            closed.BuyOrSell,
+		Amount:          math.Abs(closed.Amount),
+		OpenPrice:       closed.OpenPrice,
+		ClosePrice:      closed.ClosingPrice,
+		OpenTime:        closed.ExecutionTimeOpen,
+		CloseTime:       closed.ExecutionTimeClose,
+		Currency:        position.DisplayAndFormat.Currency,
+		GrossProfitLoss: closed.ClosedProfitLoss,
+		Costs:           math.Abs(closed.CostOpening) + math.Abs(closed.CostClosing),
+		AccountCurrency: tl.accountCurrency,
+	}
+	if trade.ID == "" {
+		trade.ID = closed.OpeningPositionID + "-" + closed.ClosingPositionID
+	}
+	if trade.GrossProfitLoss == 0 {
+		direction := 1.0
+		if trade.Side == "Sell" {
+			direction = -1
+		}
+		trade.GrossProfitLoss = (trade.ClosePrice - trade.OpenPrice) * trade.Amount * direction
+	}
+	trade.NetProfitLoss = trade.GrossProfitLoss - trade.Costs
+
+	switch {
+	case closed.ClosedProfitLoss != 0 && closed.ClosedProfitLossInBaseCurrency != 0:
+		trade.ConversionRate = closed.ClosedProfitLossInBaseCurrency / closed.ClosedProfitLoss
+	case closed.ClosingMarketValue != 0 && closed.ClosingMarketValueInBaseCurrency != 0:
+		trade.ConversionRate = closed.ClosingMarketValueInBaseCurrency / closed.ClosingMarketValue
+	default:
+		trade.ConversionRate = 1
+	}
+
+	// Saxo's own base currency costs win over converting ours
+	costsInBase := trade.Costs * trade.ConversionRate
+	if closed.CostOpeningInBaseCurrency != 0 || closed.CostClosingInBaseCurrency != 0 {
+		costsInBase = math.Abs(closed.CostOpeningInBaseCurrency) + math.Abs(closed.CostClosingInBaseCurrency)
+	}
+	trade.RealizedProfitLoss = trade.GrossProfitLoss*trade.ConversionRate - costsInBase
+	return trade
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,124 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// closedBroker serves a fixed balance and closed positions; other BrokerClient methods are not used
+type closedBroker struct {
+	saxo.BrokerClient
+	closed saxo.ClosedPositionsResponse
+}
+
+func (b *closedBroker) GetBalance(ctx context.Context) (*saxo.Balance, error) {
+	return &saxo.Balance{Currency: "EUR"}, nil
+}
+func (b *closedBroker) GetClosedPositions(ctx context.Context) (*saxo.ClosedPositionsResponse, error) {
+	return &b.closed, nil
+}
+
+func closedPosition(id string, side string, open, close float64) saxo.SaxoClosedPosition {
+	var position saxo.SaxoClosedPosition
+	position.ClosedPositionUniqueID = id
+	position.DisplayAndFormat.Currency = "USD"
+	position.DisplayAndFormat.Symbol = "EURUSD"
+	c := &position.ClosedPosition
+	c.Uic = 21
+	c.AssetType = "FxSpot"
+	c.BuyOrSell = side
+	c.Amount = 10000
+	c.OpenPrice = open
+	c.ClosingPrice = close
+	c.CostOpening = -2
+	c.CostClosing = -3
+	c.ExecutionTimeOpen = time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	c.ExecutionTimeClose = time.Date(2026, 1, 5, 15, 0, 0, 0, time.UTC)
+	return position
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestTradeLedger_NormalizesClosedPositions(t *testing.T) {
+	withRate := closedPosition("t1", "Buy", 1.1000, 1.1050)
+	withRate.ClosedPosition.ClosedProfitLoss = 50
+	withRate.ClosedPosition.ClosedProfitLossInBaseCurrency = 45
+
+	broker := &closedBroker{}
+	broker.closed.Data = []saxo.SaxoClosedPosition{withRate, closedPosition("t2", "Sell", 1.1050, 1.1100)}
+	ledger := NewTradeLedger(broker, "", slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	if err := ledger.Load(context.Background()); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	trades := ledger.Trades()
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 trades, got %d", len(trades))
+	}
+
+	first := trades[0]
+	if first.AccountCurrency != "EUR" || first.Currency != "USD" || first.Costs != 5 {
+		t.Errorf("Unexpected trade %+v", first)
+	}
+	if !approx(first.ConversionRate, 0.9) || !approx(first.NetProfitLoss, 45) || !approx(first.RealizedProfitLoss, 45-4.5) {
+		t.Errorf("Expected rate 0.9 and realized 40.5, got %+v", first)
+	}
+
+	// No Saxo P/L: recomputed from prices, short side
+	second := trades[1]
+	if !approx(second.GrossProfitLoss, -50) || !approx(second.RealizedProfitLoss, -55) {
+		t.Errorf("Expected gross -50 and realized -55, got %+v", second)
+	}
+	if !approx(ledger.RealizedProfitLoss(), 40.5-55) {
+		t.Errorf("Unexpected total %v", ledger.RealizedProfitLoss())
+	}
+
+	// Reloading and streaming the same trade do not duplicate it
+	if err := ledger.Load(context.Background()); err != nil {
+		t.Fatalf("Second Load failed: %v", err)
+	}
+	payload, _ := json.Marshal([]saxo.SaxoClosedPosition{withRate, closedPosition("t3", "Buy", 1.2, 1.21)})
+	if err := ledger.ApplyStream(payload); err != nil {
+		t.Fatalf("ApplyStream failed: %v", err)
+	}
+	if err := ledger.ApplyStream([]byte(`{"ClosedPositionUniqueId":"t3","__meta_deleted":true}`)); err != nil {
+		t.Fatalf("ApplyStream deletion failed: %v", err)
+	}
+	if got := len(ledger.Trades()); got != 3 {
+		t.Errorf("Expected 3 trades, got %d", got)
+	}
+}
+
+func TestTradeLedger_Export(t *testing.T) {
+	ledger := NewTradeLedger(&closedBroker{}, "USD", nil)
+	ledger.Add(closedPosition("t1", "Buy", 1.1, 1.2))
+
+	var csvOut bytes.Buffer
+	if err := ledger.WriteCSV(&csvOut); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csvOut.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "id,uic,") || !strings.HasPrefix(lines[1], "t1,21,FxSpot,EURUSD,Buy,10000,") {
+		t.Errorf("Unexpected CSV:\n%s", csvOut.String())
+	}
+
+	var jsonOut bytes.Buffer
+	if err := ledger.WriteJSON(&jsonOut); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var trades []Trade
+	if err := json.Unmarshal(jsonOut.Bytes(), &trades); err != nil || len(trades) != 1 || trades[0].ID != "t1" {
+		t.Errorf("Expected JSON round trip, got %+v (%v)", trades, err)
+	}
+}
@@ -0,0 +1,23 @@
+package saxo
+
+// WithManualOrders sets the ManualOrder flag sent to Saxo when a request leaves it nil (REST client only)
+// Saxo requires the flag to reflect reality: false (the default) for orders an algorithm placed,
+// true for orders a person entered, e.g. through an order ticket or a command line
+func WithManualOrders(manual bool) Option {
+	return func(o *ClientOptions) {
+		o.ManualOrders = manual
+	}
+}
+
+// ManualOrderFlag returns a ManualOrder override for OrderRequest, OrderModificationRequest and ClosePositionRequest
+func ManualOrderFlag(manual bool) *bool {
+	return &manual
+}
+
+// manualOrder resolves a request's ManualOrder override against the client default
+func (sbc *SaxoBrokerClient) manualOrder(override *bool) bool {
+	if override != nil {
+		return *override
+	}
+	return sbc.manualOrders
+}
@@ -0,0 +1,81 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSaxoBrokerClient_ManualOrderFlag(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	order := OrderRequest{
+		Instrument: createTestInstrument("EURUSD", 21, "FxSpot"),
+		AccountKey: "acc1",
+		Side:       "Buy",
+		Size:       1000,
+		Price:      1.0850,
+		OrderType:  "Limit",
+		RelatedOrders: []RelatedOrderRequest{
+			{Side: "Sell", OrderType: "Limit", Price: 1.0950, Duration: Duration(DurationGoodTillCancel)},
+		},
+	}
+	lastBody := func(method string) map[string]interface{} {
+		t.Helper()
+		requests := mockServer.RequestsTo(method, "/trade/v2/orders")
+		if len(requests) == 0 {
+			t.Fatalf("No %s /trade/v2/orders sent", method)
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal([]byte(requests[len(requests)-1].Body), &body); err != nil {
+			t.Fatalf("Invalid body: %v", err)
+		}
+		return body
+	}
+	check := func(body map[string]interface{}, want bool) {
+		t.Helper()
+		if body["ManualOrder"] != want {
+			t.Errorf("Expected ManualOrder %v, got %v", want, body["ManualOrder"])
+		}
+		for _, related := range body["Orders"].([]interface{}) {
+			if related.(map[string]interface{})["ManualOrder"] != want {
+				t.Errorf("Related order should inherit ManualOrder %v, got %+v", want, related)
+			}
+		}
+	}
+
+	// Automated by default
+	automated := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+	if _, err := automated.PlaceOrder(ctx, order); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	check(lastBody("POST"), false)
+
+	// Per-request override
+	order.ManualOrder = ManualOrderFlag(true)
+	if _, err := automated.PlaceOrder(ctx, order); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	check(lastBody("POST"), true)
+
+	// Client default applies to modifications and their related orders
+	manual := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithManualOrders(true))
+	mockServer.SetResponse("PATCH", "/trade/v2/orders", 200, SaxoOrderResponse{OrderId: "12345678"})
+	_, err := manual.ModifyOrder(ctx, OrderModificationRequest{
+		OrderID: "12345678", AccountKey: "acc1", OrderPrice: "1.0840", OrderType: "Limit", AssetType: "FxSpot",
+		OrderDuration: GoodTillTime(time.Now().Add(time.Hour)),
+		RelatedOrders: []RelatedOrderModification{{OrderID: "12345679", OrderPrice: "1.0960", OrderType: "Limit"}},
+	})
+	if err != nil {
+		t.Fatalf("ModifyOrder failed: %v", err)
+	}
+	check(lastBody("PATCH"), true)
+}
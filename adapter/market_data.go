@@ -10,19 +10,6 @@ import (
 	"time"
 )
 
-// tomorrowMidnightRFC3339 returns tomorrow's midnight time in RFC3339 format
-// Following legacy strategies/strategy.go TomorrowMidnightRFC3339() pattern
-// DEPRECATED: No longer used internally. Consumers should provide cutoffTime to GetHistoricalData().
-func tomorrowMidnightRFC3339() string {
-	// Calculate tomorrow's date
-	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
-	// Set the time components to midnight
-	tomorrowMidnight := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, time.UTC)
-	// Format the date as a string
-	formattedTomorrow := tomorrowMidnight.Format(time.RFC3339)
-	return formattedTomorrow
-}
-
 // RoundTickSize rounds a value to the nearest tick size
 // Following legacy strategies/strategy.go RoundTickSize() pattern
 // This is exported for use by other packages that need generic trading math.
@@ -75,13 +62,14 @@ func GetDecimalsFromTickSize(tickSize float64) int {
 }
 
 // GetInstrumentPrice fetches current market price using enriched instrument data
-// Following legacy broker/broker_http.go patterns for price retrieval
+// Reads the infoprices snapshot; GetQuote returns the same request's full detail
 func (sbc *SaxoBrokerClient) GetInstrumentPrice(ctx context.Context, instrument Instrument) (*PriceData, error) {
 	sbc.logger.Debug("Fetching instrument price",
 		"function", "GetInstrumentPrice",
 		"ticker", instrument.Ticker)
 
-	// Validate enriched instrument data
+	// Validate enriched instrument data (tickers known to the InstrumentStore are enriched here)
+	instrument = sbc.enrichFromStore(instrument)
 	if instrument.Uic == 0 {
 		return nil, fmt.Errorf("instrument %s is not enriched - Identifier (UIC) is missing. Run instrument enrichment first", instrument.Ticker)
 	}
@@ -91,40 +79,35 @@ func (sbc *SaxoBrokerClient) GetInstrumentPrice(ctx context.Context, instrument
 
 	// Check authentication
 	if !sbc.authClient.IsAuthenticated() {
-		return nil, fmt.Errorf("not authenticated with broker")
+		return nil, sbc.notAuthenticated()
 	}
 
-	// Build request URL using enriched UIC and AssetType
-	requestURL := fmt.Sprintf("%s/chart/v1/charts?Uic=%d&AssetType=%s&Horizon=60",
-		sbc.baseURL, instrument.Uic, instrument.AssetType)
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	// Snapshot quote from infoprices - real bid/ask for every asset type
+	quote, _, err := sbc.fetchInfoPrice(ctx, instrument)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, err
 	}
 
-	// Execute request with OAuth2 auto-refresh
-	resp, err := sbc.doRequest(ctx, httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	// No market (e.g. outside trading hours without indicative prices) - last trade, else last close
+	bid, ask := quote.Bid, quote.Ask
+	if bid == 0 && ask == 0 {
+		last := quote.LastTraded
+		if last == 0 {
+			last = quote.LastClose
+		}
+		bid, ask = last, last
 	}
-	defer resp.Body.Close()
-
-	// Handle response
-	if resp.StatusCode != http.StatusOK {
-		return nil, sbc.handleErrorResponse(resp)
+	priceData := &PriceData{
+		Ticker: instrument.Ticker,
+		Bid:    bid,
+		Ask:    ask,
+		Mid:    (bid + ask) / 2,
+		Spread: ask - bid,
 	}
-
-	// Parse price data
-	var saxoPrice SaxoPriceResponse
-	if err := json.NewDecoder(resp.Body).Decode(&saxoPrice); err != nil {
-		return nil, fmt.Errorf("failed to decode price response: %w", err)
+	if !quote.LastUpdated.IsZero() {
+		priceData.Timestamp = quote.LastUpdated.Format(time.RFC3339)
 	}
 
-	// Convert to generic format
-	priceData := sbc.convertFromSaxoPrice(saxoPrice, instrument.Ticker)
-
 	sbc.logger.Info("Price fetched successfully",
 		"function", "GetInstrumentPrice",
 		"ticker", instrument.Ticker,
@@ -134,58 +117,55 @@ func (sbc *SaxoBrokerClient) GetInstrumentPrice(ctx context.Context, instrument
 	return priceData, nil
 }
 
-// GetAccountInfo fetches current account information
-// Following legacy portfolio balance patterns
+// GetAccountInfo returns the default account (WithDefaultAccount, SetDefaultAccount), else the
+// client's first account. /port/v1/accounts/me lists every account in its Data array, so this reads
+// the cached GetAccounts result; GetAccountSummaries adds each account's balance
 func (sbc *SaxoBrokerClient) GetAccountInfo(ctx context.Context) (*AccountInfo, error) {
 	sbc.logger.Debug("Fetching account information",
 		"function", "GetAccountInfo")
 
 	// Check authentication
 	if !sbc.authClient.IsAuthenticated() {
-		return nil, fmt.Errorf("not authenticated with broker")
+		return nil, sbc.notAuthenticated()
 	}
 
-	// Build request URL - account info endpoint
-	requestURL := fmt.Sprintf("%s/port/v1/accounts/me", sbc.baseURL)
-
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	accounts, err := sbc.GetAccounts(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, err
 	}
-
-	// Execute request with OAuth2 auto-refresh
-	resp, err := sbc.doRequest(ctx, httpReq)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Handle response
-	if resp.StatusCode != http.StatusOK {
-		return nil, sbc.handleErrorResponse(resp)
+	if len(accounts.Data) == 0 {
+		return nil, fmt.Errorf("no accounts returned for client")
 	}
 
-	// Parse account data
-	var saxoAccount SaxoAccountInfo
-	if err := json.NewDecoder(resp.Body).Decode(&saxoAccount); err != nil {
-		return nil, fmt.Errorf("failed to decode account response: %w", err)
+	account := accounts.Data[0]
+	if accountKey := sbc.DefaultAccount(); accountKey != "" {
+		found := false
+		for _, candidate := range accounts.Data {
+			if candidate.AccountKey == accountKey {
+				account, found = candidate, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("default account %s not found among %d accounts", accountKey, len(accounts.Data))
+		}
 	}
 
 	sbc.logger.Info("Account info fetched",
 		"function", "GetAccountInfo",
-		"currency", saxoAccount.Currency,
-		"account_type", saxoAccount.AccountType)
+		"account_key", account.AccountKey,
+		"currency", account.Currency,
+		"account_type", account.AccountType)
 
-	// Return directly - AccountInfo is a type alias to SaxoAccountInfo
-	return &saxoAccount, nil
+	return &account, nil
 }
 
 // GetHistoricalData fetches historical OHLC data from Saxo Bank using enriched instrument data
 // Following legacy SinglePivotHistory caching pattern: cache for 1 hour per instrument
 // GetHistoricalData fetches historical OHLC bars for an instrument
 // Following legacy broker/broker_http.go GetSaxoHistoricBars pattern with caching
-// cutoffTime: The end time for historical data (typically next market close for the instrument)
+// cutoffTime: The end time for historical data (typically next market close for the instrument),
+// zero = NextBarCutoff
 func (sbc *SaxoBrokerClient) GetHistoricalData(ctx context.Context, instrument Instrument, days int, cutoffTime time.Time) ([]HistoricalDataPoint, error) {
 	sbc.logger.Debug("Fetching historical data",
 		"function", "GetHistoricalData",
@@ -193,23 +173,22 @@ func (sbc *SaxoBrokerClient) GetHistoricalData(ctx context.Context, instrument I
 		"days", days,
 		"cutoff", cutoffTime.Format(time.RFC3339))
 
-	// Create cache key (identifier + days to ensure cache matches request)
-	cacheKey := fmt.Sprintf("%d_%d", instrument.Uic, days)
+	// Primary series of bid/ask quoted data (WithPriceSide, WithHistoricalPriceSide)
+	side, err := sbc.historicalPriceSide(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check cache first (following legacy findCachedOHLC pattern)
-	sbc.cacheMutex.RLock()
-	if cached, exists := sbc.historyCache[cacheKey]; exists {
-		// Check if cache is still valid (< 1 hour old like legacy system)
-		if time.Since(cached.Timestamp) < sbc.cacheExpiry && len(cached.Data) >= days {
-			sbc.cacheMutex.RUnlock()
-			sbc.logger.Debug("History from cache",
-				"function", "GetHistoricalData",
-				"ticker", instrument.Ticker,
-				"cache_age", time.Since(cached.Timestamp))
-			return cached.Data, nil
-		}
+	// Tickers known to the InstrumentStore are enriched first, so the key has their UIC
+	instrument = sbc.enrichFromStore(instrument)
+	cacheKey := historyCacheKey(instrument.Uic, days, side)
+	if cached, ok := sbc.cachedHistory(cacheKey, days); ok {
+		sbc.logger.Debug("History from cache",
+			"function", "GetHistoricalData",
+			"ticker", instrument.Ticker)
+		return cached, nil
 	}
-	sbc.cacheMutex.RUnlock()
 
 	// Cache miss or expired - fetch fresh data
 	sbc.logger.Debug("History from request",
@@ -227,19 +206,83 @@ func (sbc *SaxoBrokerClient) GetHistoricalData(ctx context.Context, instrument I
 
 	// Check authentication
 	if !sbc.authClient.IsAuthenticated() {
-		return nil, fmt.Errorf("not authenticated with broker")
+		return nil, sbc.notAuthenticated()
+	}
+
+	// No cutoff from the consumer - end at the instrument's daily bar boundary (WithBarCutoff)
+	// and stamp the bars on that boundary below
+	configuredCutoff := cutoffTime.IsZero()
+	if configuredCutoff {
+		cutoffTime = sbc.NextBarCutoff(ctx, instrument)
+		sbc.logger.Debug("Using configured bar cutoff",
+			"function", "GetHistoricalData",
+			"ticker", instrument.Ticker,
+			"cutoff", cutoffTime.Format(time.RFC3339))
+	}
+
+	historicalData, err := sbc.fetchChart(ctx, instrument, chartQuery{Horizon: 1440, Count: days, Mode: "UpTo", Time: cutoffTime}, side)
+	if err != nil {
+		return nil, err
+	}
+	if configuredCutoff {
+		historicalData = alignDailyBars(historicalData, cutoffTime)
+	}
+
+	// Store in cache following legacy pattern (cache for 1 hour)
+	sbc.cacheMutex.Lock()
+	sbc.historyCache[cacheKey] = &cachedHistoricalData{
+		Data:      historicalData,
+		Timestamp: sbc.clock.Now(),
 	}
+	sbc.cacheMutex.Unlock()
 
+	sbc.logger.Debug("Historical data cached",
+		"function", "GetHistoricalData",
+		"ticker", instrument.Ticker,
+		"cache_expiry", sbc.cacheExpiry)
+
+	return historicalData, nil
+}
+
+// historyCacheKey keys GetHistoricalData results: UIC + days to ensure the cache matches the request,
+// + side unless mid
+func historyCacheKey(uic, days int, side PriceSide) string {
+	key := fmt.Sprintf("%d_%d", uic, days)
+	if side != PriceSideMid {
+		key += "_" + string(side)
+	}
+	return key
+}
+
+// cachedHistory returns the cached series of key while younger than cacheExpiry (1 hour like the
+// legacy system) and at least days long
+func (sbc *SaxoBrokerClient) cachedHistory(key string, days int) ([]HistoricalDataPoint, bool) {
+	sbc.cacheMutex.RLock()
+	defer sbc.cacheMutex.RUnlock()
+	cached, exists := sbc.historyCache[key]
+	if !exists || sbc.clock.Now().Sub(cached.Timestamp) >= sbc.cacheExpiry || len(cached.Data) < days {
+		return nil, false
+	}
+	return cached.Data, true
+}
+
+// chartQuery selects the bars of a /chart/v3/charts request
+type chartQuery struct {
+	Horizon int       // Bar length in minutes, 1440 = daily
+	Count   int       // Bars per request, Saxo returns at most MaxChartCount
+	Mode    string    // "UpTo" = bars ending at Time, "From" = bars starting at Time
+	Time    time.Time // Anchor of Mode
+}
+
+// fetchChart requests one page of chart bars and converts them by asset type (uncached)
+func (sbc *SaxoBrokerClient) fetchChart(ctx context.Context, instrument Instrument, query chartQuery, side PriceSide) ([]HistoricalDataPoint, error) {
 	// Build request URL for historical chart data using enriched UIC and AssetType
 	// Following legacy broker/broker_http.go GetSaxoHistoricBars pattern
-	// Using daily horizon (1440 minutes = 1 day), Mode=UpTo, and FieldGroups=Data
-	// cutoffTime is provided by consumer (typically next market close for instrument-specific timing)
-	cutoffStr := cutoffTime.Format(time.RFC3339)
-	requestURL := fmt.Sprintf("%s/chart/v3/charts?AssetType=%s&FieldGroups=Data&Count=%d&Horizon=1440&Mode=UpTo&Time=%s&Uic=%d",
-		sbc.baseURL, instrument.AssetType, days, cutoffStr, instrument.Uic)
+	requestURL := fmt.Sprintf("%s/chart/v3/charts?AssetType=%s&FieldGroups=Data&Count=%d&Horizon=%d&Mode=%s&Time=%s&Uic=%d",
+		sbc.baseURL, instrument.AssetType, query.Count, query.Horizon, query.Mode, query.Time.Format(time.RFC3339), instrument.Uic)
 
 	sbc.logger.Debug("Saxo API request",
-		"function", "GetHistoricalData",
+		"function", "fetchChart",
 		"url", requestURL)
 
 	// Create HTTP request
@@ -267,7 +310,7 @@ func (sbc *SaxoBrokerClient) GetHistoricalData(ctx context.Context, instrument I
 	}
 
 	sbc.logger.Debug("Received data points",
-		"function", "GetHistoricalData",
+		"function", "fetchChart",
 		"ticker", instrument.Ticker,
 		"count", len(saxoResponse.Data))
 
@@ -277,7 +320,7 @@ func (sbc *SaxoBrokerClient) GetHistoricalData(ctx context.Context, instrument I
 			first := saxoResponse.Data[0]
 			if strings.ToLower(instrument.AssetType) == "contractfutures" {
 				sbc.logger.Debug("First data point (Futures)",
-					"function", "GetHistoricalData",
+					"function", "fetchChart",
 					"ticker", instrument.Ticker,
 					"time", first.Time,
 					"open", first.Open,
@@ -287,7 +330,7 @@ func (sbc *SaxoBrokerClient) GetHistoricalData(ctx context.Context, instrument I
 					"volume", first.Volume)
 			} else {
 				sbc.logger.Debug("First data point (FX)",
-					"function", "GetHistoricalData",
+					"function", "fetchChart",
 					"ticker", instrument.Ticker,
 					"time", first.Time,
 					"open_bid", first.OpenBid,
@@ -297,34 +340,21 @@ func (sbc *SaxoBrokerClient) GetHistoricalData(ctx context.Context, instrument I
 			}
 		} // Convert to standardized format based on asset type
 	*/
+
+	// Handle different asset types (see assetTypeHandlers) - bid/ask mid for FX and CFDs,
+	// traded OHLC for futures and stocks
+	handler, ok := assetTypeHandlerFor(instrument.AssetType)
+	if !ok {
+		sbc.logger.Warn("Unknown asset type, using futures format",
+			"function", "fetchChart",
+			"asset_type", instrument.AssetType,
+			"ticker", instrument.Ticker)
+		handler = assetTypeHandlers["contractfutures"]
+	}
+
 	historicalData := make([]HistoricalDataPoint, len(saxoResponse.Data))
 	for i, chartPoint := range saxoResponse.Data {
-		var open, high, low, close float64
-
-		// Handle different asset types following legacy broker_http.go pattern
-		switch strings.ToLower(instrument.AssetType) {
-		case "contractfutures":
-			// Futures have direct OHLC values
-			open = chartPoint.Open
-			high = chartPoint.High
-			low = chartPoint.Low
-			close = chartPoint.Close
-		case "fxspot":
-			// FX uses bid/ask spreads - calculate mid prices
-			open = (chartPoint.OpenBid + chartPoint.OpenAsk) / 2
-			high = (chartPoint.HighBid + chartPoint.HighAsk) / 2
-			low = (chartPoint.LowBid + chartPoint.LowAsk) / 2
-			close = (chartPoint.CloseBid + chartPoint.CloseAsk) / 2
-		default:
-			sbc.logger.Warn("Unknown asset type, using futures format",
-				"function", "GetHistoricalData",
-				"asset_type", instrument.AssetType,
-				"ticker", instrument.Ticker)
-			open = chartPoint.Open
-			high = chartPoint.High
-			low = chartPoint.Low
-			close = chartPoint.Close
-		}
+		open, high, low, close, volume := handler.ohlc(chartPoint)
 
 		// Simple conversion following legacy ConvertFuturesData pattern
 		// No rounding here - rounding happens in strategy layer following legacy pattern
@@ -333,10 +363,10 @@ func (sbc *SaxoBrokerClient) GetHistoricalData(ctx context.Context, instrument I
 		date, err := time.Parse(time.RFC3339, chartPoint.Time)
 		if err != nil {
 			sbc.logger.Warn("Failed to parse timestamp",
-				"function", "GetHistoricalData",
+				"function", "fetchChart",
 				"time", chartPoint.Time,
 				"error", err)
-			date = time.Now().AddDate(0, 0, -days+i) // Fallback
+			date = sbc.clock.Now().Add(time.Duration(i-query.Count) * time.Duration(query.Horizon) * time.Minute) // Fallback
 		}
 
 		historicalData[i] = HistoricalDataPoint{
@@ -346,22 +376,16 @@ func (sbc *SaxoBrokerClient) GetHistoricalData(ctx context.Context, instrument I
 			High:   high,
 			Low:    low,
 			Close:  close,
-			Volume: 0, // Saxo doesn't provide volume for FX
+			Volume: volume, // Traded asset types only - Saxo doesn't provide volume for FX and CFDs
 		}
-	}
 
-	// Store in cache following legacy pattern (cache for 1 hour)
-	sbc.cacheMutex.Lock()
-	sbc.historyCache[cacheKey] = &cachedHistoricalData{
-		Data:      historicalData,
-		Timestamp: time.Now(),
+		// Bid/ask quoted: keep all three series, the selected one becomes the primary OHLC
+		if bid, ask, mid, ok := handler.sides(chartPoint); ok {
+			primary := side.pick(bid, ask, mid)
+			point := &historicalData[i]
+			point.Open, point.High, point.Low, point.Close = primary.Open, primary.High, primary.Low, primary.Close
+			point.Bid, point.Ask, point.Mid, point.Side = &bid, &ask, &mid, side
+		}
 	}
-	sbc.cacheMutex.Unlock()
-
-	sbc.logger.Debug("Historical data cached",
-		"function", "GetHistoricalData",
-		"ticker", instrument.Ticker,
-		"cache_expiry", sbc.cacheExpiry)
-
 	return historicalData, nil
 }
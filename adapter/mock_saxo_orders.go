@@ -0,0 +1,244 @@
+package saxo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mockOrderBook is the in-memory state behind MockSaxoServer.SimulateOrders
+type mockOrderBook struct {
+	mu         sync.Mutex
+	accountKey string
+	nextID     int
+	ids        []string // Placement order, so listings are stable
+	orders     map[string]SaxoOpenOrder
+}
+
+// SimulateOrders replaces the static order routes with a stateful order book for accountKey:
+// placed orders rest as "Working" until modified or cancelled, open order queries list them and unknown
+// order IDs answer 404. Accounts and balance report accountKey with cash.
+// Orders never fill - SetResponse on top of it still scripts fills and rejections
+func (m *MockSaxoServer) SimulateOrders(accountKey string, cash float64) {
+	book := &mockOrderBook{
+		accountKey: accountKey,
+		nextID:     50000000,
+		orders:     make(map[string]SaxoOpenOrder),
+	}
+
+	m.SetResponse("GET", "/port/v1/accounts/me", http.StatusOK, SaxoAccountResponse{
+		Data: []SaxoAccountInfo{{
+			AccountKey:  accountKey,
+			AccountType: "Normal",
+			Currency:    "EUR",
+			ClientKey:   accountKey,
+		}},
+	})
+	m.SetResponse("GET", "/port/v1/balances/me", http.StatusOK, SaxoBalance{
+		CalculationReliability:  "Ok",
+		CashAvailableForTrading: cash,
+		CashBalance:             cash,
+		Currency:                "EUR",
+		TotalValue:              cash,
+	})
+
+	m.SetHandler("POST", "/trade/v2/orders", book.handlePlace)
+	m.SetHandler("PATCH", "/trade/v2/orders", book.handleModify)
+	m.SetHandler("GET", "/trade/v2/orders/{orderId}", book.handleStatus)
+	m.SetHandler("DELETE", "/trade/v2/orders/{orderId}", book.handleCancel)
+	m.SetHandler("GET", "/port/v1/orders/me", book.handleList)
+	m.SetHandler("GET", "/port/v1/orders", book.handleList)
+}
+
+// handlePlace validates like Saxo does server-side and rests the order
+func (b *mockOrderBook) handlePlace(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AccountKey string   `json:"AccountKey"`
+		Uic        int      `json:"Uic"`
+		AssetType  string   `json:"AssetType"`
+		BuySell    string   `json:"BuySell"`
+		Amount     float64  `json:"Amount"`
+		OrderType  string   `json:"OrderType"`
+		OrderPrice *float64 `json:"OrderPrice"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMockError(w, http.StatusBadRequest, "InvalidRequest", "invalid JSON")
+		return
+	}
+	switch {
+	case req.Uic <= 0 || req.AssetType == "":
+		writeMockError(w, http.StatusBadRequest, "InvalidRequest", "Uic and AssetType are required")
+		return
+	case req.BuySell != "Buy" && req.BuySell != "Sell":
+		writeMockError(w, http.StatusBadRequest, "InvalidRequest", fmt.Sprintf("invalid BuySell %q", req.BuySell))
+		return
+	case req.Amount <= 0:
+		writeMockError(w, http.StatusBadRequest, "InvalidRequest", "Amount must be positive")
+		return
+	case req.OrderType == "":
+		writeMockError(w, http.StatusBadRequest, "InvalidRequest", "OrderType is required")
+		return
+	case req.AccountKey != "" && req.AccountKey != b.accountKey:
+		writeMockError(w, http.StatusBadRequest, "InvalidAccountKey", "unknown AccountKey")
+		return
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	b.mu.Lock()
+	b.nextID++
+	orderID := fmt.Sprintf("%d", b.nextID)
+	b.ids = append(b.ids, orderID)
+	b.orders[orderID] = SaxoOpenOrder{
+		OrderID:       orderID,
+		Uic:           req.Uic,
+		BuySell:       req.BuySell,
+		Amount:        req.Amount,
+		OrderPrice:    req.OrderPrice,
+		OrderType:     req.OrderType,
+		AssetType:     req.AssetType,
+		OrderTime:     now,
+		Status:        "Working",
+		AccountKey:    b.accountKey,
+		ClientKey:     b.accountKey,
+		OrderRelation: "StandAlone",
+	}
+	b.mu.Unlock()
+
+	writeMockJSON(w, http.StatusCreated, SaxoOrderResponse{OrderId: orderID, Status: "Working", Timestamp: now})
+}
+
+func (b *mockOrderBook) handleStatus(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	order, ok := b.orders[r.PathValue("orderId")]
+	b.mu.Unlock()
+	if !ok {
+		writeMockError(w, http.StatusNotFound, "OrderNotFound", "order not found")
+		return
+	}
+	writeMockJSON(w, http.StatusOK, SaxoOrderStatus{
+		OrderId:    order.OrderID,
+		Status:     order.Status,
+		Uic:        order.Uic,
+		BuySell:    order.BuySell,
+		Amount:     int(order.Amount),
+		OrderPrice: order.OrderPrice,
+		Timestamp:  order.OrderTime,
+	})
+}
+
+// mockOrderChange is one order in a PATCH /trade/v2/orders body
+type mockOrderChange struct {
+	OrderID       string      `json:"OrderID"`
+	OrderType     string      `json:"OrderType"`
+	OrderPrice    json.Number `json:"OrderPrice"`
+	Amount        float64     `json:"Amount"`
+	OrderDuration struct {
+		DurationType       string `json:"DurationType"`
+		ExpirationDateTime string `json:"ExpirationDateTime"`
+	} `json:"OrderDuration"`
+}
+
+// handleModify applies the order and its related Orders - all or nothing, like handleCancel
+func (b *mockOrderBook) handleModify(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		mockOrderChange
+		Orders []mockOrderChange `json:"Orders"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMockError(w, http.StatusBadRequest, "InvalidRequest", "invalid JSON")
+		return
+	}
+	changes := append([]mockOrderChange{req.mockOrderChange}, req.Orders...)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, change := range changes {
+		if _, ok := b.orders[change.OrderID]; !ok {
+			writeMockError(w, http.StatusNotFound, "OrderNotFound", fmt.Sprintf("order %s not found", change.OrderID))
+			return
+		}
+		if change.Amount < 0 {
+			writeMockError(w, http.StatusBadRequest, "InvalidRequest", "Amount must be positive")
+			return
+		}
+		if change.OrderDuration.DurationType == "GoodTillDate" && change.OrderDuration.ExpirationDateTime == "" {
+			writeMockError(w, http.StatusBadRequest, "InvalidRequest", "GoodTillDate requires ExpirationDateTime")
+			return
+		}
+		if _, err := change.OrderPrice.Float64(); change.OrderPrice != "" && err != nil {
+			writeMockError(w, http.StatusBadRequest, "InvalidRequest", fmt.Sprintf("invalid OrderPrice %q", change.OrderPrice))
+			return
+		}
+	}
+
+	for _, change := range changes {
+		order := b.orders[change.OrderID]
+		if change.OrderType != "" {
+			order.OrderType = change.OrderType
+		}
+		if change.OrderPrice != "" {
+			price, _ := change.OrderPrice.Float64()
+			order.OrderPrice = &price
+		}
+		if change.Amount > 0 {
+			order.Amount = change.Amount
+		}
+		if change.OrderDuration.DurationType != "" {
+			order.OrderDuration.DurationType = change.OrderDuration.DurationType
+			order.OrderDuration.ExpirationDateTime = change.OrderDuration.ExpirationDateTime
+		}
+		b.orders[change.OrderID] = order
+	}
+	writeMockJSON(w, http.StatusOK, map[string]string{"OrderId": req.OrderID})
+}
+
+// handleCancel removes the comma-separated order IDs - all or nothing, like a 404 from Saxo
+func (b *mockOrderBook) handleCancel(w http.ResponseWriter, r *http.Request) {
+	ids := strings.Split(r.PathValue("orderId"), ",")
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, id := range ids {
+		if _, ok := b.orders[id]; !ok {
+			writeMockError(w, http.StatusNotFound, "OrderNotFound", fmt.Sprintf("order %s not found", id))
+			return
+		}
+	}
+	cancelled := make([]map[string]string, 0, len(ids))
+	for _, id := range ids {
+		delete(b.orders, id)
+		cancelled = append(cancelled, map[string]string{"OrderId": id})
+	}
+	writeMockJSON(w, http.StatusOK, map[string]interface{}{"Orders": cancelled})
+}
+
+func (b *mockOrderBook) handleList(w http.ResponseWriter, r *http.Request) {
+	accountKey := r.URL.Query().Get("AccountKey")
+
+	b.mu.Lock()
+	orders := make([]SaxoOpenOrder, 0, len(b.orders))
+	for _, id := range b.ids {
+		order, ok := b.orders[id]
+		if !ok || (accountKey != "" && order.AccountKey != accountKey) {
+			continue
+		}
+		orders = append(orders, order)
+	}
+	b.mu.Unlock()
+
+	writeMockJSON(w, http.StatusOK, SaxoOpenOrdersResponse{Data: orders, Count: len(orders)})
+}
+
+func writeMockJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeMockError answers in Saxo's error shape
+func writeMockError(w http.ResponseWriter, status int, code, message string) {
+	writeMockJSON(w, status, map[string]string{"ErrorCode": code, "Message": message})
+}
@@ -3,18 +3,30 @@ package saxo
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 // MockSaxoServer provides HTTP mock server for unit testing
 // Following legacy broker_http.go patterns without external dependencies
+// Routes may contain path parameters ("DELETE /trade/v2/orders/{orderId}"); latency and
+// fault injection let downstream apps test retries, timeouts and rate limiting
 type MockSaxoServer struct {
-	server    *httptest.Server
-	responses map[string]MockResponse
-	requests  []MockRequest // Track requests for verification
+	server *httptest.Server
+
+	mu        sync.Mutex
+	responses map[string]MockResponse // "METHOD /path" - exact paths win over patterns
+	requests  []MockRequest           // Track requests for verification
+	latency   time.Duration
+	faults    MockFaults
+	failNext  []int // Status codes returned by the next requests, in order
+	rng       *rand.Rand
 }
 
 // MockResponse represents a configured mock response
@@ -22,14 +34,38 @@ type MockResponse struct {
 	StatusCode int
 	Body       interface{}
 	Headers    map[string]string
+	Delay      time.Duration // Added to the server-wide latency for this route
+
+	// Handler, when set, writes the response instead of StatusCode/Body - for stateful fakes
+	// Path parameters are available through r.PathValue
+	Handler http.HandlerFunc
 }
 
 // MockRequest tracks incoming requests for verification
 type MockRequest struct {
-	Method  string
-	Path    string
-	Body    string
-	Headers map[string]string
+	Method     string
+	Path       string
+	Route      string            // Matched route key, e.g. "DELETE /trade/v2/orders/{orderId}" ("" = no route)
+	PathParams map[string]string // Values of {name} segments in Route
+	Query      string            // Raw query string
+	Body       string
+	Headers    map[string]string
+	StatusCode int // Status the mock answered with
+}
+
+// MockFaults configures probabilistic failures
+type MockFaults struct {
+	Rate        float64  // Probability 0..1 that a request fails
+	StatusCodes []int    // Picked at random per failure, default 500, 503 and 429
+	Routes      []string // Only these route keys fail ("" = all routes)
+	RetryAfter  int      // Retry-After seconds sent with 429, default 1
+	Seed        int64    // Random seed for reproducible runs (0 = time-based)
+}
+
+// TestingT is the subset of testing.TB the request assertions need
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
 }
 
 // NewMockSaxoServer creates a new mock server
@@ -37,6 +73,7 @@ func NewMockSaxoServer() *MockSaxoServer {
 	mock := &MockSaxoServer{
 		responses: make(map[string]MockResponse),
 		requests:  make([]MockRequest, 0),
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 
 	// Create HTTP test server
@@ -58,51 +95,139 @@ func (m *MockSaxoServer) GetBaseURL() string {
 	return m.server.URL
 }
 
-// SetOrderPlacementResponse configures mock response for order placement
-func (m *MockSaxoServer) SetOrderPlacementResponse(response SaxoOrderResponse, statusCode int) {
-	m.responses["POST /trade/v2/orders"] = MockResponse{
+// SetResponse configures the response for method and path pattern
+// Patterns match whole segments: "/trade/v2/orders/{orderId}" matches "/trade/v2/orders/123"
+func (m *MockSaxoServer) SetResponse(method, pattern string, statusCode int, body interface{}) {
+	m.SetMockResponse(method, pattern, MockResponse{
 		StatusCode: statusCode,
-		Body:       response,
+		Body:       body,
 		Headers:    map[string]string{"Content-Type": "application/json"},
+	})
+}
+
+// SetMockResponse configures the full response (headers, per-route delay) for method and path pattern
+func (m *MockSaxoServer) SetMockResponse(method, pattern string, response MockResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[method+" "+pattern] = response
+}
+
+// SetHandler serves method and path pattern with handler (see MockResponse.Handler)
+func (m *MockSaxoServer) SetHandler(method, pattern string, handler http.HandlerFunc) {
+	m.SetMockResponse(method, pattern, MockResponse{Handler: handler})
+}
+
+// SetLatency delays every response, e.g. to exercise client timeouts
+func (m *MockSaxoServer) SetLatency(latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latency = latency
+}
+
+// SetFaults enables probabilistic failures (Rate 0 disables them)
+func (m *MockSaxoServer) SetFaults(faults MockFaults) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(faults.StatusCodes) == 0 {
+		faults.StatusCodes = []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusTooManyRequests}
 	}
+	if faults.Seed != 0 {
+		m.rng = rand.New(rand.NewSource(faults.Seed))
+	}
+	m.faults = faults
+}
+
+// FailNext makes the next requests fail with statusCodes in order, regardless of route
+// Deterministic counterpart of SetFaults: FailNext(503, 503) then success tests two retries
+func (m *MockSaxoServer) FailNext(statusCodes ...int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failNext = append(m.failNext, statusCodes...)
+}
+
+// SetOrderPlacementResponse configures mock response for order placement
+func (m *MockSaxoServer) SetOrderPlacementResponse(response SaxoOrderResponse, statusCode int) {
+	m.SetResponse("POST", "/trade/v2/orders", statusCode, response)
 }
 
 // SetOrderCancellationResponse configures mock response for order cancellation
+// Saxo cancels by path: DELETE /trade/v2/orders/{OrderIds}?AccountKey=...
 func (m *MockSaxoServer) SetOrderCancellationResponse(statusCode int, message string) {
-	m.responses["DELETE /trade/v2/orders"] = MockResponse{
-		StatusCode: statusCode,
-		Body:       map[string]string{"Message": message},
-		Headers:    map[string]string{"Content-Type": "application/json"},
-	}
+	m.SetResponse("DELETE", "/trade/v2/orders/{orderId}", statusCode, map[string]string{"Message": message})
+}
+
+// SetOpenOrdersResponse configures mock response for open order queries (/port/v1/orders[/me])
+func (m *MockSaxoServer) SetOpenOrdersResponse(orders []SaxoOpenOrder) {
+	body := SaxoOpenOrdersResponse{Data: orders, Count: len(orders)}
+	m.SetResponse("GET", "/port/v1/orders/me", http.StatusOK, body)
+	m.SetResponse("GET", "/port/v1/orders", http.StatusOK, body)
+}
+
+// SetTradingScheduleResponse configures mock response for /ref/v1/instruments/tradingschedule/{uic}/{assetType}
+func (m *MockSaxoServer) SetTradingScheduleResponse(uic int, assetType string, schedule SaxoTradingSchedule) {
+	m.SetResponse("GET", fmt.Sprintf("/ref/v1/instruments/tradingschedule/%d/%s", uic, assetType), http.StatusOK, schedule)
 }
 
 // SetAuthenticationResponse configures mock OAuth2 token response
 func (m *MockSaxoServer) SetAuthenticationResponse(token SaxoToken, statusCode int) {
-	m.responses["POST /token"] = MockResponse{
-		StatusCode: statusCode,
-		Body:       token,
-		Headers:    map[string]string{"Content-Type": "application/json"},
-	}
+	m.SetResponse("POST", "/token", statusCode, token)
 }
 
 // GetRequests returns all captured requests for verification
 func (m *MockSaxoServer) GetRequests() []MockRequest {
-	return m.requests
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockRequest(nil), m.requests...)
 }
 
 // ClearRequests clears the request history
 func (m *MockSaxoServer) ClearRequests() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.requests = make([]MockRequest, 0)
 }
 
+// RequestsTo returns captured requests matching method and path pattern
+func (m *MockSaxoServer) RequestsTo(method, pattern string) []MockRequest {
+	var matched []MockRequest
+	for _, req := range m.GetRequests() {
+		if req.Method != method {
+			continue
+		}
+		if _, ok := matchPath(pattern, req.Path); ok {
+			matched = append(matched, req)
+		}
+	}
+	return matched
+}
+
+// AssertRequested reports an error unless exactly count requests matched method and pattern
+// Returns the matching requests for further checks on params, query and body
+func (m *MockSaxoServer) AssertRequested(t TestingT, method, pattern string, count int) []MockRequest {
+	t.Helper()
+	matched := m.RequestsTo(method, pattern)
+	if len(matched) != count {
+		t.Errorf("Expected %d %s %s requests, got %d (all requests: %s)",
+			count, method, pattern, len(matched), m.describeRequests())
+	}
+	return matched
+}
+
 // Private methods
 
+func (m *MockSaxoServer) describeRequests() string {
+	var parts []string
+	for _, req := range m.GetRequests() {
+		parts = append(parts, req.Method+" "+req.Path)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
 func (m *MockSaxoServer) handleRequest(w http.ResponseWriter, r *http.Request) {
 	// Capture request for verification
 	body := ""
 	if r.Body != nil {
-		bodyBytes := make([]byte, r.ContentLength)
-		r.Body.Read(bodyBytes)
+		bodyBytes, _ := io.ReadAll(r.Body)
 		body = string(bodyBytes)
 	}
 
@@ -111,16 +236,44 @@ func (m *MockSaxoServer) handleRequest(w http.ResponseWriter, r *http.Request) {
 		headers[key] = strings.Join(values, ", ")
 	}
 
+	m.mu.Lock()
+	route, params, response, exists := m.matchLocked(r.Method, r.URL.Path)
+	fault := m.faultLocked(route)
+	delay := m.latency + response.Delay
+
+	status := http.StatusNotFound
+	switch {
+	case fault != 0:
+		status = fault
+	case exists:
+		status = response.StatusCode
+	}
+	index := len(m.requests)
 	m.requests = append(m.requests, MockRequest{
-		Method:  r.Method,
-		Path:    r.URL.Path,
-		Body:    body,
-		Headers: headers,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Route:      route,
+		PathParams: params,
+		Query:      r.URL.RawQuery,
+		Body:       body,
+		Headers:    headers,
+		StatusCode: status,
 	})
+	retryAfter := m.faults.RetryAfter
+	m.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return // Client gave up (timeout) - nothing to write
+		}
+	}
 
-	// Find matching response
-	key := fmt.Sprintf("%s %s", r.Method, r.URL.Path)
-	response, exists := m.responses[key]
+	if fault != 0 {
+		writeMockFault(w, fault, retryAfter)
+		return
+	}
 
 	if !exists {
 		// Default 404 response
@@ -132,6 +285,22 @@ func (m *MockSaxoServer) handleRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if response.Handler != nil {
+		r.Body = io.NopCloser(strings.NewReader(body))
+		for name, value := range params {
+			r.SetPathValue(name, value)
+		}
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		response.Handler(recorder, r)
+
+		m.mu.Lock()
+		if index < len(m.requests) && m.requests[index].Path == r.URL.Path {
+			m.requests[index].StatusCode = recorder.status
+		}
+		m.mu.Unlock()
+		return
+	}
+
 	// Set headers
 	for key, value := range response.Headers {
 		w.Header().Set(key, value)
@@ -146,6 +315,118 @@ func (m *MockSaxoServer) handleRequest(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// statusRecorder captures the status a Handler wrote for MockRequest.StatusCode
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// matchLocked finds the route for method and path - exact routes first, then the pattern
+// with the fewest parameters (most specific)
+func (m *MockSaxoServer) matchLocked(method, path string) (string, map[string]string, MockResponse, bool) {
+	key := method + " " + path
+	if response, ok := m.responses[key]; ok {
+		return key, nil, response, true
+	}
+
+	bestKey, bestParams, bestWildcards := "", map[string]string(nil), -1
+	for candidate := range m.responses {
+		candidateMethod, pattern, _ := strings.Cut(candidate, " ")
+		if candidateMethod != method || !strings.Contains(pattern, "{") {
+			continue
+		}
+		params, ok := matchPath(pattern, path)
+		if !ok {
+			continue
+		}
+		if bestWildcards == -1 || len(params) < bestWildcards || (len(params) == bestWildcards && candidate < bestKey) {
+			bestKey, bestParams, bestWildcards = candidate, params, len(params)
+		}
+	}
+	if bestWildcards == -1 {
+		return "", nil, MockResponse{}, false
+	}
+	return bestKey, bestParams, m.responses[bestKey], true
+}
+
+// faultLocked returns the status code to fail with, 0 = serve normally
+func (m *MockSaxoServer) faultLocked(route string) int {
+	if len(m.failNext) > 0 {
+		status := m.failNext[0]
+		m.failNext = m.failNext[1:]
+		return status
+	}
+	if m.faults.Rate <= 0 {
+		return 0
+	}
+	if len(m.faults.Routes) > 0 {
+		found := false
+		for _, r := range m.faults.Routes {
+			if r == route {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0
+		}
+	}
+	if m.rng.Float64() >= m.faults.Rate {
+		return 0
+	}
+	return m.faults.StatusCodes[m.rng.Intn(len(m.faults.StatusCodes))]
+}
+
+// writeMockFault answers like Saxo's gateway: 429 carries Retry-After
+func writeMockFault(w http.ResponseWriter, status int, retryAfter int) {
+	errorCode := "InternalServerError"
+	switch status {
+	case http.StatusTooManyRequests:
+		errorCode = "RateLimitExceeded"
+		if retryAfter <= 0 {
+			retryAfter = 1
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	case http.StatusServiceUnavailable:
+		errorCode = "ServiceUnavailable"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"ErrorCode": errorCode,
+		"Message":   "Injected fault",
+	})
+}
+
+// matchPath matches path against pattern segment by segment, capturing {name} segments
+func matchPath(pattern, path string) (map[string]string, bool) {
+	patternParts := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternParts) != len(pathParts) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, part := range patternParts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if pathParts[i] == "" {
+				return nil, false
+			}
+			params[part[1:len(part)-1]] = pathParts[i]
+			continue
+		}
+		if part != pathParts[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
 func (m *MockSaxoServer) setDefaultResponses() {
 	// Default successful order placement response
 	m.SetOrderPlacementResponse(SaxoOrderResponse{
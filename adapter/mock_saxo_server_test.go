@@ -0,0 +1,118 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMockSaxoServer_PathParams(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SetResponse("GET", "/port/v1/orders/{clientKey}/{orderId}", http.StatusOK, map[string]string{"Status": "Working"})
+	mockServer.SetResponse("GET", "/port/v1/orders/me/{orderId}", http.StatusOK, map[string]string{"Status": "Filled"})
+
+	for _, path := range []string{"/port/v1/orders/me/42", "/port/v1/orders/client1/43", "/port/v1/orders/client1"} {
+		resp, err := http.Get(mockServer.GetBaseURL() + path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	requests := mockServer.GetRequests()
+	// The more specific pattern (fewer parameters) wins
+	if requests[0].Route != "GET /port/v1/orders/me/{orderId}" || requests[0].PathParams["orderId"] != "42" {
+		t.Errorf("Expected /me route with orderId 42, got %q %v", requests[0].Route, requests[0].PathParams)
+	}
+	if requests[1].PathParams["clientKey"] != "client1" || requests[1].PathParams["orderId"] != "43" {
+		t.Errorf("Expected clientKey and orderId params, got %v", requests[1].PathParams)
+	}
+	if requests[2].StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for unmatched segment count, got %d", requests[2].StatusCode)
+	}
+	mockServer.AssertRequested(t, "GET", "/port/v1/orders/{clientKey}/{orderId}", 2)
+}
+
+func TestMockSaxoServer_FailNextAndFaults(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	mockServer.FailNext(http.StatusTooManyRequests, http.StatusServiceUnavailable)
+	for _, want := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusCreated} {
+		resp, err := http.Post(mockServer.GetBaseURL()+"/trade/v2/orders", "application/json", nil)
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Expected %d, got %d", want, resp.StatusCode)
+		}
+		if want == http.StatusTooManyRequests && resp.Header.Get("Retry-After") != "1" {
+			t.Errorf("Expected Retry-After on 429, got %q", resp.Header.Get("Retry-After"))
+		}
+	}
+
+	// Seeded faults are reproducible and limited to the listed routes
+	mockServer.ClearRequests()
+	mockServer.SetFaults(MockFaults{Rate: 0.5, StatusCodes: []int{http.StatusBadGateway}, Routes: []string{"POST /trade/v2/orders"}, Seed: 7})
+	failures := 0
+	for i := 0; i < 100; i++ {
+		resp, err := http.Post(mockServer.GetBaseURL()+"/trade/v2/orders", "application/json", nil)
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusBadGateway {
+			failures++
+		}
+		resp, err = http.Post(mockServer.GetBaseURL()+"/token", "application/json", nil)
+		if err != nil {
+			t.Fatalf("POST /token failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Faults leaked to unlisted route: %d", resp.StatusCode)
+		}
+	}
+	if failures < 30 || failures > 70 {
+		t.Errorf("Expected roughly half of 100 requests to fail, got %d", failures)
+	}
+}
+
+func TestMockSaxoServer_LatencyTimesOutClient(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SetLatency(500 * time.Millisecond)
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithTimeout(50*time.Millisecond))
+
+	err := client.CancelOrder(context.Background(), CancelOrderRequest{OrderID: "1", AccountKey: "acc"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestMockSaxoServer_SimulateOrdersRecordsHandlerStatus(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SimulateOrders("acc1", 1000)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(&MockAuthClient{authenticated: true, accessToken: "mock_token"}, mockServer.GetBaseURL(), logger)
+	ctx := context.Background()
+
+	if err := client.CancelOrder(ctx, CancelOrderRequest{OrderID: "1", AccountKey: "acc1"}); err == nil {
+		t.Error("Expected error cancelling unknown order")
+	}
+	requests := mockServer.AssertRequested(t, "DELETE", "/trade/v2/orders/{orderId}", 1)
+	if len(requests) == 1 && requests[0].StatusCode != http.StatusNotFound {
+		t.Errorf("Expected handler status 404 to be recorded, got %d", requests[0].StatusCode)
+	}
+}
@@ -9,10 +9,10 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
 	"os/exec"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -22,8 +22,21 @@ import (
 const (
 	tokenSuffix      = "_token.bin"
 	earlyRefreshTime = 2 * time.Minute
+	// maxKeeperInterval bounds the authentication keeper's sleep between checks
+	maxKeeperInterval = time.Hour
 )
 
+// DefaultTokenFileTemplate names token files - {provider}, {environment} and {client_id} are replaced
+// Environment is included to separate SIM/LIVE tokens; add {client_id} or a fixed suffix when several bots share a directory
+const DefaultTokenFileTemplate = "{provider}_{environment}" + tokenSuffix
+
+// WithTokenFileTemplate overrides DefaultTokenFileTemplate (auth client only)
+func WithTokenFileTemplate(template string) Option {
+	return func(o *ClientOptions) {
+		o.TokenFile = template
+	}
+}
+
 // Environment types for Saxo Bank
 type SaxoEnvironment string
 
@@ -35,97 +48,76 @@ const (
 // LoadSaxoEnvironmentConfig loads environment-specific Saxo configuration from environment variables
 // Returns: oauthConfigs, baseURL, websocketURL, environment, error
 func LoadSaxoEnvironmentConfig(logger *slog.Logger) (map[string]*oauth2.Config, string, string, SaxoEnvironment, error) {
-	environment := os.Getenv("SAXO_ENVIRONMENT")
-	if environment == "" {
-		environment = "sim" // Default to SIM for safety
-	}
-
-	// Read credentials from simple environment variables
-	clientID := os.Getenv("SAXO_CLIENT_ID")
-	clientSecret := os.Getenv("SAXO_CLIENT_SECRET")
-
-	// Validate credentials
-	if clientID == "" {
-		return nil, "", "", "", fmt.Errorf("SAXO_CLIENT_ID not set")
-	}
-	if clientSecret == "" {
-		return nil, "", "", "", fmt.Errorf("SAXO_CLIENT_SECRET not set")
-	}
-
-	var authURL, tokenURL, baseURL, websocketURL string
-	var saxoEnv SaxoEnvironment
-
-	// Set URLs based on environment
-	switch environment {
-	case "sim":
-		authURL = "https://sim.logonvalidation.net/authorize"
-		tokenURL = "https://sim.logonvalidation.net/token"
-		baseURL = "https://gateway.saxobank.com/sim/openapi"
-		websocketURL = "https://sim-streaming.saxobank.com/sim/oapi/streaming/ws"
-		saxoEnv = SaxoSIM
-		logger.Info("Using SIM trading environment",
-			"environment", "sim",
-			"base_url", baseURL,
-			"websocket_url", websocketURL)
-
-	case "live":
-		authURL = "https://live.logonvalidation.net/authorize"
-		tokenURL = "https://live.logonvalidation.net/token"
-		baseURL = "https://gateway.saxobank.com/openapi"
-		websocketURL = "https://live-streaming.saxobank.com/oapi/streaming/ws"
-		saxoEnv = SaxoLive
-		logger.Warn("LIVE trading environment - real money at risk!",
-			"environment", "live",
-			"base_url", baseURL,
-			"websocket_url", websocketURL)
-
-	default:
-		return nil, "", "", "", fmt.Errorf("invalid SAXO_ENVIRONMENT: %s (must be 'sim' or 'live')", environment)
-	}
-
-	// Create OAuth2 configuration
-	oauthConfig := &oauth2.Config{
-		ClientID:     clientID,
-		ClientSecret: clientSecret,
-		Scopes:       []string{"openapi"},
-		Endpoint: oauth2.Endpoint{
-			AuthURL:  authURL,
-			TokenURL: tokenURL,
-		},
-		RedirectURL: "", // Set dynamically by auth handlers
-	}
-
-	configs := map[string]*oauth2.Config{
-		"saxo": oauthConfig,
-	}
-
-	return configs, baseURL, websocketURL, saxoEnv, nil
+	return LoadProviderConfig(logger, DefaultProvider)
 }
 
-// CreateSaxoAuthClient creates a new SaxoAuthClient with environment configuration
-func CreateSaxoAuthClient(logger *slog.Logger) (*SaxoAuthClient, error) {
-	configs, baseURL, websocketURL, environment, err := LoadSaxoEnvironmentConfig(logger)
+// CreateSaxoAuthClient creates a new SaxoAuthClient from config - use FromEnv() for SAXO_* environment variables
+func CreateSaxoAuthClient(config SaxoConfig, logger *slog.Logger) (*SaxoAuthClient, error) {
+	config, err := config.withDefaults()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load Saxo configuration: %w", err)
+		return nil, fmt.Errorf("invalid %s configuration: %w", config.Provider, err)
 	}
+	config.logEnvironment(logger)
 
-	tokenStorage := NewTokenStorage()
-	return NewSaxoAuthClient(configs, baseURL, websocketURL, tokenStorage, environment, logger), nil
+	var storageOpts []FileTokenStorageOption
+	if config.TokenFileMode != 0 {
+		storageOpts = append(storageOpts, WithTokenFileMode(config.TokenFileMode))
+	}
+	if config.TokenKey != nil {
+		storageOpts = append(storageOpts, WithTokenEncryption(config.TokenKey))
+	} else if config.Environment == SaxoLive {
+		logger.Warn("LIVE refresh token stored unencrypted - set SaxoConfig.TokenKey or TOKEN_PASSPHRASE",
+			"function", "CreateSaxoAuthClient")
+	}
+	tokenStorage := NewFileTokenStorage(config.TokenPath, storageOpts...)
+	opts := append(config.clientOptions(),
+		WithProvider(config.Provider),
+		WithTokenFileTemplate(config.TokenFileTemplate),
+		WithReloginThreshold(config.ReloginThreshold),
+		WithOnAuthExpired(config.OnAuthExpired))
+	return NewSaxoAuthClient(config.oauthConfigs(), config.BaseURL, config.WebSocketURL, tokenStorage, config.Environment, logger, opts...), nil
 }
 
 // SaxoAuthClient implements AuthClient with full legacy functionality
 type SaxoAuthClient struct {
 	providerConfigs map[string]*oauth2.Config
+	provider        string // Key into providerConfigs used by Login, refresh and token storage
+	tokenFile       string // Token filename template, see DefaultTokenFileTemplate
 	environment     SaxoEnvironment
 	baseURL         string
 	websocketURL    string // Separate WebSocket URL for new streaming domain (Dec 2025)
 	tokenStorage    TokenStorage
-	tokenUpdated    chan TokenInfo
+	tokenUpdated    chan TokenInfo // Notifies the keeper of new tokens (buffer 1, never closed)
 	currentToken    TokenInfo
 	tokenMutex      sync.RWMutex
-	logger          *slog.Logger
+	refreshMu       sync.Mutex // Serializes token rotation - see refreshTokenIfNeeded
+	loginMu         sync.Mutex // Serializes Login - its callback server holds a fixed port
+	coordinator     *TokenCoordinator
+	eventSubs       []chan TokenEvent
+	eventMu         sync.Mutex // Protects eventSubs and eventsClosed
+	eventsClosed    bool
+
+	// Background goroutine lifecycle - replaced on Logout, stopped for good on Shutdown
+	lifecycle        *authLifecycle
+	retired          []*authLifecycle // Stopped lifecycles whose goroutines may still be running, waited on by Shutdown
+	lifecycleMu      sync.Mutex
+	shutdown         bool
+	logger           *slog.Logger
+	requestTimeout   time.Duration // Per-request maximum for token and re-authorization calls
+	reloginThreshold time.Duration // TokenReloginRequired lead time, 0 = disabled
+	clock            Clock         // Drives refresh timers and expiry checks (WithClock)
+	httpClient       *http.Client  // Base client of all OAuth2 traffic (WithTransport), nil = http.DefaultClient
+
+	// Refresh token expiry - see reauthenticationRequired
+	onAuthExpired func(TokenEvent)
+	authExpired   authExpiredState
+	expiredMu     sync.Mutex
 }
 
+// NewSaxoAuthClient creates the auth client
+// Only WithTimeout, WithBaseURL, WithLogger, WithProvider, WithTokenFileTemplate, WithReloginThreshold, WithOnAuthExpired,
+// WithClock, WithTransport and WithHTTPClient (base transport of token calls) are meaningful for opts
+// Without WithProvider the single key in configs is used, else DefaultProvider
 func NewSaxoAuthClient(
 	configs map[string]*oauth2.Config,
 	baseURL string,
@@ -133,16 +125,41 @@ func NewSaxoAuthClient(
 	storage TokenStorage,
 	environment SaxoEnvironment,
 	logger *slog.Logger,
+	opts ...Option,
 ) *SaxoAuthClient {
-	return &SaxoAuthClient{
-		providerConfigs: configs,
-		baseURL:         baseURL,
-		websocketURL:    websocketURL,
-		tokenStorage:    storage,
-		environment:     environment,
-		tokenUpdated:    nil, // CRITICAL: Must be nil so StartAuthenticationKeeper creates it
-		logger:          logger,
+	o := NewClientOptions(baseURL, logger, opts...)
+	provider := o.Provider
+	if provider == "" {
+		provider = DefaultProvider
+		if len(configs) == 1 {
+			for name := range configs {
+				provider = name
+			}
+		}
+	}
+	tokenFile := o.TokenFile
+	if tokenFile == "" {
+		tokenFile = DefaultTokenFileTemplate
+	}
+	sac := &SaxoAuthClient{
+		providerConfigs:  configs,
+		provider:         provider,
+		tokenFile:        tokenFile,
+		baseURL:          o.BaseURL,
+		websocketURL:     websocketURL,
+		tokenStorage:     storage,
+		environment:      environment,
+		tokenUpdated:     make(chan TokenInfo, 1),
+		lifecycle:        newAuthLifecycle(),
+		logger:           o.Logger,
+		requestTimeout:   o.Timeout,
+		reloginThreshold: o.ReloginThreshold,
+		clock:            o.Clock,
+		httpClient:       o.ResolveHTTPClient(),
+		onAuthExpired:    o.OnAuthExpired,
 	}
+	sac.coordinator = newTokenCoordinator(sac)
+	return sac
 }
 
 // GetBaseURL returns the base URL for API calls
@@ -165,10 +182,65 @@ func (sac *SaxoAuthClient) GetAccessToken() (string, error) {
 	return token.AccessToken, nil
 }
 
+// GetTokenExpiry implements AuthClient - access token expiry (zero time if no token)
+func (sac *SaxoAuthClient) GetTokenExpiry() time.Time {
+	token, err := sac.getToken(sac.provider)
+	if err != nil {
+		return time.Time{}
+	}
+	return token.Expiry
+}
+
+// GetRefreshExpiry implements AuthClient - refresh token expiry (zero time if no token)
+func (sac *SaxoAuthClient) GetRefreshExpiry() time.Time {
+	token, err := sac.getToken(sac.provider)
+	if err != nil {
+		return time.Time{}
+	}
+	return token.RefreshExpiry
+}
+
+// TokenEvents implements AuthClient
+// Each call returns a new subscription channel (buffer 10); events are dropped for slow subscribers
+func (sac *SaxoAuthClient) TokenEvents() <-chan TokenEvent {
+	ch := make(chan TokenEvent, 10)
+	sac.eventMu.Lock()
+	defer sac.eventMu.Unlock()
+	if sac.eventsClosed {
+		close(ch)
+		return ch
+	}
+	sac.eventSubs = append(sac.eventSubs, ch)
+	return ch
+}
+
+// publishTokenEvent fans out a token event to all TokenEvents() subscribers without blocking
+func (sac *SaxoAuthClient) publishTokenEvent(eventType TokenEventType, token TokenInfo, err error) {
+	event := TokenEvent{
+		Type:          eventType,
+		Expiry:        token.Expiry,
+		RefreshExpiry: token.RefreshExpiry,
+		Err:           err,
+		Timestamp:     sac.clock.Now(),
+	}
+
+	sac.eventMu.Lock()
+	defer sac.eventMu.Unlock()
+	for _, ch := range sac.eventSubs {
+		select {
+		case ch <- event:
+		default:
+			sac.logger.Debug("Token event subscriber full, dropping event",
+				"function", "publishTokenEvent",
+				"event", eventType)
+		}
+	}
+}
+
 // IsAuthenticated implements AuthClient
 func (sac *SaxoAuthClient) IsAuthenticated() bool {
 	// Use getValidToken which auto-refreshes expired tokens (following legacy pattern)
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := RequestContext(context.Background(), sac.requestTimeout)
 	defer cancel()
 
 	token, err := sac.getValidToken(ctx)
@@ -180,7 +252,10 @@ func (sac *SaxoAuthClient) IsAuthenticated() bool {
 
 // Login implements AuthClient - CLI-friendly OAuth flow with temporary callback server
 func (sac *SaxoAuthClient) Login(ctx context.Context) error {
-	// Check if already authenticated
+	sac.loginMu.Lock()
+	defer sac.loginMu.Unlock()
+
+	// Check if already authenticated - also after waiting for a concurrent Login
 	if sac.IsAuthenticated() {
 		sac.logger.Info("Already authenticated with valid token")
 		return nil
@@ -188,25 +263,26 @@ func (sac *SaxoAuthClient) Login(ctx context.Context) error {
 
 	// CLI mode: Start temporary localhost server for OAuth callback
 	sac.logger.Info("Starting CLI OAuth authentication flow")
-	return sac.loginCLI(ctx, "saxo")
+	return sac.loginCLI(ctx, sac.provider)
 }
 
 // Logout implements AuthClient
 func (sac *SaxoAuthClient) Logout() error {
 	sac.logger.Info("Starting logout process")
 
-	// Stop token refresh goroutine
+	// Stop token refresh goroutines - a fresh lifecycle lets a later Login start them again
+	sac.lifecycleMu.Lock()
+	sac.retireLifecycle()
+	sac.lifecycleMu.Unlock()
+	sac.logger.Info("Token refresh goroutines stopped")
+
 	sac.tokenMutex.Lock()
-	if sac.tokenUpdated != nil {
-		close(sac.tokenUpdated)
-		sac.tokenUpdated = nil
-		sac.logger.Info("Token refresh goroutine stopped")
-	}
 	sac.currentToken = TokenInfo{}
 	sac.tokenMutex.Unlock()
+	sac.resetAuthExpired(TokenInfo{})
 
 	// Clear from file storage
-	filename := sac.getTokenFilename("saxo")
+	filename := sac.getTokenFilename(sac.provider)
 	if err := sac.tokenStorage.DeleteToken(filename); err != nil {
 		sac.logger.Warn("Failed to delete token file", "error", err)
 		// Continue with logout even if file deletion fails
@@ -217,90 +293,144 @@ func (sac *SaxoAuthClient) Logout() error {
 }
 
 // RefreshToken implements AuthClient with legacy logic
+// Delegates to refreshTokenIfNeeded so keeper, REST calls and the TokenCoordinator share one refresh owner
 func (sac *SaxoAuthClient) RefreshToken(ctx context.Context) error {
-	// CRITICAL: Use cached token directly to avoid circular dependency with getValidToken()
-	// The TokenSource.Token() call below will handle checking expiry and refreshing automatically
-	sac.tokenMutex.RLock()
-	token := sac.currentToken
-	sac.tokenMutex.RUnlock()
-
-	// If no cached token, try loading from file
-	if token.AccessToken == "" {
-		var err error
-		token, err = sac.getToken("saxo")
-		if err != nil {
-			return err
-		}
-	}
+	_, err := sac.refreshTokenIfNeeded(ctx, 0)
+	return err
+}
 
-	config := sac.providerConfigs["saxo"]
-	if config == nil {
-		return fmt.Errorf("no OAuth config for saxo")
+// GetHTTPClient returns configured HTTP client with current token
+func (sac *SaxoAuthClient) GetHTTPClient(ctx context.Context) (*http.Client, error) {
+	token, err := sac.getValidToken(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create token source and refresh
-	// IMPORTANT: TokenSource.Token() automatically checks expiry and refreshes if needed
+	config := sac.providerConfigs[sac.provider]
 	oauthToken := &oauth2.Token{
 		AccessToken:  token.AccessToken,
 		RefreshToken: token.RefreshToken,
 		Expiry:       token.Expiry,
 	}
 
-	src := config.TokenSource(ctx, oauthToken)
-	newToken, err := src.Token()
-	if err != nil {
-		sac.logger.Error("Unable to refresh token",
-			"function", "RefreshToken",
-			"error", err)
-		return err
-	}
+	return config.Client(sac.oauthContext(ctx), oauthToken), nil
+}
+
+// authLifecycle tracks the auth client's background goroutines
+// sync.Once guards make Start* calls safe from multiple goroutines and idempotent
+type authLifecycle struct {
+	keeperOnce      sync.Once
+	coordinatorOnce sync.Once
+	stopOnce        sync.Once
+	stop            chan struct{}
+	wg              sync.WaitGroup
+	exited          chan struct{} // Closed once wg drains after retire
+}
+
+func newAuthLifecycle() *authLifecycle {
+	return &authLifecycle{stop: make(chan struct{})}
+}
 
-	// Check if token was actually refreshed (access token changed)
-	if newToken.AccessToken == token.AccessToken {
-		sac.logger.Debug("Token was not refreshed (same access token)",
-			"function", "RefreshToken")
-		// Still update in case expiry changed
+// stopAll signals all goroutines of this lifecycle to exit (idempotent)
+func (l *authLifecycle) stopAll() {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+}
+
+// retire stops the lifecycle and closes exited once its goroutines have returned
+func (l *authLifecycle) retire() {
+	l.stopAll()
+	l.exited = make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(l.exited)
+	}()
+}
+
+// retireLifecycle stops the active lifecycle and, unless shut down, swaps in a fresh one
+// The old lifecycle is kept so Shutdown still waits for its goroutines. Caller holds lifecycleMu
+func (sac *SaxoAuthClient) retireLifecycle() {
+	if sac.shutdown {
+		sac.lifecycle.stopAll()
+		return
+	}
+	old := sac.lifecycle
+	old.retire()
+	live := sac.retired[:0]
+	for _, l := range sac.retired {
+		select {
+		case <-l.exited:
+		default:
+			live = append(live, l)
+		}
 	}
+	sac.retired = append(live, old)
+	sac.lifecycle = newAuthLifecycle()
+}
 
-	// Convert and store
-	refreshedToken := sac.oauth2ToTokenInfo(*newToken, "saxo")
-	if err := sac.storeToken(refreshedToken); err != nil {
-		sac.logger.Error("Unable to save refreshed token",
-			"function", "RefreshToken",
-			"error", err)
-		return err
+// currentLifecycle returns the active lifecycle, or nil after Shutdown
+func (sac *SaxoAuthClient) currentLifecycle() *authLifecycle {
+	sac.lifecycleMu.Lock()
+	defer sac.lifecycleMu.Unlock()
+	if sac.shutdown {
+		return nil
 	}
+	return sac.lifecycle
+}
 
-	sac.logger.Info("Token refreshed successfully",
-		"function", "RefreshToken",
-		"expiry", newToken.Expiry)
-	return nil
+// refreshInterval converts time-until-expiry (as of now) into a safe timer interval
+// Guards against zero/negative durations so a stale expiry cannot spin the refresh loop
+// Capped at maxKeeperInterval: long-lived Live refresh tokens would otherwise park the keeper for months,
+// and monotonic timers do not advance while the host sleeps
+func refreshInterval(expiry, now time.Time) time.Duration {
+	interval := expiry.Sub(now) - earlyRefreshTime
+	if interval < 30*time.Second {
+		interval = 30 * time.Second
+	}
+	if interval > maxKeeperInterval {
+		interval = maxKeeperInterval
+	}
+	return interval
 }
 
-// GetHTTPClient returns configured HTTP client with current token
-func (sac *SaxoAuthClient) GetHTTPClient(ctx context.Context) (*http.Client, error) {
-	token, err := sac.getValidToken(ctx)
-	if err != nil {
-		return nil, err
+// WithReloginThreshold publishes TokenReloginRequired once the refresh token expires within threshold (auth client only)
+// Use with long-lived Live refresh tokens so an operator logs in again before a bot stops; 0 = disabled
+func WithReloginThreshold(threshold time.Duration) Option {
+	return func(o *ClientOptions) {
+		o.ReloginThreshold = threshold
 	}
+}
 
-	config := sac.providerConfigs["saxo"]
-	oauthToken := &oauth2.Token{
-		AccessToken:  token.AccessToken,
-		RefreshToken: token.RefreshToken,
-		Expiry:       token.Expiry,
+// checkRelogin publishes TokenReloginRequired once per refresh expiry inside reloginThreshold
+// prompted holds the refresh expiry last prompted for (keeper goroutine state)
+func (sac *SaxoAuthClient) checkRelogin(token TokenInfo, prompted *time.Time) {
+	if sac.reloginThreshold <= 0 || token.RefreshExpiry.IsZero() || token.RefreshExpiry.Equal(*prompted) {
+		return
+	}
+	remaining := token.RefreshExpiry.Sub(sac.clock.Now())
+	if remaining > sac.reloginThreshold {
+		return
 	}
 
-	return config.Client(ctx, oauthToken), nil
+	*prompted = token.RefreshExpiry
+	sac.logger.Warn("Refresh token expires soon - log in again to keep running unattended",
+		"function", "checkRelogin",
+		"refresh_expiry", token.RefreshExpiry,
+		"remaining", remaining.Round(time.Minute))
+	sac.publishTokenEvent(TokenReloginRequired, token, nil)
 }
 
 // StartAuthenticationKeeper starts the token refresh background process
 // Following EXACT legacy pattern from pivot-web/broker/oauth.go:235
-// This is the ONLY entry point for token management - called ONCE at boot
+// Safe to call repeatedly and concurrently - the keeper goroutine starts at most once per login
 func (sac *SaxoAuthClient) StartAuthenticationKeeper(provider string) {
-	sac.logger.Info("Authentication keeper started",
-		"function", "StartAuthenticationKeeper",
-		"provider", provider)
+	lifecycle := sac.currentLifecycle()
+	if lifecycle == nil {
+		sac.logger.Warn("Auth client shut down, not starting authentication keeper",
+			"function", "StartAuthenticationKeeper")
+		return
+	}
 
 	token, err := sac.getValidToken(context.Background())
 	if err != nil {
@@ -310,45 +440,155 @@ func (sac *SaxoAuthClient) StartAuthenticationKeeper(provider string) {
 		return
 	}
 
-	timeToExpiry := time.Until(token.RefreshExpiry) - earlyRefreshTime
-	sac.logger.Info("Valid token loaded from file",
-		"function", "StartAuthenticationKeeper",
-		"expiry", token.Expiry,
-		"refresh_in", timeToExpiry)
-
 	// only run this part once (following legacy oauth.go:250)
-	if sac.tokenUpdated == nil {
-		sac.logger.Debug("Setting up ticker and channel for token refresh",
-			"function", "StartAuthenticationKeeper")
+	lifecycle.keeperOnce.Do(func() {
+		timeToExpiry := refreshInterval(token.RefreshExpiry, sac.clock.Now())
+		sac.logger.Info("Authentication keeper started",
+			"function", "StartAuthenticationKeeper",
+			"provider", provider,
+			"expiry", token.Expiry,
+			"refresh_expiry", token.RefreshExpiry,
+			"refresh_in", timeToExpiry)
 
-		ticker := time.NewTicker(timeToExpiry)
-		sac.tokenUpdated = make(chan TokenInfo, 1)
+		// Access token rotation belongs to the coordinator - the keeper only watches the refresh token
+		sac.coordinator.start()
 
+		lifecycle.wg.Add(1)
 		go func() {
-			defer ticker.Stop()
+			defer lifecycle.wg.Done()
+			period := timeToExpiry // Repeats like a ticker until the next token update
+			next := sac.clock.After(period)
+			var prompted time.Time
+			sac.checkRelogin(token, &prompted)
 			for {
 				select {
-				case <-ticker.C:
-					_, err := sac.getValidToken(context.Background())
+				case <-lifecycle.stop:
+					sac.logger.Info("Stopping authentication keeper",
+						"function", "StartAuthenticationKeeper")
+					return
+				case <-next:
+					next = sac.clock.After(period)
+					// getValidToken only refreshes an access token that already expired. TokenAboutToExpire comes
+					// from refreshTokenIfNeeded, run early by the token coordinator and WebSocket re-authorization
+					current, err := sac.getValidToken(context.Background())
 					if err != nil {
 						sac.logger.Error("Unable to refresh token",
 							"function", "StartAuthenticationKeeper",
 							"error", err)
+						continue
 					}
-				case newToken, ok := <-sac.tokenUpdated:
-					if !ok {
-						sac.logger.Info("Token update channel closed, stopping authentication keeper",
-							"function", "StartAuthenticationKeeper")
-						return
-					}
-					ticker.Reset(time.Until(newToken.RefreshExpiry) - earlyRefreshTime)
+					sac.checkRelogin(current, &prompted)
+				case newToken := <-sac.tokenUpdated:
+					sac.checkRelogin(newToken, &prompted)
+					period = refreshInterval(newToken.RefreshExpiry, sac.clock.Now())
+					next = sac.clock.After(period)
 					sac.logger.Info("Token updated, reset refresh timer",
 						"function", "StartAuthenticationKeeper",
-						"next_refresh_in", time.Until(newToken.RefreshExpiry)-earlyRefreshTime)
+						"next_refresh_in", period)
 				}
 			}
 		}()
+	})
+}
+
+// StartTokenEarlyRefresh starts the TokenCoordinator so the access token is refreshed earlyRefreshTime before expiry
+// Kept for compatibility: SaxoWebSocketClient now subscribes to the coordinator itself and re-authorizes on every
+// rotation, so wsConnected/wsContextID are only drained (nil channels are fine). Safe to call repeatedly
+func (sac *SaxoAuthClient) StartTokenEarlyRefresh(ctx context.Context, wsConnected <-chan bool, wsContextID <-chan string) {
+	lifecycle := sac.currentLifecycle()
+	if lifecycle == nil {
+		sac.logger.Warn("Auth client shut down, not starting early refresh",
+			"function", "StartTokenEarlyRefresh")
+		return
 	}
+	sac.coordinator.start()
+	if wsConnected == nil && wsContextID == nil {
+		return
+	}
+
+	// Keep the WebSocket client's non-blocking state sends from filling up
+	lifecycle.wg.Add(1)
+	go func() {
+		defer lifecycle.wg.Done()
+		for wsConnected != nil || wsContextID != nil {
+			select {
+			case <-lifecycle.stop:
+				return
+			case <-ctx.Done():
+				return
+			case _, ok := <-wsConnected:
+				if !ok {
+					wsConnected = nil
+				}
+			case _, ok := <-wsContextID:
+				if !ok {
+					wsContextID = nil
+				}
+			}
+		}
+	}()
+}
+
+// Stop signals all background goroutines (keeper, token coordinator) to exit without waiting
+// Unlike Logout, the token is kept; unlike Shutdown, Start* may be called again afterwards
+// Shutdown still waits for the goroutines stopped here
+func (sac *SaxoAuthClient) Stop() {
+	sac.lifecycleMu.Lock()
+	defer sac.lifecycleMu.Unlock()
+	sac.retireLifecycle()
+}
+
+// Shutdown stops all background goroutines and waits for them to exit or ctx to expire
+// This includes goroutines of lifecycles already stopped by Stop or Logout that have not returned yet
+// Flushes the current token to storage and closes TokenEvents() channels. The client cannot start background work afterwards
+func (sac *SaxoAuthClient) Shutdown(ctx context.Context) error {
+	sac.lifecycleMu.Lock()
+	sac.shutdown = true
+	lifecycle := sac.lifecycle
+	retired := append([]*authLifecycle(nil), sac.retired...)
+	sac.lifecycleMu.Unlock()
+
+	lifecycle.stopAll()
+
+	done := make(chan struct{})
+	go func() {
+		lifecycle.wg.Wait()
+		for _, l := range retired {
+			<-l.exited
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("auth client shutdown: %w", ctx.Err())
+	}
+
+	// Flush current token so the next process start does not need a browser login
+	sac.tokenMutex.RLock()
+	token := sac.currentToken
+	sac.tokenMutex.RUnlock()
+	var flushErr error
+	if token.AccessToken != "" && token.Provider != "" {
+		if err := sac.tokenStorage.SaveToken(sac.getTokenFilename(token.Provider), &token); err != nil {
+			flushErr = fmt.Errorf("failed to flush token: %w", err)
+		}
+	}
+
+	sac.eventMu.Lock()
+	if !sac.eventsClosed {
+		sac.eventsClosed = true
+		for _, ch := range sac.eventSubs {
+			close(ch)
+		}
+		sac.eventSubs = nil
+	}
+	sac.eventMu.Unlock()
+
+	sac.logger.Info("Auth client shut down",
+		"function", "Shutdown")
+	return flushErr
 }
 
 // ReauthorizeWebSocket re-authorizes an active WebSocket connection with a refreshed token
@@ -360,10 +600,12 @@ func (sac *SaxoAuthClient) ReauthorizeWebSocket(ctx context.Context, contextID s
 		return fmt.Errorf("contextID cannot be empty")
 	}
 
-	// Get current token (cached or from file)
-	// CRITICAL: Use getToken() not getValidToken() to avoid circular refresh
-	// The TokenSource below will handle expiry check and refresh automatically!
-	token, err := sac.getToken("saxo")
+	ctx, cancel := RequestContext(ctx, sac.requestTimeout)
+	defer cancel()
+
+	// Rotate token only if it expires within earlyRefreshTime
+	// If the keeper (or another caller) already refreshed it, the fresh token is reused as-is
+	token, err := sac.refreshTokenIfNeeded(ctx, earlyRefreshTime)
 	if err != nil {
 		return fmt.Errorf("failed to get token: %w", err)
 	}
@@ -385,28 +627,19 @@ func (sac *SaxoAuthClient) ReauthorizeWebSocket(ctx context.Context, contextID s
 
 	reauthorizeURL := baseURL.String()
 
-	// Create token source with early expiry (2 minutes before actual expiry)
-	// This ensures token refresh happens BEFORE WebSocket re-authorization if needed
-	// Following legacy pattern: oauth2.ReuseTokenSourceWithExpiry
-	// KEY: The TokenSource automatically checks expiry and refreshes if needed!
-	oauthToken := &oauth2.Token{
-		AccessToken:  token.AccessToken,
-		RefreshToken: token.RefreshToken,
-		Expiry:       token.Expiry,
-	}
-
-	tokenSource := sac.createTokenSourceWithEarlyExpiry(ctx, oauthToken, earlyRefreshTime)
-	client := oauth2.NewClient(ctx, tokenSource)
+	// Static token source - rotation already happened above, this request must not refresh again
+	client := oauth2.NewClient(sac.oauthContext(ctx), oauth2.StaticTokenSource(&oauth2.Token{
+		AccessToken: token.AccessToken,
+		TokenType:   "Bearer",
+	}))
 
 	// Create PUT request (no body required)
 	req, err := http.NewRequestWithContext(ctx, "PUT", reauthorizeURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
+	applyRequestID(ctx, req)
 
-	// Execute request
-	// CRITICAL: The oauth2.Client automatically calls tokenSource.Token() before the request
-	// If token is expired or within earlyRefreshTime, it refreshes automatically!
 	sac.logger.Debug("Sending WebSocket re-authorization PUT request",
 		"function", "ReauthorizeWebSocket",
 		"url", reauthorizeURL,
@@ -427,52 +660,88 @@ func (sac *SaxoAuthClient) ReauthorizeWebSocket(ctx context.Context, contextID s
 		return fmt.Errorf("re-authorization failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
+	// Re-authorizing with an unchanged (still fresh) token is fine - the call is idempotent
 	sac.logger.Info("Re-authorization request successful",
 		"function", "ReauthorizeWebSocket",
-		"status", resp.StatusCode)
+		"status", resp.StatusCode,
+		"expiry", token.Expiry)
+	return nil
+}
 
-	// Get potentially refreshed token from token source
-	// This is critical - if token was refreshed during re-auth, we need to save it
-	newToken, err := tokenSource.Token()
-	if err != nil {
-		sac.logger.Error("Unable to get token after reauthorization",
-			"function", "ReauthorizeWebSocket",
-			"error", err)
-		return err
+// refreshTokenIfNeeded is the single owner of token rotation
+// Serialized by refreshMu so concurrent callers (keeper, REST calls, TokenCoordinator) rotate at most once:
+// the second caller sees the already-refreshed token and returns it unchanged
+// earlyExpiry: rotate when the access token expires within this window (0 = only when expired)
+func (sac *SaxoAuthClient) refreshTokenIfNeeded(ctx context.Context, earlyExpiry time.Duration) (TokenInfo, error) {
+	sac.refreshMu.Lock()
+	defer sac.refreshMu.Unlock()
+
+	// CRITICAL: Use cached token directly to avoid circular dependency with getValidToken()
+	sac.tokenMutex.RLock()
+	token := sac.currentToken
+	sac.tokenMutex.RUnlock()
+
+	// If no cached token, try loading from file
+	if token.AccessToken == "" {
+		var err error
+		token, err = sac.getToken(sac.provider)
+		if err != nil {
+			return TokenInfo{}, err
+		}
 	}
 
-	// LEGACY PATTERN: Check if the token has actually been refreshed
-	// If not refreshed, it's an ERROR because we only call this when token is expiring
-	if newToken.AccessToken == token.AccessToken {
-		sac.logger.Warn("Token was not refreshed (same access token)",
-			"function", "ReauthorizeWebSocket")
-		return fmt.Errorf("token was not refreshed")
+	if expiresIn := token.Expiry.Sub(sac.clock.Now()); expiresIn > earlyExpiry {
+		sac.logger.Debug("Token still fresh, no refresh needed",
+			"function", "refreshTokenIfNeeded",
+			"expires_in", expiresIn)
+		return token, nil
 	}
 
-	sac.logger.Info("New token obtained after reauthorization",
-		"function", "ReauthorizeWebSocket",
-		"expiry", newToken.Expiry)
+	if !token.RefreshExpiry.IsZero() && !sac.clock.Now().Before(token.RefreshExpiry) {
+		return TokenInfo{}, sac.reauthenticationRequired(token, nil)
+	}
+	sac.publishTokenEvent(TokenAboutToExpire, token, nil)
 
-	// Store the new token
-	refreshedToken := sac.oauth2ToTokenInfo(*newToken, "saxo")
+	config := sac.providerConfigs[sac.provider]
+	if config == nil {
+		return TokenInfo{}, fmt.Errorf("no OAuth config for %s", sac.provider)
+	}
+
+	// Token source without access token forces a refresh-token grant
+	// (config.TokenSource would otherwise reuse the old token until 10s before expiry)
+	src := config.TokenSource(sac.oauthContext(ctx), &oauth2.Token{RefreshToken: token.RefreshToken})
+	newToken, err := src.Token()
+	if err != nil {
+		sac.logger.Error("Unable to refresh token",
+			"function", "refreshTokenIfNeeded",
+			"error", err)
+		sac.publishTokenEvent(TokenRefreshFailed, token, err)
+		if isInvalidGrant(err) {
+			return TokenInfo{}, sac.reauthenticationRequired(token, err)
+		}
+		return TokenInfo{}, err
+	}
+
+	// Convert and store
+	refreshedToken := sac.oauth2ToTokenInfo(*newToken, sac.provider)
+	// Refresh token kept without refresh_token_expires_in: its persisted expiry still applies
+	// (long-lived Live tokens would otherwise fall back to 24h)
+	if newToken.RefreshToken == token.RefreshToken && newToken.Extra("refresh_token_expires_in") == nil && !token.RefreshExpiry.IsZero() {
+		refreshedToken.RefreshExpiry = token.RefreshExpiry
+	}
 	if err := sac.storeToken(refreshedToken); err != nil {
 		sac.logger.Error("Unable to save refreshed token",
-			"function", "ReauthorizeWebSocket",
+			"function", "refreshTokenIfNeeded",
 			"error", err)
-		return err
+		sac.publishTokenEvent(TokenRefreshFailed, token, err)
+		return TokenInfo{}, err
 	}
+	sac.publishTokenEvent(TokenRefreshed, refreshedToken, nil)
 
-	sac.logger.Info("Successfully reauthorized and saved new token",
-		"function", "ReauthorizeWebSocket")
-	return nil
-}
-
-// createTokenSourceWithEarlyExpiry creates a token source that refreshes tokens before actual expiry
-// Following legacy broker/oauth.go pattern
-func (sac *SaxoAuthClient) createTokenSourceWithEarlyExpiry(ctx context.Context, token *oauth2.Token, earlyExpiry time.Duration) oauth2.TokenSource {
-	config := sac.providerConfigs["saxo"]
-	baseSource := config.TokenSource(ctx, token)
-	return oauth2.ReuseTokenSourceWithExpiry(token, baseSource, earlyExpiry)
+	sac.logger.Info("Token refreshed successfully",
+		"function", "refreshTokenIfNeeded",
+		"expiry", newToken.Expiry)
+	return refreshedToken, nil
 }
 
 // Private methods implementing legacy functionality
@@ -480,7 +749,7 @@ func (sac *SaxoAuthClient) createTokenSourceWithEarlyExpiry(ctx context.Context,
 func (sac *SaxoAuthClient) getToken(provider string) (TokenInfo, error) {
 	sac.tokenMutex.RLock()
 	// Return cached token if valid
-	if sac.currentToken.AccessToken != "" && time.Now().Before(sac.currentToken.Expiry) {
+	if sac.currentToken.AccessToken != "" && sac.clock.Now().Before(sac.currentToken.Expiry) {
 		defer sac.tokenMutex.RUnlock()
 		// sac.logger.Printf("getToken: Returning cached token (expires in %v)", time.Until(sac.currentToken.Expiry))
 		return sac.currentToken, nil
@@ -492,7 +761,7 @@ func (sac *SaxoAuthClient) getToken(provider string) (TokenInfo, error) {
 	defer sac.tokenMutex.Unlock()
 
 	// Double-check after acquiring write lock (another goroutine might have updated)
-	if sac.currentToken.AccessToken != "" && time.Now().Before(sac.currentToken.Expiry) {
+	if sac.currentToken.AccessToken != "" && sac.clock.Now().Before(sac.currentToken.Expiry) {
 		// sac.logger.Printf("getToken: Returning cached token after re-check (expires in %v)", time.Until(sac.currentToken.Expiry))
 		return sac.currentToken, nil
 	}
@@ -512,19 +781,19 @@ func (sac *SaxoAuthClient) getToken(provider string) (TokenInfo, error) {
 	sac.currentToken = *tokenInfo
 	sac.logger.Debug("Loaded token from file and updated cache",
 		"function", "getToken",
-		"expires_in", time.Until(tokenInfo.Expiry))
+		"expires_in", tokenInfo.Expiry.Sub(sac.clock.Now()))
 
 	return *tokenInfo, nil
 }
 
 func (sac *SaxoAuthClient) getValidToken(ctx context.Context) (TokenInfo, error) {
-	token, err := sac.getToken("saxo")
+	token, err := sac.getToken(sac.provider)
 	if err != nil {
 		return TokenInfo{}, err
 	}
 
 	// Token is valid
-	if time.Now().Before(token.Expiry) {
+	if sac.clock.Now().Before(token.Expiry) {
 		return token, nil
 	}
 
@@ -537,7 +806,7 @@ func (sac *SaxoAuthClient) getValidToken(ctx context.Context) (TokenInfo, error)
 	}
 
 	// Return refreshed token
-	return sac.getToken("saxo")
+	return sac.getToken(sac.provider)
 }
 
 func (sac *SaxoAuthClient) storeToken(token TokenInfo) error {
@@ -545,6 +814,7 @@ func (sac *SaxoAuthClient) storeToken(token TokenInfo) error {
 	sac.tokenMutex.Lock()
 	sac.currentToken = token
 	sac.tokenMutex.Unlock()
+	sac.resetAuthExpired(token)
 
 	// Non-blocking channel send
 	select {
@@ -553,6 +823,7 @@ func (sac *SaxoAuthClient) storeToken(token TokenInfo) error {
 		sac.logger.Debug("Channel send would block, skipping",
 			"function", "storeToken")
 	}
+	sac.coordinator.notifyRotated()
 
 	// Store to file
 	filename := sac.getTokenFilename(token.Provider)
@@ -560,8 +831,15 @@ func (sac *SaxoAuthClient) storeToken(token TokenInfo) error {
 }
 
 func (sac *SaxoAuthClient) getTokenFilename(provider string) string {
-	// Include environment in filename to separate SIM/LIVE tokens
-	return fmt.Sprintf("%s_%s%s", provider, sac.environment, tokenSuffix)
+	var clientID string
+	if config := sac.providerConfigs[provider]; config != nil {
+		clientID = config.ClientID
+	}
+	return strings.NewReplacer(
+		"{provider}", provider,
+		"{environment}", string(sac.environment),
+		"{client_id}", clientID,
+	).Replace(sac.tokenFile)
 }
 
 func (sac *SaxoAuthClient) oauth2ToTokenInfo(token oauth2.Token, provider string) TokenInfo {
@@ -571,9 +849,16 @@ func (sac *SaxoAuthClient) oauth2ToTokenInfo(token oauth2.Token, provider string
 		Expiry:        token.Expiry,
 		RefreshExpiry: sac.calcRefreshTokenExpiry(token),
 		Provider:      provider,
+		Scopes:        grantedScopes(token),
 	}
 }
 
+// grantedScopes returns the scope of a token response, empty when it has none
+func grantedScopes(token oauth2.Token) []string {
+	scope, _ := token.Extra("scope").(string)
+	return strings.Fields(scope)
+}
+
 // calcRefreshTokenExpiry implements legacy logic for refresh token expiry calculation
 func (sac *SaxoAuthClient) calcRefreshTokenExpiry(token oauth2.Token) time.Time {
 	expiryTime := token.Expiry
@@ -679,13 +964,23 @@ func (sac *SaxoAuthClient) BuildRedirectURL(host string, provider string) string
 
 // GenerateAuthURL creates OAuth authorization URL with state parameter
 func (sac *SaxoAuthClient) GenerateAuthURL(provider string, state string) (string, error) {
+	return sac.generateAuthURL(provider, state)
+}
+
+// GenerateAuthURLWithRedirect is GenerateAuthURL for one login with its own redirect URL
+// The provider config is left untouched, so concurrent logins on different hosts do not interfere
+func (sac *SaxoAuthClient) GenerateAuthURLWithRedirect(provider string, state string, redirectURL string) (string, error) {
+	return sac.generateAuthURL(provider, state, oauth2.SetAuthURLParam("redirect_uri", redirectURL))
+}
+
+func (sac *SaxoAuthClient) generateAuthURL(provider string, state string, opts ...oauth2.AuthCodeOption) (string, error) {
 	config := sac.providerConfigs[provider]
 	if config == nil {
 		return "", fmt.Errorf("no OAuth config for provider: %s", provider)
 	}
 
 	// Generate authorization URL following legacy pattern
-	authURL := config.AuthCodeURL(state, oauth2.AccessTypeOffline)
+	authURL := config.AuthCodeURL(state, append([]oauth2.AuthCodeOption{oauth2.AccessTypeOffline}, opts...)...)
 
 	// Log environment for debugging (critical for SIM vs LIVE)
 	envName := "Unknown"
@@ -705,13 +1000,23 @@ func (sac *SaxoAuthClient) GenerateAuthURL(provider string, state string) (strin
 
 // ExchangeCodeForToken exchanges authorization code for access token (for web flow)
 func (sac *SaxoAuthClient) ExchangeCodeForToken(ctx context.Context, code string, provider string) error {
+	return sac.exchangeCodeForToken(ctx, code, provider)
+}
+
+// ExchangeCodeForTokenWithRedirect is ExchangeCodeForToken for a login started with
+// GenerateAuthURLWithRedirect - the exchange repeats that login's redirect URL
+func (sac *SaxoAuthClient) ExchangeCodeForTokenWithRedirect(ctx context.Context, code string, provider string, redirectURL string) error {
+	return sac.exchangeCodeForToken(ctx, code, provider, oauth2.SetAuthURLParam("redirect_uri", redirectURL))
+}
+
+func (sac *SaxoAuthClient) exchangeCodeForToken(ctx context.Context, code string, provider string, opts ...oauth2.AuthCodeOption) error {
 	config := sac.providerConfigs[provider]
 	if config == nil {
 		return fmt.Errorf("no OAuth config for provider: %s", provider)
 	}
 
 	// Exchange code for token following legacy callback pattern
-	token, err := config.Exchange(ctx, code)
+	token, err := config.Exchange(sac.oauthContext(ctx), code, opts...)
 	if err != nil {
 		sac.logger.Error("Failed to exchange authorization code for token",
 			"function", "ExchangeCodeForToken",
@@ -729,6 +1034,7 @@ func (sac *SaxoAuthClient) ExchangeCodeForToken(ctx context.Context, code string
 			"error", err)
 		return err
 	}
+	sac.publishTokenEvent(TokenRefreshed, tokenInfo, nil)
 
 	sac.logger.Info("Token obtained and stored",
 		"function", "ExchangeCodeForToken",
@@ -769,10 +1075,16 @@ func (sac *SaxoAuthClient) loginCLI(ctx context.Context, provider string) error
 	codeChan := make(chan string, 1)
 	errorChan := make(chan error, 1)
 
-	// Start temporary HTTP server for OAuth callback
-	server := &http.Server{Addr: ":" + callbackPort}
+	// Start temporary HTTP server for OAuth callback - its own mux, so repeated logins do not
+	// register on http.DefaultServeMux twice
+	mux := http.NewServeMux()
+	server := &http.Server{
+		Addr:              ":" + callbackPort,
+		Handler:           mux,
+		ReadHeaderTimeout: sac.requestTimeout,
+	}
 
-	http.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
 		// Verify state parameter
 		if r.URL.Query().Get("state") != state {
 			sac.logger.Warn("OAuth callback received invalid state parameter (CSRF protection)",
@@ -876,7 +1188,9 @@ func (sac *SaxoAuthClient) loginCLI(ctx context.Context, provider string) error
 	sac.logger.Info("Exchanging authorization code for access token",
 		"function", "loginCLI",
 		"provider", provider)
-	if err := sac.ExchangeCodeForToken(ctx, code, provider); err != nil {
+	exchangeCtx, exchangeCancel := RequestContext(ctx, sac.requestTimeout)
+	defer exchangeCancel()
+	if err := sac.ExchangeCodeForToken(exchangeCtx, code, provider); err != nil {
 		return fmt.Errorf("token exchange failed: %w", err)
 	}
 
@@ -0,0 +1,352 @@
+package saxo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultStateTTL bounds how long a user may take between LoginHandler and CallbackHandler
+const DefaultStateTTL = 10 * time.Minute
+
+// DefaultStateCookie binds a pending login to the browser that started it
+// Each login gets its own cookie (the name plus a suffix derived from the state), so logins in
+// several tabs do not overwrite each other
+const DefaultStateCookie = "saxo_oauth_state"
+
+// OAuthState is what LoginHandler remembers for CallbackHandler
+type OAuthState struct {
+	Provider    string
+	RedirectURL string // Redirect URL sent to the auth server - the token exchange must repeat it
+	ReturnTo    string // Local path to redirect to after login ("" = SuccessURL)
+	Created     time.Time
+}
+
+// StateStore keeps pending OAuth states between login and callback
+// Take must be one-time: a state is removed when it is taken (replayed callbacks fail)
+// Use a shared store (e.g. Redis) when the callback can reach a different instance than the login
+type StateStore interface {
+	Save(ctx context.Context, state string, data OAuthState) error
+	Take(ctx context.Context, state string) (OAuthState, bool, error)
+}
+
+// MemoryStateStore is an in-process StateStore that expires states after ttl
+type MemoryStateStore struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	states map[string]OAuthState
+}
+
+// NewMemoryStateStore creates a StateStore for single-instance apps (ttl <= 0 uses DefaultStateTTL)
+func NewMemoryStateStore(ttl time.Duration) *MemoryStateStore {
+	if ttl <= 0 {
+		ttl = DefaultStateTTL
+	}
+	return &MemoryStateStore{
+		ttl:    ttl,
+		states: make(map[string]OAuthState),
+	}
+}
+
+// Save implements StateStore and prunes expired states
+func (m *MemoryStateStore) Save(ctx context.Context, state string, data OAuthState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for key, pending := range m.states {
+		if now.Sub(pending.Created) > m.ttl {
+			delete(m.states, key)
+		}
+	}
+	m.states[state] = data
+	return nil
+}
+
+// Take implements StateStore
+func (m *MemoryStateStore) Take(ctx context.Context, state string) (OAuthState, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.states[state]
+	if !ok {
+		return OAuthState{}, false, nil
+	}
+	delete(m.states, state)
+	if time.Since(data.Created) > m.ttl {
+		return OAuthState{}, false, nil
+	}
+	return data, true, nil
+}
+
+// OAuthHandlerConfig configures OAuthHandlers - zero values use the defaults
+type OAuthHandlerConfig struct {
+	Provider    string     // Default: the auth client's provider, else DefaultProvider
+	RedirectURL string     // Callback URL registered with the app, "" = BuildRedirectURL(request host)
+	SuccessURL  string     // Where the browser lands after login, default "/"
+	FailureURL  string     // Where failed logins land with ?error=, "" = plain error response
+	States      StateStore // Default NewMemoryStateStore(DefaultStateTTL)
+	StateCookie string     // Cookie name prefix, default DefaultStateCookie
+	StateTTL    time.Duration
+
+	// OnLogin runs after the token is stored and the keeper started, e.g. to open a WebSocket
+	OnLogin func(ctx context.Context, provider string) error
+}
+
+// OAuthHandlers serves the browser login flow for web applications:
+//
+//	handlers := saxo.NewOAuthHandlers(authClient, saxo.OAuthHandlerConfig{SuccessURL: "/dashboard"}, logger)
+//	mux.Handle("GET /oauth/saxo/login", handlers.LoginHandler())
+//	mux.Handle("GET /oauth/saxo/callback", handlers.CallbackHandler())
+//
+// LoginHandler redirects to the auth server with a random state; CallbackHandler checks the state
+// against the store and the browser cookie (CSRF), exchanges the code and starts the keeper
+type OAuthHandlers struct {
+	auth   AuthClient
+	config OAuthHandlerConfig
+	logger *slog.Logger
+}
+
+// redirectAuthClient sends a per-login redirect URL without touching the shared provider config
+// AuthClients without it fall back to SetRedirectURL, which concurrent logins on different hosts race on
+type redirectAuthClient interface {
+	GenerateAuthURLWithRedirect(provider string, state string, redirectURL string) (string, error)
+	ExchangeCodeForTokenWithRedirect(ctx context.Context, code string, provider string, redirectURL string) error
+}
+
+// NewOAuthHandlers creates the login and callback handlers for auth
+func NewOAuthHandlers(auth AuthClient, config OAuthHandlerConfig, logger *slog.Logger) *OAuthHandlers {
+	if config.Provider == "" {
+		if named, ok := auth.(interface{ Provider() string }); ok {
+			config.Provider = named.Provider()
+		}
+	}
+	if config.Provider == "" {
+		config.Provider = DefaultProvider
+	}
+	if config.SuccessURL == "" {
+		config.SuccessURL = "/"
+	}
+	if config.StateCookie == "" {
+		config.StateCookie = DefaultStateCookie
+	}
+	if config.StateTTL <= 0 {
+		config.StateTTL = DefaultStateTTL
+	}
+	if config.States == nil {
+		config.States = NewMemoryStateStore(config.StateTTL)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &OAuthHandlers{auth: auth, config: config, logger: logger}
+}
+
+// Register mounts the handlers at GET /oauth/{provider}/login and GET /oauth/{provider}/callback
+// (the callback path BuildRedirectURL produces)
+func (h *OAuthHandlers) Register(mux *http.ServeMux) {
+	mux.Handle("GET "+h.LoginPath(), h.LoginHandler())
+	mux.Handle("GET "+h.CallbackPath(), h.CallbackHandler())
+}
+
+// LoginPath is the path Register mounts LoginHandler at
+func (h *OAuthHandlers) LoginPath() string {
+	return "/oauth/" + h.config.Provider + "/login"
+}
+
+// CallbackPath is the path Register mounts CallbackHandler at
+func (h *OAuthHandlers) CallbackPath() string {
+	return "/oauth/" + h.config.Provider + "/callback"
+}
+
+// LoginHandler starts the login - ?return_to=/path overrides SuccessURL for this login
+func (h *OAuthHandlers) LoginHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provider := h.config.Provider
+		redirectURL := h.config.RedirectURL
+		if redirectURL == "" {
+			redirectURL = h.auth.BuildRedirectURL(r.Host, provider)
+		}
+
+		state, err := generateRandomState()
+		if err != nil {
+			h.fail(w, r, http.StatusInternalServerError, fmt.Errorf("failed to generate state: %w", err))
+			return
+		}
+		pending := OAuthState{
+			Provider:    provider,
+			RedirectURL: redirectURL,
+			ReturnTo:    localPath(r.URL.Query().Get("return_to")),
+			Created:     time.Now(),
+		}
+		if err := h.config.States.Save(r.Context(), state, pending); err != nil {
+			h.fail(w, r, http.StatusInternalServerError, fmt.Errorf("failed to save state: %w", err))
+			return
+		}
+
+		authURL, err := h.authURL(provider, state, redirectURL)
+		if err != nil {
+			h.fail(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     h.stateCookieName(state),
+			Value:    state,
+			Path:     "/",
+			MaxAge:   int(h.config.StateTTL.Seconds()),
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode, // Sent on the top-level redirect back from the auth server
+		})
+
+		h.logger.Info("Redirecting to OAuth login",
+			"function", "LoginHandler",
+			"provider", provider,
+			"redirect_url", redirectURL)
+		http.Redirect(w, r, authURL, http.StatusFound)
+	})
+}
+
+// CallbackHandler completes the login on the redirect URL
+func (h *OAuthHandlers) CallbackHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		state := query.Get("state")
+
+		// Clear the cookie whatever the outcome - a state is single use
+		cookieName := h.stateCookieName(state)
+		http.SetCookie(w, &http.Cookie{
+			Name:     cookieName,
+			Value:    "",
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+
+		// CRITICAL: state must match both the store and this browser's cookie (CSRF / login fixation)
+		cookie, err := r.Cookie(cookieName)
+		if state == "" || err != nil || cookie.Value != state {
+			h.logger.Warn("OAuth callback state does not match browser cookie (CSRF protection)",
+				"function", "CallbackHandler",
+				"provider", h.config.Provider)
+			h.fail(w, r, http.StatusBadRequest, fmt.Errorf("invalid state parameter"))
+			return
+		}
+		pending, ok, err := h.config.States.Take(r.Context(), state)
+		if err != nil {
+			h.fail(w, r, http.StatusInternalServerError, fmt.Errorf("failed to load state: %w", err))
+			return
+		}
+		if !ok {
+			h.fail(w, r, http.StatusBadRequest, fmt.Errorf("unknown or expired state - start the login again"))
+			return
+		}
+
+		// Denied consent or auth server error
+		if oauthErr := query.Get("error"); oauthErr != "" {
+			if description := query.Get("error_description"); description != "" {
+				oauthErr += ": " + description
+			}
+			h.fail(w, r, http.StatusUnauthorized, fmt.Errorf("authorization failed: %s", oauthErr))
+			return
+		}
+		code := query.Get("code")
+		if code == "" {
+			h.fail(w, r, http.StatusBadRequest, fmt.Errorf("no authorization code received"))
+			return
+		}
+
+		// The exchange must send the redirect URL used for this login
+		if err := h.exchange(r.Context(), code, pending); err != nil {
+			h.fail(w, r, http.StatusBadGateway, fmt.Errorf("token exchange failed: %w", err))
+			return
+		}
+		h.auth.StartAuthenticationKeeper(pending.Provider)
+
+		if h.config.OnLogin != nil {
+			if err := h.config.OnLogin(r.Context(), pending.Provider); err != nil {
+				h.fail(w, r, http.StatusInternalServerError, fmt.Errorf("post-login hook failed: %w", err))
+				return
+			}
+		}
+
+		h.logger.Info("OAuth login completed",
+			"function", "CallbackHandler",
+			"provider", pending.Provider)
+
+		target := h.config.SuccessURL
+		if pending.ReturnTo != "" {
+			target = pending.ReturnTo
+		}
+		http.Redirect(w, r, target, http.StatusFound)
+	})
+}
+
+// authURL builds the auth server URL of one login with its redirect URL
+func (h *OAuthHandlers) authURL(provider, state, redirectURL string) (string, error) {
+	if auth, ok := h.auth.(redirectAuthClient); ok {
+		return auth.GenerateAuthURLWithRedirect(provider, state, redirectURL)
+	}
+	if err := h.auth.SetRedirectURL(provider, redirectURL); err != nil {
+		return "", err
+	}
+	return h.auth.GenerateAuthURL(provider, state)
+}
+
+// exchange trades code for a token, repeating the redirect URL of the login
+func (h *OAuthHandlers) exchange(ctx context.Context, code string, pending OAuthState) error {
+	if auth, ok := h.auth.(redirectAuthClient); ok {
+		return auth.ExchangeCodeForTokenWithRedirect(ctx, code, pending.Provider, pending.RedirectURL)
+	}
+	if err := h.auth.SetRedirectURL(pending.Provider, pending.RedirectURL); err != nil {
+		return err
+	}
+	return h.auth.ExchangeCodeForToken(ctx, code, pending.Provider)
+}
+
+// stateCookieName is the cookie of one login - a hash of state, which may contain characters
+// cookie names cannot
+func (h *OAuthHandlers) stateCookieName(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return h.config.StateCookie + "_" + hex.EncodeToString(sum[:8])
+}
+
+// fail redirects to FailureURL with ?error= or writes a plain error response
+func (h *OAuthHandlers) fail(w http.ResponseWriter, r *http.Request, status int, err error) {
+	h.logger.Error("OAuth web login failed",
+		"function", "OAuthHandlers",
+		"provider", h.config.Provider,
+		"error", err)
+
+	if h.config.FailureURL == "" {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	target, parseErr := url.Parse(h.config.FailureURL)
+	if parseErr != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	query := target.Query()
+	query.Set("error", err.Error())
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// localPath only allows same-site paths, so return_to cannot become an open redirect
+func localPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return ""
+	}
+	return path
+}
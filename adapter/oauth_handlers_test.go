@@ -0,0 +1,164 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func newTestOAuthHandlers(t *testing.T, config OAuthHandlerConfig) (*OAuthHandlers, *SaxoAuthClient) {
+	t.Helper()
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirectURI := r.FormValue("redirect_uri")
+		if r.FormValue("code") != "good-code" || (redirectURI != "http://app.local/oauth/saxo/callback" && redirectURI != "http://other.local/oauth/saxo/callback") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "web_token",
+			"refresh_token": "refresh_token",
+			"token_type":    "Bearer",
+			"expires_in":    1200,
+		})
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	configs := map[string]*oauth2.Config{
+		"saxo": {
+			ClientID: "test",
+			Endpoint: oauth2.Endpoint{AuthURL: "https://auth.example/authorize", TokenURL: tokenServer.URL + "/token"},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	sac := NewSaxoAuthClient(configs, tokenServer.URL, tokenServer.URL, &FileTokenStorage{basePath: t.TempDir()}, SaxoSIM, logger)
+	t.Cleanup(func() { sac.Shutdown(context.Background()) })
+
+	return NewOAuthHandlers(sac, config, logger), sac
+}
+
+// startLogin runs LoginHandler and returns the state sent to the auth server and the cookie
+func startLogin(t *testing.T, h *OAuthHandlers, target string) (string, *http.Cookie) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.LoginHandler().ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("Login: expected 302, got %d", rec.Code)
+	}
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil || !strings.HasPrefix(location.String(), "https://auth.example/authorize") {
+		t.Fatalf("Login redirected to %q", rec.Header().Get("Location"))
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != location.Query().Get("state") {
+		t.Fatalf("Expected state cookie matching the auth URL state, got %v", cookies)
+	}
+	return location.Query().Get("state"), cookies[0]
+}
+
+func callback(h *OAuthHandlers, query string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "http://app.local/oauth/saxo/callback?"+query, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	h.CallbackHandler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestOAuthHandlers_LoginAndCallback(t *testing.T) {
+	var hooked string
+	h, sac := newTestOAuthHandlers(t, OAuthHandlerConfig{
+		SuccessURL: "/dashboard",
+		OnLogin: func(ctx context.Context, provider string) error {
+			hooked = provider
+			return nil
+		},
+	})
+
+	state, cookie := startLogin(t, h, "http://app.local/oauth/saxo/login?return_to=/orders")
+
+	rec := callback(h, "state="+url.QueryEscape(state)+"&code=good-code", cookie)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/orders" {
+		t.Fatalf("Callback: expected redirect to /orders, got %d %q (%s)", rec.Code, rec.Header().Get("Location"), rec.Body.String())
+	}
+	if !sac.IsAuthenticated() {
+		t.Error("Expected token stored after callback")
+	}
+	if hooked != "saxo" {
+		t.Errorf("Expected OnLogin for saxo, got %q", hooked)
+	}
+
+	// States are single use
+	if rec := callback(h, "state="+url.QueryEscape(state)+"&code=good-code", cookie); rec.Code != http.StatusBadRequest {
+		t.Errorf("Replayed callback: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestOAuthHandlers_ConcurrentLogins(t *testing.T) {
+	h, sac := newTestOAuthHandlers(t, OAuthHandlerConfig{})
+
+	// Two tabs on different hosts - neither login may overwrite the other's redirect URL or cookie
+	stateA, cookieA := startLogin(t, h, "http://app.local/oauth/saxo/login")
+	stateB, cookieB := startLogin(t, h, "http://other.local/oauth/saxo/login")
+	if cookieA.Name == cookieB.Name {
+		t.Fatalf("Expected a cookie per login, both are %q", cookieA.Name)
+	}
+	if redirectURL := sac.GetOAuthConfig("saxo").RedirectURL; redirectURL != "" {
+		t.Errorf("Expected the provider config untouched, redirect URL is %q", redirectURL)
+	}
+
+	for _, login := range []struct{ state, host string }{{stateA, "app.local"}, {stateB, "other.local"}} {
+		req := httptest.NewRequest("GET", "http://"+login.host+"/oauth/saxo/callback?state="+url.QueryEscape(login.state)+"&code=good-code", nil)
+		req.AddCookie(cookieA)
+		req.AddCookie(cookieB)
+		rec := httptest.NewRecorder()
+		h.CallbackHandler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/" {
+			t.Errorf("Callback on %s: expected redirect to /, got %d %q (%s)", login.host, rec.Code, rec.Header().Get("Location"), rec.Body.String())
+		}
+	}
+}
+
+func TestOAuthHandlers_RejectsForgedCallback(t *testing.T) {
+	h, sac := newTestOAuthHandlers(t, OAuthHandlerConfig{FailureURL: "/login-failed"})
+	state, cookie := startLogin(t, h, "http://app.local/oauth/saxo/login")
+
+	// Attacker-initiated callback in a browser without the cookie
+	if rec := callback(h, "state="+url.QueryEscape(state)+"&code=good-code", nil); rec.Code != http.StatusFound ||
+		!strings.HasPrefix(rec.Header().Get("Location"), "/login-failed?error=") {
+		t.Errorf("Missing cookie: expected failure redirect, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if sac.IsAuthenticated() {
+		t.Error("Forged callback must not store a token")
+	}
+
+	// Denied consent is reported, not exchanged
+	state, cookie = startLogin(t, h, "http://app.local/oauth/saxo/login")
+	rec := callback(h, "state="+url.QueryEscape(state)+"&error=access_denied", cookie)
+	if location := rec.Header().Get("Location"); !strings.Contains(location, "access_denied") {
+		t.Errorf("Expected access_denied in failure redirect, got %q", location)
+	}
+}
+
+func TestLocalPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"/orders":              "/orders",
+		"//evil.example":       "",
+		"https://evil.example": "",
+		"/\\evil.example":      "",
+		"":                     "",
+	} {
+		if got := localPath(path); got != want {
+			t.Errorf("localPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
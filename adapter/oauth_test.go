@@ -0,0 +1,354 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+	"golang.org/x/oauth2"
+)
+
+// newTestAuthServer serves /token (refresh grant) and /authorize (WebSocket re-authorization)
+func newTestAuthServer(t *testing.T, tokenCalls, authorizeCalls *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewTLSServer(testAuthHandler(tokenCalls, authorizeCalls))
+}
+
+func testAuthHandler(tokenCalls, authorizeCalls *int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			n := atomic.AddInt32(tokenCalls, 1)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token":  fmt.Sprintf("refreshed_token_%d", n),
+				"refresh_token": "refresh_token",
+				"token_type":    "Bearer",
+				"expires_in":    1200,
+			})
+		case "/authorize":
+			atomic.AddInt32(authorizeCalls, 1)
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func newTestSaxoAuthClient(t *testing.T, serverURL string, token TokenInfo, opts ...Option) *SaxoAuthClient {
+	t.Helper()
+	configs := map[string]*oauth2.Config{
+		"saxo": {
+			ClientID: "test",
+			Endpoint: oauth2.Endpoint{TokenURL: serverURL + "/token"},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	storage := &FileTokenStorage{basePath: t.TempDir()}
+	sac := NewSaxoAuthClient(configs, serverURL, serverURL, storage, SaxoSIM, logger, opts...)
+	sac.currentToken = token
+	return sac
+}
+
+func TestReauthorizeWebSocket_FreshTokenIsIdempotent(t *testing.T) {
+	var tokenCalls, authorizeCalls int32
+	server := newTestAuthServer(t, &tokenCalls, &authorizeCalls)
+	defer server.Close()
+
+	sac := newTestSaxoAuthClient(t, server.URL, TokenInfo{
+		Provider:     "saxo",
+		AccessToken:  "fresh_token",
+		RefreshToken: "refresh_token",
+		Expiry:       time.Now().Add(15 * time.Minute),
+	})
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, server.Client())
+
+	// Twice in a row - e.g. keeper refreshed moments earlier, then WebSocket timer fires
+	for i := 0; i < 2; i++ {
+		if err := sac.ReauthorizeWebSocket(ctx, "ctx-1"); err != nil {
+			t.Fatalf("ReauthorizeWebSocket call %d failed: %v", i+1, err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&tokenCalls); got != 0 {
+		t.Errorf("Expected no token refresh for fresh token, got %d", got)
+	}
+	if got := atomic.LoadInt32(&authorizeCalls); got != 2 {
+		t.Errorf("Expected 2 authorize calls, got %d", got)
+	}
+}
+
+func TestReauthorizeWebSocket_RefreshesExpiringTokenOnce(t *testing.T) {
+	var tokenCalls, authorizeCalls int32
+	server := newTestAuthServer(t, &tokenCalls, &authorizeCalls)
+	defer server.Close()
+
+	sac := newTestSaxoAuthClient(t, server.URL, TokenInfo{
+		Provider:     "saxo",
+		AccessToken:  "expiring_token",
+		RefreshToken: "refresh_token",
+		Expiry:       time.Now().Add(time.Minute), // Inside earlyRefreshTime window
+	})
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, server.Client())
+
+	if err := sac.ReauthorizeWebSocket(ctx, "ctx-1"); err != nil {
+		t.Fatalf("ReauthorizeWebSocket failed: %v", err)
+	}
+	if err := sac.ReauthorizeWebSocket(ctx, "ctx-1"); err != nil {
+		t.Fatalf("Second ReauthorizeWebSocket failed: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&tokenCalls); got != 1 {
+		t.Errorf("Expected exactly 1 token refresh, got %d", got)
+	}
+	if sac.currentToken.AccessToken == "expiring_token" {
+		t.Error("Expected token to be rotated")
+	}
+}
+
+func TestSaxoAuthClient_TokenEvents(t *testing.T) {
+	var tokenCalls, authorizeCalls int32
+	server := newTestAuthServer(t, &tokenCalls, &authorizeCalls)
+	defer server.Close()
+
+	oldExpiry := time.Now().Add(30 * time.Second)
+	sac := newTestSaxoAuthClient(t, server.URL, TokenInfo{
+		Provider:     "saxo",
+		AccessToken:  "expiring_token",
+		RefreshToken: "refresh_token",
+		Expiry:       oldExpiry,
+	})
+	events := sac.TokenEvents()
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, server.Client())
+	if err := sac.ReauthorizeWebSocket(ctx, "ctx-1"); err != nil {
+		t.Fatalf("ReauthorizeWebSocket failed: %v", err)
+	}
+
+	expected := []TokenEventType{TokenAboutToExpire, TokenRefreshed}
+	for _, want := range expected {
+		select {
+		case event := <-events:
+			if event.Type != want {
+				t.Errorf("Expected event %s, got %s", want, event.Type)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for %s event", want)
+		}
+	}
+
+	if !sac.GetTokenExpiry().After(oldExpiry) {
+		t.Errorf("Expected GetTokenExpiry to reflect refreshed token, got %v", sac.GetTokenExpiry())
+	}
+	if sac.GetRefreshExpiry().IsZero() {
+		t.Error("Expected non-zero refresh expiry")
+	}
+}
+
+func TestSaxoAuthClient_KeeperLifecycle(t *testing.T) {
+	var tokenCalls, authorizeCalls int32
+	server := newTestAuthServer(t, &tokenCalls, &authorizeCalls)
+	defer server.Close()
+
+	sac := newTestSaxoAuthClient(t, server.URL, TokenInfo{
+		Provider:      "saxo",
+		AccessToken:   "fresh_token",
+		RefreshToken:  "refresh_token",
+		Expiry:        time.Now().Add(15 * time.Minute),
+		RefreshExpiry: time.Now().Add(time.Hour),
+	})
+
+	before := runtime.NumGoroutine()
+
+	// Concurrent and repeated starts must not leak goroutines
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sac.StartAuthenticationKeeper("saxo")
+			sac.StartTokenEarlyRefresh(context.Background(), nil, nil)
+		}()
+	}
+	wg.Wait()
+
+	if delta := runtime.NumGoroutine() - before; delta > 2 {
+		t.Errorf("Expected at most 2 background goroutines, got %d", delta)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := sac.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	// Starting after shutdown is a no-op
+	sac.StartAuthenticationKeeper("saxo")
+	time.Sleep(10 * time.Millisecond)
+	if delta := runtime.NumGoroutine() - before; delta > 0 {
+		t.Errorf("Expected no goroutines after Shutdown, got %d extra", delta)
+	}
+
+	// Shutdown is idempotent
+	if err := sac.Shutdown(ctx); err != nil {
+		t.Errorf("Second Shutdown failed: %v", err)
+	}
+}
+
+func TestSaxoAuthClient_ShutdownWaitsForStoppedLifecycles(t *testing.T) {
+	sac := newTestSaxoAuthClient(t, "http://127.0.0.1", TokenInfo{})
+
+	// A goroutine of the old lifecycle that has not returned when Stop swaps lifecycles
+	old := sac.currentLifecycle()
+	old.wg.Add(1)
+	sac.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := sac.Shutdown(ctx); err == nil {
+		t.Fatal("Expected Shutdown to wait for the stopped lifecycle's goroutine")
+	}
+
+	old.wg.Done()
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := sac.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown after the goroutine returned failed: %v", err)
+	}
+}
+
+func TestSaxoAuthClient_KeeperTickWithFreshToken(t *testing.T) {
+	var tokenCalls, authorizeCalls int32
+	server := newTestAuthServer(t, &tokenCalls, &authorizeCalls)
+	defer server.Close()
+
+	clock := mocktesting.NewFakeClock(time.Now())
+	token := TokenInfo{
+		Provider:      "saxo",
+		AccessToken:   "fresh_token",
+		RefreshToken:  "refresh_token",
+		Expiry:        clock.Now().Add(24 * time.Hour),
+		RefreshExpiry: clock.Now().Add(30 * 24 * time.Hour),
+	}
+	sac := newTestSaxoAuthClient(t, server.URL, token, WithClock(clock))
+	if err := sac.tokenStorage.SaveToken(sac.getTokenFilename("saxo"), &token); err != nil {
+		t.Fatalf("SaveToken failed: %v", err)
+	}
+	events := sac.TokenEvents()
+
+	sac.StartAuthenticationKeeper("saxo")
+	defer sac.Shutdown(context.Background())
+
+	// The keeper ticks hourly on the refresh token; the access token stays far from its early refresh window
+	for i := 0; i < 3; i++ {
+		if err := clock.WaitForWaiters(1, time.Second); err != nil {
+			t.Fatalf("Keeper not waiting: %v", err)
+		}
+		clock.Advance(maxKeeperInterval)
+		time.Sleep(20 * time.Millisecond)
+	}
+	for {
+		select {
+		case event := <-events:
+			if event.Type == TokenAboutToExpire {
+				t.Fatalf("Unexpected %s event for a token valid for hours", event.Type)
+			}
+		default:
+			if got := atomic.LoadInt32(&tokenCalls); got != 0 {
+				t.Errorf("Expected no refresh, got %d", got)
+			}
+			return
+		}
+	}
+}
+
+func TestSaxoAuthClient_LongLivedRefreshToken(t *testing.T) {
+	var tokenCalls, authorizeCalls int32
+	server := newTestAuthServer(t, &tokenCalls, &authorizeCalls)
+	defer server.Close()
+
+	// Live offline_access token: the refresh response repeats the refresh token without refresh_token_expires_in
+	refreshExpiry := time.Now().Add(300 * 24 * time.Hour).Truncate(time.Second)
+	sac := newTestSaxoAuthClient(t, server.URL, TokenInfo{
+		Provider:      "saxo",
+		AccessToken:   "expired_token",
+		RefreshToken:  "refresh_token",
+		Expiry:        time.Now().Add(-time.Minute),
+		RefreshExpiry: refreshExpiry,
+	})
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, server.Client())
+	if err := sac.RefreshToken(ctx); err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+	if !sac.GetRefreshExpiry().Equal(refreshExpiry) {
+		t.Errorf("RefreshExpiry = %v, want persisted %v (not the 24h fallback)", sac.GetRefreshExpiry(), refreshExpiry)
+	}
+
+	saved, err := sac.tokenStorage.LoadToken(sac.getTokenFilename("saxo"))
+	if err != nil || !saved.RefreshExpiry.Equal(refreshExpiry) {
+		t.Errorf("Stored RefreshExpiry = %+v, %v", saved, err)
+	}
+
+	if got := refreshInterval(refreshExpiry, time.Now()); got != maxKeeperInterval {
+		t.Errorf("refreshInterval = %v, want keeper cap %v", got, maxKeeperInterval)
+	}
+}
+
+func TestSaxoAuthClient_ReloginRequired(t *testing.T) {
+	var tokenCalls, authorizeCalls int32
+	server := newTestAuthServer(t, &tokenCalls, &authorizeCalls)
+	defer server.Close()
+
+	sac := newTestSaxoAuthClient(t, server.URL, TokenInfo{
+		Provider:      "saxo",
+		AccessToken:   "valid_token",
+		RefreshToken:  "refresh_token",
+		Expiry:        time.Now().Add(15 * time.Minute),
+		RefreshExpiry: time.Now().Add(3 * 24 * time.Hour),
+	})
+	sac.reloginThreshold = 7 * 24 * time.Hour
+	events := sac.TokenEvents()
+
+	sac.StartAuthenticationKeeper("saxo")
+	defer sac.Shutdown(context.Background())
+
+	select {
+	case event := <-events:
+		if event.Type != TokenReloginRequired {
+			t.Errorf("Expected %s, got %s", TokenReloginRequired, event.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for relogin event")
+	}
+
+	// Prompted once per refresh expiry
+	var prompted time.Time
+	token := sac.currentToken
+	sac.checkRelogin(token, &prompted)
+	sac.checkRelogin(token, &prompted)
+	count := 0
+	for done := false; !done; {
+		select {
+		case <-events:
+			count++
+		case <-time.After(50 * time.Millisecond):
+			done = true
+		}
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 relogin event for repeated checks, got %d", count)
+	}
+}
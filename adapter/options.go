@@ -0,0 +1,125 @@
+package saxo
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// DefaultUserAgent is sent on REST and WebSocket requests unless overridden with WithUserAgent
+// Following legacy pivot-web client identification
+const DefaultUserAgent = "PivotWeb/2.0"
+
+// DefaultTimeout is the maximum duration of a single request unless overridden with WithTimeout
+// Applies to every REST call, subscription POST and the WebSocket handshake
+const DefaultTimeout = 30 * time.Second
+
+// ClientOptions holds optional construction settings shared by the REST client
+// (NewSaxoBrokerClient) and the streaming client (websocket.NewSaxoWebSocketClient)
+type ClientOptions struct {
+	HTTPClient           *http.Client      // nil = use authClient.GetHTTPClient (OAuth2 auto-refresh)
+	Timeout              time.Duration     // Per-request maximum, 0 = caller's ctx only
+	UserAgent            string            // User-Agent header value
+	BaseURL              string            // Overrides the positional baseURL argument when set
+	Logger               *slog.Logger      // Overrides the positional logger argument when set
+	CacheTTLs            *CacheTTLs        // REST response cache lifetimes, nil = DefaultCacheTTLs (REST client only)
+	Provider             string            // OAuth provider key (auth client only), "" = single configured provider or DefaultProvider
+	TokenFile            string            // Token filename template (auth client only), "" = DefaultTokenFileTemplate
+	ReloginThreshold     time.Duration     // TokenReloginRequired lead time (auth client only), 0 = disabled
+	OnAuthExpired        func(TokenEvent)  // Called once the refresh token expires (auth client only), nil = events only
+	Interceptors         []Interceptor     // Request/response hooks (REST client only)
+	DryRun               bool              // Simulate order mutations (REST client only)
+	DryRunPrecheck       bool              // Dry run prechecks orders for margin numbers
+	OrderValidation      OrderValidation   // Instrument constraint checks in PlaceOrder (REST client only)
+	Clock                Clock             // Time source for timers and expiry, nil = SystemClock
+	InstrumentStore      *InstrumentStore  // Shared UIC <-> ticker metadata, nil = none
+	RejectWarnings       []string          // Order warning classes that fail PlaceOrder (REST client only)
+	DefaultAccount       string            // Account GetBalance is scoped to, "" = all accounts (REST client only)
+	Transport            *TransportConfig  // Proxy, TLS and dial settings, nil = Go defaults (or HTTPClient's)
+	ManualOrders         bool              // Default ManualOrder flag of orders, false = automated (REST client only)
+	ClientKey            string            // Client the portfolio queries are scoped to, "" = token owner (REST client only)
+	BarCutoff            BarCutoff         // Daily bar end for GetHistoricalData without cutoffTime, zero = UTC midnight (REST client only)
+	InstrumentBarCutoffs map[int]BarCutoff // Per-UIC BarCutoff overrides (REST client only)
+	HistoricalPriceSide  PriceSide         // Primary OHLC series of bid/ask chart data, "" = PriceSideMid (REST client only)
+}
+
+// Option configures a client at construction time
+type Option func(*ClientOptions)
+
+// WithHTTPClient makes the client use httpClient instead of the auth client's OAuth2 client
+// The bearer token is still taken from AuthClient.GetAccessToken on every request
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *ClientOptions) {
+		o.HTTPClient = httpClient
+	}
+}
+
+// WithTimeout overrides DefaultTimeout
+// The caller's ctx deadline still applies - whichever expires first wins
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *ClientOptions) {
+		o.Timeout = timeout
+	}
+}
+
+// WithUserAgent overrides DefaultUserAgent
+func WithUserAgent(userAgent string) Option {
+	return func(o *ClientOptions) {
+		o.UserAgent = userAgent
+	}
+}
+
+// WithBaseURL overrides the API base URL passed to the constructor
+func WithBaseURL(baseURL string) Option {
+	return func(o *ClientOptions) {
+		o.BaseURL = baseURL
+	}
+}
+
+// WithLogger overrides the logger passed to the constructor
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *ClientOptions) {
+		o.Logger = logger
+	}
+}
+
+// WithInstrumentStore shares store between clients: the REST client enriches tickers from it and
+// records instrument details, the WebSocket client resolves tickers to UICs when subscribing
+func WithInstrumentStore(store *InstrumentStore) Option {
+	return func(o *ClientOptions) {
+		o.InstrumentStore = store
+	}
+}
+
+// NewClientOptions applies opts on top of the defaults
+// Exported so the websocket package resolves options exactly like the REST client
+func NewClientOptions(baseURL string, logger *slog.Logger, opts ...Option) ClientOptions {
+	o := ClientOptions{
+		Timeout:   DefaultTimeout,
+		UserAgent: DefaultUserAgent,
+		BaseURL:   baseURL,
+		Logger:    logger,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	if o.Clock == nil {
+		o.Clock = SystemClock
+	}
+	return o
+}
+
+// RequestContext bounds ctx by the per-request timeout policy
+// timeout <= 0 leaves the caller's deadline (if any) as the only limit
+func RequestContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
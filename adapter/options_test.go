@@ -0,0 +1,106 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNewClientOptions_Defaults(t *testing.T) {
+	o := NewClientOptions("https://example.test", nil)
+
+	if o.Timeout != DefaultTimeout {
+		t.Errorf("Expected default timeout %v, got %v", DefaultTimeout, o.Timeout)
+	}
+	if o.UserAgent != DefaultUserAgent {
+		t.Errorf("Expected default user agent %s, got %s", DefaultUserAgent, o.UserAgent)
+	}
+	if o.BaseURL != "https://example.test" {
+		t.Errorf("Expected positional base URL, got %s", o.BaseURL)
+	}
+	if o.Logger == nil {
+		t.Error("Expected fallback logger, got nil")
+	}
+}
+
+func TestSaxoBrokerClient_Options(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	authClient := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "mock_token",
+		shouldError:   false,
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, "http://unused.invalid", logger,
+		WithBaseURL(mockServer.GetBaseURL()),
+		WithHTTPClient(&http.Client{Timeout: 5 * time.Second}),
+		WithUserAgent("saxo-adapter-test/1.0"),
+		WithTimeout(5*time.Second))
+
+	if client.timeout != 5*time.Second {
+		t.Errorf("Expected timeout 5s, got %v", client.timeout)
+	}
+
+	_, err := client.PlaceOrder(context.Background(), OrderRequest{
+		Instrument: createTestInstrument("EURUSD", 21, "FxSpot"),
+		Side:       "Buy",
+		Size:       1000,
+		Price:      1.0850,
+		OrderType:  "Limit",
+		Duration:   Duration(DurationDayOrder),
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+
+	requests := mockServer.GetRequests()
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(requests))
+	}
+	if got := requests[0].Headers["User-Agent"]; got != "saxo-adapter-test/1.0" {
+		t.Errorf("Expected custom User-Agent, got %q", got)
+	}
+	if got := requests[0].Headers["Authorization"]; got != "Bearer mock_token" {
+		t.Errorf("Expected bearer token on injected HTTP client, got %q", got)
+	}
+}
+
+func TestSaxoBrokerClient_RequestTimeout(t *testing.T) {
+	// Slow endpoint - must be cut off by the per-request timeout, not the caller's ctx
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slowServer.Close()
+
+	authClient := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "mock_token",
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, slowServer.URL, logger, WithTimeout(100*time.Millisecond))
+
+	start := time.Now()
+	_, err := client.GetAccounts(context.Background())
+	if err == nil {
+		t.Fatal("Expected timeout error, got nil")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Request not bounded by timeout, took %v", elapsed)
+	}
+}
@@ -0,0 +1,38 @@
+package saxo
+
+import "fmt"
+
+// IsCashAmount reports whether the order is placed by monetary value (CashAmount) instead of Size
+func (r OrderRequest) IsCashAmount() bool {
+	return r.OrderAmountType == AmountTypeCashAmount
+}
+
+// ResolveAmount returns the Saxo AmountType and Amount the order is sent with, or why the
+// asset type does not accept it - lets other BrokerClient implementations apply the same rules
+func (r OrderRequest) ResolveAmount() (amountType string, amount float64, err error) {
+	return orderAmount(r)
+}
+
+// orderAmount resolves the Saxo AmountType and Amount of req
+// Asset types with explicit handling validate and default the type (see assetTypeHandlers),
+// others send the requested type as is ("" = left out, Saxo assumes Quantity)
+func orderAmount(req OrderRequest) (amountType string, amount float64, err error) {
+	amountType = req.OrderAmountType
+	if handler, ok := assetTypeHandlerFor(req.Instrument.AssetType); ok {
+		if amountType, err = handler.amountType(req.Instrument.AssetType, req.OrderAmountType); err != nil {
+			return "", 0, err
+		}
+	}
+
+	switch amountType {
+	case AmountTypeCashAmount:
+		if req.CashAmount <= 0 {
+			return "", 0, fmt.Errorf("CashAmount order requires a positive CashAmount, got %v", req.CashAmount)
+		}
+		return amountType, req.CashAmount, nil
+	case "", AmountTypeQuantity:
+		return amountType, float64(req.Size), nil
+	default:
+		return "", 0, fmt.Errorf("unknown OrderAmountType %q", amountType)
+	}
+}
@@ -0,0 +1,65 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestSaxoBrokerClient_CashAmountOrder(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+	ctx := context.Background()
+
+	// Invest 1000 of AAPL - Size is ignored
+	order := OrderRequest{
+		Instrument: createTestInstrument("AAPL:xnas", 211, AssetTypeStock),
+		AccountKey: "acc1", Side: "Buy", Size: 7, OrderType: "Market",
+		OrderAmountType: AmountTypeCashAmount, CashAmount: 1000,
+	}
+	if _, err := client.PlaceOrder(ctx, order); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	requests := mockServer.AssertRequested(t, "POST", "/trade/v2/orders", 1)
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(requests[0].Body), &body); err != nil {
+		t.Fatalf("Invalid body: %v", err)
+	}
+	if body["AmountType"] != AmountTypeCashAmount || body["Amount"] != 1000.0 {
+		t.Errorf("Expected CashAmount 1000, got AmountType %v Amount %v", body["AmountType"], body["Amount"])
+	}
+
+	tests := []struct {
+		name  string
+		order OrderRequest
+	}{
+		{"missing cash amount", OrderRequest{Instrument: order.Instrument, Side: "Buy", OrderType: "Market", OrderAmountType: AmountTypeCashAmount}},
+		{"FX by value", OrderRequest{Instrument: createTestInstrument("EURUSD", 21, AssetTypeFxSpot), Side: "Buy", OrderType: "Market", OrderAmountType: AmountTypeCashAmount, CashAmount: 1000}},
+		{"unknown amount type", OrderRequest{Instrument: createTestInstrument("XYZ", 99, "Bond"), Side: "Buy", Size: 1, OrderType: "Market", OrderAmountType: "Nominal"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.PlaceOrder(ctx, tt.order); err == nil {
+				t.Error("Expected PlaceOrder to fail")
+			}
+		})
+	}
+	mockServer.AssertRequested(t, "POST", "/trade/v2/orders", 1)
+}
+
+func TestCheckOrderConstraints_CashAmountSkipsSize(t *testing.T) {
+	detail := InstrumentDetail{Uic: 211, TickSize: 0.01, LotSize: 1, MinimumTradeSize: 10}
+	req := OrderRequest{
+		Instrument: createTestInstrument("AAPL:xnas", 211, AssetTypeStock), Side: "Buy", OrderType: "Market",
+		OrderAmountType: AmountTypeCashAmount, CashAmount: 250,
+	}
+	if _, err := CheckOrderConstraints(req, detail, OrderValidationStrict); err != nil {
+		t.Errorf("CashAmount order should skip size constraints: %v", err)
+	}
+}
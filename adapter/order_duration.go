@@ -0,0 +1,108 @@
+package saxo
+
+import (
+	"fmt"
+	"time"
+)
+
+// Order duration types (OrderDuration.Type)
+const (
+	DurationDayOrder          = "DayOrder"
+	DurationGoodTillCancel    = "GoodTillCancel"
+	DurationGoodTillDate      = "GoodTillDate"
+	DurationImmediateOrCancel = "ImmediateOrCancel"
+	DurationFillOrKill        = "FillOrKill"
+	DurationAtTheOpening      = "AtTheOpening"
+	DurationAtTheClose        = "AtTheClose"
+)
+
+// saxoExpiryLayout is the ExpirationDateTime format Saxo accepts (no zone, UTC)
+const saxoExpiryLayout = "2006-01-02T15:04:05"
+
+// OrderDuration is how long an order stays working
+// Build GoodTillDate durations with GoodTillDate (whole day) or GoodTillTime (exact moment)
+type OrderDuration struct {
+	Type string // DurationDayOrder, DurationGoodTillDate, ... "" = DayOrder when placing
+	// Expiry is required for GoodTillDate and must be zero otherwise
+	// With ExpiryHasTime false only the calendar date of Expiry counts (as written, no zone shift),
+	// with ExpiryHasTime true the exact moment is sent, converted to UTC
+	Expiry        time.Time
+	ExpiryHasTime bool
+}
+
+// Duration returns a duration of durationType without expiry, e.g. Duration(DurationGoodTillCancel)
+func Duration(durationType string) OrderDuration {
+	return OrderDuration{Type: durationType}
+}
+
+// GoodTillDate keeps the order working through the end of day's calendar date
+func GoodTillDate(day time.Time) OrderDuration {
+	return OrderDuration{Type: DurationGoodTillDate, Expiry: day}
+}
+
+// GoodTillTime keeps the order working until the moment at
+func GoodTillTime(at time.Time) OrderDuration {
+	return OrderDuration{Type: DurationGoodTillDate, Expiry: at, ExpiryHasTime: true}
+}
+
+// String formats the duration for logs, e.g. "GoodTillDate 2026-12-31"
+func (d OrderDuration) String() string {
+	if d.Expiry.IsZero() {
+		return d.Type
+	}
+	if d.ExpiryHasTime {
+		return d.Type + " " + d.Expiry.UTC().Format(time.RFC3339)
+	}
+	return d.Type + " " + d.Expiry.Format("2006-01-02")
+}
+
+// Validate checks that GoodTillDate has an expiry that is not in the past (relative to now),
+// and that other duration types have none
+func (d OrderDuration) Validate(now time.Time) error {
+	if d.Type != DurationGoodTillDate {
+		if !d.Expiry.IsZero() {
+			return fmt.Errorf("%s does not take an expiry, only %s does", d.Type, DurationGoodTillDate)
+		}
+		return nil
+	}
+	if d.Expiry.IsZero() {
+		return fmt.Errorf("%s requires an expiry", DurationGoodTillDate)
+	}
+	if d.ExpiryHasTime {
+		if !d.Expiry.After(now) {
+			return fmt.Errorf("%s expiry %s is not in the future", DurationGoodTillDate, d.Expiry.UTC().Format(time.RFC3339))
+		}
+		return nil
+	}
+	expiryDay := time.Date(d.Expiry.Year(), d.Expiry.Month(), d.Expiry.Day(), 0, 0, 0, 0, time.UTC)
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if expiryDay.Before(today) {
+		return fmt.Errorf("%s expiry %s is in the past", DurationGoodTillDate, d.Expiry.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// withDefault returns d, or a DayOrder when no type is set
+func (d OrderDuration) withDefault() OrderDuration {
+	if d.Type == "" {
+		d.Type = DurationDayOrder
+	}
+	return d
+}
+
+// toSaxo builds the Saxo OrderDuration object
+func (d OrderDuration) toSaxo() map[string]interface{} {
+	duration := map[string]interface{}{"DurationType": d.Type}
+	if d.Expiry.IsZero() {
+		return duration
+	}
+	if d.ExpiryHasTime {
+		duration["ExpirationDateTime"] = d.Expiry.UTC().Format(saxoExpiryLayout)
+		duration["ExpirationDateContainsTime"] = true
+	} else {
+		duration["ExpirationDateTime"] = time.Date(d.Expiry.Year(), d.Expiry.Month(), d.Expiry.Day(), 0, 0, 0, 0, time.UTC).Format(saxoExpiryLayout)
+		duration["ExpirationDateContainsTime"] = false
+	}
+	return duration
+}
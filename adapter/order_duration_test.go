@@ -0,0 +1,60 @@
+package saxo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOrderDuration_Validate(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		duration OrderDuration
+		wantErr  bool
+	}{
+		{"day order", Duration(DurationDayOrder), false},
+		{"unset", OrderDuration{}, false},
+		{"good till cancel with expiry", OrderDuration{Type: DurationGoodTillCancel, Expiry: now.Add(time.Hour)}, true},
+		{"good till date without expiry", Duration(DurationGoodTillDate), true},
+		{"good till date today", GoodTillDate(now), false},
+		{"good till date yesterday", GoodTillDate(now.AddDate(0, 0, -1)), true},
+		{"good till time ahead", GoodTillTime(now.Add(time.Minute)), false},
+		{"good till time passed", GoodTillTime(now.Add(-time.Minute)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.duration.Validate(now)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOrderDuration_toSaxo(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	// Exact moments are sent in UTC
+	at := GoodTillTime(time.Date(2026, 3, 10, 16, 30, 0, 0, newYork)).toSaxo()
+	if at["ExpirationDateTime"] != "2026-03-10T20:30:00" || at["ExpirationDateContainsTime"] != true {
+		t.Errorf("Unexpected GoodTillTime payload %v", at)
+	}
+
+	// Whole days keep the calendar date as written, even when late in a zone west of UTC
+	day := GoodTillDate(time.Date(2026, 3, 10, 23, 0, 0, 0, newYork)).toSaxo()
+	if day["ExpirationDateTime"] != "2026-03-10T00:00:00" || day["ExpirationDateContainsTime"] != false {
+		t.Errorf("Unexpected GoodTillDate payload %v", day)
+	}
+	if day["DurationType"] != DurationGoodTillDate {
+		t.Errorf("Expected DurationType %s, got %v", DurationGoodTillDate, day["DurationType"])
+	}
+
+	plain := Duration(DurationGoodTillCancel).toSaxo()
+	if _, ok := plain["ExpirationDateTime"]; ok {
+		t.Errorf("GoodTillCancel should not carry an expiry, got %v", plain)
+	}
+}
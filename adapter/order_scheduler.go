@@ -0,0 +1,391 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrMarketClosed is matched (errors.Is) by the *MarketClosedError of OrderScheduler.Submit
+var ErrMarketClosed = errors.New("market closed")
+
+// MarketClosedError is returned by OrderScheduler.Submit when an order is not placed or queued
+// because the instrument's market is closed
+type MarketClosedError struct {
+	Instrument Instrument
+	NextOpen   time.Time // Zero when the schedule has no open ahead
+}
+
+func (e *MarketClosedError) Error() string {
+	if e.NextOpen.IsZero() {
+		return fmt.Sprintf("market closed for %s: no open in the trading schedule", e.Instrument.Ticker)
+	}
+	return fmt.Sprintf("market closed for %s until %s", e.Instrument.Ticker, e.NextOpen.UTC().Format(time.RFC3339))
+}
+
+func (e *MarketClosedError) Unwrap() error {
+	return ErrMarketClosed
+}
+
+// ClosedMarketPolicy is what OrderScheduler.Submit does with an order while the market is closed
+type ClosedMarketPolicy int
+
+const (
+	QueueUntilOpen   ClosedMarketPolicy = iota // Hold the order locally and place it at the next open
+	RejectWhenClosed                           // Fail with *MarketClosedError
+)
+
+// ScheduledOrderState is the lifecycle state of an order handled by OrderScheduler
+type ScheduledOrderState string
+
+const (
+	ScheduledOrderQueued    ScheduledOrderState = "Queued"    // Waiting for the market to open
+	ScheduledOrderSubmitted ScheduledOrderState = "Submitted" // Placed with PlaceOrder, Response is set
+	ScheduledOrderFailed    ScheduledOrderState = "Failed"    // PlaceOrder failed, Err is set
+	ScheduledOrderRejected  ScheduledOrderState = "Rejected"  // Market closed and not queued, Err is a *MarketClosedError
+	ScheduledOrderCancelled ScheduledOrderState = "Cancelled" // Removed from the queue by Cancel or Shutdown
+)
+
+// ScheduledOrder is an order handled by OrderScheduler, as of its last state change
+type ScheduledOrder struct {
+	ID       string // Local ID, "sched-1", ... - not a Saxo order ID
+	Request  OrderRequest
+	State    ScheduledOrderState
+	SubmitAt time.Time      // Next open the order is queued for (Queued only)
+	Response *OrderResponse // Submitted only
+	Err      error          // Failed and Rejected only
+}
+
+// OrderSchedulerEvent reports a state change of a scheduled order
+type OrderSchedulerEvent struct {
+	Order ScheduledOrder
+	Time  time.Time
+}
+
+// ScheduledOrderBroker places orders and supplies trading phases - BrokerClient satisfies it
+type ScheduledOrderBroker interface {
+	TradingScheduleProvider
+	PlaceOrder(ctx context.Context, req OrderRequest) (*OrderResponse, error)
+}
+
+// OrderSchedulerConfig configures OrderScheduler - zero values use the defaults below
+type OrderSchedulerConfig struct {
+	Policy ClosedMarketPolicy // Default QueueUntilOpen
+	// MaxWait rejects orders whose next open is further away than this (0 = queue for any open)
+	MaxWait time.Duration
+	// OpenPhaseStates are schedule phase states where orders are placed (default AutomatedTrading, Open)
+	OpenPhaseStates []string
+	Clock           Clock // nil = SystemClock
+}
+
+// OrderScheduler places orders through broker when the instrument's market is open, and queues
+// them locally for the next open (or rejects them, per Policy) when it is closed, instead of
+// passing Saxo's closed-market rejection through. Safe for concurrent use
+//
+// Times the trading schedule does not cover are treated as open - Saxo decides. Queued orders live
+// in memory only: Shutdown cancels them
+type OrderScheduler struct {
+	broker ScheduledOrderBroker
+	config OrderSchedulerConfig
+	clock  Clock
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	nextID int
+	queued map[string]*queuedOrder
+	events chan<- OrderSchedulerEvent
+
+	ctx    context.Context // Canceled by Shutdown - bounds waits and placements of queued orders
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// queuedOrder is a Queued order with its waiting goroutine's cancel
+type queuedOrder struct {
+	order  ScheduledOrder
+	cancel context.CancelFunc
+}
+
+// NewOrderScheduler creates a scheduler placing orders through broker
+func NewOrderScheduler(broker ScheduledOrderBroker, config OrderSchedulerConfig, logger *slog.Logger) *OrderScheduler {
+	if len(config.OpenPhaseStates) == 0 {
+		config.OpenPhaseStates = DefaultOpenPhaseStates
+	}
+	clock := config.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &OrderScheduler{
+		broker: broker,
+		config: config,
+		clock:  clock,
+		logger: logger,
+		queued: make(map[string]*queuedOrder),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// SetEventChannel registers the channel receiving every state change (non-blocking send)
+func (s *OrderScheduler) SetEventChannel(ch chan<- OrderSchedulerEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = ch
+}
+
+// Submit places req now when its market is open. When it is closed, the order is queued for the
+// next open (State Queued) or rejected with a *MarketClosedError, per Policy and MaxWait.
+// A failed PlaceOrder is returned as is, with the order in State Failed
+func (s *OrderScheduler) Submit(ctx context.Context, req OrderRequest) (ScheduledOrder, error) {
+	if s.ctx.Err() != nil {
+		return ScheduledOrder{}, fmt.Errorf("order scheduler is shut down")
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	order := ScheduledOrder{ID: fmt.Sprintf("sched-%d", s.nextID), Request: req}
+	s.mu.Unlock()
+
+	open, nextOpen, err := s.marketState(ctx, req.Instrument)
+	if err != nil {
+		return ScheduledOrder{}, err
+	}
+	if open {
+		return s.place(ctx, order)
+	}
+
+	closedErr := &MarketClosedError{Instrument: req.Instrument, NextOpen: nextOpen}
+	now := s.clock.Now()
+	if s.config.Policy == RejectWhenClosed || nextOpen.IsZero() ||
+		(s.config.MaxWait > 0 && nextOpen.Sub(now) > s.config.MaxWait) {
+		order.State = ScheduledOrderRejected
+		order.Err = closedErr
+		s.logger.Warn("Order rejected, market closed",
+			"function", "OrderScheduler.Submit",
+			"order_id", order.ID,
+			"ticker", req.Instrument.Ticker,
+			"next_open", nextOpen)
+		s.publish(order)
+		return order, closedErr
+	}
+
+	order = s.enqueue(order, nextOpen)
+	s.logger.Info("Order queued until market open",
+		"function", "OrderScheduler.Submit",
+		"order_id", order.ID,
+		"ticker", req.Instrument.Ticker,
+		"submit_at", nextOpen)
+	return order, nil
+}
+
+// Cancel removes a Queued order; false when id is not queued (already placed, unknown)
+func (s *OrderScheduler) Cancel(id string) bool {
+	s.mu.Lock()
+	entry, ok := s.queued[id]
+	if ok {
+		delete(s.queued, id)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	entry.cancel()
+	entry.order.State = ScheduledOrderCancelled
+	s.publish(entry.order)
+	return true
+}
+
+// Pending returns the Queued orders, earliest SubmitAt first
+func (s *OrderScheduler) Pending() []ScheduledOrder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := make([]ScheduledOrder, 0, len(s.queued))
+	for _, entry := range s.queued {
+		pending = append(pending, entry.order)
+	}
+	sortScheduledOrders(pending)
+	return pending
+}
+
+// Shutdown implements Shutdowner - cancels the queued orders and waits for placements in flight
+func (s *OrderScheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.cancel()
+	queued := s.queued
+	s.queued = make(map[string]*queuedOrder)
+	s.mu.Unlock()
+	for _, entry := range queued {
+		entry.order.State = ScheduledOrderCancelled
+		s.publish(entry.order)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("order scheduler did not stop: %w", ctx.Err())
+	}
+}
+
+// marketState reports whether instrument's market is open now, else its next open (zero if none)
+// A failed schedule lookup or a time outside the schedule counts as open - Saxo decides
+func (s *OrderScheduler) marketState(ctx context.Context, instrument Instrument) (bool, time.Time, error) {
+	if instrument.Identifier == 0 || instrument.AssetType == "" {
+		return false, time.Time{}, fmt.Errorf("instrument %s not enriched: UIC and asset type are required", instrument.Ticker)
+	}
+	schedule, err := s.broker.GetTradingSchedule(ctx, TradingScheduleParams{Uic: instrument.Identifier, AssetType: instrument.AssetType})
+	if err != nil {
+		s.logger.Warn("Trading schedule unavailable, placing order without market check",
+			"function", "OrderScheduler.marketState",
+			"ticker", instrument.Ticker,
+			"error", err)
+		return true, time.Time{}, nil
+	}
+
+	now := s.clock.Now()
+	phase, ok := schedule.PhaseAt(now)
+	if !ok || phase.isOpen(s.config.OpenPhaseStates) {
+		return true, time.Time{}, nil
+	}
+	return false, s.nextOpen(schedule, now), nil
+}
+
+// nextOpen returns the start of the first open phase after t (zero if none)
+func (s *OrderScheduler) nextOpen(schedule *TradingSchedule, t time.Time) time.Time {
+	for _, phase := range schedule.allPhases() {
+		if phase.StartTime.After(t) && phase.isOpen(s.config.OpenPhaseStates) {
+			return phase.StartTime
+		}
+	}
+	return time.Time{}
+}
+
+// place calls PlaceOrder and publishes the outcome
+func (s *OrderScheduler) place(ctx context.Context, order ScheduledOrder) (ScheduledOrder, error) {
+	order.SubmitAt = time.Time{}
+	resp, err := s.broker.PlaceOrder(ctx, order.Request)
+	if err != nil {
+		order.State = ScheduledOrderFailed
+		order.Err = err
+		s.publish(order)
+		return order, err
+	}
+	order.State = ScheduledOrderSubmitted
+	order.Response = resp
+	s.publish(order)
+	return order, nil
+}
+
+// enqueue tracks order as Queued and starts its wait for submitAt
+func (s *OrderScheduler) enqueue(order ScheduledOrder, submitAt time.Time) ScheduledOrder {
+	order.State = ScheduledOrderQueued
+	order.SubmitAt = submitAt
+	order.Err = nil
+
+	waitCtx, cancel := context.WithCancel(s.ctx)
+	s.mu.Lock()
+	if s.ctx.Err() != nil {
+		// Shut down meanwhile - the queue is gone
+		s.mu.Unlock()
+		cancel()
+		order.State = ScheduledOrderCancelled
+		s.publish(order)
+		return order
+	}
+	s.queued[order.ID] = &queuedOrder{order: order, cancel: cancel}
+	s.mu.Unlock()
+	s.publish(order)
+
+	s.wg.Add(1)
+	go s.wait(waitCtx, order)
+	return order
+}
+
+// wait sleeps until the order's SubmitAt, re-checks the market and places or re-queues the order
+func (s *OrderScheduler) wait(ctx context.Context, order ScheduledOrder) {
+	defer s.wg.Done()
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-s.clock.After(order.SubmitAt.Sub(s.clock.Now())):
+	}
+
+	// Claim the order - a concurrent Cancel or Shutdown wins
+	s.mu.Lock()
+	entry, ok := s.queued[order.ID]
+	delete(s.queued, order.ID)
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	defer entry.cancel()
+
+	// Phases can move (holidays, early closes) - check again before placing
+	open, nextOpen, err := s.marketState(ctx, order.Request.Instrument)
+	switch {
+	case err != nil:
+		order.State = ScheduledOrderFailed
+		order.Err = err
+		s.publish(order)
+	case open:
+		if _, err := s.place(ctx, order); err != nil {
+			s.logger.Error("Queued order placement failed",
+				"function", "OrderScheduler.wait",
+				"order_id", order.ID,
+				"error", err)
+		}
+	case nextOpen.IsZero():
+		order.State = ScheduledOrderRejected
+		order.Err = &MarketClosedError{Instrument: order.Request.Instrument}
+		s.publish(order)
+	default:
+		s.logger.Info("Market still closed, order re-queued",
+			"function", "OrderScheduler.wait",
+			"order_id", order.ID,
+			"submit_at", nextOpen)
+		s.enqueue(order, nextOpen)
+	}
+}
+
+func (s *OrderScheduler) publish(order ScheduledOrder) {
+	s.mu.Lock()
+	events := s.events
+	s.mu.Unlock()
+	if events == nil {
+		return
+	}
+	select {
+	case events <- OrderSchedulerEvent{Order: order, Time: s.clock.Now()}:
+	default:
+		s.logger.Warn("Order scheduler event dropped - channel full",
+			"function", "OrderScheduler.publish",
+			"order_id", order.ID,
+			"state", order.State)
+	}
+}
+
+// sortScheduledOrders orders by SubmitAt, then ID
+func sortScheduledOrders(orders []ScheduledOrder) {
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].SubmitAt.Equal(orders[j].SubmitAt) {
+			return orders[i].SubmitAt.Before(orders[j].SubmitAt)
+		}
+		return orders[i].ID < orders[j].ID
+	})
+}
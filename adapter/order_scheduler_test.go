@@ -0,0 +1,184 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+// scheduleBroker serves a fixed trading schedule and records placed orders
+type scheduleBroker struct {
+	schedule *TradingSchedule
+
+	mu     sync.Mutex
+	placed []OrderRequest
+}
+
+func (b *scheduleBroker) GetTradingSchedule(ctx context.Context, params TradingScheduleParams) (*TradingSchedule, error) {
+	return b.schedule, nil
+}
+
+func (b *scheduleBroker) PlaceOrder(ctx context.Context, req OrderRequest) (*OrderResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.placed = append(b.placed, req)
+	return &OrderResponse{OrderID: fmt.Sprintf("order-%d", len(b.placed)), Status: "Working"}, nil
+}
+
+func (b *scheduleBroker) placedCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.placed)
+}
+
+// expectSchedulerEvent waits for the next event and checks its state
+func expectSchedulerEvent(t *testing.T, events <-chan OrderSchedulerEvent, state ScheduledOrderState) OrderSchedulerEvent {
+	t.Helper()
+	select {
+	case event := <-events:
+		if event.Order.State != state {
+			t.Fatalf("Expected %s event, got %+v", state, event.Order)
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatalf("No %s event", state)
+		return OrderSchedulerEvent{}
+	}
+}
+
+func TestOrderScheduler_QueuesUntilOpen(t *testing.T) {
+	now := time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC)
+	opens := now.Add(2 * time.Hour)
+	broker := &scheduleBroker{schedule: &TradingSchedule{Phases: []SaxoTradingPhase{
+		{StartTime: now.Add(-10 * time.Hour), EndTime: opens, State: "Closed"},
+		{StartTime: opens, EndTime: opens.Add(8 * time.Hour), State: "AutomatedTrading"},
+	}}}
+	clock := mocktesting.NewFakeClock(now)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewOrderScheduler(broker, OrderSchedulerConfig{Clock: clock}, logger)
+	defer scheduler.Shutdown(context.Background())
+	events := make(chan OrderSchedulerEvent, 10)
+	scheduler.SetEventChannel(events)
+
+	req := OrderRequest{Instrument: Instrument{Ticker: "DAX", Identifier: 4912, AssetType: "CfdOnIndex"}, Side: "Buy", Size: 1, OrderType: "Market"}
+	order, err := scheduler.Submit(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if order.State != ScheduledOrderQueued || !order.SubmitAt.Equal(opens) {
+		t.Fatalf("Expected the order queued for %v, got %+v", opens, order)
+	}
+	expectSchedulerEvent(t, events, ScheduledOrderQueued)
+	if pending := scheduler.Pending(); len(pending) != 1 || pending[0].ID != order.ID {
+		t.Fatalf("Expected the order pending, got %+v", pending)
+	}
+
+	// Nothing is placed before the open
+	if err := clock.WaitForWaiters(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	if broker.placedCount() != 0 {
+		t.Fatal("Order placed while the market was closed")
+	}
+
+	clock.Advance(time.Hour)
+	event := expectSchedulerEvent(t, events, ScheduledOrderSubmitted)
+	if event.Order.ID != order.ID || event.Order.Response == nil || event.Order.Response.OrderID != "order-1" {
+		t.Fatalf("Unexpected submitted event %+v", event.Order)
+	}
+	if len(scheduler.Pending()) != 0 {
+		t.Error("Expected no pending orders after the open")
+	}
+}
+
+func TestOrderScheduler_OpenAndRejected(t *testing.T) {
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	broker := &scheduleBroker{schedule: &TradingSchedule{Phases: []SaxoTradingPhase{
+		{StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour), State: "AutomatedTrading"},
+		{StartTime: now.Add(time.Hour), EndTime: now.Add(20 * time.Hour), State: "Closed"},
+		{StartTime: now.Add(20 * time.Hour), EndTime: now.Add(30 * time.Hour), State: "AutomatedTrading"},
+	}}}
+	clock := mocktesting.NewFakeClock(now)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	req := OrderRequest{Instrument: Instrument{Ticker: "DAX", Identifier: 4912, AssetType: "CfdOnIndex"}, Side: "Buy", Size: 1, OrderType: "Market"}
+
+	// Open market - placed right away
+	scheduler := NewOrderScheduler(broker, OrderSchedulerConfig{Policy: RejectWhenClosed, Clock: clock}, logger)
+	defer scheduler.Shutdown(context.Background())
+	order, err := scheduler.Submit(context.Background(), req)
+	if err != nil || order.State != ScheduledOrderSubmitted || broker.placedCount() != 1 {
+		t.Fatalf("Expected the order placed, got %+v, %v", order, err)
+	}
+
+	// Closed market with RejectWhenClosed - typed error, nothing placed
+	clock.Advance(2 * time.Hour)
+	order, err = scheduler.Submit(context.Background(), req)
+	var closedErr *MarketClosedError
+	if !errors.As(err, &closedErr) || !errors.Is(err, ErrMarketClosed) {
+		t.Fatalf("Expected a *MarketClosedError, got %v", err)
+	}
+	if order.State != ScheduledOrderRejected || !closedErr.NextOpen.Equal(now.Add(20*time.Hour)) {
+		t.Fatalf("Unexpected rejection %+v, next open %v", order, closedErr.NextOpen)
+	}
+
+	// Queueing, but the next open is beyond MaxWait
+	waiting := NewOrderScheduler(broker, OrderSchedulerConfig{MaxWait: time.Hour, Clock: clock}, logger)
+	defer waiting.Shutdown(context.Background())
+	if _, err := waiting.Submit(context.Background(), req); !errors.Is(err, ErrMarketClosed) {
+		t.Fatalf("Expected ErrMarketClosed beyond MaxWait, got %v", err)
+	}
+	if broker.placedCount() != 1 {
+		t.Errorf("Expected only the first order placed, got %d", broker.placedCount())
+	}
+}
+
+func TestOrderScheduler_CancelAndShutdown(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	broker := &scheduleBroker{schedule: &TradingSchedule{Phases: []SaxoTradingPhase{
+		{StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour), State: "Closed"},
+		{StartTime: now.Add(time.Hour), EndTime: now.Add(9 * time.Hour), State: "AutomatedTrading"},
+	}}}
+	clock := mocktesting.NewFakeClock(now)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewOrderScheduler(broker, OrderSchedulerConfig{Clock: clock}, logger)
+	events := make(chan OrderSchedulerEvent, 10)
+	scheduler.SetEventChannel(events)
+
+	req := OrderRequest{Instrument: Instrument{Ticker: "DAX", Identifier: 4912, AssetType: "CfdOnIndex"}, Side: "Buy", Size: 1, OrderType: "Market"}
+	first, _ := scheduler.Submit(context.Background(), req)
+	second, _ := scheduler.Submit(context.Background(), req)
+	expectSchedulerEvent(t, events, ScheduledOrderQueued)
+	expectSchedulerEvent(t, events, ScheduledOrderQueued)
+
+	if !scheduler.Cancel(first.ID) {
+		t.Fatal("Cancel of a queued order failed")
+	}
+	if scheduler.Cancel(first.ID) {
+		t.Error("Second Cancel should report false")
+	}
+	expectSchedulerEvent(t, events, ScheduledOrderCancelled)
+
+	if err := scheduler.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	event := expectSchedulerEvent(t, events, ScheduledOrderCancelled)
+	if event.Order.ID != second.ID {
+		t.Errorf("Expected %s cancelled by Shutdown, got %s", second.ID, event.Order.ID)
+	}
+
+	clock.Advance(2 * time.Hour)
+	if broker.placedCount() != 0 {
+		t.Errorf("Cancelled orders must not be placed, got %d", broker.placedCount())
+	}
+	if _, err := scheduler.Submit(context.Background(), req); err == nil {
+		t.Error("Submit after Shutdown should fail")
+	}
+}
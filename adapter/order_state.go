@@ -0,0 +1,153 @@
+package saxo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OrderState is the typed lifecycle state of a Saxo order
+// Parse the raw Status strings of OrderStatus, LiveOrder and OrderUpdate with their State methods
+//
+//	LockedPlacementPending -> Working | NotWorking | WaitCondition | Parked | Filled | Cancelled | Rejected
+//	Working                -> WorkingLockedChangePending | WorkingLockedCancelPending | NotWorking | Parked |
+//	                          DoneForDay | Filled | Cancelled | Expired
+//	Working*Pending        -> Working | Filled | Cancelled | Expired (change pending may also go to cancel pending)
+//	NotWorking             -> Working (e.g. exit legs once the entry fills) | NotWorkingLocked*Pending | Cancelled | Expired
+//	NotWorking*Pending     -> NotWorking | Working | Cancelled
+//	WaitCondition, Parked, DoneForDay -> Working | Cancelled | Expired
+//	Filled, Cancelled, Expired, Rejected: terminal
+//
+// Unknown (unrecognised or missing Status) may follow and precede any state
+type OrderState string
+
+const (
+	OrderStateUnknown                 OrderState = "Unknown"
+	OrderStatePlacementPending        OrderState = "LockedPlacementPending"
+	OrderStateWorking                 OrderState = "Working"
+	OrderStateWorkingChangePending    OrderState = "WorkingLockedChangePending"
+	OrderStateWorkingCancelPending    OrderState = "WorkingLockedCancelPending"
+	OrderStateNotWorking              OrderState = "NotWorking" // Inactive, e.g. the exit legs of an unfilled entry
+	OrderStateNotWorkingChangePending OrderState = "NotWorkingLockedChangePending"
+	OrderStateNotWorkingCancelPending OrderState = "NotWorkingLockedCancelPending"
+	OrderStateWaitCondition           OrderState = "WaitCondition" // Waiting for a condition (e.g. a trigger price) to activate
+	OrderStateParked                  OrderState = "Parked"        // Kept by Saxo, not sent to the market
+	OrderStateDoneForDay              OrderState = "DoneForDay"    // No more fills this session, resumes on the next
+	OrderStateFilled                  OrderState = "Filled"
+	OrderStateCancelled               OrderState = "Cancelled"
+	OrderStateExpired                 OrderState = "Expired"
+	OrderStateRejected                OrderState = "Rejected"
+)
+
+// orderStates are the known states, OrderStateUnknown aside
+var orderStates = []OrderState{
+	OrderStatePlacementPending, OrderStateWorking, OrderStateWorkingChangePending, OrderStateWorkingCancelPending,
+	OrderStateNotWorking, OrderStateNotWorkingChangePending, OrderStateNotWorkingCancelPending,
+	OrderStateWaitCondition, OrderStateParked, OrderStateDoneForDay,
+	OrderStateFilled, OrderStateCancelled, OrderStateExpired, OrderStateRejected,
+}
+
+// orderTransitions lists the states each non-terminal state may move to, besides itself and Unknown
+var orderTransitions = map[OrderState][]OrderState{
+	OrderStatePlacementPending: {OrderStateWorking, OrderStateNotWorking, OrderStateWaitCondition, OrderStateParked,
+		OrderStateFilled, OrderStateCancelled, OrderStateRejected},
+	OrderStateWorking: {OrderStateWorkingChangePending, OrderStateWorkingCancelPending, OrderStateNotWorking,
+		OrderStateParked, OrderStateDoneForDay, OrderStateFilled, OrderStateCancelled, OrderStateExpired},
+	OrderStateWorkingChangePending: {OrderStateWorking, OrderStateWorkingCancelPending, OrderStateFilled,
+		OrderStateCancelled, OrderStateExpired},
+	OrderStateWorkingCancelPending: {OrderStateWorking, OrderStateFilled, OrderStateCancelled, OrderStateExpired},
+	OrderStateNotWorking: {OrderStateWorking, OrderStateNotWorkingChangePending, OrderStateNotWorkingCancelPending,
+		OrderStateCancelled, OrderStateExpired},
+	OrderStateNotWorkingChangePending: {OrderStateNotWorking, OrderStateWorking, OrderStateCancelled},
+	OrderStateNotWorkingCancelPending: {OrderStateNotWorking, OrderStateWorking, OrderStateCancelled},
+	OrderStateWaitCondition:           {OrderStateWorking, OrderStateCancelled, OrderStateExpired},
+	OrderStateParked:                  {OrderStateWorking, OrderStateCancelled, OrderStateExpired},
+	OrderStateDoneForDay:              {OrderStateWorking, OrderStateCancelled, OrderStateExpired},
+}
+
+// ParseOrderState maps a Saxo status string to its OrderState, case-insensitively
+// "Canceled" is accepted and "FinalFill" (order events of the last fill) is Filled; empty and
+// unrecognised strings are OrderStateUnknown
+func ParseOrderState(status string) OrderState {
+	if strings.EqualFold(status, "Canceled") {
+		return OrderStateCancelled
+	}
+	if strings.EqualFold(status, "FinalFill") {
+		return OrderStateFilled
+	}
+	for _, state := range orderStates {
+		if strings.EqualFold(status, string(state)) {
+			return state
+		}
+	}
+	return OrderStateUnknown
+}
+
+// IsTerminal reports whether the order is done: filled, cancelled, expired or rejected
+func (s OrderState) IsTerminal() bool {
+	switch s {
+	case OrderStateFilled, OrderStateCancelled, OrderStateExpired, OrderStateRejected:
+		return true
+	}
+	return false
+}
+
+// IsActive reports whether the order is in the market and can fill now, including while a change
+// or cancel is pending
+func (s OrderState) IsActive() bool {
+	switch s {
+	case OrderStateWorking, OrderStateWorkingChangePending, OrderStateWorkingCancelPending:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether next may follow s (see OrderState)
+func (s OrderState) CanTransitionTo(next OrderState) bool {
+	if s == next || s == OrderStateUnknown || next == OrderStateUnknown {
+		return true
+	}
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition returns an *OrderTransitionError when next may not follow s
+func (s OrderState) ValidateTransition(next OrderState) error {
+	if !s.CanTransitionTo(next) {
+		return &OrderTransitionError{From: s, To: next}
+	}
+	return nil
+}
+
+// OrderTransitionError reports a state change the order lifecycle does not allow,
+// e.g. a message that arrived after the order was filled
+type OrderTransitionError struct {
+	From OrderState
+	To   OrderState
+}
+
+func (e *OrderTransitionError) Error() string {
+	if e.From.IsTerminal() {
+		return fmt.Sprintf("order state %s is terminal, cannot move to %s", e.From, e.To)
+	}
+	return fmt.Sprintf("order state %s cannot move to %s", e.From, e.To)
+}
+
+// State returns the typed Status
+func (s OrderStatus) State() OrderState {
+	return ParseOrderState(s.Status)
+}
+
+// State returns the typed Status
+func (o LiveOrder) State() OrderState {
+	return ParseOrderState(o.Status)
+}
+
+// State returns the typed Status - OrderStateUnknown for deltas without Status, which leave the
+// state unchanged. A deletion (MetaDeleted) carries no Status either
+func (u OrderUpdate) State() OrderState {
+	return ParseOrderState(u.Status)
+}
@@ -0,0 +1,65 @@
+package saxo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseOrderState(t *testing.T) {
+	tests := map[string]OrderState{
+		"Working":                    OrderStateWorking,
+		"working":                    OrderStateWorking,
+		"WorkingLockedCancelPending": OrderStateWorkingCancelPending,
+		"DoneForDay":                 OrderStateDoneForDay,
+		"Canceled":                   OrderStateCancelled,
+		"FinalFill":                  OrderStateFilled,
+		"":                           OrderStateUnknown,
+		"Modified":                   OrderStateUnknown,
+	}
+	for status, want := range tests {
+		if got := ParseOrderState(status); got != want {
+			t.Errorf("ParseOrderState(%q) = %s, want %s", status, got, want)
+		}
+	}
+
+	if !(LiveOrder{Status: "Parked"}).State().CanTransitionTo(OrderStateWorking) || (OrderStatus{Status: "Filled"}).State() != OrderStateFilled {
+		t.Error("Expected State helpers to parse Status")
+	}
+	if (OrderUpdate{OrderId: "1"}).State() != OrderStateUnknown {
+		t.Error("Expected a delta without Status to be Unknown")
+	}
+}
+
+func TestOrderState_Lifecycle(t *testing.T) {
+	for _, state := range orderStates {
+		if state.IsTerminal() && state.IsActive() {
+			t.Errorf("%s is both terminal and active", state)
+		}
+		if _, ok := orderTransitions[state]; ok == state.IsTerminal() {
+			t.Errorf("Expected transitions for exactly the non-terminal states, %s has ok=%v", state, ok)
+		}
+	}
+	if !OrderStateWorkingChangePending.IsActive() || OrderStateNotWorking.IsActive() || OrderStateDoneForDay.IsTerminal() {
+		t.Error("Unexpected IsActive/IsTerminal")
+	}
+
+	// A typical bracket: the exit leg waits, activates when the entry fills, then fills itself
+	path := []OrderState{OrderStatePlacementPending, OrderStateNotWorking, OrderStateWorking, OrderStateWorkingChangePending, OrderStateWorking, OrderStateFilled}
+	for i := 1; i < len(path); i++ {
+		if err := path[i-1].ValidateTransition(path[i]); err != nil {
+			t.Errorf("Expected %s -> %s allowed: %v", path[i-1], path[i], err)
+		}
+	}
+
+	err := OrderStateFilled.ValidateTransition(OrderStateWorking)
+	var transitionErr *OrderTransitionError
+	if !errors.As(err, &transitionErr) || transitionErr.From != OrderStateFilled || transitionErr.To != OrderStateWorking {
+		t.Errorf("Expected an OrderTransitionError out of Filled, got %v", err)
+	}
+	if OrderStateParked.CanTransitionTo(OrderStateDoneForDay) {
+		t.Error("Expected Parked -> DoneForDay rejected")
+	}
+	if !OrderStateFilled.CanTransitionTo(OrderStateUnknown) || !OrderStateUnknown.CanTransitionTo(OrderStateExpired) {
+		t.Error("Expected Unknown allowed on either side")
+	}
+}
@@ -0,0 +1,216 @@
+package saxo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// OrderValidation selects how PlaceOrder treats orders that violate instrument constraints
+type OrderValidation int
+
+const (
+	OrderValidationOff    OrderValidation = iota // Send as is - Saxo answers violations with a 400
+	OrderValidationStrict                        // Fail with *ValidationError before sending
+	OrderValidationRound                         // Round Size to the lot size and prices to the tick size
+)
+
+// WithOrderValidation makes PlaceOrder check Size and prices against the instrument's LotSize,
+// MinimumLotSize, MinimumTradeSize and TickSize (REST client only)
+// Constraints come from GET /ref/v1/instruments/details, cached per UIC for CacheTTLs.InstrumentDetails
+func WithOrderValidation(mode OrderValidation) Option {
+	return func(o *ClientOptions) {
+		o.OrderValidation = mode
+	}
+}
+
+// ValidationError describes an order field that violates an instrument constraint
+// Check with errors.As; Suggested is the nearest value Saxo accepts
+type ValidationError struct {
+	Uic        int
+	Field      string // "Size", "Price", "StopLimitPrice", "RelatedOrders[1].Price"
+	Value      float64
+	Constraint string // "LotSize", "MinimumLotSize", "MinimumTradeSize" or "TickSize"
+	Limit      float64
+	Suggested  float64
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("order %s %v violates %s %v for uic %d (nearest valid: %v)",
+		e.Field, e.Value, e.Constraint, e.Limit, e.Uic, e.Suggested)
+}
+
+// CheckOrderConstraints validates req against detail
+// OrderValidationRound returns the adjusted request instead of an error; Market orders skip price checks
+// and CashAmount orders skip size checks. Size is an integer, so AmountDecimals never rejects it
+func CheckOrderConstraints(req OrderRequest, detail InstrumentDetail, mode OrderValidation) (OrderRequest, error) {
+	if mode == OrderValidationOff {
+		return req, nil
+	}
+	round := mode == OrderValidationRound
+	uic := req.Instrument.Identifier
+
+	var err error
+	if !req.IsCashAmount() { // Saxo converts a CashAmount to units itself
+		size, err := checkSize(uic, float64(req.Size), detail, round)
+		if err != nil {
+			return req, err
+		}
+		req.Size = int(size)
+	}
+
+	if req.OrderType != "Market" && req.Price > 0 {
+		if req.Price, err = checkPrice(uic, "Price", req.Price, detail, round); err != nil {
+			return req, err
+		}
+	}
+	if req.StopLimitPrice > 0 {
+		if req.StopLimitPrice, err = checkPrice(uic, "StopLimitPrice", req.StopLimitPrice, detail, round); err != nil {
+			return req, err
+		}
+	}
+	if len(req.RelatedOrders) > 0 {
+		related := make([]RelatedOrderRequest, len(req.RelatedOrders))
+		copy(related, req.RelatedOrders)
+		for i := range related {
+			if related[i].OrderType == "Market" || related[i].Price <= 0 {
+				continue
+			}
+			field := fmt.Sprintf("RelatedOrders[%d].Price", i)
+			if related[i].Price, err = checkPrice(uic, field, related[i].Price, detail, round); err != nil {
+				return req, err
+			}
+		}
+		req.RelatedOrders = related
+	}
+	return req, nil
+}
+
+// checkSize aligns size to LotSize, then to the larger of MinimumLotSize and MinimumTradeSize
+func checkSize(uic int, size float64, detail InstrumentDetail, round bool) (float64, error) {
+	valid := size
+	if detail.LotSize > 0 {
+		valid = math.Round(size/detail.LotSize) * detail.LotSize
+	}
+	constraint, minimum := "MinimumLotSize", detail.MinimumLotSize
+	if detail.MinimumTradeSize > minimum {
+		constraint, minimum = "MinimumTradeSize", detail.MinimumTradeSize
+	}
+	if valid < minimum {
+		valid = minimum
+		if detail.LotSize > 0 {
+			valid = math.Ceil(minimum/detail.LotSize) * detail.LotSize
+		}
+	}
+	valid = math.Round(valid) // Size is whole units
+	if valid <= 0 && size > 0 {
+		// Below half a lot with no minimum - the smallest tradable size, never a zero-size order
+		valid = math.Max(math.Ceil(detail.LotSize), 1)
+	}
+	if valid == size || round {
+		return valid, nil
+	}
+	if size < minimum {
+		return 0, &ValidationError{Uic: uic, Field: "Size", Value: size, Constraint: constraint, Limit: minimum, Suggested: valid}
+	}
+	return 0, &ValidationError{Uic: uic, Field: "Size", Value: size, Constraint: "LotSize", Limit: detail.LotSize, Suggested: valid}
+}
+
+// checkPrice aligns price to TickSize and the instrument's order decimals
+func checkPrice(uic int, field string, price float64, detail InstrumentDetail, round bool) (float64, error) {
+	if detail.TickSize <= 0 {
+		return price, nil
+	}
+	decimals := max(detail.OrderDecimals, GetDecimalsFromTickSize(detail.TickSize))
+	valid := SetDecimals(RoundTickSize(price, detail.TickSize), decimals, false, 0)
+
+	// Float prices are never exact - accept anything within a fraction of a tick
+	if math.Abs(valid-price) < detail.TickSize*1e-6 {
+		return valid, nil
+	}
+	if round {
+		return valid, nil
+	}
+	return 0, &ValidationError{Uic: uic, Field: field, Value: price, Constraint: "TickSize", Limit: detail.TickSize, Suggested: valid}
+}
+
+// instrumentDetailsCache keeps constraints per UIC for order validation
+type instrumentDetailsCache struct {
+	ttl     time.Duration
+	clock   Clock
+	mu      sync.Mutex
+	entries map[int]cachedInstrumentDetail
+}
+
+type cachedInstrumentDetail struct {
+	detail  InstrumentDetail
+	expires time.Time
+}
+
+// validateOrder applies the configured OrderValidation to req
+func (sbc *SaxoBrokerClient) validateOrder(ctx context.Context, req OrderRequest) (OrderRequest, error) {
+	if sbc.orderValidation == OrderValidationOff {
+		return req, nil
+	}
+	detail, err := sbc.instrumentDetail(ctx, req.Instrument.Identifier)
+	if err != nil {
+		return req, fmt.Errorf("failed to get instrument constraints: %w", err)
+	}
+	validated, err := CheckOrderConstraints(req, detail, sbc.orderValidation)
+	if err != nil {
+		return req, err
+	}
+	if validated.Size != req.Size || validated.Price != req.Price || validated.StopLimitPrice != req.StopLimitPrice {
+		sbc.logger.Info("Order rounded to instrument constraints",
+			"function", "validateOrder",
+			"ticker", req.Instrument.Ticker,
+			"size", req.Size,
+			"rounded_size", validated.Size,
+			"price", req.Price,
+			"rounded_price", validated.Price)
+	}
+	return validated, nil
+}
+
+func newInstrumentDetailsCache(ttl time.Duration, clock Clock) *instrumentDetailsCache {
+	return &instrumentDetailsCache{ttl: ttl, clock: clock, entries: make(map[int]cachedInstrumentDetail)}
+}
+
+func (c *instrumentDetailsCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[int]cachedInstrumentDetail)
+}
+
+// instrumentDetail returns the cached InstrumentDetail for uic, fetching it when missing or expired
+func (sbc *SaxoBrokerClient) instrumentDetail(ctx context.Context, uic int) (InstrumentDetail, error) {
+	if uic == 0 {
+		return InstrumentDetail{}, fmt.Errorf("instrument is not enriched - Identifier (UIC) is missing")
+	}
+	cache := sbc.instrumentDetails
+	cache.mu.Lock()
+	cached, ok := cache.entries[uic]
+	cache.mu.Unlock()
+	if ok && cache.clock.Now().Before(cached.expires) && !cacheBypassed(ctx) {
+		return cached.detail, nil
+	}
+
+	details, err := sbc.GetInstrumentDetails(ctx, []int{uic})
+	if err != nil {
+		return InstrumentDetail{}, err
+	}
+	for _, detail := range details {
+		if detail.Uic != uic {
+			continue
+		}
+		if cache.ttl > 0 {
+			cache.mu.Lock()
+			cache.entries[uic] = cachedInstrumentDetail{detail: detail, expires: cache.clock.Now().Add(cache.ttl)}
+			cache.mu.Unlock()
+		}
+		return detail, nil
+	}
+	return InstrumentDetail{}, fmt.Errorf("no instrument details for uic %d", uic)
+}
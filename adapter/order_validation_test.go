@@ -0,0 +1,107 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckOrderConstraints(t *testing.T) {
+	detail := InstrumentDetail{Uic: 21, TickSize: 0.00005, OrderDecimals: 5, LotSize: 1000, MinimumTradeSize: 5000}
+	req := dryRunOrder()
+	req.Size = 12300
+	req.Price = 1.08512
+
+	_, err := CheckOrderConstraints(req, detail, OrderValidationStrict)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "Size" || validationErr.Constraint != "LotSize" || validationErr.Suggested != 12000 {
+		t.Fatalf("Expected LotSize violation suggesting 12000, got %v", err)
+	}
+
+	req.Size = 1000
+	if _, err := CheckOrderConstraints(req, detail, OrderValidationStrict); !errors.As(err, &validationErr) || validationErr.Constraint != "MinimumTradeSize" {
+		t.Errorf("Expected MinimumTradeSize violation, got %v", err)
+	}
+
+	req.Size = 12000
+	if _, err := CheckOrderConstraints(req, detail, OrderValidationStrict); !errors.As(err, &validationErr) || validationErr.Field != "Price" || validationErr.Suggested != 1.0851 {
+		t.Errorf("Expected TickSize violation suggesting 1.0851, got %v", err)
+	}
+
+	// Round mode fixes every field and leaves the caller's related orders untouched
+	req.Size = 12300
+	req.RelatedOrders[0].Price = 1.09503
+	rounded, err := CheckOrderConstraints(req, detail, OrderValidationRound)
+	if err != nil {
+		t.Fatalf("Round failed: %v", err)
+	}
+	if rounded.Size != 12000 || rounded.Price != 1.0851 || rounded.RelatedOrders[0].Price != 1.09505 {
+		t.Errorf("Unexpected rounded order %+v", rounded)
+	}
+	if req.RelatedOrders[0].Price != 1.09503 {
+		t.Error("Expected input related orders unchanged")
+	}
+
+	// Below half a lot with no minimum rounds up to one lot, never to zero
+	tiny := req
+	tiny.Size, tiny.RelatedOrders = 300, nil
+	if rounded, err := CheckOrderConstraints(tiny, InstrumentDetail{Uic: 21, LotSize: 1000}, OrderValidationRound); err != nil || rounded.Size != 1000 {
+		t.Errorf("Expected 300 rounded up to one lot of 1000, got %v (err %v)", rounded.Size, err)
+	}
+
+	// Valid prices pass despite float representation
+	req.Price, req.Size, req.RelatedOrders = 1.0850, 12000, nil
+	if _, err := CheckOrderConstraints(req, detail, OrderValidationStrict); err != nil {
+		t.Errorf("Expected valid order to pass, got %v", err)
+	}
+}
+
+func TestSaxoBrokerClient_PlaceOrderValidation(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SetResponse("GET", "/ref/v1/instruments/details", 200, map[string]interface{}{
+		"Data": []map[string]interface{}{{"Identifier": 42, "TickSize": 0.25, "LotSize": 1, "MinimumLotSize": 1,
+			"Format": map[string]interface{}{"Decimals": 2, "OrderDecimals": 2}}},
+	})
+	mockServer.SetOrderPlacementResponse(SaxoOrderResponse{OrderId: "1"}, 201)
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	req := OrderRequest{
+		Instrument: createTestInstrument("ES", 42, "ContractFutures"),
+		Side:       "Buy",
+		Size:       1,
+		Price:      5000.1,
+		OrderType:  "Limit",
+		AccountKey: "test_account_key",
+	}
+
+	strict := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithOrderValidation(OrderValidationStrict))
+	var validationErr *ValidationError
+	if _, err := strict.PlaceOrder(context.Background(), req); !errors.As(err, &validationErr) {
+		t.Fatalf("Expected ValidationError, got %v", err)
+	}
+	mockServer.AssertRequested(t, "POST", "/trade/v2/orders", 0)
+
+	round := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithOrderValidation(OrderValidationRound))
+	for i := 0; i < 2; i++ {
+		if _, err := round.PlaceOrder(context.Background(), req); err != nil {
+			t.Fatalf("PlaceOrder failed: %v", err)
+		}
+	}
+	orders := mockServer.AssertRequested(t, "POST", "/trade/v2/orders", 2)
+	if len(orders) == 2 && !strings.Contains(orders[0].Body, `"OrderPrice":5000`) {
+		t.Errorf("Expected price rounded to 5000, got %s", orders[0].Body)
+	}
+	// One details lookup per client, then cached
+	mockServer.AssertRequested(t, "GET", "/ref/v1/instruments/details", 2)
+
+	round.InvalidateCache(CacheInstrumentDetails)
+	if _, err := round.PlaceOrder(context.Background(), req); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	mockServer.AssertRequested(t, "GET", "/ref/v1/instruments/details", 3)
+}
@@ -0,0 +1,119 @@
+package saxo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Order warning classes (OrderWarning.Class)
+const (
+	OrderWarningMarketState = "MarketState" // Accepted while the market is not open, works from the next session
+	OrderWarningPreCheck    = "PreCheck"    // Advisory ErrorInfo on an order Saxo accepted
+	OrderWarningMessage     = "Message"     // Free-text Message returned with the order
+)
+
+// OrderWarning is an advisory Saxo returned with an accepted order
+type OrderWarning struct {
+	Class   string // OrderWarningMarketState, OrderWarningPreCheck, OrderWarningMessage
+	Code    string // MarketState ("Closed", "PreMarket", ...) or Saxo ErrorCode, "" for messages
+	Message string
+	OrderID string // Related order the warning belongs to, "" = the main order
+}
+
+func (w OrderWarning) String() string {
+	parts := []string{w.Class}
+	if w.Code != "" {
+		parts = append(parts, w.Code)
+	}
+	if w.Message != "" {
+		parts = append(parts, w.Message)
+	}
+	return strings.Join(parts, ": ")
+}
+
+// OrderWarningError is returned by PlaceOrder when the precheck reports a warning class
+// configured with WithRejectOrderWarnings. The order was not placed
+type OrderWarningError struct {
+	Warnings []OrderWarning // Only the rejected ones
+}
+
+func (e *OrderWarningError) Error() string {
+	warnings := make([]string, 0, len(e.Warnings))
+	for _, w := range e.Warnings {
+		warnings = append(warnings, w.String())
+	}
+	return fmt.Sprintf("order rejected on warning: %s", strings.Join(warnings, "; "))
+}
+
+// WithRejectOrderWarnings makes PlaceOrder precheck every order and refuse it, with an *OrderWarningError,
+// when the precheck reports one of classes, e.g. OrderWarningMarketState to never queue orders for the
+// next open. Costs one extra request per order. Warnings on the placement response itself are only reported
+func WithRejectOrderWarnings(classes ...string) Option {
+	return func(o *ClientOptions) {
+		o.RejectWarnings = classes
+	}
+}
+
+// orderWarnings collects the advisories of a placement response, including those of related orders
+func orderWarnings(resp SaxoOrderResponse) []OrderWarning {
+	warnings := advisoryWarnings(resp.MarketState, resp.ErrorInfo, resp.Message)
+	for _, order := range resp.Orders {
+		for _, w := range advisoryWarnings("", order.ErrorInfo, order.Message) {
+			w.OrderID = order.OrderID
+			warnings = append(warnings, w)
+		}
+	}
+	return warnings
+}
+
+// advisoryWarnings converts the advisory fields shared by placement and precheck responses
+func advisoryWarnings(marketState string, errorInfo *SaxoErrorResponse, message string) []OrderWarning {
+	var warnings []OrderWarning
+	if marketState != "" && marketState != "Open" {
+		warnings = append(warnings, OrderWarning{
+			Class:   OrderWarningMarketState,
+			Code:    marketState,
+			Message: fmt.Sprintf("market is %s", marketState),
+		})
+	}
+	if errorInfo != nil && (errorInfo.ErrorCode != "" || errorInfo.Message != "") {
+		warnings = append(warnings, OrderWarning{
+			Class:   OrderWarningPreCheck,
+			Code:    errorInfo.ErrorCode,
+			Message: errorInfo.Message,
+		})
+	}
+	if message != "" {
+		warnings = append(warnings, OrderWarning{Class: OrderWarningMessage, Message: message})
+	}
+	return warnings
+}
+
+// checkRejectedWarnings prechecks payload when WithRejectOrderWarnings is set
+// and fails when the precheck reports a rejected class
+func (sbc *SaxoBrokerClient) checkRejectedWarnings(ctx context.Context, payload []byte, side string) error {
+	if len(sbc.rejectWarnings) == 0 {
+		return nil
+	}
+	precheck, err := sbc.precheck(ctx, payload, side)
+	if err != nil {
+		return err
+	}
+
+	var rejected []OrderWarning
+	for _, w := range precheck.Warnings {
+		if slices.Contains(sbc.rejectWarnings, w.Class) {
+			rejected = append(rejected, w)
+		}
+	}
+	if len(rejected) == 0 {
+		return nil
+	}
+	sbc.logger.Warn("Order refused on precheck warning",
+		"function", "PlaceOrder",
+		"warnings", len(rejected),
+		"first", rejected[0].String())
+	return &OrderWarningError{Warnings: rejected}
+}
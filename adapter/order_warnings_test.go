@@ -0,0 +1,86 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestSaxoBrokerClient_PlaceOrderWarnings(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+
+	mockServer.SetResponse("POST", "/trade/v2/orders", 201, map[string]interface{}{
+		"OrderId":     "1001",
+		"MarketState": "Closed",
+		"Orders": []map[string]interface{}{
+			{"OrderId": "1002", "ErrorInfo": map[string]string{"ErrorCode": "OrderConvertedToMarketOnOpen", "Message": "Converted"}},
+		},
+	})
+
+	resp, err := client.PlaceOrder(context.Background(), OrderRequest{
+		Instrument: createTestInstrument("EURUSD", 21, "FxSpot"),
+		Side:       "Buy",
+		Size:       1000,
+		Price:      1.0850,
+		OrderType:  "Limit",
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	if len(resp.Warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %+v", resp.Warnings)
+	}
+	if w := resp.Warnings[0]; w.Class != OrderWarningMarketState || w.Code != "Closed" || w.OrderID != "" {
+		t.Errorf("Unexpected market state warning %+v", w)
+	}
+	if w := resp.Warnings[1]; w.Class != OrderWarningPreCheck || w.Code != "OrderConvertedToMarketOnOpen" || w.OrderID != "1002" {
+		t.Errorf("Unexpected related order warning %+v", w)
+	}
+}
+
+func TestSaxoBrokerClient_RejectOrderWarnings(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger,
+		WithRejectOrderWarnings(OrderWarningMarketState))
+
+	mockServer.SetResponse("POST", "/trade/v2/orders/precheck", 200, map[string]interface{}{
+		"PreCheckResult": "Ok",
+		"MarketState":    "Closed",
+	})
+	mockServer.SetOrderPlacementResponse(SaxoOrderResponse{OrderId: "1001"}, 201)
+
+	req := OrderRequest{
+		Instrument: createTestInstrument("EURUSD", 21, "FxSpot"),
+		Side:       "Buy",
+		Size:       1000,
+		Price:      1.0850,
+		OrderType:  "Limit",
+	}
+	_, err := client.PlaceOrder(context.Background(), req)
+	var warningErr *OrderWarningError
+	if !errors.As(err, &warningErr) || len(warningErr.Warnings) != 1 {
+		t.Fatalf("Expected OrderWarningError, got %v", err)
+	}
+	mockServer.AssertRequested(t, "POST", "/trade/v2/orders", 0)
+
+	// Warning classes not configured for rejection still let the order through
+	mockServer.SetResponse("POST", "/trade/v2/orders/precheck", 200, map[string]interface{}{
+		"PreCheckResult": "Ok",
+		"ErrorInfo":      map[string]string{"ErrorCode": "PriceFarFromMarket", "Message": "Price is far from market"},
+	})
+	if _, err := client.PlaceOrder(context.Background(), req); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	mockServer.AssertRequested(t, "POST", "/trade/v2/orders", 1)
+}
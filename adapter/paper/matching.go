@@ -0,0 +1,503 @@
+package paper
+
+import (
+	"fmt"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// paperOrder is a simulated order; related orders share the master's UIC and size
+type paperOrder struct {
+	id             string
+	seq            int
+	uic            int
+	assetType      string
+	ticker         string
+	accountKey     string
+	side           string // "Buy" or "Sell"
+	orderType      string // "Market", "Limit", "StopIfTraded", "Stop", "StopLimit"
+	duration       string
+	expiresAt      time.Time // GoodTillDate expiry, zero = never (see expireOrdersLocked)
+	size           int
+	price          float64
+	stopLimitPrice float64
+	status         string // "Working", "NotWorking" (inactive related order), "Filled", "Cancelled", "Expired"
+	relation       string // "StandAlone", "IfDoneMaster", "IfDoneSlave"
+	parentID       string
+	relatedIDs     []string
+	ocoID          string // Sibling cancelled when this order fills
+	placedAt       time.Time
+	fillPrice      float64
+}
+
+// isOpen reports whether the order can still fill, be modified or cancelled
+func (o *paperOrder) isOpen() bool {
+	return o.status == "Working" || o.status == "NotWorking"
+}
+
+// paperPosition is an open simulated position; amount is signed (negative = short)
+type paperPosition struct {
+	id            string
+	seq           int
+	uic           int
+	assetType     string
+	ticker        string
+	accountKey    string
+	amount        float64
+	openPrice     float64
+	openedAt      time.Time
+	sourceOrderID string
+}
+
+// isSupportedOrderType lists the order types the matcher understands
+func isSupportedOrderType(orderType string) bool {
+	switch orderType {
+	case "Market", "Limit", "StopIfTraded", "Stop", "StopLimit":
+		return true
+	default:
+		return false
+	}
+}
+
+// durationType is the duration type of d, DayOrder when unset (as Saxo defaults it)
+func durationType(d saxo.OrderDuration) string {
+	if d.Type == "" {
+		return saxo.DurationDayOrder
+	}
+	return d.Type
+}
+
+// expiryOf is the moment a GoodTillDate order stops working, zero for other durations
+// A date-only expiry lasts through the end of that calendar date in Expiry's location
+func expiryOf(d saxo.OrderDuration) time.Time {
+	if d.Type != saxo.DurationGoodTillDate || d.Expiry.IsZero() {
+		return time.Time{}
+	}
+	if d.ExpiryHasTime {
+		return d.Expiry
+	}
+	year, month, day := d.Expiry.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, d.Expiry.Location())
+}
+
+// newOrderLocked creates and stores a working order
+func (pb *PaperBrokerClient) newOrderLocked(uic int, assetType, ticker, accountKey, side, orderType string, duration saxo.OrderDuration, size int, price, stopLimitPrice float64) *paperOrder {
+	pb.orderSeq++
+	order := &paperOrder{
+		id:             fmt.Sprintf("paper-%d", pb.orderSeq),
+		seq:            pb.orderSeq,
+		uic:            uic,
+		assetType:      assetType,
+		ticker:         ticker,
+		accountKey:     accountKey,
+		side:           side,
+		orderType:      orderType,
+		duration:       durationType(duration),
+		expiresAt:      expiryOf(duration),
+		size:           size,
+		price:          price,
+		stopLimitPrice: stopLimitPrice,
+		status:         "Working",
+		relation:       "StandAlone",
+		placedAt:       pb.config.Clock.Now(),
+	}
+	pb.orders[order.id] = order
+	return order
+}
+
+// expireOrdersLocked expires open GoodTillDate orders whose expiry has passed on pb.config.Clock
+// The inactive related orders of an expired entry can never activate and are cancelled with it
+func (pb *PaperBrokerClient) expireOrdersLocked() {
+	now := pb.config.Clock.Now()
+	for _, order := range pb.sortedOrdersLocked() {
+		if !order.isOpen() || order.expiresAt.IsZero() || now.Before(order.expiresAt) {
+			continue
+		}
+		order.status = "Expired"
+		pb.publishOrderLocked(order)
+		for _, childID := range order.relatedIDs {
+			if child := pb.orders[childID]; child.status == "NotWorking" {
+				pb.cancelLocked(child)
+			}
+		}
+
+		pb.logger.Info("Paper order expired",
+			"function", "expireOrdersLocked",
+			"order_id", order.id,
+			"expiry", order.expiresAt)
+	}
+}
+
+// matchOrdersLocked fills every working order for the update's UIC whose condition is met
+// Repeats until stable - an entry fill activates related orders that may fill on the same quote.
+// Expired orders are removed first so they never fill
+func (pb *PaperBrokerClient) matchOrdersLocked(update saxo.PriceUpdate) {
+	pb.expireOrdersLocked()
+	for {
+		filled := false
+		for _, order := range pb.sortedOrdersLocked() {
+			if order.status != "Working" || order.uic != update.Uic {
+				continue
+			}
+			if pb.triggeredLocked(order, update) {
+				pb.fillLocked(order, update)
+				filled = true
+			}
+		}
+		if !filled {
+			return
+		}
+	}
+}
+
+// triggeredLocked evaluates an order against the touch (buy at ask, sell at bid)
+// A triggered StopLimit turns into a Limit at its StopLimitPrice and is evaluated again
+func (pb *PaperBrokerClient) triggeredLocked(order *paperOrder, update saxo.PriceUpdate) bool {
+	touch := update.Bid
+	if order.side == "Buy" {
+		touch = update.Ask
+	}
+	if touch == 0 {
+		return false
+	}
+
+	switch order.orderType {
+	case "Market":
+		return true
+	case "Limit":
+		if order.side == "Buy" {
+			return touch <= order.price
+		}
+		return touch >= order.price
+	case "StopIfTraded", "Stop":
+		if order.side == "Buy" {
+			return touch >= order.price
+		}
+		return touch <= order.price
+	case "StopLimit":
+		stopped := (order.side == "Buy" && touch >= order.price) || (order.side == "Sell" && touch <= order.price)
+		if !stopped {
+			return false
+		}
+		order.orderType = "Limit"
+		order.price = order.stopLimitPrice
+		pb.publishOrderLocked(order)
+		return pb.triggeredLocked(order, update)
+	default:
+		return false
+	}
+}
+
+// fillLocked executes an order at the touch, updates positions and activates/cancels related orders
+func (pb *PaperBrokerClient) fillLocked(order *paperOrder, update saxo.PriceUpdate) {
+	fillPrice := update.Bid
+	signed := -float64(order.size)
+	if order.side == "Buy" {
+		fillPrice = update.Ask
+		signed = float64(order.size)
+	}
+
+	order.status = "Filled"
+	order.fillPrice = fillPrice
+
+	pb.applyFillLocked(order, signed, fillPrice)
+
+	pb.logger.Info("Paper order filled",
+		"function", "fillLocked",
+		"order_id", order.id,
+		"uic", order.uic,
+		"side", order.side,
+		"size", order.size,
+		"fill_price", fillPrice)
+
+	pb.publishOrderLocked(order)
+
+	// IfDone entry filled - related exit orders go live as an OCO pair
+	for _, childID := range order.relatedIDs {
+		child := pb.orders[childID]
+		if child.status == "NotWorking" {
+			child.status = "Working"
+			pb.publishOrderLocked(child)
+		}
+	}
+
+	// One side of the OCO filled - the other is cancelled
+	if order.ocoID != "" {
+		if sibling := pb.orders[order.ocoID]; sibling.isOpen() {
+			pb.cancelLocked(sibling)
+		}
+	}
+
+	pb.publishPortfolioLocked()
+}
+
+// applyFillLocked nets the fill against opposite positions (FIFO) and opens a position with the rest
+func (pb *PaperBrokerClient) applyFillLocked(order *paperOrder, signed, fillPrice float64) {
+	now := pb.config.Clock.Now()
+	remaining := signed
+
+	for _, position := range pb.positionsForUicLocked(order.uic) {
+		if remaining == 0 || (position.amount > 0) == (remaining > 0) {
+			continue
+		}
+
+		closedAmount := minFloat(absFloat(position.amount), absFloat(remaining))
+		direction := 1.0
+		buyOrSell := "Buy"
+		if position.amount < 0 {
+			direction = -1
+			buyOrSell = "Sell"
+		}
+		profitLoss := (fillPrice - position.openPrice) * closedAmount * direction
+		pb.cashBalance += profitLoss
+
+		var closed saxo.SaxoClosedPosition
+		closed.ClosedPositionUniqueID = fmt.Sprintf("%s-%s", position.id, order.id)
+		closed.NetPositionID = netPositionID(position.uic, position.assetType)
+		closed.DisplayAndFormat.Symbol = position.ticker
+		closed.ClosedPosition.AccountID = position.accountKey
+		closed.ClosedPosition.Amount = closedAmount
+		closed.ClosedPosition.AssetType = position.assetType
+		closed.ClosedPosition.BuyOrSell = buyOrSell
+		closed.ClosedPosition.ClosedProfitLoss = profitLoss
+		closed.ClosedPosition.ClosedProfitLossInBaseCurrency = profitLoss
+		closed.ClosedPosition.ClosingMarketValue = closedAmount * fillPrice
+		closed.ClosedPosition.ClosingPositionID = order.id
+		closed.ClosedPosition.ClosingPrice = fillPrice
+		closed.ClosedPosition.ExecutionTimeClose = now
+		closed.ClosedPosition.ExecutionTimeOpen = position.openedAt
+		closed.ClosedPosition.OpeningPositionID = position.id
+		closed.ClosedPosition.OpenPrice = position.openPrice
+		closed.ClosedPosition.Uic = position.uic
+		pb.closed = append(pb.closed, closed)
+
+		position.amount -= closedAmount * direction
+		remaining += closedAmount * direction
+		if position.amount == 0 {
+			delete(pb.positions, position.id)
+		}
+	}
+
+	if remaining != 0 {
+		pb.positionSeq++
+		position := &paperPosition{
+			id:            fmt.Sprintf("paper-pos-%d", pb.positionSeq),
+			seq:           pb.positionSeq,
+			uic:           order.uic,
+			assetType:     order.assetType,
+			ticker:        order.ticker,
+			accountKey:    order.accountKey,
+			amount:        remaining,
+			openPrice:     fillPrice,
+			openedAt:      now,
+			sourceOrderID: order.id,
+		}
+		pb.positions[position.id] = position
+	}
+}
+
+// cancelLocked marks an order cancelled and publishes the deletion
+func (pb *PaperBrokerClient) cancelLocked(order *paperOrder) {
+	order.status = "Cancelled"
+	pb.publishOrderLocked(order)
+}
+
+// positionsForUicLocked returns open positions for a UIC in opening order
+func (pb *PaperBrokerClient) positionsForUicLocked(uic int) []*paperPosition {
+	var positions []*paperPosition
+	for _, position := range pb.sortedPositionsLocked() {
+		if position.uic == uic {
+			positions = append(positions, position)
+		}
+	}
+	return positions
+}
+
+// checkMarginLocked rejects orders that add exposure beyond the available margin
+// Only the part of the order that opens new exposure is charged: reducing an opposite position is
+// free, and the excess of a reversal is charged like a new position. Margin is valued at the
+// current mid, or at orderPrice while no quote has been seen
+func (pb *PaperBrokerClient) checkMarginLocked(uic int, side string, size int, price saxo.PriceUpdate, orderPrice float64) error {
+	net := 0.0
+	for _, position := range pb.positionsForUicLocked(uic) {
+		net += position.amount
+	}
+	opening := float64(size)
+	if (side == "Buy" && net < 0) || (side == "Sell" && net > 0) {
+		opening -= absFloat(net)
+	}
+	if opening <= 0 {
+		return nil
+	}
+
+	mid := price.Mid
+	if mid <= 0 {
+		mid = orderPrice
+	}
+	if mid <= 0 {
+		return fmt.Errorf("no price for UIC %d yet - cannot check margin", uic)
+	}
+	required := opening * mid * pb.config.MarginRate
+	balance := pb.balanceLocked()
+	if required > balance.MarginAvailableForTrading {
+		return fmt.Errorf("insufficient margin: order requires %.2f, available %.2f",
+			required, balance.MarginAvailableForTrading)
+	}
+	return nil
+}
+
+// positionMarginLocked returns the initial margin held by a position at the current mid
+func (pb *PaperBrokerClient) positionMarginLocked(position *paperPosition) float64 {
+	mid := position.openPrice
+	if price, ok := pb.prices[position.uic]; ok && price.Mid != 0 {
+		mid = price.Mid
+	}
+	return absFloat(position.amount) * mid * pb.config.MarginRate
+}
+
+// balanceLocked marks open positions to market and derives margin figures
+func (pb *PaperBrokerClient) balanceLocked() *saxo.Balance {
+	unrealized := 0.0
+	marginUsed := 0.0
+	for _, position := range pb.positions {
+		if price, ok := pb.prices[position.uic]; ok {
+			unrealized += (closingPrice(position.amount, price) - position.openPrice) * position.amount
+		}
+		marginUsed += pb.positionMarginLocked(position)
+	}
+
+	openOrders := 0
+	for _, order := range pb.orders {
+		if order.isOpen() {
+			openOrders++
+		}
+	}
+
+	equity := pb.cashBalance + unrealized
+	balance := &saxo.Balance{
+		CalculationReliability:       "Ok",
+		CashAvailableForTrading:      equity - marginUsed,
+		CashBalance:                  pb.cashBalance,
+		ClosedPositionsCount:         len(pb.closed),
+		Currency:                     pb.config.Currency,
+		CurrencyDecimals:             2,
+		MarginAvailableForTrading:    equity - marginUsed,
+		MarginUsedByCurrentPositions: marginUsed,
+		NetEquityForMargin:           equity,
+		OpenPositionsCount:           len(pb.positions),
+		OrdersCount:                  openOrders,
+		TotalValue:                   equity,
+		UnrealizedMarginProfitLoss:   unrealized,
+		UnrealizedPositionsValue:     unrealized,
+	}
+	if equity != 0 {
+		balance.MarginUtilizationPct = marginUsed / equity * 100
+	}
+	balance.InitialMargin.MarginAvailable = balance.MarginAvailableForTrading
+	balance.InitialMargin.MarginUsedByCurrentPositions = marginUsed
+	balance.InitialMargin.NetEquityForMargin = equity
+	balance.InitialMargin.MarginUtilizationPct = balance.MarginUtilizationPct
+	return balance
+}
+
+// publishOrderLocked emits an OrderUpdate shaped like the WebSocket order stream
+// Filled and cancelled orders carry __meta_deleted like Saxo's deletion messages
+func (pb *PaperBrokerClient) publishOrderLocked(order *paperOrder) {
+	if pb.channelsClosed {
+		return
+	}
+
+	uic := order.uic
+	amount := order.size
+	update := saxo.OrderUpdate{
+		OrderId:       order.id,
+		Status:        order.status,
+		UpdatedAt:     pb.config.Clock.Now(),
+		OpenOrderType: order.orderType,
+		OrderPrice:    order.price,
+		Uic:           &uic,
+		Amount:        &amount,
+		BuySell:       order.side,
+		OrderRelation: order.relation,
+	}
+	if order.status == "Filled" {
+		update.FilledSize = float64(order.size)
+		update.OrderPrice = order.fillPrice
+	}
+	if !order.isOpen() {
+		deleted := true
+		update.MetaDeleted = &deleted
+	}
+	for _, childID := range order.relatedIDs {
+		child := pb.orders[childID]
+		update.RelatedOpenOrders = append(update.RelatedOpenOrders, saxo.RelatedOrder{
+			OrderID:       child.id,
+			OpenOrderType: child.orderType,
+			OrderPrice:    child.price,
+			Amount:        float64(child.size),
+			Status:        child.status,
+		})
+	}
+
+	select {
+	case pb.orderUpdateChan <- update:
+	default:
+		pb.logger.Warn("Order update channel full, dropping update",
+			"function", "publishOrderLocked",
+			"order_id", order.id)
+	}
+}
+
+// publishPortfolioLocked emits the current balance and margin
+func (pb *PaperBrokerClient) publishPortfolioLocked() {
+	if pb.channelsClosed {
+		return
+	}
+
+	balance := pb.balanceLocked()
+	update := saxo.PortfolioUpdate{
+		Balance:              balance.TotalValue,
+		MarginUsed:           balance.MarginUsedByCurrentPositions,
+		MarginFree:           balance.MarginAvailableForTrading,
+		CashBalance:          balance.CashBalance,
+		UnrealizedProfitLoss: balance.UnrealizedMarginProfitLoss,
+		MarginUtilizationPct: balance.MarginUtilizationPct,
+		Currency:             balance.Currency,
+		UpdatedAt:            pb.config.Clock.Now(),
+	}
+
+	select {
+	case pb.portfolioUpdateChan <- update:
+	default:
+		pb.logger.Warn("Portfolio update channel full, dropping update",
+			"function", "publishPortfolioLocked")
+	}
+}
+
+// netPositionID follows Saxo's "{Uic}__{AssetType}" net position key
+func netPositionID(uic int, assetType string) string {
+	return fmt.Sprintf("%d__%s", uic, assetType)
+}
+
+// closingPrice is the price a position would close at: bid for longs, ask for shorts
+func closingPrice(amount float64, price saxo.PriceUpdate) float64 {
+	if amount < 0 {
+		return price.Ask
+	}
+	return price.Bid
+}
+
+func absFloat(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
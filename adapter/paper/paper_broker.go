@@ -0,0 +1,939 @@
+// Package paper provides a simulated BrokerClient that fills orders against streamed prices
+// Lets strategies run against real SIM/LIVE market data without placing any broker orders
+package paper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// errNoReference is returned by reference-data calls when Config.Reference is not set
+var errNoReference = errors.New("paper broker has no reference BrokerClient for this call")
+
+// errShutdown is returned by order calls after Shutdown
+var errShutdown = errors.New("paper broker is shut down")
+
+// Config configures the simulated account
+// P&L is booked in the instrument's quote currency - no FX conversion to the account currency
+type Config struct {
+	InitialBalance float64    // Starting cash, default 100000
+	Currency       string     // Account currency, default "USD"
+	AccountKey     string     // Default "paper-account"
+	ClientKey      string     // Default "paper-client"
+	MarginRate     float64    // Initial margin as a fraction of notional, default 0.05 (20:1)
+	Clock          saxo.Clock // Order, fill and expiry times, nil = saxo.SystemClock
+
+	// Reference serves instrument search/details, schedules and historical data (optional)
+	// Typically the real SaxoBrokerClient - it is only ever used for reads
+	Reference saxo.BrokerClient
+}
+
+// PaperBrokerClient implements saxo.BrokerClient with simulated execution
+// Orders are matched on every price update: Market fills at the touch, Limit when the touch
+// crosses the limit, StopIfTraded when the touch crosses the stop. IfDone related orders are
+// activated as an OCO pair once the entry fills
+type PaperBrokerClient struct {
+	config Config
+	logger *slog.Logger
+
+	mu             sync.Mutex
+	prices         map[int]saxo.PriceUpdate
+	orders         map[string]*paperOrder    // All orders by ID, including filled/cancelled
+	positions      map[string]*paperPosition // Open positions by ID
+	closed         []saxo.SaxoClosedPosition
+	cashBalance    float64
+	orderSeq       int
+	positionSeq    int
+	shutdown       bool
+	channelsClosed bool // Update channels closed - set by Shutdown after producers stop
+
+	orderUpdateChan     chan saxo.OrderUpdate
+	portfolioUpdateChan chan saxo.PortfolioUpdate
+
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	startMu   sync.Mutex
+	closeOnce sync.Once
+}
+
+// Compile-time check that the paper client is a drop-in BrokerClient
+var _ saxo.BrokerClient = (*PaperBrokerClient)(nil)
+
+// NewPaperBrokerClient creates a simulated broker
+// Feed prices with Start (a channel, e.g. a websocket PriceSubscription) or UpdatePrice
+func NewPaperBrokerClient(config Config, logger *slog.Logger) *PaperBrokerClient {
+	if config.InitialBalance == 0 {
+		config.InitialBalance = 100000
+	}
+	if config.Currency == "" {
+		config.Currency = "USD"
+	}
+	if config.AccountKey == "" {
+		config.AccountKey = "paper-account"
+	}
+	if config.ClientKey == "" {
+		config.ClientKey = "paper-client"
+	}
+	if config.MarginRate <= 0 {
+		config.MarginRate = 0.05
+	}
+	if config.Clock == nil {
+		config.Clock = saxo.SystemClock
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &PaperBrokerClient{
+		config:              config,
+		logger:              logger,
+		prices:              make(map[int]saxo.PriceUpdate),
+		orders:              make(map[string]*paperOrder),
+		positions:           make(map[string]*paperPosition),
+		cashBalance:         config.InitialBalance,
+		orderUpdateChan:     make(chan saxo.OrderUpdate, 1000),
+		portfolioUpdateChan: make(chan saxo.PortfolioUpdate, 100),
+		stop:                make(chan struct{}),
+	}
+}
+
+// Start consumes prices until ctx is done, the channel closes or Shutdown is called
+// Can be called once per price source (e.g. one per asset type subscription)
+func (pb *PaperBrokerClient) Start(ctx context.Context, prices <-chan saxo.PriceUpdate) {
+	pb.startMu.Lock()
+	defer pb.startMu.Unlock()
+
+	pb.wg.Add(1)
+	go func() {
+		defer pb.wg.Done()
+		for {
+			select {
+			case <-pb.stop:
+				return
+			case <-ctx.Done():
+				return
+			case update, ok := <-prices:
+				if !ok {
+					return
+				}
+				pb.UpdatePrice(update)
+			}
+		}
+	}()
+
+	pb.logger.Info("Paper broker consuming prices",
+		"function", "Start")
+}
+
+// UpdatePrice records a quote and matches working orders for its UIC
+func (pb *PaperBrokerClient) UpdatePrice(update saxo.PriceUpdate) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	if pb.shutdown {
+		return
+	}
+	pb.prices[update.Uic] = update
+	pb.matchOrdersLocked(update)
+}
+
+// GetOrderUpdateChannel returns simulated order events (same shape as the WebSocket stream)
+func (pb *PaperBrokerClient) GetOrderUpdateChannel() <-chan saxo.OrderUpdate {
+	return pb.orderUpdateChan
+}
+
+// GetPortfolioUpdateChannel returns balance/margin snapshots published after every fill
+func (pb *PaperBrokerClient) GetPortfolioUpdateChannel() <-chan saxo.PortfolioUpdate {
+	return pb.portfolioUpdateChan
+}
+
+// Shutdown implements saxo.Shutdowner - stops price consumers and closes the update channels
+func (pb *PaperBrokerClient) Shutdown(ctx context.Context) error {
+	pb.mu.Lock()
+	pb.shutdown = true
+	pb.mu.Unlock()
+
+	pb.closeOnce.Do(func() {
+		close(pb.stop)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		pb.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("price consumers still running at shutdown deadline: %w", ctx.Err())
+	}
+
+	// Producers are UpdatePrice and order calls, all gated on pb.shutdown under pb.mu
+	pb.mu.Lock()
+	if !pb.channelsClosed {
+		close(pb.orderUpdateChan)
+		close(pb.portfolioUpdateChan)
+		pb.channelsClosed = true
+	}
+	pb.mu.Unlock()
+
+	pb.logger.Info("Paper broker shut down",
+		"function", "Shutdown")
+	return nil
+}
+
+// PlaceOrder implements BrokerClient.PlaceOrder
+// Market orders fill immediately at the current touch and fail if no price has been seen yet
+func (pb *PaperBrokerClient) PlaceOrder(ctx context.Context, req saxo.OrderRequest) (*saxo.OrderResponse, error) {
+	// Same contract as the HTTP client: a cancelled context places nothing
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	uic := req.Instrument.Uic
+	if uic == 0 {
+		uic = req.Instrument.Identifier
+	}
+	if uic == 0 {
+		return nil, fmt.Errorf("instrument %s has no UIC", req.Instrument.Ticker)
+	}
+	if req.Side != "Buy" && req.Side != "Sell" {
+		return nil, fmt.Errorf("invalid side %q", req.Side)
+	}
+	// Same asset type rules as the REST client, e.g. CashAmount only for stocks, ETFs and funds
+	if _, _, err := req.ResolveAmount(); err != nil {
+		return nil, err
+	}
+	if !req.IsCashAmount() && req.Size <= 0 {
+		return nil, fmt.Errorf("invalid size %d", req.Size)
+	}
+	if !isSupportedOrderType(req.OrderType) {
+		return nil, fmt.Errorf("unsupported order type %q", req.OrderType)
+	}
+	if err := req.Duration.Validate(pb.config.Clock.Now()); err != nil {
+		return nil, fmt.Errorf("invalid order duration: %w", err)
+	}
+	for _, related := range req.RelatedOrders {
+		if !isSupportedOrderType(related.OrderType) || related.OrderType == "Market" {
+			return nil, fmt.Errorf("unsupported related order type %q", related.OrderType)
+		}
+		if err := related.Duration.Validate(pb.config.Clock.Now()); err != nil {
+			return nil, fmt.Errorf("invalid related order duration: %w", err)
+		}
+	}
+
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	if pb.shutdown {
+		return nil, errShutdown
+	}
+
+	price, hasPrice := pb.prices[uic]
+	if req.OrderType == "Market" && !hasPrice {
+		return nil, fmt.Errorf("no price for UIC %d yet - cannot fill market order", uic)
+	}
+	if req.IsCashAmount() {
+		// Whole units the cash amount pays for at the order price - paper positions are not fractional
+		orderPrice := req.Price
+		if req.OrderType == "Market" || orderPrice <= 0 {
+			orderPrice = price.Mid
+		}
+		if orderPrice <= 0 {
+			return nil, fmt.Errorf("no price for UIC %d yet - cannot size cash amount order", uic)
+		}
+		if req.Size = int(req.CashAmount / orderPrice); req.Size <= 0 {
+			return nil, fmt.Errorf("cash amount %v buys less than one unit at %v", req.CashAmount, orderPrice)
+		}
+	}
+	if err := pb.checkMarginLocked(uic, req.Side, req.Size, price, req.Price); err != nil {
+		return nil, err
+	}
+
+	accountKey := req.AccountKey
+	if accountKey == "" {
+		accountKey = pb.config.AccountKey
+	}
+
+	master := pb.newOrderLocked(uic, req.Instrument.AssetType, req.Instrument.Ticker, accountKey,
+		req.Side, req.OrderType, req.Duration, req.Size, req.Price, req.StopLimitPrice)
+
+	response := &saxo.OrderResponse{
+		OrderID:   master.id,
+		Timestamp: master.placedAt.Format(time.RFC3339),
+	}
+
+	if len(req.RelatedOrders) > 0 {
+		master.relation = "IfDoneMaster"
+		for _, related := range req.RelatedOrders {
+			child := pb.newOrderLocked(uic, req.Instrument.AssetType, req.Instrument.Ticker, accountKey,
+				related.Side, related.OrderType, related.Duration, req.Size, related.Price, 0)
+			child.relation = "IfDoneSlave"
+			child.parentID = master.id
+			child.status = "NotWorking" // Inactive until the entry fills
+			master.relatedIDs = append(master.relatedIDs, child.id)
+			response.RelatedOrderIDs = append(response.RelatedOrderIDs, child.id)
+		}
+		if len(master.relatedIDs) == 2 {
+			pb.orders[master.relatedIDs[0]].ocoID = master.relatedIDs[1]
+			pb.orders[master.relatedIDs[1]].ocoID = master.relatedIDs[0]
+		}
+	}
+
+	pb.logger.Info("Paper order placed",
+		"function", "PlaceOrder",
+		"order_id", master.id,
+		"uic", uic,
+		"side", req.Side,
+		"order_type", req.OrderType,
+		"size", req.Size,
+		"price", req.Price,
+		"related_orders", len(master.relatedIDs))
+
+	pb.publishOrderLocked(master)
+
+	if hasPrice {
+		pb.matchOrdersLocked(price)
+	}
+
+	response.Status = master.status
+	return response, nil
+}
+
+// paperModification is one validated order change, applied only once all changes validate
+type paperModification struct {
+	order     *paperOrder
+	price     float64
+	hasPrice  bool
+	orderType string
+	size      int
+	duration  saxo.OrderDuration
+}
+
+// ModifyOrder implements BrokerClient.ModifyOrder (price, type, amount, duration and related orders)
+// Related orders must belong to the modified order; nothing changes unless every change is valid
+func (pb *PaperBrokerClient) ModifyOrder(ctx context.Context, req saxo.OrderModificationRequest) (*saxo.OrderResponse, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	if pb.shutdown {
+		return nil, errShutdown
+	}
+
+	pb.expireOrdersLocked()
+	order, ok := pb.orders[req.OrderID]
+	if !ok {
+		return nil, fmt.Errorf("order %s not found", req.OrderID)
+	}
+
+	mod, err := pb.validateModificationLocked(order, req.OrderPrice, req.OrderType, req.Amount, req.OrderDuration)
+	if err != nil {
+		return nil, err
+	}
+	mods := []paperModification{mod}
+
+	for _, related := range req.RelatedOrders {
+		child, ok := pb.orders[related.OrderID]
+		if !ok {
+			return nil, fmt.Errorf("related order %s not found", related.OrderID)
+		}
+		if !slices.Contains(order.relatedIDs, child.id) && order.ocoID != child.id {
+			return nil, fmt.Errorf("order %s is not related to order %s", related.OrderID, req.OrderID)
+		}
+		mod, err := pb.validateModificationLocked(child, related.OrderPrice, related.OrderType, related.Amount, related.OrderDuration)
+		if err != nil {
+			return nil, fmt.Errorf("related order %s: %w", related.OrderID, err)
+		}
+		mods = append(mods, mod)
+	}
+
+	for _, mod := range mods {
+		if mod.hasPrice {
+			mod.order.price = mod.price
+		}
+		if mod.orderType != "" {
+			mod.order.orderType = mod.orderType
+		}
+		if mod.size > 0 {
+			mod.order.size = mod.size
+		}
+		if mod.duration.Type != "" {
+			mod.order.duration = mod.duration.Type
+			mod.order.expiresAt = expiryOf(mod.duration)
+		}
+
+		pb.logger.Info("Paper order modified",
+			"function", "ModifyOrder",
+			"order_id", mod.order.id,
+			"price", mod.order.price,
+			"size", mod.order.size,
+			"order_type", mod.order.orderType)
+
+		pb.publishOrderLocked(mod.order)
+	}
+	if price, ok := pb.prices[order.uic]; ok {
+		pb.matchOrdersLocked(price)
+	}
+
+	return &saxo.OrderResponse{
+		OrderID:   order.id,
+		Status:    order.status,
+		Timestamp: pb.config.Clock.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// validateModificationLocked checks one order change without applying it
+func (pb *PaperBrokerClient) validateModificationLocked(order *paperOrder, price, orderType string, amount float64, duration saxo.OrderDuration) (paperModification, error) {
+	mod := paperModification{order: order, orderType: orderType, duration: duration}
+
+	if !order.isOpen() {
+		return mod, fmt.Errorf("order %s is %s and cannot be modified", order.id, order.status)
+	}
+	if price != "" {
+		parsed, err := strconv.ParseFloat(price, 64)
+		if err != nil {
+			return mod, fmt.Errorf("invalid order price %q: %w", price, err)
+		}
+		mod.price, mod.hasPrice = parsed, true
+	}
+	if orderType != "" && !isSupportedOrderType(orderType) {
+		return mod, fmt.Errorf("unsupported order type %q", orderType)
+	}
+	if amount < 0 {
+		return mod, fmt.Errorf("amount must not be negative, got %v", amount)
+	}
+	mod.size = int(amount)
+	if err := duration.Validate(pb.config.Clock.Now()); err != nil {
+		return mod, err
+	}
+	return mod, nil
+}
+
+// GetOrderStatus implements BrokerClient.GetOrderStatus (works for filled and cancelled orders too)
+func (pb *PaperBrokerClient) GetOrderStatus(ctx context.Context, orderID string) (*saxo.OrderStatus, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	pb.expireOrdersLocked()
+	order, ok := pb.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("order %s not found", orderID)
+	}
+	price := order.price
+	if order.status == "Filled" {
+		price = order.fillPrice
+	}
+	return &saxo.OrderStatus{
+		OrderID: order.id,
+		Status:  order.status,
+		Price:   price,
+		Size:    order.size,
+	}, nil
+}
+
+// CancelOrder implements BrokerClient.CancelOrder
+// Cancelling an IfDone entry also cancels its inactive related orders
+func (pb *PaperBrokerClient) CancelOrder(ctx context.Context, req saxo.CancelOrderRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	if pb.shutdown {
+		return errShutdown
+	}
+
+	pb.expireOrdersLocked()
+	order, ok := pb.orders[req.OrderID]
+	if !ok {
+		return fmt.Errorf("order %s not found", req.OrderID)
+	}
+	if !order.isOpen() {
+		return fmt.Errorf("order %s is %s and cannot be cancelled", req.OrderID, order.status)
+	}
+
+	pb.cancelLocked(order)
+	for _, childID := range order.relatedIDs {
+		if child := pb.orders[childID]; child.isOpen() {
+			pb.cancelLocked(child)
+		}
+	}
+
+	pb.logger.Info("Paper order cancelled",
+		"function", "CancelOrder",
+		"order_id", order.id)
+	return nil
+}
+
+// ClosePosition implements BrokerClient.ClosePosition with a market order at the current touch
+// Closes PositionID when set, otherwise every position for Uic (the net position)
+func (pb *PaperBrokerClient) ClosePosition(ctx context.Context, req saxo.ClosePositionRequest) (*saxo.OrderResponse, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	if pb.shutdown {
+		return nil, errShutdown
+	}
+
+	var targets []*paperPosition
+	if req.PositionID != "" {
+		position, ok := pb.positions[req.PositionID]
+		if !ok {
+			return nil, fmt.Errorf("position %s not found", req.PositionID)
+		}
+		targets = append(targets, position)
+	} else {
+		targets = pb.positionsForUicLocked(req.Uic)
+		if len(targets) == 0 {
+			return nil, fmt.Errorf("no open position for UIC %d", req.Uic)
+		}
+	}
+
+	price, ok := pb.prices[targets[0].uic]
+	if !ok {
+		return nil, fmt.Errorf("no price for UIC %d yet - cannot close position", targets[0].uic)
+	}
+
+	remaining := req.Amount
+	var orderID string
+	for _, position := range targets {
+		amount := absFloat(position.amount)
+		if remaining > 0 && remaining < amount {
+			amount = remaining
+		}
+		side := "Sell"
+		if position.amount < 0 {
+			side = "Buy"
+		}
+
+		order := pb.newOrderLocked(position.uic, position.assetType, position.ticker, position.accountKey,
+			side, "Market", saxo.Duration(saxo.DurationFillOrKill), int(amount), 0, 0)
+		orderID = order.id
+		pb.fillLocked(order, price)
+
+		if remaining > 0 {
+			remaining -= amount
+			if remaining <= 0 {
+				break
+			}
+		}
+	}
+
+	return &saxo.OrderResponse{
+		OrderID:   orderID,
+		Status:    "Filled",
+		Timestamp: pb.config.Clock.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// GetOpenOrdersFiltered implements BrokerClient.GetOpenOrdersFiltered (FieldGroups are ignored)
+func (pb *PaperBrokerClient) GetOpenOrdersFiltered(ctx context.Context, params saxo.OpenOrdersParams) ([]saxo.LiveOrder, error) {
+	orders, err := pb.GetOpenOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filtered := orders[:0]
+	for _, order := range orders {
+		if params.Matches(order) {
+			filtered = append(filtered, order)
+		}
+	}
+	return filtered, nil
+}
+
+// GetOpenOrders implements BrokerClient.GetOpenOrders (working and inactive related orders)
+func (pb *PaperBrokerClient) GetOpenOrders(ctx context.Context) ([]saxo.LiveOrder, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	pb.expireOrdersLocked()
+	var orders []saxo.LiveOrder
+	for _, order := range pb.sortedOrdersLocked() {
+		if !order.isOpen() {
+			continue
+		}
+		live := saxo.LiveOrder{
+			OrderID:        order.id,
+			Uic:            order.uic,
+			Ticker:         order.ticker,
+			AssetType:      order.assetType,
+			OrderType:      order.orderType,
+			Amount:         float64(order.size),
+			Price:          order.price,
+			StopLimitPrice: order.stopLimitPrice,
+			OrderTime:      order.placedAt,
+			Status:         order.status,
+			BuySell:        order.side,
+			OrderDuration:  order.duration,
+			OrderRelation:  order.relation,
+			AccountKey:     order.accountKey,
+			ClientKey:      pb.config.ClientKey,
+			IsMarketOpen:   true,
+		}
+		if price, ok := pb.prices[order.uic]; ok {
+			live.MarketPrice = price.Mid
+			live.DistanceToMarket = absFloat(order.price - price.Mid)
+		}
+		for _, childID := range order.relatedIDs {
+			child := pb.orders[childID]
+			live.RelatedOrders = append(live.RelatedOrders, saxo.RelatedOrder{
+				OrderID:       child.id,
+				OpenOrderType: child.orderType,
+				OrderPrice:    child.price,
+				Amount:        float64(child.size),
+				Status:        child.status,
+			})
+		}
+		orders = append(orders, live)
+	}
+	return orders, nil
+}
+
+// GetOpenPositions implements BrokerClient.GetOpenPositions
+func (pb *PaperBrokerClient) GetOpenPositions(ctx context.Context) (*saxo.OpenPositionsResponse, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	response := &saxo.OpenPositionsResponse{}
+	for _, position := range pb.sortedPositionsLocked() {
+		var p saxo.SaxoOpenPosition
+		p.PositionID = position.id
+		p.NetPositionID = netPositionID(position.uic, position.assetType)
+		p.DisplayAndFormat.Symbol = position.ticker
+		p.PositionBase.AccountID = position.accountKey
+		p.PositionBase.AccountKey = position.accountKey
+		p.PositionBase.Amount = position.amount
+		p.PositionBase.AssetType = position.assetType
+		p.PositionBase.CanBeClosed = true
+		p.PositionBase.ExecutionTimeOpen = position.openedAt
+		p.PositionBase.IsMarketOpen = true
+		p.PositionBase.OpenPrice = position.openPrice
+		p.PositionBase.SourceOrderID = position.sourceOrderID
+		p.PositionBase.Status = "Open"
+		p.PositionBase.Uic = position.uic
+		if price, ok := pb.prices[position.uic]; ok {
+			current := closingPrice(position.amount, price)
+			p.PositionView.Bid = price.Bid
+			p.PositionView.Ask = price.Ask
+			p.PositionView.CurrentPrice = current
+			p.PositionView.Exposure = position.amount * current
+			p.PositionView.MarketValue = position.amount * current
+			p.PositionView.ProfitLossOnTrade = (current - position.openPrice) * position.amount
+		}
+		response.Data = append(response.Data, p)
+	}
+	response.Count = len(response.Data)
+	return response, nil
+}
+
+// GetNetPositions implements BrokerClient.GetNetPositions (positions aggregated per UIC)
+func (pb *PaperBrokerClient) GetNetPositions(ctx context.Context) (*saxo.NetPositionsResponse, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	byID := make(map[string]*saxo.SaxoNetPosition)
+	var ids []string
+	for _, position := range pb.sortedPositionsLocked() {
+		id := netPositionID(position.uic, position.assetType)
+		net, ok := byID[id]
+		if !ok {
+			net = &saxo.SaxoNetPosition{NetPositionID: id}
+			net.DisplayAndFormat.Symbol = position.ticker
+			net.NetPositionBase.AccountID = position.accountKey
+			net.NetPositionBase.AssetType = position.assetType
+			net.NetPositionBase.CanBeClosed = true
+			net.NetPositionBase.ExecutionTimeOpen = position.openedAt
+			net.NetPositionBase.IsMarketOpen = true
+			net.NetPositionBase.Status = "Open"
+			net.NetPositionBase.Uic = position.uic
+			net.PositionsAccount = position.accountKey
+			net.SinglePositionID = position.id
+			byID[id] = net
+			ids = append(ids, id)
+		}
+
+		// Volume-weighted open price across the aggregated positions
+		total := net.NetPositionBase.Amount + position.amount
+		if total != 0 {
+			net.NetPositionBase.OpenPrice = (net.NetPositionBase.OpenPrice*net.NetPositionBase.Amount +
+				position.openPrice*position.amount) / total
+		}
+		net.NetPositionBase.Amount = total
+		net.PositionsNotClosedCount++
+		if net.PositionsNotClosedCount > 1 {
+			net.SinglePositionID = ""
+		}
+	}
+
+	response := &saxo.NetPositionsResponse{}
+	for _, id := range ids {
+		net := byID[id]
+		if price, ok := pb.prices[net.NetPositionBase.Uic]; ok {
+			current := closingPrice(net.NetPositionBase.Amount, price)
+			net.NetPositionView.Bid = price.Bid
+			net.NetPositionView.Ask = price.Ask
+			net.NetPositionView.CurrentPrice = current
+			net.NetPositionView.Exposure = net.NetPositionBase.Amount * current
+			net.NetPositionView.MarketValue = net.NetPositionBase.Amount * current
+			net.NetPositionView.ProfitLossOnTrade = (current - net.NetPositionBase.OpenPrice) * net.NetPositionBase.Amount
+		}
+		response.Data = append(response.Data, *net)
+	}
+	response.Count = len(response.Data)
+	return response, nil
+}
+
+// GetClosedPositions implements BrokerClient.GetClosedPositions
+func (pb *PaperBrokerClient) GetClosedPositions(ctx context.Context) (*saxo.ClosedPositionsResponse, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	data := append([]saxo.SaxoClosedPosition(nil), pb.closed...)
+	return &saxo.ClosedPositionsResponse{Data: data, Count: len(data)}, nil
+}
+
+// GetHistoricalPositions implements BrokerClient.GetHistoricalPositions from the simulated closed trades
+// fromDate/toDate: "YYYY-MM-DD", inclusive; empty = unbounded
+func (pb *PaperBrokerClient) GetHistoricalPositions(ctx context.Context, clientKey, fromDate, toDate string) (*saxo.HistoricalPositionsResponse, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	response := &saxo.HistoricalPositionsResponse{}
+	for _, closed := range pb.closed {
+		c := closed.ClosedPosition
+		tradeDate := c.ExecutionTimeClose.Format("2006-01-02")
+		if (fromDate != "" && tradeDate < fromDate) || (toDate != "" && tradeDate > toDate) {
+			continue
+		}
+		longShort := "Long"
+		if c.BuyOrSell == "Sell" {
+			longShort = "Short"
+		}
+		h := saxo.SaxoHistoricalPosition{
+			AccountID:          c.AccountID,
+			Amount:             c.Amount,
+			ClosingAssetType:   c.AssetType,
+			ClosingTradeDate:   tradeDate,
+			ExecutionTimeClose: c.ExecutionTimeClose,
+			ExecutionTimeOpen:  c.ExecutionTimeOpen,
+			InstrumentSymbol:   closed.DisplayAndFormat.Symbol,
+			OpeningAssetType:   c.AssetType,
+			PriceClose:         c.ClosingPrice,
+			PriceOpen:          c.OpenPrice,
+			ProfitLoss:         c.ClosedProfitLoss,
+			Uic:                strconv.Itoa(c.Uic),
+		}
+		h.LongShort.PresentationValue = longShort
+		response.Data = append(response.Data, h)
+	}
+	response.Count = len(response.Data)
+	return response, nil
+}
+
+// GetBalance implements BrokerClient.GetBalance from simulated cash, open P&L and margin
+func (pb *PaperBrokerClient) GetBalance(ctx context.Context) (*saxo.Balance, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	return pb.balanceLocked(), nil
+}
+
+// GetAccounts implements BrokerClient.GetAccounts (a single simulated account)
+func (pb *PaperBrokerClient) GetAccounts(ctx context.Context) (*saxo.Accounts, error) {
+	info, _ := pb.GetAccountInfo(ctx)
+	return &saxo.Accounts{Data: []saxo.SaxoAccountInfo{*info}}, nil
+}
+
+// GetAccountInfo implements BrokerClient.GetAccountInfo
+func (pb *PaperBrokerClient) GetAccountInfo(ctx context.Context) (*saxo.AccountInfo, error) {
+	return &saxo.AccountInfo{
+		AccountKey:  pb.config.AccountKey,
+		AccountType: "Normal",
+		Currency:    pb.config.Currency,
+		ClientKey:   pb.config.ClientKey,
+	}, nil
+}
+
+// GetClientInfo implements BrokerClient.GetClientInfo (also satisfies saxo.ClientInfoProvider)
+func (pb *PaperBrokerClient) GetClientInfo(ctx context.Context) (*saxo.ClientInfo, error) {
+	return &saxo.ClientInfo{
+		Active:    true,
+		ClientKey: pb.config.ClientKey,
+		Name:      "Paper Trading",
+		UserKey:   pb.config.ClientKey,
+	}, nil
+}
+
+// GetMarginOverview implements BrokerClient.GetMarginOverview with one contributor per net position
+func (pb *PaperBrokerClient) GetMarginOverview(ctx context.Context, clientKey string) (*saxo.MarginOverview, error) {
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+
+	overview := &saxo.MarginOverview{}
+	overview.Groups = make([]struct {
+		Contributors []struct {
+			AssetTypes            []string `json:"AssetTypes"`
+			InstrumentDescription string   `json:"InstrumentDescription"`
+			InstrumentSpecifier   string   `json:"InstrumentSpecifier"`
+			Margin                float64  `json:"Margin"`
+			Uic                   int      `json:"Uic"`
+		} `json:"Contributors"`
+		GroupType   string  `json:"GroupType"`
+		TotalMargin float64 `json:"TotalMargin"`
+	}, 1)
+	group := &overview.Groups[0]
+	group.GroupType = "Paper"
+
+	margins := make(map[int]float64)
+	assetTypes := make(map[int]string)
+	tickers := make(map[int]string)
+	var uics []int
+	for _, position := range pb.sortedPositionsLocked() {
+		if _, ok := margins[position.uic]; !ok {
+			uics = append(uics, position.uic)
+		}
+		margins[position.uic] += pb.positionMarginLocked(position)
+		assetTypes[position.uic] = position.assetType
+		tickers[position.uic] = position.ticker
+	}
+	for _, uic := range uics {
+		group.Contributors = append(group.Contributors, struct {
+			AssetTypes            []string `json:"AssetTypes"`
+			InstrumentDescription string   `json:"InstrumentDescription"`
+			InstrumentSpecifier   string   `json:"InstrumentSpecifier"`
+			Margin                float64  `json:"Margin"`
+			Uic                   int      `json:"Uic"`
+		}{
+			AssetTypes:            []string{assetTypes[uic]},
+			InstrumentDescription: tickers[uic],
+			Margin:                margins[uic],
+			Uic:                   uic,
+		})
+		group.TotalMargin += margins[uic]
+	}
+	return overview, nil
+}
+
+// GetInstrumentPrice implements BrokerClient.GetInstrumentPrice from the last streamed quote
+// Falls back to Config.Reference when no quote has been seen for the instrument
+func (pb *PaperBrokerClient) GetInstrumentPrice(ctx context.Context, instrument saxo.Instrument) (*saxo.PriceData, error) {
+	uic := instrument.Uic
+	if uic == 0 {
+		uic = instrument.Identifier
+	}
+
+	pb.mu.Lock()
+	price, ok := pb.prices[uic]
+	pb.mu.Unlock()
+
+	if !ok {
+		if pb.config.Reference == nil {
+			return nil, fmt.Errorf("no price for UIC %d yet: %w", uic, errNoReference)
+		}
+		return pb.config.Reference.GetInstrumentPrice(ctx, instrument)
+	}
+
+	return &saxo.PriceData{
+		Ticker:    instrument.Ticker,
+		Bid:       price.Bid,
+		Ask:       price.Ask,
+		Mid:       price.Mid,
+		Spread:    price.Ask - price.Bid,
+		Timestamp: price.Timestamp.Format(time.RFC3339),
+	}, nil
+}
+
+// GetTradingSchedule delegates to Config.Reference
+func (pb *PaperBrokerClient) GetTradingSchedule(ctx context.Context, params saxo.TradingScheduleParams) (*saxo.TradingSchedule, error) {
+	if pb.config.Reference == nil {
+		return nil, errNoReference
+	}
+	return pb.config.Reference.GetTradingSchedule(ctx, params)
+}
+
+// IsMarketOpen delegates to Config.Reference - without one the paper market is always open
+func (pb *PaperBrokerClient) IsMarketOpen(ctx context.Context, instrument saxo.Instrument) (bool, error) {
+	if pb.config.Reference == nil {
+		return true, nil
+	}
+	return pb.config.Reference.IsMarketOpen(ctx, instrument)
+}
+
+// SearchInstruments delegates to Config.Reference
+func (pb *PaperBrokerClient) SearchInstruments(ctx context.Context, params saxo.InstrumentSearchParams) (*saxo.InstrumentSearchResult, error) {
+	if pb.config.Reference == nil {
+		return nil, errNoReference
+	}
+	return pb.config.Reference.SearchInstruments(ctx, params)
+}
+
+// GetInstrumentDetails delegates to Config.Reference
+func (pb *PaperBrokerClient) GetInstrumentDetails(ctx context.Context, uics []int) ([]saxo.InstrumentDetail, error) {
+	if pb.config.Reference == nil {
+		return nil, errNoReference
+	}
+	return pb.config.Reference.GetInstrumentDetails(ctx, uics)
+}
+
+// GetInstrumentPrices delegates to Config.Reference
+func (pb *PaperBrokerClient) GetInstrumentPrices(ctx context.Context, uics []int, fieldGroups string, assetType string) ([]saxo.InstrumentPriceInfo, error) {
+	if pb.config.Reference == nil {
+		return nil, errNoReference
+	}
+	return pb.config.Reference.GetInstrumentPrices(ctx, uics, fieldGroups, assetType)
+}
+
+// GetHistoricalData delegates to Config.Reference
+func (pb *PaperBrokerClient) GetHistoricalData(ctx context.Context, instrument saxo.Instrument, days int, cutoffTime time.Time) ([]saxo.HistoricalDataPoint, error) {
+	if pb.config.Reference == nil {
+		return nil, errNoReference
+	}
+	return pb.config.Reference.GetHistoricalData(ctx, instrument, days, cutoffTime)
+}
+
+// SetSessionCapabilities is a no-op - the simulated session always has full trading
+func (pb *PaperBrokerClient) SetSessionCapabilities(ctx context.Context, tradeLevel string) error {
+	return nil
+}
+
+// GetSessionCapabilities reports full trading with realtime data
+func (pb *PaperBrokerClient) GetSessionCapabilities(ctx context.Context) (*saxo.SessionCapabilities, error) {
+	return &saxo.SessionCapabilities{
+		AuthenticationLevel: "Complete",
+		DataLevel:           "Realtime",
+		TradeLevel:          saxo.TradeLevelFullTradingAndChat,
+	}, nil
+}
+
+// SetTradeLevel is a no-op like SetSessionCapabilities
+func (pb *PaperBrokerClient) SetTradeLevel(ctx context.Context, level string) error {
+	return nil
+}
+
+// sortedOrdersLocked returns orders in placement order
+func (pb *PaperBrokerClient) sortedOrdersLocked() []*paperOrder {
+	orders := make([]*paperOrder, 0, len(pb.orders))
+	for _, order := range pb.orders {
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].seq < orders[j].seq })
+	return orders
+}
+
+// sortedPositionsLocked returns open positions in opening order
+func (pb *PaperBrokerClient) sortedPositionsLocked() []*paperPosition {
+	positions := make([]*paperPosition, 0, len(pb.positions))
+	for _, position := range pb.positions {
+		positions = append(positions, position)
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].seq < positions[j].seq })
+	return positions
+}
@@ -0,0 +1,361 @@
+package paper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func newTestPaperBroker(t *testing.T) *PaperBrokerClient {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	pb := NewPaperBrokerClient(Config{InitialBalance: 10000}, logger)
+	t.Cleanup(func() { pb.Shutdown(context.Background()) })
+	return pb
+}
+
+func eurusd() saxo.Instrument {
+	return saxo.Instrument{Ticker: "EURUSD", Uic: 21, AssetType: "FxSpot"}
+}
+
+func quote(bid, ask float64) saxo.PriceUpdate {
+	return saxo.PriceUpdate{Uic: 21, Bid: bid, Ask: ask, Mid: (bid + ask) / 2, Timestamp: time.Now()}
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestPaperBroker_MarketOrderRoundTrip(t *testing.T) {
+	pb := newTestPaperBroker(t)
+	ctx := context.Background()
+
+	// No quote yet - market order cannot fill
+	if _, err := pb.PlaceOrder(ctx, saxo.OrderRequest{Instrument: eurusd(), Side: "Buy", Size: 10000, OrderType: "Market"}); err == nil {
+		t.Fatal("Expected market order without a price to fail")
+	}
+
+	pb.UpdatePrice(quote(1.1000, 1.1002))
+	resp, err := pb.PlaceOrder(ctx, saxo.OrderRequest{Instrument: eurusd(), Side: "Buy", Size: 10000, OrderType: "Market"})
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	if resp.Status != "Filled" {
+		t.Errorf("Expected Filled, got %s", resp.Status)
+	}
+
+	positions, _ := pb.GetOpenPositions(ctx)
+	if positions.Count != 1 || !almostEqual(positions.Data[0].PositionBase.OpenPrice, 1.1002) {
+		t.Fatalf("Expected one long position opened at the ask, got %+v", positions.Data)
+	}
+
+	// Price moves up 50 pips, close at the bid
+	pb.UpdatePrice(quote(1.1052, 1.1054))
+	if _, err := pb.ClosePosition(ctx, saxo.ClosePositionRequest{PositionID: positions.Data[0].PositionID}); err != nil {
+		t.Fatalf("ClosePosition failed: %v", err)
+	}
+
+	balance, _ := pb.GetBalance(ctx)
+	if !almostEqual(balance.CashBalance, 10000+0.0050*10000) {
+		t.Errorf("Expected realized profit of 50, cash balance %.4f", balance.CashBalance)
+	}
+	closed, _ := pb.GetClosedPositions(ctx)
+	if closed.Count != 1 {
+		t.Errorf("Expected 1 closed position, got %d", closed.Count)
+	}
+	if balance.OpenPositionsCount != 0 {
+		t.Errorf("Expected no open positions, got %d", balance.OpenPositionsCount)
+	}
+}
+
+func TestPaperBroker_IfDoneOco(t *testing.T) {
+	pb := newTestPaperBroker(t)
+	ctx := context.Background()
+
+	pb.UpdatePrice(quote(1.1000, 1.1002))
+
+	// Buy limit below market, with take profit and stop loss
+	resp, err := pb.PlaceOrder(ctx, saxo.OrderRequest{
+		Instrument: eurusd(),
+		Side:       "Buy",
+		Size:       10000,
+		Price:      1.0950,
+		OrderType:  "Limit",
+		Duration:   saxo.Duration(saxo.DurationGoodTillCancel),
+		RelatedOrders: []saxo.RelatedOrderRequest{
+			{Side: "Sell", OrderType: "Limit", Price: 1.1050},
+			{Side: "Sell", OrderType: "StopIfTraded", Price: 1.0900},
+		},
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	if resp.Status != "Working" || len(resp.RelatedOrderIDs) != 2 {
+		t.Fatalf("Expected working entry with 2 related orders, got %+v", resp)
+	}
+	target, stop := resp.RelatedOrderIDs[0], resp.RelatedOrderIDs[1]
+
+	// Related orders inactive until the entry fills
+	if status, _ := pb.GetOrderStatus(ctx, stop); status.Status != "NotWorking" {
+		t.Errorf("Expected stop NotWorking before entry fill, got %s", status.Status)
+	}
+
+	pb.UpdatePrice(quote(1.0948, 1.0950)) // Entry fills
+	if status, _ := pb.GetOrderStatus(ctx, resp.OrderID); status.Status != "Filled" {
+		t.Fatalf("Expected entry Filled, got %s", status.Status)
+	}
+	if status, _ := pb.GetOrderStatus(ctx, stop); status.Status != "Working" {
+		t.Errorf("Expected stop Working after entry fill, got %s", status.Status)
+	}
+
+	pb.UpdatePrice(quote(1.1050, 1.1052)) // Target fills, stop cancelled
+	if status, _ := pb.GetOrderStatus(ctx, target); status.Status != "Filled" {
+		t.Errorf("Expected target Filled, got %s", status.Status)
+	}
+	if status, _ := pb.GetOrderStatus(ctx, stop); status.Status != "Cancelled" {
+		t.Errorf("Expected stop Cancelled by OCO, got %s", status.Status)
+	}
+
+	open, _ := pb.GetOpenOrders(ctx)
+	if len(open) != 0 {
+		t.Errorf("Expected no open orders, got %d", len(open))
+	}
+
+	// Event stream mirrors the lifecycle: entry fill carries __meta_deleted
+	var sawEntryFill bool
+	for len(pb.GetOrderUpdateChannel()) > 0 {
+		update := <-pb.GetOrderUpdateChannel()
+		if update.OrderId == resp.OrderID && update.Status == "Filled" {
+			sawEntryFill = update.MetaDeleted != nil && *update.MetaDeleted
+		}
+	}
+	if !sawEntryFill {
+		t.Error("Expected entry fill OrderUpdate with __meta_deleted")
+	}
+	select {
+	case <-pb.GetPortfolioUpdateChannel():
+	default:
+		t.Error("Expected PortfolioUpdate after fills")
+	}
+}
+
+func TestPaperBroker_ModifyRelatedOrders(t *testing.T) {
+	pb := newTestPaperBroker(t)
+	ctx := context.Background()
+
+	pb.UpdatePrice(quote(1.1000, 1.1002))
+	resp, err := pb.PlaceOrder(ctx, saxo.OrderRequest{
+		Instrument: eurusd(),
+		Side:       "Buy",
+		Size:       10000,
+		Price:      1.0950,
+		OrderType:  "Limit",
+		Duration:   saxo.Duration(saxo.DurationGoodTillCancel),
+		RelatedOrders: []saxo.RelatedOrderRequest{
+			{Side: "Sell", OrderType: "Limit", Price: 1.1050},
+			{Side: "Sell", OrderType: "StopIfTraded", Price: 1.0900},
+		},
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	stop := resp.RelatedOrderIDs[1]
+
+	// Resize the entry and move its stop in one request
+	req := saxo.OrderModificationRequest{OrderID: resp.OrderID, Amount: 20000}
+	req.RelatedOrders = []saxo.RelatedOrderModification{{OrderID: stop, OrderPrice: "1.0920"}}
+	if _, err := pb.ModifyOrder(ctx, req); err != nil {
+		t.Fatalf("ModifyOrder failed: %v", err)
+	}
+	if status, _ := pb.GetOrderStatus(ctx, resp.OrderID); status.Size != 20000 {
+		t.Errorf("Expected entry size 20000, got %d", status.Size)
+	}
+	if status, _ := pb.GetOrderStatus(ctx, stop); !almostEqual(status.Price, 1.0920) {
+		t.Errorf("Expected stop moved to 1.0920, got %v", status.Price)
+	}
+
+	// An invalid related change leaves the entry untouched
+	req = saxo.OrderModificationRequest{OrderID: resp.OrderID, OrderPrice: "1.0940"}
+	req.RelatedOrders = []saxo.RelatedOrderModification{{OrderID: stop, Amount: -1}}
+	if _, err := pb.ModifyOrder(ctx, req); err == nil {
+		t.Error("Expected negative related amount to fail")
+	}
+	if status, _ := pb.GetOrderStatus(ctx, resp.OrderID); !almostEqual(status.Price, 1.0950) {
+		t.Errorf("Failed modification changed entry price to %v", status.Price)
+	}
+
+	// Orders outside the OCO group are rejected
+	other, err := pb.PlaceOrder(ctx, saxo.OrderRequest{
+		Instrument: eurusd(), Side: "Buy", Size: 10000, Price: 1.0800, OrderType: "Limit",
+	})
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	req = saxo.OrderModificationRequest{OrderID: resp.OrderID}
+	req.RelatedOrders = []saxo.RelatedOrderModification{{OrderID: other.OrderID, OrderPrice: "1.0700"}}
+	if _, err := pb.ModifyOrder(ctx, req); err == nil {
+		t.Error("Expected unrelated order to be rejected")
+	}
+}
+
+func TestPaperBroker_MarginAndReference(t *testing.T) {
+	pb := newTestPaperBroker(t)
+	ctx := context.Background()
+
+	pb.UpdatePrice(quote(1.1000, 1.1002))
+
+	// 10000 balance at 5% margin supports ~181k notional
+	if _, err := pb.PlaceOrder(ctx, saxo.OrderRequest{Instrument: eurusd(), Side: "Buy", Size: 1000000, OrderType: "Market"}); err == nil {
+		t.Error("Expected insufficient margin error")
+	}
+
+	if _, err := pb.SearchInstruments(ctx, saxo.InstrumentSearchParams{Keywords: "EUR"}); !errors.Is(err, errNoReference) {
+		t.Errorf("Expected errNoReference without Config.Reference, got %v", err)
+	}
+
+	price, err := pb.GetInstrumentPrice(ctx, eurusd())
+	if err != nil || !almostEqual(price.Bid, 1.1000) {
+		t.Errorf("Expected streamed quote from GetInstrumentPrice, got %+v, %v", price, err)
+	}
+}
+
+func TestPaperBroker_MarginOnReversal(t *testing.T) {
+	pb := newTestPaperBroker(t)
+	ctx := context.Background()
+
+	pb.UpdatePrice(quote(1.1000, 1.1002))
+	if _, err := pb.PlaceOrder(ctx, saxo.OrderRequest{Instrument: eurusd(), Side: "Buy", Size: 100000, OrderType: "Market"}); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+
+	// Closing 100k is free, the 300k reversal beyond it needs ~16.5k margin with ~4.5k available
+	if _, err := pb.PlaceOrder(ctx, saxo.OrderRequest{Instrument: eurusd(), Side: "Sell", Size: 400000, OrderType: "Market"}); err == nil {
+		t.Error("Expected the reversal excess to be margin checked")
+	}
+	if _, err := pb.PlaceOrder(ctx, saxo.OrderRequest{Instrument: eurusd(), Side: "Sell", Size: 150000, OrderType: "Market"}); err != nil {
+		t.Fatalf("Expected a reversal within margin to fill: %v", err)
+	}
+	positions, _ := pb.GetOpenPositions(ctx)
+	if positions.Count != 1 || positions.Data[0].PositionBase.Amount != -50000 {
+		t.Errorf("Expected a 50k short after the reversal, got %+v", positions.Data)
+	}
+
+	// Without a mid the order price values the margin, and without either the order is refused
+	gbpusd := saxo.Instrument{Ticker: "GBPUSD", Uic: 31, AssetType: "FxSpot"}
+	if _, err := pb.PlaceOrder(ctx, saxo.OrderRequest{Instrument: gbpusd, Side: "Buy", Size: 1000000, Price: 1.25, OrderType: "Limit"}); err == nil {
+		t.Error("Expected the limit order to be margin checked at its price")
+	}
+	pb.UpdatePrice(saxo.PriceUpdate{Uic: 31, Bid: 1.2500, Ask: 1.2502})
+	if _, err := pb.PlaceOrder(ctx, saxo.OrderRequest{Instrument: gbpusd, Side: "Buy", Size: 10000, OrderType: "Market"}); err == nil {
+		t.Error("Expected a zero mid without an order price to be refused")
+	}
+}
+
+func TestPaperBroker_CashAmountOrder(t *testing.T) {
+	pb := newTestPaperBroker(t)
+	ctx := context.Background()
+
+	// FX is traded in units only, as on the REST client
+	pb.UpdatePrice(quote(1.1000, 1.1002))
+	fxOrder := saxo.OrderRequest{Instrument: eurusd(), Side: "Buy", OrderType: "Market", OrderAmountType: saxo.AmountTypeCashAmount, CashAmount: 1000}
+	if _, err := pb.PlaceOrder(ctx, fxOrder); err == nil {
+		t.Error("Expected a CashAmount FxSpot order to be rejected")
+	}
+
+	aapl := saxo.Instrument{Ticker: "AAPL", Uic: 211, AssetType: saxo.AssetTypeStock}
+	pb.UpdatePrice(saxo.PriceUpdate{Uic: 211, Bid: 99.9, Ask: 100.1, Mid: 100, Timestamp: time.Now()})
+	order := saxo.OrderRequest{Instrument: aapl, Side: "Buy", OrderType: "Market", OrderAmountType: saxo.AmountTypeCashAmount, CashAmount: 1000}
+	if _, err := pb.PlaceOrder(ctx, order); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	positions, _ := pb.GetOpenPositions(ctx)
+	if positions.Count != 1 || positions.Data[0].PositionBase.Amount != 10 {
+		t.Fatalf("Expected 1000 / 100 = 10 shares, got %+v", positions.Data)
+	}
+
+	order.CashAmount = 50
+	if _, err := pb.PlaceOrder(ctx, order); err == nil {
+		t.Error("Expected a cash amount below one unit to fail")
+	}
+}
+
+func TestPaperBroker_StartAndShutdown(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	pb := NewPaperBrokerClient(Config{}, logger)
+
+	prices := make(chan saxo.PriceUpdate, 1)
+	pb.Start(context.Background(), prices)
+	prices <- quote(1.2000, 1.2002)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := pb.GetInstrumentPrice(context.Background(), eurusd()); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Price from channel never reached the paper broker")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := saxo.Shutdown(context.Background(), pb); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if _, ok := <-pb.GetOrderUpdateChannel(); ok {
+		t.Error("Expected order update channel closed after Shutdown")
+	}
+	if _, err := pb.PlaceOrder(context.Background(), saxo.OrderRequest{Instrument: eurusd(), Side: "Buy", Size: 1, OrderType: "Market"}); !errors.Is(err, errShutdown) {
+		t.Errorf("Expected errShutdown, got %v", err)
+	}
+}
+
+func TestPaperBroker_GoodTillDateExpires(t *testing.T) {
+	clock := mocktesting.NewFakeClock(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	pb := NewPaperBrokerClient(Config{InitialBalance: 10000, Clock: clock}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	t.Cleanup(func() { pb.Shutdown(context.Background()) })
+	ctx := context.Background()
+
+	pb.UpdatePrice(quote(1.1000, 1.1002))
+	timed, err := pb.PlaceOrder(ctx, saxo.OrderRequest{Instrument: eurusd(), Side: "Buy", Size: 1000, OrderType: "Limit", Price: 1.0900,
+		Duration:      saxo.GoodTillTime(clock.Now().Add(time.Hour)),
+		RelatedOrders: []saxo.RelatedOrderRequest{{Side: "Sell", OrderType: "StopIfTraded", Price: 1.0850}}})
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	dated, err := pb.PlaceOrder(ctx, saxo.OrderRequest{Instrument: eurusd(), Side: "Buy", Size: 1000, OrderType: "Limit", Price: 1.0900,
+		Duration: saxo.GoodTillDate(clock.Now())})
+	if err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+
+	// An hour later the timed order and its inactive stop are gone, the dated one works through the day
+	clock.Advance(time.Hour)
+	if status, _ := pb.GetOrderStatus(ctx, timed.OrderID); status.Status != "Expired" {
+		t.Errorf("Expected the timed order expired, got %s", status.Status)
+	}
+	if status, _ := pb.GetOrderStatus(ctx, timed.RelatedOrderIDs[0]); status.Status != "Cancelled" {
+		t.Errorf("Expected the related stop cancelled, got %s", status.Status)
+	}
+	orders, _ := pb.GetOpenOrders(ctx)
+	if len(orders) != 1 || orders[0].OrderID != dated.OrderID {
+		t.Fatalf("Expected only the dated order open, got %+v", orders)
+	}
+
+	// Past midnight the dated order expires before a quote that would have filled it
+	clock.Advance(15 * time.Hour)
+	pb.UpdatePrice(quote(1.0890, 1.0892))
+	if status, _ := pb.GetOrderStatus(ctx, dated.OrderID); status.Status != "Expired" {
+		t.Errorf("Expected the dated order expired, got %s", status.Status)
+	}
+	if positions, _ := pb.GetOpenPositions(ctx); positions.Count != 0 {
+		t.Errorf("Expected no fill of an expired order, got %+v", positions.Data)
+	}
+}
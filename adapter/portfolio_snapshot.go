@@ -0,0 +1,264 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PortfolioSnapshotVersion is bumped whenever a PortfolioSnapshot field changes meaning or is removed
+// New fields are added without a bump; consumers should ignore unknown fields
+const PortfolioSnapshotVersion = 1
+
+// Portfolio snapshot sections, keys of PortfolioSnapshot.Errors
+const (
+	SnapshotSectionClient    = "client"
+	SnapshotSectionBalance   = "balance"
+	SnapshotSectionAccounts  = "accounts"
+	SnapshotSectionOrders    = "orders"
+	SnapshotSectionPositions = "positions"
+)
+
+// PortfolioSnapshot is the account state at one moment in a stable, JSON-serializable form
+// It does not follow Saxo's response shapes, so stored snapshots stay readable across adapter versions
+type PortfolioSnapshot struct {
+	Version   int                `json:"version"`
+	TakenAt   time.Time          `json:"taken_at"`
+	ClientKey string             `json:"client_key,omitempty"`
+	Balance   *SnapshotBalance   `json:"balance,omitempty"`
+	Accounts  []SnapshotAccount  `json:"accounts"`
+	Orders    []SnapshotOrder    `json:"orders"`
+	Positions []SnapshotPosition `json:"positions"`
+	Errors    map[string]string  `json:"errors,omitempty"` // Section -> error for sections that failed
+}
+
+// SnapshotBalance is the account balance part of a PortfolioSnapshot
+type SnapshotBalance struct {
+	Currency                string  `json:"currency"`
+	TotalValue              float64 `json:"total_value"`
+	CashBalance             float64 `json:"cash_balance"`
+	CashAvailableForTrading float64 `json:"cash_available_for_trading"`
+	UnrealizedProfitLoss    float64 `json:"unrealized_profit_loss"`
+	MarginUsed              float64 `json:"margin_used"`
+	MarginAvailable         float64 `json:"margin_available"`
+	MarginUtilizationPct    float64 `json:"margin_utilization_pct"`
+}
+
+// SnapshotAccount is one trading account of a PortfolioSnapshot
+type SnapshotAccount struct {
+	AccountKey  string `json:"account_key"`
+	AccountType string `json:"account_type"`
+	Currency    string `json:"currency"`
+}
+
+// SnapshotOrder is one working order of a PortfolioSnapshot
+type SnapshotOrder struct {
+	OrderID       string    `json:"order_id"`
+	AccountKey    string    `json:"account_key"`
+	Uic           int       `json:"uic"`
+	AssetType     string    `json:"asset_type"`
+	Symbol        string    `json:"symbol"`
+	BuySell       string    `json:"buy_sell"`
+	OrderType     string    `json:"order_type"`
+	Amount        float64   `json:"amount"`
+	Price         float64   `json:"price"`
+	Duration      string    `json:"duration"`
+	Status        string    `json:"status"`
+	OrderTime     time.Time `json:"order_time"`
+	RelatedOrders []string  `json:"related_orders,omitempty"` // Order IDs of OCO/IfDone orders
+}
+
+// SnapshotPosition is one net position of a PortfolioSnapshot
+type SnapshotPosition struct {
+	NetPositionID  string  `json:"net_position_id"`
+	AccountID      string  `json:"account_id"`
+	Uic            int     `json:"uic"`
+	AssetType      string  `json:"asset_type"`
+	Symbol         string  `json:"symbol"`
+	Currency       string  `json:"currency"`
+	Amount         float64 `json:"amount"` // Negative for short
+	OpenPrice      float64 `json:"open_price"`
+	CurrentPrice   float64 `json:"current_price"`
+	ProfitLoss     float64 `json:"profit_loss"`
+	ProfitLossBase float64 `json:"profit_loss_base"` // In the account currency
+	MarketValue    float64 `json:"market_value"`
+}
+
+// SnapshotSectionError reports one section of SnapshotPortfolio that could not be fetched
+type SnapshotSectionError struct {
+	Section string
+	Err     error
+}
+
+func (e *SnapshotSectionError) Error() string {
+	return fmt.Sprintf("portfolio snapshot %s: %v", e.Section, e.Err)
+}
+
+func (e *SnapshotSectionError) Unwrap() error {
+	return e.Err
+}
+
+// SnapshotPortfolio fetches client, balance, accounts, open orders and net positions concurrently
+// A failed section is left empty, recorded in Errors and joined into the returned error as a
+// *SnapshotSectionError; the snapshot is returned either way
+func (sbc *SaxoBrokerClient) SnapshotPortfolio(ctx context.Context) (*PortfolioSnapshot, error) {
+	if !sbc.authClient.IsAuthenticated() {
+		return nil, sbc.notAuthenticated()
+	}
+
+	snapshot := &PortfolioSnapshot{
+		Version:   PortfolioSnapshotVersion,
+		TakenAt:   sbc.clock.Now().UTC(),
+		Accounts:  []SnapshotAccount{},
+		Orders:    []SnapshotOrder{},
+		Positions: []SnapshotPosition{},
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	section := func(name string, fetch func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fetch(); err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, &SnapshotSectionError{Section: name, Err: err})
+				if snapshot.Errors == nil {
+					snapshot.Errors = make(map[string]string)
+				}
+				snapshot.Errors[name] = err.Error()
+			}
+		}()
+	}
+
+	section(SnapshotSectionClient, func() error {
+		info, err := sbc.GetClientInfo(ctx)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		snapshot.ClientKey = info.ClientKey
+		return nil
+	})
+	section(SnapshotSectionBalance, func() error {
+		balance, err := sbc.GetBalance(ctx)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		snapshot.Balance = snapshotBalance(balance)
+		return nil
+	})
+	section(SnapshotSectionAccounts, func() error {
+		accounts, err := sbc.GetAccounts(ctx)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, account := range accounts.Data {
+			snapshot.Accounts = append(snapshot.Accounts, SnapshotAccount{
+				AccountKey:  account.AccountKey,
+				AccountType: account.AccountType,
+				Currency:    account.Currency,
+			})
+		}
+		return nil
+	})
+	section(SnapshotSectionOrders, func() error {
+		orders, err := sbc.GetOpenOrders(ctx)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, order := range orders {
+			snapshot.Orders = append(snapshot.Orders, snapshotOrder(order))
+		}
+		return nil
+	})
+	section(SnapshotSectionPositions, func() error {
+		positions, err := sbc.GetNetPositions(ctx)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, position := range positions.Data {
+			snapshot.Positions = append(snapshot.Positions, snapshotPosition(position))
+		}
+		return nil
+	})
+	wg.Wait()
+
+	err := errors.Join(errs...)
+	if err != nil {
+		sbc.logger.Warn("Portfolio snapshot incomplete",
+			"function", "SnapshotPortfolio",
+			"failed_sections", len(errs),
+			"error", err)
+	} else {
+		sbc.logger.Info("Portfolio snapshot taken",
+			"function", "SnapshotPortfolio",
+			"orders", len(snapshot.Orders),
+			"positions", len(snapshot.Positions))
+	}
+	return snapshot, err
+}
+
+func snapshotBalance(balance *Balance) *SnapshotBalance {
+	return &SnapshotBalance{
+		Currency:                balance.Currency,
+		TotalValue:              balance.TotalValue,
+		CashBalance:             balance.CashBalance,
+		CashAvailableForTrading: balance.CashAvailableForTrading,
+		UnrealizedProfitLoss:    balance.UnrealizedMarginProfitLoss,
+		MarginUsed:              balance.MarginUsedByCurrentPositions,
+		MarginAvailable:         balance.MarginAvailableForTrading,
+		MarginUtilizationPct:    balance.MarginUtilizationPct,
+	}
+}
+
+func snapshotOrder(order LiveOrder) SnapshotOrder {
+	snapshotted := SnapshotOrder{
+		OrderID:    order.OrderID,
+		AccountKey: order.AccountKey,
+		Uic:        order.Uic,
+		AssetType:  order.AssetType,
+		Symbol:     order.DisplayAndFormat.Symbol,
+		BuySell:    order.BuySell,
+		OrderType:  order.OrderType,
+		Amount:     order.Amount,
+		Price:      order.Price,
+		Duration:   order.OrderDuration,
+		Status:     order.Status,
+		OrderTime:  order.OrderTime,
+	}
+	for _, related := range order.RelatedOrders {
+		snapshotted.RelatedOrders = append(snapshotted.RelatedOrders, related.OrderID)
+	}
+	return snapshotted
+}
+
+func snapshotPosition(position SaxoNetPosition) SnapshotPosition {
+	return SnapshotPosition{
+		NetPositionID:  position.NetPositionID,
+		AccountID:      position.NetPositionBase.AccountID,
+		Uic:            position.NetPositionBase.Uic,
+		AssetType:      position.NetPositionBase.AssetType,
+		Symbol:         position.DisplayAndFormat.Symbol,
+		Currency:       position.DisplayAndFormat.Currency,
+		Amount:         position.NetPositionBase.Amount,
+		OpenPrice:      position.NetPositionBase.OpenPrice,
+		CurrentPrice:   position.NetPositionView.CurrentPrice,
+		ProfitLoss:     position.NetPositionView.ProfitLossOnTrade,
+		ProfitLossBase: position.NetPositionView.ProfitLossOnTradeInBaseCurrency,
+		MarketValue:    position.NetPositionView.MarketValue,
+	}
+}
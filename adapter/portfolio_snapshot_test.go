@@ -0,0 +1,55 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestSaxoBrokerClient_SnapshotPortfolio(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	mockServer.SetResponse("GET", "/port/v1/users/me", http.StatusOK, SaxoClientInfo{ClientKey: "client1"})
+	mockServer.SetResponse("GET", "/port/v1/balances/me", http.StatusOK, SaxoBalance{Currency: "EUR", TotalValue: 10250, CashBalance: 10000})
+	mockServer.SetResponse("GET", "/port/v1/accounts/me", http.StatusOK, SaxoAccounts{Data: []SaxoAccountInfo{{AccountKey: "acc1", Currency: "EUR"}}})
+	mockServer.SetOpenOrdersResponse([]SaxoOpenOrder{{OrderID: "1", Uic: 21, AssetType: "FxSpot", Status: "Working", AccountKey: "acc1"}})
+	mockServer.SetResponse("GET", "/port/v1/netpositions/me", http.StatusServiceUnavailable, map[string]string{"Message": "down"})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+
+	snapshot, err := client.SnapshotPortfolio(context.Background())
+	if snapshot == nil {
+		t.Fatalf("Expected a partial snapshot, got nil (%v)", err)
+	}
+	var sectionErr *SnapshotSectionError
+	if !errors.As(err, &sectionErr) || sectionErr.Section != SnapshotSectionPositions {
+		t.Fatalf("Expected positions section error, got %v", err)
+	}
+	if _, failed := snapshot.Errors[SnapshotSectionPositions]; !failed || len(snapshot.Errors) != 1 {
+		t.Errorf("Expected only positions in Errors, got %v", snapshot.Errors)
+	}
+	if snapshot.Version != PortfolioSnapshotVersion || snapshot.ClientKey != "client1" {
+		t.Errorf("Unexpected header: version %d client %q", snapshot.Version, snapshot.ClientKey)
+	}
+	if snapshot.Balance == nil || snapshot.Balance.TotalValue != 10250 {
+		t.Errorf("Unexpected balance: %+v", snapshot.Balance)
+	}
+	if len(snapshot.Accounts) != 1 || len(snapshot.Orders) != 1 || snapshot.Orders[0].OrderID != "1" {
+		t.Errorf("Unexpected accounts %+v / orders %+v", snapshot.Accounts, snapshot.Orders)
+	}
+
+	// Stable model: failed sections serialize as empty lists, not null
+	encoded, _ := json.Marshal(snapshot)
+	var decoded map[string]interface{}
+	json.Unmarshal(encoded, &decoded)
+	if positions, ok := decoded["positions"].([]interface{}); !ok || len(positions) != 0 {
+		t.Errorf("Expected positions: [], got %s", encoded)
+	}
+}
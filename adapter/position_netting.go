@@ -0,0 +1,115 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Position netting modes of a Saxo client (SaxoClientDetails.PositionNettingMode)
+const (
+	// PositionNettingEndOfDay keeps every fill as its own position until end of day:
+	// an opposite order opens a new position unless it references the one to close
+	PositionNettingEndOfDay = "EndOfDay"
+	// PositionNettingIntraday nets opposite fills immediately
+	PositionNettingIntraday = "Intraday"
+)
+
+// positionNettingMode returns the client's netting mode (see GetClientDetails)
+func (sbc *SaxoBrokerClient) positionNettingMode(ctx context.Context) (string, error) {
+	details, err := sbc.GetClientDetails(ctx)
+	if err != nil {
+		return "", err
+	}
+	return details.PositionNettingMode, nil
+}
+
+// GetClientDetails returns the client record including its position netting mode and profile
+// Endpoint: GET /port/v1/clients/me (/clients/details under WithClientKey), cached for CacheTTLs.ClientInfo - the netting profile is
+// changed in SaxoTraderGO, not per session
+func (sbc *SaxoBrokerClient) GetClientDetails(ctx context.Context) (*SaxoClientDetails, error) {
+	return cachedFetch(ctx, sbc.responseCache, CacheClientDetails, func() (*SaxoClientDetails, error) {
+		return sbc.fetchClientDetails(ctx)
+	})
+}
+
+func (sbc *SaxoBrokerClient) fetchClientDetails(ctx context.Context) (*SaxoClientDetails, error) {
+	if sbc.clientKey != "" {
+		return sbc.GetClientDetailsFor(ctx, sbc.clientKey)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", sbc.baseURL+"/port/v1/clients/me", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := sbc.doRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client details: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, sbc.handleErrorResponse(resp)
+	}
+
+	var details SaxoClientDetails
+	if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
+		return nil, fmt.Errorf("failed to decode client details: %w", err)
+	}
+
+	sbc.logger.Info("Retrieved client details",
+		"function", "GetClientDetails",
+		"client_key", details.ClientKey,
+		"netting_mode", details.PositionNettingMode,
+		"netting_profile", details.PositionNettingProfile)
+	return &details, nil
+}
+
+// closeOrderFor builds the order closing req for the client's netting mode
+// EndOfDay netting with a PositionID closes exactly that position; otherwise an opposite
+// market order that is not force-opened nets against the existing position
+func (sbc *SaxoBrokerClient) closeOrderFor(ctx context.Context, req ClosePositionRequest) SaxoOrderRequest {
+	oppositeSide := "Sell"
+	if req.BuySell == "Sell" {
+		oppositeSide = "Buy"
+	}
+
+	forceOpen := false
+	closeOrder := SaxoOrderRequest{
+		AccountKey:  req.AccountKey,
+		Uic:         req.Uic,
+		AssetType:   req.AssetType,
+		BuySell:     oppositeSide,
+		Amount:      req.Amount,
+		OrderType:   "Market",
+		ManualOrder: sbc.manualOrder(req.ManualOrder),
+		IsForceOpen: &forceOpen,
+	}
+	closeOrder.OrderDuration.DurationType = "DayOrder"
+	if _, ok := assetTypeHandlerFor(req.AssetType); ok {
+		closeOrder.AmountType = AmountTypeQuantity // Positions are held in units
+	}
+
+	mode, err := sbc.positionNettingMode(ctx)
+	if err != nil {
+		sbc.logger.Warn("Could not detect position netting mode, closing with an opposite order",
+			"function", "ClosePosition",
+			"error", err)
+		return closeOrder
+	}
+
+	switch {
+	case mode == PositionNettingEndOfDay && req.PositionID != "":
+		closeOrder.PositionID = req.PositionID
+		sbc.logger.Debug("Closing position by PositionId (end-of-day netting)",
+			"function", "ClosePosition",
+			"position_id", req.PositionID)
+	case mode == PositionNettingEndOfDay:
+		// Saxo has no order field for net positions - the opposite order stays open until end of day
+		sbc.logger.Warn("End-of-day netting without PositionID, the close only nets at end of day",
+			"function", "ClosePosition",
+			"net_position_id", req.NetPositionID)
+	}
+	return closeOrder
+}
@@ -0,0 +1,71 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestSaxoBrokerClient_ClosePositionByNettingMode(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+	ctx := context.Background()
+	req := ClosePositionRequest{
+		PositionID: "pos1", NetPositionID: "21__FxSpot", AccountKey: "acc1", Uic: 21, AssetType: "FxSpot", Amount: 1000, BuySell: "Buy",
+	}
+
+	lastOrder := func() SaxoOrderRequest {
+		t.Helper()
+		requests := mockServer.RequestsTo("POST", "/trade/v2/orders")
+		if len(requests) == 0 {
+			t.Fatal("No close order sent")
+		}
+		var order SaxoOrderRequest
+		if err := json.Unmarshal([]byte(requests[len(requests)-1].Body), &order); err != nil {
+			t.Fatalf("Invalid close order body: %v", err)
+		}
+		return order
+	}
+
+	// End-of-day netting closes the referenced position
+	mockServer.SetResponse("GET", "/port/v1/clients/me", http.StatusOK, SaxoClientDetails{ClientKey: "client1", PositionNettingMode: PositionNettingEndOfDay})
+	if _, err := client.ClosePosition(ctx, req); err != nil {
+		t.Fatalf("ClosePosition failed: %v", err)
+	}
+	order := lastOrder()
+	if order.PositionID != "pos1" || order.BuySell != "Sell" || order.IsForceOpen == nil || *order.IsForceOpen {
+		t.Errorf("Expected a PositionId close, got %+v", order)
+	}
+
+	// Intraday netting nets an opposite order, the mode is cached
+	mockServer.SetResponse("GET", "/port/v1/clients/me", http.StatusOK, SaxoClientDetails{ClientKey: "client1", PositionNettingMode: PositionNettingIntraday})
+	client.InvalidateCache(CacheClientDetails)
+	if _, err := client.ClosePosition(ctx, req); err != nil {
+		t.Fatalf("ClosePosition failed: %v", err)
+	}
+	if _, err := client.ClosePosition(ctx, req); err != nil {
+		t.Fatalf("ClosePosition failed: %v", err)
+	}
+	order = lastOrder()
+	if order.PositionID != "" || order.IsForceOpen == nil || *order.IsForceOpen {
+		t.Errorf("Expected a netting opposite order, got %+v", order)
+	}
+	mockServer.AssertRequested(t, "GET", "/port/v1/clients/me", 2)
+
+	// Unknown mode falls back to the opposite order
+	mockServer.SetResponse("GET", "/port/v1/clients/me", http.StatusInternalServerError, SaxoErrorResponse{ErrorCode: "InternalError"})
+	client.InvalidateCache(CacheClientDetails)
+	if _, err := client.ClosePosition(ctx, req); err != nil {
+		t.Fatalf("ClosePosition should fall back when the mode is unknown: %v", err)
+	}
+	if order := lastOrder(); order.PositionID != "" {
+		t.Errorf("Fallback should not reference the position, got %+v", order)
+	}
+}
@@ -0,0 +1,211 @@
+package saxo
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Saxo display formats (InstrumentDetail.Format)
+// For Fractions and ModernFractions, Decimals is the power of two of the denominator (5 = 32nds)
+const (
+	PriceFormatNormal          = "Normal"
+	PriceFormatFractions       = "Fractions"       // "110 16/32"
+	PriceFormatModernFractions = "ModernFractions" // "110'16.5" - US treasury futures in 32nds
+)
+
+// PriceDisplay holds quote prices as Saxo displays them (see PriceFormatter.FormatPriceUpdate)
+type PriceDisplay struct {
+	Bid string
+	Ask string
+	Mid string
+}
+
+// OHLCDisplay holds bar prices as Saxo displays them (see PriceFormatter.FormatHistorical)
+type OHLCDisplay struct {
+	Open  string
+	High  string
+	Low   string
+	Close string
+}
+
+// PriceFormatter converts between display price, decimal price and monetary value for one instrument
+// Saxo always sends decimal prices; display strings are only needed for UIs and logs, monetary
+// values for P/L and sizing
+type PriceFormatter struct {
+	format            string
+	decimals          int
+	numeratorDecimals int
+	tickSize          float64
+	contractFactor    float64
+}
+
+// NewPriceFormatter creates a formatter from GetInstrumentDetails output
+// An empty Format is treated as Normal; PriceToContractFactor 0 is treated as 1
+func NewPriceFormatter(detail InstrumentDetail) *PriceFormatter {
+	pf := &PriceFormatter{
+		format:            detail.Format,
+		decimals:          detail.Decimals,
+		numeratorDecimals: detail.NumeratorDecimals,
+		tickSize:          detail.TickSize,
+		contractFactor:    detail.PriceToContractFactor,
+	}
+	if pf.format == "" {
+		pf.format = PriceFormatNormal
+	}
+	if pf.contractFactor == 0 {
+		pf.contractFactor = 1
+	}
+	return pf
+}
+
+// IsFractional reports whether prices are displayed as fractions
+func (pf *PriceFormatter) IsFractional() bool {
+	return pf.format == PriceFormatFractions || pf.format == PriceFormatModernFractions
+}
+
+// denominator is 2^Decimals for fractional formats
+func (pf *PriceFormatter) denominator() float64 {
+	return math.Pow(2, float64(pf.decimals))
+}
+
+// Round snaps price to the tick size and removes float noise
+func (pf *PriceFormatter) Round(price float64) float64 {
+	if pf.tickSize > 0 {
+		price = RoundTickSize(price, pf.tickSize)
+	}
+	if pf.IsFractional() {
+		// Enough decimals for the smallest numerator step (1/2^Decimals / 10^NumeratorDecimals)
+		return SetDecimals(price, pf.decimals+pf.numeratorDecimals, false, 0)
+	}
+	return SetDecimals(price, pf.decimals, false, 0)
+}
+
+// Format returns the display string of a decimal price
+func (pf *PriceFormatter) Format(price float64) string {
+	if !pf.IsFractional() {
+		return strconv.FormatFloat(price, 'f', pf.decimals, 64)
+	}
+
+	sign := ""
+	if price < 0 {
+		sign = "-"
+		price = -price
+	}
+	denominator := pf.denominator()
+	whole := math.Floor(price)
+	shift := math.Pow(10, float64(pf.numeratorDecimals))
+	numerator := math.Round((price-whole)*denominator*shift) / shift
+	if numerator >= denominator {
+		// Rounding carried into the next whole number
+		whole++
+		numerator -= denominator
+	}
+	numeratorText := strconv.FormatFloat(numerator, 'f', pf.numeratorDecimals, 64)
+
+	if pf.format == PriceFormatFractions {
+		return fmt.Sprintf("%s%.0f %s/%.0f", sign, whole, numeratorText, denominator)
+	}
+	// ModernFractions pads the numerator to the width of the denominator: 110'05.5 for 32nds
+	width := len(strconv.Itoa(int(denominator) - 1))
+	if pad := width - len(strings.SplitN(numeratorText, ".", 2)[0]); pad > 0 {
+		numeratorText = strings.Repeat("0", pad) + numeratorText
+	}
+	return fmt.Sprintf("%s%.0f'%s", sign, whole, numeratorText)
+}
+
+// Parse converts a display string back to a decimal price
+// Plain decimal strings are accepted for every format
+func (pf *PriceFormatter) Parse(display string) (float64, error) {
+	display = strings.TrimSpace(display)
+	if display == "" {
+		return 0, fmt.Errorf("empty price")
+	}
+
+	sign := 1.0
+	if strings.HasPrefix(display, "-") {
+		sign = -1
+		display = display[1:]
+	}
+
+	var wholeText, numeratorText string
+	denominator := pf.denominator()
+	switch {
+	case strings.Contains(display, "'"):
+		wholeText, numeratorText, _ = strings.Cut(display, "'")
+	case strings.Contains(display, "/"):
+		var fraction string
+		wholeText, fraction, _ = strings.Cut(display, " ")
+		if !strings.Contains(fraction, "/") {
+			// "16/32" without whole part
+			wholeText, fraction = "0", wholeText
+		}
+		var denominatorText string
+		numeratorText, denominatorText, _ = strings.Cut(fraction, "/")
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(denominatorText), 64)
+		if err != nil || parsed == 0 {
+			return 0, fmt.Errorf("invalid fraction denominator in %q", display)
+		}
+		denominator = parsed
+	default:
+		price, err := strconv.ParseFloat(display, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid price %q: %w", display, err)
+		}
+		return sign * price, nil
+	}
+
+	whole, err := strconv.ParseFloat(strings.TrimSpace(wholeText), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid whole part in %q: %w", display, err)
+	}
+	numerator, err := strconv.ParseFloat(strings.TrimSpace(numeratorText), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid numerator in %q: %w", display, err)
+	}
+	if numerator < 0 || numerator >= denominator {
+		return 0, fmt.Errorf("numerator out of range in %q", display)
+	}
+	return sign * (whole + numerator/denominator), nil
+}
+
+// Value returns the monetary value of amount contracts (or units) at price, in instrument currency
+// Following Saxo: value = price x PriceToContractFactor x amount
+func (pf *PriceFormatter) Value(price, amount float64) float64 {
+	return price * pf.contractFactor * amount
+}
+
+// PriceForValue is the inverse of Value: the price at which amount is worth value
+func (pf *PriceFormatter) PriceForValue(value, amount float64) float64 {
+	if amount == 0 {
+		return 0
+	}
+	return value / (pf.contractFactor * amount)
+}
+
+// FormatPriceUpdate returns a copy of update with Display filled in
+func (pf *PriceFormatter) FormatPriceUpdate(update PriceUpdate) PriceUpdate {
+	update.Display = &PriceDisplay{
+		Bid: pf.Format(update.Bid),
+		Ask: pf.Format(update.Ask),
+		Mid: pf.Format(update.Mid),
+	}
+	return update
+}
+
+// FormatHistorical returns a copy of points with Display filled in
+// The input is not modified - GetHistoricalData returns cached slices
+func (pf *PriceFormatter) FormatHistorical(points []HistoricalDataPoint) []HistoricalDataPoint {
+	result := make([]HistoricalDataPoint, len(points))
+	for i, point := range points {
+		point.Display = &OHLCDisplay{
+			Open:  pf.Format(point.Open),
+			High:  pf.Format(point.High),
+			Low:   pf.Format(point.Low),
+			Close: pf.Format(point.Close),
+		}
+		result[i] = point
+	}
+	return result
+}
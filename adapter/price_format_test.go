@@ -0,0 +1,80 @@
+package saxo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPriceFormatter_ModernFractions(t *testing.T) {
+	// 10Y T-Note future: 32nds with half-32nd numerator, $1000 per point
+	pf := NewPriceFormatter(InstrumentDetail{
+		Format:                PriceFormatModernFractions,
+		Decimals:              5,
+		NumeratorDecimals:     1,
+		TickSize:              0.015625,
+		PriceToContractFactor: 1000,
+	})
+
+	cases := map[float64]string{
+		110.515625: "110'16.5",
+		110.15625:  "110'05.0",
+		-0.5:       "-0'16.0",
+	}
+	for price, display := range cases {
+		if got := pf.Format(price); got != display {
+			t.Errorf("Format(%v) = %q, want %q", price, got, display)
+		}
+		parsed, err := pf.Parse(display)
+		if err != nil || math.Abs(parsed-price) > 1e-12 {
+			t.Errorf("Parse(%q) = %v (%v), want %v", display, parsed, err, price)
+		}
+	}
+
+	// Rounding a numerator up to the denominator carries into the whole number
+	if got := pf.Format(110.999); got != "111'00.0" {
+		t.Errorf("Expected carry, got %q", got)
+	}
+	if got := pf.Round(110.52); got != 110.515625 {
+		t.Errorf("Round = %v, want 110.515625", got)
+	}
+	if _, err := pf.Parse("110'32"); err == nil {
+		t.Error("Expected numerator out of range error")
+	}
+	if got := pf.Value(110.5, 2); got != 221000 {
+		t.Errorf("Value = %v, want 221000", got)
+	}
+	if got := pf.PriceForValue(221000, 2); got != 110.5 {
+		t.Errorf("PriceForValue = %v, want 110.5", got)
+	}
+}
+
+func TestPriceFormatter_NormalAndFractions(t *testing.T) {
+	normal := NewPriceFormatter(InstrumentDetail{Decimals: 5, TickSize: 0.00005})
+	if got := normal.Format(1.08512); got != "1.08512" {
+		t.Errorf("Format = %q", got)
+	}
+	if got := normal.Round(1.085123); got != 1.0851 {
+		t.Errorf("Round = %v, want 1.0851", got)
+	}
+	if got := normal.Value(1.1, 10000); math.Abs(got-11000) > 1e-9 {
+		t.Errorf("Expected factor 1 by default, got %v", got)
+	}
+
+	fractions := NewPriceFormatter(InstrumentDetail{Format: PriceFormatFractions, Decimals: 3})
+	if got := fractions.Format(98.375); got != "98 3/8" {
+		t.Errorf("Format = %q, want 98 3/8", got)
+	}
+	if got, err := fractions.Parse("98 3/8"); err != nil || got != 98.375 {
+		t.Errorf("Parse = %v (%v)", got, err)
+	}
+
+	update := fractions.FormatPriceUpdate(PriceUpdate{Uic: 1, Bid: 98.25, Ask: 98.5, Mid: 98.375})
+	if update.Display == nil || update.Display.Bid != "98 2/8" || update.Display.Mid != "98 3/8" {
+		t.Errorf("Unexpected display %+v", update.Display)
+	}
+	points := []HistoricalDataPoint{{Open: 98, High: 99, Low: 97.5, Close: 98.125}}
+	formatted := fractions.FormatHistorical(points)
+	if points[0].Display != nil || formatted[0].Display.Close != "98 1/8" {
+		t.Errorf("Expected formatted copy, got %+v", formatted[0].Display)
+	}
+}
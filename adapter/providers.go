@@ -0,0 +1,129 @@
+package saxo
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// DefaultProvider is the provider key used when PROVIDER is not set
+const DefaultProvider = "saxo"
+
+// ProviderEndpoints are the OAuth and API URLs of one provider environment
+type ProviderEndpoints struct {
+	AuthURL      string
+	TokenURL     string
+	BaseURL      string // REST gateway, e.g. https://gateway.saxobank.com/sim/openapi
+	WebSocketURL string // Streaming base including /streaming/ws
+}
+
+// Provider describes an OAuth provider the auth layer can log in to
+// Saxo white labels share the OpenAPI, so a second provider only differs in endpoints and credentials
+type Provider struct {
+	Name         string   // Key used in token filenames and /oauth/{provider}/callback
+	EnvPrefix    string   // Reads {EnvPrefix}_CLIENT_ID, {EnvPrefix}_CLIENT_SECRET and {EnvPrefix}_ENVIRONMENT
+	Scopes       []string // OAuth scopes (default "openapi")
+	Environments map[SaxoEnvironment]ProviderEndpoints
+}
+
+// saxoProvider is the built-in Saxo Bank provider
+var saxoProvider = Provider{
+	Name:      DefaultProvider,
+	EnvPrefix: "SAXO",
+	Scopes:    []string{"openapi"},
+	Environments: map[SaxoEnvironment]ProviderEndpoints{
+		SaxoSIM: {
+			AuthURL:      "https://sim.logonvalidation.net/authorize",
+			TokenURL:     "https://sim.logonvalidation.net/token",
+			BaseURL:      "https://gateway.saxobank.com/sim/openapi",
+			WebSocketURL: "https://sim-streaming.saxobank.com/sim/oapi/streaming/ws",
+		},
+		SaxoLive: {
+			AuthURL:      "https://live.logonvalidation.net/authorize",
+			TokenURL:     "https://live.logonvalidation.net/token",
+			BaseURL:      "https://gateway.saxobank.com/openapi",
+			WebSocketURL: "https://live-streaming.saxobank.com/oapi/streaming/ws",
+		},
+	},
+}
+
+var (
+	providers   = map[string]Provider{DefaultProvider: saxoProvider}
+	providersMu sync.RWMutex
+)
+
+// RegisterProvider makes a provider selectable via PROVIDER / LoadProviderConfig
+// Registering an existing name replaces it
+func RegisterProvider(provider Provider) error {
+	if provider.Name == "" || provider.EnvPrefix == "" {
+		return fmt.Errorf("provider name and env prefix are required")
+	}
+	if len(provider.Environments) == 0 {
+		return fmt.Errorf("provider %s has no environments", provider.Name)
+	}
+	for env, endpoints := range provider.Environments {
+		if endpoints.AuthURL == "" || endpoints.TokenURL == "" || endpoints.BaseURL == "" {
+			return fmt.Errorf("provider %s environment %s: auth, token and base URL are required", provider.Name, env)
+		}
+	}
+
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[provider.Name] = provider
+	return nil
+}
+
+// LookupProvider returns the registered provider called name
+func LookupProvider(name string) (Provider, bool) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	provider, ok := providers[name]
+	return provider, ok
+}
+
+// RegisteredProviders returns the names of all registered providers, sorted
+func RegisteredProviders() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SelectedProvider returns the provider chosen by the PROVIDER environment variable (default "saxo")
+func SelectedProvider() string {
+	if provider := os.Getenv("PROVIDER"); provider != "" {
+		return provider
+	}
+	return DefaultProvider
+}
+
+// LoadProviderConfig loads credentials and environment for a registered provider from environment variables
+// Returns: oauthConfigs (keyed by provider name), baseURL, websocketURL, environment, error
+func LoadProviderConfig(logger *slog.Logger, name string) (map[string]*oauth2.Config, string, string, SaxoEnvironment, error) {
+	config, err := providerConfigFromEnv(name).withDefaults()
+	if err != nil {
+		return nil, "", "", "", err
+	}
+	config.logEnvironment(logger)
+	return config.oauthConfigs(), config.BaseURL, config.WebSocketURL, config.Environment, nil
+}
+
+// WithProvider selects the providerConfigs key the auth client logs in with (auth client only)
+func WithProvider(name string) Option {
+	return func(o *ClientOptions) {
+		o.Provider = name
+	}
+}
+
+// Provider returns the name of the provider this client authenticates against
+func (sac *SaxoAuthClient) Provider() string {
+	return sac.provider
+}
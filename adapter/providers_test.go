@@ -0,0 +1,145 @@
+package saxo
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// testWhiteLabel is a second OpenAPI-compatible provider used to check nothing hardcodes "saxo"
+var testWhiteLabel = Provider{
+	Name:      "whitelabel",
+	EnvPrefix: "WHITELABEL",
+	Environments: map[SaxoEnvironment]ProviderEndpoints{
+		SaxoSIM: {
+			AuthURL:      "https://sim.whitelabel.example/authorize",
+			TokenURL:     "https://sim.whitelabel.example/token",
+			BaseURL:      "https://gateway.whitelabel.example/sim/openapi",
+			WebSocketURL: "https://streaming.whitelabel.example/sim/oapi/streaming/ws",
+		},
+	},
+}
+
+// registerTestProvider registers provider for the test and removes it from the global registry afterwards
+func registerTestProvider(t *testing.T, provider Provider) {
+	t.Helper()
+	providersMu.RLock()
+	previous, existed := providers[provider.Name]
+	providersMu.RUnlock()
+	if err := RegisterProvider(provider); err != nil {
+		t.Fatalf("RegisterProvider failed: %v", err)
+	}
+	t.Cleanup(func() {
+		providersMu.Lock()
+		defer providersMu.Unlock()
+		if existed {
+			providers[provider.Name] = previous
+		} else {
+			delete(providers, provider.Name)
+		}
+	})
+}
+
+func TestRegisterProvider_Validation(t *testing.T) {
+	if err := RegisterProvider(Provider{Name: "broken", EnvPrefix: "BROKEN"}); err == nil {
+		t.Error("Expected error for provider without environments")
+	}
+	if err := RegisterProvider(Provider{EnvPrefix: "NONAME", Environments: testWhiteLabel.Environments}); err == nil {
+		t.Error("Expected error for provider without name")
+	}
+	if _, ok := LookupProvider("broken"); ok {
+		t.Error("Invalid provider was registered")
+	}
+	if _, ok := LookupProvider(DefaultProvider); !ok {
+		t.Errorf("Built-in %s provider not registered", DefaultProvider)
+	}
+}
+
+func TestLoadProviderConfig_SecondProvider(t *testing.T) {
+	registerTestProvider(t, testWhiteLabel)
+	t.Setenv("WHITELABEL_CLIENT_ID", "wl_id")
+	t.Setenv("WHITELABEL_CLIENT_SECRET", "wl_secret")
+	t.Setenv("PROVIDER", "whitelabel")
+
+	if got := SelectedProvider(); got != "whitelabel" {
+		t.Fatalf("SelectedProvider() = %s, want whitelabel", got)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	configs, baseURL, websocketURL, env, err := LoadProviderConfig(logger, SelectedProvider())
+	if err != nil {
+		t.Fatalf("LoadProviderConfig failed: %v", err)
+	}
+	config, ok := configs["whitelabel"]
+	if !ok || config.ClientID != "wl_id" || config.Endpoint.TokenURL != "https://sim.whitelabel.example/token" {
+		t.Errorf("Unexpected OAuth configs: %+v", configs)
+	}
+	if len(config.Scopes) != 1 || config.Scopes[0] != "openapi" {
+		t.Errorf("Scopes = %v, want default [openapi]", config.Scopes)
+	}
+	if baseURL != "https://gateway.whitelabel.example/sim/openapi" || websocketURL == "" || env != SaxoSIM {
+		t.Errorf("Unexpected endpoints: %s %s %s", baseURL, websocketURL, env)
+	}
+
+	// No live endpoints registered for the white label
+	t.Setenv("WHITELABEL_ENVIRONMENT", "live")
+	if _, _, _, _, err := LoadProviderConfig(logger, "whitelabel"); err == nil {
+		t.Error("Expected error for unsupported environment")
+	}
+
+	if _, _, _, _, err := LoadProviderConfig(logger, "unknown"); err == nil || !strings.Contains(err.Error(), "unknown provider") {
+		t.Errorf("Expected unknown provider error, got %v", err)
+	}
+}
+
+func TestSaxoAuthClient_NonDefaultProvider(t *testing.T) {
+	var tokenCalls, authorizeCalls int32
+	server := newTestAuthServer(t, &tokenCalls, &authorizeCalls)
+	defer server.Close()
+
+	configs := map[string]*oauth2.Config{
+		"whitelabel": {
+			ClientID: "test",
+			Endpoint: oauth2.Endpoint{TokenURL: server.URL + "/token"},
+		},
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	storage := &FileTokenStorage{basePath: t.TempDir()}
+
+	// Provider inferred from the single configured key
+	sac := NewSaxoAuthClient(configs, server.URL, server.URL, storage, SaxoSIM, logger)
+	if sac.Provider() != "whitelabel" {
+		t.Fatalf("Provider() = %s, want whitelabel", sac.Provider())
+	}
+	sac.currentToken = TokenInfo{
+		Provider:      "whitelabel",
+		AccessToken:   "expired_token",
+		RefreshToken:  "refresh_token",
+		Expiry:        time.Now().Add(-time.Minute),
+		RefreshExpiry: time.Now().Add(time.Hour),
+	}
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, server.Client())
+	if err := sac.RefreshToken(ctx); err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+
+	saved, err := storage.LoadToken("whitelabel_sim" + tokenSuffix)
+	if err != nil {
+		t.Fatalf("Refreshed token not stored under provider filename: %v", err)
+	}
+	if saved.Provider != "whitelabel" || saved.AccessToken != "refreshed_token_1" {
+		t.Errorf("Unexpected stored token: provider=%s access=%s", saved.Provider, saved.AccessToken)
+	}
+
+	// Explicit WithProvider wins over inference
+	explicit := NewSaxoAuthClient(map[string]*oauth2.Config{"a": {}, "b": {}}, server.URL, server.URL, storage, SaxoSIM, logger, WithProvider("b"))
+	if explicit.Provider() != "b" {
+		t.Errorf("Provider() = %s, want b", explicit.Provider())
+	}
+}
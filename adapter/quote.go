@@ -0,0 +1,192 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// quoteFieldGroups are the /trade/v1/infoprices field groups GetQuote reads
+const quoteFieldGroups = "Quote,PriceInfo,PriceInfoDetails,InstrumentPriceDetails"
+
+// Quote is a snapshot of one instrument's price and market state (GetQuote)
+type Quote struct {
+	Ticker    string
+	Uic       int
+	AssetType string
+
+	Bid          float64
+	Ask          float64
+	Mid          float64
+	Spread       float64
+	BidSize      float64
+	AskSize      float64
+	PriceTypeBid string // "Tradable", "Indicative", "OldIndicative" or "NoMarket"
+	PriceTypeAsk string
+
+	LastUpdated time.Time
+	Delay       time.Duration // 0 = real-time, else the delay of a delayed-data session
+
+	// Today's session so far
+	Open       float64
+	High       float64
+	Low        float64
+	LastTraded float64 // Traded asset types only
+	Volume     float64 // Traded asset types only
+	NetChange  float64
+	LastClose  float64 // Close of the previous session
+
+	MarketState string    // Saxo's Quote.MarketState, e.g. "Open", "Closed", "PreMarket"
+	MarketOpen  bool      // Saxo's IsMarketOpen, or the trading schedule when Saxo leaves it out
+	NextOpen    time.Time // From the trading schedule, zero when unknown
+	NextClose   time.Time // From the trading schedule, zero when unknown
+}
+
+// saxoInfoPriceSnapshot is the /trade/v1/infoprices response for quoteFieldGroups
+type saxoInfoPriceSnapshot struct {
+	Uic         int    `json:"Uic"`
+	AssetType   string `json:"AssetType"`
+	LastUpdated string `json:"LastUpdated"`
+	Quote       struct {
+		Bid              float64 `json:"Bid"`
+		Ask              float64 `json:"Ask"`
+		Mid              float64 `json:"Mid"`
+		DelayedByMinutes int     `json:"DelayedByMinutes"`
+		MarketState      string  `json:"MarketState"`
+		PriceTypeBid     string  `json:"PriceTypeBid"`
+		PriceTypeAsk     string  `json:"PriceTypeAsk"`
+	} `json:"Quote"`
+	PriceInfo struct {
+		High      float64 `json:"High"`
+		Low       float64 `json:"Low"`
+		NetChange float64 `json:"NetChange"`
+	} `json:"PriceInfo"`
+	PriceInfoDetails struct {
+		AskSize    float64 `json:"AskSize"`
+		BidSize    float64 `json:"BidSize"`
+		LastClose  float64 `json:"LastClose"`
+		LastTraded float64 `json:"LastTraded"`
+		Open       float64 `json:"Open"`
+		Volume     float64 `json:"Volume"`
+	} `json:"PriceInfoDetails"`
+	InstrumentPriceDetails struct {
+		IsMarketOpen *bool `json:"IsMarketOpen"`
+	} `json:"InstrumentPriceDetails"`
+}
+
+// GetQuote returns bid/ask, today's session prices and market state of instrument from one
+// infoprices request, with the next open and close from the trading schedule (cached per day).
+// A failed schedule lookup leaves NextOpen/NextClose zero instead of failing the quote
+func (sbc *SaxoBrokerClient) GetQuote(ctx context.Context, instrument Instrument) (*Quote, error) {
+	instrument = sbc.enrichFromStore(instrument)
+	quote, marketOpenKnown, err := sbc.fetchInfoPrice(ctx, instrument)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduleInstrument := instrument
+	scheduleInstrument.Identifier = quote.Uic
+	schedule, err := sbc.cachedTradingSchedule(ctx, scheduleInstrument)
+	if err != nil {
+		sbc.logger.Warn("Trading schedule unavailable for quote",
+			"function", "GetQuote",
+			"ticker", instrument.Ticker,
+			"error", err)
+		return quote, nil
+	}
+	now := sbc.clock.Now()
+	if !marketOpenKnown {
+		quote.MarketOpen = schedule.IsOpenAt(now)
+	}
+	if open, ok := schedule.NextOpen(now); ok {
+		quote.NextOpen = open
+	}
+	if close, ok := schedule.NextClose(now); ok {
+		quote.NextClose = close
+	}
+	return quote, nil
+}
+
+// fetchInfoPrice requests the infoprices snapshot of an enriched instrument
+// marketOpenKnown is false when Saxo left IsMarketOpen out (MarketOpen then follows MarketState)
+func (sbc *SaxoBrokerClient) fetchInfoPrice(ctx context.Context, instrument Instrument) (quote *Quote, marketOpenKnown bool, err error) {
+	uic := instrument.Uic
+	if uic == 0 {
+		uic = instrument.Identifier
+	}
+	if uic == 0 {
+		return nil, false, fmt.Errorf("instrument %s is not enriched - Identifier (UIC) is missing. Run instrument enrichment first", instrument.Ticker)
+	}
+	if instrument.AssetType == "" {
+		return nil, false, fmt.Errorf("instrument %s is missing AssetType. This should be loaded from futures.json", instrument.Ticker)
+	}
+	if !sbc.authClient.IsAuthenticated() {
+		return nil, false, sbc.notAuthenticated()
+	}
+
+	query := url.Values{}
+	query.Set("Uic", strconv.Itoa(uic))
+	query.Set("AssetType", instrument.AssetType)
+	query.Set("FieldGroups", quoteFieldGroups)
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", sbc.baseURL+"/trade/v1/infoprices?"+query.Encode(), nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	resp, err := sbc.doRequest(ctx, httpReq)
+	if err != nil {
+		return nil, false, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, sbc.handleErrorResponse(resp)
+	}
+
+	var info saxoInfoPriceSnapshot
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, false, fmt.Errorf("failed to decode infoprice response: %w", err)
+	}
+
+	quote = &Quote{
+		Ticker:       instrument.Ticker,
+		Uic:          uic,
+		AssetType:    instrument.AssetType,
+		Bid:          info.Quote.Bid,
+		Ask:          info.Quote.Ask,
+		Mid:          info.Quote.Mid,
+		BidSize:      info.PriceInfoDetails.BidSize,
+		AskSize:      info.PriceInfoDetails.AskSize,
+		PriceTypeBid: info.Quote.PriceTypeBid,
+		PriceTypeAsk: info.Quote.PriceTypeAsk,
+		Delay:        time.Duration(info.Quote.DelayedByMinutes) * time.Minute,
+		Open:         info.PriceInfoDetails.Open,
+		High:         info.PriceInfo.High,
+		Low:          info.PriceInfo.Low,
+		LastTraded:   info.PriceInfoDetails.LastTraded,
+		Volume:       info.PriceInfoDetails.Volume,
+		NetChange:    info.PriceInfo.NetChange,
+		LastClose:    info.PriceInfoDetails.LastClose,
+		MarketState:  info.Quote.MarketState,
+		MarketOpen:   info.Quote.MarketState == "Open",
+	}
+	if quote.Mid == 0 && quote.Bid != 0 && quote.Ask != 0 {
+		quote.Mid = (quote.Bid + quote.Ask) / 2
+	}
+	if quote.Bid != 0 && quote.Ask != 0 {
+		quote.Spread = quote.Ask - quote.Bid
+	}
+	if isOpen := info.InstrumentPriceDetails.IsMarketOpen; isOpen != nil {
+		quote.MarketOpen, marketOpenKnown = *isOpen, true
+	}
+	if info.LastUpdated != "" {
+		if updated, err := time.Parse(time.RFC3339, info.LastUpdated); err == nil {
+			quote.LastUpdated = updated
+		}
+	}
+	return quote, marketOpenKnown, nil
+}
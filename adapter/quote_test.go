@@ -0,0 +1,105 @@
+package saxo
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestSaxoBrokerClient_GetQuote(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	now := time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC)
+	mockServer.SetResponse("GET", "/trade/v1/infoprices", http.StatusOK, map[string]interface{}{
+		"Uic": 4916, "AssetType": "ContractFutures", "LastUpdated": "2026-10-16T13:59:58.123Z",
+		"Quote":            map[string]interface{}{"Bid": 5000.25, "Ask": 5000.5, "Mid": 5000.375, "DelayedByMinutes": 15, "MarketState": "Open", "PriceTypeBid": "Tradable", "PriceTypeAsk": "Tradable"},
+		"PriceInfo":        map[string]interface{}{"High": 5010, "Low": 4990, "NetChange": 12.5},
+		"PriceInfoDetails": map[string]interface{}{"Open": 4995, "LastClose": 4988, "LastTraded": 5000.25, "Volume": 125000, "BidSize": 12, "AskSize": 7},
+	})
+	sessionClose := time.Date(2026, 10, 16, 21, 0, 0, 0, time.UTC)
+	mockServer.SetTradingScheduleResponse(4916, AssetTypeContractFutures, SaxoTradingSchedule{Phases: []SaxoTradingPhase{
+		{StartTime: now.Add(-6 * time.Hour), EndTime: sessionClose, State: "AutomatedTrading"},
+		{StartTime: sessionClose, EndTime: sessionClose.Add(time.Hour), State: "Closed"},
+		{StartTime: sessionClose.Add(time.Hour), EndTime: sessionClose.Add(24 * time.Hour), State: "AutomatedTrading"},
+	}})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), slog.New(slog.NewTextHandler(io.Discard, nil)),
+		WithClock(mocktesting.NewFakeClock(now)))
+	es := Instrument{Ticker: "ES", Uic: 4916, AssetType: AssetTypeContractFutures}
+
+	quote, err := client.GetQuote(context.Background(), es)
+	if err != nil {
+		t.Fatalf("GetQuote failed: %v", err)
+	}
+	if quote.Bid != 5000.25 || quote.Ask != 5000.5 || quote.Spread != 0.25 || quote.BidSize != 12 || quote.PriceTypeAsk != "Tradable" {
+		t.Errorf("Unexpected prices %+v", quote)
+	}
+	if quote.Open != 4995 || quote.High != 5010 || quote.Low != 4990 || quote.LastClose != 4988 || quote.Volume != 125000 || quote.NetChange != 12.5 {
+		t.Errorf("Unexpected session prices %+v", quote)
+	}
+	if quote.Delay != 15*time.Minute || quote.LastUpdated.IsZero() || quote.MarketState != "Open" {
+		t.Errorf("Unexpected delay or state %+v", quote)
+	}
+	if !quote.MarketOpen || !quote.NextClose.Equal(sessionClose) || !quote.NextOpen.Equal(sessionClose.Add(time.Hour)) {
+		t.Errorf("Expected market open until %v, got %+v", sessionClose, quote)
+	}
+
+	requests := mockServer.AssertRequested(t, "GET", "/trade/v1/infoprices", 1)
+	if len(requests) == 1 {
+		query, _ := url.ParseQuery(requests[0].Query)
+		if query.Get("Uic") != "4916" || query.Get("AssetType") != "ContractFutures" || query.Get("FieldGroups") != quoteFieldGroups {
+			t.Errorf("Unexpected infoprices query %s", requests[0].Query)
+		}
+	}
+
+	// The schedule is cached, GetInstrumentPrice reads infoprices only
+	price, err := client.GetInstrumentPrice(context.Background(), es)
+	if err != nil {
+		t.Fatalf("GetInstrumentPrice failed: %v", err)
+	}
+	if price.Bid != 5000.25 || price.Ask != 5000.5 || price.Mid != 5000.375 || price.Timestamp != "2026-10-16T13:59:58Z" {
+		t.Errorf("Unexpected price %+v", price)
+	}
+	mockServer.AssertRequested(t, "GET", "/ref/v1/instruments/tradingschedule/{uic}/{assetType}", 1)
+}
+
+func TestSaxoBrokerClient_GetQuoteNoMarket(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	mockServer.SetResponse("GET", "/trade/v1/infoprices", http.StatusOK, map[string]interface{}{
+		"Uic": 211, "AssetType": "Stock",
+		"Quote":                  map[string]interface{}{"MarketState": "Closed", "PriceTypeBid": "NoMarket", "PriceTypeAsk": "NoMarket"},
+		"PriceInfoDetails":       map[string]interface{}{"LastClose": 180.5},
+		"InstrumentPriceDetails": map[string]interface{}{"IsMarketOpen": false},
+	})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	aapl := Instrument{Ticker: "AAPL", Uic: 211, AssetType: AssetTypeStock}
+
+	// No schedule configured - the quote is still returned
+	quote, err := client.GetQuote(context.Background(), aapl)
+	if err != nil {
+		t.Fatalf("GetQuote failed: %v", err)
+	}
+	if quote.MarketOpen || !quote.NextClose.IsZero() || quote.Spread != 0 || quote.LastClose != 180.5 {
+		t.Errorf("Unexpected quote %+v", quote)
+	}
+
+	price, err := client.GetInstrumentPrice(context.Background(), aapl)
+	if err != nil {
+		t.Fatalf("GetInstrumentPrice failed: %v", err)
+	}
+	if price.Bid != 180.5 || price.Ask != 180.5 || price.Spread != 0 {
+		t.Errorf("Expected the last close without a market, got %+v", price)
+	}
+}
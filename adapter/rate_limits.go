@@ -0,0 +1,123 @@
+package saxo
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is Saxo's last reported state of one rate-limit dimension
+// Saxo sends X-RateLimit-<Dimension>-Limit/-Remaining/-Reset on throttled endpoints, e.g. the
+// AppDay dimension on every call and per-service dimensions on others
+type RateLimit struct {
+	Dimension string // In canonical header case, e.g. "Appday"
+	Limit     int
+	Remaining int
+	Reset     time.Time // When Remaining refills
+}
+
+// rateLimitTracker keeps the latest rate-limit headers and Retry-After of any response
+type rateLimitTracker struct {
+	mu         sync.Mutex
+	limits     map[string]RateLimit
+	retryAfter time.Time // From the last 429, zero = none
+}
+
+func newRateLimitTracker() *rateLimitTracker {
+	return &rateLimitTracker{limits: make(map[string]RateLimit)}
+}
+
+// observe records the rate-limit headers of a response received at now
+func (t *rateLimitTracker) observe(status int, header http.Header, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for name, values := range header {
+		if len(values) == 0 || !strings.HasPrefix(name, "X-Ratelimit-") {
+			continue
+		}
+		dimension, field, ok := strings.Cut(strings.TrimPrefix(name, "X-Ratelimit-"), "-")
+		if !ok {
+			continue
+		}
+		value, err := strconv.Atoi(values[0])
+		if err != nil {
+			continue
+		}
+		limit := t.limits[dimension]
+		limit.Dimension = dimension
+		switch field {
+		case "Limit":
+			limit.Limit = value
+		case "Remaining":
+			limit.Remaining = value
+		case "Reset":
+			limit.Reset = now.Add(time.Duration(value) * time.Second)
+		default:
+			continue
+		}
+		t.limits[dimension] = limit
+	}
+
+	if status == http.StatusTooManyRequests {
+		seconds, err := strconv.Atoi(header.Get("Retry-After"))
+		if err != nil || seconds <= 0 {
+			seconds = 1
+		}
+		t.retryAfter = now.Add(time.Duration(seconds) * time.Second)
+	}
+}
+
+// wait returns how long to hold off before the next request: until the latest reset of an exhausted
+// dimension or the last Retry-After, 0 when requests may go out now
+func (t *rateLimitTracker) wait(now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	until := t.retryAfter
+	for _, limit := range t.limits {
+		if limit.Remaining <= 0 && limit.Reset.After(until) {
+			until = limit.Reset
+		}
+	}
+	if !until.After(now) {
+		return 0
+	}
+	return until.Sub(now)
+}
+
+// snapshot returns the tracked dimensions sorted by name
+func (t *rateLimitTracker) snapshot() []RateLimit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	limits := make([]RateLimit, 0, len(t.limits))
+	for _, limit := range t.limits {
+		limits = append(limits, limit)
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].Dimension < limits[j].Dimension })
+	return limits
+}
+
+// RateLimits returns the rate-limit state Saxo reported on the latest responses, one entry per dimension
+func (sbc *SaxoBrokerClient) RateLimits() []RateLimit {
+	return sbc.rateLimits.snapshot()
+}
+
+// waitRateLimit blocks on the client clock while the tracked rate limits ask to hold off
+func (sbc *SaxoBrokerClient) waitRateLimit(ctx context.Context) error {
+	wait := sbc.rateLimits.wait(sbc.clock.Now())
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-sbc.clock.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,145 @@
+package saxo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// rawRetries is how often DoRaw retries a GET throttled with 429, after the Retry-After pause
+const rawRetries = 2
+
+// RawResponse is the answer of an endpoint called through DoRaw
+type RawResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Data       any // Body decoded as JSON (map[string]any, []any, ...), nil for empty or non-JSON bodies
+}
+
+// Decode unmarshals the body into v, e.g. a struct for the endpoint's response
+func (r *RawResponse) Decode(v any) error {
+	if len(r.Body) == 0 {
+		return fmt.Errorf("empty response body")
+	}
+	return json.Unmarshal(r.Body, v)
+}
+
+// DoRaw calls a Saxo OpenAPI endpoint the client does not wrap, e.g. DoRaw(ctx, "GET", "/ref/v1/exchanges", nil, nil)
+// path is relative to the base URL and must start with "/". body is sent as JSON: []byte and
+// json.RawMessage as they are, other values marshalled, nil for none
+// The request goes through the same OAuth transport, interceptors, request IDs, timeout and rate-limit
+// tracking as the wrapped calls; a GET throttled with 429 is retried after its Retry-After pause
+// Writes to /trade/ endpoints are refused in dry run and, except DELETE (cancels), while the kill switch is active
+// Non-2xx responses return the RawResponse together with the error ("HTTP <status>: <body>")
+func (sbc *SaxoBrokerClient) DoRaw(ctx context.Context, method, path string, query url.Values, body any) (*RawResponse, error) {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") {
+		return nil, fmt.Errorf("raw request path %q must be relative to the base URL and start with /", path)
+	}
+	guardPath, err := rawGuardPath(path)
+	if err != nil {
+		return nil, err
+	}
+	method = strings.ToUpper(method)
+	if invalidatesBalance(method, guardPath) {
+		// Raw writes to the trading endpoints get the same guards as PlaceOrder
+		if sbc.dryRun {
+			return nil, fmt.Errorf("dry run: raw %s %s not sent", method, path)
+		}
+		if method != http.MethodDelete {
+			if err := sbc.killSwitch.check(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !sbc.authClient.IsAuthenticated() {
+		return nil, sbc.notAuthenticated()
+	}
+
+	var payload []byte
+	switch b := body.(type) {
+	case nil:
+	case []byte:
+		payload = b
+	case json.RawMessage:
+		payload = b
+	default:
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+	endpoint := sbc.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := sbc.waitRateLimit(ctx); err != nil {
+			return nil, err
+		}
+		raw, err := sbc.doRawOnce(ctx, method, endpoint, payload)
+		if raw == nil || raw.StatusCode != http.StatusTooManyRequests || method != http.MethodGet || attempt == rawRetries {
+			return raw, err
+		}
+		sbc.logger.Warn("Raw request hit the rate limit, retrying",
+			"function", "DoRaw",
+			"method", method,
+			"path", path,
+			"attempt", attempt+1)
+	}
+}
+
+// rawGuardPath is the path as the server resolves it - unescaped, dot segments removed and
+// lower-cased - so "/Trade/..." or "/ref/../trade/..." cannot slip past the trading guards
+func rawGuardPath(raw string) (string, error) {
+	unescaped, err := url.PathUnescape(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid raw request path %q: %w", raw, err)
+	}
+	return strings.ToLower(path.Clean(unescaped)), nil
+}
+
+// doRawOnce sends one DoRaw request and reads the whole response
+func (sbc *SaxoBrokerClient) doRawOnce(ctx context.Context, method, endpoint string, payload []byte) (*RawResponse, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := sbc.doRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	raw := &RawResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: data}
+	if len(data) > 0 && strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		if err := json.Unmarshal(data, &raw.Data); err != nil && resp.StatusCode < 300 {
+			return raw, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	if resp.StatusCode >= 300 {
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		return raw, sbc.handleErrorResponse(resp)
+	}
+	return raw, nil
+}
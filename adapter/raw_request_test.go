@@ -0,0 +1,104 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestSaxoBrokerClient_DoRaw(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SetResponse("GET", "/ref/v1/exchanges", http.StatusOK, map[string]any{
+		"Data": []map[string]any{{"ExchangeId": "NYSE", "Name": "New York Stock Exchange"}},
+	})
+	mockServer.SetResponse("POST", "/trade/v1/alerts", http.StatusCreated, map[string]string{"AlertId": "a-1"})
+	mockServer.SetResponse("GET", "/ref/v1/unknown", http.StatusNotFound, map[string]string{"ErrorCode": "NotFound"})
+
+	clock := mocktesting.NewFakeClock(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	advanceClock(t, clock)
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), slog.New(slog.NewTextHandler(io.Discard, nil)), WithClock(clock))
+	ctx := context.Background()
+
+	// GET with query, throttled once: retried after Retry-After
+	mockServer.FailNext(http.StatusTooManyRequests)
+	raw, err := client.DoRaw(ctx, "get", "/ref/v1/exchanges", url.Values{"$top": {"10"}}, nil)
+	if err != nil {
+		t.Fatalf("DoRaw failed: %v", err)
+	}
+	data, _ := raw.Data.(map[string]any)
+	if raw.StatusCode != http.StatusOK || data["Data"] == nil {
+		t.Errorf("Expected decoded JSON, got %d %+v", raw.StatusCode, raw.Data)
+	}
+	var exchanges struct{ Data []struct{ ExchangeId string } }
+	if err := raw.Decode(&exchanges); err != nil || len(exchanges.Data) != 1 || exchanges.Data[0].ExchangeId != "NYSE" {
+		t.Errorf("Decode failed: %v %+v", err, exchanges)
+	}
+	requests := mockServer.AssertRequested(t, "GET", "/ref/v1/exchanges", 2)
+	if len(requests) == 2 && requests[1].Query != "%24top=10" {
+		t.Errorf("Expected the query, got %q", requests[1].Query)
+	}
+
+	// POST marshals the body
+	raw, err = client.DoRaw(ctx, "POST", "/trade/v1/alerts", nil, map[string]any{"Uic": 21})
+	if err != nil || raw.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected 201, got %+v (err %v)", raw, err)
+	}
+	requests = mockServer.AssertRequested(t, "POST", "/trade/v1/alerts", 1)
+	if len(requests) == 1 && !json.Valid([]byte(requests[0].Body)) {
+		t.Errorf("Expected a JSON body, got %q", requests[0].Body)
+	}
+
+	// Errors keep the response
+	raw, err = client.DoRaw(ctx, "GET", "/ref/v1/unknown", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "HTTP 404") || raw == nil || raw.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected HTTP 404 with the response, got %+v (err %v)", raw, err)
+	}
+
+	// Only paths on the base URL, so the token never goes to another host
+	for _, path := range []string{"https://example.com/x", "//example.com/x", "ref/v1/exchanges"} {
+		if _, err := client.DoRaw(ctx, "GET", path, nil, nil); err == nil {
+			t.Errorf("Expected %q to be rejected", path)
+		}
+	}
+
+	// Trading writes keep the dry run guard
+	dryRun := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), slog.New(slog.NewTextHandler(io.Discard, nil)), WithDryRun(false))
+	if _, err := dryRun.DoRaw(ctx, "POST", "/trade/v1/alerts", nil, map[string]any{"Uic": 21}); err == nil {
+		t.Error("Expected the trade write to be refused in dry run")
+	}
+	mockServer.AssertRequested(t, "POST", "/trade/v1/alerts", 1)
+
+	// The guards see the path the server resolves, not the spelling the caller used
+	sent := len(mockServer.GetRequests())
+	bypasses := []string{"/Trade/v2/orders", "/ref/../trade/v2/orders", "/ref/%2e%2e/TRADE/v2/orders"}
+	for _, path := range bypasses {
+		if _, err := dryRun.DoRaw(ctx, "POST", path, nil, map[string]any{"Uic": 21}); err == nil || !strings.Contains(err.Error(), "dry run") {
+			t.Errorf("Expected %q to be refused in dry run, got %v", path, err)
+		}
+	}
+	client.KillSwitch().Trigger(ctx, "test", KillBlockOnly)
+	for _, path := range bypasses {
+		if _, err := client.DoRaw(ctx, "POST", path, nil, map[string]any{"Uic": 21}); err == nil {
+			t.Errorf("Expected %q to be refused while the kill switch is active", path)
+		}
+	}
+	client.KillSwitch().Reset("test")
+	if n := len(mockServer.GetRequests()) - sent; n != 0 {
+		t.Errorf("Expected no request to reach the server, got %d", n)
+	}
+
+	authClient.authenticated = false
+	if _, err := client.DoRaw(ctx, "GET", "/ref/v1/exchanges", nil, nil); err == nil {
+		t.Error("Expected not authenticated error")
+	}
+}
@@ -0,0 +1,231 @@
+// Package recorder captures streaming updates to disk for debugging and later replay
+// Taps sit between a WebSocketClient channel and its consumer: every update is forwarded
+// unchanged and a copy is queued for the sink
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// Kind identifies the payload of a Record
+type Kind string
+
+const (
+	KindPrice     Kind = "price"
+	KindOrder     Kind = "order"
+	KindPortfolio Kind = "portfolio"
+)
+
+// DefaultQueueSize is the number of records buffered between taps and the sink
+const DefaultQueueSize = 10000
+
+// Record is one captured update; exactly one payload is set
+type Record struct {
+	Kind       Kind                  `json:"kind"`
+	RecordedAt time.Time             `json:"recorded_at"`
+	Price      *saxo.PriceUpdate     `json:"price,omitempty"`
+	Order      *saxo.OrderUpdate     `json:"order,omitempty"`
+	Portfolio  *saxo.PortfolioUpdate `json:"portfolio,omitempty"`
+}
+
+// Recorder writes tapped updates to a Sink from a single background goroutine
+// The live path never waits for disk - when the queue is full the record is dropped and counted
+type Recorder struct {
+	sink   Sink
+	logger *slog.Logger
+
+	queueMu     sync.RWMutex
+	queue       chan Record
+	queueClosed bool // Set by Shutdown under queueMu; enqueue is a no-op afterwards
+	recorded    atomic.Uint64
+	dropped     atomic.Uint64
+
+	tapsMu  sync.Mutex
+	taps    sync.WaitGroup
+	closing bool
+
+	writerDone chan struct{}
+	closeOnce  sync.Once
+	closeErr   error
+	writeErr   error // First sink error, reported by Close
+}
+
+// NewRecorder starts the writer goroutine; queueSize <= 0 uses DefaultQueueSize
+func NewRecorder(sink Sink, queueSize int, logger *slog.Logger) *Recorder {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	r := &Recorder{
+		sink:       sink,
+		logger:     logger,
+		queue:      make(chan Record, queueSize),
+		writerDone: make(chan struct{}),
+	}
+	go r.writeLoop()
+	return r
+}
+
+// TapPrices records every price update from in and forwards it on the returned channel
+// The returned channel has the same capacity as in and is closed when in is closed
+func (r *Recorder) TapPrices(in <-chan saxo.PriceUpdate) <-chan saxo.PriceUpdate {
+	out := make(chan saxo.PriceUpdate, cap(in))
+	r.startTap(func(record func(Record)) {
+		defer close(out)
+		for update := range in {
+			record(Record{Kind: KindPrice, RecordedAt: time.Now(), Price: &update})
+			out <- update
+		}
+	})
+	return out
+}
+
+// TapOrders records every order update from in and forwards it on the returned channel
+func (r *Recorder) TapOrders(in <-chan saxo.OrderUpdate) <-chan saxo.OrderUpdate {
+	out := make(chan saxo.OrderUpdate, cap(in))
+	r.startTap(func(record func(Record)) {
+		defer close(out)
+		for update := range in {
+			record(Record{Kind: KindOrder, RecordedAt: time.Now(), Order: &update})
+			out <- update
+		}
+	})
+	return out
+}
+
+// TapPortfolio records every portfolio update from in and forwards it on the returned channel
+func (r *Recorder) TapPortfolio(in <-chan saxo.PortfolioUpdate) <-chan saxo.PortfolioUpdate {
+	out := make(chan saxo.PortfolioUpdate, cap(in))
+	r.startTap(func(record func(Record)) {
+		defer close(out)
+		for update := range in {
+			record(Record{Kind: KindPortfolio, RecordedAt: time.Now(), Portfolio: &update})
+			out <- update
+		}
+	})
+	return out
+}
+
+// Recorded returns the number of records written to the sink
+func (r *Recorder) Recorded() uint64 {
+	return r.recorded.Load()
+}
+
+// Dropped returns the number of records discarded because the queue was full or already shut down
+func (r *Recorder) Dropped() uint64 {
+	return r.dropped.Load()
+}
+
+// Shutdown implements saxo.Shutdowner - waits for taps to finish (their input channels must
+// be closed first, e.g. by shutting down the WebSocket client), drains the queue and closes the sink
+func (r *Recorder) Shutdown(ctx context.Context) error {
+	r.tapsMu.Lock()
+	r.closing = true
+	r.tapsMu.Unlock()
+
+	tapsDone := make(chan struct{})
+	go func() {
+		r.taps.Wait()
+		close(tapsDone)
+	}()
+	select {
+	case <-tapsDone:
+	case <-ctx.Done():
+		return fmt.Errorf("recorder taps still running at shutdown deadline: %w", ctx.Err())
+	}
+
+	r.closeOnce.Do(func() {
+		r.queueMu.Lock()
+		r.queueClosed = true
+		close(r.queue)
+		r.queueMu.Unlock()
+		<-r.writerDone
+		if err := r.sink.Close(); err != nil {
+			r.closeErr = fmt.Errorf("failed to close recording sink: %w", err)
+		} else if r.writeErr != nil {
+			r.closeErr = fmt.Errorf("recording sink write failed: %w", r.writeErr)
+		}
+
+		r.logger.Info("Recorder closed",
+			"function", "Shutdown",
+			"recorded", r.recorded.Load(),
+			"dropped", r.dropped.Load())
+	})
+	return r.closeErr
+}
+
+// startTap runs a forwarding goroutine tracked by Shutdown
+// Tracked taps may enqueue until they exit - Shutdown only closes the queue after they are done
+func (r *Recorder) startTap(forward func(record func(Record))) {
+	r.tapsMu.Lock()
+	defer r.tapsMu.Unlock()
+
+	if r.closing {
+		// Not tracked - Shutdown may already be waiting on the WaitGroup
+		r.logger.Warn("Tap added after Shutdown, updates are forwarded but not recorded",
+			"function", "startTap")
+		go forward(func(Record) { r.dropped.Add(1) })
+		return
+	}
+	r.taps.Add(1)
+	go func() {
+		defer r.taps.Done()
+		forward(r.enqueue)
+	}()
+}
+
+// enqueue hands a record to the writer without blocking
+// After Shutdown the record is counted as dropped instead of being sent on the closed queue
+func (r *Recorder) enqueue(record Record) {
+	r.queueMu.RLock()
+	defer r.queueMu.RUnlock()
+
+	if r.queueClosed {
+		r.dropped.Add(1)
+		return
+	}
+	select {
+	case r.queue <- record:
+	default:
+		if r.dropped.Add(1)%1000 == 1 {
+			r.logger.Warn("Recorder queue full, dropping records",
+				"function", "enqueue",
+				"dropped_total", r.dropped.Load())
+		}
+	}
+}
+
+// writeLoop drains the queue into the sink, flushing whenever it runs empty
+func (r *Recorder) writeLoop() {
+	defer close(r.writerDone)
+
+	for record := range r.queue {
+		if err := r.sink.Write(record); err != nil {
+			if r.writeErr == nil {
+				r.writeErr = err
+				r.logger.Error("Failed to write record",
+					"function", "writeLoop",
+					"kind", record.Kind,
+					"error", err)
+			}
+			continue
+		}
+		r.recorded.Add(1)
+
+		if len(r.queue) == 0 {
+			if err := r.sink.Flush(); err != nil && r.writeErr == nil {
+				r.writeErr = err
+			}
+		}
+	}
+}
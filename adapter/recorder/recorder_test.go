@@ -0,0 +1,153 @@
+package recorder
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, nil))
+}
+
+func TestRecorder_TapsForwardAndRecord(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewRotatingFileSink(dir, "eurusd", JSONLEncoder{}, 0, 0)
+	if err != nil {
+		t.Fatalf("NewRotatingFileSink failed: %v", err)
+	}
+	rec := NewRecorder(sink, 0, testLogger())
+
+	prices := make(chan saxo.PriceUpdate, 10)
+	orders := make(chan saxo.OrderUpdate, 10)
+	tappedPrices := rec.TapPrices(prices)
+	tappedOrders := rec.TapOrders(orders)
+
+	prices <- saxo.PriceUpdate{Uic: 21, Bid: 1.1, Ask: 1.2, Mid: 1.15, Timestamp: time.Now()}
+	orders <- saxo.OrderUpdate{OrderId: "123", Status: "Working"}
+	close(prices)
+	close(orders)
+
+	if update := <-tappedPrices; update.Uic != 21 {
+		t.Errorf("Expected forwarded price for UIC 21, got %+v", update)
+	}
+	if update := <-tappedOrders; update.OrderId != "123" {
+		t.Errorf("Expected forwarded order 123, got %+v", update)
+	}
+	if _, ok := <-tappedPrices; ok {
+		t.Error("Expected tapped channel closed when input closes")
+	}
+
+	if err := saxo.Shutdown(context.Background(), rec); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if rec.Recorded() != 2 || rec.Dropped() != 0 {
+		t.Errorf("Expected 2 recorded, 0 dropped, got %d/%d", rec.Recorded(), rec.Dropped())
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "eurusd-*.jsonl"))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 capture file, got %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(string(data), `"kind":"order"`) {
+		t.Errorf("Unexpected capture content: %s", data)
+	}
+}
+
+func TestRecorder_TapAfterShutdownForwardsWithoutRecording(t *testing.T) {
+	sink, err := NewRotatingFileSink(t.TempDir(), "late", JSONLEncoder{}, 0, 0)
+	if err != nil {
+		t.Fatalf("NewRotatingFileSink failed: %v", err)
+	}
+	rec := NewRecorder(sink, 0, testLogger())
+	if err := rec.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	prices := make(chan saxo.PriceUpdate, 1)
+	tapped := rec.TapPrices(prices)
+	prices <- saxo.PriceUpdate{Uic: 21, Bid: 1.1, Ask: 1.2}
+	close(prices)
+
+	if update := <-tapped; update.Uic != 21 {
+		t.Errorf("Expected forwarded price for UIC 21, got %+v", update)
+	}
+	if _, ok := <-tapped; ok {
+		t.Error("Expected tapped channel closed when input closes")
+	}
+
+	// A direct enqueue after Shutdown must not send on the closed queue
+	rec.enqueue(Record{Kind: KindPrice, RecordedAt: time.Now()})
+
+	if rec.Recorded() != 0 || rec.Dropped() != 2 {
+		t.Errorf("Expected 0 recorded, 2 dropped, got %d/%d", rec.Recorded(), rec.Dropped())
+	}
+}
+
+func TestRotatingFileSink_RotatesBySizeAndAge(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewRotatingFileSink(dir, "", CSVEncoder{}, 250, time.Hour)
+	if err != nil {
+		t.Fatalf("NewRotatingFileSink failed: %v", err)
+	}
+	now := time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+	sink.now = func() time.Time { return now }
+
+	record := Record{Kind: KindPrice, RecordedAt: now, Price: &saxo.PriceUpdate{Uic: 21, Bid: 1.1, Ask: 1.2, Mid: 1.15, Timestamp: now}}
+	for i := 0; i < 3; i++ {
+		if err := sink.Write(record); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	now = now.Add(time.Hour)
+	if err := sink.Write(record); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "capture-*.csv"))
+	sort.Strings(files)
+	if len(files) < 3 {
+		t.Fatalf("Expected size and age rotation to produce at least 3 files, got %v", files)
+	}
+	for _, file := range files {
+		data, _ := os.ReadFile(file)
+		if !strings.HasPrefix(string(data), "kind,recorded_at,uic") {
+			t.Errorf("Expected CSV header in %s, got %q", file, data)
+		}
+	}
+	if !strings.Contains(files[len(files)-1], "20240102T100000") {
+		t.Errorf("Expected last file named after rotation time, got %s", files[len(files)-1])
+	}
+}
+
+func TestBinaryEncoder_PriceFrame(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	frame, err := BinaryEncoder{}.Encode(Record{Kind: KindPrice, RecordedAt: ts, Price: &saxo.PriceUpdate{Uic: 21, Bid: 1.1, Timestamp: ts}})
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if length := binary.BigEndian.Uint32(frame[0:4]); int(length) != len(frame)-4 {
+		t.Errorf("Length prefix %d does not match frame size %d", length, len(frame)-4)
+	}
+	if frame[4] != binaryKindPrice || len(frame) != 4+1+8+4+8*3+8 {
+		t.Errorf("Unexpected price frame: kind %d, size %d", frame[4], len(frame))
+	}
+
+	if _, err := (BinaryEncoder{}).Encode(Record{Kind: KindOrder}); err == nil || !bytes.Contains([]byte(err.Error()), []byte("no payload")) {
+		t.Errorf("Expected no payload error, got %v", err)
+	}
+}
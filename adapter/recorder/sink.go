@@ -0,0 +1,287 @@
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Sink persists records; Recorder calls it from a single goroutine
+type Sink interface {
+	Write(record Record) error
+	Flush() error // Called whenever the recorder's queue is drained
+	Close() error
+}
+
+// Encoder turns records into bytes for RotatingFileSink
+type Encoder interface {
+	Extension() string // File extension without dot, e.g. "jsonl"
+	Header() []byte    // Written at the start of every file (nil for none)
+	Encode(record Record) ([]byte, error)
+}
+
+// JSONLEncoder writes one JSON object per line - readable by replay.ReadTicks
+type JSONLEncoder struct{}
+
+func (JSONLEncoder) Extension() string { return "jsonl" }
+
+func (JSONLEncoder) Header() []byte { return nil }
+
+func (JSONLEncoder) Encode(record Record) ([]byte, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// csvColumns covers all record kinds; columns that do not apply are left empty
+var csvColumns = []string{
+	"kind", "recorded_at",
+	"uic", "bid", "ask", "mid", "timestamp",
+	"order_id", "status", "filled_size", "order_price",
+	"balance", "margin_used", "margin_free",
+}
+
+// CSVEncoder writes a flat row per record with a header line per file
+type CSVEncoder struct{}
+
+func (CSVEncoder) Extension() string { return "csv" }
+
+func (CSVEncoder) Header() []byte {
+	return csvLine(csvColumns)
+}
+
+func (CSVEncoder) Encode(record Record) ([]byte, error) {
+	row := make([]string, len(csvColumns))
+	row[0] = string(record.Kind)
+	row[1] = record.RecordedAt.Format(time.RFC3339Nano)
+
+	switch {
+	case record.Price != nil:
+		row[2] = strconv.Itoa(record.Price.Uic)
+		row[3] = formatFloat(record.Price.Bid)
+		row[4] = formatFloat(record.Price.Ask)
+		row[5] = formatFloat(record.Price.Mid)
+		row[6] = record.Price.Timestamp.Format(time.RFC3339Nano)
+	case record.Order != nil:
+		row[7] = record.Order.OrderId
+		row[8] = record.Order.Status
+		row[9] = formatFloat(record.Order.FilledSize)
+		row[10] = formatFloat(record.Order.OrderPrice)
+	case record.Portfolio != nil:
+		row[11] = formatFloat(record.Portfolio.Balance)
+		row[12] = formatFloat(record.Portfolio.MarginUsed)
+		row[13] = formatFloat(record.Portfolio.MarginFree)
+	default:
+		return nil, fmt.Errorf("record has no payload")
+	}
+	return csvLine(row), nil
+}
+
+// BinaryEncoder writes compact length-prefixed frames: price ticks as fixed-width fields,
+// order and portfolio updates as JSON payloads
+//
+// Frame: uint32 length | byte kind | int64 recorded_at (unix nanos) | payload
+// Price payload: int32 uic | float64 bid | float64 ask | float64 mid | int64 timestamp (unix nanos)
+type BinaryEncoder struct{}
+
+// Binary kind bytes
+const (
+	binaryKindPrice     byte = 1
+	binaryKindOrder     byte = 2
+	binaryKindPortfolio byte = 3
+)
+
+func (BinaryEncoder) Extension() string { return "bin" }
+
+func (BinaryEncoder) Header() []byte { return nil }
+
+func (BinaryEncoder) Encode(record Record) ([]byte, error) {
+	var payload bytes.Buffer
+
+	var kind byte
+	switch {
+	case record.Price != nil:
+		kind = binaryKindPrice
+		binary.Write(&payload, binary.BigEndian, int32(record.Price.Uic))
+		binary.Write(&payload, binary.BigEndian, math.Float64bits(record.Price.Bid))
+		binary.Write(&payload, binary.BigEndian, math.Float64bits(record.Price.Ask))
+		binary.Write(&payload, binary.BigEndian, math.Float64bits(record.Price.Mid))
+		binary.Write(&payload, binary.BigEndian, record.Price.Timestamp.UnixNano())
+	case record.Order != nil:
+		kind = binaryKindOrder
+		if err := json.NewEncoder(&payload).Encode(record.Order); err != nil {
+			return nil, err
+		}
+	case record.Portfolio != nil:
+		kind = binaryKindPortfolio
+		if err := json.NewEncoder(&payload).Encode(record.Portfolio); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("record has no payload")
+	}
+
+	frame := make([]byte, 4+1+8, 4+1+8+payload.Len())
+	binary.BigEndian.PutUint32(frame[0:4], uint32(1+8+payload.Len()))
+	frame[4] = kind
+	binary.BigEndian.PutUint64(frame[5:13], uint64(record.RecordedAt.UnixNano()))
+	return append(frame, payload.Bytes()...), nil
+}
+
+// RotatingFileSink writes encoded records to Dir, starting a new file when MaxBytes or MaxAge
+// is exceeded. Files are named <Prefix>-<UTC start time>-<seq>.<ext>
+type RotatingFileSink struct {
+	dir      string
+	prefix   string
+	encoder  Encoder
+	maxBytes int64         // 0 = no size limit
+	maxAge   time.Duration // 0 = no time limit
+
+	file     *os.File
+	writer   *bufio.Writer
+	written  int64
+	openedAt time.Time
+	seq      int
+	now      func() time.Time
+}
+
+// NewRotatingFileSink creates Dir if needed; the first file is opened on the first record
+func NewRotatingFileSink(dir, prefix string, encoder Encoder, maxBytes int64, maxAge time.Duration) (*RotatingFileSink, error) {
+	if encoder == nil {
+		encoder = JSONLEncoder{}
+	}
+	if prefix == "" {
+		prefix = "capture"
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	return &RotatingFileSink{
+		dir:      dir,
+		prefix:   prefix,
+		encoder:  encoder,
+		maxBytes: maxBytes,
+		maxAge:   maxAge,
+		now:      time.Now,
+	}, nil
+}
+
+// CurrentFile returns the path being written ("" before the first record)
+func (s *RotatingFileSink) CurrentFile() string {
+	if s.file == nil {
+		return ""
+	}
+	return s.file.Name()
+}
+
+// Write encodes the record, rotating first if it would exceed the limits
+func (s *RotatingFileSink) Write(record Record) error {
+	data, err := s.encoder.Encode(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s record: %w", record.Kind, err)
+	}
+
+	if s.file != nil && s.needsRotation(int64(len(data))) {
+		if err := s.closeFile(); err != nil {
+			return err
+		}
+	}
+	if s.file == nil {
+		if err := s.openFile(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.writer.Write(data)
+	s.written += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
+
+// Flush pushes buffered records to the file
+func (s *RotatingFileSink) Flush() error {
+	if s.writer == nil {
+		return nil
+	}
+	return s.writer.Flush()
+}
+
+// Close flushes and closes the current file
+func (s *RotatingFileSink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	return s.closeFile()
+}
+
+// needsRotation checks the size and age limits; a file always holds at least one record
+func (s *RotatingFileSink) needsRotation(next int64) bool {
+	if s.maxBytes > 0 && s.written+next > s.maxBytes && s.written > int64(len(s.encoder.Header())) {
+		return true
+	}
+	if s.maxAge > 0 && s.now().Sub(s.openedAt) >= s.maxAge {
+		return true
+	}
+	return false
+}
+
+func (s *RotatingFileSink) openFile() error {
+	s.openedAt = s.now()
+	s.seq++
+	name := fmt.Sprintf("%s-%s-%04d.%s", s.prefix, s.openedAt.UTC().Format("20060102T150405"), s.seq, s.encoder.Extension())
+
+	file, err := os.OpenFile(filepath.Join(s.dir, name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open recording file: %w", err)
+	}
+	s.file = file
+	s.writer = bufio.NewWriter(file)
+	s.written = 0
+
+	if header := s.encoder.Header(); len(header) > 0 {
+		n, err := s.writer.Write(header)
+		s.written += int64(n)
+		if err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *RotatingFileSink) closeFile() error {
+	flushErr := s.writer.Flush()
+	closeErr := s.file.Close()
+	s.file = nil
+	s.writer = nil
+	if flushErr != nil {
+		return fmt.Errorf("failed to flush recording file: %w", flushErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to close recording file: %w", closeErr)
+	}
+	return nil
+}
+
+func csvLine(fields []string) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(fields)
+	w.Flush()
+	return buf.Bytes()
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,417 @@
+// Package replay provides a WebSocketClient that plays back historical bars or recorded ticks
+// Strategy code written against saxo.WebSocketClient can be backtested without changes
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/recorder"
+)
+
+// Playback speeds - any other positive multiplier also works (e.g. 60 for one minute per second)
+const (
+	AsFastAsPossible = 0.0  // No pacing - each update is sent as soon as the consumer reads the previous one
+	RealTime         = 1.0  // Gaps between updates match the original timestamps
+	TenTimes         = 10.0 // 10x real time
+)
+
+// ReplayContextID is published on the context ID channel on Connect
+const ReplayContextID = "replay"
+
+// errClosed is returned after Close
+var errClosed = errors.New("replay client is closed")
+
+// Config configures playback
+type Config struct {
+	// Speed multiplies real time: RealTime, TenTimes or AsFastAsPossible (the default)
+	Speed float64
+
+	// ExpandBars emits four prices per bar instead of only Close, so intrabar stops and limits can
+	// trigger in backtests: Open, High, Low, Close on bearish bars, Open, Low, High, Close otherwise
+	ExpandBars bool
+
+	// PriceBufferSize is the price channel buffer, default 100 like the live client
+	PriceBufferSize int
+}
+
+// ReplayWebSocketClient implements saxo.WebSocketClient over recorded data
+// Playback starts on the first SubscribeToPrices call and only emits subscribed UICs.
+// Updates are never dropped - a slow consumer slows playback down. The price channel is
+// closed when every series has been played (or on Close), and Done() is closed with it
+type ReplayWebSocketClient struct {
+	config Config
+	logger *slog.Logger
+
+	mu         sync.Mutex
+	events     []saxo.PriceUpdate
+	subscribed map[int]bool
+	connected  bool
+	started    bool
+	closed     bool
+
+	stateChannel     chan<- bool
+	contextIDChannel chan<- string
+
+	priceUpdateChan     chan saxo.PriceUpdate
+	orderUpdateChan     chan saxo.OrderUpdate
+	portfolioUpdateChan chan saxo.PortfolioUpdate
+	sessionEventChan    chan saxo.SessionUpdate
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// Compile-time check that replay is a drop-in WebSocketClient
+var _ saxo.WebSocketClient = (*ReplayWebSocketClient)(nil)
+
+// NewReplayWebSocketClient creates a replay client; add data with AddBars, AddTicks or LoadTicks
+func NewReplayWebSocketClient(config Config, logger *slog.Logger) *ReplayWebSocketClient {
+	if config.Speed < 0 {
+		config.Speed = AsFastAsPossible
+	}
+	if config.PriceBufferSize <= 0 {
+		config.PriceBufferSize = 100
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &ReplayWebSocketClient{
+		config:              config,
+		logger:              logger,
+		subscribed:          make(map[int]bool),
+		priceUpdateChan:     make(chan saxo.PriceUpdate, config.PriceBufferSize),
+		orderUpdateChan:     make(chan saxo.OrderUpdate, 1),
+		portfolioUpdateChan: make(chan saxo.PortfolioUpdate, 1),
+		sessionEventChan:    make(chan saxo.SessionUpdate, 1),
+		stop:                make(chan struct{}),
+		done:                make(chan struct{}),
+	}
+}
+
+// AddBars queues a bar series for uic (HistoricalDataPoint carries a ticker, not a UIC)
+// Bid/Ask are the bar price -/+ half of spread
+func (r *ReplayWebSocketClient) AddBars(uic int, points []saxo.HistoricalDataPoint, spread float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return fmt.Errorf("cannot add bars after playback started")
+	}
+
+	for _, point := range points {
+		prices := []float64{point.Close}
+		if r.config.ExpandBars {
+			if point.Close < point.Open {
+				prices = []float64{point.Open, point.High, point.Low, point.Close}
+			} else {
+				prices = []float64{point.Open, point.Low, point.High, point.Close}
+			}
+		}
+		for _, price := range prices {
+			r.events = append(r.events, saxo.PriceUpdate{
+				Uic:       uic,
+				Bid:       price - spread/2,
+				Ask:       price + spread/2,
+				Mid:       price,
+				Timestamp: point.Time,
+			})
+		}
+	}
+	return nil
+}
+
+// AddTicks queues recorded price updates
+func (r *ReplayWebSocketClient) AddTicks(updates []saxo.PriceUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return fmt.Errorf("cannot add ticks after playback started")
+	}
+	r.events = append(r.events, updates...)
+	return nil
+}
+
+// LoadTicks reads a JSON Lines tick file (see ReadTicks) and queues it
+func (r *ReplayWebSocketClient) LoadTicks(reader io.Reader) error {
+	updates, err := ReadTicks(reader)
+	if err != nil {
+		return err
+	}
+	return r.AddTicks(updates)
+}
+
+// ReadTicks parses a JSON Lines tick file; blank lines are skipped
+// Accepts plain PriceUpdate objects and recorder.JSONLEncoder captures (non-price records skipped)
+func ReadTicks(reader io.Reader) ([]saxo.PriceUpdate, error) {
+	var updates []saxo.PriceUpdate
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record recorder.Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse tick on line %d: %w", line, err)
+		}
+		if record.Kind != "" {
+			if record.Price != nil {
+				updates = append(updates, *record.Price)
+			}
+			continue
+		}
+
+		var update saxo.PriceUpdate
+		if err := json.Unmarshal(scanner.Bytes(), &update); err != nil {
+			return nil, fmt.Errorf("failed to parse tick on line %d: %w", line, err)
+		}
+		updates = append(updates, update)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ticks: %w", err)
+	}
+	return updates, nil
+}
+
+// Connect marks the client connected and publishes state; no network is involved
+func (r *ReplayWebSocketClient) Connect(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return errClosed
+	}
+	if r.connected {
+		return nil
+	}
+	r.connected = true
+	r.publishStateLocked(true)
+
+	r.logger.Info("Replay client connected",
+		"function", "Connect",
+		"events", len(r.events),
+		"speed", r.config.Speed)
+	return nil
+}
+
+// SubscribeToPrices adds instruments (numeric UIC strings) to the playback filter
+// The first call starts playback; later calls only widen the filter
+func (r *ReplayWebSocketClient) SubscribeToPrices(ctx context.Context, instruments []string, assetType string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return errClosed
+	}
+	if !r.connected {
+		return fmt.Errorf("replay client not connected")
+	}
+
+	added := 0
+	for _, instrument := range instruments {
+		uic, err := strconv.Atoi(instrument)
+		if err != nil {
+			r.logger.Warn("Could not parse instrument as UIC",
+				"function", "SubscribeToPrices",
+				"instrument", instrument)
+			continue
+		}
+		r.subscribed[uic] = true
+		added++
+	}
+	if added == 0 {
+		return fmt.Errorf("no valid UICs found for instruments")
+	}
+
+	if !r.started {
+		r.started = true
+		events := r.events
+		r.events = nil
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Timestamp.Before(events[j].Timestamp)
+		})
+
+		r.wg.Add(1)
+		go r.play(events)
+	}
+	return nil
+}
+
+// SubscribeToOrders is a no-op - replay carries market data only
+func (r *ReplayWebSocketClient) SubscribeToOrders(ctx context.Context) error {
+	return nil
+}
+
+// SubscribeToPortfolio is a no-op - replay carries market data only
+func (r *ReplayWebSocketClient) SubscribeToPortfolio(ctx context.Context) error {
+	return nil
+}
+
+// SubscribeToSessionEvents is a no-op - replay carries market data only
+func (r *ReplayWebSocketClient) SubscribeToSessionEvents(ctx context.Context) error {
+	return nil
+}
+
+// GetPriceUpdateChannel returns replayed prices, closed when playback ends
+func (r *ReplayWebSocketClient) GetPriceUpdateChannel() <-chan saxo.PriceUpdate {
+	return r.priceUpdateChan
+}
+
+// GetOrderUpdateChannel returns a channel that never carries updates (closed on Close)
+// Pair with paper.PaperBrokerClient for simulated order events
+func (r *ReplayWebSocketClient) GetOrderUpdateChannel() <-chan saxo.OrderUpdate {
+	return r.orderUpdateChan
+}
+
+// GetPortfolioUpdateChannel returns a channel that never carries updates (closed on Close)
+func (r *ReplayWebSocketClient) GetPortfolioUpdateChannel() <-chan saxo.PortfolioUpdate {
+	return r.portfolioUpdateChan
+}
+
+// GetSessionEventChannel returns a channel that never carries updates (closed on Close)
+func (r *ReplayWebSocketClient) GetSessionEventChannel() <-chan saxo.SessionUpdate {
+	return r.sessionEventChan
+}
+
+// SetStateChannels registers connection state channels; Connect publishes ReplayContextID
+func (r *ReplayWebSocketClient) SetStateChannels(stateChannel chan<- bool, contextIDChannel chan<- string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stateChannel = stateChannel
+	r.contextIDChannel = contextIDChannel
+	if r.connected {
+		r.publishStateLocked(true)
+	}
+}
+
+// Done is closed when playback has finished (or Close stopped it)
+func (r *ReplayWebSocketClient) Done() <-chan struct{} {
+	return r.done
+}
+
+// IsConnected implements saxo.WebSocketClient - true between Connect and Close
+func (r *ReplayWebSocketClient) IsConnected() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.connected
+}
+
+// Close stops playback and closes all channels. Idempotent and safe to call before Connect
+func (r *ReplayWebSocketClient) Close() error {
+	r.closeOnce.Do(func() {
+		r.mu.Lock()
+		r.closed = true
+		started := r.started
+		wasConnected := r.connected
+		r.connected = false
+		r.mu.Unlock()
+
+		close(r.stop)
+		r.wg.Wait()
+
+		// play closes the price channel and done on exit; without playback close them here
+		if !started {
+			close(r.priceUpdateChan)
+			close(r.done)
+		}
+		close(r.orderUpdateChan)
+		close(r.portfolioUpdateChan)
+		close(r.sessionEventChan)
+
+		if wasConnected {
+			r.mu.Lock()
+			r.publishStateLocked(false)
+			r.mu.Unlock()
+		}
+
+		r.logger.Info("Replay client closed",
+			"function", "Close")
+	})
+	return nil
+}
+
+// Shutdown implements saxo.Shutdowner
+func (r *ReplayWebSocketClient) Shutdown(ctx context.Context) error {
+	return r.Close()
+}
+
+// play sends events in timestamp order, paced by config.Speed
+func (r *ReplayWebSocketClient) play(events []saxo.PriceUpdate) {
+	defer r.wg.Done()
+	defer close(r.done)
+	defer close(r.priceUpdateChan)
+
+	sent := 0
+	var previous time.Time
+	for _, event := range events {
+		if !r.isSubscribed(event.Uic) {
+			continue
+		}
+
+		if r.config.Speed > 0 && !previous.IsZero() {
+			if gap := event.Timestamp.Sub(previous); gap > 0 {
+				timer := time.NewTimer(time.Duration(float64(gap) / r.config.Speed))
+				select {
+				case <-timer.C:
+				case <-r.stop:
+					timer.Stop()
+					return
+				}
+			}
+		}
+		previous = event.Timestamp
+
+		select {
+		case r.priceUpdateChan <- event:
+			sent++
+		case <-r.stop:
+			return
+		}
+	}
+
+	r.logger.Info("Replay finished",
+		"function", "play",
+		"events_sent", sent)
+}
+
+// isSubscribed checks the playback filter
+func (r *ReplayWebSocketClient) isSubscribed(uic int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.subscribed[uic]
+}
+
+// publishStateLocked sends state without blocking (mirrors the live client)
+func (r *ReplayWebSocketClient) publishStateLocked(connected bool) {
+	if connected && r.contextIDChannel != nil {
+		select {
+		case r.contextIDChannel <- ReplayContextID:
+		default:
+		}
+	}
+	if r.stateChannel != nil {
+		select {
+		case r.stateChannel <- connected:
+		default:
+		}
+	}
+}
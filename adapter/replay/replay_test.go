@@ -0,0 +1,158 @@
+package replay
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+func newTestReplay(t *testing.T, config Config) *ReplayWebSocketClient {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	r := NewReplayWebSocketClient(config, logger)
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestReplay_BarsMergedInTimeOrder(t *testing.T) {
+	r := newTestReplay(t, Config{ExpandBars: true})
+	ctx := context.Background()
+	start := time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	// Bearish bar - path is Open, High, Low, Close
+	if err := r.AddBars(21, []saxo.HistoricalDataPoint{
+		{Time: start, Open: 1.10, High: 1.12, Low: 1.08, Close: 1.09},
+	}, 0.0002); err != nil {
+		t.Fatalf("AddBars failed: %v", err)
+	}
+	if err := r.AddTicks([]saxo.PriceUpdate{
+		{Uic: 31, Bid: 1.25, Ask: 1.26, Mid: 1.255, Timestamp: start.Add(-time.Minute)},
+		{Uic: 99, Bid: 5, Ask: 6, Mid: 5.5, Timestamp: start},
+	}); err != nil {
+		t.Fatalf("AddTicks failed: %v", err)
+	}
+
+	state := make(chan bool, 1)
+	contextIDs := make(chan string, 1)
+	r.SetStateChannels(state, contextIDs)
+	if err := r.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if !<-state || <-contextIDs != ReplayContextID {
+		t.Error("Expected connected state and replay context ID")
+	}
+
+	if err := r.SubscribeToPrices(ctx, []string{"21", "31"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+
+	var got []saxo.PriceUpdate
+	for update := range r.GetPriceUpdateChannel() {
+		got = append(got, update)
+	}
+	<-r.Done()
+
+	// UIC 99 not subscribed; UIC 31 tick is earliest
+	if len(got) != 5 {
+		t.Fatalf("Expected 5 updates, got %d: %+v", len(got), got)
+	}
+	if got[0].Uic != 31 {
+		t.Errorf("Expected earliest tick first, got UIC %d", got[0].Uic)
+	}
+	wantMids := []float64{1.10, 1.12, 1.08, 1.09}
+	for i, want := range wantMids {
+		if got[i+1].Mid != want {
+			t.Errorf("Bar path step %d: expected mid %v, got %v", i, want, got[i+1].Mid)
+		}
+	}
+	if spread := got[1].Ask - got[1].Bid; spread < 0.00019 || spread > 0.00021 {
+		t.Errorf("Expected spread 0.0002, got %v", spread)
+	}
+}
+
+func TestReplay_ExpandBarsPath(t *testing.T) {
+	r := newTestReplay(t, Config{ExpandBars: true})
+	start := time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+	if err := r.AddBars(21, []saxo.HistoricalDataPoint{
+		{Time: start, Open: 1.10, High: 1.12, Low: 1.08, Close: 1.11},                // Bullish
+		{Time: start.Add(time.Hour), Open: 1.11, High: 1.13, Low: 1.09, Close: 1.10}, // Bearish
+	}, 0); err != nil {
+		t.Fatalf("AddBars failed: %v", err)
+	}
+
+	wantMids := []float64{1.10, 1.08, 1.12, 1.11, 1.11, 1.13, 1.09, 1.10}
+	if len(r.events) != len(wantMids) {
+		t.Fatalf("Expected %d prices, got %d", len(wantMids), len(r.events))
+	}
+	for i, want := range wantMids {
+		if r.events[i].Mid != want {
+			t.Errorf("Bar path step %d: expected mid %v, got %v", i, want, r.events[i].Mid)
+		}
+	}
+}
+
+func TestReplay_PacedPlaybackAndClose(t *testing.T) {
+	// 1 hour between ticks at 3600x is ~1s - Close must interrupt the wait
+	r := newTestReplay(t, Config{Speed: 3600})
+	ctx := context.Background()
+
+	ticks := "{\"Uic\":21,\"Bid\":1.1,\"Ask\":1.2,\"Mid\":1.15,\"Timestamp\":\"2024-01-02T09:00:00Z\"}\n\n" +
+		"{\"Uic\":21,\"Bid\":1.2,\"Ask\":1.3,\"Mid\":1.25,\"Timestamp\":\"2024-01-02T10:00:00Z\"}\n"
+	if err := r.LoadTicks(strings.NewReader(ticks)); err != nil {
+		t.Fatalf("LoadTicks failed: %v", err)
+	}
+
+	if err := r.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if err := r.SubscribeToPrices(ctx, []string{"21"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+
+	select {
+	case update := <-r.GetPriceUpdateChannel():
+		if update.Bid != 1.1 {
+			t.Errorf("Expected first tick, got %+v", update)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("First tick not delivered")
+	}
+
+	start := time.Now()
+	if err := saxo.Shutdown(ctx, r); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Close waited for the paced gap (%v)", elapsed)
+	}
+	if _, ok := <-r.GetPriceUpdateChannel(); ok {
+		t.Error("Expected price channel closed after Close")
+	}
+	if err := r.Connect(ctx); err == nil {
+		t.Error("Expected Connect after Close to fail")
+	}
+}
+
+func TestReadTicks_InvalidLine(t *testing.T) {
+	if _, err := ReadTicks(strings.NewReader("{\"Uic\":21}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected parse error on line 2, got %v", err)
+	}
+}
+
+func TestReadTicks_RecorderCapture(t *testing.T) {
+	capture := `{"kind":"price","recorded_at":"2024-01-02T09:00:01Z","price":{"Uic":21,"Bid":1.1,"Ask":1.2,"Mid":1.15,"Timestamp":"2024-01-02T09:00:00Z"}}
+{"kind":"order","recorded_at":"2024-01-02T09:00:02Z","order":{"OrderId":"123"}}
+`
+	updates, err := ReadTicks(strings.NewReader(capture))
+	if err != nil {
+		t.Fatalf("ReadTicks failed: %v", err)
+	}
+	if len(updates) != 1 || updates[0].Uic != 21 || updates[0].Bid != 1.1 {
+		t.Errorf("Expected the single price record, got %+v", updates)
+	}
+}
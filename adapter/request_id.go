@@ -0,0 +1,79 @@
+package saxo
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// RequestIDHeader carries the idempotency key Saxo uses to detect duplicate operations
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// orderRequestKey marks the ctx of the request that places or modifies an order
+type orderRequestKey struct{}
+
+// WithRequestID makes the order request of PlaceOrder, ClosePosition and ModifyOrder calls made with
+// ctx use requestID as its X-Request-ID. Prechecks, cancels and all other writes get a fresh key each
+// Reuse the same key when retrying a PlaceOrder after a network timeout: Saxo rejects
+// the repeat as a duplicate instead of executing the order twice
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// withoutRequestID drops the caller's key, for calls made in a loop with one ctx
+func withoutRequestID(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, "")
+}
+
+// orderRequest marks ctx for the single request that places or modifies an order, the only one
+// that takes the WithRequestID key
+func orderRequest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, orderRequestKey{}, true)
+}
+
+// RequestIDFromContext returns the key set by WithRequestID
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	return requestID, ok && requestID != ""
+}
+
+// NewRequestID returns a random UUID (version 4) for use with WithRequestID
+func NewRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(fmt.Sprintf("saxo: failed to generate request ID: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// isMutating reports whether method changes state at Saxo and needs an idempotency key
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// applyRequestID sets X-Request-ID on mutating requests and returns it ("" for reads)
+// Precedence: header already set by the caller, then WithRequestID (order requests only), then a fresh UUID
+func applyRequestID(ctx context.Context, req *http.Request) string {
+	if !isMutating(req.Method) {
+		return ""
+	}
+	if requestID := req.Header.Get(RequestIDHeader); requestID != "" {
+		return requestID
+	}
+	requestID, ok := RequestIDFromContext(ctx)
+	if isOrder, _ := ctx.Value(orderRequestKey{}).(bool); !ok || !isOrder {
+		requestID = NewRequestID()
+	}
+	req.Header.Set(RequestIDHeader, requestID)
+	return requestID
+}
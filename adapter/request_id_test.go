@@ -0,0 +1,154 @@
+package saxo
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewRequestID(t *testing.T) {
+	first, second := NewRequestID(), NewRequestID()
+	if !uuidPattern.MatchString(first) {
+		t.Errorf("Expected a version 4 UUID, got %q", first)
+	}
+	if first == second {
+		t.Errorf("Expected unique request IDs, got %q twice", first)
+	}
+}
+
+func TestSaxoBrokerClient_RequestIDs(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SimulateOrders("test_account_key", 100000)
+
+	authClient := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "mock_token",
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+	header := http.CanonicalHeaderKey(RequestIDHeader)
+
+	order := OrderRequest{
+		Instrument: createTestInstrument("EURUSD", 21, "FxSpot"),
+		Side:       "Buy",
+		Size:       1000,
+		Price:      1.0850,
+		OrderType:  "Limit",
+		AccountKey: "test_account_key",
+	}
+
+	// Generated: every mutating call gets its own UUID
+	for i := 0; i < 2; i++ {
+		if _, err := client.PlaceOrder(context.Background(), order); err != nil {
+			t.Fatalf("PlaceOrder failed: %v", err)
+		}
+	}
+	placed := mockServer.AssertRequested(t, "POST", "/trade/v2/orders", 2)
+	if len(placed) == 2 {
+		if !uuidPattern.MatchString(placed[0].Headers[header]) {
+			t.Errorf("Expected generated UUID request ID, got %q", placed[0].Headers[header])
+		}
+		if placed[0].Headers[header] == placed[1].Headers[header] {
+			t.Error("Expected distinct request IDs for separate orders")
+		}
+	}
+
+	// Reads carry no idempotency key
+	if _, err := client.GetOpenOrders(context.Background()); err != nil {
+		t.Fatalf("GetOpenOrders failed: %v", err)
+	}
+	for _, req := range mockServer.RequestsTo("GET", "/port/v1/orders/me") {
+		if id := req.Headers[header]; id != "" {
+			t.Errorf("GET should not carry a request ID, got %q", id)
+		}
+	}
+
+	// Caller-supplied: a retry reuses the same key
+	mockServer.ClearRequests()
+	ctx := WithRequestID(context.Background(), "retry-key-1")
+	if _, err := client.PlaceOrder(ctx, order); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	placed = mockServer.AssertRequested(t, "POST", "/trade/v2/orders", 1)
+	if len(placed) == 1 && placed[0].Headers[header] != "retry-key-1" {
+		t.Errorf("Expected caller request ID retry-key-1, got %q", placed[0].Headers[header])
+	}
+
+	// Errors name the request so it can be traced with Saxo support
+	// Cancels never take the caller's key - it belongs to the order request
+	err := client.CancelOrder(WithRequestID(context.Background(), "cancel-key-1"), CancelOrderRequest{
+		OrderID:    "999999",
+		AccountKey: "test_account_key",
+	})
+	cancelled := mockServer.RequestsTo("DELETE", "/trade/v2/orders/{orderIds}")
+	if len(cancelled) != 1 || cancelled[0].Headers[header] == "cancel-key-1" || !uuidPattern.MatchString(cancelled[0].Headers[header]) {
+		t.Fatalf("Expected one cancel with a generated request ID, got %+v", cancelled)
+	}
+	if err == nil || !strings.Contains(err.Error(), cancelled[0].Headers[header]) {
+		t.Errorf("Expected error to contain the request ID, got %v", err)
+	}
+}
+
+func TestSaxoBrokerClient_RequestIDScope(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SimulateOrders("test_account_key", 100000)
+	mockServer.SetResponse("POST", "/trade/v2/orders/precheck", 200, map[string]interface{}{"PreCheckResult": "Ok"})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger,
+		WithRejectOrderWarnings(OrderWarningMarketState))
+	header := http.CanonicalHeaderKey(RequestIDHeader)
+
+	order := OrderRequest{
+		Instrument: createTestInstrument("EURUSD", 21, "FxSpot"),
+		Side:       "Buy",
+		Size:       1000,
+		Price:      1.0850,
+		OrderType:  "Limit",
+		AccountKey: "test_account_key",
+	}
+	ctx := WithRequestID(context.Background(), "order-key-1")
+	if _, err := client.PlaceOrder(ctx, order); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+
+	// The precheck must not use up the key of the order
+	prechecks := mockServer.AssertRequested(t, "POST", "/trade/v2/orders/precheck", 1)
+	placed := mockServer.AssertRequested(t, "POST", "/trade/v2/orders", 1)
+	if len(prechecks) != 1 || len(placed) != 1 {
+		t.FailNow()
+	}
+	if placed[0].Headers[header] != "order-key-1" {
+		t.Errorf("Expected the order to carry order-key-1, got %q", placed[0].Headers[header])
+	}
+	if id := prechecks[0].Headers[header]; id == placed[0].Headers[header] || !uuidPattern.MatchString(id) {
+		t.Errorf("Expected a fresh request ID on the precheck, got %q", id)
+	}
+
+	// The kill switch cancels every order with its own key, even with a caller key on ctx
+	if _, err := client.PlaceOrder(context.Background(), order); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	event := client.KillSwitch().Trigger(ctx, "test", KillCancelOrders)
+	if len(event.CancelledOrders) != 2 || len(event.Errors) != 0 {
+		t.Fatalf("Expected 2 cancelled orders, got %+v", event)
+	}
+	seen := map[string]bool{}
+	for _, req := range mockServer.RequestsTo("DELETE", "/trade/v2/orders/{orderIds}") {
+		id := req.Headers[header]
+		if id == "order-key-1" || seen[id] {
+			t.Errorf("Expected distinct generated request IDs on cancels, got %q", id)
+		}
+		seen[id] = true
+	}
+}
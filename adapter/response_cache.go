@@ -0,0 +1,171 @@
+package saxo
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEntry identifies a cached REST response (see InvalidateCache)
+type CacheEntry string
+
+const (
+	CacheClientInfo    CacheEntry = "client_info"    // GetClientInfo - /port/v1/users/me
+	CacheClientDetails CacheEntry = "client_details" // GetClientDetails - /port/v1/clients/me (CacheTTLs.ClientInfo)
+	CacheAccounts      CacheEntry = "accounts"       // GetAccounts - /port/v1/accounts/me
+	CacheBalance       CacheEntry = "balance"        // GetBalance/GetAccountBalance - /port/v1/balances/me
+
+	// Per-UIC instrument constraints used by WithOrderValidation - /ref/v1/instruments/details
+	CacheInstrumentDetails CacheEntry = "instrument_details"
+
+	// Per-UIC trading schedules, kept until the UTC day changes - /ref/v1/instruments/tradingschedule
+	CacheTradingSchedules CacheEntry = "trading_schedules"
+)
+
+// CacheTTLs sets how long each response is reused - 0 disables caching for that entry
+type CacheTTLs struct {
+	ClientInfo time.Duration
+	Accounts   time.Duration
+	Balance    time.Duration
+
+	InstrumentDetails time.Duration
+}
+
+// DefaultCacheTTLs are used unless overridden with WithCacheTTLs
+// ClientKey and accounts rarely change; balance is kept short so each trading cycle sees fresh margin.
+// Instrument details only change on contract roll or exchange notices
+func DefaultCacheTTLs() CacheTTLs {
+	return CacheTTLs{
+		ClientInfo:        time.Hour,
+		Accounts:          10 * time.Minute,
+		Balance:           5 * time.Second,
+		InstrumentDetails: 24 * time.Hour,
+	}
+}
+
+// WithCacheTTLs overrides DefaultCacheTTLs (REST client only)
+func WithCacheTTLs(ttls CacheTTLs) Option {
+	return func(o *ClientOptions) {
+		o.CacheTTLs = &ttls
+	}
+}
+
+// ttl returns the configured lifetime for entry
+func (t CacheTTLs) ttl(entry CacheEntry) time.Duration {
+	switch entry {
+	case CacheClientInfo, CacheClientDetails:
+		return t.ClientInfo
+	case CacheAccounts:
+		return t.Accounts
+	case CacheBalance:
+		return t.Balance
+	case CacheInstrumentDetails:
+		return t.InstrumentDetails
+	}
+	return 0
+}
+
+type bypassCacheKey struct{}
+
+// WithoutCache makes cached calls made with the returned ctx go to Saxo (the fresh response is still stored)
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return bypass
+}
+
+// responseCache holds read-through REST responses with per-entry expiry
+type responseCache struct {
+	ttls    CacheTTLs
+	clock   Clock
+	mu      sync.Mutex
+	entries map[CacheEntry]cachedResponse
+}
+
+type cachedResponse struct {
+	value   any
+	expires time.Time
+}
+
+func newResponseCache(ttls CacheTTLs, clock Clock) *responseCache {
+	return &responseCache{
+		ttls:    ttls,
+		clock:   clock,
+		entries: make(map[CacheEntry]cachedResponse),
+	}
+}
+
+func (c *responseCache) get(entry CacheEntry) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.entries[entry]
+	if !ok || c.clock.Now().After(cached.expires) {
+		return nil, false
+	}
+	return cached.value, true
+}
+
+func (c *responseCache) put(entry CacheEntry, value any) {
+	ttl := c.ttls.ttl(entry)
+	if ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[entry] = cachedResponse{value: value, expires: c.clock.Now().Add(ttl)}
+}
+
+// invalidate drops entries (all entries when none are given)
+func (c *responseCache) invalidate(entries ...CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(entries) == 0 {
+		c.entries = make(map[CacheEntry]cachedResponse)
+		return
+	}
+	for _, entry := range entries {
+		delete(c.entries, entry)
+	}
+}
+
+// cachedFetch returns a copy of the cached response for entry, or calls fetch and stores its result
+// Copies keep callers from mutating the shared value (slices inside are still shared - read only)
+func cachedFetch[T any](ctx context.Context, cache *responseCache, entry CacheEntry, fetch func() (*T, error)) (*T, error) {
+	if !cacheBypassed(ctx) {
+		if cached, ok := cache.get(entry); ok {
+			value := *cached.(*T)
+			return &value, nil
+		}
+	}
+
+	fresh, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	cache.put(entry, fresh)
+	value := *fresh
+	return &value, nil
+}
+
+// InvalidateCache drops cached responses so the next call goes to Saxo (all entries when none are given)
+// Balance is invalidated automatically after every successful order or position change
+func (sbc *SaxoBrokerClient) InvalidateCache(entries ...CacheEntry) {
+	sbc.responseCache.invalidate(entries...)
+	if len(entries) == 0 || slices.Contains(entries, CacheInstrumentDetails) {
+		sbc.instrumentDetails.invalidate()
+	}
+	if len(entries) == 0 || slices.Contains(entries, CacheTradingSchedules) {
+		sbc.invalidateSchedules()
+	}
+}
+
+// invalidatesBalance reports whether a successful request changes balance or margin
+// path includes the base URL path (e.g. /sim/openapi/trade/v2/orders)
+func invalidatesBalance(method, path string) bool {
+	return method != "GET" && strings.Contains(path, "/trade/")
+}
@@ -0,0 +1,78 @@
+package saxo
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestSaxoBrokerClient_ResponseCache(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SetResponse("GET", "/port/v1/users/me", http.StatusOK, SaxoClientInfo{ClientKey: "client1"})
+	mockServer.SetResponse("GET", "/port/v1/balances/me", http.StatusOK, SaxoBalance{TotalValue: 1000})
+
+	authClient := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "mock_token",
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+	ctx := context.Background()
+
+	countRequests := func(path string) int {
+		count := 0
+		for _, req := range mockServer.GetRequests() {
+			if req.Path == path {
+				count++
+			}
+		}
+		return count
+	}
+
+	for i := 0; i < 3; i++ {
+		info, err := client.GetClientInfo(ctx)
+		if err != nil || info.ClientKey != "client1" {
+			t.Fatalf("GetClientInfo returned %+v, err %v", info, err)
+		}
+		info.ClientKey = "mutated" // Callers get copies
+	}
+	if got := countRequests("/port/v1/users/me"); got != 1 {
+		t.Errorf("Expected client info fetched once, got %d", got)
+	}
+
+	// Per-call bypass and explicit invalidation go to Saxo
+	client.GetClientInfo(WithoutCache(ctx))
+	client.InvalidateCache(CacheClientInfo)
+	info, _ := client.GetClientInfo(ctx)
+	if got := countRequests("/port/v1/users/me"); got != 3 || info.ClientKey != "client1" {
+		t.Errorf("Expected 3 fetches after bypass and invalidate, got %d (%+v)", got, info)
+	}
+
+	// A placed order invalidates the cached balance
+	client.GetBalance(ctx)
+	client.GetBalance(ctx)
+	if _, err := client.PlaceOrder(ctx, OrderRequest{
+		Instrument: createTestInstrument("EURUSD", 21, "FxSpot"),
+		Side:       "Buy",
+		Size:       1000,
+		OrderType:  "Market",
+	}); err != nil {
+		t.Fatalf("PlaceOrder failed: %v", err)
+	}
+	client.GetBalance(ctx)
+	if got := countRequests("/port/v1/balances/me"); got != 2 {
+		t.Errorf("Expected balance refetched only after the order, got %d fetches", got)
+	}
+
+	// Zero TTL disables caching
+	uncached := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger, WithCacheTTLs(CacheTTLs{}))
+	mockServer.ClearRequests()
+	uncached.GetBalance(ctx)
+	uncached.GetBalance(ctx)
+	if got := countRequests("/port/v1/balances/me"); got != 2 {
+		t.Errorf("Expected every call fetched with caching disabled, got %d", got)
+	}
+}
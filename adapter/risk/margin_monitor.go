@@ -0,0 +1,224 @@
+package risk
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// Margin levels of MarginMonitor, from MarginUtilizationPct
+const (
+	MarginLevelNormal   = "Normal"
+	MarginLevelWarning  = "Warning"
+	MarginLevelCritical = "Critical"
+)
+
+// Default MarginThresholds values, used for zero fields
+const (
+	DefaultMarginWarningPct    = 60.0
+	DefaultMarginCriticalPct   = 80.0
+	DefaultMarginHysteresisPct = 5.0
+	DefaultMarginAlertCooldown = 5 * time.Minute
+)
+
+// MarginThresholds configures MarginMonitor - zero values use the defaults above
+type MarginThresholds struct {
+	WarningPct  float64 // MarginUtilizationPct at which the level becomes Warning
+	CriticalPct float64 // MarginUtilizationPct at which the level becomes Critical
+	// HysteresisPct is how far utilization must fall below a threshold before the level drops back,
+	// so a value hovering at the threshold does not flap
+	HysteresisPct float64
+	// Cooldown suppresses a repeated alert for the same level (after falling back and rising again)
+	// Critical escalations are never suppressed
+	Cooldown time.Duration
+}
+
+func (t MarginThresholds) withDefaults() MarginThresholds {
+	if t.WarningPct <= 0 {
+		t.WarningPct = DefaultMarginWarningPct
+	}
+	if t.CriticalPct <= 0 {
+		t.CriticalPct = DefaultMarginCriticalPct
+	}
+	if t.HysteresisPct <= 0 {
+		t.HysteresisPct = DefaultMarginHysteresisPct
+	}
+	if t.Cooldown <= 0 {
+		t.Cooldown = DefaultMarginAlertCooldown
+	}
+	return t
+}
+
+// MarginAlert is delivered when the margin level changes
+type MarginAlert struct {
+	Level          string // MarginLevelNormal, MarginLevelWarning, MarginLevelCritical
+	Previous       string
+	UtilizationPct float64
+	Update         saxo.PortfolioUpdate // Balance the alert was computed from
+	At             time.Time
+}
+
+// Escalated reports whether the level went up
+func (a MarginAlert) Escalated() bool {
+	return marginLevelRank(a.Level) > marginLevelRank(a.Previous)
+}
+
+// MarginAlertHandler is called synchronously for every alert
+type MarginAlertHandler func(MarginAlert)
+
+// MarginMonitor watches MarginUtilizationPct of the balance stream and alerts on level changes,
+// so a bot can reduce exposure before Saxo force-closes positions
+// Feed it with Run (GetPortfolioUpdateChannel) or Update. Once the WebSocket client's Events is
+// called, GetPortfolioUpdateChannel goes silent - call Update for PortfolioEvents in the Events loop
+type MarginMonitor struct {
+	thresholds MarginThresholds
+	handler    MarginAlertHandler
+	logger     *slog.Logger
+
+	mu          sync.Mutex
+	clock       saxo.Clock // Stamps alerts and times the cooldown (SetClock)
+	level       string
+	lastAlerted map[string]time.Time // Level -> last alert
+	eventChan   chan<- MarginAlert
+}
+
+// NewMarginMonitor creates a monitor starting at MarginLevelNormal; handler may be nil
+func NewMarginMonitor(thresholds MarginThresholds, handler MarginAlertHandler, logger *slog.Logger) *MarginMonitor {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &MarginMonitor{
+		thresholds:  thresholds.withDefaults(),
+		handler:     handler,
+		logger:      logger,
+		clock:       saxo.SystemClock,
+		level:       MarginLevelNormal,
+		lastAlerted: make(map[string]time.Time),
+	}
+}
+
+// SetClock replaces saxo.SystemClock, e.g. with the clients' WithClock clock; nil restores it
+func (m *MarginMonitor) SetClock(clock saxo.Clock) {
+	if clock == nil {
+		clock = saxo.SystemClock
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clock = clock
+}
+
+// SetEventChannel also delivers alerts to ch (non-blocking, dropped when full)
+func (m *MarginMonitor) SetEventChannel(ch chan<- MarginAlert) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.eventChan = ch
+}
+
+// Level returns the current margin level
+func (m *MarginMonitor) Level() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.level
+}
+
+// Run feeds updates into the monitor until ctx is done or updates is closed
+// Receives nothing from GetPortfolioUpdateChannel while the client's Events stream is enabled
+func (m *MarginMonitor) Run(ctx context.Context, updates <-chan saxo.PortfolioUpdate) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			m.Update(update)
+		}
+	}
+}
+
+// Update evaluates one balance and alerts when the level changed
+func (m *MarginMonitor) Update(update saxo.PortfolioUpdate) {
+	m.mu.Lock()
+	previous := m.level
+	next := m.nextLevel(previous, update.MarginUtilizationPct)
+	if next == previous {
+		m.mu.Unlock()
+		return
+	}
+	m.level = next
+
+	now := m.clock.Now()
+	alert := MarginAlert{
+		Level:          next,
+		Previous:       previous,
+		UtilizationPct: update.MarginUtilizationPct,
+		Update:         update,
+		At:             now,
+	}
+	last, alerted := m.lastAlerted[next]
+	if next != MarginLevelCritical && alerted && now.Sub(last) < m.thresholds.Cooldown {
+		m.mu.Unlock()
+		m.logger.Debug("Margin alert suppressed by cooldown",
+			"function", "MarginMonitor.Update",
+			"level", next,
+			"utilization_pct", update.MarginUtilizationPct)
+		return
+	}
+	m.lastAlerted[next] = now
+	eventChan := m.eventChan
+	m.mu.Unlock()
+
+	log := m.logger.Info
+	if alert.Escalated() {
+		log = m.logger.Warn
+	}
+	log("Margin level changed",
+		"function", "MarginMonitor.Update",
+		"level", next,
+		"previous", previous,
+		"utilization_pct", update.MarginUtilizationPct)
+
+	if m.handler != nil {
+		m.handler(alert)
+	}
+	if eventChan != nil {
+		select {
+		case eventChan <- alert:
+		default:
+			m.logger.Warn("Margin alert channel full, dropping alert",
+				"function", "MarginMonitor.Update",
+				"level", next)
+		}
+	}
+}
+
+// nextLevel applies the thresholds with hysteresis: levels rise at the threshold
+// and only fall once utilization is HysteresisPct below it
+func (m *MarginMonitor) nextLevel(current string, pct float64) string {
+	t := m.thresholds
+	switch {
+	case pct >= t.CriticalPct:
+		return MarginLevelCritical
+	case current == MarginLevelCritical && pct > t.CriticalPct-t.HysteresisPct:
+		return MarginLevelCritical
+	case pct >= t.WarningPct:
+		return MarginLevelWarning
+	case current != MarginLevelNormal && pct > t.WarningPct-t.HysteresisPct:
+		return MarginLevelWarning
+	}
+	return MarginLevelNormal
+}
+
+func marginLevelRank(level string) int {
+	switch level {
+	case MarginLevelWarning:
+		return 1
+	case MarginLevelCritical:
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,65 @@
+package risk
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestMarginMonitor_LevelsWithHysteresisAndCooldown(t *testing.T) {
+	var alerts []MarginAlert
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	monitor := NewMarginMonitor(MarginThresholds{WarningPct: 60, CriticalPct: 80, HysteresisPct: 5, Cooldown: time.Minute},
+		func(alert MarginAlert) { alerts = append(alerts, alert) }, logger)
+	clock := mocktesting.NewFakeClock(time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC))
+	monitor.SetClock(clock)
+
+	feed := func(pct float64) {
+		monitor.Update(saxo.PortfolioUpdate{MarginUtilizationPct: pct})
+	}
+
+	feed(40)
+	feed(61)
+	feed(58) // Within hysteresis: stays Warning
+	if monitor.Level() != MarginLevelWarning || len(alerts) != 1 {
+		t.Fatalf("Expected one Warning alert, got level %s and %+v", monitor.Level(), alerts)
+	}
+	if !alerts[0].Escalated() || alerts[0].Previous != MarginLevelNormal {
+		t.Errorf("Unexpected first alert %+v", alerts[0])
+	}
+
+	clock.Advance(2 * time.Minute)
+	feed(85)
+	feed(77) // Within hysteresis: stays Critical
+	feed(70)
+	if len(alerts) != 3 || alerts[1].Level != MarginLevelCritical || alerts[2].Level != MarginLevelWarning {
+		t.Fatalf("Expected Critical then Warning alerts, got %+v", alerts)
+	}
+	if alerts[2].Escalated() {
+		t.Error("Critical -> Warning is not an escalation")
+	}
+
+	// Falling back is alerted, rising again within the cooldown is not
+	feed(50)
+	feed(65)
+	if monitor.Level() != MarginLevelWarning || len(alerts) != 4 {
+		t.Fatalf("Expected the repeated Warning to be suppressed, got level %s and %d alerts", monitor.Level(), len(alerts))
+	}
+
+	// Critical is never suppressed
+	feed(90)
+	if len(alerts) != 5 || alerts[4].Level != MarginLevelCritical {
+		t.Fatalf("Expected a second Critical alert, got %+v", alerts)
+	}
+
+	clock.Advance(2 * time.Minute)
+	feed(50)
+	feed(65)
+	if len(alerts) != 7 || alerts[6].Level != MarginLevelWarning {
+		t.Errorf("Expected Warning alert after the cooldown, got %d alerts", len(alerts))
+	}
+}
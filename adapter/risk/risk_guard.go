@@ -0,0 +1,395 @@
+// Package risk provides RiskGuard, a BrokerClient wrapper that enforces pre-trade limits locally
+// Violating orders are rejected with a *ViolationError before they reach the broker
+package risk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// Rules a ViolationError can break - test with errors.Is
+var (
+	ErrInstrumentNotAllowed = errors.New("instrument not allowed")
+	ErrOrderNotional        = errors.New("order notional above limit")
+	ErrNetPosition          = errors.New("net position above limit")
+	ErrOrderRate            = errors.New("order rate above limit")
+	ErrNoPrice              = errors.New("no price for notional check")
+)
+
+// ErrUnknownOrder rejects a ModifyOrder whose order (or related order) is not among the open orders,
+// since its limits cannot be checked
+var ErrUnknownOrder = errors.New("order not open")
+
+// ViolationError describes a rejected order
+type ViolationError struct {
+	Rule   error // One of the Err* rules above
+	Uic    int
+	Limit  float64
+	Actual float64
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("risk: %v (uic %d: %v, limit %v)", e.Rule, e.Uic, e.Actual, e.Limit)
+}
+
+func (e *ViolationError) Unwrap() error {
+	return e.Rule
+}
+
+// Limits configures RiskGuard - zero values disable a rule
+// Notional is size x price in the instrument's quote currency, no FX conversion
+type Limits struct {
+	MaxOrderNotional float64 // Per order
+	MaxNetPosition   float64 // Absolute units per UIC, default for UICs missing from MaxNetPositionByUic
+	MaxOrdersPerMin  int     // New and modified orders across all instruments, rolling minute
+
+	MaxNetPositionByUic map[int]float64 // Per-UIC override of MaxNetPosition
+
+	AllowedUics []int // When set, only these UICs can be traded
+	DeniedUics  []int // Never traded, wins over AllowedUics
+
+	Clock saxo.Clock // Order rate window, nil = saxo.SystemClock
+}
+
+// RiskGuard wraps a BrokerClient and checks PlaceOrder and ModifyOrder against Limits
+// CancelOrder always passes and ClosePosition passes unless it overshoots into a position above
+// the net position limit: reducing risk is never blocked.
+// Order calls are serialized so concurrent orders cannot jointly exceed a limit
+type RiskGuard struct {
+	saxo.BrokerClient
+	limits Limits
+	logger *slog.Logger
+
+	orderMu sync.Mutex // Held across check and placement
+	mu      sync.Mutex // Guards prices and orderTimes
+	prices  map[int]float64
+	allowed map[int]bool
+	denied  map[int]bool
+
+	orderTimes []time.Time // Accepted orders within the last minute
+}
+
+// NewRiskGuard wraps inner with limits
+func NewRiskGuard(inner saxo.BrokerClient, limits Limits, logger *slog.Logger) *RiskGuard {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if limits.Clock == nil {
+		limits.Clock = saxo.SystemClock
+	}
+	rg := &RiskGuard{
+		BrokerClient: inner,
+		limits:       limits,
+		logger:       logger,
+		prices:       make(map[int]float64),
+		denied:       make(map[int]bool),
+	}
+	if len(limits.AllowedUics) > 0 {
+		rg.allowed = make(map[int]bool)
+		for _, uic := range limits.AllowedUics {
+			rg.allowed[uic] = true
+		}
+	}
+	for _, uic := range limits.DeniedUics {
+		rg.denied[uic] = true
+	}
+	return rg
+}
+
+// UpdatePrice records the latest mid for notional checks on market orders
+// Without it, market orders look the price up with GetInstrumentPrice
+func (rg *RiskGuard) UpdatePrice(update saxo.PriceUpdate) {
+	price := update.Mid
+	if price == 0 {
+		price = (update.Bid + update.Ask) / 2
+	}
+	if price <= 0 {
+		return
+	}
+	rg.mu.Lock()
+	rg.prices[update.Uic] = price
+	rg.mu.Unlock()
+}
+
+// PlaceOrder implements BrokerClient.PlaceOrder with all limits checked first
+func (rg *RiskGuard) PlaceOrder(ctx context.Context, req saxo.OrderRequest) (*saxo.OrderResponse, error) {
+	rg.orderMu.Lock()
+	defer rg.orderMu.Unlock()
+
+	uic := instrumentUic(req.Instrument)
+	if err := rg.checkInstrument(uic); err != nil {
+		return nil, rg.reject("PlaceOrder", err)
+	}
+	if err := rg.checkRate(uic); err != nil {
+		return nil, rg.reject("PlaceOrder", err)
+	}
+	// CashAmount orders are converted to units at the order price for the size based limits
+	size := float64(req.Size)
+	if rg.limits.MaxOrderNotional > 0 || (req.IsCashAmount() && rg.netPositionLimit(uic) > 0) {
+		price, err := rg.orderPrice(ctx, req)
+		if err != nil {
+			return nil, rg.reject("PlaceOrder", err)
+		}
+		if req.IsCashAmount() {
+			size = req.CashAmount / price
+		}
+		if err := rg.checkNotional(uic, size, price); err != nil {
+			return nil, rg.reject("PlaceOrder", err)
+		}
+	}
+	if err := rg.checkNetPosition(ctx, uic, signed(req.Side, size), ""); err != nil {
+		return nil, rg.reject("PlaceOrder", err)
+	}
+
+	resp, err := rg.BrokerClient.PlaceOrder(ctx, req)
+	if err == nil {
+		rg.recordOrder()
+	}
+	return resp, err
+}
+
+// ModifyOrder implements BrokerClient.ModifyOrder
+// Price and amount changes are checked against the working orders they modify, RelatedOrders included
+func (rg *RiskGuard) ModifyOrder(ctx context.Context, req saxo.OrderModificationRequest) (*saxo.OrderResponse, error) {
+	rg.orderMu.Lock()
+	defer rg.orderMu.Unlock()
+
+	orders, err := rg.BrokerClient.GetOpenOrders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("risk: failed to get open orders: %w", err)
+	}
+	// Every order must be listed - an unlisted one could not be checked and is rejected uncounted
+	order := findOrder(orders, req.OrderID)
+	if order == nil {
+		return nil, rg.reject("ModifyOrder", fmt.Errorf("risk: %w: %s", ErrUnknownOrder, req.OrderID))
+	}
+	related := make([]*saxo.LiveOrder, len(req.RelatedOrders))
+	for i, modification := range req.RelatedOrders {
+		if related[i] = findOrder(orders, modification.OrderID); related[i] == nil {
+			return nil, rg.reject("ModifyOrder", fmt.Errorf("risk: %w: %s", ErrUnknownOrder, modification.OrderID))
+		}
+	}
+
+	if err := rg.checkInstrument(order.Uic); err != nil {
+		return nil, rg.reject("ModifyOrder", err)
+	}
+	if err := rg.checkRate(order.Uic); err != nil {
+		return nil, rg.reject("ModifyOrder", err)
+	}
+	if err := rg.checkModification(ctx, order, req.Amount, req.OrderPrice); err != nil {
+		return nil, rg.reject("ModifyOrder", err)
+	}
+	for i, modification := range req.RelatedOrders {
+		if err := rg.checkModification(ctx, related[i], modification.Amount, modification.OrderPrice); err != nil {
+			return nil, rg.reject("ModifyOrder", err)
+		}
+	}
+
+	resp, err := rg.BrokerClient.ModifyOrder(ctx, req)
+	if err == nil {
+		rg.recordOrder()
+	}
+	return resp, err
+}
+
+// ClosePosition implements BrokerClient.ClosePosition
+// Only the net position limit applies, so a close larger than the position cannot open one above
+// it; a close that reduces the position always passes
+func (rg *RiskGuard) ClosePosition(ctx context.Context, req saxo.ClosePositionRequest) (*saxo.OrderResponse, error) {
+	rg.orderMu.Lock()
+	defer rg.orderMu.Unlock()
+
+	// BuySell is the side of the position, the close trades the opposite way
+	if err := rg.checkNetPosition(ctx, req.Uic, -signed(req.BuySell, req.Amount), ""); err != nil {
+		return nil, rg.reject("ClosePosition", err)
+	}
+	return rg.BrokerClient.ClosePosition(ctx, req)
+}
+
+// checkModification checks order with its new amount and price (0 and "" keep the current ones)
+func (rg *RiskGuard) checkModification(ctx context.Context, order *saxo.LiveOrder, newAmount float64, newPrice string) error {
+	amount, price := order.Amount, order.Price
+	if newAmount > 0 {
+		amount = newAmount
+	}
+	if newPrice != "" {
+		if parsed, err := strconv.ParseFloat(newPrice, 64); err == nil {
+			price = parsed
+		}
+	}
+	if price > 0 {
+		if err := rg.checkNotional(order.Uic, amount, price); err != nil {
+			return err
+		}
+	}
+	// Exit legs add no exposure (see checkNetPosition)
+	if newAmount > 0 && isEntry(order.OrderRelation) {
+		return rg.checkNetPosition(ctx, order.Uic, signed(order.BuySell, amount), order.OrderID)
+	}
+	return nil
+}
+
+func (rg *RiskGuard) checkInstrument(uic int) error {
+	if rg.denied[uic] || (rg.allowed != nil && !rg.allowed[uic]) {
+		return &ViolationError{Rule: ErrInstrumentNotAllowed, Uic: uic}
+	}
+	return nil
+}
+
+func (rg *RiskGuard) checkRate(uic int) error {
+	if rg.limits.MaxOrdersPerMin <= 0 {
+		return nil
+	}
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	cutoff := rg.limits.Clock.Now().Add(-time.Minute)
+	recent := rg.orderTimes[:0]
+	for _, placed := range rg.orderTimes {
+		if placed.After(cutoff) {
+			recent = append(recent, placed)
+		}
+	}
+	rg.orderTimes = recent
+	if len(recent) >= rg.limits.MaxOrdersPerMin {
+		return &ViolationError{Rule: ErrOrderRate, Uic: uic,
+			Limit: float64(rg.limits.MaxOrdersPerMin), Actual: float64(len(recent) + 1)}
+	}
+	return nil
+}
+
+func (rg *RiskGuard) recordOrder() {
+	if rg.limits.MaxOrdersPerMin <= 0 {
+		return
+	}
+	rg.mu.Lock()
+	rg.orderTimes = append(rg.orderTimes, rg.limits.Clock.Now())
+	rg.mu.Unlock()
+}
+
+func (rg *RiskGuard) checkNotional(uic int, amount, price float64) error {
+	if rg.limits.MaxOrderNotional <= 0 {
+		return nil
+	}
+	if notional := amount * price; notional > rg.limits.MaxOrderNotional {
+		return &ViolationError{Rule: ErrOrderNotional, Uic: uic, Limit: rg.limits.MaxOrderNotional, Actual: notional}
+	}
+	return nil
+}
+
+// netPositionLimit returns the net position limit for uic, 0 = unlimited
+func (rg *RiskGuard) netPositionLimit(uic int) float64 {
+	if override, ok := rg.limits.MaxNetPositionByUic[uic]; ok {
+		return override
+	}
+	return rg.limits.MaxNetPosition
+}
+
+// checkNetPosition projects the net position if delta and every working entry order on uic filled
+// excludeOrderID leaves out the order being modified (delta replaces it)
+func (rg *RiskGuard) checkNetPosition(ctx context.Context, uic int, delta float64, excludeOrderID string) error {
+	limit := rg.netPositionLimit(uic)
+	if limit <= 0 {
+		return nil
+	}
+
+	positions, err := rg.BrokerClient.GetNetPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("risk: failed to get net positions: %w", err)
+	}
+	projected := delta
+	for _, position := range positions.Data {
+		if position.NetPositionBase.Uic == uic {
+			projected += position.NetPositionBase.Amount
+		}
+	}
+
+	orders, err := rg.BrokerClient.GetOpenOrdersFiltered(ctx, saxo.OpenOrdersParams{Uic: uic})
+	if err != nil {
+		return fmt.Errorf("risk: failed to get open orders: %w", err)
+	}
+	for _, order := range orders {
+		// Exit legs (IfDoneSlave, Oco) close the entry they belong to - they add no exposure
+		if order.OrderID == excludeOrderID || order.Uic != uic || !isEntry(order.OrderRelation) {
+			continue
+		}
+		projected += signed(order.BuySell, order.Amount)
+	}
+
+	// An order that shrinks the projection always passes, even while above the limit
+	if math.Abs(projected) > limit && math.Abs(projected) > math.Abs(projected-delta) {
+		return &ViolationError{Rule: ErrNetPosition, Uic: uic, Limit: limit, Actual: math.Abs(projected)}
+	}
+	return nil
+}
+
+// orderPrice is the order's own price, else the latest UpdatePrice mid, else a live quote
+// Without a positive price the order is rejected (ErrNoPrice) - a notional of 0 would pass every limit
+func (rg *RiskGuard) orderPrice(ctx context.Context, req saxo.OrderRequest) (float64, error) {
+	if req.OrderType != "Market" && req.Price > 0 {
+		return req.Price, nil
+	}
+	rg.mu.Lock()
+	price, ok := rg.prices[instrumentUic(req.Instrument)]
+	rg.mu.Unlock()
+	if ok {
+		return price, nil
+	}
+
+	quote, err := rg.BrokerClient.GetInstrumentPrice(ctx, req.Instrument)
+	if err != nil {
+		return 0, fmt.Errorf("risk: %w: %w", ErrNoPrice, err)
+	}
+	price = quote.Mid
+	if price <= 0 && quote.Bid > 0 && quote.Ask > 0 {
+		price = (quote.Bid + quote.Ask) / 2
+	}
+	if price <= 0 {
+		return 0, &ViolationError{Rule: ErrNoPrice, Uic: instrumentUic(req.Instrument), Actual: price}
+	}
+	return price, nil
+}
+
+// findOrder returns the order with orderID from orders, nil when missing
+func findOrder(orders []saxo.LiveOrder, orderID string) *saxo.LiveOrder {
+	for i := range orders {
+		if orders[i].OrderID == orderID {
+			return &orders[i]
+		}
+	}
+	return nil
+}
+
+func (rg *RiskGuard) reject(function string, err error) error {
+	rg.logger.Warn("Order rejected by risk guard",
+		"function", function,
+		"error", err)
+	return err
+}
+
+// instrumentUic returns the instrument's UIC - brokers enrich either Uic or Identifier
+func instrumentUic(instrument saxo.Instrument) int {
+	if instrument.Uic != 0 {
+		return instrument.Uic
+	}
+	return instrument.Identifier
+}
+
+func isEntry(relation string) bool {
+	return relation == "" || relation == "StandAlone" || relation == "IfDoneMaster"
+}
+
+func signed(side string, amount float64) float64 {
+	if side == "Sell" {
+		return -amount
+	}
+	return amount
+}
@@ -0,0 +1,259 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/brokerclienttest"
+	"github.com/bjoelf/saxo-adapter/adapter/paper"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+var eurusd = saxo.Instrument{Ticker: "EURUSD", Identifier: 21, Uic: 21, AssetType: "FxSpot"}
+
+func newTestGuard(t *testing.T, limits Limits) (*RiskGuard, *paper.PaperBrokerClient) {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	broker := paper.NewPaperBrokerClient(paper.Config{InitialBalance: 1000000}, logger)
+	t.Cleanup(func() { broker.Shutdown(context.Background()) })
+	broker.UpdatePrice(saxo.PriceUpdate{Uic: 21, Bid: 1.1000, Ask: 1.1002, Mid: 1.1001})
+	return NewRiskGuard(broker, limits, logger), broker
+}
+
+func limitBuy(size int, price float64) saxo.OrderRequest {
+	return saxo.OrderRequest{Instrument: eurusd, Side: "Buy", Size: size, Price: price, OrderType: "Limit"}
+}
+
+func expectViolation(t *testing.T, err error, rule error) {
+	t.Helper()
+	var violation *ViolationError
+	if !errors.As(err, &violation) || !errors.Is(err, rule) {
+		t.Fatalf("Expected %v violation, got %v", rule, err)
+	}
+}
+
+func TestRiskGuard_InstrumentLists(t *testing.T) {
+	guard, _ := newTestGuard(t, Limits{AllowedUics: []int{21, 22}, DeniedUics: []int{22}})
+	ctx := context.Background()
+
+	if _, err := guard.PlaceOrder(ctx, limitBuy(1000, 1.0)); err != nil {
+		t.Fatalf("Allowed instrument rejected: %v", err)
+	}
+	gbpusd := limitBuy(1000, 1.0)
+	gbpusd.Instrument = saxo.Instrument{Ticker: "GBPUSD", Identifier: 22, AssetType: "FxSpot"}
+	_, err := guard.PlaceOrder(ctx, gbpusd)
+	expectViolation(t, err, ErrInstrumentNotAllowed)
+
+	usdjpy := limitBuy(1000, 1.0)
+	usdjpy.Instrument = saxo.Instrument{Ticker: "USDJPY", Identifier: 42, AssetType: "FxSpot"}
+	_, err = guard.PlaceOrder(ctx, usdjpy)
+	expectViolation(t, err, ErrInstrumentNotAllowed)
+
+	// Instruments enriched on Uic only are checked the same way
+	gbpusd.Instrument = saxo.Instrument{Ticker: "GBPUSD", Uic: 22, AssetType: "FxSpot"}
+	_, err = guard.PlaceOrder(ctx, gbpusd)
+	expectViolation(t, err, ErrInstrumentNotAllowed)
+}
+
+func TestRiskGuard_OrderNotional(t *testing.T) {
+	guard, _ := newTestGuard(t, Limits{MaxOrderNotional: 50000})
+	ctx := context.Background()
+
+	_, err := guard.PlaceOrder(ctx, limitBuy(100000, 1.0))
+	expectViolation(t, err, ErrOrderNotional)
+
+	// Market orders are priced from UpdatePrice
+	guard.UpdatePrice(saxo.PriceUpdate{Uic: 21, Bid: 1.1000, Ask: 1.1002, Mid: 1.1001})
+	_, err = guard.PlaceOrder(ctx, saxo.OrderRequest{Instrument: eurusd, Side: "Sell", Size: 50000, OrderType: "Market"})
+	expectViolation(t, err, ErrOrderNotional)
+
+	// Modifications are checked against the working order
+	placed, err := guard.PlaceOrder(ctx, limitBuy(40000, 1.0))
+	if err != nil {
+		t.Fatalf("PlaceOrder within limit failed: %v", err)
+	}
+	_, err = guard.ModifyOrder(ctx, saxo.OrderModificationRequest{OrderID: placed.OrderID, Amount: 60000})
+	expectViolation(t, err, ErrOrderNotional)
+	if _, err := guard.ModifyOrder(ctx, saxo.OrderModificationRequest{OrderID: placed.OrderID, OrderPrice: "0.9"}); err != nil {
+		t.Errorf("Modification within limit failed: %v", err)
+	}
+}
+
+// zeroQuoteBroker answers GetInstrumentPrice without a price, like a closed market
+type zeroQuoteBroker struct {
+	saxo.BrokerClient
+}
+
+func (b zeroQuoteBroker) GetInstrumentPrice(ctx context.Context, instrument saxo.Instrument) (*saxo.PriceData, error) {
+	return &saxo.PriceData{Ticker: instrument.Ticker}, nil
+}
+
+func TestRiskGuard_NoPrice(t *testing.T) {
+	_, broker := newTestGuard(t, Limits{})
+	guard := NewRiskGuard(zeroQuoteBroker{broker}, Limits{MaxOrderNotional: 50000}, nil)
+
+	_, err := guard.PlaceOrder(context.Background(), saxo.OrderRequest{Instrument: eurusd, Side: "Buy", Size: 1000000, OrderType: "Market"})
+	expectViolation(t, err, ErrNoPrice)
+
+	// CashAmount orders are converted to units at the price, so they need one for both limits
+	cashGuard := NewRiskGuard(zeroQuoteBroker{broker}, Limits{MaxOrderNotional: 50000, MaxNetPosition: 100000}, nil)
+	cash := saxo.OrderRequest{Instrument: eurusd, Side: "Buy", OrderType: "Market",
+		OrderAmountType: saxo.AmountTypeCashAmount, CashAmount: 1e9}
+	_, err = cashGuard.PlaceOrder(context.Background(), cash)
+	expectViolation(t, err, ErrNoPrice)
+}
+
+func TestRiskGuard_CashAmount(t *testing.T) {
+	guard, _ := newTestGuard(t, Limits{MaxOrderNotional: 50000})
+	cash := saxo.OrderRequest{Instrument: eurusd, Side: "Buy", OrderType: "Limit", Price: 1.2,
+		OrderAmountType: saxo.AmountTypeCashAmount, CashAmount: 60000}
+	_, err := guard.PlaceOrder(context.Background(), cash)
+	expectViolation(t, err, ErrOrderNotional)
+}
+
+func TestRiskGuard_NetPosition(t *testing.T) {
+	guard, _ := newTestGuard(t, Limits{MaxNetPosition: 100000, MaxNetPositionByUic: map[int]float64{21: 30000}})
+	ctx := context.Background()
+
+	// Filled position counts
+	if _, err := guard.PlaceOrder(ctx, saxo.OrderRequest{Instrument: eurusd, Side: "Buy", Size: 20000, OrderType: "Market"}); err != nil {
+		t.Fatalf("Market order failed: %v", err)
+	}
+	// Working entry orders count too
+	if _, err := guard.PlaceOrder(ctx, limitBuy(10000, 1.0)); err != nil {
+		t.Fatalf("Limit order failed: %v", err)
+	}
+	_, err := guard.PlaceOrder(ctx, limitBuy(1000, 1.0))
+	expectViolation(t, err, ErrNetPosition)
+
+	// Selling reduces exposure and passes
+	if _, err := guard.PlaceOrder(ctx, saxo.OrderRequest{Instrument: eurusd, Side: "Sell", Size: 20000, OrderType: "Market"}); err != nil {
+		t.Errorf("Exposure-reducing order rejected: %v", err)
+	}
+}
+
+// workingOrdersBroker lists fixed working orders and counts modifications reaching it
+type workingOrdersBroker struct {
+	saxo.BrokerClient
+	orders   []saxo.LiveOrder
+	modified int
+}
+
+func (b *workingOrdersBroker) GetOpenOrders(ctx context.Context) ([]saxo.LiveOrder, error) {
+	return b.orders, nil
+}
+
+func (b *workingOrdersBroker) ModifyOrder(ctx context.Context, req saxo.OrderModificationRequest) (*saxo.OrderResponse, error) {
+	b.modified++
+	return &saxo.OrderResponse{OrderID: req.OrderID}, nil
+}
+
+func TestRiskGuard_ModifyRelatedOrders(t *testing.T) {
+	_, paperBroker := newTestGuard(t, Limits{})
+	broker := &workingOrdersBroker{BrokerClient: paperBroker, orders: []saxo.LiveOrder{
+		{OrderID: "entry", Uic: 21, BuySell: "Buy", Amount: 10000, Price: 1.1, OrderRelation: "IfDoneMaster"},
+		{OrderID: "target", Uic: 21, BuySell: "Sell", Amount: 10000, Price: 1.2, OrderRelation: "IfDoneSlave"},
+	}}
+	guard := NewRiskGuard(broker, Limits{MaxOrderNotional: 50000}, nil)
+	ctx := context.Background()
+
+	// The target grows above the notional limit in the same request as an entry price change
+	_, err := guard.ModifyOrder(ctx, saxo.OrderModificationRequest{OrderID: "entry", OrderPrice: "1.09",
+		RelatedOrders: []saxo.RelatedOrderModification{{OrderID: "target", Amount: 50000}}})
+	expectViolation(t, err, ErrOrderNotional)
+	if broker.modified != 0 {
+		t.Fatal("Expected the violating modification to be blocked")
+	}
+
+	if _, err := guard.ModifyOrder(ctx, saxo.OrderModificationRequest{OrderID: "entry", OrderPrice: "1.09",
+		RelatedOrders: []saxo.RelatedOrderModification{{OrderID: "target", OrderPrice: "1.21"}}}); err != nil {
+		t.Errorf("Modification within limit failed: %v", err)
+	}
+}
+
+func TestRiskGuard_ModifyUnknownOrder(t *testing.T) {
+	_, paperBroker := newTestGuard(t, Limits{})
+	broker := &workingOrdersBroker{BrokerClient: paperBroker, orders: []saxo.LiveOrder{
+		{OrderID: "entry", Uic: 21, BuySell: "Buy", Amount: 10000, Price: 1.1, OrderRelation: "IfDoneMaster"},
+	}}
+	guard := NewRiskGuard(broker, Limits{MaxOrderNotional: 50000, MaxOrdersPerMin: 1}, nil)
+	ctx := context.Background()
+
+	_, err := guard.ModifyOrder(ctx, saxo.OrderModificationRequest{OrderID: "gone", Amount: 1e9})
+	if !errors.Is(err, ErrUnknownOrder) {
+		t.Fatalf("Expected ErrUnknownOrder, got %v", err)
+	}
+	_, err = guard.ModifyOrder(ctx, saxo.OrderModificationRequest{OrderID: "entry", OrderPrice: "1.09",
+		RelatedOrders: []saxo.RelatedOrderModification{{OrderID: "gone", Amount: 1e9}}})
+	if !errors.Is(err, ErrUnknownOrder) {
+		t.Fatalf("Expected ErrUnknownOrder for a related order, got %v", err)
+	}
+	if broker.modified != 0 {
+		t.Fatal("Expected unknown orders to be blocked")
+	}
+
+	// Rejected modifications do not use up the rate window
+	if _, err := guard.ModifyOrder(ctx, saxo.OrderModificationRequest{OrderID: "entry", OrderPrice: "1.09"}); err != nil {
+		t.Errorf("Modification of a listed order failed: %v", err)
+	}
+}
+
+func TestRiskGuard_ClosePosition(t *testing.T) {
+	guard, _ := newTestGuard(t, Limits{MaxNetPosition: 30000, DeniedUics: []int{21}})
+	ctx := context.Background()
+	unguarded := NewRiskGuard(guard.BrokerClient, Limits{}, nil)
+	if _, err := unguarded.PlaceOrder(ctx, saxo.OrderRequest{Instrument: eurusd, Side: "Buy", Size: 20000, OrderType: "Market"}); err != nil {
+		t.Fatalf("Market order failed: %v", err)
+	}
+
+	// A close larger than the position would open a short above the limit
+	_, err := guard.ClosePosition(ctx, saxo.ClosePositionRequest{Uic: 21, AssetType: "FxSpot", Amount: 60000, BuySell: "Buy"})
+	expectViolation(t, err, ErrNetPosition)
+
+	// Closing the position passes, denied instrument or not
+	if _, err := guard.ClosePosition(ctx, saxo.ClosePositionRequest{Uic: 21, AssetType: "FxSpot", Amount: 20000, BuySell: "Buy"}); err != nil {
+		t.Errorf("Exposure-reducing close rejected: %v", err)
+	}
+}
+
+func TestRiskGuard_OrderRate(t *testing.T) {
+	clock := mocktesting.NewFakeClock(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	guard, _ := newTestGuard(t, Limits{MaxOrdersPerMin: 2, Clock: clock})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := guard.PlaceOrder(ctx, limitBuy(1000, 1.0)); err != nil {
+			t.Fatalf("Order %d failed: %v", i, err)
+		}
+	}
+	_, err := guard.PlaceOrder(ctx, limitBuy(1000, 1.0))
+	expectViolation(t, err, ErrOrderRate)
+
+	// Cancels are never limited
+	orders, _ := guard.GetOpenOrders(ctx)
+	if err := guard.CancelOrder(ctx, saxo.CancelOrderRequest{OrderID: orders[0].OrderID}); err != nil {
+		t.Errorf("CancelOrder rejected: %v", err)
+	}
+
+	clock.Advance(61 * time.Second)
+	if _, err := guard.PlaceOrder(ctx, limitBuy(1000, 1.0)); err != nil {
+		t.Errorf("Order after the window failed: %v", err)
+	}
+}
+
+// A guard with room to spare must behave exactly like the client it wraps
+func TestRiskGuard_Conformance(t *testing.T) {
+	brokerclienttest.Run(t, brokerclienttest.Harness{
+		New: func(t *testing.T) saxo.BrokerClient {
+			guard, _ := newTestGuard(t, Limits{MaxOrderNotional: 1e9, MaxNetPosition: 1e9, MaxOrdersPerMin: 1000})
+			return guard
+		},
+		Instrument:   eurusd,
+		RestingPrice: 0.5,
+	})
+}
@@ -8,19 +8,25 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
 // CreateBrokerServices creates Saxo broker client with injected auth client
 // Following dependency injection pattern like NewSaxoWebSocketClient()
-func CreateBrokerServices(authClient AuthClient, logger *slog.Logger) (BrokerClient, error) {
+// Only Provider, BaseURL and Timeout of config are used - empty values come from authClient
+func CreateBrokerServices(authClient AuthClient, config SaxoConfig, logger *slog.Logger) (BrokerClient, error) {
 	// Start authentication keeper if already authenticated (legacy WebSocket lifecycle pattern)
 	if authClient.IsAuthenticated() {
-		provider := os.Getenv("PROVIDER")
+		provider := config.Provider
+		if named, ok := authClient.(interface{ Provider() string }); ok && provider == "" {
+			provider = named.Provider()
+		}
 		if provider == "" {
-			provider = "saxo"
+			provider = DefaultProvider
 		}
 		authClient.StartAuthenticationKeeper(provider)
 		logger.Info("Authentication keeper started",
@@ -29,11 +35,15 @@ func CreateBrokerServices(authClient AuthClient, logger *slog.Logger) (BrokerCli
 	} else {
 		logger.Info("Not authenticated",
 			"function", "CreateBrokerServices",
-			"message", "use /broker/login to authenticate")
+			"message", "call Login, or POST /broker/login when served by adapter/server")
 	}
 
 	// Create broker client (adapter layer)
-	brokerClient := NewSaxoBrokerClient(authClient, authClient.GetBaseURL(), logger)
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = authClient.GetBaseURL()
+	}
+	brokerClient := NewSaxoBrokerClient(authClient, baseURL, logger, config.clientOptions()...)
 
 	return brokerClient, nil
 }
@@ -50,22 +60,104 @@ type SaxoBrokerClient struct {
 	authClient AuthClient
 	baseURL    string
 	logger     *slog.Logger
+	clock      Clock // Cache expiry and market hours (WithClock)
+
+	// Construction options (see options.go)
+	httpClient   *http.Client // nil = authClient.GetHTTPClient
+	timeout      time.Duration
+	userAgent    string
+	interceptors []Interceptor
+
+	// WithDryRun: order mutations are validated and logged, never sent
+	dryRun         bool
+	dryRunPrecheck bool
+
+	killSwitch *KillSwitch
+
+	// WithManualOrders: ManualOrder flag of requests that leave it nil
+	manualOrders bool
+
+	// WithClientKey: client the portfolio queries are scoped to, "" = token owner (/me endpoints)
+	clientKey string
+
+	// WithRejectOrderWarnings: warning classes that fail PlaceOrder before the order is sent
+	rejectWarnings []string
+
+	// WithDefaultAccount / SetDefaultAccount: account GetBalance reports, "" = all accounts
+	defaultAccount   string
+	defaultAccountMu sync.RWMutex
+
+	// WithOrderValidation: lot and tick constraints per UIC
+	orderValidation   OrderValidation
+	instrumentDetails *instrumentDetailsCache
+
+	// WithInstrumentStore: ticker enrichment, updated from GetInstrumentDetails (nil = none)
+	instrumentStore *InstrumentStore
+
+	// WithHistoricalPriceSide: primary OHLC series of bid/ask quoted history, "" = mid
+	priceSide PriceSide
+
+	// WithBarCutoff / WithInstrumentBarCutoff: daily bar end when GetHistoricalData gets no cutoffTime
+	barCutoff            BarCutoff
+	instrumentBarCutoffs map[int]BarCutoff
 
 	// Historical data cache following legacy SinglePivotHistory caching pattern
 	historyCache map[string]*cachedHistoricalData
 	cacheMutex   sync.RWMutex
 	cacheExpiry  time.Duration // Default: 1 hour like legacy system
+
+	// Trading schedules per instrument per UTC day for IsMarketOpen
+	scheduleCache   map[string]*cachedSchedule
+	scheduleCacheMu sync.RWMutex
+
+	// Read-through cache for client info, accounts and balance (see InvalidateCache)
+	responseCache *responseCache
+
+	// Latest X-RateLimit-* headers and Retry-After (RateLimits, HistoryDownloader)
+	rateLimits *rateLimitTracker
+
+	// Last known session capabilities for SessionCapabilityEvents
+	sessionCapabilities *sessionCapabilityState
 }
 
 // NewSaxoBrokerClient creates a new Saxo broker client
-func NewSaxoBrokerClient(authClient AuthClient, baseURL string, logger *slog.Logger) *SaxoBrokerClient {
-	return &SaxoBrokerClient{
-		authClient:   authClient,
-		baseURL:      baseURL,
-		logger:       logger,
-		historyCache: make(map[string]*cachedHistoricalData),
-		cacheExpiry:  1 * time.Hour, // Following legacy 1-hour cache pattern
-	}
+// Optional settings (HTTP client, timeout, user agent, base URL, logger) are applied via opts
+func NewSaxoBrokerClient(authClient AuthClient, baseURL string, logger *slog.Logger, opts ...Option) *SaxoBrokerClient {
+	o := NewClientOptions(baseURL, logger, opts...)
+	cacheTTLs := DefaultCacheTTLs()
+	if o.CacheTTLs != nil {
+		cacheTTLs = *o.CacheTTLs
+	}
+	sbc := &SaxoBrokerClient{
+		authClient:           authClient,
+		baseURL:              o.BaseURL,
+		logger:               o.Logger,
+		clock:                o.Clock,
+		httpClient:           o.ResolveHTTPClient(),
+		timeout:              o.Timeout,
+		userAgent:            o.UserAgent,
+		interceptors:         o.Interceptors,
+		dryRun:               o.DryRun,
+		dryRunPrecheck:       o.DryRunPrecheck,
+		historyCache:         make(map[string]*cachedHistoricalData),
+		scheduleCache:        make(map[string]*cachedSchedule),
+		responseCache:        newResponseCache(cacheTTLs, o.Clock),
+		rateLimits:           newRateLimitTracker(),
+		orderValidation:      o.OrderValidation,
+		instrumentDetails:    newInstrumentDetailsCache(cacheTTLs.InstrumentDetails, o.Clock),
+		instrumentStore:      o.InstrumentStore,
+		rejectWarnings:       o.RejectWarnings,
+		manualOrders:         o.ManualOrders,
+		clientKey:            o.ClientKey,
+		priceSide:            o.HistoricalPriceSide,
+		barCutoff:            o.BarCutoff,
+		instrumentBarCutoffs: o.InstrumentBarCutoffs,
+		defaultAccount:       o.DefaultAccount,
+		sessionCapabilities:  newSessionCapabilityState(o.Clock),
+		cacheExpiry:          1 * time.Hour, // Following legacy 1-hour cache pattern
+	}
+	sbc.killSwitch = newKillSwitch(sbc, o.Clock, o.Logger)
+	return sbc
 }
 
 // PlaceOrder implements BrokerClient.PlaceOrder
@@ -77,9 +169,19 @@ func (sbc *SaxoBrokerClient) PlaceOrder(ctx context.Context, req OrderRequest) (
 		"order_type", req.OrderType,
 		"side", req.Side)
 
+	if err := sbc.killSwitch.check(); err != nil {
+		return nil, err
+	}
+
 	// Check authentication
 	if !sbc.authClient.IsAuthenticated() {
-		return nil, fmt.Errorf("not authenticated with broker")
+		return nil, sbc.notAuthenticated()
+	}
+
+	// Check (or round) Size and prices against LotSize and TickSize when enabled
+	req, err := sbc.validateOrder(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
 	// Convert generic OrderRequest to Saxo-specific format
@@ -87,6 +189,12 @@ func (sbc *SaxoBrokerClient) PlaceOrder(ctx context.Context, req OrderRequest) (
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert order request: %w", err)
 	}
+	if sbc.dryRun {
+		if err := validateDryRunOrder(req); err != nil {
+			return nil, fmt.Errorf("invalid order request: %w", err)
+		}
+		return sbc.dryRunOrder(ctx, "PlaceOrder", saxoReq, req.OrderType, req.Side, len(req.RelatedOrders))
+	}
 
 	// Marshal request body (supports both single and multi-leg orders)
 	reqBody, err := json.Marshal(saxoReq)
@@ -94,6 +202,11 @@ func (sbc *SaxoBrokerClient) PlaceOrder(ctx context.Context, req OrderRequest) (
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	// Fail fast on rejected warning classes: precheck first, nothing is placed when one applies
+	if err := sbc.checkRejectedWarnings(ctx, reqBody, req.Side); err != nil {
+		return nil, err
+	}
+
 	// Log request payload for debugging
 	sbc.logger.Debug("Order request payload",
 		"function", "PlaceOrder",
@@ -109,7 +222,7 @@ func (sbc *SaxoBrokerClient) PlaceOrder(ctx context.Context, req OrderRequest) (
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
 	// Execute request with OAuth2 auto-refresh
-	resp, err := sbc.doRequest(ctx, httpReq)
+	resp, err := sbc.doRequest(orderRequest(ctx), httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -150,6 +263,14 @@ func (sbc *SaxoBrokerClient) PlaceOrder(ctx context.Context, req OrderRequest) (
 		"function", "PlaceOrder",
 		"order_id", genericResp.OrderID,
 		"status", genericResp.Status)
+	for _, warning := range genericResp.Warnings {
+		sbc.logger.Warn("Order accepted with warning",
+			"function", "PlaceOrder",
+			"order_id", genericResp.OrderID,
+			"class", warning.Class,
+			"code", warning.Code,
+			"message", warning.Message)
+	}
 
 	return genericResp, nil
 }
@@ -164,7 +285,13 @@ func (sbc *SaxoBrokerClient) CancelOrder(ctx context.Context, req CancelOrderReq
 
 	// Check authentication
 	if !sbc.authClient.IsAuthenticated() {
-		return fmt.Errorf("not authenticated with broker")
+		return sbc.notAuthenticated()
+	}
+	if sbc.dryRun {
+		sbc.logger.Info("Dry run: cancellation not sent",
+			"function", "CancelOrder",
+			"order_id", req.OrderID)
+		return nil
 	}
 
 	// Build URL with query parameters following Saxo API documentation
@@ -213,13 +340,14 @@ func (sbc *SaxoBrokerClient) CancelOrder(ctx context.Context, req CancelOrderReq
 }
 
 // ClosePosition implements BrokerClient.ClosePosition
-// Closes position by placing an opposite market order
+// Closes position by placing an opposite market order, chosen by the client's netting mode (GetClientDetails)
 //
-// For accounts with Real-time (Intraday) netting: Opposing positions are netted immediately
-// For accounts with End-of-Day netting: Positions are netted overnight
+// For accounts with Real-time (Intraday) netting: Opposing positions are netted immediately,
+// the order is sent with IsForceOpen=false
+// For accounts with End-of-Day netting: The order references req.PositionID so it closes that
+// position instead of opening a new one. Without PositionID it is netted overnight
 //
 // Note: Real-time netting does NOT support relating orders to positions.
-// Therefore we use a simple opposite market order which works for both netting modes.
 // Reference: https://www.developer.saxo/openapi/learn/fifo-real-time-netting
 func (sbc *SaxoBrokerClient) ClosePosition(ctx context.Context, req ClosePositionRequest) (*OrderResponse, error) {
 	sbc.logger.Info("Closing position",
@@ -230,32 +358,17 @@ func (sbc *SaxoBrokerClient) ClosePosition(ctx context.Context, req ClosePositio
 
 	// Check authentication
 	if !sbc.authClient.IsAuthenticated() {
-		return nil, fmt.Errorf("not authenticated with broker")
+		return nil, sbc.notAuthenticated()
 	}
 
-	// Determine opposite direction to close position
-	// If position is Buy (long), we need to Sell to close
-	// If position is Sell (short), we need to Buy to close
-	oppositeSide := "Sell"
-	if req.BuySell == "Sell" {
-		oppositeSide = "Buy"
-	}
+	// PositionId close under end-of-day netting, else an opposite order netting the position
+	closeOrder := sbc.closeOrderFor(ctx, req)
+	oppositeSide := closeOrder.BuySell
 
-	// Build simple market order to close position
-	// This works for both real-time and end-of-day netting
-	closeOrder := SaxoOrderRequest{
-		AccountKey:  req.AccountKey,
-		Uic:         req.Uic,
-		AssetType:   req.AssetType,
-		BuySell:     oppositeSide,
-		Amount:      req.Amount,
-		OrderType:   "Market",
-		ManualOrder: true, // Manual order - user clicked Close Position button
+	if sbc.dryRun {
+		return sbc.dryRunOrder(ctx, "ClosePosition", closeOrder, "Market", oppositeSide, 0)
 	}
 
-	// Set order duration
-	closeOrder.OrderDuration.DurationType = "DayOrder"
-
 	// Marshal request body
 	reqBody, err := json.Marshal(closeOrder)
 	if err != nil {
@@ -265,7 +378,8 @@ func (sbc *SaxoBrokerClient) ClosePosition(ctx context.Context, req ClosePositio
 	sbc.logger.Info("Placing market order to close position",
 		"function", "ClosePosition",
 		"side", oppositeSide,
-		"amount", req.Amount)
+		"amount", req.Amount,
+		"position_id", closeOrder.PositionID)
 	sbc.logger.Debug("Close position request payload",
 		"function", "ClosePosition",
 		"payload", string(reqBody))
@@ -281,7 +395,7 @@ func (sbc *SaxoBrokerClient) ClosePosition(ctx context.Context, req ClosePositio
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	// Execute request with OAuth2 auto-refresh
-	resp, err := sbc.doRequest(ctx, httpReq)
+	resp, err := sbc.doRequest(orderRequest(ctx), httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -311,28 +425,33 @@ func (sbc *SaxoBrokerClient) ModifyOrder(ctx context.Context, req OrderModificat
 	sbc.logger.Info("Modifying order",
 		"function", "ModifyOrder",
 		"order_id", req.OrderID,
-		"new_price", req.OrderPrice)
+		"new_price", req.OrderPrice,
+		"new_amount", req.Amount,
+		"related_orders", len(req.RelatedOrders))
+
+	if err := sbc.killSwitch.check(); err != nil {
+		return nil, err
+	}
 
 	// Check authentication
 	if !sbc.authClient.IsAuthenticated() {
-		return nil, fmt.Errorf("not authenticated with broker")
+		return nil, sbc.notAuthenticated()
 	}
 
-	// Build modification payload following legacy SaxoMoveStopParams pattern
-	// NOTE: OrderID must be in the body, not in the URL path (Saxo API requirement)
-	payload := map[string]interface{}{
-		"AccountKey": req.AccountKey,
-		"OrderID":    req.OrderID, // OrderID in body, not URL path!
-		"OrderType":  req.OrderType,
-		"AssetType":  req.AssetType,
-		"OrderDuration": map[string]interface{}{
-			"DurationType": req.OrderDuration.DurationType,
-		},
+	payload, err := buildModifyOrderPayload(req, sbc.manualOrder(req.ManualOrder), sbc.clock.Now())
+	if err != nil {
+		return nil, fmt.Errorf("invalid modification request: %w", err)
 	}
-
-	// Add OrderPrice only if specified (market orders don't have price)
-	if req.OrderPrice != "" {
-		payload["OrderPrice"] = req.OrderPrice
+	if sbc.dryRun {
+		sbc.logger.Info("Dry run: modification not sent",
+			"function", "ModifyOrder",
+			"order_id", req.OrderID)
+		return &OrderResponse{
+			OrderID:   req.OrderID,
+			Status:    "Modified",
+			Timestamp: sbc.clock.Now().Format(time.RFC3339),
+			DryRun:    true,
+		}, nil
 	}
 
 	// Marshal request payload
@@ -349,12 +468,11 @@ func (sbc *SaxoBrokerClient) ModifyOrder(ctx context.Context, req OrderModificat
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	// Set headers
+	// Set headers (X-Request-ID is added by doRequest)
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("X-Request-ID", fmt.Sprintf("modify-%d", time.Now().Unix()))
 
 	// Execute request with OAuth2 auto-refresh (use doRequest for consistent logging)
-	resp, err := sbc.doRequest(ctx, httpReq)
+	resp, err := sbc.doRequest(orderRequest(ctx), httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
@@ -389,10 +507,79 @@ func (sbc *SaxoBrokerClient) ModifyOrder(ctx context.Context, req OrderModificat
 	return &OrderResponse{
 		OrderID:   req.OrderID,
 		Status:    "Modified",
-		Timestamp: time.Now().Format(time.RFC3339),
+		Timestamp: sbc.clock.Now().Format(time.RFC3339),
 	}, nil
 }
 
+// buildModifyOrderPayload builds the PATCH /trade/v2/orders body
+// Following legacy SaxoMoveStopParams pattern
+// NOTE: OrderID must be in the body, not in the URL path (Saxo API requirement)
+// now is the reference time for GoodTillDate expiry validation
+// manualOrder is the resolved ManualOrder flag, sent on the order and its related orders
+func buildModifyOrderPayload(req OrderModificationRequest, manualOrder bool, now time.Time) (map[string]interface{}, error) {
+	if req.OrderID == "" {
+		return nil, fmt.Errorf("order ID is required")
+	}
+
+	payload := map[string]interface{}{
+		"AccountKey":  req.AccountKey,
+		"OrderID":     req.OrderID, // OrderID in body, not URL path!
+		"OrderType":   req.OrderType,
+		"AssetType":   req.AssetType,
+		"ManualOrder": manualOrder,
+	}
+	if err := addModifyOrderFields(payload, req.OrderPrice, req.Amount, req.OrderDuration, now); err != nil {
+		return nil, err
+	}
+
+	// Related orders (OCO target/stop) are moved in the same request via the Orders array
+	if len(req.RelatedOrders) > 0 {
+		relatedOrders := make([]map[string]interface{}, 0, len(req.RelatedOrders))
+		for _, related := range req.RelatedOrders {
+			if related.OrderID == "" {
+				return nil, fmt.Errorf("related order ID is required")
+			}
+			relatedOrder := map[string]interface{}{
+				"AccountKey":  req.AccountKey,
+				"OrderID":     related.OrderID,
+				"OrderType":   related.OrderType,
+				"AssetType":   req.AssetType,
+				"ManualOrder": manualOrder,
+			}
+			if err := addModifyOrderFields(relatedOrder, related.OrderPrice, related.Amount, related.OrderDuration, now); err != nil {
+				return nil, fmt.Errorf("related order %s: %w", related.OrderID, err)
+			}
+			relatedOrders = append(relatedOrders, relatedOrder)
+		}
+		payload["Orders"] = relatedOrders
+	}
+
+	return payload, nil
+}
+
+// addModifyOrderFields adds price, amount and duration of one modified order
+// OrderDuration is always sent (required by Saxo on PATCH); price and amount only when set
+func addModifyOrderFields(order map[string]interface{}, price string, amount float64, duration OrderDuration, now time.Time) error {
+	// Add OrderPrice only if specified (market orders don't have price)
+	if price != "" {
+		order["OrderPrice"] = price
+	}
+
+	if amount < 0 {
+		return fmt.Errorf("amount must not be negative, got %v", amount)
+	}
+	if amount > 0 {
+		order["Amount"] = amount
+	}
+
+	// CRITICAL: Saxo rejects GoodTillDate without an expiry
+	if err := duration.Validate(now); err != nil {
+		return err
+	}
+	order["OrderDuration"] = duration.toSaxo()
+	return nil
+}
+
 // GetOrderStatus implements BrokerClient.GetOrderStatus
 func (sbc *SaxoBrokerClient) GetOrderStatus(ctx context.Context, orderID string) (*OrderStatus, error) {
 	sbc.logger.Debug("Checking order status",
@@ -401,7 +588,7 @@ func (sbc *SaxoBrokerClient) GetOrderStatus(ctx context.Context, orderID string)
 
 	// Check authentication
 	if !sbc.authClient.IsAuthenticated() {
-		return nil, fmt.Errorf("not authenticated with broker")
+		return nil, sbc.notAuthenticated()
 	}
 
 	// Create HTTP request
@@ -440,16 +627,46 @@ func (sbc *SaxoBrokerClient) GetOrderStatus(ctx context.Context, orderID string)
 // GetOpenOrders retrieves all open orders from Saxo API
 // Used by recovery system to match live orders to signals
 func (sbc *SaxoBrokerClient) GetOpenOrders(ctx context.Context) ([]LiveOrder, error) {
-	// Saxo API endpoint: GET /port/v1/orders/me
-	// Request all field groups to get complete order data including Symbol and Description
-	url := fmt.Sprintf("%s/port/v1/orders/me?FieldGroups=DisplayAndFormat,ExchangeInfo", sbc.baseURL)
+	return sbc.GetOpenOrdersFiltered(ctx, OpenOrdersParams{})
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+// GetOpenOrdersFiltered retrieves open orders matching params
+// Status, AccountKey and FieldGroups are Saxo query params; /port/v1/orders has no instrument
+// filter, so Uic and AssetType are applied to the response
+func (sbc *SaxoBrokerClient) GetOpenOrdersFiltered(ctx context.Context, params OpenOrdersParams) ([]LiveOrder, error) {
+	// Request all field groups by default to get complete order data including Symbol and Description
+	fieldGroups := params.FieldGroups
+	if len(fieldGroups) == 0 {
+		fieldGroups = []string{"DisplayAndFormat", "ExchangeInfo"}
+	}
+	query := url.Values{}
+	query.Set("FieldGroups", strings.Join(fieldGroups, ","))
+	if len(params.Status) > 0 {
+		query.Set("Status", strings.Join(params.Status, ","))
+	}
+
+	// Saxo API endpoint: GET /port/v1/orders/me, or /port/v1/orders for one account or client
+	endpoint := "/port/v1/orders/me"
+	if params.AccountKey != "" || params.ClientKey != "" || sbc.clientKey != "" {
+		clientKey := params.ClientKey
+		if clientKey == "" {
+			var err error
+			if clientKey, err = sbc.scopedClientKey(ctx); err != nil {
+				return nil, fmt.Errorf("failed to get ClientKey for account filter: %w", err)
+			}
+		}
+		endpoint = "/port/v1/orders"
+		query.Set("ClientKey", clientKey)
+		if params.AccountKey != "" {
+			query.Set("AccountKey", params.AccountKey)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", sbc.baseURL+endpoint+"?"+query.Encode(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Add authorization header
 	// Execute request with OAuth2 auto-refresh
 	resp, err := sbc.doRequest(ctx, req)
 	if err != nil {
@@ -471,11 +688,16 @@ func (sbc *SaxoBrokerClient) GetOpenOrders(ctx context.Context) ([]LiveOrder, er
 	liveOrders := make([]LiveOrder, 0, len(saxoResponse.Data))
 	for _, saxoOrder := range saxoResponse.Data {
 		liveOrder := sbc.convertFromSaxoOpenOrder(saxoOrder)
+		if !params.Matches(liveOrder) {
+			continue
+		}
 		liveOrders = append(liveOrders, liveOrder)
 	}
 
 	sbc.logger.Info("Retrieved open orders",
-		"function", "GetOpenOrders",
+		"function", "GetOpenOrdersFiltered",
+		"endpoint", endpoint,
+		"received", len(saxoResponse.Data),
 		"count", len(liveOrders))
 	return liveOrders, nil
 }
@@ -494,7 +716,7 @@ func (sbc *SaxoBrokerClient) GetOpenPositions(ctx context.Context) (*SaxoOpenPos
 	// Request all field groups: PositionBase, PositionView, and DisplayAndFormat
 	// Without FieldGroups parameter, only PositionBase and PositionView are returned by default
 	// We need to explicitly request all three to get Symbol and Description
-	url := fmt.Sprintf("%s/port/v1/positions/me?FieldGroups=PositionBase,PositionView,DisplayAndFormat", sbc.baseURL)
+	url := sbc.portfolioURL("positions", url.Values{"FieldGroups": {"PositionBase,PositionView,DisplayAndFormat"}})
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -540,7 +762,7 @@ func (sbc *SaxoBrokerClient) GetOpenPositions(ctx context.Context) (*SaxoOpenPos
 // Example: 3 long EURUSD positions = 1 net position showing total exposure
 func (sbc *SaxoBrokerClient) GetNetPositions(ctx context.Context) (*SaxoNetPositionsResponse, error) {
 	// Request all field groups to get complete net position data including Symbol and Description
-	url := fmt.Sprintf("%s/port/v1/netpositions/me?FieldGroups=NetPositionBase,NetPositionView,DisplayAndFormat", sbc.baseURL)
+	url := sbc.portfolioURL("netpositions", url.Values{"FieldGroups": {"NetPositionBase,NetPositionView,DisplayAndFormat"}})
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -574,7 +796,7 @@ func (sbc *SaxoBrokerClient) GetNetPositions(ctx context.Context) (*SaxoNetPosit
 // Endpoint: GET /port/v1/closedpositions/me
 func (sbc *SaxoBrokerClient) GetClosedPositions(ctx context.Context) (*SaxoClosedPositionsResponse, error) {
 	// Request all field groups to get complete closed position data including Symbol and Description
-	url := fmt.Sprintf("%s/port/v1/closedpositions/me?FieldGroups=ClosedPosition,DisplayAndFormat", sbc.baseURL)
+	url := sbc.portfolioURL("closedpositions", url.Values{"FieldGroups": {"ClosedPosition,DisplayAndFormat"}})
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -663,11 +885,18 @@ func (sbc *SaxoBrokerClient) GetHistoricalPositions(ctx context.Context, clientK
 }
 
 // GetAccounts implements BrokerClient.GetAccounts with generic return type
+// Cached for CacheTTLs.Accounts - use WithoutCache(ctx) to force a fetch
 func (sbc *SaxoBrokerClient) GetAccounts(ctx context.Context) (*Accounts, error) {
+	return cachedFetch(ctx, sbc.responseCache, CacheAccounts, func() (*Accounts, error) {
+		return sbc.fetchAccounts(ctx)
+	})
+}
+
+func (sbc *SaxoBrokerClient) fetchAccounts(ctx context.Context) (*Accounts, error) {
 	sbc.logger.Debug("Fetching accounts",
 		"function", "GetAccounts")
 
-	url := fmt.Sprintf("%s/port/v1/accounts/me", sbc.baseURL)
+	url := sbc.portfolioURL("accounts", nil)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -701,8 +930,15 @@ func (sbc *SaxoBrokerClient) GetAccounts(ctx context.Context) (*Accounts, error)
 
 // GetAccountBalance retrieves account balance from Saxo API
 // Endpoint: GET /port/v1/balances/me
+// Cached for CacheTTLs.Balance - use WithoutCache(ctx) to force a fetch
 func (sbc *SaxoBrokerClient) GetAccountBalance(ctx context.Context) (*SaxoBalance, error) {
-	url := fmt.Sprintf("%s/port/v1/balances/me", sbc.baseURL)
+	return cachedFetch(ctx, sbc.responseCache, CacheBalance, func() (*SaxoBalance, error) {
+		return sbc.fetchAccountBalance(ctx)
+	})
+}
+
+func (sbc *SaxoBrokerClient) fetchAccountBalance(ctx context.Context) (*SaxoBalance, error) {
+	url := sbc.portfolioURL("balances", nil)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -773,7 +1009,14 @@ func (sbc *SaxoBrokerClient) GetMarginOverview(ctx context.Context, clientKey st
 
 // GetClientInfo retrieves client/user information from Saxo API
 // Endpoint: GET /port/v1/users/me
+// Cached for CacheTTLs.ClientInfo - use WithoutCache(ctx) to force a fetch
 func (sbc *SaxoBrokerClient) GetClientInfo(ctx context.Context) (*SaxoClientInfo, error) {
+	return cachedFetch(ctx, sbc.responseCache, CacheClientInfo, func() (*SaxoClientInfo, error) {
+		return sbc.fetchClientInfo(ctx)
+	})
+}
+
+func (sbc *SaxoBrokerClient) fetchClientInfo(ctx context.Context) (*SaxoClientInfo, error) {
 	url := fmt.Sprintf("%s/port/v1/users/me", sbc.baseURL)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -807,12 +1050,19 @@ func (sbc *SaxoBrokerClient) GetClientInfo(ctx context.Context) (*SaxoClientInfo
 }
 
 // GetBalance implements BrokerClient.GetBalance with generic return type
+// Scoped to the default account when one is selected (WithDefaultAccount, SetDefaultAccount)
 func (sbc *SaxoBrokerClient) GetBalance(ctx context.Context) (*Balance, error) {
 	sbc.logger.Debug("Fetching account balance",
 		"function", "GetBalance")
 
-	// Get Saxo-specific balance
-	saxoBalance, err := sbc.GetAccountBalance(ctx)
+	// Get Saxo-specific balance, of the default account when one is selected
+	var saxoBalance *SaxoBalance
+	var err error
+	if accountKey := sbc.DefaultAccount(); accountKey != "" {
+		saxoBalance, err = sbc.GetBalanceForAccount(ctx, "", accountKey)
+	} else {
+		saxoBalance, err = sbc.GetAccountBalance(ctx)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -832,15 +1082,25 @@ func (sbc *SaxoBrokerClient) convertToSaxoOrder(req OrderRequest) (map[string]in
 		return nil, fmt.Errorf("instrument %s is missing AssetType", req.Instrument.Ticker)
 	}
 
-	// Build main order structure
+	// Amount is units, or a monetary value for CashAmount orders
+	amountType, amount, err := orderAmount(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build main order structure - ManualOrder is required by Saxo on every order
+	manualOrder := sbc.manualOrder(req.ManualOrder)
 	saxoReq := map[string]interface{}{
 		"AccountKey":  req.AccountKey,
 		"Uic":         req.Instrument.Identifier,
 		"AssetType":   req.Instrument.AssetType,
 		"BuySell":     req.Side,
-		"Amount":      float64(req.Size),
+		"Amount":      amount,
 		"OrderType":   req.OrderType,
-		"ManualOrder": true,
+		"ManualOrder": manualOrder,
+	}
+	if amountType != "" {
+		saxoReq["AmountType"] = amountType
 	}
 
 	// Set price for non-market orders
@@ -853,29 +1113,29 @@ func (sbc *SaxoBrokerClient) convertToSaxoOrder(req OrderRequest) (map[string]in
 		saxoReq["StopLimitPrice"] = req.StopLimitPrice
 	}
 
-	// Set order duration
-	duration := req.Duration
-	if duration == "" {
-		duration = "DayOrder" // Default
-	}
-	saxoReq["OrderDuration"] = map[string]string{
-		"DurationType": duration,
+	// Set order duration (DayOrder by default); GoodTillDate carries ExpirationDateTime in UTC
+	duration := req.Duration.withDefault()
+	if err := duration.Validate(sbc.clock.Now()); err != nil {
+		return nil, fmt.Errorf("invalid order duration: %w", err)
 	}
+	saxoReq["OrderDuration"] = duration.toSaxo()
 
 	// Handle multi-leg orders (complex/OCO orders)
 	if len(req.RelatedOrders) > 0 {
 		relatedOrders := make([]map[string]interface{}, 0, len(req.RelatedOrders))
 
 		for _, related := range req.RelatedOrders {
+			relatedDuration := related.Duration.withDefault()
+			if err := relatedDuration.Validate(sbc.clock.Now()); err != nil {
+				return nil, fmt.Errorf("invalid related order duration: %w", err)
+			}
 			// Per Saxo API docs: Related orders inherit AccountKey, Uic, AssetType from parent
 			relatedOrder := map[string]interface{}{
-				"BuySell":    related.Side,
-				"OrderType":  related.OrderType,
-				"OrderPrice": related.Price,
-				"OrderDuration": map[string]string{
-					"DurationType": related.Duration,
-				},
-				"ManualOrder": true,
+				"BuySell":       related.Side,
+				"OrderType":     related.OrderType,
+				"OrderPrice":    related.Price,
+				"OrderDuration": relatedDuration.toSaxo(),
+				"ManualOrder":   manualOrder,
 			}
 			relatedOrders = append(relatedOrders, relatedOrder)
 		}
@@ -896,6 +1156,7 @@ func (sbc *SaxoBrokerClient) convertFromSaxoResponse(saxoResp SaxoOrderResponse)
 		OrderID:   saxoResp.OrderId,
 		Status:    saxoResp.Status,
 		Timestamp: saxoResp.Timestamp,
+		Warnings:  orderWarnings(saxoResp),
 	}
 
 	// If this is a multi-leg order response, populate RelatedOrderIDs positionally.
@@ -917,10 +1178,17 @@ func (sbc *SaxoBrokerClient) convertFromSaxoResponse(saxoResp SaxoOrderResponse)
 	return resp
 }
 
+// Price is the execution price once filled, else the order price (same as the paper broker)
 func (sbc *SaxoBrokerClient) convertFromSaxoStatus(saxoStatus SaxoOrderStatus) *OrderStatus {
+	price := derefFloat64(saxoStatus.OrderPrice)
+	if saxoStatus.ExecutionPrice != nil {
+		price = *saxoStatus.ExecutionPrice
+	}
 	return &OrderStatus{
 		OrderID: saxoStatus.OrderId,
 		Status:  saxoStatus.Status,
+		Price:   price,
+		Size:    saxoStatus.Amount,
 		//FilledQuantity:    saxoStatus.FilledAmount,
 		//RemainingQuantity: saxoStatus.Amount - saxoStatus.FilledAmount,
 		//AveragePrice:      saxoStatus.ExecutionPrice,
@@ -944,7 +1212,7 @@ func (sbc *SaxoBrokerClient) convertFromSaxoOpenOrder(saxoOrder SaxoOpenOrder) L
 			"function", "convertFromSaxoOpenOrder",
 			"order_time", saxoOrder.OrderTime,
 			"error", err)
-		orderTime = time.Now()
+		orderTime = sbc.clock.Now()
 	}
 
 	// Convert related orders
@@ -991,10 +1259,10 @@ func (sbc *SaxoBrokerClient) convertFromSaxoOpenOrder(saxoOrder SaxoOpenOrder) L
 	return liveOrder
 }
 
-// GetTradingSchedule retrieves trading schedule from Saxo API with generic return type
+// fetchTradingSchedule retrieves trading schedule from Saxo API with generic return type (see GetTradingSchedule)
 // Following legacy broker/broker_http.go GetSaxoTradingSchedule pattern
 // Endpoint: /ref/v1/instruments/tradingschedule/{UIC}/{AssetType}
-func (sbc *SaxoBrokerClient) GetTradingSchedule(ctx context.Context, params TradingScheduleParams) (*TradingSchedule, error) {
+func (sbc *SaxoBrokerClient) fetchTradingSchedule(ctx context.Context, params TradingScheduleParams) (*TradingSchedule, error) {
 	endpoint := fmt.Sprintf("/ref/v1/instruments/tradingschedule/%d/%s", params.Uic, params.AssetType)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", sbc.baseURL+endpoint, nil)
@@ -1018,7 +1286,7 @@ func (sbc *SaxoBrokerClient) GetTradingSchedule(ctx context.Context, params Trad
 	}
 
 	sbc.logger.Info("Retrieved trading schedule",
-		"function", "GetTradingSchedule",
+		"function", "fetchTradingSchedule",
 		"uic", params.Uic,
 		"sessions_count", len(saxoSchedule.Sessions))
 
@@ -1038,64 +1306,66 @@ func (sbc *SaxoBrokerClient) GetTradingSchedule(ctx context.Context, params Trad
 	}, nil
 }
 
-// convertFromSaxoPrice converts Saxo price response to generic format
-// Following legacy broker/broker_http.go price conversion patterns
-func (sbc *SaxoBrokerClient) convertFromSaxoPrice(saxoPrice SaxoPriceResponse, ticker string) *PriceData {
-	if len(saxoPrice.Data) == 0 {
-		sbc.logger.Warn("Empty price data",
-			"function", "convertFromSaxoPrice",
-			"ticker", ticker)
-		return &PriceData{
-			Ticker:    ticker,
-			Bid:       0.0,
-			Ask:       0.0,
-			Mid:       0.0,
-			Spread:    0.0,
-			Timestamp: "",
-		}
-	}
-
-	// Use most recent data point (last in array)
-	latest := saxoPrice.Data[len(saxoPrice.Data)-1]
-
-	// Calculate mid price and spread following FX domain knowledge
-	bid := latest.CloseBid
-	ask := latest.CloseAsk
-	mid := (bid + ask) / 2.0
-	spread := ask - bid
-
-	return &PriceData{
-		Ticker:    ticker,
-		Bid:       bid,
-		Ask:       ask,
-		Mid:       mid,
-		Spread:    spread,
-		Timestamp: latest.Time,
-	}
-}
-
 // doRequest executes an HTTP request using OAuth2 auto-refresh client
 // This ensures tokens are automatically refreshed before requests, triggering
 // external refresh notifications for WebSocket re-authorization
 // Matches legacy pivot-web broker/oauth.go::sendBrokerData() logging pattern
+// Every call is bounded by the client's per-request timeout as well as the caller's ctx
 func (sbc *SaxoBrokerClient) doRequest(ctx context.Context, req *http.Request) (*http.Response, error) {
-	httpClient, err := sbc.authClient.GetHTTPClient(ctx)
+	ctx, cancel := RequestContext(ctx, sbc.timeout)
+	req = req.WithContext(ctx)
+
+	httpClient, err := sbc.getHTTPClient(ctx, req)
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("failed to get HTTP client: %w", err)
 	}
 
+	// Idempotency key on POST/PATCH/DELETE so Saxo can reject duplicate operations
+	requestID := applyRequestID(ctx, req)
+
+	// Audit hooks see the final request, headers included (see WithInterceptor)
+	var exchange *rawExchange
+	if len(sbc.interceptors) > 0 {
+		exchange, err = sbc.interceptRequest(ctx, req, requestID)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("request rejected by interceptor: %w", err)
+		}
+	}
+
 	// Execute request
 	resp, err := httpClient.Do(req)
+	if exchange != nil {
+		err = sbc.interceptResponse(ctx, exchange, resp, err)
+	}
 	if err != nil {
+		cancel()
+		if requestID != "" {
+			// The operation may still have executed - retry with the same ID (WithRequestID)
+			return nil, fmt.Errorf("request %s: %w", requestID, err)
+		}
 		return nil, err
 	}
 
+	// Keep deadline alive until caller has read the body
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+
+	// Rate-limit headers for RateLimits and the history downloader's pacing
+	sbc.rateLimits.observe(resp.StatusCode, resp.Header, sbc.clock.Now())
+
+	// Orders and position changes move balance and margin - next GetBalance goes to Saxo
+	if resp.StatusCode < 300 && invalidatesBalance(req.Method, req.URL.Path) {
+		sbc.responseCache.invalidate(CacheBalance)
+	}
+
 	// Log response status (matching pivot-web pattern)
 	sbc.logger.Info("HTTP response received",
 		"function", "doRequest",
 		"status", resp.StatusCode,
 		"method", req.Method,
-		"path", req.URL.Path)
+		"path", req.URL.Path,
+		"request_id", requestID)
 
 	// Log response headers (matching pivot-web detailed header logging)
 	if sbc.logger.Enabled(ctx, slog.LevelDebug) {
@@ -1115,11 +1385,46 @@ func (sbc *SaxoBrokerClient) doRequest(ctx context.Context, req *http.Request) (
 	return resp, nil
 }
 
+// cancelOnCloseBody releases the per-request context once the response body is closed
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// getHTTPClient returns the client used for API calls and applies common headers to req
+// Uses the injected WithHTTPClient client when set, otherwise the OAuth2 auto-refresh client
+func (sbc *SaxoBrokerClient) getHTTPClient(ctx context.Context, req *http.Request) (*http.Client, error) {
+	if sbc.userAgent != "" {
+		req.Header.Set("User-Agent", sbc.userAgent)
+	}
+
+	if sbc.httpClient == nil {
+		return sbc.authClient.GetHTTPClient(ctx)
+	}
+
+	// Injected client carries no OAuth2 transport - set bearer token explicitly
+	if req.Header.Get("Authorization") == "" {
+		accessToken, err := sbc.authClient.GetAccessToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return sbc.httpClient, nil
+}
+
 // handleErrorResponse handles HTTP error responses
 // Enhanced to log error body before returning (matching pivot-web pattern)
 func (sbc *SaxoBrokerClient) handleErrorResponse(resp *http.Response) error {
 	body, _ := io.ReadAll(resp.Body)
 	bodyStr := string(body)
+	requestID := resp.Request.Header.Get(RequestIDHeader)
 
 	// Log non-2xx responses (matching pivot-web pattern)
 	sbc.logger.Warn("HTTP error response",
@@ -1127,28 +1432,111 @@ func (sbc *SaxoBrokerClient) handleErrorResponse(resp *http.Response) error {
 		"status", resp.StatusCode,
 		"body", bodyStr,
 		"method", resp.Request.Method,
-		"path", resp.Request.URL.Path)
+		"path", resp.Request.URL.Path,
+		"request_id", requestID)
 
+	if requestID != "" {
+		return fmt.Errorf("HTTP %d (request %s): %s", resp.StatusCode, requestID, bodyStr)
+	}
 	return fmt.Errorf("HTTP %d: %s", resp.StatusCode, bodyStr)
 }
 
 // SearchInstruments implements BrokerClient.SearchInstruments
-// Searches for instruments matching criteria
-func (sbc *SaxoBrokerClient) SearchInstruments(ctx context.Context, params InstrumentSearchParams) ([]Instrument, error) {
+// Searches for instruments matching criteria, following __next until every page (or MaxResults) is read
+// Reference: Saxo API GET /ref/v1/instruments
+func (sbc *SaxoBrokerClient) SearchInstruments(ctx context.Context, params InstrumentSearchParams) (*InstrumentSearchResult, error) {
 	sbc.logger.Info("Searching instruments",
 		"function", "SearchInstruments",
 		"asset_type", params.AssetType,
 		"keywords", params.Keywords)
 
 	if !sbc.authClient.IsAuthenticated() {
-		return nil, fmt.Errorf("not authenticated with broker")
+		return nil, sbc.notAuthenticated()
+	}
+
+	query := url.Values{}
+	if params.Keywords != "" {
+		query.Set("Keywords", params.Keywords)
+	}
+	if params.AssetType != "" {
+		query.Set("AssetTypes", params.AssetType)
+	}
+	if params.Exchange != "" {
+		query.Set("ExchangeId", params.Exchange)
+	}
+	if len(params.Uics) > 0 {
+		query.Set("Uics", joinUics(params.Uics))
+	}
+	if params.IncludeNonTradable {
+		query.Set("IncludeNonTradable", "true")
+	}
+	if params.CanParticipateInMultiLegOrder {
+		query.Set("CanParticipateInMultiLegOrder", "true")
 	}
+	if params.Top > 0 {
+		query.Set("$top", strconv.Itoa(params.Top))
+	}
+	query.Set("$skip", "0")
 
-	// Build URL with query parameters
-	url := fmt.Sprintf("%s/ref/v1/instruments/?AssetType=%s&ExchangeId=%s&Keywords=%s&Skip=0",
-		sbc.baseURL, params.AssetType, params.Exchange, params.Keywords)
+	result := &InstrumentSearchResult{}
+	pageURL := sbc.baseURL + "/ref/v1/instruments?" + query.Encode()
+	for pages := 0; pageURL != ""; pages++ {
+		page, err := sbc.searchInstrumentsPage(ctx, pageURL)
+		if err != nil {
+			return nil, fmt.Errorf("instrument search page %d failed: %w", pages+1, err)
+		}
+		if pages == 0 {
+			result.TotalCount = page.Count
+		}
+		for _, item := range page.Data {
+			result.Instruments = append(result.Instruments, Instrument{
+				Identifier:  item.Identifier,
+				Uic:         item.Identifier,
+				Symbol:      item.Symbol,
+				Description: item.Description,
+				AssetType:   item.AssetType,
+				Exchange:    item.ExchangeID,
+				Currency:    item.CurrencyCode,
+			})
+		}
+		if params.MaxResults > 0 && len(result.Instruments) >= params.MaxResults {
+			result.Instruments = result.Instruments[:params.MaxResults]
+			break
+		}
+		if len(page.Data) == 0 {
+			break
+		}
+		if pageURL, err = sbc.nextPageURL(pageURL, page.Next); err != nil {
+			return nil, fmt.Errorf("instrument search: %w", err)
+		}
+	}
+	if result.TotalCount < len(result.Instruments) {
+		result.TotalCount = len(result.Instruments) // __count is omitted on some responses
+	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	sbc.logger.Info("Found instruments",
+		"function", "SearchInstruments",
+		"count", len(result.Instruments),
+		"total_count", result.TotalCount)
+	return result, nil
+}
+
+// saxoInstrumentSearchPage is one page of GET /ref/v1/instruments
+type saxoInstrumentSearchPage struct {
+	Count int    `json:"__count"`
+	Next  string `json:"__next"`
+	Data  []struct {
+		Identifier   int    `json:"Identifier"`
+		Symbol       string `json:"Symbol"`
+		Description  string `json:"Description"`
+		AssetType    string `json:"AssetType"`
+		ExchangeID   string `json:"ExchangeId"`
+		CurrencyCode string `json:"CurrencyCode"`
+	} `json:"Data"`
+}
+
+func (sbc *SaxoBrokerClient) searchInstrumentsPage(ctx context.Context, pageURL string) (*saxoInstrumentSearchPage, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -1163,60 +1551,73 @@ func (sbc *SaxoBrokerClient) SearchInstruments(ctx context.Context, params Instr
 		return nil, sbc.handleErrorResponse(resp)
 	}
 
-	// Parse Saxo API response
-	var saxoResp struct {
-		Data []struct {
-			Identifier   int    `json:"Identifier"`
-			Symbol       string `json:"Symbol"`
-			Description  string `json:"Description"`
-			AssetType    string `json:"AssetType"`
-			ExchangeID   string `json:"ExchangeId"`
-			CurrencyCode string `json:"CurrencyCode"`
-		} `json:"Data"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&saxoResp); err != nil {
+	var page saxoInstrumentSearchPage
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	// Convert to generic Instrument format
-	instruments := make([]Instrument, len(saxoResp.Data))
-	for i, item := range saxoResp.Data {
-		instruments[i] = Instrument{
-			Identifier:  item.Identifier,
-			Uic:         item.Identifier,
-			Symbol:      item.Symbol,
-			Description: item.Description,
-			AssetType:   item.AssetType,
-			Exchange:    item.ExchangeID,
-			Currency:    item.CurrencyCode,
-		}
-	}
-
-	sbc.logger.Info("Found instruments",
-		"function", "SearchInstruments",
-		"count", len(instruments))
-	return instruments, nil
+	return &page, nil
 }
 
 // GetInstrumentDetails implements BrokerClient.GetInstrumentDetails
 // Gets detailed instrument information for multiple UICs
+// More than MaxUicsPerRequest UICs are fetched in parallel chunks; results keep the order of uics.
+// When chunks fail, the details of the other chunks are returned with a *UicChunkError per failed chunk
 func (sbc *SaxoBrokerClient) GetInstrumentDetails(ctx context.Context, uics []int) ([]InstrumentDetail, error) {
 	sbc.logger.Info("Fetching instrument details",
 		"function", "GetInstrumentDetails",
 		"count", len(uics))
 
 	if !sbc.authClient.IsAuthenticated() {
-		return nil, fmt.Errorf("not authenticated with broker")
+		return nil, sbc.notAuthenticated()
+	}
+	if len(uics) == 0 {
+		return nil, nil
 	}
 
-	// Convert UICs to comma-separated string
-	uicsStr := fmt.Sprintf("%d", uics[0])
-	for i := 1; i < len(uics); i++ {
-		uicsStr += fmt.Sprintf(",%d", uics[i])
+	details, err := fetchUicChunks(ctx, uics, func(d InstrumentDetail) int { return d.Uic }, sbc.getInstrumentDetailsChunk)
+	sbc.storeInstrumentDetails(details)
+	if err != nil {
+		sbc.logger.Error("Instrument details incomplete",
+			"function", "GetInstrumentDetails",
+			"requested", len(uics),
+			"retrieved", len(details),
+			"error", err)
+		return details, err
 	}
 
-	url := fmt.Sprintf("%s/ref/v1/instruments/details?Uics=%s", sbc.baseURL, uicsStr)
+	sbc.logger.Info("Retrieved instrument details",
+		"function", "GetInstrumentDetails",
+		"count", len(details))
+	return details, nil
+}
+
+// storeInstrumentDetails records fetched constraints in the shared InstrumentStore, if any
+func (sbc *SaxoBrokerClient) storeInstrumentDetails(details []InstrumentDetail) {
+	if sbc.instrumentStore == nil {
+		return
+	}
+	for _, detail := range details {
+		if err := sbc.instrumentStore.MergeDetail(detail); err != nil {
+			sbc.logger.Warn("Failed to update instrument store",
+				"function", "storeInstrumentDetails",
+				"uic", detail.Uic,
+				"error", err)
+		}
+	}
+}
+
+// enrichFromStore fills a missing UIC and asset type from the shared InstrumentStore, if any
+func (sbc *SaxoBrokerClient) enrichFromStore(instrument Instrument) Instrument {
+	if sbc.instrumentStore == nil || (instrument.Uic != 0 && instrument.AssetType != "") {
+		return instrument
+	}
+	enriched, _ := sbc.instrumentStore.Enrich(instrument)
+	return enriched
+}
+
+// getInstrumentDetailsChunk fetches at most MaxUicsPerRequest UICs in one request
+func (sbc *SaxoBrokerClient) getInstrumentDetailsChunk(ctx context.Context, uics []int) ([]InstrumentDetail, error) {
+	url := fmt.Sprintf("%s/ref/v1/instruments/details?Uics=%s", sbc.baseURL, joinUics(uics))
 
 	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -1237,10 +1638,17 @@ func (sbc *SaxoBrokerClient) GetInstrumentDetails(ctx context.Context, uics []in
 	var saxoResp struct {
 		Data []struct {
 			Identifier            int     `json:"Identifier"`
+			AssetType             string  `json:"AssetType"`
+			Symbol                string  `json:"Symbol"`
+			Description           string  `json:"Description"`
 			TickSize              float64 `json:"TickSize"`
 			ExpiryDate            string  `json:"ExpiryDate"`
 			NoticeDate            string  `json:"NoticeDate"`
 			PriceToContractFactor float64 `json:"PriceToContractFactor"`
+			LotSize               float64 `json:"LotSize"`
+			MinimumLotSize        float64 `json:"MinimumLotSize"`
+			MinimumTradeSize      float64 `json:"MinimumTradeSize"`
+			AmountDecimals        int     `json:"AmountDecimals"`
 			Format                struct {
 				Decimals          int    `json:"Decimals"`
 				OrderDecimals     int    `json:"OrderDecimals"`
@@ -1259,12 +1667,19 @@ func (sbc *SaxoBrokerClient) GetInstrumentDetails(ctx context.Context, uics []in
 	for i, item := range saxoResp.Data {
 		detail := InstrumentDetail{
 			Uic:                   item.Identifier,
+			AssetType:             item.AssetType,
+			Symbol:                item.Symbol,
+			Description:           item.Description,
 			TickSize:              item.TickSize,
 			Decimals:              item.Format.Decimals,
 			OrderDecimals:         item.Format.OrderDecimals,
 			PriceToContractFactor: item.PriceToContractFactor,
 			Format:                item.Format.Format,
 			NumeratorDecimals:     item.Format.NumeratorDecimals,
+			LotSize:               item.LotSize,
+			MinimumLotSize:        item.MinimumLotSize,
+			MinimumTradeSize:      item.MinimumTradeSize,
+			AmountDecimals:        item.AmountDecimals,
 		}
 
 		// Parse dates if available
@@ -1281,15 +1696,12 @@ func (sbc *SaxoBrokerClient) GetInstrumentDetails(ctx context.Context, uics []in
 
 		details[i] = detail
 	}
-
-	sbc.logger.Info("Retrieved instrument details",
-		"function", "GetInstrumentDetails",
-		"count", len(details))
 	return details, nil
 }
 
 // GetInstrumentPrices implements BrokerClient.GetInstrumentPrices
 // Gets price information (including open interest) for instrument selection
+// Chunked like GetInstrumentDetails: partial results come with a *UicChunkError per failed chunk
 func (sbc *SaxoBrokerClient) GetInstrumentPrices(ctx context.Context, uics []int, fieldGroups string, assetType string) ([]InstrumentPriceInfo, error) {
 	sbc.logger.Info("Fetching instrument prices",
 		"function", "GetInstrumentPrices",
@@ -1297,17 +1709,35 @@ func (sbc *SaxoBrokerClient) GetInstrumentPrices(ctx context.Context, uics []int
 		"asset_type", assetType)
 
 	if !sbc.authClient.IsAuthenticated() {
-		return nil, fmt.Errorf("not authenticated with broker")
+		return nil, sbc.notAuthenticated()
+	}
+	if len(uics) == 0 {
+		return nil, nil
 	}
 
-	// Convert UICs to comma-separated string
-	uicsStr := fmt.Sprintf("%d", uics[0])
-	for i := 1; i < len(uics); i++ {
-		uicsStr += fmt.Sprintf(",%d", uics[i])
+	prices, err := fetchUicChunks(ctx, uics, func(p InstrumentPriceInfo) int { return p.Uic },
+		func(ctx context.Context, chunk []int) ([]InstrumentPriceInfo, error) {
+			return sbc.getInstrumentPricesChunk(ctx, chunk, fieldGroups, assetType)
+		})
+	if err != nil {
+		sbc.logger.Error("Instrument prices incomplete",
+			"function", "GetInstrumentPrices",
+			"requested", len(uics),
+			"retrieved", len(prices),
+			"error", err)
+		return prices, err
 	}
 
+	sbc.logger.Info("Retrieved instrument prices",
+		"function", "GetInstrumentPrices",
+		"count", len(prices))
+	return prices, nil
+}
+
+// getInstrumentPricesChunk fetches at most MaxUicsPerRequest UICs in one request
+func (sbc *SaxoBrokerClient) getInstrumentPricesChunk(ctx context.Context, uics []int, fieldGroups string, assetType string) ([]InstrumentPriceInfo, error) {
 	url := fmt.Sprintf("%s/trade/v1/infoprices/list?Uics=%s&FieldGroups=%s&AssetType=%s",
-		sbc.baseURL, uicsStr, fieldGroups, assetType)
+		sbc.baseURL, joinUics(uics), fieldGroups, assetType)
 
 	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
@@ -1350,17 +1780,13 @@ func (sbc *SaxoBrokerClient) GetInstrumentPrices(ctx context.Context, uics []int
 			LastPrice:    item.Quote.Mid,
 		}
 	}
-
-	sbc.logger.Info("Retrieved instrument prices",
-		"function", "GetInstrumentPrices",
-		"count", len(prices))
 	return prices, nil
 }
 
-// SetSessionCapabilities requests a trade level upgrade for the current session
+// SetSessionCapabilities requests a trade level for the current session without reading it back
 // Following legacy SetFullTradingAndChat() pattern from broker_http.go
 // Reference: Saxo API PATCH /root/v1/sessions/capabilities
-// tradeLevel: "FullTradingAndChat" for real-time data, "OrderOnly" for delayed data
+// tradeLevel: "FullTradingAndChat" for real-time data, "OrdersOnly" for delayed data (see SetTradeLevel)
 func (sbc *SaxoBrokerClient) SetSessionCapabilities(ctx context.Context, tradeLevel string) error {
 	type tradeLevelRequest struct {
 		TradeLevel string `json:"TradeLevel"`
@@ -1377,15 +1803,10 @@ func (sbc *SaxoBrokerClient) SetSessionCapabilities(ctx context.Context, tradeLe
 		return fmt.Errorf("failed to create session capability request: %w", err)
 	}
 
-	accessToken, err := sbc.authClient.GetAccessToken()
-	if err != nil {
-		return fmt.Errorf("failed to get access token: %w", err)
-	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+accessToken)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	// Use doRequest so the per-request timeout and injected HTTP client apply here too
+	resp, err := sbc.doRequest(ctx, req)
 	if err != nil {
 		return fmt.Errorf("session capability request failed: %w", err)
 	}
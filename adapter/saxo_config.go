@@ -0,0 +1,234 @@
+package saxo
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// SaxoConfig is the explicit configuration accepted by the factory functions
+// Zero values fall back to the registered provider's endpoints (see RegisterProvider) and the defaults below,
+// so apps configuring programmatically only set credentials; FromEnv builds it from SAXO_* variables
+type SaxoConfig struct {
+	Provider     string          // Registered provider name, "" = DefaultProvider
+	ClientID     string          // OAuth client ID (required)
+	ClientSecret string          // OAuth client secret (required)
+	Environment  SaxoEnvironment // "" = SaxoSIM for safety
+
+	// Endpoint overrides - "" = the provider's endpoint for Environment, e.g. for a corporate proxy
+	// or a partner domain. All four are required for a provider that is not registered
+	AuthURL      string
+	TokenURL     string
+	BaseURL      string
+	WebSocketURL string
+
+	TokenPath         string           // Token storage directory, "" = DefaultTokenDir (migrating from LegacyTokenPath)
+	TokenFileTemplate string           // Token filename, "" = DefaultTokenFileTemplate
+	TokenFileMode     os.FileMode      // Token file permissions, 0 = DefaultTokenFileMode
+	TokenKey          TokenKeyProvider // Encrypts token files at rest, nil = plain files
+	Timeout           time.Duration    // Per-request maximum, 0 = DefaultTimeout
+	Transport         *TransportConfig // Proxy, TLS and dial settings for auth and REST traffic, nil = Go defaults
+	ManualOrders      bool             // Orders are entered by a person, false = automated (WithManualOrders)
+	ClientKey         string           // Scope portfolio queries to this client, "" = token owner (WithClientKey)
+
+	// ReloginThreshold publishes TokenReloginRequired when the refresh token expires within it, 0 = disabled
+	// Set for Live apps with long-lived (e.g. 365-day) refresh tokens running unattended
+	ReloginThreshold time.Duration
+	// OnAuthExpired is called once the refresh token has expired or was rejected (WithOnAuthExpired)
+	OnAuthExpired func(TokenEvent)
+}
+
+// FromEnv builds a SaxoConfig from environment variables
+// PROVIDER selects the provider (default "saxo"); its EnvPrefix selects {PREFIX}_CLIENT_ID,
+// {PREFIX}_CLIENT_SECRET and {PREFIX}_ENVIRONMENT; {PREFIX}_AUTH_URL, {PREFIX}_TOKEN_URL, {PREFIX}_BASE_URL and
+// {PREFIX}_WEBSOCKET_URL override endpoints; TOKEN_STORAGE_PATH and TOKEN_FILE_TEMPLATE place the token file,
+// TOKEN_PASSPHRASE encrypts it
+func FromEnv() SaxoConfig {
+	return providerConfigFromEnv(SelectedProvider())
+}
+
+// providerConfigFromEnv reads the environment variables of a registered provider
+func providerConfigFromEnv(name string) SaxoConfig {
+	config := SaxoConfig{
+		Provider:          name,
+		TokenPath:         os.Getenv("TOKEN_STORAGE_PATH"),
+		TokenFileTemplate: os.Getenv("TOKEN_FILE_TEMPLATE"),
+	}
+	if os.Getenv("TOKEN_PASSPHRASE") != "" {
+		config.TokenKey = EnvPassphraseKey("TOKEN_PASSPHRASE")
+	}
+	provider, ok := LookupProvider(name)
+	if !ok {
+		return config // withDefaults reports the unknown provider
+	}
+
+	prefix := provider.EnvPrefix
+	config.ClientID = os.Getenv(prefix + "_CLIENT_ID")
+	config.ClientSecret = os.Getenv(prefix + "_CLIENT_SECRET")
+	config.Environment = SaxoEnvironment(os.Getenv(prefix + "_ENVIRONMENT"))
+	config.AuthURL = os.Getenv(prefix + "_AUTH_URL")
+	config.TokenURL = os.Getenv(prefix + "_TOKEN_URL")
+	config.BaseURL = os.Getenv(prefix + "_BASE_URL")
+	config.WebSocketURL = os.Getenv(prefix + "_WEBSOCKET_URL")
+	return config
+}
+
+// Validate reports whether the config is complete once defaults are applied
+func (c SaxoConfig) Validate() error {
+	_, err := c.withDefaults()
+	return err
+}
+
+// withDefaults validates c and fills unset fields from the provider registry
+func (c SaxoConfig) withDefaults() (SaxoConfig, error) {
+	if c.Provider == "" {
+		c.Provider = DefaultProvider
+	}
+	if c.Environment == "" {
+		c.Environment = SaxoSIM // Default to SIM for safety
+	}
+
+	provider, registered := LookupProvider(c.Provider)
+	explicit := c.AuthURL != "" && c.TokenURL != "" && c.BaseURL != "" && c.WebSocketURL != ""
+	if !registered && !explicit {
+		return c, fmt.Errorf("unknown provider %q (registered: %s) - set all endpoint URLs to use an unregistered provider",
+			c.Provider, strings.Join(RegisteredProviders(), ", "))
+	}
+
+	// Name the environment variable in errors so FromEnv users know what to set
+	envHint := func(suffix string) string {
+		if !registered {
+			return ""
+		}
+		return fmt.Sprintf(" (%s_%s)", provider.EnvPrefix, suffix)
+	}
+
+	if c.ClientID == "" {
+		return c, fmt.Errorf("client ID not set%s", envHint("CLIENT_ID"))
+	}
+	if c.ClientSecret == "" {
+		return c, fmt.Errorf("client secret not set%s", envHint("CLIENT_SECRET"))
+	}
+
+	overrides := []struct {
+		value  *string
+		suffix string
+	}{
+		{&c.AuthURL, "AUTH_URL"},
+		{&c.TokenURL, "TOKEN_URL"},
+		{&c.BaseURL, "BASE_URL"},
+		{&c.WebSocketURL, "WEBSOCKET_URL"},
+	}
+	for _, override := range overrides {
+		if *override.value == "" {
+			continue
+		}
+		if err := validateEndpointURL(*override.value); err != nil {
+			return c, fmt.Errorf("invalid endpoint override%s: %w", envHint(override.suffix), err)
+		}
+	}
+	// Paths are appended to these, e.g. BaseURL + "/trade/v2/orders"
+	c.BaseURL = strings.TrimRight(c.BaseURL, "/")
+	c.WebSocketURL = strings.TrimRight(c.WebSocketURL, "/")
+
+	if explicit {
+		return c, nil
+	}
+
+	endpoints, ok := provider.Environments[c.Environment]
+	if !ok {
+		return c, fmt.Errorf("invalid environment%s: %s (provider %s has no such environment)", envHint("ENVIRONMENT"), c.Environment, c.Provider)
+	}
+	if c.AuthURL == "" {
+		c.AuthURL = endpoints.AuthURL
+	}
+	if c.TokenURL == "" {
+		c.TokenURL = endpoints.TokenURL
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = endpoints.BaseURL
+	}
+	if c.WebSocketURL == "" {
+		c.WebSocketURL = endpoints.WebSocketURL
+	}
+	return c, nil
+}
+
+// validateEndpointURL accepts absolute http(s) and ws(s) URLs
+func validateEndpointURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "ws", "wss":
+	default:
+		return fmt.Errorf("%q: scheme must be http, https, ws or wss", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q: host missing", raw)
+	}
+	return nil
+}
+
+// oauthConfigs returns the OAuth2 configuration keyed by provider name
+// Call on a config returned by withDefaults
+func (c SaxoConfig) oauthConfigs() map[string]*oauth2.Config {
+	scopes := []string{"openapi"}
+	if provider, ok := LookupProvider(c.Provider); ok && len(provider.Scopes) > 0 {
+		scopes = provider.Scopes
+	}
+
+	return map[string]*oauth2.Config{
+		c.Provider: {
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			Scopes:       scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  c.AuthURL,
+				TokenURL: c.TokenURL,
+			},
+			RedirectURL: "", // Set dynamically by auth handlers
+		},
+	}
+}
+
+// logEnvironment logs the resolved endpoints, warning loudly for LIVE
+func (c SaxoConfig) logEnvironment(logger *slog.Logger) {
+	if c.Environment == SaxoLive {
+		logger.Warn("LIVE trading environment - real money at risk!",
+			"provider", c.Provider,
+			"environment", c.Environment,
+			"base_url", c.BaseURL,
+			"websocket_url", c.WebSocketURL)
+		return
+	}
+	logger.Info("Using trading environment",
+		"provider", c.Provider,
+		"environment", c.Environment,
+		"base_url", c.BaseURL,
+		"websocket_url", c.WebSocketURL)
+}
+
+// clientOptions returns the Options implied by the config
+func (c SaxoConfig) clientOptions() []Option {
+	var opts []Option
+	if c.Timeout > 0 {
+		opts = append(opts, WithTimeout(c.Timeout))
+	}
+	if c.Transport != nil {
+		opts = append(opts, WithTransport(*c.Transport))
+	}
+	if c.ManualOrders {
+		opts = append(opts, WithManualOrders(true))
+	}
+	if c.ClientKey != "" {
+		opts = append(opts, WithClientKey(c.ClientKey))
+	}
+	return opts
+}
@@ -0,0 +1,126 @@
+package saxo
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaxoConfig_Defaults(t *testing.T) {
+	config, err := SaxoConfig{ClientID: "id", ClientSecret: "secret"}.withDefaults()
+	if err != nil {
+		t.Fatalf("withDefaults failed: %v", err)
+	}
+	if config.Provider != DefaultProvider || config.Environment != SaxoSIM || config.TokenPath != "" {
+		t.Errorf("Unexpected defaults: %+v", config)
+	}
+	if config.BaseURL != "https://gateway.saxobank.com/sim/openapi" || config.TokenURL != "https://sim.logonvalidation.net/token" {
+		t.Errorf("SIM endpoints not applied: %+v", config)
+	}
+
+	// Overrides win over provider endpoints
+	config, err = SaxoConfig{ClientID: "id", ClientSecret: "secret", BaseURL: "http://localhost:9999"}.withDefaults()
+	if err != nil || config.BaseURL != "http://localhost:9999" || config.AuthURL == "" {
+		t.Errorf("BaseURL override: %+v, %v", config, err)
+	}
+
+	if err := (SaxoConfig{ClientSecret: "secret"}).Validate(); err == nil || !strings.Contains(err.Error(), "SAXO_CLIENT_ID") {
+		t.Errorf("Expected missing client ID error naming SAXO_CLIENT_ID, got %v", err)
+	}
+	if err := (SaxoConfig{ClientID: "id", ClientSecret: "secret", Environment: "demo"}).Validate(); err == nil {
+		t.Error("Expected error for unknown environment")
+	}
+
+	// Unregistered providers work when every endpoint is given
+	explicit := SaxoConfig{
+		Provider:     "unregistered",
+		ClientID:     "id",
+		ClientSecret: "secret",
+		AuthURL:      "https://auth.example/authorize",
+		TokenURL:     "https://auth.example/token",
+		BaseURL:      "https://api.example/openapi",
+		WebSocketURL: "https://stream.example/streaming/ws",
+	}
+	if err := explicit.Validate(); err != nil {
+		t.Errorf("Explicit endpoints rejected: %v", err)
+	}
+	explicit.WebSocketURL = ""
+	if err := explicit.Validate(); err == nil || !strings.Contains(err.Error(), "unknown provider") {
+		t.Errorf("Expected unknown provider error, got %v", err)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("PROVIDER", "")
+	t.Setenv("SAXO_CLIENT_ID", "env_id")
+	t.Setenv("SAXO_CLIENT_SECRET", "env_secret")
+	t.Setenv("SAXO_ENVIRONMENT", "live")
+	t.Setenv("TOKEN_STORAGE_PATH", "/tmp/tokens")
+
+	config := FromEnv()
+	if config.Provider != DefaultProvider || config.ClientID != "env_id" || config.ClientSecret != "env_secret" ||
+		config.Environment != SaxoLive || config.TokenPath != "/tmp/tokens" {
+		t.Errorf("Unexpected config from env: %+v", config)
+	}
+}
+
+func TestSaxoConfig_EndpointOverrides(t *testing.T) {
+	t.Setenv("PROVIDER", "")
+	t.Setenv("SAXO_CLIENT_ID", "env_id")
+	t.Setenv("SAXO_CLIENT_SECRET", "env_secret")
+	t.Setenv("SAXO_ENVIRONMENT", "")
+	t.Setenv("SAXO_BASE_URL", "https://proxy.corp.example/saxo/openapi/")
+	t.Setenv("SAXO_WEBSOCKET_URL", "wss://proxy.corp.example/saxo/streaming/ws")
+
+	// Overridden URLs replace the preset, the rest stays SIM
+	config, err := FromEnv().withDefaults()
+	if err != nil {
+		t.Fatalf("withDefaults failed: %v", err)
+	}
+	if config.BaseURL != "https://proxy.corp.example/saxo/openapi" || config.WebSocketURL != "wss://proxy.corp.example/saxo/streaming/ws" {
+		t.Errorf("Overrides not applied: %+v", config)
+	}
+	if config.AuthURL != "https://sim.logonvalidation.net/authorize" {
+		t.Errorf("AuthURL should keep the SIM preset, got %s", config.AuthURL)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	_, baseURL, _, _, err := LoadSaxoEnvironmentConfig(logger)
+	if err != nil || baseURL != "https://proxy.corp.example/saxo/openapi" {
+		t.Errorf("LoadSaxoEnvironmentConfig ignored the override: %s, %v", baseURL, err)
+	}
+
+	t.Setenv("SAXO_TOKEN_URL", "proxy.corp.example/token")
+	if err := FromEnv().Validate(); err == nil || !strings.Contains(err.Error(), "SAXO_TOKEN_URL") {
+		t.Errorf("Expected invalid override error naming SAXO_TOKEN_URL, got %v", err)
+	}
+}
+
+func TestCreateSaxoAuthClient_Config(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	authClient, err := CreateSaxoAuthClient(SaxoConfig{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		BaseURL:      "http://localhost:9999/sim/openapi",
+		TokenPath:    t.TempDir(),
+		Timeout:      5 * time.Second,
+	}, logger)
+	if err != nil {
+		t.Fatalf("CreateSaxoAuthClient failed: %v", err)
+	}
+	if authClient.GetBaseURL() != "http://localhost:9999/sim/openapi" {
+		t.Errorf("GetBaseURL() = %s", authClient.GetBaseURL())
+	}
+	if authClient.requestTimeout != 5*time.Second {
+		t.Errorf("requestTimeout = %v, want 5s", authClient.requestTimeout)
+	}
+	if authClient.GetOAuthConfig(DefaultProvider).ClientID != "id" {
+		t.Error("OAuth config not built from SaxoConfig")
+	}
+
+	if _, err := CreateSaxoAuthClient(SaxoConfig{}, logger); err == nil {
+		t.Error("Expected error for empty config")
+	}
+}
@@ -2,9 +2,11 @@ package saxo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
@@ -93,6 +95,11 @@ func (m *MockAuthClient) StartTokenEarlyRefresh(ctx context.Context, wsConnected
 	// Mock implementation - no-op for testing
 }
 
+// SubscribeTokenRefresh registers a token rotation listener (mock implementation - never fires)
+func (m *MockAuthClient) SubscribeTokenRefresh(name string, listener TokenListener) func() {
+	return func() {}
+}
+
 // ReauthorizeWebSocket reauthorizes WebSocket connection (mock implementation)
 func (m *MockAuthClient) ReauthorizeWebSocket(ctx context.Context, contextID string) error {
 	if m.shouldError {
@@ -120,6 +127,21 @@ func (m *MockAuthClient) GetAccessToken() (string, error) {
 	return m.accessToken, nil
 }
 
+// GetTokenExpiry returns a fixed 20-minute expiry (mock implementation)
+func (m *MockAuthClient) GetTokenExpiry() time.Time {
+	return time.Now().Add(20 * time.Minute)
+}
+
+// GetRefreshExpiry returns a fixed 1-hour refresh expiry (mock implementation)
+func (m *MockAuthClient) GetRefreshExpiry() time.Time {
+	return time.Now().Add(time.Hour)
+}
+
+// TokenEvents returns a channel that never fires (mock implementation)
+func (m *MockAuthClient) TokenEvents() <-chan TokenEvent {
+	return make(chan TokenEvent)
+}
+
 // createTestInstrument creates a mock enriched instrument for testing
 func createTestInstrument(ticker string, uic int, assetType string) Instrument {
 	return Instrument{
@@ -157,7 +179,7 @@ func TestSaxoBrokerClient_PlaceOrder(t *testing.T) {
 		Size:       1000,
 		Price:      1.0850,
 		OrderType:  "Limit",
-		Duration:   "DayOrder",
+		Duration:   Duration(DurationDayOrder),
 	}
 
 	// Expected response
@@ -232,20 +254,209 @@ func TestSaxoBrokerClient_CancelOrder(t *testing.T) {
 
 	// Verify results
 	if err != nil {
-		// Expected due to mock server path matching - CancelOrder includes query params
-		t.Skipf("CancelOrder failed due to mock server path matching: %v", err)
-		return
+		t.Fatalf("CancelOrder failed: %v", err)
 	}
 
 	// Verify request
-	requests := mockServer.GetRequests()
+	requests := mockServer.AssertRequested(t, "DELETE", "/trade/v2/orders/{orderId}", 1)
 	if len(requests) != 1 {
-		t.Fatalf("Expected 1 request, got %d", len(requests))
+		return
+	}
+	if got := requests[0].PathParams["orderId"]; got != "12345678" {
+		t.Errorf("Expected order ID 12345678 in path, got %q", got)
+	}
+	if requests[0].Query != "AccountKey=test_account_key" {
+		t.Errorf("Expected AccountKey query, got %q", requests[0].Query)
+	}
+}
+
+func TestSaxoBrokerClient_ModifyOrder(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	mockServer.SetResponse("PATCH", "/trade/v2/orders", 200, map[string]string{"OrderId": "12345678"})
+
+	authClient := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "mock_token",
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+
+	// Entry resized with an expiry, and the stop of its OCO pair moved in the same request
+	req := OrderModificationRequest{
+		OrderID:    "12345678",
+		AccountKey: "test_account_key",
+		OrderPrice: "1.0950",
+		OrderType:  "Limit",
+		AssetType:  "FxSpot",
+		Amount:     20000,
+	}
+	expiry := time.Now().AddDate(0, 2, 0)
+	req.OrderDuration = GoodTillDate(expiry)
+	stop := RelatedOrderModification{OrderID: "12345680", OrderPrice: "1.0920", OrderType: "StopIfTraded"}
+	stop.OrderDuration = Duration(DurationGoodTillCancel)
+	req.RelatedOrders = []RelatedOrderModification{stop}
+
+	if _, err := client.ModifyOrder(context.Background(), req); err != nil {
+		t.Fatalf("ModifyOrder failed: %v", err)
+	}
+
+	requests := mockServer.AssertRequested(t, "PATCH", "/trade/v2/orders", 1)
+	if len(requests) != 1 {
+		return
+	}
+	var body struct {
+		OrderID       string
+		Amount        float64
+		OrderDuration struct{ DurationType, ExpirationDateTime string }
+		Orders        []struct {
+			OrderID, OrderPrice, AccountKey, AssetType string
+			Amount                                     *float64
+		}
+	}
+	if err := json.Unmarshal([]byte(requests[0].Body), &body); err != nil {
+		t.Fatalf("Invalid PATCH body %q: %v", requests[0].Body, err)
+	}
+	if body.Amount != 20000 {
+		t.Errorf("Expected Amount 20000, got %v", body.Amount)
+	}
+	if body.OrderDuration.ExpirationDateTime != expiry.Format("2006-01-02")+"T00:00:00" {
+		t.Errorf("Expected ExpirationDateTime, got %+v", body.OrderDuration)
+	}
+	if len(body.Orders) != 1 {
+		t.Fatalf("Expected 1 related order, got %d", len(body.Orders))
+	}
+	related := body.Orders[0]
+	if related.OrderID != "12345680" || related.OrderPrice != "1.0920" {
+		t.Errorf("Unexpected related order %+v", related)
+	}
+	if related.AccountKey != "test_account_key" || related.AssetType != "FxSpot" {
+		t.Errorf("Related order should inherit AccountKey and AssetType, got %+v", related)
+	}
+	if related.Amount != nil {
+		t.Errorf("Related order without Amount should keep its size, got %v", *related.Amount)
+	}
+
+	// Invalid modifications fail before anything is sent
+	mockServer.ClearRequests()
+	req.OrderDuration = Duration(DurationGoodTillDate)
+	if _, err := client.ModifyOrder(context.Background(), req); err == nil {
+		t.Error("Expected GoodTillDate without expiry to fail")
+	}
+	mockServer.AssertRequested(t, "PATCH", "/trade/v2/orders", 0)
+}
+
+func TestSaxoBrokerClient_GetOpenOrdersFiltered(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	authClient := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "mock_token",
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+
+	mockServer.SetOpenOrdersResponse([]SaxoOpenOrder{
+		{OrderID: "1", Uic: 21, AssetType: "FxSpot", Status: "Working", AccountKey: "acc1"},
+		{OrderID: "2", Uic: 31, AssetType: "FxSpot", Status: "Working", AccountKey: "acc1"},
+		{OrderID: "3", Uic: 21, AssetType: "CfdOnFutures", Status: "Working", AccountKey: "acc1"},
+	})
+
+	ctx := context.Background()
+	orders, err := client.GetOpenOrdersFiltered(ctx, OpenOrdersParams{
+		Status:      []string{"Working"},
+		AccountKey:  "acc1",
+		ClientKey:   "client1",
+		Uic:         21,
+		AssetType:   "FxSpot",
+		FieldGroups: []string{"DisplayAndFormat"},
+	})
+	if err != nil {
+		t.Fatalf("GetOpenOrdersFiltered failed: %v", err)
+	}
+	if len(orders) != 1 || orders[0].OrderID != "1" {
+		t.Errorf("Expected only order 1 after Uic/AssetType filter, got %+v", orders)
+	}
+
+	// Status, account and field groups are sent to Saxo instead of filtering everything locally
+	requests := mockServer.GetRequests()
+	if len(requests) != 1 || requests[0].Path != "/port/v1/orders" {
+		t.Fatalf("Expected one request to /port/v1/orders, got %+v", requests)
+	}
+	for _, param := range []string{"Status=Working", "AccountKey=acc1", "ClientKey=client1", "FieldGroups=DisplayAndFormat"} {
+		if !strings.Contains(requests[0].Query, param) {
+			t.Errorf("Expected query to contain %s, got %s", param, requests[0].Query)
+		}
+	}
+
+	// Unfiltered GetOpenOrders keeps the default endpoint and field groups
+	mockServer.ClearRequests()
+	if orders, err = client.GetOpenOrders(ctx); err != nil || len(orders) != 3 {
+		t.Fatalf("GetOpenOrders returned %d orders, err %v", len(orders), err)
+	}
+	if got := mockServer.GetRequests()[0]; got.Path != "/port/v1/orders/me" || got.Query != "FieldGroups=DisplayAndFormat%2CExchangeInfo" {
+		t.Errorf("Unexpected default request %s?%s", got.Path, got.Query)
+	}
+}
+
+func TestSaxoBrokerClient_SearchInstrumentsPaged(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+
+	mockServer.SetHandler("GET", "/ref/v1/instruments", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("Keywords") != "S&P 500" || query.Get("AssetTypes") != "CfdOnIndex" || query.Get("$top") != "2" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		page := map[string]interface{}{"__count": 3}
+		if query.Get("$skip") == "0" {
+			next := url.Values{"Keywords": {"S&P 500"}, "AssetTypes": {"CfdOnIndex"}, "$top": {"2"}, "$skip": {"2"}}
+			page["__next"] = mockServer.GetBaseURL() + "/ref/v1/instruments?" + next.Encode()
+			page["Data"] = []map[string]interface{}{{"Identifier": 1, "Symbol": "US500.I"}, {"Identifier": 2, "Symbol": "US500M.I"}}
+		} else {
+			page["Data"] = []map[string]interface{}{{"Identifier": 3, "Symbol": "US500E.I"}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	})
+
+	ctx := context.Background()
+	params := InstrumentSearchParams{Keywords: "S&P 500", AssetType: "CfdOnIndex", Top: 2}
+	result, err := client.SearchInstruments(ctx, params)
+	if err != nil {
+		t.Fatalf("SearchInstruments failed: %v", err)
+	}
+	if result.TotalCount != 3 || len(result.Instruments) != 3 || result.Instruments[2].Uic != 3 {
+		t.Fatalf("Expected 3 instruments over 2 pages, got %+v", result)
+	}
+	if got := len(mockServer.GetRequests()); got != 2 {
+		t.Errorf("Expected 2 page requests, got %d", got)
+	}
+
+	// MaxResults stops paging; TotalCount still reports every match
+	mockServer.ClearRequests()
+	params.MaxResults = 2
+	if result, err = client.SearchInstruments(ctx, params); err != nil || len(result.Instruments) != 2 || result.TotalCount != 3 {
+		t.Fatalf("Expected 2 of 3 instruments, got %+v, err %v", result, err)
+	}
+	if got := len(mockServer.GetRequests()); got != 1 {
+		t.Errorf("Expected 1 page request with MaxResults, got %d", got)
 	}
 
-	req2 := requests[0]
-	if req2.Method != "DELETE" {
-		t.Errorf("Expected DELETE method, got %s", req2.Method)
+	// Filters are sent to Saxo
+	mockServer.ClearRequests()
+	client.SearchInstruments(ctx, InstrumentSearchParams{Uics: []int{21, 31}, IncludeNonTradable: true, CanParticipateInMultiLegOrder: true})
+	query := mockServer.GetRequests()[0].Query
+	for _, param := range []string{"Uics=21%2C31", "IncludeNonTradable=true", "CanParticipateInMultiLegOrder=true"} {
+		if !strings.Contains(query, param) {
+			t.Errorf("Expected query to contain %s, got %s", param, query)
+		}
 	}
 }
 
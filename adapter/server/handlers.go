@@ -0,0 +1,204 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// routes maps the gateway endpoints - request and response bodies are the adapter's Go types as JSON
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+
+	mux.HandleFunc("GET /broker/login", s.handleLoginStatus)
+	mux.HandleFunc("POST /broker/login", s.handleLogin)
+	mux.HandleFunc("POST /broker/logout", s.handleLogout)
+	mux.HandleFunc("GET /broker/session", s.handleSession)
+	s.oauth.Register(mux)
+
+	mux.HandleFunc("GET /broker/balance", s.requireSession(s.handleBalance))
+	mux.HandleFunc("GET /broker/accounts", s.requireSession(s.handleAccounts))
+	mux.HandleFunc("GET /broker/positions", s.requireSession(s.handlePositions))
+	mux.HandleFunc("GET /broker/orders", s.requireSession(s.handleOrders))
+	mux.HandleFunc("POST /broker/orders", s.requireSession(s.handlePlaceOrder))
+	mux.HandleFunc("DELETE /broker/orders/{id}", s.requireSession(s.handleCancelOrder))
+	mux.HandleFunc("GET /broker/price", s.requireSession(s.handlePrice))
+	mux.HandleFunc("GET /broker/stream/prices", s.requireSession(s.handlePriceStream))
+	return mux
+}
+
+// loginStatus is the GET /broker/login response
+type loginStatus struct {
+	Authenticated bool       `json:"authenticated"`
+	TokenExpiry   *time.Time `json:"token_expiry,omitempty"`
+	RefreshExpiry *time.Time `json:"refresh_expiry,omitempty"`
+	LoginURL      string     `json:"login_url,omitempty"` // POST /broker/login only, while not authenticated
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleLoginStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.loginStatus())
+}
+
+func (s *Server) loginStatus() loginStatus {
+	status := loginStatus{Authenticated: s.auth.IsAuthenticated()}
+	if expiry := s.auth.GetTokenExpiry(); !expiry.IsZero() {
+		status.TokenExpiry = &expiry
+	}
+	if expiry := s.auth.GetRefreshExpiry(); !expiry.IsZero() {
+		status.RefreshExpiry = &expiry
+	}
+	return status
+}
+
+// handleLogin returns the URL that starts the browser login (GET /oauth/{provider}/login)
+// The gateway does not block on the login - open login_url in a browser and poll GET /broker/login
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	status := s.loginStatus()
+	if !status.Authenticated {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		status.LoginURL = scheme + "://" + r.Host + s.oauth.LoginPath() + tokenQuery(s.config.Token)
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if err := s.auth.Logout(); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("logout failed: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.loginStatus())
+}
+
+// handleSession returns the auth client's SessionInfo - a failed owner lookup still returns the expiry fields
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	provider, ok := s.auth.(saxo.SessionInfoProvider)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("auth client does not report session info"))
+		return
+	}
+	info, err := provider.GetSessionInfo(r.Context())
+	if err != nil && info != nil {
+		s.logger.Warn("Session info incomplete",
+			"function", "handleSession",
+			"error", err)
+		err = nil
+	}
+	s.respond(w, "handleSession", info, err)
+}
+
+func (s *Server) handleBalance(w http.ResponseWriter, r *http.Request) {
+	balance, err := s.broker.GetBalance(r.Context())
+	s.respond(w, "handleBalance", balance, err)
+}
+
+func (s *Server) handleAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := s.broker.GetAccounts(r.Context())
+	s.respond(w, "handleAccounts", accounts, err)
+}
+
+func (s *Server) handlePositions(w http.ResponseWriter, r *http.Request) {
+	positions, err := s.broker.GetNetPositions(r.Context())
+	s.respond(w, "handlePositions", positions, err)
+}
+
+// handleOrders lists open orders - ?status=Working,Filled&account=KEY&uic=21&asset_type=FxSpot
+func (s *Server) handleOrders(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := saxo.OpenOrdersParams{
+		AccountKey: query.Get("account"),
+		AssetType:  query.Get("asset_type"),
+	}
+	if status := query.Get("status"); status != "" {
+		params.Status = strings.Split(status, ",")
+	}
+	if uic := query.Get("uic"); uic != "" {
+		n, err := strconv.Atoi(uic)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid uic %q", uic))
+			return
+		}
+		params.Uic = n
+	}
+
+	orders, err := s.broker.GetOpenOrdersFiltered(r.Context(), params)
+	s.respond(w, "handleOrders", orders, err)
+}
+
+func (s *Server) handlePlaceOrder(w http.ResponseWriter, r *http.Request) {
+	var req saxo.OrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid order request: %w", err))
+		return
+	}
+
+	response, err := s.broker.PlaceOrder(r.Context(), req)
+	if err == nil {
+		s.logger.Info("Order placed via gateway",
+			"function", "handlePlaceOrder",
+			"order_id", response.OrderID)
+	}
+	s.respond(w, "handlePlaceOrder", response, err)
+}
+
+// handleCancelOrder cancels /broker/orders/{id}?account=KEY
+func (s *Server) handleCancelOrder(w http.ResponseWriter, r *http.Request) {
+	req := saxo.CancelOrderRequest{
+		OrderID:    r.PathValue("id"),
+		AccountKey: r.URL.Query().Get("account"),
+	}
+	if err := s.broker.CancelOrder(r.Context(), req); err != nil {
+		s.respond(w, "handleCancelOrder", nil, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePrice returns the latest quote - ?uic=21&asset_type=FxSpot
+func (s *Server) handlePrice(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	uic, err := strconv.Atoi(query.Get("uic"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid uic %q", query.Get("uic")))
+		return
+	}
+	instrument := saxo.Instrument{
+		Identifier: uic,
+		Uic:        uic,
+		AssetType:  assetTypeOrDefault(query.Get("asset_type")),
+	}
+
+	price, err := s.broker.GetInstrumentPrice(r.Context(), instrument)
+	s.respond(w, "handlePrice", price, err)
+}
+
+// respond writes body, or logs err and maps it to 502 - the broker, not the gateway, failed
+func (s *Server) respond(w http.ResponseWriter, function string, body any, err error) {
+	if err != nil {
+		s.logger.Error("Broker request failed",
+			"function", function,
+			"error", err)
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+func assetTypeOrDefault(assetType string) string {
+	if assetType == "" {
+		return "FxSpot"
+	}
+	return assetType
+}
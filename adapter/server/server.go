@@ -0,0 +1,253 @@
+// Package server exposes an authenticated adapter session as a local REST + WebSocket gateway,
+// so non-Go components can share one Saxo login, one token keeper and one streaming connection
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket"
+	gws "github.com/gorilla/websocket"
+)
+
+// DefaultAddr binds to localhost only - the gateway carries an authenticated trading session
+const DefaultAddr = "127.0.0.1:8090"
+
+// Config configures the gateway - zero values use the defaults
+type Config struct {
+	Addr  string // Listen address, default DefaultAddr
+	Token string // Bearer token required on every request, "" = none (required when Addr is not loopback)
+
+	// OAuth configures the browser login behind POST /broker/login
+	// RedirectURL defaults to the gateway's own callback, SuccessURL to GET /broker/login
+	OAuth saxo.OAuthHandlerConfig
+}
+
+// PriceStreamer opens per-client price handles - *websocket.SaxoWebSocketClient satisfies it
+type PriceStreamer interface {
+	SubscribeInstruments(ctx context.Context, instruments []saxo.InstrumentRef, bufferSize int) (*websocket.PriceSubscription, error)
+}
+
+// Server is the REST + WebSocket gateway (see docs/ARCHITECTURE.md "HTTP Gateway")
+type Server struct {
+	auth       saxo.AuthClient
+	broker     saxo.BrokerClient
+	prices     PriceStreamer // nil = price streaming disabled
+	config     Config
+	logger     *slog.Logger
+	oauth      *saxo.OAuthHandlers
+	handler    http.Handler
+	httpServer *http.Server
+	upgrader   gws.Upgrader
+
+	// Active price streams - hijacked connections are not closed by http.Server.Shutdown
+	streamsMu sync.Mutex
+	streams   map[*gws.Conn]context.CancelFunc
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// Compile-time check that the gateway joins saxo.Shutdown
+var _ saxo.Shutdowner = (*Server)(nil)
+
+// New creates the gateway; prices may be nil when streaming is not needed
+func New(auth saxo.AuthClient, broker saxo.BrokerClient, prices PriceStreamer, config Config, logger *slog.Logger) (*Server, error) {
+	if config.Addr == "" {
+		config.Addr = DefaultAddr
+	}
+	if config.Token == "" && !isLoopback(config.Addr) {
+		return nil, fmt.Errorf("refusing to serve %s without a token: set Config.Token or bind to localhost", config.Addr)
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if config.OAuth.SuccessURL == "" {
+		config.OAuth.SuccessURL = "/broker/login" + tokenQuery(config.Token)
+	}
+
+	s := &Server{
+		auth:    auth,
+		broker:  broker,
+		prices:  prices,
+		config:  config,
+		logger:  logger,
+		oauth:   saxo.NewOAuthHandlers(auth, config.OAuth, logger),
+		streams: make(map[*gws.Conn]context.CancelFunc),
+		upgrader: gws.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 4096,
+		},
+	}
+	s.handler = s.rejectCrossSite(s.authorize(s.routes()))
+	s.httpServer = &http.Server{
+		Addr:              config.Addr,
+		Handler:           s.handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s, nil
+}
+
+// Handler returns the gateway's routes for embedding in another server or httptest
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// ListenAndServe serves until Shutdown, then returns nil
+func (s *Server) ListenAndServe() error {
+	s.logger.Info("HTTP gateway listening",
+		"function", "ListenAndServe",
+		"addr", s.config.Addr,
+		"token_required", s.config.Token != "",
+		"streaming", s.prices != nil)
+	if err := s.httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("gateway failed: %w", err)
+	}
+	return nil
+}
+
+// Shutdown implements saxo.Shutdowner - stops accepting requests and closes price streams
+// Shut the gateway down before the WebSocket, broker and auth clients it serves
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.streamsMu.Lock()
+	s.closed = true
+	for _, cancel := range s.streams {
+		cancel()
+	}
+	s.streamsMu.Unlock()
+
+	err := s.httpServer.Shutdown(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("price streams did not stop: %w", ctx.Err())
+	}
+	if err != nil {
+		return fmt.Errorf("gateway shutdown failed: %w", err)
+	}
+	s.logger.Info("HTTP gateway shut down",
+		"function", "Shutdown")
+	return nil
+}
+
+// authorize checks the bearer token (header, or ?token= for WebSocket clients that cannot set headers)
+func (s *Server) authorize(next http.Handler) http.Handler {
+	if s.config.Token == "" {
+		return next
+	}
+	want := []byte(s.config.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The auth server's redirect cannot carry the token - the callback checks state and cookie instead
+		if r.Method == http.MethodGet && r.URL.Path == s.oauth.CallbackPath() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if got == "" {
+			got = r.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), want) != 1 {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid or missing gateway token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rejectCrossSite blocks browser pages from driving the gateway
+// Without a token the Host must be loopback (defeats DNS rebinding); state-changing requests must
+// come from the gateway's own origin and carry JSON, which a cross-site form or simple fetch cannot send
+func (s *Server) rejectCrossSite(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.config.Token == "" && !isLoopbackHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %q not allowed without a gateway token", r.Host))
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if origin := r.Header.Get("Origin"); origin != "" {
+				u, err := url.Parse(origin)
+				if err != nil || u.Host != r.Host {
+					writeError(w, http.StatusForbidden, fmt.Errorf("cross-origin request from %q rejected", origin))
+					return
+				}
+			}
+			if contentType := r.Header.Get("Content-Type"); contentType != "" || r.ContentLength > 0 {
+				if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "application/json" {
+					writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("request body must be application/json"))
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireSession rejects broker calls before the Saxo login
+func (s *Server) requireSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.auth.IsAuthenticated() {
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("not authenticated with broker - POST /broker/login"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// tokenQuery is the ?token= suffix for URLs a browser opens, "" without a token
+func tokenQuery(token string) string {
+	if token == "" {
+		return ""
+	}
+	return "?token=" + url.QueryEscape(token)
+}
+
+// isLoopback reports whether addr only accepts local connections
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	return isLoopbackHost(host)
+}
+
+// isLoopbackHost reports whether a Host header (with or without port) names this machine
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,353 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/paper"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+	gws "github.com/gorilla/websocket"
+)
+
+// fakeAuth implements the AuthClient methods the gateway and WebSocket client use
+type fakeAuth struct {
+	saxo.AuthClient
+	authenticated bool
+	httpClient    *http.Client
+}
+
+func (f *fakeAuth) IsAuthenticated() bool           { return f.authenticated }
+func (f *fakeAuth) GetAccessToken() (string, error) { return "test_token_123", nil }
+func (f *fakeAuth) GetTokenExpiry() time.Time       { return time.Time{} }
+func (f *fakeAuth) GetRefreshExpiry() time.Time     { return time.Time{} }
+func (f *fakeAuth) Logout() error                   { f.authenticated = false; return nil }
+func (f *fakeAuth) GetSessionInfo(ctx context.Context) (*saxo.SessionInfo, error) {
+	return &saxo.SessionInfo{Authenticated: f.authenticated, Environment: saxo.SaxoSIM}, nil
+}
+func (f *fakeAuth) BuildRedirectURL(host string, provider string) string {
+	return "http://" + host + "/oauth/" + provider + "/callback"
+}
+func (f *fakeAuth) SetRedirectURL(provider string, redirectURL string) error { return nil }
+func (f *fakeAuth) GenerateAuthURL(provider string, state string) (string, error) {
+	return "https://sim.logonvalidation.net/authorize?state=" + url.QueryEscape(state), nil
+}
+func (f *fakeAuth) ExchangeCodeForToken(ctx context.Context, code string, provider string) error {
+	f.authenticated = true
+	return nil
+}
+func (f *fakeAuth) StartAuthenticationKeeper(provider string) {}
+func (f *fakeAuth) GetHTTPClient(ctx context.Context) (*http.Client, error) {
+	if f.httpClient != nil {
+		return f.httpClient, nil
+	}
+	return http.DefaultClient, nil
+}
+func (f *fakeAuth) ReauthorizeWebSocket(ctx context.Context, contextID string) error { return nil }
+func (f *fakeAuth) SubscribeTokenRefresh(name string, listener saxo.TokenListener) func() {
+	return func() {}
+}
+
+func newTestGateway(t *testing.T, auth *fakeAuth, prices PriceStreamer, config Config) *httptest.Server {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	broker := paper.NewPaperBrokerClient(paper.Config{}, logger)
+	t.Cleanup(func() { broker.Shutdown(context.Background()) })
+
+	s, err := New(auth, broker, prices, config, logger)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func doJSON(t *testing.T, method, url string, body any, out any) int {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, _ := http.NewRequest(method, url, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("Decode %s %s: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func dialStream(t *testing.T, ctx context.Context, url string) *gws.Conn {
+	t.Helper()
+	conn, _, err := gws.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		t.Fatalf("Stream dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+func TestNew_RequiresTokenOffLocalhost(t *testing.T) {
+	if _, err := New(&fakeAuth{}, nil, nil, Config{Addr: "0.0.0.0:8090"}, nil); err == nil {
+		t.Error("Expected error for non-loopback address without token")
+	}
+	if _, err := New(&fakeAuth{}, nil, nil, Config{Addr: "0.0.0.0:8090", Token: "secret"}, nil); err != nil {
+		t.Errorf("Token should allow non-loopback address: %v", err)
+	}
+	if _, err := New(&fakeAuth{}, nil, nil, Config{}, nil); err != nil {
+		t.Errorf("Default address should be accepted: %v", err)
+	}
+}
+
+func TestServer_LoginAndOrders(t *testing.T) {
+	auth := &fakeAuth{}
+	ts := newTestGateway(t, auth, nil, Config{})
+
+	// Broker endpoints are closed until login
+	if status := doJSON(t, "GET", ts.URL+"/broker/balance", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("Expected 401 before login, got %d", status)
+	}
+	var login loginStatus
+	if status := doJSON(t, "POST", ts.URL+"/broker/login", nil, &login); status != http.StatusOK || login.Authenticated || login.LoginURL == "" {
+		t.Fatalf("Login: status %d, %+v", status, login)
+	}
+	auth.authenticated = true // Browser login completed
+
+	var session saxo.SessionInfo
+	if status := doJSON(t, "GET", ts.URL+"/broker/session", nil, &session); status != http.StatusOK || !session.Authenticated || session.Environment != saxo.SaxoSIM {
+		t.Errorf("Session: status %d, %+v", status, session)
+	}
+
+	var balance saxo.Balance
+	if status := doJSON(t, "GET", ts.URL+"/broker/balance", nil, &balance); status != http.StatusOK || balance.CashBalance != 100000 {
+		t.Errorf("Balance: status %d, cash %v", status, balance.CashBalance)
+	}
+
+	order := saxo.OrderRequest{
+		Instrument: saxo.Instrument{Ticker: "EURUSD", Uic: 21, AssetType: "FxSpot"},
+		Side:       "Buy",
+		Size:       10000,
+		Price:      1.0950,
+		OrderType:  "Limit",
+		Duration:   saxo.Duration(saxo.DurationGoodTillCancel),
+	}
+	var placed saxo.OrderResponse
+	if status := doJSON(t, "POST", ts.URL+"/broker/orders", order, &placed); status != http.StatusOK || placed.OrderID == "" {
+		t.Fatalf("PlaceOrder: status %d, %+v", status, placed)
+	}
+
+	var orders []saxo.LiveOrder
+	if status := doJSON(t, "GET", ts.URL+"/broker/orders?uic=21", nil, &orders); status != http.StatusOK || len(orders) != 1 {
+		t.Fatalf("Orders: status %d, %d orders", status, len(orders))
+	}
+
+	if status := doJSON(t, "DELETE", ts.URL+"/broker/orders/"+placed.OrderID, nil, nil); status != http.StatusNoContent {
+		t.Errorf("Cancel: expected 204, got %d", status)
+	}
+	if status := doJSON(t, "DELETE", ts.URL+"/broker/orders/"+placed.OrderID, nil, nil); status != http.StatusBadGateway {
+		t.Errorf("Second cancel: expected 502, got %d", status)
+	}
+	if status := doJSON(t, "GET", ts.URL+"/broker/orders?uic=x", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("Invalid uic: expected 400, got %d", status)
+	}
+}
+
+func TestServer_LoginTwice(t *testing.T) {
+	ts := newTestGateway(t, &fakeAuth{}, nil, Config{})
+
+	// The gateway hands out the web login URL instead of running a login itself
+	for i := 0; i < 2; i++ {
+		var login loginStatus
+		if status := doJSON(t, "POST", ts.URL+"/broker/login", nil, &login); status != http.StatusOK {
+			t.Fatalf("Login %d: expected 200, got %d", i+1, status)
+		}
+		if login.Authenticated || login.LoginURL != ts.URL+"/oauth/saxo/login" {
+			t.Errorf("Login %d: expected login URL %s, got %+v", i+1, ts.URL+"/oauth/saxo/login", login)
+		}
+	}
+}
+
+func TestServer_WebLogin(t *testing.T) {
+	auth := &fakeAuth{}
+	ts := newTestGateway(t, auth, nil, Config{Token: "secret"})
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	req, _ := http.NewRequest("POST", ts.URL+"/broker/login", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /broker/login failed: %v", err)
+	}
+	var login loginStatus
+	json.NewDecoder(resp.Body).Decode(&login)
+	resp.Body.Close()
+	if login.LoginURL != ts.URL+"/oauth/saxo/login?token=secret" {
+		t.Fatalf("Expected login URL with token, got %q", login.LoginURL)
+	}
+
+	// The browser opens the login URL and is sent to the auth server with a state cookie
+	resp, err = noRedirect.Get(login.LoginURL)
+	if err != nil {
+		t.Fatalf("Login URL failed: %v", err)
+	}
+	resp.Body.Close()
+	authURL, _ := url.Parse(resp.Header.Get("Location"))
+	state := authURL.Query().Get("state")
+	if resp.StatusCode != http.StatusFound || state == "" || len(resp.Cookies()) != 1 {
+		t.Fatalf("Expected redirect with state and cookie, got %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	// The auth server's redirect carries no gateway token
+	req, _ = http.NewRequest("GET", ts.URL+"/oauth/saxo/callback?code=abc&state="+url.QueryEscape(state), nil)
+	req.AddCookie(resp.Cookies()[0])
+	resp, err = noRedirect.Do(req)
+	if err != nil {
+		t.Fatalf("Callback failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != "/broker/login?token=secret" {
+		t.Errorf("Expected redirect to login status, got %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	if !auth.authenticated {
+		t.Error("Expected gateway session to be authenticated after the callback")
+	}
+
+	// Only the callback is exempt from the token
+	resp, err = noRedirect.Get(ts.URL + "/oauth/saxo/login")
+	if err != nil {
+		t.Fatalf("Login without token failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for login without token, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_Token(t *testing.T) {
+	ts := newTestGateway(t, &fakeAuth{authenticated: true}, nil, Config{Token: "secret"})
+
+	if status := doJSON(t, "GET", ts.URL+"/healthz", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", status)
+	}
+	req, _ := http.NewRequest("GET", ts.URL+"/healthz", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("healthz failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 with token, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_RejectsCrossSiteRequests(t *testing.T) {
+	ts := newTestGateway(t, &fakeAuth{authenticated: true}, nil, Config{})
+
+	send := func(method, path, contentType, origin, host string) int {
+		t.Helper()
+		req, _ := http.NewRequest(method, ts.URL+path, strings.NewReader(`{}`))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if host != "" {
+			req.Host = host
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// A cross-site form or simple fetch can only send text/plain, urlencoded or multipart bodies
+	if status := send("POST", "/broker/orders", "text/plain", "", ""); status != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected 415 for text/plain order, got %d", status)
+	}
+	if status := send("POST", "/broker/login", "application/json", "https://evil.example", ""); status != http.StatusForbidden {
+		t.Errorf("Expected 403 for foreign Origin, got %d", status)
+	}
+	// DNS rebinding: a foreign host name resolving to 127.0.0.1
+	if status := send("GET", "/broker/session", "", "", "evil.example:8090"); status != http.StatusForbidden {
+		t.Errorf("Expected 403 for foreign Host, got %d", status)
+	}
+	if status := send("POST", "/broker/logout", "application/json; charset=utf-8", ts.URL, ""); status != http.StatusOK {
+		t.Errorf("Expected same-origin JSON request accepted, got %d", status)
+	}
+}
+
+func TestServer_PriceStreamFanOut(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+	mockServer.SetSnapshotQuote(21, 1.1000, 1.1002)
+
+	auth := &fakeAuth{authenticated: true, httpClient: mockServer.GetHTTPClient()}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ws := websocket.NewSaxoWebSocketClient(auth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := ws.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer ws.Close()
+
+	ts := newTestGateway(t, auth, ws, Config{})
+	streamURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/broker/stream/prices?uics=21"
+
+	// The first client widens the Saxo subscription and receives the snapshot
+	first := dialStream(t, ctx, streamURL)
+	var update saxo.PriceUpdate
+	if err := first.ReadJSON(&update); err != nil {
+		t.Fatalf("Snapshot read failed: %v", err)
+	}
+	if update.Uic != 21 || update.Bid != 1.1000 || !update.Snapshot {
+		t.Errorf("Unexpected snapshot %+v", update)
+	}
+
+	// The second client shares the subscription - a streamed quote reaches both
+	second := dialStream(t, ctx, streamURL)
+	if err := mockServer.SendPriceUpdate("21", 1.1010, 1.1012); err != nil {
+		t.Fatalf("SendPriceUpdate failed: %v", err)
+	}
+	for i, conn := range []*gws.Conn{first, second} {
+		var streamed saxo.PriceUpdate
+		if err := conn.ReadJSON(&streamed); err != nil {
+			t.Fatalf("Client %d read failed: %v", i, err)
+		}
+		if streamed.Uic != 21 || streamed.Bid != 1.1010 || streamed.Snapshot {
+			t.Errorf("Client %d: unexpected update %+v", i, streamed)
+		}
+	}
+
+	if status := doJSON(t, "GET", ts.URL+"/broker/stream/prices?uics=abc", nil, nil); status != http.StatusBadRequest {
+		t.Errorf("Invalid uics: expected 400, got %d", status)
+	}
+}
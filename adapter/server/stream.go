@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	gws "github.com/gorilla/websocket"
+)
+
+const (
+	streamBufferSize   = 256              // Per-client update buffer - slow clients drop, they never block the feed
+	streamWriteTimeout = 10 * time.Second // A client that cannot take a write within this is disconnected
+)
+
+// handlePriceStream upgrades to a WebSocket and streams saxo.PriceUpdate JSON messages
+// ?uics=21,31&asset_type=FxSpot - each client gets its own reference-counted price handle,
+// so clients share the single Saxo subscription and closing one never affects another
+func (s *Server) handlePriceStream(w http.ResponseWriter, r *http.Request) {
+	if s.prices == nil {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("price streaming not configured"))
+		return
+	}
+	instruments, err := parseInstruments(r.URL.Query().Get("uics"), r.URL.Query().Get("asset_type"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Subscribe before upgrading so failures still reach the client as a JSON error
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, err := s.prices.SubscribeInstruments(r.Context(), instruments, streamBufferSize)
+	if err != nil {
+		cancel()
+		s.respond(w, "handlePriceStream", nil, fmt.Errorf("price subscription failed: %w", err))
+		return
+	}
+
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade already wrote the HTTP error
+		cancel()
+		sub.Unsubscribe(context.Background())
+		return
+	}
+	if !s.track(conn, cancel) {
+		cancel()
+		sub.Unsubscribe(context.Background())
+		conn.Close()
+		return
+	}
+	defer s.untrack(conn)
+
+	s.logger.Info("Price stream client connected",
+		"function", "handlePriceStream",
+		"remote", r.RemoteAddr,
+		"instruments", len(instruments))
+
+	// Reader detects client close - the stream is send-only
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	s.pump(ctx, conn, sub.Updates())
+
+	unsubCtx, unsubCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer unsubCancel()
+	if err := sub.Unsubscribe(unsubCtx); err != nil {
+		s.logger.Warn("Failed to release price handle",
+			"function", "handlePriceStream",
+			"error", err)
+	}
+	conn.WriteControl(gws.CloseMessage,
+		gws.FormatCloseMessage(gws.CloseNormalClosure, ""),
+		time.Now().Add(time.Second))
+	conn.Close()
+
+	s.logger.Info("Price stream client disconnected",
+		"function", "handlePriceStream",
+		"remote", r.RemoteAddr,
+		"dropped", sub.Dropped())
+}
+
+// pump forwards updates until the client leaves, the gateway shuts down or the handle closes
+func (s *Server) pump(ctx context.Context, conn *gws.Conn, updates <-chan saxo.PriceUpdate) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if err := conn.WriteJSON(update); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// track registers a stream for Shutdown; false once the gateway is closing
+func (s *Server) track(conn *gws.Conn, cancel context.CancelFunc) bool {
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.streams[conn] = cancel
+	s.wg.Add(1)
+	return true
+}
+
+func (s *Server) untrack(conn *gws.Conn) {
+	s.streamsMu.Lock()
+	delete(s.streams, conn)
+	s.streamsMu.Unlock()
+	s.wg.Done()
+}
+
+// parseInstruments turns "21,31" into refs of one asset type (default FxSpot)
+func parseInstruments(uics string, assetType string) ([]saxo.InstrumentRef, error) {
+	if uics == "" {
+		return nil, fmt.Errorf("uics parameter required, e.g. ?uics=21,31")
+	}
+	assetType = assetTypeOrDefault(assetType)
+
+	var instruments []saxo.InstrumentRef
+	for _, field := range strings.Split(uics, ",") {
+		uic, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || uic <= 0 {
+			return nil, fmt.Errorf("invalid uic %q", field)
+		}
+		instruments = append(instruments, saxo.InstrumentRef{Uic: uic, AssetType: assetType})
+	}
+	return instruments, nil
+}
@@ -0,0 +1,150 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Saxo session trade levels
+// Only one session per user can hold FullTradingAndChat - taking it downgrades the other logins,
+// so read-only dashboards should stay on OrdersOnly
+const (
+	TradeLevelFullTradingAndChat = "FullTradingAndChat"
+	TradeLevelOrdersOnly         = "OrdersOnly"
+)
+
+// SessionCapabilities is the state of GET /root/v1/sessions/capabilities
+type SessionCapabilities struct {
+	AuthenticationLevel string `json:"AuthenticationLevel"`
+	DataLevel           string `json:"DataLevel"` // "Realtime", "Delayed"
+	TradeLevel          string `json:"TradeLevel"`
+}
+
+// SessionCapabilityChanged reports a change of the session's trade or data level
+type SessionCapabilityChanged struct {
+	Previous SessionCapabilities // Zero value for the first known state
+	Current  SessionCapabilities
+	Source   string // "query" (GetSessionCapabilities, SetTradeLevel) or "stream" (ObserveSessionUpdate)
+	Time     time.Time
+}
+
+// sessionCapabilityState remembers the last known capabilities to detect changes
+type sessionCapabilityState struct {
+	mu      sync.Mutex
+	current SessionCapabilities
+	known   bool
+	events  chan SessionCapabilityChanged
+	clock   Clock // Stamps change events (WithClock)
+}
+
+func newSessionCapabilityState(clock Clock) *sessionCapabilityState {
+	return &sessionCapabilityState{events: make(chan SessionCapabilityChanged, 16), clock: clock}
+}
+
+// record stores next and returns the change event, false when nothing changed
+// Empty fields in next keep their last known value (stream events may omit levels)
+func (s *sessionCapabilityState) record(next SessionCapabilities, source string) (SessionCapabilityChanged, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if next.AuthenticationLevel == "" {
+		next.AuthenticationLevel = s.current.AuthenticationLevel
+	}
+	if next.DataLevel == "" {
+		next.DataLevel = s.current.DataLevel
+	}
+	if next.TradeLevel == "" {
+		next.TradeLevel = s.current.TradeLevel
+	}
+	if s.known && next == s.current {
+		return SessionCapabilityChanged{}, false
+	}
+	event := SessionCapabilityChanged{Previous: s.current, Current: next, Source: source, Time: s.clock.Now()}
+	s.current, s.known = next, true
+	return event, true
+}
+
+// GetSessionCapabilities reads the current session's trade and data level
+// Reference: Saxo API GET /root/v1/sessions/capabilities
+func (sbc *SaxoBrokerClient) GetSessionCapabilities(ctx context.Context) (*SessionCapabilities, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", sbc.baseURL+"/root/v1/sessions/capabilities", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session capability request: %w", err)
+	}
+
+	resp, err := sbc.doRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("session capability request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, sbc.handleErrorResponse(resp)
+	}
+
+	var capabilities SessionCapabilities
+	if err := json.NewDecoder(resp.Body).Decode(&capabilities); err != nil {
+		return nil, fmt.Errorf("failed to decode session capabilities: %w", err)
+	}
+	sbc.recordSessionCapabilities(capabilities, "query")
+	return &capabilities, nil
+}
+
+// SetTradeLevel requests level for the current session and reads the result back
+// The adapter never changes the trade level on its own - applications decide whether to take
+// FullTradingAndChat. Saxo may apply the change asynchronously; the session event stream
+// (ObserveSessionUpdate) then reports it
+func (sbc *SaxoBrokerClient) SetTradeLevel(ctx context.Context, level string) error {
+	if level != TradeLevelFullTradingAndChat && level != TradeLevelOrdersOnly {
+		return fmt.Errorf("invalid trade level %q: must be %s or %s", level, TradeLevelFullTradingAndChat, TradeLevelOrdersOnly)
+	}
+	if err := sbc.SetSessionCapabilities(ctx, level); err != nil {
+		return err
+	}
+
+	capabilities, err := sbc.GetSessionCapabilities(ctx)
+	if err != nil {
+		return fmt.Errorf("trade level requested but capabilities could not be read: %w", err)
+	}
+	if capabilities.TradeLevel != level {
+		sbc.logger.Warn("Trade level not applied yet",
+			"function", "SetTradeLevel",
+			"requested", level,
+			"current", capabilities.TradeLevel)
+	}
+	return nil
+}
+
+// ObserveSessionUpdate feeds a session event from the WebSocket stream (GetSessionEventChannel)
+// into change detection, so SessionCapabilityEvents also reports downgrades by other logins
+func (sbc *SaxoBrokerClient) ObserveSessionUpdate(update SessionUpdate) {
+	sbc.recordSessionCapabilities(SessionCapabilities{TradeLevel: update.TradeLevel, DataLevel: update.DataLevel}, "stream")
+}
+
+// SessionCapabilityEvents delivers SessionCapabilityChanged; events are dropped when nobody reads
+func (sbc *SaxoBrokerClient) SessionCapabilityEvents() <-chan SessionCapabilityChanged {
+	return sbc.sessionCapabilities.events
+}
+
+func (sbc *SaxoBrokerClient) recordSessionCapabilities(capabilities SessionCapabilities, source string) {
+	event, changed := sbc.sessionCapabilities.record(capabilities, source)
+	if !changed {
+		return
+	}
+	sbc.logger.Info("Session capabilities changed",
+		"function", "recordSessionCapabilities",
+		"source", source,
+		"previous_trade_level", event.Previous.TradeLevel,
+		"trade_level", event.Current.TradeLevel,
+		"data_level", event.Current.DataLevel)
+
+	select {
+	case sbc.sessionCapabilities.events <- event:
+	default:
+		sbc.logger.Warn("Session capability event dropped - channel full",
+			"function", "recordSessionCapabilities")
+	}
+}
@@ -0,0 +1,66 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestSaxoBrokerClient_SessionCapabilities(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+	tradeLevel := TradeLevelOrdersOnly
+	mockServer.SetHandler("GET", "/root/v1/sessions/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(SessionCapabilities{AuthenticationLevel: "Complete", DataLevel: "Realtime", TradeLevel: tradeLevel})
+	})
+	mockServer.SetHandler("PATCH", "/root/v1/sessions/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		var req SessionCapabilities
+		json.NewDecoder(r.Body).Decode(&req)
+		tradeLevel = req.TradeLevel
+		w.WriteHeader(http.StatusAccepted)
+	})
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	ctx := context.Background()
+
+	capabilities, err := client.GetSessionCapabilities(ctx)
+	if err != nil || capabilities.TradeLevel != TradeLevelOrdersOnly {
+		t.Fatalf("Expected OrdersOnly, got %+v (%v)", capabilities, err)
+	}
+	if event := <-client.SessionCapabilityEvents(); event.Current.TradeLevel != TradeLevelOrdersOnly || event.Previous.TradeLevel != "" {
+		t.Errorf("Unexpected first event %+v", event)
+	}
+
+	// Reading the same state again is not a change
+	if _, err := client.GetSessionCapabilities(ctx); err != nil {
+		t.Fatalf("GetSessionCapabilities failed: %v", err)
+	}
+	select {
+	case event := <-client.SessionCapabilityEvents():
+		t.Errorf("Expected no event for unchanged state, got %+v", event)
+	default:
+	}
+
+	if err := client.SetTradeLevel(ctx, TradeLevelFullTradingAndChat); err != nil {
+		t.Fatalf("SetTradeLevel failed: %v", err)
+	}
+	event := <-client.SessionCapabilityEvents()
+	if event.Previous.TradeLevel != TradeLevelOrdersOnly || event.Current.TradeLevel != TradeLevelFullTradingAndChat || event.Source != "query" {
+		t.Errorf("Unexpected upgrade event %+v", event)
+	}
+
+	// Another login takes the trade level; the stream event keeps the data level it omits
+	client.ObserveSessionUpdate(SessionUpdate{TradeLevel: TradeLevelOrdersOnly})
+	event = <-client.SessionCapabilityEvents()
+	if event.Source != "stream" || event.Current.TradeLevel != TradeLevelOrdersOnly || event.Current.DataLevel != "Realtime" {
+		t.Errorf("Unexpected stream event %+v", event)
+	}
+
+	if err := client.SetTradeLevel(ctx, "Everything"); err == nil {
+		t.Error("Expected invalid trade level error")
+	}
+	mockServer.AssertRequested(t, "PATCH", "/root/v1/sessions/capabilities", 1)
+}
@@ -0,0 +1,151 @@
+package saxo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SessionInfo describes the logged-in session, e.g. for a dashboard showing when the bot needs a new login
+type SessionInfo struct {
+	Provider      string
+	Environment   SaxoEnvironment
+	Authenticated bool // False without a token or with an expired refresh token - Login is needed
+
+	TokenExpiry      time.Time     // Access token, refreshed automatically before it expires
+	RefreshExpiry    time.Time     // Refresh token - Login is needed after this
+	TokenRemaining   time.Duration // 0 once expired
+	RefreshRemaining time.Duration // 0 once expired
+
+	Scopes []string // Granted OAuth scopes: the token response's scope, else the requested scopes
+
+	// Token owner, from /port/v1/users/me (access token claims when that fails)
+	UserId    string
+	UserKey   string
+	ClientKey string
+	Name      string
+	SessionId string // Saxo session of the access token, "" when the token is not a JWT
+	AppKey    string // OpenAPI application of the access token, "" when the token is not a JWT
+}
+
+// SessionInfoProvider is implemented by auth clients that can describe their session (SaxoAuthClient)
+type SessionInfoProvider interface {
+	GetSessionInfo(ctx context.Context) (*SessionInfo, error)
+}
+
+// tokenClaims are the Saxo access token (JWT) claims SessionInfo reads
+type tokenClaims struct {
+	UserKey   string `json:"uid"`
+	ClientKey string `json:"cid"`
+	SessionId string `json:"sid"`
+	AppKey    string `json:"oaa"`
+	Scope     string `json:"scope"`
+}
+
+// GetSessionInfo returns token and refresh expiry, granted scopes, environment and the token owner
+// Without a usable token the result has Authenticated false and no error. When /port/v1/users/me
+// fails, the result is still returned, with the owner taken from the token claims, alongside the error
+func (sac *SaxoAuthClient) GetSessionInfo(ctx context.Context) (*SessionInfo, error) {
+	info := &SessionInfo{Provider: sac.provider, Environment: sac.environment}
+
+	sac.tokenMutex.RLock()
+	token := sac.currentToken
+	sac.tokenMutex.RUnlock()
+	if token.AccessToken == "" {
+		if stored, err := sac.getToken(sac.provider); err == nil {
+			token = stored
+		}
+	}
+	if token.AccessToken == "" {
+		return info, nil
+	}
+
+	now := sac.clock.Now()
+	info.TokenExpiry, info.RefreshExpiry = token.Expiry, token.RefreshExpiry
+	info.TokenRemaining = max(token.Expiry.Sub(now), 0)
+	info.RefreshRemaining = max(token.RefreshExpiry.Sub(now), 0)
+	info.Authenticated = info.TokenRemaining > 0 || info.RefreshRemaining > 0
+	if !info.Authenticated {
+		return info, nil
+	}
+
+	claims := parseTokenClaims(token.AccessToken)
+	info.Scopes = token.Scopes
+	if len(info.Scopes) == 0 && claims.Scope != "" {
+		info.Scopes = strings.Fields(claims.Scope)
+	}
+	if config := sac.providerConfigs[sac.provider]; len(info.Scopes) == 0 && config != nil {
+		info.Scopes = config.Scopes
+	}
+	info.UserKey, info.ClientKey = claims.UserKey, claims.ClientKey
+	info.SessionId, info.AppKey = claims.SessionId, claims.AppKey
+
+	user, err := sac.fetchUser(ctx)
+	if err != nil {
+		sac.logger.Warn("Session owner lookup failed, using token claims",
+			"function", "GetSessionInfo",
+			"error", err)
+		return info, fmt.Errorf("failed to get session owner: %w", err)
+	}
+	info.UserId, info.Name = user.UserID, user.Name
+	if user.UserKey != "" {
+		info.UserKey = user.UserKey
+	}
+	if user.ClientKey != "" {
+		info.ClientKey = user.ClientKey
+	}
+	return info, nil
+}
+
+// fetchUser reads GET /port/v1/users/me with the current token (refreshed when expired)
+func (sac *SaxoAuthClient) fetchUser(ctx context.Context) (*SaxoClientInfo, error) {
+	ctx, cancel := RequestContext(ctx, sac.requestTimeout)
+	defer cancel()
+
+	client, err := sac.GetHTTPClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", sac.baseURL+"/port/v1/users/me", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	applyRequestID(ctx, req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("users/me failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	var user SaxoClientInfo
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, fmt.Errorf("failed to decode users/me response: %w", err)
+	}
+	return &user, nil
+}
+
+// parseTokenClaims decodes the payload of a JWT access token without verifying it
+// Tokens that are not JWTs give empty claims
+func parseTokenClaims(accessToken string) tokenClaims {
+	var claims tokenClaims
+	parts := strings.Split(accessToken, ".")
+	if len(parts) != 3 {
+		return claims
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return claims
+	}
+	json.Unmarshal(payload, &claims)
+	return claims
+}
@@ -0,0 +1,78 @@
+package saxo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSaxoAuthClient_GetSessionInfo(t *testing.T) {
+	var usersMe int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/port/v1/users/me" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		usersMe++
+		if usersMe > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(SaxoClientInfo{UserID: "12345", UserKey: "user-key", ClientKey: "client-key", Name: "Test User"})
+	}))
+	defer server.Close()
+
+	payload, _ := json.Marshal(map[string]string{"uid": "claim-user", "cid": "claim-client", "sid": "session-1", "oaa": "app-1"})
+	jwt := "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+	now := time.Now()
+	sac := newTestSaxoAuthClient(t, server.URL, TokenInfo{
+		Provider:      "saxo",
+		AccessToken:   jwt,
+		Expiry:        now.Add(20 * time.Minute),
+		RefreshExpiry: now.Add(time.Hour),
+		Scopes:        []string{"openapi"},
+	})
+
+	info, err := sac.GetSessionInfo(context.Background())
+	if err != nil {
+		t.Fatalf("GetSessionInfo failed: %v", err)
+	}
+	if !info.Authenticated || info.Environment != SaxoSIM || info.Provider != "saxo" || !reflect.DeepEqual(info.Scopes, []string{"openapi"}) {
+		t.Errorf("Unexpected session %+v", info)
+	}
+	if info.TokenRemaining <= 19*time.Minute || info.RefreshRemaining <= 59*time.Minute {
+		t.Errorf("Unexpected remaining times %v / %v", info.TokenRemaining, info.RefreshRemaining)
+	}
+	if info.UserId != "12345" || info.UserKey != "user-key" || info.ClientKey != "client-key" || info.Name != "Test User" {
+		t.Errorf("Expected the owner from users/me, got %+v", info)
+	}
+	if info.SessionId != "session-1" || info.AppKey != "app-1" {
+		t.Errorf("Expected session and app from the token claims, got %+v", info)
+	}
+
+	// users/me failing still returns the claims
+	info, err = sac.GetSessionInfo(context.Background())
+	if err == nil || info == nil || info.ClientKey != "claim-client" || info.UserKey != "claim-user" || info.UserId != "" {
+		t.Errorf("Expected the token claims with an error, got %+v (%v)", info, err)
+	}
+}
+
+func TestSaxoAuthClient_GetSessionInfoWithoutToken(t *testing.T) {
+	sac := newTestSaxoAuthClient(t, "http://127.0.0.1:0", TokenInfo{})
+	info, err := sac.GetSessionInfo(context.Background())
+	if err != nil || info.Authenticated || !info.RefreshExpiry.IsZero() {
+		t.Errorf("Expected an unauthenticated session without error, got %+v (%v)", info, err)
+	}
+
+	expired := time.Now().Add(-time.Minute)
+	sac = newTestSaxoAuthClient(t, "http://127.0.0.1:0", TokenInfo{Provider: "saxo", AccessToken: "opaque", Expiry: expired, RefreshExpiry: expired})
+	info, err = sac.GetSessionInfo(context.Background())
+	if err != nil || info.Authenticated || info.TokenRemaining != 0 || info.RefreshRemaining != 0 {
+		t.Errorf("Expected an expired session, got %+v (%v)", info, err)
+	}
+}
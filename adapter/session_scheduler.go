@@ -0,0 +1,339 @@
+package saxo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SessionState is the daily session lifecycle state managed by SessionScheduler
+type SessionState string
+
+const (
+	// SessionOpen - WebSocket connected, trading allowed
+	SessionOpen SessionState = "Open"
+	// SessionMarketClosed - connected, but the trading schedule reports the market closed: trading paused
+	SessionMarketClosed SessionState = "MarketClosed"
+	// SessionMaintenance - inside a daily maintenance window: disconnected, trading paused
+	SessionMaintenance SessionState = "Maintenance"
+	// SessionWeekend - FX weekend close: disconnected, trading paused
+	SessionWeekend SessionState = "Weekend"
+)
+
+// DailyWindow is a UTC time-of-day range, e.g. 21:00-22:00 for Saxo's nightly maintenance
+// End before Start wraps past midnight
+type DailyWindow struct {
+	Start time.Duration // Offset from 00:00 UTC
+	End   time.Duration
+}
+
+// contains reports whether t (UTC) falls inside the window
+func (w DailyWindow) contains(t time.Time) bool {
+	offset := sinceMidnight(t)
+	if w.Start <= w.End {
+		return offset >= w.Start && offset < w.End
+	}
+	return offset >= w.Start || offset < w.End
+}
+
+// WeeklyWindow is a UTC range across days of the week, e.g. FX weekend Friday 21:00 - Sunday 21:00
+type WeeklyWindow struct {
+	StartDay time.Weekday
+	Start    time.Duration // Offset from 00:00 UTC on StartDay
+	EndDay   time.Weekday
+	End      time.Duration // Offset from 00:00 UTC on EndDay
+}
+
+// contains reports whether t (UTC) falls inside the window (wraps past Saturday)
+func (w WeeklyWindow) contains(t time.Time) bool {
+	offset := time.Duration(t.UTC().Weekday())*24*time.Hour + sinceMidnight(t)
+	start := time.Duration(w.StartDay)*24*time.Hour + w.Start
+	end := time.Duration(w.EndDay)*24*time.Hour + w.End
+	if start <= end {
+		return offset >= start && offset < end
+	}
+	return offset >= start || offset < end
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	t = t.UTC()
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// TradingScheduleProvider supplies trading phases - BrokerClient satisfies it
+type TradingScheduleProvider interface {
+	GetTradingSchedule(ctx context.Context, params TradingScheduleParams) (*TradingSchedule, error)
+}
+
+// SessionSchedulerConfig configures SessionScheduler - zero values use the defaults below
+type SessionSchedulerConfig struct {
+	// MaintenanceWindows disconnect the WebSocket daily (default 21:00-22:00 UTC)
+	MaintenanceWindows []DailyWindow
+	// Weekend disconnects over the FX weekend (nil = stay connected over the weekend)
+	Weekend *WeeklyWindow
+	// ScheduleInstrument selects the GetTradingSchedule data that pauses trading outside open phases
+	// Zero Uic = no schedule, only the fixed windows apply
+	ScheduleInstrument TradingScheduleParams
+	// OpenPhaseStates are schedule phase states where trading is allowed (default AutomatedTrading, Open)
+	OpenPhaseStates []string
+	// CheckInterval between state evaluations (default 30s)
+	CheckInterval time.Duration
+	// ScheduleRefresh interval for GetTradingSchedule (default 1h)
+	ScheduleRefresh time.Duration
+}
+
+// DefaultFXWeekend is the FX weekend close, Friday 21:00 UTC to Sunday 21:00 UTC
+func DefaultFXWeekend() *WeeklyWindow {
+	return &WeeklyWindow{StartDay: time.Friday, Start: 21 * time.Hour, EndDay: time.Sunday, End: 21 * time.Hour}
+}
+
+// SessionScheduler implements the 22:00 UTC connect / 21:00 UTC shutdown lifecycle:
+// it connects and disconnects the WebSocket around maintenance windows and the FX weekend,
+// and pauses trading whenever the trading schedule reports the market closed
+type SessionScheduler struct {
+	ws        WebSocketClient
+	schedules TradingScheduleProvider // nil = no schedule data
+	config    SessionSchedulerConfig
+	logger    *slog.Logger
+	now       func() time.Time // Replaced in tests
+
+	mu          sync.Mutex
+	state       SessionState
+	connected   bool
+	schedule    *TradingSchedule
+	lastRefresh time.Time
+
+	onConnect    func(ctx context.Context) error // Resubscribe hook after a scheduled connect
+	stateChannel chan<- SessionState
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSessionScheduler creates a scheduler for ws; schedules may be nil when ScheduleInstrument is unset
+func NewSessionScheduler(ws WebSocketClient, schedules TradingScheduleProvider, config SessionSchedulerConfig, logger *slog.Logger) *SessionScheduler {
+	if config.MaintenanceWindows == nil {
+		config.MaintenanceWindows = []DailyWindow{{Start: 21 * time.Hour, End: 22 * time.Hour}}
+	}
+	if len(config.OpenPhaseStates) == 0 {
+		config.OpenPhaseStates = DefaultOpenPhaseStates
+	}
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = 30 * time.Second
+	}
+	if config.ScheduleRefresh <= 0 {
+		config.ScheduleRefresh = time.Hour
+	}
+	return &SessionScheduler{
+		ws:        ws,
+		schedules: schedules,
+		config:    config,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// SetOnConnect registers a hook run after every scheduled Connect (e.g. to resubscribe prices)
+func (s *SessionScheduler) SetOnConnect(fn func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onConnect = fn
+}
+
+// SetStateChannel registers a channel receiving every state change (non-blocking send)
+func (s *SessionScheduler) SetStateChannel(ch chan<- SessionState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stateChannel = ch
+}
+
+// State returns the current session state ("" before Start)
+func (s *SessionScheduler) State() SessionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// TradingAllowed reports whether orders should be placed now - check before PlaceOrder
+func (s *SessionScheduler) TradingAllowed() bool {
+	return s.State() == SessionOpen
+}
+
+// Start evaluates the current state immediately, then keeps evaluating every CheckInterval
+func (s *SessionScheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("session scheduler already started")
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+
+	if err := s.evaluate(ctx); err != nil {
+		s.logger.Warn("Initial session evaluation failed",
+			"function", "Start",
+			"error", err)
+	}
+
+	go s.run(runCtx)
+	return nil
+}
+
+// Shutdown implements Shutdowner - stops the scheduler loop (the WebSocket is left as is)
+func (s *SessionScheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("session scheduler did not stop: %w", ctx.Err())
+	}
+}
+
+func (s *SessionScheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.evaluate(ctx); err != nil {
+				s.logger.Warn("Session evaluation failed",
+					"function", "run",
+					"error", err)
+			}
+		}
+	}
+}
+
+// evaluate refreshes schedule data when due, then applies the state for now
+func (s *SessionScheduler) evaluate(ctx context.Context) error {
+	now := s.now()
+	s.refreshSchedule(ctx, now)
+
+	state := s.stateAt(now)
+	s.mu.Lock()
+	previous := s.state
+	connected := s.connected
+	onConnect := s.onConnect
+	s.mu.Unlock()
+
+	var err error
+	shouldConnect := state == SessionOpen || state == SessionMarketClosed
+	switch {
+	case shouldConnect && !connected:
+		s.logger.Info("Connecting WebSocket for trading session",
+			"function", "evaluate",
+			"state", state)
+		if err = s.ws.Connect(ctx); err != nil {
+			return fmt.Errorf("scheduled connect failed: %w", err)
+		}
+		connected = true
+		if onConnect != nil {
+			if hookErr := onConnect(ctx); hookErr != nil {
+				err = fmt.Errorf("on-connect hook failed: %w", hookErr)
+			}
+		}
+	case !shouldConnect && connected:
+		s.logger.Info("Disconnecting WebSocket for session break",
+			"function", "evaluate",
+			"state", state)
+		if closeErr := s.ws.Close(); closeErr != nil {
+			err = fmt.Errorf("scheduled disconnect failed: %w", closeErr)
+		}
+		connected = false
+	}
+
+	s.mu.Lock()
+	s.state = state
+	s.connected = connected
+	stateChannel := s.stateChannel
+	s.mu.Unlock()
+
+	if state != previous {
+		s.logger.Info("Session state changed",
+			"function", "evaluate",
+			"from", previous,
+			"to", state,
+			"trading_allowed", state == SessionOpen)
+		if stateChannel != nil {
+			select {
+			case stateChannel <- state:
+			default:
+				s.logger.Warn("Session state channel full, dropping update",
+					"function", "evaluate")
+			}
+		}
+	}
+	return err
+}
+
+// stateAt derives the state from the fixed windows first, then the trading schedule
+func (s *SessionScheduler) stateAt(t time.Time) SessionState {
+	if s.config.Weekend != nil && s.config.Weekend.contains(t) {
+		return SessionWeekend
+	}
+	for _, window := range s.config.MaintenanceWindows {
+		if window.contains(t) {
+			return SessionMaintenance
+		}
+	}
+
+	s.mu.Lock()
+	schedule := s.schedule
+	s.mu.Unlock()
+	if schedule == nil {
+		return SessionOpen
+	}
+	phase, ok := schedule.PhaseAt(t)
+	if !ok {
+		// Not covered by schedule data - fixed windows are authoritative
+		return SessionOpen
+	}
+	if phase.isOpen(s.config.OpenPhaseStates) {
+		return SessionOpen
+	}
+	return SessionMarketClosed
+}
+
+// refreshSchedule reloads trading phases every ScheduleRefresh; failures keep the previous phases
+func (s *SessionScheduler) refreshSchedule(ctx context.Context, now time.Time) {
+	if s.schedules == nil || s.config.ScheduleInstrument.Uic == 0 {
+		return
+	}
+	s.mu.Lock()
+	due := s.lastRefresh.IsZero() || now.Sub(s.lastRefresh) >= s.config.ScheduleRefresh
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+
+	schedule, err := s.schedules.GetTradingSchedule(ctx, s.config.ScheduleInstrument)
+	if err != nil {
+		s.logger.Warn("Trading schedule refresh failed, keeping previous phases",
+			"function", "refreshSchedule",
+			"uic", s.config.ScheduleInstrument.Uic,
+			"error", err)
+		return
+	}
+
+	s.mu.Lock()
+	s.schedule = schedule
+	s.lastRefresh = now
+	s.mu.Unlock()
+}
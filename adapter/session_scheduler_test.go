@@ -0,0 +1,115 @@
+package saxo
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+// countingWebSocket counts Connect/Close calls made by the scheduler
+type countingWebSocket struct {
+	WebSocketClient
+	connects int
+	closes   int
+}
+
+func (c *countingWebSocket) Connect(ctx context.Context) error {
+	c.connects++
+	return nil
+}
+
+func (c *countingWebSocket) Close() error {
+	c.closes++
+	return nil
+}
+
+type staticSchedule struct {
+	schedule *TradingSchedule
+	calls    int
+}
+
+func (s *staticSchedule) GetTradingSchedule(ctx context.Context, params TradingScheduleParams) (*TradingSchedule, error) {
+	s.calls++
+	return s.schedule, nil
+}
+
+func TestSessionScheduler_Lifecycle(t *testing.T) {
+	ws := &countingWebSocket{}
+	// Wednesday 2024-01-03: market phase closed 12:00-13:00 UTC
+	day := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	schedules := &staticSchedule{schedule: &TradingSchedule{Sessions: []TradingPhase{
+		{StartTime: day, EndTime: day.Add(12 * time.Hour), State: "AutomatedTrading"},
+		{StartTime: day.Add(12 * time.Hour), EndTime: day.Add(13 * time.Hour), State: "Closed"},
+		{StartTime: day.Add(13 * time.Hour), EndTime: day.Add(48 * time.Hour), State: "AutomatedTrading"},
+	}}}
+
+	scheduler := NewSessionScheduler(ws, schedules, SessionSchedulerConfig{
+		Weekend:            DefaultFXWeekend(),
+		ScheduleInstrument: TradingScheduleParams{Uic: 21, AssetType: "FxSpot"},
+		CheckInterval:      time.Hour, // evaluate is driven manually below
+	}, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	now := day.Add(10 * time.Hour)
+	scheduler.now = func() time.Time { return now }
+	states := make(chan SessionState, 10)
+	scheduler.SetStateChannel(states)
+	resubscribed := 0
+	scheduler.SetOnConnect(func(ctx context.Context) error {
+		resubscribed++
+		return nil
+	})
+
+	ctx := context.Background()
+	if err := scheduler.Start(ctx); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer Shutdown(ctx, scheduler)
+
+	if !scheduler.TradingAllowed() || ws.connects != 1 || resubscribed != 1 {
+		t.Fatalf("Expected connected open session, got state %s, %d connects", scheduler.State(), ws.connects)
+	}
+
+	steps := []struct {
+		at      time.Time
+		want    SessionState
+		connect int
+		close   int
+	}{
+		{day.Add(12*time.Hour + 30*time.Minute), SessionMarketClosed, 1, 0}, // paused, stays connected
+		{day.Add(21*time.Hour + 15*time.Minute), SessionMaintenance, 1, 1},  // 21:00 UTC shutdown
+		{day.Add(22*time.Hour + 1*time.Minute), SessionOpen, 2, 1},          // 22:00 UTC connect
+		{day.Add(2*24*time.Hour + 21*time.Hour + 30*time.Minute), SessionWeekend, 2, 2},
+		{day.Add(4*24*time.Hour + 22*time.Hour), SessionOpen, 3, 2}, // Sunday 22:00
+	}
+	for _, step := range steps {
+		now = step.at
+		if err := scheduler.evaluate(ctx); err != nil {
+			t.Fatalf("Evaluate at %v failed: %v", step.at, err)
+		}
+		if scheduler.State() != step.want || ws.connects != step.connect || ws.closes != step.close {
+			t.Errorf("At %v: expected %s (%d connects, %d closes), got %s (%d, %d)",
+				step.at, step.want, step.connect, step.close, scheduler.State(), ws.connects, ws.closes)
+		}
+	}
+
+	if len(states) != 6 {
+		t.Errorf("Expected 6 state changes published, got %d", len(states))
+	}
+	if schedules.calls < 2 {
+		t.Errorf("Expected schedule refreshed hourly, got %d calls", schedules.calls)
+	}
+}
+
+func TestWeeklyWindow_WrapsWeekEnd(t *testing.T) {
+	// Saturday 22:00 - Monday 01:00 wraps past Saturday/Sunday numbering
+	window := WeeklyWindow{StartDay: time.Saturday, Start: 22 * time.Hour, EndDay: time.Monday, End: time.Hour}
+	sunday := time.Date(2024, 1, 7, 12, 0, 0, 0, time.UTC)
+	if !window.contains(sunday) || !window.contains(sunday.Add(12*time.Hour+30*time.Minute)) {
+		t.Error("Expected Sunday and early Monday inside the window")
+	}
+	if window.contains(sunday.Add(14 * time.Hour)) {
+		t.Error("Expected Monday 02:00 outside the window")
+	}
+}
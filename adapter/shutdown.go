@@ -0,0 +1,59 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Shutdowner is implemented by adapter components that own background goroutines,
+// server-side state or persisted state:
+// SaxoAuthClient, SaxoBrokerClient and websocket.SaxoWebSocketClient
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// Shutdown tears down adapter components in the order given and joins all errors
+// Pass streaming first, then broker, then auth so subscriptions are deleted while the token is still valid:
+//
+//	saxo.Shutdown(ctx, wsClient, brokerClient, authClient)
+//
+// Every component is shut down even if an earlier one fails; nil components are skipped
+func Shutdown(ctx context.Context, components ...Shutdowner) error {
+	var errs []error
+	for _, component := range components {
+		if isNilShutdowner(component) {
+			continue
+		}
+		if err := component.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", component, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isNilShutdowner catches typed nil pointers wrapped in the interface
+func isNilShutdowner(component Shutdowner) bool {
+	if component == nil {
+		return true
+	}
+	v := reflect.ValueOf(component)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// Shutdown implements Shutdowner
+// The REST client owns no goroutines - releases cached historical data and schedules
+func (sbc *SaxoBrokerClient) Shutdown(ctx context.Context) error {
+	sbc.cacheMutex.Lock()
+	sbc.historyCache = make(map[string]*cachedHistoricalData)
+	sbc.cacheMutex.Unlock()
+
+	sbc.invalidateSchedules()
+
+	sbc.responseCache.invalidate()
+
+	sbc.logger.Info("Broker client shut down",
+		"function", "Shutdown")
+	return nil
+}
@@ -0,0 +1,53 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+// recordingShutdowner records shutdown order for verification
+type recordingShutdowner struct {
+	name  string
+	order *[]string
+	err   error
+}
+
+func (r *recordingShutdowner) Shutdown(ctx context.Context) error {
+	*r.order = append(*r.order, r.name)
+	return r.err
+}
+
+func TestShutdown_OrderAndErrors(t *testing.T) {
+	var order []string
+	failure := errors.New("delete failed")
+
+	var nilBroker *SaxoBrokerClient
+	err := Shutdown(context.Background(),
+		&recordingShutdowner{name: "websocket", order: &order, err: failure},
+		nilBroker, // typed nil must be skipped
+		&recordingShutdowner{name: "auth", order: &order},
+	)
+
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected joined error to contain component failure, got %v", err)
+	}
+	if len(order) != 2 || order[0] != "websocket" || order[1] != "auth" {
+		t.Errorf("Expected shutdown order [websocket auth], got %v", order)
+	}
+}
+
+func TestSaxoBrokerClient_Shutdown(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(&MockAuthClient{authenticated: true}, "http://unused.invalid", logger)
+	client.historyCache["21_10"] = &cachedHistoricalData{}
+
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if len(client.historyCache) != 0 {
+		t.Errorf("Expected history cache to be cleared, got %d entries", len(client.historyCache))
+	}
+}
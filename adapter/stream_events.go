@@ -0,0 +1,48 @@
+package saxo
+
+import "time"
+
+// EventKind tags a StreamEvent
+type EventKind string
+
+// StreamEvent kinds - exactly the matching field of StreamEvent is set
+const (
+	PriceEvent      EventKind = "price"
+	OrderEvent      EventKind = "order"
+	PortfolioEvent  EventKind = "portfolio"
+	ConnectionEvent EventKind = "connection"
+	SessionEvent    EventKind = "session"
+)
+
+// StreamEvent is one streaming update of any kind, in arrival order
+// Switch on Kind and read the matching field; the struct marshals to JSON as-is for persistence
+type StreamEvent struct {
+	Kind EventKind `json:"kind"`
+	Seq  uint64    `json:"seq"` // Increases by one per delivered or dropped event, gaps mean dropped events
+	At   time.Time `json:"at"`
+
+	Price      *PriceUpdate      `json:"price,omitempty"`
+	Order      *OrderUpdate      `json:"order,omitempty"`
+	Portfolio  *PortfolioUpdate  `json:"portfolio,omitempty"`
+	Connection *ConnectionUpdate `json:"connection,omitempty"`
+	Session    *SessionUpdate    `json:"session,omitempty"`
+}
+
+// ConnectionUpdate is a connection state change of the stream
+type ConnectionUpdate struct {
+	Connected bool   `json:"connected"`
+	ContextID string `json:"context_id,omitempty"` // Set when connected
+
+	// Draining is set when the server announced a disconnect (Saxo's _disconnect) and a
+	// reconnect is scheduled after ReconnectAfter
+	Draining       bool          `json:"draining,omitempty"`
+	ReconnectAfter time.Duration `json:"reconnect_after,omitempty"`
+}
+
+// EventStreamer is implemented by WebSocket clients offering a single ordered event stream
+// Kept separate from WebSocketClient so replay/paper implementations need not support it
+type EventStreamer interface {
+	// Events switches the client to the unified stream: price, order, portfolio and session
+	// updates are delivered here instead of their own channels. Connection changes are added
+	Events() <-chan StreamEvent
+}
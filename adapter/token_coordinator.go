@@ -0,0 +1,163 @@
+package saxo
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// TokenListener is notified after every access token rotation, whoever triggered it
+// ctx is cancelled when the auth client stops; token is the newly stored token
+type TokenListener func(ctx context.Context, token TokenInfo)
+
+// TokenCoordinator is the single owner of early access token refresh
+// One timer fires earlyRefreshTime before expiry and rotates the token once (refreshTokenIfNeeded).
+// Every rotation - timer, expired token on a REST call, login - is then fanned out to subscribers,
+// e.g. the WebSocket client re-authorizing its stream. HTTP and WebSocket layers never run their own
+// refresh timers, so the same token is never rotated twice
+type TokenCoordinator struct {
+	sac     *SaxoAuthClient
+	logger  *slog.Logger
+	rotated chan struct{} // Signalled by storeToken (buffer 1, never closed)
+
+	mu        sync.Mutex
+	listeners map[int]namedTokenListener
+	nextID    int
+}
+
+type namedTokenListener struct {
+	name     string
+	listener TokenListener
+}
+
+func newTokenCoordinator(sac *SaxoAuthClient) *TokenCoordinator {
+	return &TokenCoordinator{
+		sac:       sac,
+		logger:    sac.logger,
+		rotated:   make(chan struct{}, 1),
+		listeners: make(map[int]namedTokenListener),
+	}
+}
+
+// SubscribeTokenRefresh implements AuthClient - see TokenCoordinator.Subscribe
+func (sac *SaxoAuthClient) SubscribeTokenRefresh(name string, listener TokenListener) (unsubscribe func()) {
+	return sac.coordinator.Subscribe(name, listener)
+}
+
+// Subscribe registers listener for token rotations and starts the coordinator timer
+// name is only used for logging. The returned func unsubscribes and is safe to call more than once
+func (tc *TokenCoordinator) Subscribe(name string, listener TokenListener) (unsubscribe func()) {
+	tc.mu.Lock()
+	id := tc.nextID
+	tc.nextID++
+	tc.listeners[id] = namedTokenListener{name: name, listener: listener}
+	tc.mu.Unlock()
+
+	tc.logger.Debug("Token refresh listener subscribed",
+		"function", "TokenCoordinator.Subscribe",
+		"listener", name)
+	tc.start()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			tc.mu.Lock()
+			delete(tc.listeners, id)
+			tc.mu.Unlock()
+		})
+	}
+}
+
+// notifyRotated wakes the coordinator after a token was stored (non-blocking)
+func (tc *TokenCoordinator) notifyRotated() {
+	select {
+	case tc.rotated <- struct{}{}:
+	default:
+	}
+}
+
+// start runs the coordinator goroutine at most once per auth lifecycle (no-op after Shutdown)
+// Subscribers survive Logout/Stop; the next Subscribe, StartAuthenticationKeeper or StartTokenEarlyRefresh restarts the timer
+func (tc *TokenCoordinator) start() {
+	lifecycle := tc.sac.currentLifecycle()
+	if lifecycle == nil {
+		tc.logger.Warn("Auth client shut down, not starting token coordinator",
+			"function", "TokenCoordinator.start")
+		return
+	}
+
+	lifecycle.coordinatorOnce.Do(func() {
+		lifecycle.wg.Add(1)
+		go tc.run(lifecycle)
+	})
+}
+
+func (tc *TokenCoordinator) run(lifecycle *authLifecycle) {
+	defer lifecycle.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := tc.sac.clock
+	next := clock.After(refreshInterval(tc.sac.GetTokenExpiry(), clock.Now()))
+	tc.logger.Info("Token coordinator started",
+		"function", "TokenCoordinator.run",
+		"expiry", tc.sac.GetTokenExpiry())
+
+	for {
+		select {
+		case <-lifecycle.stop:
+			tc.logger.Info("Stopping token coordinator",
+				"function", "TokenCoordinator.run")
+			return
+		case <-next:
+			// A successful rotation signals rotated - listeners run from there, exactly once
+			refreshCtx, refreshCancel := RequestContext(ctx, tc.sac.requestTimeout)
+			_, err := tc.sac.refreshTokenIfNeeded(refreshCtx, earlyRefreshTime)
+			refreshCancel()
+			if err != nil {
+				tc.logger.Error("Early token refresh failed",
+					"function", "TokenCoordinator.run",
+					"error", err)
+			}
+			next = clock.After(refreshInterval(tc.sac.GetTokenExpiry(), clock.Now()))
+		case <-tc.rotated:
+			tc.sac.tokenMutex.RLock()
+			token := tc.sac.currentToken
+			tc.sac.tokenMutex.RUnlock()
+			if token.AccessToken == "" {
+				continue
+			}
+			tc.notify(ctx, token)
+			next = clock.After(refreshInterval(token.Expiry, clock.Now()))
+		}
+	}
+}
+
+// notify calls every listener in turn; a panicking listener is logged and does not stop the others
+func (tc *TokenCoordinator) notify(ctx context.Context, token TokenInfo) {
+	tc.mu.Lock()
+	listeners := make([]namedTokenListener, 0, len(tc.listeners))
+	for _, l := range tc.listeners {
+		listeners = append(listeners, l)
+	}
+	tc.mu.Unlock()
+
+	tc.logger.Debug("Token rotated, notifying listeners",
+		"function", "TokenCoordinator.notify",
+		"listeners", len(listeners),
+		"expiry", token.Expiry)
+	for _, l := range listeners {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					tc.logger.Error("Panic in token refresh listener",
+						"function", "TokenCoordinator.notify",
+						"listener", l.name,
+						"panic", r)
+				}
+			}()
+			l.listener(ctx, token)
+		}()
+	}
+}
@@ -0,0 +1,164 @@
+package saxo
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+	"golang.org/x/oauth2"
+)
+
+func TestTokenCoordinator_OneRefreshFansOutToListeners(t *testing.T) {
+	var tokenCalls, authorizeCalls int32
+	server := newTestAuthServer(t, &tokenCalls, &authorizeCalls)
+	defer server.Close()
+
+	sac := newTestSaxoAuthClient(t, server.URL, TokenInfo{
+		Provider:     "saxo",
+		AccessToken:  "expired_token",
+		RefreshToken: "refresh_token",
+		Expiry:       time.Now().Add(-time.Second),
+	})
+	defer sac.Shutdown(context.Background())
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, server.Client())
+
+	// WebSocket layer: re-authorizes its stream on rotation, like SaxoWebSocketClient
+	wsDone := make(chan error, 4)
+	sac.SubscribeTokenRefresh("websocket", func(_ context.Context, token TokenInfo) {
+		wsDone <- sac.ReauthorizeWebSocket(ctx, "ctx-1")
+	})
+	// HTTP layer: just observes the new token
+	httpTokens := make(chan TokenInfo, 4)
+	unsubscribe := sac.SubscribeTokenRefresh("http", func(_ context.Context, token TokenInfo) {
+		httpTokens <- token
+	})
+
+	// Expired token on a REST call - rotation outside the coordinator timer still reaches every listener
+	if err := sac.RefreshToken(ctx); err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+
+	select {
+	case err := <-wsDone:
+		if err != nil {
+			t.Fatalf("Listener re-authorization failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for WebSocket listener")
+	}
+	select {
+	case token := <-httpTokens:
+		if token.AccessToken != "refreshed_token_1" {
+			t.Errorf("Expected rotated token, got %q", token.AccessToken)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for HTTP listener")
+	}
+
+	if got := atomic.LoadInt32(&tokenCalls); got != 1 {
+		t.Errorf("Expected exactly 1 token refresh, got %d", got)
+	}
+	if got := atomic.LoadInt32(&authorizeCalls); got != 1 {
+		t.Errorf("Expected 1 authorize call, got %d", got)
+	}
+
+	// Unsubscribed listeners are not called on the next rotation
+	unsubscribe()
+	unsubscribe()
+	sac.tokenMutex.Lock()
+	sac.currentToken.Expiry = time.Now().Add(-time.Second)
+	sac.tokenMutex.Unlock()
+	if err := sac.RefreshToken(ctx); err != nil {
+		t.Fatalf("Second RefreshToken failed: %v", err)
+	}
+	select {
+	case <-wsDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for WebSocket listener after second rotation")
+	}
+	select {
+	case token := <-httpTokens:
+		t.Errorf("Unsubscribed listener received %q", token.AccessToken)
+	default:
+	}
+}
+
+func TestTokenCoordinator_ListenerPanicDoesNotStopOthers(t *testing.T) {
+	var tokenCalls, authorizeCalls int32
+	server := newTestAuthServer(t, &tokenCalls, &authorizeCalls)
+	defer server.Close()
+
+	sac := newTestSaxoAuthClient(t, server.URL, TokenInfo{
+		Provider:    "saxo",
+		AccessToken: "fresh_token",
+		Expiry:      time.Now().Add(15 * time.Minute),
+	})
+	defer sac.Shutdown(context.Background())
+
+	called := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		sac.SubscribeTokenRefresh("panicky", func(context.Context, TokenInfo) {
+			called <- struct{}{}
+			panic("listener bug")
+		})
+	}
+
+	// Login stores a token like any other rotation
+	if err := sac.storeToken(sac.currentToken); err != nil {
+		t.Fatalf("storeToken failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-called:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected both listeners called, got %d", i)
+		}
+	}
+}
+
+func TestTokenCoordinator_RefreshesEighteenMinutesIntoToken(t *testing.T) {
+	// Plain HTTP: the coordinator refreshes with the default HTTP client, not a test-injected one
+	var tokenCalls, authorizeCalls int32
+	server := httptest.NewServer(testAuthHandler(&tokenCalls, &authorizeCalls))
+	defer server.Close()
+
+	clock := mocktesting.NewFakeClock(time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC))
+	sac := newTestSaxoAuthClient(t, server.URL, TokenInfo{
+		Provider:     "saxo",
+		AccessToken:  "initial_token",
+		RefreshToken: "refresh_token",
+		Expiry:       clock.Now().Add(20 * time.Minute),
+	}, WithClock(clock))
+	defer sac.Shutdown(context.Background())
+
+	rotated := make(chan TokenInfo, 1)
+	sac.SubscribeTokenRefresh("test", func(_ context.Context, token TokenInfo) {
+		rotated <- token
+	})
+	if err := clock.WaitForWaiters(1, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(18*time.Minute - time.Second)
+	select {
+	case token := <-rotated:
+		t.Fatalf("Refreshed before 18 minutes: %q", token.AccessToken)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case token := <-rotated:
+		if token.AccessToken != "refreshed_token_1" {
+			t.Errorf("Expected rotated token, got %q", token.AccessToken)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for refresh at 18 minutes")
+	}
+	if got := atomic.LoadInt32(&tokenCalls); got != 1 {
+		t.Errorf("Expected exactly 1 token refresh, got %d", got)
+	}
+}
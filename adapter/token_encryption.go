@@ -0,0 +1,129 @@
+package saxo
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"os"
+)
+
+// encryptedTokenMagic prefixes encrypted token files: magic | salt | nonce | AES-256-GCM ciphertext
+var encryptedTokenMagic = []byte("SAXOENC1")
+
+const (
+	tokenSaltSize = 16
+	// passphraseIterations follows the OWASP PBKDF2-HMAC-SHA256 recommendation
+	passphraseIterations = 600_000
+)
+
+// TokenKeyProvider supplies the 32-byte AES-256 key for encrypted token files
+// salt is random per write so passphrase-derived keys differ per file; KMS/Vault providers may ignore it
+type TokenKeyProvider interface {
+	TokenKey(salt []byte) ([]byte, error)
+}
+
+// TokenKeyFunc adapts a callback (e.g. a KMS decrypt or Vault read) to TokenKeyProvider
+type TokenKeyFunc func(salt []byte) ([]byte, error)
+
+// TokenKey implements TokenKeyProvider
+func (f TokenKeyFunc) TokenKey(salt []byte) ([]byte, error) {
+	return f(salt)
+}
+
+// PassphraseKey derives token keys from passphrase with PBKDF2-HMAC-SHA256
+func PassphraseKey(passphrase string) TokenKeyProvider {
+	return TokenKeyFunc(func(salt []byte) ([]byte, error) {
+		if passphrase == "" {
+			return nil, fmt.Errorf("empty token passphrase")
+		}
+		return pbkdf2.Key(sha256.New, passphrase, salt, passphraseIterations, 32)
+	})
+}
+
+// EnvPassphraseKey derives token keys from the passphrase in envVar, read on every use
+func EnvPassphraseKey(envVar string) TokenKeyProvider {
+	return TokenKeyFunc(func(salt []byte) ([]byte, error) {
+		passphrase := os.Getenv(envVar)
+		if passphrase == "" {
+			return nil, fmt.Errorf("%s not set", envVar)
+		}
+		return PassphraseKey(passphrase).TokenKey(salt)
+	})
+}
+
+// WithTokenEncryption encrypts token files with keys from keys
+// Existing plain files are still read and re-written encrypted on first load
+func WithTokenEncryption(keys TokenKeyProvider) FileTokenStorageOption {
+	return func(f *FileTokenStorage) {
+		f.keys = keys
+	}
+}
+
+// isEncryptedToken reports whether data was written by encryptToken
+func isEncryptedToken(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedTokenMagic)
+}
+
+// encryptToken seals plaintext, binding it to filename so files cannot be swapped between providers
+func encryptToken(keys TokenKeyProvider, filename string, plaintext []byte) ([]byte, error) {
+	salt := make([]byte, tokenSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	gcm, err := tokenCipher(keys, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(encryptedTokenMagic)+len(salt)+len(nonce)+len(plaintext)+gcm.Overhead())
+	out = append(out, encryptedTokenMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, []byte(filename)), nil
+}
+
+// decryptToken opens data written by encryptToken for the same filename
+func decryptToken(keys TokenKeyProvider, filename string, data []byte) ([]byte, error) {
+	data = data[len(encryptedTokenMagic):]
+	if len(data) < tokenSaltSize {
+		return nil, fmt.Errorf("encrypted token file truncated")
+	}
+	salt, data := data[:tokenSaltSize], data[tokenSaltSize:]
+	gcm, err := tokenCipher(keys, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted token file truncated")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt token (wrong key?): %w", err)
+	}
+	return plaintext, nil
+}
+
+func tokenCipher(keys TokenKeyProvider, salt []byte) (cipher.AEAD, error) {
+	key, err := keys.TokenKey(salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("token key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -7,38 +7,110 @@ import (
 	"path/filepath"
 )
 
+// LegacyTokenPath is the working-directory token location used before DefaultTokenDir
+// Tokens found there are moved to the new location on first load
+const LegacyTokenPath = "data"
+
+// DefaultTokenFileMode restricts token files to the owner
+const DefaultTokenFileMode os.FileMode = 0600
+
+// DefaultTokenDir returns os.UserConfigDir()/saxo-adapter, or LegacyTokenPath when no config dir exists
+func DefaultTokenDir() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return LegacyTokenPath
+	}
+	return filepath.Join(configDir, "saxo-adapter")
+}
+
 // FileTokenStorage implements TokenStorage interface using file-based persistence
 type FileTokenStorage struct {
-	basePath string
+	basePath   string
+	fileMode   os.FileMode
+	legacyPath string           // Directory to migrate tokens from, "" = no migration
+	keys       TokenKeyProvider // nil = plain files, see WithTokenEncryption
+}
+
+// FileTokenStorageOption configures NewFileTokenStorage
+type FileTokenStorageOption func(*FileTokenStorage)
+
+// WithTokenFileMode overrides DefaultTokenFileMode (e.g. 0640 for a shared group)
+func WithTokenFileMode(mode os.FileMode) FileTokenStorageOption {
+	return func(f *FileTokenStorage) {
+		f.fileMode = mode
+	}
+}
+
+// WithTokenMigration moves tokens from legacyPath on first load when they are missing from the storage directory
+func WithTokenMigration(legacyPath string) FileTokenStorageOption {
+	return func(f *FileTokenStorage) {
+		f.legacyPath = legacyPath
+	}
 }
 
 // NewTokenStorage creates a new file-based token storage
-// Stores tokens in the data/ directory by default
+// Stores tokens in TOKEN_STORAGE_PATH, or DefaultTokenDir (migrating from data/) by default
 func NewTokenStorage() TokenStorage {
-	basePath := os.Getenv("TOKEN_STORAGE_PATH")
+	return NewFileTokenStorage(os.Getenv("TOKEN_STORAGE_PATH"))
+}
+
+// NewFileTokenStorage creates a file-based token storage in basePath
+// "" = DefaultTokenDir, migrating tokens from LegacyTokenPath
+func NewFileTokenStorage(basePath string, opts ...FileTokenStorageOption) TokenStorage {
+	storage := &FileTokenStorage{
+		basePath: basePath,
+		fileMode: DefaultTokenFileMode,
+	}
 	if basePath == "" {
-		basePath = "data" // Default to data/ directory
+		storage.basePath = DefaultTokenDir()
+		storage.legacyPath = LegacyTokenPath
+	}
+	for _, opt := range opts {
+		opt(storage)
+	}
+	if storage.legacyPath != "" && filepath.Clean(storage.legacyPath) == filepath.Clean(storage.basePath) {
+		storage.legacyPath = ""
 	}
 
-	// Create directory if it doesn't exist
-	os.MkdirAll(basePath, 0700)
+	// Create directory if it doesn't exist - SaveToken retries, so read-only filesystems only fail on save
+	os.MkdirAll(storage.basePath, 0700)
 
-	return &FileTokenStorage{
-		basePath: basePath,
+	return storage
+}
+
+// mode returns the file permissions - a zero-value FileTokenStorage writes owner-only files
+func (f *FileTokenStorage) mode() os.FileMode {
+	if f.fileMode == 0 {
+		return DefaultTokenFileMode
 	}
+	return f.fileMode
+}
+
+// Path returns the file a token with filename is stored in
+func (f *FileTokenStorage) Path(filename string) string {
+	return filepath.Join(f.basePath, filename)
 }
 
 // SaveToken saves token to file
 func (f *FileTokenStorage) SaveToken(filename string, token *TokenInfo) error {
-	filePath := filepath.Join(f.basePath, filename)
+	filePath := f.Path(filename)
 
 	data, err := json.MarshalIndent(token, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal token: %w", err)
 	}
+	if f.keys != nil {
+		if data, err = encryptToken(f.keys, filename, data); err != nil {
+			return fmt.Errorf("failed to encrypt token: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(f.basePath, 0700); err != nil {
+		return fmt.Errorf("failed to create token directory: %w", err)
+	}
 
-	// Write with restricted permissions (owner only)
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
+	// Write with restricted permissions (owner only by default)
+	if err := os.WriteFile(filePath, data, f.mode()); err != nil {
 		return fmt.Errorf("failed to write token file: %w", err)
 	}
 
@@ -47,9 +119,12 @@ func (f *FileTokenStorage) SaveToken(filename string, token *TokenInfo) error {
 
 // LoadToken loads token from file
 func (f *FileTokenStorage) LoadToken(filename string) (*TokenInfo, error) {
-	filePath := filepath.Join(f.basePath, filename)
+	filePath := f.Path(filename)
 
 	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) && f.legacyPath != "" {
+		data, err = f.migrate(filename)
+	}
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, fmt.Errorf("token file not found: %s", filename)
@@ -57,17 +132,56 @@ func (f *FileTokenStorage) LoadToken(filename string) (*TokenInfo, error) {
 		return nil, fmt.Errorf("failed to read token file: %w", err)
 	}
 
+	encrypted := isEncryptedToken(data)
+	if encrypted {
+		if f.keys == nil {
+			return nil, fmt.Errorf("token file %s is encrypted but no key provider is configured", filename)
+		}
+		if data, err = decryptToken(f.keys, filename, data); err != nil {
+			return nil, err
+		}
+	}
+
 	var token TokenInfo
 	if err := json.Unmarshal(data, &token); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal token: %w", err)
 	}
 
+	// Upgrade plain files once encryption is enabled - a failure keeps the plain file readable
+	if f.keys != nil && !encrypted {
+		f.SaveToken(filename, &token)
+	}
+
 	return &token, nil
 }
 
+// migrate moves filename from legacyPath into basePath and returns its contents
+// A failed write leaves the legacy file in place, so the token is still used this run
+func (f *FileTokenStorage) migrate(filename string) ([]byte, error) {
+	legacyFile := filepath.Join(f.legacyPath, filename)
+	data, err := os.ReadFile(legacyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(f.basePath, 0700); err != nil {
+		return data, nil
+	}
+	if err := os.WriteFile(f.Path(filename), data, f.mode()); err != nil {
+		return data, nil
+	}
+	os.Remove(legacyFile)
+	return data, nil
+}
+
 // DeleteToken deletes token file
 func (f *FileTokenStorage) DeleteToken(filename string) error {
-	filePath := filepath.Join(f.basePath, filename)
+	filePath := f.Path(filename)
+
+	// Drop an unmigrated legacy copy too, else the next load would bring it back
+	if f.legacyPath != "" {
+		os.Remove(filepath.Join(f.legacyPath, filename))
+	}
 
 	if err := os.Remove(filePath); err != nil {
 		if os.IsNotExist(err) {
@@ -0,0 +1,129 @@
+package saxo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestFileTokenStorage_MigratesLegacyToken(t *testing.T) {
+	legacyDir := t.TempDir()
+	newDir := filepath.Join(t.TempDir(), "saxo-adapter")
+
+	legacy := &FileTokenStorage{basePath: legacyDir, fileMode: DefaultTokenFileMode}
+	if err := legacy.SaveToken("saxo_sim_token.bin", &TokenInfo{Provider: "saxo", AccessToken: "legacy"}); err != nil {
+		t.Fatalf("SaveToken failed: %v", err)
+	}
+
+	storage := NewFileTokenStorage(newDir, WithTokenMigration(legacyDir), WithTokenFileMode(0640)).(*FileTokenStorage)
+	token, err := storage.LoadToken("saxo_sim_token.bin")
+	if err != nil {
+		t.Fatalf("LoadToken failed: %v", err)
+	}
+	if token.AccessToken != "legacy" {
+		t.Errorf("AccessToken = %s, want legacy", token.AccessToken)
+	}
+
+	info, err := os.Stat(storage.Path("saxo_sim_token.bin"))
+	if err != nil {
+		t.Fatalf("Token not migrated: %v", err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("Migrated file mode = %v, want 0640", info.Mode().Perm())
+	}
+	if _, err := os.Stat(filepath.Join(legacyDir, "saxo_sim_token.bin")); !os.IsNotExist(err) {
+		t.Errorf("Legacy token still present after migration: %v", err)
+	}
+
+	if err := storage.DeleteToken("saxo_sim_token.bin"); err != nil {
+		t.Fatalf("DeleteToken failed: %v", err)
+	}
+	if _, err := storage.LoadToken("saxo_sim_token.bin"); err == nil {
+		t.Error("Token still loadable after DeleteToken")
+	}
+}
+
+func TestSaxoAuthClient_TokenFileTemplate(t *testing.T) {
+	configs := map[string]*oauth2.Config{"saxo": {ClientID: "app42"}}
+	storage := &FileTokenStorage{basePath: t.TempDir(), fileMode: DefaultTokenFileMode}
+
+	sac := NewSaxoAuthClient(configs, "", "", storage, SaxoLive, nil)
+	if got := sac.getTokenFilename("saxo"); got != "saxo_live_token.bin" {
+		t.Errorf("Default filename = %s, want saxo_live_token.bin", got)
+	}
+
+	sac = NewSaxoAuthClient(configs, "", "", storage, SaxoSIM, nil, WithTokenFileTemplate("{provider}-{client_id}-{environment}.json"))
+	if got := sac.getTokenFilename("saxo"); got != "saxo-app42-sim.json" {
+		t.Errorf("Templated filename = %s, want saxo-app42-sim.json", got)
+	}
+}
+
+func TestFileTokenStorage_Encryption(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{7}, 32)
+	keys := TokenKeyFunc(func(salt []byte) ([]byte, error) { return key, nil })
+
+	// A plain file written before encryption was enabled
+	plain := NewFileTokenStorage(dir)
+	if err := plain.SaveToken("saxo_live_token.bin", &TokenInfo{Provider: "saxo", RefreshToken: "secret_refresh"}); err != nil {
+		t.Fatalf("SaveToken failed: %v", err)
+	}
+
+	storage := NewFileTokenStorage(dir, WithTokenEncryption(keys)).(*FileTokenStorage)
+	token, err := storage.LoadToken("saxo_live_token.bin")
+	if err != nil || token.RefreshToken != "secret_refresh" {
+		t.Fatalf("LoadToken of plain file: %+v, %v", token, err)
+	}
+
+	// Upgraded in place on first load
+	data, err := os.ReadFile(storage.Path("saxo_live_token.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isEncryptedToken(data) || bytes.Contains(data, []byte("secret_refresh")) {
+		t.Fatal("Token file not encrypted after load")
+	}
+
+	token, err = storage.LoadToken("saxo_live_token.bin")
+	if err != nil || token.RefreshToken != "secret_refresh" {
+		t.Errorf("LoadToken of encrypted file: %+v, %v", token, err)
+	}
+
+	if _, err := plain.LoadToken("saxo_live_token.bin"); err == nil {
+		t.Error("Expected error loading encrypted file without a key provider")
+	}
+	wrongKey := NewFileTokenStorage(dir, WithTokenEncryption(TokenKeyFunc(func(salt []byte) ([]byte, error) {
+		return bytes.Repeat([]byte{8}, 32), nil
+	})))
+	if _, err := wrongKey.LoadToken("saxo_live_token.bin"); err == nil {
+		t.Error("Expected error loading with the wrong key")
+	}
+
+	// Bound to the filename - a renamed file does not decrypt
+	if err := os.Rename(storage.Path("saxo_live_token.bin"), storage.Path("saxo_sim_token.bin")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.LoadToken("saxo_sim_token.bin"); err == nil {
+		t.Error("Expected error loading a token file under another name")
+	}
+}
+
+func TestPassphraseKey(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	first, err := PassphraseKey("correct horse").TokenKey(salt)
+	if err != nil || len(first) != 32 {
+		t.Fatalf("PassphraseKey: %d bytes, %v", len(first), err)
+	}
+	second, _ := PassphraseKey("correct horse").TokenKey(salt)
+	if !bytes.Equal(first, second) {
+		t.Error("Same passphrase and salt derived different keys")
+	}
+
+	t.Setenv("TEST_TOKEN_PASSPHRASE", "")
+	if _, err := EnvPassphraseKey("TEST_TOKEN_PASSPHRASE").TokenKey(salt); err == nil {
+		t.Error("Expected error for unset passphrase variable")
+	}
+}
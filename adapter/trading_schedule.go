@@ -0,0 +1,238 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultOpenPhaseStates are the Saxo phase states treated as open by the TradingSchedule helpers
+var DefaultOpenPhaseStates = []string{"AutomatedTrading", "Open"}
+
+// allPhases returns the schedule's phases sorted by StartTime
+// Saxo fills Sessions on some endpoints and Phases on others
+func (s *SaxoTradingSchedule) allPhases() []SaxoTradingPhase {
+	phases := s.Sessions
+	if len(phases) == 0 {
+		phases = s.Phases
+	}
+	sorted := make([]SaxoTradingPhase, len(phases))
+	copy(sorted, phases)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].StartTime.Before(sorted[j].StartTime) })
+	return sorted
+}
+
+// PhaseAt returns the phase covering t, false when the schedule has no data for t
+// Phase times are absolute instants, so t may be in any time zone
+func (s *SaxoTradingSchedule) PhaseAt(t time.Time) (SaxoTradingPhase, bool) {
+	for _, phase := range s.allPhases() {
+		if !t.Before(phase.StartTime) && t.Before(phase.EndTime) {
+			return phase, true
+		}
+	}
+	return SaxoTradingPhase{}, false
+}
+
+// IsOpenAt reports whether t falls inside an open phase (DefaultOpenPhaseStates)
+// Times not covered by the schedule count as closed
+func (s *SaxoTradingSchedule) IsOpenAt(t time.Time) bool {
+	phase, ok := s.PhaseAt(t)
+	return ok && phase.isOpen(DefaultOpenPhaseStates)
+}
+
+// NextTransition returns when the market next opens or closes after t and whether it opens then
+// ok is false when the schedule has no further change
+func (s *SaxoTradingSchedule) NextTransition(t time.Time) (at time.Time, opens bool, ok bool) {
+	open := s.IsOpenAt(t)
+	for _, boundary := range s.boundariesAfter(t) {
+		if s.IsOpenAt(boundary) != open {
+			return boundary, !open, true
+		}
+	}
+	return time.Time{}, false, false
+}
+
+// NextOpen returns the next time after t the market opens (false if not in the schedule)
+func (s *SaxoTradingSchedule) NextOpen(t time.Time) (time.Time, bool) {
+	return s.nextChangeTo(t, true)
+}
+
+// NextClose returns the next time after t the market closes (false if not in the schedule)
+func (s *SaxoTradingSchedule) NextClose(t time.Time) (time.Time, bool) {
+	return s.nextChangeTo(t, false)
+}
+
+func (s *SaxoTradingSchedule) nextChangeTo(t time.Time, open bool) (time.Time, bool) {
+	for at, opens, ok := s.NextTransition(t); ok; at, opens, ok = s.NextTransition(at) {
+		if opens == open {
+			return at, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// boundariesAfter lists phase start/end times after t in chronological order
+func (s *SaxoTradingSchedule) boundariesAfter(t time.Time) []time.Time {
+	var boundaries []time.Time
+	for _, phase := range s.allPhases() {
+		for _, boundary := range []time.Time{phase.StartTime, phase.EndTime} {
+			if boundary.After(t) {
+				boundaries = append(boundaries, boundary)
+			}
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+	return boundaries
+}
+
+// isOpen reports whether the phase state is one of openStates
+func (p SaxoTradingPhase) isOpen(openStates []string) bool {
+	for _, state := range openStates {
+		if p.State == state {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedSchedule is a trading schedule fetched on Day (UTC, "2006-01-02")
+type cachedSchedule struct {
+	Schedule *TradingSchedule
+	Day      string
+}
+
+// IsMarketOpen implements BrokerClient.IsMarketOpen
+// Schedules are cached per instrument per UTC day, so calling this before every order is cheap
+func (sbc *SaxoBrokerClient) IsMarketOpen(ctx context.Context, instrument Instrument) (bool, error) {
+	if instrument.Identifier == 0 || instrument.AssetType == "" {
+		return false, fmt.Errorf("instrument %s not enriched: UIC and asset type are required", instrument.Ticker)
+	}
+
+	schedule, err := sbc.cachedTradingSchedule(ctx, instrument)
+	if err != nil {
+		return false, err
+	}
+	return schedule.IsOpenAt(sbc.clock.Now()), nil
+}
+
+// scheduleBatchConcurrency bounds the parallel schedule requests of GetTradingSchedules
+const scheduleBatchConcurrency = 4
+
+// GetTradingSchedule returns the trading schedule of an instrument, fetched from Saxo at most once
+// per instrument per UTC day - the cached schedule is dropped when the day changes
+// WithoutCache(ctx) forces a fetch; InvalidateCache(CacheTradingSchedules) drops all schedules
+// Cached schedules are shared between callers and must not be modified
+func (sbc *SaxoBrokerClient) GetTradingSchedule(ctx context.Context, params TradingScheduleParams) (*TradingSchedule, error) {
+	if !cacheBypassed(ctx) {
+		if schedule, ok := sbc.cachedScheduleFor(params); ok {
+			return schedule, nil
+		}
+	}
+
+	day := sbc.clock.Now().UTC().Format("2006-01-02")
+	schedule, err := sbc.fetchTradingSchedule(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	sbc.scheduleCacheMu.Lock()
+	sbc.scheduleCache[scheduleCacheKey(params)] = &cachedSchedule{Schedule: schedule, Day: day}
+	sbc.scheduleCacheMu.Unlock()
+	return schedule, nil
+}
+
+// GetTradingSchedules returns the trading schedules of many instruments, in the order of params
+// Cached schedules are returned without a request and duplicates are fetched once. The others go out
+// scheduleBatchConcurrency at a time, waiting while the client's rate limits (RateLimits) report a
+// pause. Failed instruments are nil in the result and joined into the error
+func (sbc *SaxoBrokerClient) GetTradingSchedules(ctx context.Context, params []TradingScheduleParams) ([]*TradingSchedule, error) {
+	schedules := make([]*TradingSchedule, len(params))
+	pending := make(map[string][]int) // Cache key -> indexes in params
+	for i, p := range params {
+		if schedule, ok := sbc.cachedScheduleFor(p); ok && !cacheBypassed(ctx) {
+			schedules[i] = schedule
+			continue
+		}
+		key := scheduleCacheKey(p)
+		pending[key] = append(pending[key], i)
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	sem := make(chan struct{}, scheduleBatchConcurrency)
+	var wg sync.WaitGroup
+	for _, indexes := range pending {
+		p := params[indexes[0]]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			schedule, err := sbc.fetchScheduleWithinRateLimits(ctx, sem, p)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("trading schedule %d/%s: %w", p.Uic, p.AssetType, err))
+				return
+			}
+			for _, i := range indexes {
+				schedules[i] = schedule
+			}
+		}()
+	}
+	wg.Wait()
+
+	sbc.logger.Info("Trading schedules retrieved",
+		"function", "GetTradingSchedules",
+		"instruments", len(params),
+		"fetched", len(pending),
+		"failed", len(errs))
+	return schedules, errors.Join(errs...)
+}
+
+// fetchScheduleWithinRateLimits calls GetTradingSchedule holding a slot of sem, after any rate-limit pause
+func (sbc *SaxoBrokerClient) fetchScheduleWithinRateLimits(ctx context.Context, sem chan struct{}, params TradingScheduleParams) (*TradingSchedule, error) {
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-sem }()
+
+	if err := sbc.waitRateLimit(ctx); err != nil {
+		return nil, err
+	}
+	return sbc.GetTradingSchedule(ctx, params)
+}
+
+// cachedScheduleFor returns the schedule cached for params today (UTC)
+func (sbc *SaxoBrokerClient) cachedScheduleFor(params TradingScheduleParams) (*TradingSchedule, bool) {
+	day := sbc.clock.Now().UTC().Format("2006-01-02")
+	sbc.scheduleCacheMu.RLock()
+	cached, exists := sbc.scheduleCache[scheduleCacheKey(params)]
+	sbc.scheduleCacheMu.RUnlock()
+	if !exists || cached.Day != day {
+		return nil, false
+	}
+	return cached.Schedule, true
+}
+
+// invalidateSchedules drops all cached trading schedules
+func (sbc *SaxoBrokerClient) invalidateSchedules() {
+	sbc.scheduleCacheMu.Lock()
+	sbc.scheduleCache = make(map[string]*cachedSchedule)
+	sbc.scheduleCacheMu.Unlock()
+}
+
+func scheduleCacheKey(params TradingScheduleParams) string {
+	return fmt.Sprintf("%d_%s", params.Uic, params.AssetType)
+}
+
+// cachedTradingSchedule returns instrument's trading schedule, fetched at most once per UTC day
+func (sbc *SaxoBrokerClient) cachedTradingSchedule(ctx context.Context, instrument Instrument) (*TradingSchedule, error) {
+	schedule, err := sbc.GetTradingSchedule(ctx, TradingScheduleParams{Uic: instrument.Identifier, AssetType: instrument.AssetType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading schedule: %w", err)
+	}
+	return schedule, nil
+}
@@ -0,0 +1,138 @@
+package saxo
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestTradingSchedule_Helpers(t *testing.T) {
+	day := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	// Unsorted on purpose, with a gap 17:00-18:00 that counts as closed
+	schedule := &TradingSchedule{Phases: []TradingPhase{
+		{StartTime: day.Add(18 * time.Hour), EndTime: day.Add(24 * time.Hour), State: "AutomatedTrading"},
+		{StartTime: day, EndTime: day.Add(9 * time.Hour), State: "Closed"},
+		{StartTime: day.Add(9 * time.Hour), EndTime: day.Add(17 * time.Hour), State: "AutomatedTrading"},
+	}}
+
+	// Times in another zone compare as instants
+	newYork := time.FixedZone("EST", -5*60*60)
+	if !schedule.IsOpenAt(day.Add(10 * time.Hour).In(newYork)) {
+		t.Error("Expected open at 10:00 UTC")
+	}
+	if schedule.IsOpenAt(day.Add(17*time.Hour + 30*time.Minute)) {
+		t.Error("Expected closed inside the schedule gap")
+	}
+
+	at, opens, ok := schedule.NextTransition(day.Add(8 * time.Hour))
+	if !ok || !opens || !at.Equal(day.Add(9*time.Hour)) {
+		t.Errorf("Expected open transition at 09:00, got %v opens=%v ok=%v", at, opens, ok)
+	}
+	if next, ok := schedule.NextClose(day.Add(8 * time.Hour)); !ok || !next.Equal(day.Add(17*time.Hour)) {
+		t.Errorf("Expected next close 17:00, got %v", next)
+	}
+	if next, ok := schedule.NextOpen(day.Add(10 * time.Hour)); !ok || !next.Equal(day.Add(18*time.Hour)) {
+		t.Errorf("Expected next open 18:00 after the gap, got %v", next)
+	}
+	if _, ok := schedule.NextOpen(day.Add(19 * time.Hour)); ok {
+		t.Error("Expected no open beyond the schedule")
+	}
+}
+
+func TestSaxoBrokerClient_IsMarketOpen(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	authClient := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "mock_token",
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), logger)
+
+	now := time.Now().UTC()
+	mockServer.SetTradingScheduleResponse(21, "FxSpot", SaxoTradingSchedule{Phases: []SaxoTradingPhase{
+		{StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour), State: "AutomatedTrading"},
+	}})
+
+	ctx := context.Background()
+	instrument := createTestInstrument("EURUSD", 21, "FxSpot")
+	for i := 0; i < 3; i++ {
+		open, err := client.IsMarketOpen(ctx, instrument)
+		if err != nil || !open {
+			t.Fatalf("Expected market open, got %v (err %v)", open, err)
+		}
+	}
+	if got := len(mockServer.GetRequests()); got != 1 {
+		t.Errorf("Expected schedule fetched once per day, got %d requests", got)
+	}
+
+	if _, err := client.IsMarketOpen(ctx, Instrument{Ticker: "EURUSD"}); err == nil {
+		t.Error("Expected error for un-enriched instrument")
+	}
+}
+
+func TestSaxoBrokerClient_TradingScheduleCache(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	clock := mocktesting.NewFakeClock(time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC))
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), slog.New(slog.NewTextHandler(io.Discard, nil)), WithClock(clock))
+
+	day := clock.Now()
+	for uic := 1; uic <= 3; uic++ {
+		mockServer.SetTradingScheduleResponse(uic, "FxSpot", SaxoTradingSchedule{Phases: []SaxoTradingPhase{
+			{StartTime: day, EndTime: day.Add(time.Duration(uic) * time.Hour), State: "AutomatedTrading"},
+		}})
+	}
+	const pattern = "/ref/v1/instruments/tradingschedule/{uic}/{assetType}"
+	ctx := context.Background()
+
+	if _, err := client.GetTradingSchedule(ctx, TradingScheduleParams{Uic: 1, AssetType: "FxSpot"}); err != nil {
+		t.Fatalf("GetTradingSchedule failed: %v", err)
+	}
+	// UIC 1 is cached, UIC 2 is asked for twice, UIC 9 has no schedule
+	params := []TradingScheduleParams{
+		{Uic: 1, AssetType: "FxSpot"}, {Uic: 2, AssetType: "FxSpot"}, {Uic: 9, AssetType: "FxSpot"},
+		{Uic: 3, AssetType: "FxSpot"}, {Uic: 2, AssetType: "FxSpot"},
+	}
+	schedules, err := client.GetTradingSchedules(ctx, params)
+	if err == nil || !strings.Contains(err.Error(), "trading schedule 9/FxSpot") {
+		t.Errorf("Expected the error of UIC 9, got %v", err)
+	}
+	for i, want := range []int{1, 2, 0, 3, 2} {
+		if want == 0 {
+			if schedules[i] != nil {
+				t.Errorf("Expected no schedule for UIC 9, got %+v", schedules[i])
+			}
+			continue
+		}
+		if schedules[i] == nil || !schedules[i].Phases[0].EndTime.Equal(day.Add(time.Duration(want)*time.Hour)) {
+			t.Errorf("Unexpected schedule %d: %+v", i, schedules[i])
+		}
+	}
+	mockServer.AssertRequested(t, "GET", pattern, 4) // 1, then 2, 9 and 3
+
+	// The same day is served from the cache; the next day, WithoutCache and InvalidateCache fetch again
+	clock.Advance(17 * time.Hour)
+	if _, err := client.GetTradingSchedules(ctx, params[:2]); err != nil {
+		t.Fatalf("GetTradingSchedules failed: %v", err)
+	}
+	mockServer.AssertRequested(t, "GET", pattern, 4)
+	clock.Advance(time.Hour)
+	if _, err := client.GetTradingSchedules(ctx, params[:2]); err != nil {
+		t.Fatalf("GetTradingSchedules failed: %v", err)
+	}
+	mockServer.AssertRequested(t, "GET", pattern, 6)
+	client.GetTradingSchedule(WithoutCache(ctx), params[0])
+	mockServer.AssertRequested(t, "GET", pattern, 7)
+	client.InvalidateCache(CacheTradingSchedules)
+	client.GetTradingSchedule(ctx, params[1])
+	mockServer.AssertRequested(t, "GET", pattern, 8)
+}
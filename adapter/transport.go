@@ -0,0 +1,106 @@
+package saxo
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// Transport defaults, matching http.DefaultTransport
+const (
+	DefaultDialTimeout         = 30 * time.Second
+	DefaultKeepAlive           = 30 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+	DefaultIdleConnTimeout     = 90 * time.Second
+)
+
+// TransportConfig configures the network path of all adapter traffic: OAuth token calls,
+// REST requests, subscription POSTs and the WebSocket dial. Zero values keep the Go defaults
+type TransportConfig struct {
+	ProxyURL            *url.URL      // Outbound proxy, nil = HTTPS_PROXY/HTTP_PROXY/NO_PROXY environment variables
+	TLSConfig           *tls.Config   // e.g. RootCAs for a TLS-inspecting proxy or MinVersion, nil = Go defaults
+	DialTimeout         time.Duration // TCP connect timeout, 0 = DefaultDialTimeout
+	KeepAlive           time.Duration // TCP keep-alive interval, 0 = DefaultKeepAlive, negative disables
+	TLSHandshakeTimeout time.Duration // 0 = DefaultTLSHandshakeTimeout; bounds the whole WebSocket opening handshake, 0 = WithTimeout
+	IdleConnTimeout     time.Duration // Idle pooled connections are closed after this, 0 = DefaultIdleConnTimeout
+	MaxIdleConnsPerHost int           // 0 = http.DefaultMaxIdleConnsPerHost
+}
+
+// WithTransport routes the client's traffic through the given proxy, TLS and dial settings
+// Ignored by the REST and WebSocket clients, including the WebSocket dial, when WithHTTPClient is
+// also set - the dial then uses the proxy and TLS config of the injected client's *http.Transport
+func WithTransport(config TransportConfig) Option {
+	return func(o *ClientOptions) {
+		o.Transport = &config
+	}
+}
+
+// Proxy returns the proxy selection function for http.Transport and websocket.Dialer
+func (c TransportConfig) Proxy() func(*http.Request) (*url.URL, error) {
+	if c.ProxyURL != nil {
+		return http.ProxyURL(c.ProxyURL)
+	}
+	return http.ProxyFromEnvironment
+}
+
+// Dialer returns the TCP dialer with the configured timeouts
+func (c TransportConfig) Dialer() *net.Dialer {
+	dialer := &net.Dialer{Timeout: DefaultDialTimeout, KeepAlive: DefaultKeepAlive}
+	if c.DialTimeout > 0 {
+		dialer.Timeout = c.DialTimeout
+	}
+	if c.KeepAlive != 0 {
+		dialer.KeepAlive = c.KeepAlive
+	}
+	return dialer
+}
+
+// NewTransport builds an http.Transport from the config
+func (c TransportConfig) NewTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = c.Proxy()
+	transport.DialContext = c.Dialer().DialContext
+	transport.TLSHandshakeTimeout = DefaultTLSHandshakeTimeout
+	if c.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = c.TLSHandshakeTimeout
+	}
+	transport.IdleConnTimeout = DefaultIdleConnTimeout
+	if c.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = c.IdleConnTimeout
+	}
+	if c.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+	if c.TLSConfig != nil {
+		transport.TLSClientConfig = c.TLSConfig.Clone()
+	}
+	return transport
+}
+
+// NewHTTPClient returns an http.Client using NewTransport
+// The client carries no OAuth2 token - the REST and WebSocket clients set the bearer header themselves
+func (c TransportConfig) NewHTTPClient() *http.Client {
+	return &http.Client{Transport: c.NewTransport()}
+}
+
+// ResolveHTTPClient returns the HTTP client for the REST and WebSocket clients
+// An explicit WithHTTPClient wins over WithTransport; nil = the auth client's OAuth2 client
+func (o ClientOptions) ResolveHTTPClient() *http.Client {
+	if o.HTTPClient != nil || o.Transport == nil {
+		return o.HTTPClient
+	}
+	return o.Transport.NewHTTPClient()
+}
+
+// oauthContext makes the oauth2 package (token exchange, refresh and OAuth2 clients) use the configured transport
+func (sac *SaxoAuthClient) oauthContext(ctx context.Context) context.Context {
+	if sac.httpClient == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, oauth2.HTTPClient, sac.httpClient)
+}
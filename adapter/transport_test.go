@@ -0,0 +1,84 @@
+package saxo
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTransportConfig_NewTransport(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy.corp.example:3128")
+	transport := TransportConfig{
+		ProxyURL:            proxyURL,
+		TLSConfig:           &tls.Config{MinVersion: tls.VersionTLS13},
+		TLSHandshakeTimeout: 3 * time.Second,
+		MaxIdleConnsPerHost: 8,
+	}.NewTransport()
+
+	req, _ := http.NewRequest("GET", "https://gateway.saxobank.com/sim/openapi/port/v1/users/me", nil)
+	if got, err := transport.Proxy(req); err != nil || got.String() != proxyURL.String() {
+		t.Errorf("Expected proxy %s, got %v, %v", proxyURL, got, err)
+	}
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS13 || transport.TLSHandshakeTimeout != 3*time.Second ||
+		transport.MaxIdleConnsPerHost != 8 || transport.IdleConnTimeout != DefaultIdleConnTimeout {
+		t.Errorf("Settings not applied: %+v", transport)
+	}
+}
+
+func TestWithTransport_RoutesRESTAndTokenTrafficThroughProxy(t *testing.T) {
+	// A plain HTTP proxy receives absolute request URIs - this one answers them itself
+	var mu sync.Mutex
+	var hosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hosts = append(hosts, r.URL.Host+r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/token":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "proxied_token", "refresh_token": "refresh_token", "token_type": "Bearer", "expires_in": 1200,
+			})
+		default:
+			json.NewEncoder(w).Encode(SaxoBalance{Currency: "EUR", TotalValue: 100})
+		}
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+	transport := WithTransport(TransportConfig{ProxyURL: proxyURL})
+
+	sac := newTestSaxoAuthClient(t, "http://auth.saxo.example", TokenInfo{
+		Provider:     "saxo",
+		AccessToken:  "expired_token",
+		RefreshToken: "refresh_token",
+		Expiry:       time.Now().Add(-time.Minute),
+	}, transport)
+	if err := sac.RefreshToken(context.Background()); err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoBrokerClient(sac, "http://gateway.saxo.example/openapi", logger, transport)
+	balance, err := client.GetBalance(context.Background())
+	if err != nil {
+		t.Fatalf("GetBalance failed: %v", err)
+	}
+	if balance.TotalValue != 100 {
+		t.Errorf("Expected the proxied balance, got %+v", balance)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"auth.saxo.example/token", "gateway.saxo.example/openapi/port/v1/balances/me"}
+	if len(hosts) != len(want) || hosts[0] != want[0] || hosts[1] != want[1] {
+		t.Errorf("Expected proxied requests %v, got %v", want, hosts)
+	}
+}
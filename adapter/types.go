@@ -11,6 +11,7 @@ type SaxoOrderRequest struct {
 	Amount      float64 `json:"Amount"`               // Order size
 	OrderType   string  `json:"OrderType"`            // "Market", "Limit", "Stop", etc.
 	OrderPrice  float64 `json:"OrderPrice,omitempty"` // Price for limit/stop orders
+	AmountType  string  `json:"AmountType,omitempty"` // "Quantity" or "CashAmount" (see AmountTypeQuantity)
 	ManualOrder bool    `json:"ManualOrder"`          // Required: indicates if order is manual or automated
 
 	// Order duration following Saxo patterns
@@ -25,6 +26,24 @@ type SaxoOrderRequest struct {
 	// Optional advanced order fields
 	TakeProfitPrice *float64 `json:"TakeProfitPrice,omitempty"`
 	StopLossPrice   *float64 `json:"StopLossPrice,omitempty"`
+
+	// Position closing (ClosePosition)
+	PositionID  string `json:"PositionId,omitempty"`  // Position to close, end-of-day netting only
+	IsForceOpen *bool  `json:"IsForceOpen,omitempty"` // false = net against existing positions
+}
+
+// SaxoPrecheckResponse represents POST /trade/v2/orders/precheck
+type SaxoPrecheckResponse struct {
+	PreCheckResult                string  `json:"PreCheckResult"` // "Ok" or "Error"
+	EstimatedCashRequired         float64 `json:"EstimatedCashRequired"`
+	EstimatedCashRequiredCurrency string  `json:"EstimatedCashRequiredCurrency"`
+	MarginImpactBuySell           *struct {
+		InitialMarginAvailableCurrent float64 `json:"InitialMarginAvailableCurrent"`
+		InitialMarginAvailableBuy     float64 `json:"InitialMarginAvailableBuy"`
+		InitialMarginAvailableSell    float64 `json:"InitialMarginAvailableSell"`
+	} `json:"MarginImpactBuySell,omitempty"`
+	ErrorInfo   *SaxoErrorResponse `json:"ErrorInfo,omitempty"`
+	MarketState string             `json:"MarketState,omitempty"` // e.g. "Closed" outside market hours
 }
 
 // SaxoOrderResponse represents Saxo Bank order response
@@ -38,10 +57,17 @@ type SaxoOrderResponse struct {
 	ExecutionPrice *float64 `json:"ExecutionPrice,omitempty"`
 	FilledAmount   *int     `json:"FilledAmount,omitempty"`
 
+	// Advisories on an accepted order (see orderWarnings)
+	PreCheckResult string             `json:"PreCheckResult,omitempty"` // "Ok" when checked and accepted
+	MarketState    string             `json:"MarketState,omitempty"`    // e.g. "Closed" when accepted outside market hours
+	ErrorInfo      *SaxoErrorResponse `json:"ErrorInfo,omitempty"`      // Warning code and text on an accepted order
+
 	// Multi-leg order response (for complex/OCO orders)
 	Orders []struct {
-		OrderID       string `json:"OrderId"`
-		OpenOrderType string `json:"OpenOrderType"`
+		OrderID       string             `json:"OrderId"`
+		OpenOrderType string             `json:"OpenOrderType"`
+		Message       string             `json:"Message,omitempty"`
+		ErrorInfo     *SaxoErrorResponse `json:"ErrorInfo,omitempty"`
 	} `json:"Orders,omitempty"`
 }
 
@@ -164,6 +190,25 @@ type SaxoClientInfo struct {
 	UserKey                           string    `json:"UserKey"`
 }
 
+// SaxoAccountGroup represents one entry of GET /port/v1/accountgroups
+type SaxoAccountGroup struct {
+	AccountGroupKey             string  `json:"AccountGroupKey"`
+	AccountGroupName            string  `json:"AccountGroupName"`
+	AccountValueProtectionLimit float64 `json:"AccountValueProtectionLimit,omitempty"`
+}
+
+// SaxoClientDetails represents GET /port/v1/clients/me (also /clients/details and /clients?OwnerKey=)
+type SaxoClientDetails struct {
+	ClientKey              string `json:"ClientKey"`
+	ClientID               string `json:"ClientId"`
+	Name                   string `json:"Name"`
+	DefaultAccountKey      string `json:"DefaultAccountKey"`
+	DefaultCurrency        string `json:"DefaultCurrency"`
+	PositionNettingMode    string `json:"PositionNettingMode"`    // "EndOfDay" or "Intraday"
+	PositionNettingProfile string `json:"PositionNettingProfile"` // e.g. "FifoEndOfDay", "FifoRealTime", "AverageRealTime"
+	PositionNettingMethod  string `json:"PositionNettingMethod"`  // e.g. "FiFo", "Average"
+}
+
 // SaxoErrorResponse represents Saxo API error response
 type SaxoErrorResponse struct {
 	ErrorCode string `json:"ErrorCode"`
@@ -514,7 +559,30 @@ type TokenInfo struct {
 	RefreshToken  string    `json:"refresh_token"`
 	TokenType     string    `json:"token_type"`
 	Expiry        time.Time `json:"expiry"`
-	RefreshExpiry time.Time `json:"refresh_expiry"` // When refresh token expires
+	RefreshExpiry time.Time `json:"refresh_expiry"`   // When refresh token expires
+	Scopes        []string  `json:"scopes,omitempty"` // Granted scopes when the token response lists them
+}
+
+// TokenEventType identifies token lifecycle events published on AuthClient.TokenEvents()
+type TokenEventType string
+
+const (
+	TokenRefreshed     TokenEventType = "refreshed"       // New access token stored
+	TokenAboutToExpire TokenEventType = "about_to_expire" // Token is inside the early refresh window
+	TokenRefreshFailed TokenEventType = "refresh_failed"  // Refresh attempt failed, see Err
+	// TokenReloginRequired - refresh token expires within the relogin threshold, Login is needed before RefreshExpiry
+	TokenReloginRequired TokenEventType = "relogin_required"
+	// TokenAuthExpired - the refresh token expired or was rejected, calls fail with ErrReauthenticationRequired until Login
+	TokenAuthExpired TokenEventType = "auth_expired"
+)
+
+// TokenEvent is a token lifecycle notification
+type TokenEvent struct {
+	Type          TokenEventType
+	Expiry        time.Time // Access token expiry at time of event
+	RefreshExpiry time.Time // Refresh token expiry at time of event
+	Err           error     // Set for TokenRefreshFailed and TokenAuthExpired
+	Timestamp     time.Time
 }
 
 // SaxoSearchParams represents parameters for instrument search
@@ -592,7 +660,8 @@ type SaxoPriceQuote struct {
 
 // SessionUpdate represents a Saxo session state event
 // Sent both as snapshot (from HTTP POST response) and as live WebSocket events
-// Consumer should call SetSessionCapabilities("FullTradingAndChat") if TradeLevel is not "FullTradingAndChat"
+// Consumers that trade may call SetTradeLevel(TradeLevelFullTradingAndChat) when TradeLevel is lower;
+// SaxoBrokerClient.ObserveSessionUpdate turns these events into SessionCapabilityChanged
 type SessionUpdate struct {
 	TradeLevel string // "FullTradingAndChat", "OrderOnly", etc.
 	DataLevel  string // "Realtime", "Delayed", etc.
@@ -0,0 +1,97 @@
+package saxo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxUicsPerRequest is Saxo's limit on the Uics parameter of list endpoints
+// GetInstrumentDetails and GetInstrumentPrices split longer lists into requests of this size
+const MaxUicsPerRequest = 50
+
+// uicChunkConcurrency bounds parallel chunk requests, keeping well inside Saxo's rate limits
+const uicChunkConcurrency = 4
+
+// UicChunkError reports one failed request of a chunked UIC call
+// The other chunks' results are still returned; use errors.As to find which UICs are missing
+type UicChunkError struct {
+	Uics []int
+	Err  error
+}
+
+func (e *UicChunkError) Error() string {
+	return fmt.Sprintf("uics %d-%d (%d): %v", e.Uics[0], e.Uics[len(e.Uics)-1], len(e.Uics), e.Err)
+}
+
+func (e *UicChunkError) Unwrap() error {
+	return e.Err
+}
+
+// fetchUicChunks runs fetch for every MaxUicsPerRequest-sized chunk of uics, at most
+// uicChunkConcurrency at a time, and merges the results in the order of uics (uicOf keys each result).
+// Failed chunks are joined as *UicChunkError next to the results of the chunks that succeeded
+func fetchUicChunks[T any](ctx context.Context, uics []int, uicOf func(T) int, fetch func(ctx context.Context, chunk []int) ([]T, error)) ([]T, error) {
+	if len(uics) <= MaxUicsPerRequest {
+		results, err := fetch(ctx, uics)
+		if err != nil {
+			return nil, err
+		}
+		return results, nil
+	}
+
+	var chunks [][]int
+	for start := 0; start < len(uics); start += MaxUicsPerRequest {
+		chunks = append(chunks, uics[start:min(start+MaxUicsPerRequest, len(uics))])
+	}
+
+	results := make([][]T, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, uicChunkConcurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = &UicChunkError{Uics: chunk, Err: ctx.Err()}
+				return
+			}
+			if results[i], errs[i] = fetch(ctx, chunk); errs[i] != nil {
+				errs[i] = &UicChunkError{Uics: chunk, Err: errs[i]}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var merged []T
+	for _, chunkResults := range results {
+		merged = append(merged, chunkResults...)
+	}
+
+	// Saxo does not promise response order - sort by position in the request
+	position := make(map[int]int, len(uics))
+	for i := len(uics) - 1; i >= 0; i-- {
+		position[uics[i]] = i
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return position[uicOf(merged[i])] < position[uicOf(merged[j])]
+	})
+	return merged, errors.Join(errs...)
+}
+
+// joinUics formats uics for the Uics query parameter ("21,31,42")
+func joinUics(uics []int) string {
+	parts := make([]string, len(uics))
+	for i, uic := range uics {
+		parts[i] = strconv.Itoa(uic)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,81 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetInstrumentDetails_ChunksOverUicLimit(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	var requests, inFlight, maxInFlight int32
+	mockServer.SetHandler("GET", "/ref/v1/instruments/details", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+
+		uics := strings.Split(r.URL.Query().Get("Uics"), ",")
+		if len(uics) > MaxUicsPerRequest {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if uics[0] == "1050" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// Reverse order - the client restores the request order
+		data := make([]map[string]interface{}, 0, len(uics))
+		for i := len(uics) - 1; i >= 0; i-- {
+			uic, _ := strconv.Atoi(uics[i])
+			data = append(data, map[string]interface{}{"Identifier": uic, "TickSize": 0.01})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"Data": data})
+	})
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	uics := make([]int, 200)
+	for i := range uics {
+		uics[i] = 1000 + i
+	}
+
+	// Chunk 1050-1099 fails: the other 150 details come back with a chunk error
+	details, err := client.GetInstrumentDetails(context.Background(), uics)
+	var chunkErr *UicChunkError
+	if !errors.As(err, &chunkErr) || chunkErr.Uics[0] != 1050 || len(chunkErr.Uics) != MaxUicsPerRequest {
+		t.Fatalf("Expected a UicChunkError for 1050-1099, got %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 4 {
+		t.Errorf("Expected 4 requests for 200 UICs, got %d", got)
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got > uicChunkConcurrency {
+		t.Errorf("Expected at most %d parallel requests, got %d", uicChunkConcurrency, got)
+	}
+	if len(details) != 150 {
+		t.Fatalf("Expected 150 details, got %d", len(details))
+	}
+	for i := 1; i < len(details); i++ {
+		if details[i].Uic <= details[i-1].Uic {
+			t.Fatalf("Details out of request order at %d: %d after %d", i, details[i].Uic, details[i-1].Uic)
+		}
+	}
+	if details[49].Uic != 1049 || details[50].Uic != 1100 {
+		t.Errorf("Expected the failed chunk missing, got %d then %d", details[49].Uic, details[50].Uic)
+	}
+}
@@ -0,0 +1,223 @@
+package saxo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Watchlist is a list of instruments the user maintains in SaxoTraderGO
+type Watchlist struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ItemCount int    `json:"item_count"`
+	Editable  bool   `json:"editable"` // false for Saxo-curated lists such as "Popular"
+}
+
+// WatchlistItem is one instrument on a watchlist
+type WatchlistItem struct {
+	Uic         int    `json:"uic"`
+	AssetType   string `json:"asset_type"`
+	Symbol      string `json:"symbol"`
+	Description string `json:"description"`
+}
+
+// Ref returns the item as an InstrumentRef, e.g. for websocket SubscribeInstruments
+func (item WatchlistItem) Ref() InstrumentRef {
+	return InstrumentRef{Uic: item.Uic, AssetType: item.AssetType}
+}
+
+// saxoWatchlist is one list of GET /port/v1/lists
+type saxoWatchlist struct {
+	ListID      string `json:"ListId"`
+	DisplayName string `json:"DisplayName"`
+	Type        string `json:"Type"` // "User" lists are editable, "System" lists are not
+	Items       []struct {
+		Uic       int    `json:"Uic"`
+		AssetType string `json:"AssetType"`
+	} `json:"Items"`
+}
+
+// GetWatchlists returns the user's watchlists
+// Reference: Saxo API GET /port/v1/lists
+func (sbc *SaxoBrokerClient) GetWatchlists(ctx context.Context) ([]Watchlist, error) {
+	if !sbc.authClient.IsAuthenticated() {
+		return nil, sbc.notAuthenticated()
+	}
+
+	var response struct {
+		Data []saxoWatchlist `json:"Data"`
+	}
+	if err := sbc.getWatchlistJSON(ctx, "/port/v1/lists", &response); err != nil {
+		return nil, fmt.Errorf("failed to get watchlists: %w", err)
+	}
+
+	watchlists := make([]Watchlist, len(response.Data))
+	for i, list := range response.Data {
+		watchlists[i] = Watchlist{
+			ID:        list.ListID,
+			Name:      list.DisplayName,
+			ItemCount: len(list.Items),
+			Editable:  list.Type != "System",
+		}
+	}
+
+	sbc.logger.Info("Retrieved watchlists",
+		"function", "GetWatchlists",
+		"count", len(watchlists))
+	return watchlists, nil
+}
+
+// GetWatchlistItems returns the instruments on watchlist listID, with symbol and description
+// Reference: Saxo API GET /port/v1/lists/{ListId}
+func (sbc *SaxoBrokerClient) GetWatchlistItems(ctx context.Context, listID string) ([]WatchlistItem, error) {
+	if !sbc.authClient.IsAuthenticated() {
+		return nil, sbc.notAuthenticated()
+	}
+	if listID == "" {
+		return nil, fmt.Errorf("watchlist ID is required")
+	}
+
+	var list saxoWatchlist
+	if err := sbc.getWatchlistJSON(ctx, "/port/v1/lists/"+url.PathEscape(listID), &list); err != nil {
+		return nil, fmt.Errorf("failed to get watchlist %s: %w", listID, err)
+	}
+
+	items := make([]WatchlistItem, len(list.Items))
+	for i, item := range list.Items {
+		items[i] = WatchlistItem{Uic: item.Uic, AssetType: item.AssetType}
+	}
+
+	// Lists only carry UICs - symbols come from instrument search, batched per asset type
+	byAssetType := make(map[string][]int)
+	for _, item := range items {
+		byAssetType[item.AssetType] = append(byAssetType[item.AssetType], item.Uic)
+	}
+	for assetType, uics := range byAssetType {
+		result, err := sbc.SearchInstruments(ctx, InstrumentSearchParams{AssetType: assetType, Uics: uics, IncludeNonTradable: true})
+		if err != nil {
+			sbc.logger.Warn("Watchlist symbols unavailable",
+				"function", "GetWatchlistItems",
+				"asset_type", assetType,
+				"error", err)
+			continue
+		}
+		for _, instrument := range result.Instruments {
+			for i := range items {
+				if items[i].Uic == instrument.Uic && items[i].AssetType == instrument.AssetType {
+					items[i].Symbol = instrument.Symbol
+					items[i].Description = instrument.Description
+				}
+			}
+		}
+	}
+
+	sbc.logger.Info("Retrieved watchlist items",
+		"function", "GetWatchlistItems",
+		"list_id", listID,
+		"count", len(items))
+	return items, nil
+}
+
+// AddToWatchlist appends an instrument to watchlist listID
+// Reference: Saxo API POST /port/v1/lists/{ListId}/items
+func (sbc *SaxoBrokerClient) AddToWatchlist(ctx context.Context, listID string, instrument InstrumentRef) error {
+	if !sbc.authClient.IsAuthenticated() {
+		return sbc.notAuthenticated()
+	}
+	if listID == "" || instrument.Uic == 0 || instrument.AssetType == "" {
+		return fmt.Errorf("watchlist ID, UIC and asset type are required")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"Uic": instrument.Uic, "AssetType": instrument.AssetType})
+	if err != nil {
+		return fmt.Errorf("failed to marshal watchlist item: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		sbc.baseURL+"/port/v1/lists/"+url.PathEscape(listID)+"/items", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create watchlist request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := sbc.doWatchlistMutation(ctx, req); err != nil {
+		return fmt.Errorf("failed to add uic %d to watchlist %s: %w", instrument.Uic, listID, err)
+	}
+
+	sbc.logger.Info("Added to watchlist",
+		"function", "AddToWatchlist",
+		"list_id", listID,
+		"uic", instrument.Uic,
+		"asset_type", instrument.AssetType)
+	return nil
+}
+
+// RemoveFromWatchlist removes an instrument from watchlist listID
+// Reference: Saxo API DELETE /port/v1/lists/{ListId}/items?Uic=&AssetType=
+func (sbc *SaxoBrokerClient) RemoveFromWatchlist(ctx context.Context, listID string, instrument InstrumentRef) error {
+	if !sbc.authClient.IsAuthenticated() {
+		return sbc.notAuthenticated()
+	}
+	if listID == "" || instrument.Uic == 0 || instrument.AssetType == "" {
+		return fmt.Errorf("watchlist ID, UIC and asset type are required")
+	}
+
+	query := url.Values{}
+	query.Set("Uic", strconv.Itoa(instrument.Uic))
+	query.Set("AssetType", instrument.AssetType)
+	req, err := http.NewRequestWithContext(ctx, "DELETE",
+		sbc.baseURL+"/port/v1/lists/"+url.PathEscape(listID)+"/items?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create watchlist request: %w", err)
+	}
+
+	if err := sbc.doWatchlistMutation(ctx, req); err != nil {
+		return fmt.Errorf("failed to remove uic %d from watchlist %s: %w", instrument.Uic, listID, err)
+	}
+
+	sbc.logger.Info("Removed from watchlist",
+		"function", "RemoveFromWatchlist",
+		"list_id", listID,
+		"uic", instrument.Uic,
+		"asset_type", instrument.AssetType)
+	return nil
+}
+
+func (sbc *SaxoBrokerClient) getWatchlistJSON(ctx context.Context, path string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", sbc.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := sbc.doRequest(ctx, req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return sbc.handleErrorResponse(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// doWatchlistMutation sends req and accepts 200, 201 and 204
+func (sbc *SaxoBrokerClient) doWatchlistMutation(ctx context.Context, req *http.Request) error {
+	resp, err := sbc.doRequest(ctx, req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	}
+	return sbc.handleErrorResponse(resp)
+}
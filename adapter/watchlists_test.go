@@ -0,0 +1,103 @@
+package saxo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSaxoBrokerClient_Watchlists(t *testing.T) {
+	mockServer := NewMockSaxoServer()
+	defer mockServer.Close()
+
+	type item struct {
+		Uic       int
+		AssetType string
+	}
+	var mu sync.Mutex
+	items := []item{{21, "FxSpot"}, {4912, "Stock"}}
+	list := func() map[string]interface{} {
+		return map[string]interface{}{"ListId": "my list", "DisplayName": "Majors", "Type": "User", "Items": items}
+	}
+	mockServer.SetHandler("GET", "/port/v1/lists", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"Data": []interface{}{
+			list(),
+			map[string]interface{}{"ListId": "popular", "DisplayName": "Popular", "Type": "System"},
+		}})
+	})
+	mockServer.SetHandler("GET", "/port/v1/lists/{listId}", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(list())
+	})
+	mockServer.SetHandler("POST", "/port/v1/lists/{listId}/items", func(w http.ResponseWriter, r *http.Request) {
+		var added item
+		json.NewDecoder(r.Body).Decode(&added)
+		mu.Lock()
+		items = append(items, added)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	})
+	mockServer.SetHandler("DELETE", "/port/v1/lists/{listId}/items", func(w http.ResponseWriter, r *http.Request) {
+		uic, _ := strconv.Atoi(r.URL.Query().Get("Uic"))
+		mu.Lock()
+		defer mu.Unlock()
+		for i, existing := range items {
+			if existing.Uic == uic && existing.AssetType == r.URL.Query().Get("AssetType") {
+				items = append(items[:i], items[i+1:]...)
+				break
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mockServer.SetHandler("GET", "/ref/v1/instruments", func(w http.ResponseWriter, r *http.Request) {
+		symbols := map[string]map[string]interface{}{
+			"FxSpot": {"Identifier": 21, "Symbol": "EURUSD", "AssetType": "FxSpot"},
+			"Stock":  {"Identifier": 4912, "Symbol": "AAPL:xnas", "AssetType": "Stock"},
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"Data": []interface{}{symbols[r.URL.Query().Get("AssetTypes")]}})
+	})
+
+	authClient := &MockAuthClient{authenticated: true, accessToken: "mock_token"}
+	client := NewSaxoBrokerClient(authClient, mockServer.GetBaseURL(), slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	ctx := context.Background()
+
+	watchlists, err := client.GetWatchlists(ctx)
+	if err != nil || len(watchlists) != 2 {
+		t.Fatalf("Expected 2 watchlists, got %+v (%v)", watchlists, err)
+	}
+	if w := watchlists[0]; w.ID != "my list" || w.Name != "Majors" || w.ItemCount != 2 || !w.Editable || watchlists[1].Editable {
+		t.Errorf("Unexpected watchlists %+v", watchlists)
+	}
+
+	got, err := client.GetWatchlistItems(ctx, "my list")
+	if err != nil || len(got) != 2 {
+		t.Fatalf("Expected 2 items, got %+v (%v)", got, err)
+	}
+	if got[0].Symbol != "EURUSD" || got[1].Symbol != "AAPL:xnas" || got[1].Ref() != (InstrumentRef{Uic: 4912, AssetType: "Stock"}) {
+		t.Errorf("Unexpected items %+v", got)
+	}
+
+	if err := client.AddToWatchlist(ctx, "my list", InstrumentRef{Uic: 31, AssetType: "FxSpot"}); err != nil {
+		t.Fatalf("AddToWatchlist failed: %v", err)
+	}
+	if err := client.RemoveFromWatchlist(ctx, "my list", InstrumentRef{Uic: 21, AssetType: "FxSpot"}); err != nil {
+		t.Fatalf("RemoveFromWatchlist failed: %v", err)
+	}
+	mu.Lock()
+	if len(items) != 2 || items[0].Uic != 4912 || items[1].Uic != 31 {
+		t.Errorf("Unexpected list after add and remove %+v", items)
+	}
+	mu.Unlock()
+
+	if err := client.AddToWatchlist(ctx, "my list", InstrumentRef{Uic: 31}); err == nil {
+		t.Error("Expected error without asset type")
+	}
+}
@@ -0,0 +1,265 @@
+package websocket
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// BackpressureStrategy decides what happens to price updates when the consumer falls behind
+type BackpressureStrategy int
+
+const (
+	// BackpressureDrop drops the newest update when the price channel is full (default, legacy behavior)
+	BackpressureDrop BackpressureStrategy = iota
+	// BackpressureConflate keeps only the latest quote per UIC while the consumer is behind
+	// Consumers always receive a coherent latest price, never a stale one queued behind newer data
+	BackpressureConflate
+	// BackpressureQueue buffers updates in a bounded FIFO, dropping the oldest once MaxQueue is reached
+	BackpressureQueue
+	// BackpressureBlock waits up to Timeout for channel space, then drops the update
+	// CRITICAL: blocks the processor goroutine - order and session messages wait too
+	BackpressureBlock
+)
+
+// String returns the strategy name for logging
+func (s BackpressureStrategy) String() string {
+	switch s {
+	case BackpressureDrop:
+		return "drop"
+	case BackpressureConflate:
+		return "conflate"
+	case BackpressureQueue:
+		return "queue"
+	case BackpressureBlock:
+		return "block"
+	default:
+		return "unknown"
+	}
+}
+
+// Defaults applied when BackpressurePolicy fields are zero
+const (
+	DefaultBackpressureMaxQueue = 10000
+	DefaultBackpressureTimeout  = 100 * time.Millisecond
+)
+
+// BackpressurePolicy configures price stream delivery (see SaxoWebSocketClient.SetPriceBackpressure)
+type BackpressurePolicy struct {
+	Strategy BackpressureStrategy
+	MaxQueue int           // BackpressureQueue: overflow capacity beyond the channel buffer
+	Timeout  time.Duration // BackpressureBlock: maximum wait per update
+}
+
+// priceDispatcher delivers price updates to priceUpdateChan according to a BackpressurePolicy
+// Drop and Block deliver inline from the processor goroutine. Conflate and Queue hold updates
+// in a pending buffer drained by a pump goroutine, started on first use and stopped by Shutdown
+type priceDispatcher struct {
+	out    chan saxo.PriceUpdate
+	policy BackpressurePolicy
+
+	mu      sync.Mutex
+	pending []saxo.PriceUpdate // Queue: FIFO of updates; Conflate: one entry per UIC in arrival order
+	index   map[int]int        // Conflate: UIC -> position in pending
+	signal  chan struct{}      // Wakes the pump, buffer 1
+	stop    chan struct{}      // Closed by Stop
+	done    chan struct{}      // Closed when the pump exits
+	started bool               // Pump goroutine launched
+	stopped bool               // Stop called - no more deliveries
+
+	dropped   atomic.Uint64
+	conflated atomic.Uint64
+}
+
+func newPriceDispatcher(out chan saxo.PriceUpdate, policy BackpressurePolicy) *priceDispatcher {
+	if policy.MaxQueue <= 0 {
+		policy.MaxQueue = DefaultBackpressureMaxQueue
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = DefaultBackpressureTimeout
+	}
+	return &priceDispatcher{
+		out:    out,
+		policy: policy,
+		index:  make(map[int]int),
+		signal: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Dispatch delivers one update, returning false if this update was dropped
+// (Queue evicts the oldest pending update instead - counted in dropped, not reported here)
+func (pd *priceDispatcher) Dispatch(update saxo.PriceUpdate) bool {
+	switch pd.policy.Strategy {
+	case BackpressureConflate, BackpressureQueue:
+		return pd.enqueue(update)
+	case BackpressureBlock:
+		return pd.sendWithTimeout(update)
+	default:
+		return pd.sendOrDrop(update)
+	}
+}
+
+// sendOrDrop is the legacy non-blocking send
+func (pd *priceDispatcher) sendOrDrop(update saxo.PriceUpdate) bool {
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+	if pd.stopped {
+		return false
+	}
+	select {
+	case pd.out <- update:
+		return true
+	default:
+		pd.dropped.Add(1)
+		return false
+	}
+}
+
+// sendWithTimeout waits for channel space up to policy.Timeout
+func (pd *priceDispatcher) sendWithTimeout(update saxo.PriceUpdate) bool {
+	select {
+	case <-pd.stop:
+		return false
+	default:
+	}
+
+	timer := time.NewTimer(pd.policy.Timeout)
+	defer timer.Stop()
+
+	select {
+	case pd.out <- update:
+		return true
+	case <-timer.C:
+		pd.dropped.Add(1)
+		return false
+	case <-pd.stop:
+		return false
+	}
+}
+
+// enqueue adds the update to the pending buffer and wakes the pump
+func (pd *priceDispatcher) enqueue(update saxo.PriceUpdate) bool {
+	pd.mu.Lock()
+	if pd.stopped {
+		pd.mu.Unlock()
+		return false
+	}
+
+	if pd.policy.Strategy == BackpressureConflate {
+		if i, ok := pd.index[update.Uic]; ok {
+			// Replace in place - keeps the UIC's position so no instrument starves
+			pd.pending[i] = update
+			pd.conflated.Add(1)
+		} else {
+			pd.index[update.Uic] = len(pd.pending)
+			pd.pending = append(pd.pending, update)
+		}
+	} else {
+		if len(pd.pending) >= pd.policy.MaxQueue {
+			pd.pending = pd.pending[1:]
+			pd.dropped.Add(1)
+		}
+		pd.pending = append(pd.pending, update)
+	}
+
+	if !pd.started {
+		pd.started = true
+		go pd.pump()
+	}
+	pd.mu.Unlock()
+
+	select {
+	case pd.signal <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// next pops the oldest pending update
+func (pd *priceDispatcher) next() (saxo.PriceUpdate, bool) {
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+
+	if len(pd.pending) == 0 {
+		return saxo.PriceUpdate{}, false
+	}
+	update := pd.pending[0]
+	pd.pending = pd.pending[1:]
+
+	if pd.policy.Strategy == BackpressureConflate {
+		delete(pd.index, update.Uic)
+		for uic, i := range pd.index {
+			pd.index[uic] = i - 1
+		}
+	}
+	return update, true
+}
+
+// pump drains pending into the output channel, blocking on the consumer
+func (pd *priceDispatcher) pump() {
+	defer close(pd.done)
+
+	for {
+		update, ok := pd.next()
+		if !ok {
+			select {
+			case <-pd.signal:
+				continue
+			case <-pd.stop:
+				return
+			}
+		}
+
+		select {
+		case pd.out <- update:
+		case <-pd.stop:
+			return
+		}
+	}
+}
+
+// Stop terminates delivery and waits for the pump to exit
+// CRITICAL: call before closing the output channel
+func (pd *priceDispatcher) Stop() {
+	pd.mu.Lock()
+	if pd.stopped {
+		pd.mu.Unlock()
+		return
+	}
+	pd.stopped = true
+	started := pd.started
+	close(pd.stop)
+	pd.mu.Unlock()
+
+	if started {
+		<-pd.done
+	}
+}
+
+// PendingLen returns the number of updates waiting for the consumer outside the channel buffer
+func (pd *priceDispatcher) PendingLen() int {
+	pd.mu.Lock()
+	defer pd.mu.Unlock()
+	return len(pd.pending)
+}
+
+// SetPriceBackpressure selects how price updates are delivered when the consumer falls behind
+// Call before Connect; the default is BackpressureDrop
+func (ws *SaxoWebSocketClient) SetPriceBackpressure(policy BackpressurePolicy) {
+	ws.priceDispatcher.Stop()
+	ws.priceDispatcher = newPriceDispatcher(ws.priceUpdateChan, policy)
+
+	ws.logger.Info("Price backpressure policy set",
+		"function", "SetPriceBackpressure",
+		"strategy", policy.Strategy.String())
+}
+
+// DroppedPriceUpdates returns the number of price updates discarded by the backpressure policy
+// Conflated updates are not counted - they were superseded by a newer quote, not lost
+func (ws *SaxoWebSocketClient) DroppedPriceUpdates() uint64 {
+	return ws.priceDispatcher.dropped.Load()
+}
@@ -0,0 +1,113 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+func TestPriceDispatcher_Drop(t *testing.T) {
+	out := make(chan saxo.PriceUpdate, 1)
+	pd := newPriceDispatcher(out, BackpressurePolicy{Strategy: BackpressureDrop})
+	defer pd.Stop()
+
+	if !pd.Dispatch(saxo.PriceUpdate{Uic: 21, Bid: 1}) {
+		t.Fatal("Expected first update to be delivered")
+	}
+	if pd.Dispatch(saxo.PriceUpdate{Uic: 21, Bid: 2}) {
+		t.Error("Expected second update to be dropped")
+	}
+	if got := pd.dropped.Load(); got != 1 {
+		t.Errorf("Expected 1 dropped update, got %d", got)
+	}
+}
+
+func TestPriceDispatcher_Conflate(t *testing.T) {
+	out := make(chan saxo.PriceUpdate) // Unbuffered - consumer is always "behind"
+	pd := newPriceDispatcher(out, BackpressurePolicy{Strategy: BackpressureConflate})
+	defer pd.Stop()
+
+	// Pump picks up the first update and blocks on the send; the rest pile up per UIC
+	pd.Dispatch(saxo.PriceUpdate{Uic: 21, Bid: 1.0})
+	time.Sleep(10 * time.Millisecond)
+	for i := 2; i <= 5; i++ {
+		pd.Dispatch(saxo.PriceUpdate{Uic: 21, Bid: float64(i)})
+	}
+	pd.Dispatch(saxo.PriceUpdate{Uic: 31, Bid: 100})
+
+	want := []saxo.PriceUpdate{{Uic: 21, Bid: 1}, {Uic: 21, Bid: 5}, {Uic: 31, Bid: 100}}
+	for _, w := range want {
+		select {
+		case got := <-out:
+			if got.Uic != w.Uic || got.Bid != w.Bid {
+				t.Errorf("Expected UIC %d bid %.0f, got UIC %d bid %.0f", w.Uic, w.Bid, got.Uic, got.Bid)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for UIC %d", w.Uic)
+		}
+	}
+
+	if got := pd.conflated.Load(); got != 3 {
+		t.Errorf("Expected 3 conflated updates, got %d", got)
+	}
+	if got := pd.dropped.Load(); got != 0 {
+		t.Errorf("Expected no dropped updates, got %d", got)
+	}
+}
+
+func TestPriceDispatcher_Queue(t *testing.T) {
+	out := make(chan saxo.PriceUpdate)
+	pd := newPriceDispatcher(out, BackpressurePolicy{Strategy: BackpressureQueue, MaxQueue: 2})
+	defer pd.Stop()
+
+	pd.Dispatch(saxo.PriceUpdate{Uic: 1})
+	time.Sleep(10 * time.Millisecond) // Pump holds UIC 1
+	for uic := 2; uic <= 4; uic++ {
+		pd.Dispatch(saxo.PriceUpdate{Uic: uic})
+	}
+
+	// UIC 2 evicted as the oldest pending update
+	for _, want := range []int{1, 3, 4} {
+		select {
+		case got := <-out:
+			if got.Uic != want {
+				t.Errorf("Expected UIC %d, got %d", want, got.Uic)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timeout waiting for UIC %d", want)
+		}
+	}
+	if got := pd.dropped.Load(); got != 1 {
+		t.Errorf("Expected 1 dropped update, got %d", got)
+	}
+}
+
+func TestPriceDispatcher_Block(t *testing.T) {
+	out := make(chan saxo.PriceUpdate, 1)
+	pd := newPriceDispatcher(out, BackpressurePolicy{Strategy: BackpressureBlock, Timeout: 20 * time.Millisecond})
+	defer pd.Stop()
+
+	pd.Dispatch(saxo.PriceUpdate{Uic: 21})
+
+	// Consumer frees space within the timeout - update delivered
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		<-out
+	}()
+	if !pd.Dispatch(saxo.PriceUpdate{Uic: 22}) {
+		t.Error("Expected update to be delivered once space freed")
+	}
+
+	// Nobody reads - dropped after the timeout
+	start := time.Now()
+	if pd.Dispatch(saxo.PriceUpdate{Uic: 23}) {
+		t.Error("Expected update to be dropped after timeout")
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected to block for the timeout, returned after %v", elapsed)
+	}
+	if got := pd.dropped.Load(); got != 1 {
+		t.Errorf("Expected 1 dropped update, got %d", got)
+	}
+}
@@ -0,0 +1,97 @@
+package websocket
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+)
+
+// Hot path buffers - at dozens of instruments with 1s refresh, every frame and price message would
+// otherwise allocate its read buffer, parsed messages and decode target
+
+// maxPooledFrame bounds the frame buffers returned to the pool - a rare large frame (e.g. a
+// snapshot replay) is left to the GC instead of pinning its memory
+const maxPooledFrame = 64 << 10
+
+// frameBuffer holds one read frame. Reference counted: the processor holds one reference and every
+// message queued to a worker another (SetMessageWorkers), so the buffer returns to the pool after
+// the last message of the frame is handled
+type frameBuffer struct {
+	bytes.Buffer
+	refs atomic.Int32
+}
+
+var framePool = sync.Pool{New: func() any { return new(frameBuffer) }}
+
+// acquireFrame returns an empty frame buffer for the reader, holding one reference
+func acquireFrame() *frameBuffer {
+	buf := framePool.Get().(*frameBuffer)
+	buf.Reset()
+	buf.refs.Store(1)
+	return buf
+}
+
+// retain adds a reference for a message handed to another goroutine. nil-safe
+func (buf *frameBuffer) retain() {
+	if buf != nil {
+		buf.refs.Add(1)
+	}
+}
+
+// readFrame reads the next WebSocket message into a pooled buffer
+// Replaces conn.ReadMessage, which allocates a fresh slice per message
+func readFrame(conn *websocket.Conn) (int, *frameBuffer, error) {
+	messageType, r, err := conn.NextReader()
+	if err != nil {
+		return messageType, nil, err
+	}
+	buf := acquireFrame()
+	if _, err := buf.ReadFrom(r); err != nil {
+		releaseFrame(buf)
+		return messageType, nil, err
+	}
+	return messageType, buf, nil
+}
+
+// releaseFrame drops one reference and returns buf to the pool once its frame is fully processed
+// Nothing may keep slices of it - custom DataHandlers get a copy (see SubscribeCustomContext)
+func releaseFrame(buf *frameBuffer) {
+	if buf == nil || buf.refs.Add(-1) > 0 || buf.Cap() > maxPooledFrame {
+		return
+	}
+	framePool.Put(buf)
+}
+
+var parsedPool = sync.Pool{New: func() any { return new([]ParsedMessage) }}
+
+// acquireParsed returns an empty slice for the messages of one frame
+func acquireParsed() *[]ParsedMessage {
+	parsed := parsedPool.Get().(*[]ParsedMessage)
+	*parsed = (*parsed)[:0]
+	return parsed
+}
+
+// releaseParsed clears the payload references and returns parsed to the pool
+func releaseParsed(parsed *[]ParsedMessage) {
+	clear(*parsed)
+	parsedPool.Put(parsed)
+}
+
+var priceBatchPool = sync.Pool{New: func() any { return new([]priceDelta) }}
+
+// acquirePriceBatch returns an empty decode target for a price message
+func acquirePriceBatch() *[]priceDelta {
+	batch := priceBatchPool.Get().(*[]priceDelta)
+	*batch = (*batch)[:0]
+	return batch
+}
+
+// releasePriceBatch zeroes the batch and returns it to the pool
+// encoding/json decodes into reused elements without clearing them - a delta omitting a field
+// would otherwise inherit the previous message's value
+func releasePriceBatch(batch *[]priceDelta) {
+	clear((*batch)[:cap(*batch)])
+	priceBatchPool.Put(batch)
+}
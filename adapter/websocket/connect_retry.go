@@ -0,0 +1,112 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ConnectRetryPolicy controls retries of the initial Connect when the handshake fails transiently
+// (DNS or network errors, 5xx/429 from the streaming gateway, e.g. during Saxo maintenance).
+// Separate from ReconnectPolicy, which covers connections lost after Connect succeeded
+type ConnectRetryPolicy struct {
+	InitialDelay time.Duration // Delay before the first retry
+	Multiplier   float64       // Delay growth per retry (<1 treated as 1 = constant delay)
+	MaxDelay     time.Duration // Upper bound for a single delay (0 = no bound)
+	MaxRetries   int           // Retries after the first attempt, 0 = fail on the first error
+	Jitter       float64       // Randomizes each delay by +/- this fraction (0-1)
+}
+
+// DefaultConnectRetryPolicy returns the policy used unless SetConnectRetryPolicy is called
+// 1s, 2s, 4s, 8s with +/-20% jitter - about 15s before Connect gives up
+func DefaultConnectRetryPolicy() ConnectRetryPolicy {
+	return ConnectRetryPolicy{
+		InitialDelay: time.Second,
+		Multiplier:   2,
+		MaxDelay:     10 * time.Second,
+		MaxRetries:   4,
+		Jitter:       0.2,
+	}
+}
+
+// Delay returns the wait before retry (1-based), including jitter
+func (p ConnectRetryPolicy) Delay(retry int) time.Duration {
+	return ReconnectPolicy{
+		InitialDelay: p.InitialDelay,
+		Multiplier:   p.Multiplier,
+		MaxDelay:     p.MaxDelay,
+		Jitter:       p.Jitter,
+	}.Delay(retry)
+}
+
+// SetConnectRetryPolicy replaces the initial connect retry policy
+func (ws *SaxoWebSocketClient) SetConnectRetryPolicy(policy ConnectRetryPolicy) {
+	ws.reconnectPolicyMu.Lock()
+	defer ws.reconnectPolicyMu.Unlock()
+	ws.connectRetryPolicy = policy
+}
+
+// ConnectRetryPolicy returns the current initial connect retry policy
+func (ws *SaxoWebSocketClient) ConnectRetryPolicy() ConnectRetryPolicy {
+	ws.reconnectPolicyMu.RLock()
+	defer ws.reconnectPolicyMu.RUnlock()
+	return ws.connectRetryPolicy
+}
+
+// HandshakeError is returned when the streaming gateway answered the WebSocket upgrade with an HTTP error
+type HandshakeError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *HandshakeError) Error() string {
+	return fmt.Sprintf("websocket handshake failed with HTTP %d: %v", e.StatusCode, e.Err)
+}
+
+func (e *HandshakeError) Unwrap() error {
+	return e.Err
+}
+
+// isTransientConnectError reports whether a failed connect may succeed when retried
+// Authentication and other 4xx errors are permanent - retrying cannot fix them
+func isTransientConnectError(err error) bool {
+	var handshakeErr *HandshakeError
+	if errors.As(err, &handshakeErr) {
+		return handshakeErr.StatusCode >= http.StatusInternalServerError ||
+			handshakeErr.StatusCode == http.StatusTooManyRequests ||
+			handshakeErr.StatusCode == http.StatusRequestTimeout
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// establishWithRetry runs EstablishConnection, retrying transient failures per ConnectRetryPolicy
+// Stops early when ctx is done; only Connect uses it, reconnects follow ReconnectPolicy
+func (ws *SaxoWebSocketClient) establishWithRetry(ctx context.Context) error {
+	policy := ws.ConnectRetryPolicy()
+
+	err := ws.connectionManager.EstablishConnection(ctx)
+	for retry := 1; err != nil && retry <= policy.MaxRetries; retry++ {
+		if !isTransientConnectError(err) || ctx.Err() != nil {
+			return err
+		}
+		delay := policy.Delay(retry)
+		ws.logger.Warn("Connect failed, retrying",
+			"function", "Connect",
+			"retry", retry,
+			"max_retries", policy.MaxRetries,
+			"delay", delay,
+			"error", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("connect retry canceled: %w", errors.Join(ctx.Err(), err))
+		case <-ws.clock.After(delay):
+		}
+		err = ws.connectionManager.EstablishConnection(ctx)
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestIsTransientConnectError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"503 maintenance", &HandshakeError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"429 rate limited", &HandshakeError{StatusCode: http.StatusTooManyRequests}, true},
+		{"401 unauthorized", &HandshakeError{StatusCode: http.StatusUnauthorized}, false},
+		{"DNS failure", &net.DNSError{Err: "no such host", Name: "streaming.saxobank.com", IsTemporary: true}, true},
+		{"timeout", context.DeadlineExceeded, true},
+		{"not authenticated", errors.New("authentication required"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientConnectError(tt.err); got != tt.want {
+				t.Errorf("Expected %v for %v, got %v", tt.want, tt.err, got)
+			}
+		})
+	}
+}
+
+func TestConnect_RetriesTransientHandshakeFailures(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	client := newReconnectTestClient(t, mockServer)
+	client.SetConnectRetryPolicy(ConnectRetryPolicy{InitialDelay: 10 * time.Millisecond, Multiplier: 1, MaxRetries: 3})
+	defer client.Close()
+
+	mockServer.FailHandshakes(http.StatusServiceUnavailable, 2)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect should succeed after transient failures: %v", err)
+	}
+	if !client.IsConnected() {
+		t.Error("Expected client to be connected")
+	}
+}
+
+func TestConnect_GivesUpAfterMaxRetries(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	client := newReconnectTestClient(t, mockServer)
+	client.SetConnectRetryPolicy(ConnectRetryPolicy{InitialDelay: 10 * time.Millisecond, Multiplier: 1, MaxRetries: 2})
+	defer client.Close()
+
+	mockServer.FailHandshakes(http.StatusServiceUnavailable, 10)
+	err := client.Connect(context.Background())
+	var handshakeErr *HandshakeError
+	if !errors.As(err, &handshakeErr) || handshakeErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected the last handshake error, got %v", err)
+	}
+
+	// Permanent failures are not retried
+	mockServer.FailHandshakes(http.StatusForbidden, 1)
+	if err := client.Connect(context.Background()); !errors.As(err, &handshakeErr) || handshakeErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("Expected a 403 handshake error, got %v", err)
+	}
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect should succeed once the gateway accepts: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -13,25 +14,23 @@ import (
 
 // ConnectionManager handles WebSocket connection lifecycle following legacy broker_websocket.go patterns
 // Manages 22:00 UTC connection establishment and complex reconnection logic
+// CRITICAL: state is read by the reader, processor and monitoring goroutines - never use plain fields
 type ConnectionManager struct {
 	client       *SaxoWebSocketClient
-	connected    bool
-	reconnecting bool
-
-	// Reconnection strategy following legacy exponential backoff patterns
-	reconnectAttempts    int
-	maxReconnectAttempts int
-	baseReconnectDelay   time.Duration
-	maxReconnectDelay    time.Duration
+	connected    atomic.Bool
+	reconnecting atomic.Bool // Set while reconnectWithBackoff runs - at most one sequence at a time
+
+	// Attempts in the current reconnection sequence - backoff comes from client.ReconnectPolicy
+	reconnectAttempts atomic.Int32
+
+	// Serializes EstablishConnection so concurrent reconnect paths cannot both dial
+	establishMu sync.Mutex
 }
 
 // NewConnectionManager creates connection manager following legacy WebSocket lifecycle patterns
 func NewConnectionManager(client *SaxoWebSocketClient) *ConnectionManager {
 	return &ConnectionManager{
-		client:               client,
-		maxReconnectAttempts: 10,
-		baseReconnectDelay:   time.Second * 2,
-		maxReconnectDelay:    time.Minute * 5,
+		client: client,
 	}
 }
 
@@ -40,12 +39,20 @@ func (cm *ConnectionManager) EstablishConnection(ctx context.Context) error {
 	cm.client.logger.Info("Starting WebSocket connection",
 		"function", "EstablishConnection")
 
-	if cm.connected {
+	cm.establishMu.Lock()
+	defer cm.establishMu.Unlock()
+
+	if cm.connected.Load() {
 		cm.client.logger.Info("Connection already established",
 			"function", "EstablishConnection")
 		return fmt.Errorf("connection already established")
 	}
 
+	// Reconnect paths pass the client's context, which is nil before the first connect
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
 	// Verify authentication before connection - critical for Saxo WebSocket
 	cm.client.logger.Debug("Checking authentication",
 		"function", "EstablishConnection")
@@ -72,8 +79,8 @@ func (cm *ConnectionManager) EstablishConnection(ctx context.Context) error {
 		"token_length", len(accessToken))
 
 	// Generate context ID for this WebSocket connection session
-	// Following legacy generateHumanReadableID pattern: "websocket-{timestamp}"
-	contextId := generateHumanReadableID("websocket")
+	// Following legacy generateHumanReadableID pattern: "{name}-{timestamp}-{millis}", unique per connect
+	contextId := generateContextID(cm.client.ContextName(), cm.client.currentContextID())
 	cm.client.logger.Debug("Generated context ID",
 		"function", "EstablishConnection",
 		"context_id", contextId)
@@ -87,7 +94,9 @@ func (cm *ConnectionManager) EstablishConnection(ctx context.Context) error {
 	// Configure connection headers with OAuth2 token
 	headers := http.Header{}
 	headers.Set("Authorization", "Bearer "+accessToken)
-	headers.Set("User-Agent", "PivotWeb/2.0")
+	if cm.client.userAgent != "" {
+		headers.Set("User-Agent", cm.client.userAgent)
+	}
 
 	cm.client.logger.Debug("Configuring headers",
 		"function", "EstablishConnection",
@@ -98,7 +107,7 @@ func (cm *ConnectionManager) EstablishConnection(ctx context.Context) error {
 		"url", wsURL)
 
 	// Get HTTP client to extract TLS config (for tests with self-signed certs)
-	httpClient, err := cm.client.authClient.GetHTTPClient(ctx)
+	httpClient, err := cm.client.getHTTPClient(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get HTTP client: %w", err)
 	}
@@ -106,15 +115,31 @@ func (cm *ConnectionManager) EstablishConnection(ctx context.Context) error {
 	// Create WebSocket connection with timeout
 	// Use TLS config from HTTP client if available (for test compatibility)
 	dialer := websocket.Dialer{
-		HandshakeTimeout: 30 * time.Second,
+		HandshakeTimeout: cm.client.requestTimeout,
 		ReadBufferSize:   4096,
 		WriteBufferSize:  4096,
 	}
 
 	// For TLS connections, use the HTTP client's transport TLS config
 	// This ensures test mock servers with self-signed certs work properly
-	if transport, ok := httpClient.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
-		dialer.TLSClientConfig = transport.TLSClientConfig
+	// An injected WithHTTPClient also lends its proxy
+	if transport, ok := httpClient.Transport.(*http.Transport); ok {
+		dialer.Proxy = transport.Proxy
+		if transport.TLSClientConfig != nil {
+			dialer.TLSClientConfig = transport.TLSClientConfig
+		}
+	}
+	// saxo.WithTransport: same proxy, TLS and dial settings as the REST traffic
+	// The dialer has a single timeout for the whole opening handshake, TLSHandshakeTimeout sets it
+	if transport := cm.client.transport; transport != nil {
+		dialer.Proxy = transport.Proxy()
+		dialer.NetDialContext = transport.Dialer().DialContext
+		if transport.TLSHandshakeTimeout > 0 {
+			dialer.HandshakeTimeout = transport.TLSHandshakeTimeout
+		}
+		if transport.TLSConfig != nil {
+			dialer.TLSClientConfig = transport.TLSConfig.Clone()
+		}
 	}
 
 	cm.client.logger.Debug("Dialing WebSocket",
@@ -126,6 +151,7 @@ func (cm *ConnectionManager) EstablishConnection(ctx context.Context) error {
 				"function", "EstablishConnection",
 				"status_code", resp.StatusCode,
 				"headers", resp.Header)
+			err = &HandshakeError{StatusCode: resp.StatusCode, Err: err}
 		} else {
 			cm.client.logger.Error("WebSocket dial failed",
 				"function", "EstablishConnection",
@@ -151,18 +177,20 @@ func (cm *ConnectionManager) EstablishConnection(ctx context.Context) error {
 	})
 
 	// Connection established successfully
-	cm.client.conn = conn
-	cm.client.contextID = contextId // Use the contextId we generated earlier
-	cm.client.lastSequenceNumber = 0
-	cm.connected = true
-	cm.reconnectAttempts = 0
+	cm.client.setConnection(conn, contextId) // Use the contextId we generated earlier
+	cm.client.lastSequenceNumber.Store(0)
+	cm.connected.Store(true)
+	cm.reconnectAttempts.Store(0)
 
 	cm.client.logger.Info("WebSocket connection established successfully",
 		"function", "EstablishConnection",
-		"context_id", cm.client.contextID,
+		"context_id", contextId,
 		"local_addr", conn.LocalAddr().String(),
 		"remote_addr", conn.RemoteAddr().String())
 
+	// Notify SetStateChannels consumers of the new context ID
+	cm.client.publishConnectionState(true, contextId)
+
 	// NEW: Start separated reader/processor/reconnection goroutines
 	// Following legacy broker_websocket.go breakthrough pattern - CRITICAL FIX
 
@@ -171,7 +199,7 @@ func (cm *ConnectionManager) EstablishConnection(ctx context.Context) error {
 	// This ensures goroutines use a fresh, non-canceled context
 	cm.client.logger.Debug("Creating fresh context for goroutines",
 		"function", "EstablishConnection")
-	cm.client.ctx, cm.client.cancel = context.WithCancel(context.Background())
+	cm.client.newConnContext(false)
 
 	cm.client.logger.Info("Starting goroutines",
 		"function", "EstablishConnection")
@@ -181,9 +209,10 @@ func (cm *ConnectionManager) EstablishConnection(ctx context.Context) error {
 		"function", "EstablishConnection")
 	go cm.client.readMessages()
 
-	// Start processor goroutine (handles messages and errors)
+	// Start processor goroutine (handles messages and errors), with the worker pool Close stopped
 	cm.client.logger.Debug("Starting processor goroutine",
 		"function", "EstablishConnection")
+	cm.client.startMessageWorkers()
 	go cm.client.processMessages()
 
 	// CRITICAL: Check if reconnection handler goroutine is already running (singleton pattern)
@@ -205,11 +234,11 @@ func (cm *ConnectionManager) EstablishConnection(ctx context.Context) error {
 		"function", "EstablishConnection")
 	go cm.startSubscriptionMonitoring()
 
-	// Start token refresh timer - CRITICAL for keeping WebSocket alive
+	// Subscribe to token rotations - CRITICAL for keeping WebSocket alive
 	// Following legacy broker_websocket.go pattern (line 165)
-	cm.client.logger.Debug("Starting token refresh timer",
+	cm.client.logger.Debug("Subscribing to token refresh",
 		"function", "EstablishConnection")
-	timeleft := cm.client.startTokenRefreshTimer()
+	timeleft := cm.client.startTokenRefresh()
 	cm.client.logger.Debug("Token refresh scheduled",
 		"function", "EstablishConnection",
 		"expires_in", timeleft)
@@ -234,70 +263,36 @@ func (cm *ConnectionManager) HandleConnectionError(err error) {
 
 	cm.handleConnectionClosed()
 
-	if !cm.reconnecting {
-		cm.reconnecting = true
+	// CompareAndSwap: concurrent errors start a single reconnection sequence
+	if cm.reconnecting.CompareAndSwap(false, true) {
 		go cm.reconnectWithBackoff()
 	}
 }
 
-// reconnectWithBackoff implements exponential backoff reconnection following legacy patterns
+// reconnectWithBackoff reconnects and resubscribes following the client's ReconnectPolicy
 func (cm *ConnectionManager) reconnectWithBackoff() {
-	defer func() {
-		cm.reconnecting = false
-	}()
-
-	for cm.reconnectAttempts < cm.maxReconnectAttempts {
-		cm.reconnectAttempts++
+	defer cm.reconnecting.Store(false)
 
-		// Calculate exponential backoff delay
-		delay := time.Duration(cm.reconnectAttempts) * cm.baseReconnectDelay
-		if delay > cm.maxReconnectDelay {
-			delay = cm.maxReconnectDelay
-		}
-
-		cm.client.logger.Info("Reconnection attempt",
-			"function", "reconnectWithBackoff",
-			"attempt", cm.reconnectAttempts,
-			"max_attempts", cm.maxReconnectAttempts,
-			"delay", delay)
-
-		// Wait before reconnection attempt
-		select {
-		case <-cm.client.ctx.Done():
-			cm.client.logger.Info("Reconnection cancelled",
-				"function", "reconnectWithBackoff",
-				"reason", "context cancellation")
-			return
-		case <-time.After(delay):
-			// Continue with reconnection attempt
-		}
+	err := cm.client.retryWithPolicy("reconnectWithBackoff", func(attempt int) error {
+		cm.reconnectAttempts.Store(int32(attempt))
 
-		// Attempt to reestablish connection
-		if err := cm.EstablishConnection(cm.client.ctx); err != nil {
-			cm.client.logger.Warn("Reconnection attempt failed",
-				"function", "reconnectWithBackoff",
-				"attempt", cm.reconnectAttempts,
-				"error", err)
-			continue
+		if err := cm.EstablishConnection(cm.client.connContext()); err != nil {
+			return err
 		}
 
 		// Resubscribe to all previous subscriptions with new reference IDs
-		if err := cm.client.subscriptionManager.HandleSubscriptions(nil); err != nil {
-			cm.client.logger.Warn("Resubscription failed after reconnection",
-				"function", "reconnectWithBackoff",
-				"error", err)
+		if err := cm.client.subscriptionManager.ResubscribeAll(cm.client.connContext()); err != nil {
 			cm.handleConnectionClosed()
-			continue
+			return fmt.Errorf("resubscription failed after reconnection: %w", err)
 		}
-
-		cm.client.logger.Info("WebSocket reconnection successful",
-			"function", "reconnectWithBackoff")
+		return nil
+	})
+	if err != nil {
 		return
 	}
 
-	cm.client.logger.Error("Max reconnection attempts reached",
-		"function", "reconnectWithBackoff",
-		"max_attempts", cm.maxReconnectAttempts)
+	cm.client.logger.Info("WebSocket reconnection successful",
+		"function", "reconnectWithBackoff")
 }
 
 // startSubscriptionMonitoring monitors subscription health following legacy patterns
@@ -325,29 +320,40 @@ func (cm *ConnectionManager) startSubscriptionMonitoring() {
 	cm.client.logger.Info("Subscription monitoring goroutine started",
 		"function", "startSubscriptionMonitoring")
 
-	ticker := time.NewTicker(55 * time.Second) // Check every 55 seconds
-	defer ticker.Stop()
-
+	clock := cm.client.clock
 	for {
+		// Thresholds are re-read every pass, so SetSubscriptionOptions applies without reconnecting
+		_, interval := cm.client.monitorOptions()
 		select {
-		case <-cm.client.ctx.Done():
+		case <-cm.client.done():
 			return
-		case <-ticker.C:
-			if !cm.connected {
+		case <-clock.After(interval): // Default every 55 seconds (SubscriptionOptions.CheckInterval)
+			if !cm.connected.Load() {
 				continue
 			}
 
-			// Check for timed-out subscriptions (no message for >100 seconds)
-			now := time.Now()
+			// Check for timed-out subscriptions (default: no message for >100 seconds)
+			options, _ := cm.client.monitorOptions()
+			now := clock.Now()
 			var timedOut []string
 
+			// Temporarily disabled or recovering subscriptions are silent by design - skip them,
+			// as well as subscriptions configured to never time out
+			totalSubscriptions := 0
 			cm.client.lastMessageTimestampsMu.RLock()
 			for refID, lastTimestamp := range cm.client.lastMessageTimestamps {
-				if now.Sub(lastTimestamp) > 100*time.Second {
+				opts, ok := options[refID]
+				if !ok {
+					opts = cm.client.SubscriptionOptionsFor("")
+				}
+				if !opts.monitored() || cm.client.subscriptionHealth.paused(refID) {
+					continue
+				}
+				totalSubscriptions++
+				if now.Sub(lastTimestamp) > opts.InactivityTimeout {
 					timedOut = append(timedOut, refID)
 				}
 			}
-			totalSubscriptions := len(cm.client.lastMessageTimestamps)
 			cm.client.lastMessageTimestampsMu.RUnlock()
 
 			// If all subscriptions timed out, trigger full reconnect
@@ -392,11 +398,13 @@ func (cm *ConnectionManager) startSubscriptionMonitoring() {
 
 // handleConnectionClosed updates connection state following legacy cleanup patterns
 func (cm *ConnectionManager) handleConnectionClosed() {
-	cm.connected = false
+	// Swap: only the caller that saw the connection up publishes the disconnect
+	if cm.connected.Swap(false) {
+		cm.client.publishConnectionState(false, "")
+	}
 
-	if cm.client.conn != nil {
-		cm.client.conn.Close()
-		cm.client.conn = nil
+	if conn := cm.client.takeConnection(); conn != nil {
+		conn.Close()
 	}
 }
 
@@ -406,7 +414,7 @@ func (cm *ConnectionManager) CloseConnection() error {
 	cm.client.logger.Info("Closing WebSocket connection",
 		"function", "CloseConnection")
 
-	if !cm.connected {
+	if !cm.connected.Load() {
 		cm.client.logger.Debug("Already closed (no-op)",
 			"function", "CloseConnection")
 		return nil // Already closed
@@ -414,11 +422,9 @@ func (cm *ConnectionManager) CloseConnection() error {
 
 	// CRITICAL: Cancel context to signal all goroutines to stop
 	// Following legacy broker_websocket.go pattern (line 670)
-	if cm.client.cancel != nil {
-		cm.client.logger.Debug("Canceling context to stop goroutines",
-			"function", "CloseConnection")
-		cm.client.cancel()
-	}
+	cm.client.logger.Debug("Canceling context to stop goroutines",
+		"function", "CloseConnection")
+	cm.client.cancelContext()
 
 	// CRITICAL: Wait for goroutines to exit with timeout (following legacy pattern)
 	// Legacy broker_websocket.go has 5-second timeout for reader/processor/monitoring
@@ -491,11 +497,11 @@ func (cm *ConnectionManager) CloseConnection() error {
 	}
 
 	// Now close the actual WebSocket connection
-	if cm.client.conn != nil {
+	if conn := cm.client.takeConnection(); conn != nil {
 		cm.client.logger.Debug("Sending close message",
 			"function", "CloseConnection")
 		// Send close message
-		err := cm.client.conn.WriteMessage(
+		err := conn.WriteMessage(
 			websocket.CloseMessage,
 			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
 		)
@@ -511,7 +517,7 @@ func (cm *ConnectionManager) CloseConnection() error {
 		cm.client.logger.Debug("Closing TCP connection",
 			"function", "CloseConnection")
 		// Close connection
-		err = cm.client.conn.Close()
+		err = conn.Close()
 		if err != nil {
 			cm.client.logger.Warn("Error closing connection",
 				"function", "CloseConnection",
@@ -520,12 +526,11 @@ func (cm *ConnectionManager) CloseConnection() error {
 			cm.client.logger.Debug("TCP connection closed",
 				"function", "CloseConnection")
 		}
-
-		cm.client.conn = nil
 	}
 
-	cm.connected = false
-	cm.reconnectAttempts = 0
+	cm.connected.Store(false)
+	cm.reconnectAttempts.Store(0)
+	cm.client.publishConnectionState(false, "")
 
 	cm.client.logger.Info("WebSocket connection closed successfully",
 		"function", "CloseConnection")
@@ -534,7 +539,12 @@ func (cm *ConnectionManager) CloseConnection() error {
 
 // IsConnected returns current connection status
 func (cm *ConnectionManager) IsConnected() bool {
-	return cm.connected
+	return cm.connected.Load()
+}
+
+// ReconnectAttempts returns the attempt number of the running reconnection sequence (0 = none)
+func (cm *ConnectionManager) ReconnectAttempts() int {
+	return int(cm.reconnectAttempts.Load())
 }
 
 // buildWebSocketURL constructs Saxo WebSocket URL following legacy connectWebSocket pattern
@@ -0,0 +1,196 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+// Run with -race: connection state is shared by the reader, processor, monitor and reconnect goroutines
+
+func newReconnectTestClient(t *testing.T, mockServer *mocktesting.MockSaxoWebSocketServer, opts ...saxo.Option) *SaxoWebSocketClient {
+	t.Helper()
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger, opts...)
+	client.SetReconnectPolicy(ReconnectPolicy{InitialDelay: 10 * time.Millisecond, Multiplier: 1, MaxAttempts: 5})
+	return client
+}
+
+// waitFor polls condition until it holds or the deadline passes
+func waitFor(t *testing.T, timeout time.Duration, condition func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return condition()
+}
+
+func TestConnectionManager_IsConnectedOnInterface(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	var client saxo.WebSocketClient = newReconnectTestClient(t, mockServer)
+	if client.IsConnected() {
+		t.Error("Expected disconnected before Connect")
+	}
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if !client.IsConnected() {
+		t.Error("Expected connected after Connect")
+	}
+	client.Close()
+	if client.IsConnected() {
+		t.Error("Expected disconnected after Close")
+	}
+}
+
+func TestConnectionManager_ConcurrentReconnects(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	client := newReconnectTestClient(t, mockServer)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	// Readers poll the state while errors from several goroutines race to reconnect
+	stop := make(chan struct{})
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					client.IsConnected()
+					client.connectionManager.ReconnectAttempts()
+					client.currentContextID()
+					client.done()
+				}
+			}
+		}()
+	}
+
+	var errorsSent sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		errorsSent.Add(1)
+		go func(i int) {
+			defer errorsSent.Done()
+			if i%2 == 0 {
+				client.connectionManager.HandleConnectionError(errors.New("injected connection error"))
+			} else {
+				client.handleConnectionError(errors.New("injected read error"))
+			}
+		}(i)
+	}
+	errorsSent.Wait()
+
+	reconnected := waitFor(t, 5*time.Second, func() bool {
+		return client.IsConnected() && !client.connectionManager.reconnecting.Load() && mockServer.ActiveConnections() == 1
+	})
+	close(stop)
+	readers.Wait()
+
+	if !reconnected {
+		t.Fatalf("Expected one live connection after concurrent reconnects (connected %v, active %d)",
+			client.IsConnected(), mockServer.ActiveConnections())
+	}
+	if got := len(mockServer.Connections()); got < 2 {
+		t.Errorf("Expected at least one reconnect, server saw %d connects", got)
+	}
+	if client.currentContextID() == "" {
+		t.Error("Expected a context ID after reconnect")
+	}
+}
+
+func TestConnectionManager_CloseDuringReconnect(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	client := newReconnectTestClient(t, mockServer)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		client.connectionManager.HandleConnectionError(errors.New("injected connection error"))
+	}()
+	go func() {
+		defer wg.Done()
+		client.Close()
+	}()
+	wg.Wait()
+
+	// Shutdown stops any reconnection sequence the error started
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if !waitFor(t, 2*time.Second, func() bool { return !client.connectionManager.reconnecting.Load() }) {
+		t.Error("Reconnection sequence still running after Shutdown")
+	}
+}
+
+func TestConnectionManager_SubscriptionTimeoutReconnects(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	clock := mocktesting.NewFakeClock(time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC))
+	client := newReconnectTestClient(t, mockServer, saxo.WithClock(clock))
+	client.SetReconnectPolicy(ReconnectPolicy{InitialDelay: 5 * time.Second, Multiplier: 1, MaxAttempts: 5})
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+	client.UpdateLastMessageTimestamp("prices-ref")
+
+	// First check at 55s: silent for 55s, still within the 100s limit
+	if err := clock.WaitForWaiters(1, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(55 * time.Second)
+	if err := clock.WaitForWaiters(1, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(mockServer.Connections()); got != 1 {
+		t.Fatalf("Expected no reconnect after 55s, server saw %d connects", got)
+	}
+
+	// Second check at 110s: the only subscription timed out - reconnect waits out the 5s backoff
+	clock.Advance(55 * time.Second)
+	if err := clock.WaitForWaiters(1, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(mockServer.Connections()); got != 1 {
+		t.Fatalf("Expected reconnect to wait for backoff, server saw %d connects", got)
+	}
+	clock.Advance(5 * time.Second)
+	if !waitFor(t, 3*time.Second, func() bool { return len(mockServer.Connections()) == 2 && client.IsConnected() }) {
+		t.Fatalf("Expected reconnect after 100s timeout, server saw %d connects", len(mockServer.Connections()))
+	}
+}
@@ -0,0 +1,136 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// ContextPool manages several independent streaming contexts that share one AuthClient
+// Saxo allows multiple contexts per token; each has its own connection, subscriptions, channels
+// and reconnects, so a reset of a high-frequency price context never touches a slow portfolio context.
+// Token rotations re-authorize every connected context (each client subscribes to the TokenCoordinator)
+type ContextPool struct {
+	authClient   saxo.AuthClient
+	apiBaseURL   string
+	websocketURL string
+	logger       *slog.Logger
+	opts         []saxo.Option
+
+	mu      sync.Mutex
+	clients map[string]*SaxoWebSocketClient
+	order   []string // Creation order for ConnectAll and Shutdown
+}
+
+// Compile-time check that the pool can be passed to saxo.Shutdown
+var _ saxo.Shutdowner = (*ContextPool)(nil)
+
+// NewContextPool creates an empty pool; opts apply to every client it creates
+func NewContextPool(authClient saxo.AuthClient, apiBaseURL, websocketURL string, logger *slog.Logger, opts ...saxo.Option) *ContextPool {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ContextPool{
+		authClient:   authClient,
+		apiBaseURL:   apiBaseURL,
+		websocketURL: websocketURL,
+		logger:       logger,
+		opts:         opts,
+		clients:      make(map[string]*SaxoWebSocketClient),
+	}
+}
+
+// Context returns the client for name, creating it (not connected) on first use
+// name becomes the context ID prefix, e.g. "prices-20260105-090000-123" (see SetContextName)
+func (p *ContextPool) Context(name string) (*SaxoWebSocketClient, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if client, ok := p.clients[name]; ok {
+		return client, nil
+	}
+
+	client := NewSaxoWebSocketClient(p.authClient, p.apiBaseURL, p.websocketURL, p.logger.With("context", name), p.opts...)
+	if err := client.SetContextName(name); err != nil {
+		return nil, err
+	}
+	p.clients[name] = client
+	p.order = append(p.order, name)
+
+	p.logger.Info("Streaming context created",
+		"function", "ContextPool.Context",
+		"context", name,
+		"contexts", len(p.clients))
+	return client, nil
+}
+
+// Lookup returns the client for name without creating it
+func (p *ContextPool) Lookup(name string) (*SaxoWebSocketClient, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	client, ok := p.clients[name]
+	return client, ok
+}
+
+// Names returns the context names in creation order
+func (p *ContextPool) Names() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.order...)
+}
+
+// ConnectAll connects every context that is not connected yet
+// All contexts are attempted; errors are joined
+func (p *ContextPool) ConnectAll(ctx context.Context) error {
+	var errs []error
+	for _, name := range p.Names() {
+		client, _ := p.Lookup(name)
+		if err := client.Connect(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("context %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Remove shuts down the context and drops it from the pool
+func (p *ContextPool) Remove(ctx context.Context, name string) error {
+	p.mu.Lock()
+	client, ok := p.clients[name]
+	if ok {
+		delete(p.clients, name)
+		for i, existing := range p.order {
+			if existing == name {
+				p.order = append(p.order[:i], p.order[i+1:]...)
+				break
+			}
+		}
+	}
+	p.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no streaming context %q", name)
+	}
+	return client.Shutdown(ctx)
+}
+
+// Shutdown implements saxo.Shutdowner - shuts down every context, newest first
+func (p *ContextPool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	clients := make([]*SaxoWebSocketClient, 0, len(p.order))
+	for i := len(p.order) - 1; i >= 0; i-- {
+		clients = append(clients, p.clients[p.order[i]])
+	}
+	p.clients = make(map[string]*SaxoWebSocketClient)
+	p.order = nil
+	p.mu.Unlock()
+
+	var errs []error
+	for _, client := range clients {
+		if err := client.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("context %s: %w", client.ContextName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestContextPool_IndependentContexts(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+	mockAuth := &MockAuthClient{authenticated: true, accessToken: "test_token_123", httpClient: mockServer.GetHTTPClient()}
+	pool := NewContextPool(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	ctx := context.Background()
+
+	prices, err := pool.Context("prices")
+	if err != nil {
+		t.Fatalf("Context failed: %v", err)
+	}
+	portfolio, _ := pool.Context("portfolio")
+	if again, _ := pool.Context("prices"); again != prices {
+		t.Error("Expected the same client for the same name")
+	}
+	if _, err := pool.Context("bad name"); err == nil {
+		t.Error("Expected invalid context name error")
+	}
+
+	if err := pool.ConnectAll(ctx); err != nil {
+		t.Fatalf("ConnectAll failed: %v", err)
+	}
+	if !strings.HasPrefix(prices.ContextID(), "prices-") || !strings.HasPrefix(portfolio.ContextID(), "portfolio-") {
+		t.Errorf("Unexpected context IDs %q %q", prices.ContextID(), portfolio.ContextID())
+	}
+	if mockServer.ActiveConnections() != 2 {
+		t.Errorf("Expected 2 connections, got %d", mockServer.ActiveConnections())
+	}
+
+	// A rotation re-authorizes every context
+	mockAuth.mu.Lock()
+	listeners := append([]saxo.TokenListener(nil), mockAuth.tokenListeners...)
+	mockAuth.mu.Unlock()
+	for _, listener := range listeners {
+		listener(ctx, saxo.TokenInfo{AccessToken: "rotated", Expiry: time.Now().Add(20 * time.Minute)})
+	}
+	mockAuth.mu.Lock()
+	reauthorized := strings.Join(mockAuth.reauthorized, ",")
+	mockAuth.mu.Unlock()
+	if !strings.Contains(reauthorized, prices.ContextID()) || !strings.Contains(reauthorized, portfolio.ContextID()) {
+		t.Errorf("Expected both contexts re-authorized, got %s", reauthorized)
+	}
+
+	// Resetting one context leaves the other connected
+	if err := pool.Remove(ctx, "prices"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if !portfolio.IsConnected() || prices.IsConnected() {
+		t.Errorf("Expected only portfolio connected, prices=%v portfolio=%v", prices.IsConnected(), portfolio.IsConnected())
+	}
+	if names := pool.Names(); len(names) != 1 || names[0] != "portfolio" {
+		t.Errorf("Unexpected names %v", names)
+	}
+
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+	if _, ok := pool.Lookup("portfolio"); ok || portfolio.IsConnected() {
+		t.Error("Expected pool empty and disconnected after Shutdown")
+	}
+}
+
+func TestGenerateContextID_UniqueAcrossClients(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		// Interleaved names must not reset the dedup of an earlier name
+		for _, name := range []string{"prices", "orders"} {
+			id := generateContextID(name, "")
+			if seen[id] {
+				t.Fatalf("Duplicate context ID %s", id)
+			}
+			seen[id] = true
+		}
+	}
+}
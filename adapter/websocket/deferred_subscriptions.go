@@ -0,0 +1,117 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// deferredSubscription is a subscribe call made while disconnected, replayed once a context ID exists
+type deferredSubscription struct {
+	key       string // Later intents with the same key replace earlier ones
+	subscribe func(ctx context.Context) error
+}
+
+// deferUntilConnected queues subscribe under key when the WebSocket is not connected
+// Returns false when connected - the caller subscribes immediately
+// CRITICAL: the connected check and the append share deferredMu with flushDeferredSubscriptions,
+// so an intent queued while Connect is running is never missed
+func (ws *SaxoWebSocketClient) deferUntilConnected(key string, subscribe func(ctx context.Context) error) bool {
+	ws.deferredMu.Lock()
+	defer ws.deferredMu.Unlock()
+
+	if ws.connectionManager.IsConnected() {
+		return false
+	}
+
+	for i, pending := range ws.deferred {
+		if pending.key == key {
+			ws.deferred[i].subscribe = subscribe
+			ws.logger.Debug("Deferred subscription replaced",
+				"function", "deferUntilConnected",
+				"key", key)
+			return true
+		}
+	}
+	ws.deferred = append(ws.deferred, deferredSubscription{key: key, subscribe: subscribe})
+	ws.logger.Info("Not connected, subscription deferred until Connect",
+		"function", "deferUntilConnected",
+		"key", key,
+		"pending", len(ws.deferred))
+	return true
+}
+
+// cancelDeferred drops a queued intent, returns true if one was queued
+func (ws *SaxoWebSocketClient) cancelDeferred(key string) bool {
+	ws.deferredMu.Lock()
+	defer ws.deferredMu.Unlock()
+
+	for i, pending := range ws.deferred {
+		if pending.key == key {
+			ws.deferred = append(ws.deferred[:i], ws.deferred[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// PendingSubscriptions returns the keys of subscriptions waiting for a connection, in call order
+func (ws *SaxoWebSocketClient) PendingSubscriptions() []string {
+	ws.deferredMu.Lock()
+	defer ws.deferredMu.Unlock()
+
+	keys := make([]string, len(ws.deferred))
+	for i, pending := range ws.deferred {
+		keys[i] = pending.key
+	}
+	return keys
+}
+
+// flushDeferredSubscriptions replays queued intents in call order after Connect or a reconnect
+// Failed intents are logged and dropped - the error is returned for the caller to log
+func (ws *SaxoWebSocketClient) flushDeferredSubscriptions(ctx context.Context) error {
+	ws.deferredMu.Lock()
+	pending := ws.deferred
+	ws.deferred = nil
+	ws.deferredMu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	ws.logger.Info("Flushing deferred subscriptions",
+		"function", "flushDeferredSubscriptions",
+		"count", len(pending))
+
+	var errs []error
+	for _, intent := range pending {
+		if err := intent.subscribe(ctx); err != nil {
+			ws.logger.Error("Deferred subscription failed",
+				"function", "flushDeferredSubscriptions",
+				"key", intent.key,
+				"error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", intent.key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deferPriceSync queues a re-post of the assetType price subscription with the router's UICs at flush time
+// Shared by SubscribeToPrices, SubscribeInstruments and Unsubscribe - one POST carries all of them
+func (ws *SaxoWebSocketClient) deferPriceSync(assetType string) bool {
+	return ws.deferUntilConnected("prices:"+assetType, func(ctx context.Context) error {
+		ws.priceRouter.changeMu.Lock()
+		defer ws.priceRouter.changeMu.Unlock()
+
+		uics := ws.priceRouter.uicsFor(assetType)
+		if len(uics) == 0 {
+			return ws.subscriptionManager.UnsubscribeInstrumentPrices(ctx, assetType)
+		}
+		body, err := ws.subscriptionManager.SubscribeToInstrumentPricesContext(ctx, uicStrings(uics), assetType)
+		if err != nil {
+			return err
+		}
+		ws.messageHandler.publishPriceSnapshot(body, assetType)
+		return nil
+	})
+}
@@ -0,0 +1,96 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestSaxoWebSocketClient_SubscribeBeforeConnect(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+	mockServer.SetSnapshotQuote(21, 1.1000, 1.1002)
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Previously failed with "WebSocket not connected - no context ID"
+	if err := client.SubscribeToPrices(ctx, []string{"21"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices before Connect failed: %v", err)
+	}
+	handle, err := client.Subscribe(ctx, []string{"31"}, "FxSpot", 10)
+	if err != nil {
+		t.Fatalf("Subscribe before Connect failed: %v", err)
+	}
+	if err := client.SubscribeToPortfolio(ctx); err != nil {
+		t.Fatalf("SubscribeToPortfolio before Connect failed: %v", err)
+	}
+	if _, err := client.RegisterSubscription(ctx, "invalid", "/port/v1/positions/subscriptions", nil, nil); err == nil {
+		t.Error("Expected a nil handler to be rejected before deferring")
+	}
+	handler := func(referenceID string, payload []byte) error { return nil }
+	if _, err := client.RegisterSubscription(ctx, "positions", "/port/v1/positions/subscriptions", nil, handler); err != nil {
+		t.Fatalf("RegisterSubscription before Connect failed: %v", err)
+	}
+	if err := client.UnregisterSubscription(ctx, "positions"); err != nil {
+		t.Fatalf("UnregisterSubscription of a deferred subscription failed: %v", err)
+	}
+
+	// Both price calls share one intent per asset type; the unregistered one is gone
+	want := []string{"prices:FxSpot", "portfolio"}
+	if got := client.PendingSubscriptions(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected pending %v, got %v", want, got)
+	}
+	if got := len(mockServer.GetActiveSubscriptions()); got != 0 {
+		t.Fatalf("Expected nothing subscribed before Connect, got %d", got)
+	}
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if got := client.PendingSubscriptions(); len(got) != 0 {
+		t.Errorf("Expected queue flushed on Connect, got %v", got)
+	}
+	if got := activePriceUicsByAssetType(mockServer)["FxSpot"]; got != "21,31" {
+		t.Errorf("Expected one FxSpot subscription for 21,31, got %q", got)
+	}
+	if got := len(mockServer.GetActiveSubscriptions()); got != 2 {
+		t.Errorf("Expected price and balance subscriptions after flush, got %d", got)
+	}
+
+	select {
+	case update := <-client.GetPriceUpdateChannel():
+		if !update.Snapshot || update.Uic != 21 {
+			t.Errorf("Expected snapshot for UIC 21 after flush, got %+v", update)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Snapshot not published after deferred subscription flushed")
+	}
+
+	// Connected - calls go straight to Saxo again
+	if err := handle.Unsubscribe(ctx); err != nil {
+		t.Fatalf("Unsubscribe failed: %v", err)
+	}
+	if got := activePriceUicsByAssetType(mockServer)["FxSpot"]; got != "21" {
+		t.Errorf("Expected subscription narrowed to 21, got %q", got)
+	}
+	if got := client.PendingSubscriptions(); len(got) != 0 {
+		t.Errorf("Expected nothing queued while connected, got %v", got)
+	}
+}
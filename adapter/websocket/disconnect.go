@@ -0,0 +1,131 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// errServerDisconnect is the reconnection reason after a _disconnect control message
+var errServerDisconnect = errors.New("server requested disconnect")
+
+// DisconnectPolicy controls the reaction to Saxo's _disconnect control message, which the
+// streaming server sends before it drops the connection, e.g. ahead of planned maintenance
+type DisconnectPolicy struct {
+	Reconnect bool          // false = Close the client and leave reconnecting to the caller
+	Delay     time.Duration // Wait before reconnecting when the message advises no delay
+	MaxDelay  time.Duration // Upper bound for an advised delay (0 = no bound)
+}
+
+// DefaultDisconnectPolicy returns the policy used unless SetDisconnectPolicy is called
+// Reconnects after the advised delay, 5s when none is given, at most 5 minutes
+func DefaultDisconnectPolicy() DisconnectPolicy {
+	return DisconnectPolicy{
+		Reconnect: true,
+		Delay:     5 * time.Second,
+		MaxDelay:  5 * time.Minute,
+	}
+}
+
+// delayFor returns the wait before reconnecting after msg
+func (p DisconnectPolicy) delayFor(msg DisconnectMessage) time.Duration {
+	delay := p.Delay
+	if msg.ReconnectDelaySeconds > 0 {
+		delay = time.Duration(msg.ReconnectDelaySeconds) * time.Second
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// SetDisconnectPolicy replaces the _disconnect handling policy
+func (ws *SaxoWebSocketClient) SetDisconnectPolicy(policy DisconnectPolicy) {
+	ws.reconnectPolicyMu.Lock()
+	defer ws.reconnectPolicyMu.Unlock()
+	ws.disconnectPolicy = policy
+}
+
+// DisconnectPolicy returns the current _disconnect handling policy
+func (ws *SaxoWebSocketClient) DisconnectPolicy() DisconnectPolicy {
+	ws.reconnectPolicyMu.RLock()
+	defer ws.reconnectPolicyMu.RUnlock()
+	return ws.disconnectPolicy
+}
+
+// IsDraining reports whether the server announced a disconnect and a reconnect is pending
+func (ws *SaxoWebSocketClient) IsDraining() bool {
+	return ws.draining.Load()
+}
+
+// parseDisconnectMessage reads a _disconnect payload, sent as an object or a one-element array
+// An unreadable payload yields the zero message - the policy's default delay applies
+func parseDisconnectMessage(payload []byte) DisconnectMessage {
+	var msg DisconnectMessage
+	if err := json.Unmarshal(payload, &msg); err == nil {
+		return msg
+	}
+	var msgs []DisconnectMessage
+	if err := json.Unmarshal(payload, &msgs); err == nil && len(msgs) > 0 {
+		return msgs[0]
+	}
+	return DisconnectMessage{}
+}
+
+// handleServerDisconnect marks the connection draining and schedules a reconnect per DisconnectPolicy
+// Runs on the processor goroutine, so Close and the reconnect are started on their own goroutines
+func (ws *SaxoWebSocketClient) handleServerDisconnect(msg DisconnectMessage) {
+	policy := ws.DisconnectPolicy()
+	if !policy.Reconnect {
+		ws.logger.Warn("Server requested disconnect, closing client",
+			"function", "handleServerDisconnect",
+			"reason", msg.Reason)
+		go ws.Close()
+		return
+	}
+	if !ws.draining.CompareAndSwap(false, true) {
+		return // Reconnect already scheduled
+	}
+
+	delay := policy.delayFor(msg)
+	ws.logger.Warn("Server requested disconnect, reconnect scheduled",
+		"function", "handleServerDisconnect",
+		"reason", msg.Reason,
+		"delay", delay)
+	ws.emitEvent(saxo.StreamEvent{Kind: saxo.ConnectionEvent, Connection: &saxo.ConnectionUpdate{
+		Connected:      ws.IsConnected(),
+		ContextID:      ws.currentContextID(),
+		Draining:       true,
+		ReconnectAfter: delay,
+	}})
+	// State channels carry no draining flag - report the connection as gone, true follows on reconnect
+	ws.publishStateChannels(false, "")
+
+	go ws.reconnectAfterDisconnect(delay)
+}
+
+// reconnectAfterDisconnect waits delay, then hands the reconnect to the reconnection handler
+// Close during the wait cancels it
+func (ws *SaxoWebSocketClient) reconnectAfterDisconnect(delay time.Duration) {
+	defer ws.draining.Store(false)
+
+	select {
+	case <-ws.done():
+		return
+	case <-ws.clock.After(delay):
+	}
+	if ws.isShutdown() {
+		return
+	}
+
+	ws.logger.Info("Reconnecting after server disconnect",
+		"function", "reconnectAfterDisconnect")
+	select {
+	case ws.reconnectionTrigger <- errServerDisconnect:
+	default:
+		ws.logger.Debug("Reconnection already queued",
+			"function", "reconnectAfterDisconnect")
+	}
+}
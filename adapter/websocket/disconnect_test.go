@@ -0,0 +1,159 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+// nextConnectionEvent returns the next ConnectionEvent from events, skipping other kinds
+func nextConnectionEvent(t *testing.T, events <-chan saxo.StreamEvent) saxo.ConnectionUpdate {
+	t.Helper()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.Kind == saxo.ConnectionEvent {
+				return *event.Connection
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for a connection event")
+		}
+	}
+}
+
+func TestSaxoWebSocketClient_DisconnectSchedulesReconnect(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	clock := mocktesting.NewFakeClock(time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC))
+	client := newReconnectTestClient(t, mockServer, saxo.WithClock(clock))
+	client.SetReconnectPolicy(ReconnectPolicy{Multiplier: 1, MaxAttempts: 5})
+	events := client.Events()
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+	if update := nextConnectionEvent(t, events); !update.Connected {
+		t.Fatalf("Expected connected event, got %+v", update)
+	}
+	if err := clock.WaitForWaiters(1, 2*time.Second); err != nil {
+		t.Fatal(err) // Subscription monitoring
+	}
+
+	if err := mockServer.SendDisconnectWithDelay(30 * time.Second); err != nil {
+		t.Fatalf("SendDisconnect failed: %v", err)
+	}
+	update := nextConnectionEvent(t, events)
+	if !update.Draining || update.ReconnectAfter != 30*time.Second {
+		t.Fatalf("Expected draining event with 30s delay, got %+v", update)
+	}
+	if !client.IsDraining() {
+		t.Error("Expected client to be draining")
+	}
+
+	// The server drops the connection - no reconnect before the advised delay
+	if err := clock.WaitForWaiters(2, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	mockServer.DropConnections()
+	if update := nextConnectionEvent(t, events); update.Connected {
+		t.Fatalf("Expected disconnected event, got %+v", update)
+	}
+	clock.Advance(29 * time.Second)
+	time.Sleep(100 * time.Millisecond)
+	if got := len(mockServer.Connections()); got != 1 {
+		t.Fatalf("Expected reconnect to wait for the advised delay, server saw %d connects", got)
+	}
+
+	clock.Advance(time.Second)
+	if !waitFor(t, 5*time.Second, func() bool {
+		return len(mockServer.Connections()) == 2 && client.IsConnected() && !client.IsDraining()
+	}) {
+		t.Fatalf("Expected reconnect after 30s, server saw %d connects", len(mockServer.Connections()))
+	}
+}
+
+func TestSaxoWebSocketClient_DisconnectPublishesStateChannel(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	client := newReconnectTestClient(t, mockServer)
+	client.SetDisconnectPolicy(DisconnectPolicy{Reconnect: true, Delay: time.Hour})
+	stateChannel := make(chan bool, 1)
+	client.SetStateChannels(stateChannel, nil)
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+	select {
+	case connected := <-stateChannel:
+		if !connected {
+			t.Fatal("Expected connected state after Connect")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for connected state")
+	}
+
+	if err := mockServer.SendDisconnect(); err != nil {
+		t.Fatalf("SendDisconnect failed: %v", err)
+	}
+	select {
+	case connected := <-stateChannel:
+		if connected {
+			t.Fatal("Expected false on the state channel for the draining notice")
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for the draining notice on the state channel")
+	}
+	if !client.IsDraining() {
+		t.Error("Expected client to be draining")
+	}
+}
+
+func TestSaxoWebSocketClient_DisconnectClosesWithoutReconnect(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	client := newReconnectTestClient(t, mockServer)
+	client.SetDisconnectPolicy(DisconnectPolicy{Reconnect: false})
+	if err := client.Connect(context.Background()); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := mockServer.SendDisconnect(); err != nil {
+		t.Fatalf("SendDisconnect failed: %v", err)
+	}
+	if !waitFor(t, 3*time.Second, func() bool { return !client.IsConnected() }) {
+		t.Fatal("Expected the client to close on _disconnect")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if got := len(mockServer.Connections()); got != 1 {
+		t.Errorf("Expected no reconnect, server saw %d connects", got)
+	}
+	if client.IsDraining() {
+		t.Error("Close policy should not mark the client draining")
+	}
+}
+
+func TestDisconnectPolicy_Delay(t *testing.T) {
+	policy := DisconnectPolicy{Reconnect: true, Delay: 5 * time.Second, MaxDelay: time.Minute}
+	tests := []struct {
+		payload string
+		want    time.Duration
+	}{
+		{`{"ReferenceId":"_disconnect"}`, 5 * time.Second},
+		{`{"ReferenceId":"_disconnect","ReconnectDelaySeconds":20}`, 20 * time.Second},
+		{`[{"ReferenceId":"_disconnect","ReconnectDelaySeconds":600}]`, time.Minute},
+		{`not json`, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := policy.delayFor(parseDisconnectMessage([]byte(tt.payload))); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.payload, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package websocket
+
+import (
+	"sync"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// DefaultEventBuffer is the capacity of the Events channel
+const DefaultEventBuffer = 1000
+
+// eventBus is the unified stream behind Events, nil channel until the first Events call
+type eventBus struct {
+	mu      sync.Mutex
+	ch      chan saxo.StreamEvent
+	seq     uint64
+	dropped uint64
+	closed  bool
+}
+
+// Events implements saxo.EventStreamer: from the first call on, price, order, portfolio and session
+// updates go to the returned channel instead of GetPriceUpdateChannel, GetOrderUpdateChannel,
+// GetPortfolioUpdateChannel and GetSessionEventChannel, in the order they arrived (per subscription
+// only with SetMessageWorkers).
+// Connection changes are added as ConnectionEvent (SetStateChannels keeps working).
+// Price handles from Subscribe and depth updates are unaffected. Sends are non-blocking:
+// a full channel drops the event, visible as a Seq gap and in DroppedEvents.
+// Returns the same channel on every call; closed on Close
+func (ws *SaxoWebSocketClient) Events() <-chan saxo.StreamEvent {
+	ws.events.mu.Lock()
+	defer ws.events.mu.Unlock()
+	if ws.events.ch == nil {
+		ws.events.ch = make(chan saxo.StreamEvent, DefaultEventBuffer)
+		if ws.events.closed {
+			close(ws.events.ch)
+		}
+		ws.logger.Info("Unified event stream enabled",
+			"function", "Events",
+			"buffer", DefaultEventBuffer)
+	}
+	return ws.events.ch
+}
+
+// DroppedEvents returns the number of events discarded because the Events channel was full
+func (ws *SaxoWebSocketClient) DroppedEvents() uint64 {
+	ws.events.mu.Lock()
+	defer ws.events.mu.Unlock()
+	return ws.events.dropped
+}
+
+// eventsEnabled reports whether Events was called - lets hot paths skip building an event
+func (ws *SaxoWebSocketClient) eventsEnabled() bool {
+	ws.events.mu.Lock()
+	defer ws.events.mu.Unlock()
+	return ws.events.ch != nil
+}
+
+// emitEvent sends event to the unified stream and reports whether the stream is enabled
+// Callers fall back to their own channel when it returns false
+func (ws *SaxoWebSocketClient) emitEvent(event saxo.StreamEvent) bool {
+	ws.events.mu.Lock()
+	defer ws.events.mu.Unlock()
+	if ws.events.ch == nil {
+		return false
+	}
+	if ws.events.closed {
+		return true
+	}
+
+	ws.events.seq++
+	event.Seq = ws.events.seq
+	event.At = ws.clock.Now()
+	select {
+	case ws.events.ch <- event:
+	default:
+		ws.events.dropped++
+		ws.logger.Warn("Event channel full, dropping event",
+			"function", "emitEvent",
+			"kind", event.Kind,
+			"seq", event.Seq)
+	}
+	return true
+}
+
+// closeEvents closes the Events channel (if enabled) exactly once, after all producers exited
+func (ws *SaxoWebSocketClient) closeEvents() {
+	ws.events.mu.Lock()
+	defer ws.events.mu.Unlock()
+	if ws.events.closed {
+		return
+	}
+	ws.events.closed = true
+	if ws.events.ch != nil {
+		close(ws.events.ch)
+	}
+}
@@ -0,0 +1,61 @@
+package websocket
+
+import (
+	"testing"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+func TestSaxoWebSocketClient_EventsPreserveArrivalOrder(t *testing.T) {
+	client := newModelTestClient()
+	events := client.Events()
+	if client.Events() != events {
+		t.Fatal("Events should return the same channel on every call")
+	}
+
+	client.publishConnectionState(true, "ctx-1")
+	if err := client.messageHandler.handleOrderUpdate([]byte(`[{"OrderId":"order_1","Status":"Working"}]`)); err != nil {
+		t.Fatalf("handleOrderUpdate failed: %v", err)
+	}
+	if err := client.messageHandler.handlePortfolioUpdate("balance_1", []byte(`{"TotalValue":10500,"MarginUtilizationPct":12.5}`)); err != nil {
+		t.Fatalf("handlePortfolioUpdate failed: %v", err)
+	}
+	client.handleSessionEvent([]byte(`{"State":"Active","Snapshot":{"TradeLevel":"OrderOnly"}}`))
+	client.publishConnectionState(false, "")
+
+	expected := []saxo.EventKind{saxo.ConnectionEvent, saxo.OrderEvent, saxo.PortfolioEvent, saxo.SessionEvent, saxo.ConnectionEvent}
+	for i, kind := range expected {
+		event := <-events
+		if event.Kind != kind || event.Seq != uint64(i+1) {
+			t.Fatalf("Event %d: expected %s with seq %d, got %s with seq %d", i, kind, i+1, event.Kind, event.Seq)
+		}
+		switch event.Kind {
+		case saxo.ConnectionEvent:
+			if event.Connection == nil || event.Connection.Connected != (i == 0) {
+				t.Errorf("Unexpected connection event %+v", event.Connection)
+			}
+		case saxo.OrderEvent:
+			if event.Order == nil || event.Order.OrderId != "order_1" {
+				t.Errorf("Unexpected order event %+v", event.Order)
+			}
+		case saxo.PortfolioEvent:
+			if event.Portfolio == nil || event.Portfolio.MarginUtilizationPct != 12.5 {
+				t.Errorf("Unexpected portfolio event %+v", event.Portfolio)
+			}
+		case saxo.SessionEvent:
+			if event.Session == nil || event.Session.TradeLevel != "OrderOnly" {
+				t.Errorf("Unexpected session event %+v", event.Session)
+			}
+		}
+	}
+
+	// The per-kind channels are bypassed while the unified stream is enabled
+	if len(client.GetOrderUpdateChannel()) != 0 || len(client.GetPortfolioUpdateChannel()) != 0 || len(client.GetSessionEventChannel()) != 0 {
+		t.Error("Per-kind channels should stay empty with Events enabled")
+	}
+
+	client.closeUpdateChannels()
+	if _, ok := <-events; ok {
+		t.Error("Events channel should be closed with the update channels")
+	}
+}
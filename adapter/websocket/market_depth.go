@@ -0,0 +1,274 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// streamingDepthQuote is the Quote field group of /trade/v1/prices
+// Pointers distinguish "not in this delta" from zero
+type streamingDepthQuote struct {
+	Ask     *float64 `json:"Ask"`
+	Bid     *float64 `json:"Bid"`
+	AskSize *float64 `json:"AskSize"`
+	BidSize *float64 `json:"BidSize"`
+}
+
+// streamingMarketDepth is the MarketDepth field group of /trade/v1/prices
+// Saxo sends changed arrays whole, so a non-nil slice replaces the previous ladder side
+type streamingMarketDepth struct {
+	Ask        []float64 `json:"Ask"`
+	AskOrders  []int     `json:"AskOrders"`
+	AskSize    []float64 `json:"AskSize"`
+	Bid        []float64 `json:"Bid"`
+	BidOrders  []int     `json:"BidOrders"`
+	BidSize    []float64 `json:"BidSize"`
+	NoOfBids   *int      `json:"NoOfBids"`
+	NoOfOffers *int      `json:"NoOfOffers"`
+}
+
+// StreamingDepthUpdate is one /trade/v1/prices message (snapshot or delta)
+type StreamingDepthUpdate struct {
+	Uic         int                   `json:"Uic"`
+	AssetType   string                `json:"AssetType"`
+	Quote       *streamingDepthQuote  `json:"Quote"`
+	MarketDepth *streamingMarketDepth `json:"MarketDepth"`
+}
+
+// depthSubscriptionResponse is the POST /trade/v1/prices/subscriptions response
+type depthSubscriptionResponse struct {
+	Snapshot StreamingDepthUpdate `json:"Snapshot"`
+}
+
+// depthBook is the merged state for one UIC
+type depthBook struct {
+	assetType  string
+	quote      streamingDepthQuote
+	depth      streamingMarketDepth
+	noOfBids   int
+	noOfOffers int
+}
+
+// depthBooks merges deltas per UIC
+type depthBooks struct {
+	mu    sync.Mutex
+	books map[int]*depthBook
+}
+
+func newDepthBooks() *depthBooks {
+	return &depthBooks{books: make(map[int]*depthBook)}
+}
+
+// apply merges a snapshot or delta and returns the resulting full book
+// Callers fill Uic/AssetType from the subscription when the delta omits them
+func (db *depthBooks) apply(update StreamingDepthUpdate) saxo.DepthUpdate {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	book, ok := db.books[update.Uic]
+	if !ok {
+		book = &depthBook{}
+		db.books[update.Uic] = book
+	}
+	if update.AssetType != "" {
+		book.assetType = update.AssetType
+	}
+
+	if q := update.Quote; q != nil {
+		if q.Ask != nil {
+			book.quote.Ask = q.Ask
+		}
+		if q.Bid != nil {
+			book.quote.Bid = q.Bid
+		}
+		if q.AskSize != nil {
+			book.quote.AskSize = q.AskSize
+		}
+		if q.BidSize != nil {
+			book.quote.BidSize = q.BidSize
+		}
+	}
+
+	if d := update.MarketDepth; d != nil {
+		if d.Ask != nil {
+			book.depth.Ask = d.Ask
+		}
+		if d.AskOrders != nil {
+			book.depth.AskOrders = d.AskOrders
+		}
+		if d.AskSize != nil {
+			book.depth.AskSize = d.AskSize
+		}
+		if d.Bid != nil {
+			book.depth.Bid = d.Bid
+		}
+		if d.BidOrders != nil {
+			book.depth.BidOrders = d.BidOrders
+		}
+		if d.BidSize != nil {
+			book.depth.BidSize = d.BidSize
+		}
+		if d.NoOfBids != nil {
+			book.noOfBids = *d.NoOfBids
+		}
+		if d.NoOfOffers != nil {
+			book.noOfOffers = *d.NoOfOffers
+		}
+	}
+
+	result := saxo.DepthUpdate{
+		Uic:       update.Uic,
+		AssetType: book.assetType,
+		Bids:      buildDepthLevels(book.depth.Bid, book.depth.BidSize, book.depth.BidOrders, book.noOfBids),
+		Asks:      buildDepthLevels(book.depth.Ask, book.depth.AskSize, book.depth.AskOrders, book.noOfOffers),
+		Timestamp: time.Now(),
+	}
+	if book.quote.Bid != nil {
+		result.Bid = *book.quote.Bid
+	}
+	if book.quote.Ask != nil {
+		result.Ask = *book.quote.Ask
+	}
+	if book.quote.BidSize != nil {
+		result.BidSize = *book.quote.BidSize
+	}
+	if book.quote.AskSize != nil {
+		result.AskSize = *book.quote.AskSize
+	}
+
+	// Instruments without depth still report top of book - present it as a one-level ladder
+	if len(result.Bids) == 0 && result.Bid != 0 {
+		result.Bids = []saxo.DepthLevel{{Price: result.Bid, Size: result.BidSize}}
+	}
+	if len(result.Asks) == 0 && result.Ask != 0 {
+		result.Asks = []saxo.DepthLevel{{Price: result.Ask, Size: result.AskSize}}
+	}
+	return result
+}
+
+// buildDepthLevels zips the parallel arrays; count (NoOfBids/NoOfOffers) trims stale tail levels
+func buildDepthLevels(prices, sizes []float64, orders []int, count int) []saxo.DepthLevel {
+	n := len(prices)
+	if count > 0 && count < n {
+		n = count
+	}
+	levels := make([]saxo.DepthLevel, 0, n)
+	for i := 0; i < n; i++ {
+		level := saxo.DepthLevel{Price: prices[i]}
+		if i < len(sizes) {
+			level.Size = sizes[i]
+		}
+		if i < len(orders) {
+			level.Orders = orders[i]
+		}
+		levels = append(levels, level)
+	}
+	return levels
+}
+
+// SubscribeToDepth streams the order book for one instrument via /trade/v1/prices with the
+// MarketDepth field group. The subscription snapshot is pushed as the first DepthUpdate
+// Depth is only available where Saxo offers it (e.g. futures, FX with Level 2 data) -
+// otherwise updates carry the top of book only
+func (ws *SaxoWebSocketClient) SubscribeToDepth(ctx context.Context, uic int, assetType string) error {
+	if ws.isShutdown() {
+		return errClientShutdown
+	}
+
+	if ws.deferUntilConnected(fmt.Sprintf("depth:%s:%d", assetType, uic), func(ctx context.Context) error {
+		return ws.SubscribeToDepth(ctx, uic, assetType)
+	}) {
+		return nil
+	}
+
+	body, err := ws.subscriptionManager.SubscribeToMarketDepthContext(ctx, uic, assetType)
+	if err != nil {
+		ws.logger.Error("Market depth subscription failed",
+			"function", "SubscribeToDepth",
+			"uic", uic,
+			"asset_type", assetType,
+			"error", err)
+		return err
+	}
+
+	if len(body) > 0 {
+		var response depthSubscriptionResponse
+		if err := json.Unmarshal(body, &response); err != nil {
+			ws.logger.Warn("Failed to parse market depth snapshot",
+				"function", "SubscribeToDepth",
+				"uic", uic,
+				"error", err)
+		} else {
+			if response.Snapshot.Uic == 0 {
+				response.Snapshot.Uic = uic
+			}
+			if response.Snapshot.AssetType == "" {
+				response.Snapshot.AssetType = assetType
+			}
+			ws.publishDepth(ws.depthBooks.apply(response.Snapshot))
+		}
+	}
+
+	ws.logger.Info("Market depth subscription successful",
+		"function", "SubscribeToDepth",
+		"uic", uic,
+		"asset_type", assetType)
+	return nil
+}
+
+// GetDepthUpdateChannel returns full order books after every snapshot/delta
+func (ws *SaxoWebSocketClient) GetDepthUpdateChannel() <-chan saxo.DepthUpdate {
+	return ws.depthUpdateChan
+}
+
+// publishDepth sends without blocking - depth is superseded by the next update anyway
+func (ws *SaxoWebSocketClient) publishDepth(update saxo.DepthUpdate) {
+	select {
+	case ws.depthUpdateChan <- update:
+	default:
+		ws.logger.Warn("Depth update channel full, dropping update",
+			"function", "publishDepth",
+			"uic", update.Uic)
+	}
+}
+
+// handleDepthUpdate processes /trade/v1/prices messages (single object, or array when batched)
+// Deltas usually omit the static Uic/AssetType - they are taken from the subscription arguments
+func (mh *MessageHandler) handleDepthUpdate(referenceID string, payload []byte) error {
+	var updates []StreamingDepthUpdate
+	if trimmed := bytes.TrimSpace(payload); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &updates); err != nil {
+			return fmt.Errorf("failed to unmarshal depth updates: %w", err)
+		}
+	} else {
+		var update StreamingDepthUpdate
+		if err := json.Unmarshal(trimmed, &update); err != nil {
+			return fmt.Errorf("failed to unmarshal depth update: %w", err)
+		}
+		updates = append(updates, update)
+	}
+
+	uic, assetType := mh.client.subscriptionManager.depthInstrument(referenceID)
+	for _, update := range updates {
+		if update.Uic == 0 {
+			update.Uic = uic
+		}
+		if update.AssetType == "" {
+			update.AssetType = assetType
+		}
+		if update.Uic == 0 {
+			mh.client.logger.Warn("Depth update for unknown subscription, skipping",
+				"function", "handleDepthUpdate",
+				"reference_id", referenceID)
+			continue
+		}
+		mh.client.publishDepth(mh.client.depthBooks.apply(update))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestSaxoWebSocketClient_MarketDepth(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SubscribeToDepth(ctx, 21, "ContractFutures"); err != nil {
+		t.Fatalf("SubscribeToDepth failed: %v", err)
+	}
+
+	// Snapshot is pushed first
+	snapshot := receiveDepth(t, client)
+	if snapshot.Uic != 21 || snapshot.AssetType != "ContractFutures" {
+		t.Errorf("Unexpected snapshot instrument: %+v", snapshot)
+	}
+	if len(snapshot.Bids) != 2 || len(snapshot.Asks) != 2 {
+		t.Fatalf("Expected 2x2 ladder, got %d bids, %d asks", len(snapshot.Bids), len(snapshot.Asks))
+	}
+	if snapshot.Bids[0] != (saxo.DepthLevel{Price: 1.1000, Size: 1000000, Orders: 3}) {
+		t.Errorf("Unexpected best bid level: %+v", snapshot.Bids[0])
+	}
+	if snapshot.AskSize != 500000 {
+		t.Errorf("Expected top-of-book ask size 500000, got %v", snapshot.AskSize)
+	}
+
+	// Delta only replaces the bid side and shrinks it to one level; asks are kept
+	delta := map[string]interface{}{
+		"MarketDepth": map[string]interface{}{
+			"Bid":      []float64{1.1001},
+			"BidSize":  []float64{750000},
+			"NoOfBids": 1,
+		},
+	}
+	if err := mockServer.SendDepthDelta(21, delta); err != nil {
+		t.Fatalf("SendDepthDelta failed: %v", err)
+	}
+
+	update := receiveDepth(t, client)
+	if update.Uic != 21 {
+		t.Errorf("Expected delta mapped to UIC 21 from subscription, got %d", update.Uic)
+	}
+	if len(update.Bids) != 1 || update.Bids[0].Price != 1.1001 || update.Bids[0].Size != 750000 {
+		t.Errorf("Expected merged bid side, got %+v", update.Bids)
+	}
+	if len(update.Asks) != 2 || update.Asks[1].Price != 1.1003 {
+		t.Errorf("Expected ask side unchanged, got %+v", update.Asks)
+	}
+}
+
+func receiveDepth(t *testing.T, client *SaxoWebSocketClient) saxo.DepthUpdate {
+	t.Helper()
+	select {
+	case update := <-client.GetDepthUpdateChannel():
+		return update
+	case <-time.After(3 * time.Second):
+		t.Fatal("Timed out waiting for depth update")
+		return saxo.DepthUpdate{}
+	}
+}
@@ -2,9 +2,9 @@ package websocket
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
-	"strings"
+	"sync"
 	"time"
 
 	saxo "github.com/bjoelf/saxo-adapter/adapter"
@@ -14,20 +14,39 @@ import (
 // Handles price updates, order status changes, and portfolio updates for strategy_manager coordination
 type MessageHandler struct {
 	client *SaxoWebSocketClient
+
+	// Last known balance per subscription (ReferenceId) - Saxo streams balance deltas, see mergeBalance
+	balances  map[string]saxo.PortfolioUpdate
+	balanceMu sync.Mutex
+
+	// Context of open orders by OrderId - Saxo streams order deltas, see mergeOrder
+	orders   map[string]saxo.OrderUpdate
+	ordersMu sync.Mutex
+
+	// Reference IDs seen so far, so parsing does not allocate one string per message
+	referenceIDs referenceIDCache
 }
 
 // NewMessageHandler creates message handler following legacy message processing patterns
 func NewMessageHandler(client *SaxoWebSocketClient) *MessageHandler {
 	return &MessageHandler{
-		client: client,
+		client:   client,
+		balances: make(map[string]saxo.PortfolioUpdate),
+		orders:   make(map[string]saxo.OrderUpdate),
 	}
 }
 
 // StreamingPriceUpdate matches legacy streaming_prices.go format
+// The optional field groups are only sent when requested (SetPriceSubscriptionOptions)
 type StreamingPriceUpdate struct {
 	LastUpdated string     `json:"LastUpdated"`
 	Quote       PriceQuote `json:"Quote"`
 	Uic         int        `json:"Uic"`
+
+	InstrumentPriceDetails *StreamingInstrumentPriceDetails `json:"InstrumentPriceDetails,omitempty"`
+	PriceInfo              *StreamingPriceInfo              `json:"PriceInfo,omitempty"`
+	PriceInfoDetails       *StreamingPriceInfoDetails       `json:"PriceInfoDetails,omitempty"`
+	Commissions            *StreamingCommissions            `json:"Commissions,omitempty"`
 }
 
 // PriceQuote matches legacy priceQuote format
@@ -40,23 +59,56 @@ type PriceQuote struct {
 }
 
 // ProcessMessage routes incoming WebSocket messages following legacy patterns
-// Uses binary protocol parser for Saxo WebSocket message format
+// Uses binary protocol parser for Saxo WebSocket message format. A frame may carry several
+// messages; each is routed in order, and a failing one does not stop the rest
 func (mh *MessageHandler) ProcessMessage(message []byte) error {
-	// Parse binary Saxo WebSocket message
-	parsed, err := parseMessage(message)
-	if err != nil {
-		return fmt.Errorf("failed to parse WebSocket message: %w", err)
+	return mh.processFrame(message, mh.client.clock.Now(), nil)
+}
+
+// processFrame parses and routes the messages of one frame read at receivedAt
+// With a pooled frame and SetMessageWorkers, data messages go to the worker of their ReferenceId
+// and keep a reference to frame; control messages are always handled here, in frame order
+func (mh *MessageHandler) processFrame(message []byte, receivedAt time.Time, frame *frameBuffer) error {
+	// Parse binary Saxo WebSocket frame into a pooled slice
+	parsed := acquireParsed()
+	defer releaseParsed(parsed)
+	messages, parseErr := parseMessages(message, *parsed, &mh.referenceIDs)
+	*parsed = messages
+	if parseErr != nil {
+		parseErr = fmt.Errorf("failed to parse WebSocket message: %w", parseErr)
+	}
+
+	var errs []error
+	for i := range messages {
+		if err := mh.routeMessage(&messages[i], receivedAt, frame); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", messages[i].ReferenceID, err))
+		}
+	}
+	if parseErr == nil && errs == nil {
+		return nil
 	}
+	return errors.Join(append(errs, parseErr)...)
+}
 
+// routeMessage records the sequence number of one parsed message and routes it
+func (mh *MessageHandler) routeMessage(parsed *ParsedMessage, receivedAt time.Time, frame *frameBuffer) error {
 	// Update sequence number for reconnection
-	mh.client.lastSequenceNumber = parsed.MessageID
+	mh.client.lastSequenceNumber.Store(parsed.MessageID)
 
 	// Route based on message type (control vs data)
 	if parsed.IsControlMessage() {
 		return mh.handleControlMessage(parsed)
 	}
 
-	return mh.handleDataMessage(parsed)
+	// Worker pool (SetMessageWorkers) - only for pooled frames, whose lifetime the workers extend
+	// A pool stopped or replaced mid-dispatch hands the message back - handled inline, never dropped
+	if workers := mh.client.messageWorkers.Load(); workers != nil && frame != nil {
+		if workers.dispatch(*parsed, receivedAt, frame) {
+			return nil
+		}
+	}
+
+	return mh.handleDataMessage(parsed, receivedAt)
 }
 
 // handleControlMessage processes control messages (_heartbeat, _disconnect, _resetsubscriptions)
@@ -66,7 +118,7 @@ func (mh *MessageHandler) handleControlMessage(parsed *ParsedMessage) error {
 	case "_heartbeat":
 		return handleHeartbeat(parsed.Payload, mh.client)
 	case "_disconnect":
-		return handleDisconnect(mh.client)
+		return handleDisconnect(parsed.Payload, mh.client)
 	case "_resetsubscriptions":
 		return handleResetSubscriptions(parsed.Payload, mh.client)
 	default:
@@ -78,78 +130,136 @@ func (mh *MessageHandler) handleControlMessage(parsed *ParsedMessage) error {
 }
 
 // handleDataMessage routes data messages by reference ID following legacy subscription patterns
-func (mh *MessageHandler) handleDataMessage(parsed *ParsedMessage) error {
-	//mh.client.logger.Printf("🔄 Data message: messageId=%d, referenceId=%s", parsed.MessageID, parsed.ReferenceID)
-
-	// Route based on reference ID prefix (human-readable IDs like "prices-20251119-132309")
-	// Match by subscription type prefix to handle dynamic timestamp suffixes
-	var err error
-	subscriptionFound := false
-
-	if strings.Contains(parsed.ReferenceID, PricesSubscriptionKey) {
-		//mh.client.logger.Printf("Routing to price update handler")
-		err = mh.handlePriceUpdate(parsed.Payload)
-		subscriptionFound = true
-	} else if strings.Contains(parsed.ReferenceID, OrderUpdatesSubscriptionKey) {
-		//mh.client.logger.Printf("Routing to order update handler")
-		err = mh.handleOrderUpdate(parsed.Payload)
-		subscriptionFound = true
-	} else if strings.Contains(parsed.ReferenceID, PortfolioBalanceSubscriptionKey) {
-		//mh.client.logger.Printf("Routing to portfolio update handler")
-		err = mh.handlePortfolioUpdate(parsed.Payload)
-		subscriptionFound = true
-	} else if strings.Contains(parsed.ReferenceID, SessionEventsSubscriptionKey) {
-		//mh.client.logger.Printf("Routing to session update handler")
-		mh.client.handleSessionEvent(parsed.Payload)
-		subscriptionFound = true
-	} else {
+// The handler comes from the SubscriptionManager registry - every subscription (built-in or
+// RegisterSubscription) records its handler under the exact ReferenceId it was created with
+func (mh *MessageHandler) handleDataMessage(parsed *ParsedMessage, receivedAt time.Time) error {
+	handler, timed := mh.client.subscriptionManager.routeFor(parsed.ReferenceID)
+	if handler == nil && timed == nil {
 		mh.client.logger.Warn("Unknown data message reference",
 			"function", "handleDataMessage",
 			"reference_id", parsed.ReferenceID)
+		return nil
+	}
+
+	var err error
+	if timed != nil {
+		err = timed(parsed.ReferenceID, parsed.Payload, receivedAt)
+	} else {
+		err = handler(parsed.ReferenceID, parsed.Payload)
 	}
 
 	// Update timestamp for successfully routed data messages
 	// CRITICAL FIX: This prevents false "Partial timeout detected" warnings for active subscriptions
 	// Active subscriptions (e.g., prices during market hours) send data messages instead of
 	// "NoNewData" heartbeats, so we must update timestamps here to reflect subscription health
-	if subscriptionFound {
-		mh.client.lastMessageTimestampsMu.Lock()
-		mh.client.lastMessageTimestamps[parsed.ReferenceID] = time.Now()
-		mh.client.lastMessageTimestampsMu.Unlock()
-	}
+	mh.client.lastMessageTimestampsMu.Lock()
+	now := mh.client.clock.Now()
+	mh.client.lastMessageTimestamps[parsed.ReferenceID] = now
+	mh.client.lastMessageTimestampsMu.Unlock()
+	mh.client.subscriptionHealth.recordData(parsed.ReferenceID, now)
 
 	return err
 }
 
+// DataHandler adapters for the built-in subscriptions (registered by SubscriptionManager)
+
+func (mh *MessageHandler) routePriceUpdate(referenceID string, payload []byte) error {
+	return mh.handlePriceUpdateAt(payload, mh.client.subscriptionManager.priceAssetType(referenceID), mh.client.clock.Now())
+}
+
+func (mh *MessageHandler) routeTimedPriceUpdate(referenceID string, payload []byte, receivedAt time.Time) error {
+	return mh.handlePriceUpdateAt(payload, mh.client.subscriptionManager.priceAssetType(referenceID), receivedAt)
+}
+
+func (mh *MessageHandler) routeOrderUpdate(referenceID string, payload []byte) error {
+	return mh.handleOrderUpdate(payload)
+}
+
+func (mh *MessageHandler) routePortfolioUpdate(referenceID string, payload []byte) error {
+	return mh.handlePortfolioUpdate(referenceID, payload)
+}
+
+func (mh *MessageHandler) routeSessionEvent(referenceID string, payload []byte) error {
+	mh.client.handleSessionEvent(payload)
+	return nil
+}
+
 // handlePriceUpdate processes price feed messages following legacy price coordination patterns
 // CRITICAL: Saxo sends price updates as JSON array directly, not wrapped in object
 // Legacy pattern: json.Unmarshal(incoming, &priceUpdates) where priceUpdates is []StreamingPriceUpdate
 func (mh *MessageHandler) handlePriceUpdate(payload []byte) error {
+	return mh.handlePriceUpdateAt(payload, "", mh.client.clock.Now())
+}
+
+// handlePriceUpdateAt is handlePriceUpdate for a message of an assetType subscription read at
+// receivedAt (PriceUpdate.RecvTime)
+func (mh *MessageHandler) handlePriceUpdateAt(payload []byte, assetType string, receivedAt time.Time) error {
 	// Parse as array of price updates following legacy streaming_prices.go pattern
-	var priceUpdates []StreamingPriceUpdate
-	if err := json.Unmarshal(payload, &priceUpdates); err != nil {
+	// The decode target is pooled - publishPrices copies what it keeps
+	batch := acquirePriceBatch()
+	defer releasePriceBatch(batch)
+	if err := json.Unmarshal(payload, batch); err != nil {
 		return fmt.Errorf("failed to unmarshal price updates: %w", err)
 	}
 
-	if len(priceUpdates) == 0 {
+	if len(*batch) == 0 {
 		return fmt.Errorf("empty price update array")
 	}
 
+	mh.publishPrices(*batch, assetType, false, receivedAt)
+	return nil
+}
+
+// publishPriceSnapshot injects the Snapshot of a POST /trade/v1/infoprices/subscriptions response
+// so consumers get current quotes immediately instead of waiting for the first delta
+func (mh *MessageHandler) publishPriceSnapshot(body []byte, assetType string) {
+	if len(body) == 0 {
+		return
+	}
+	var response struct {
+		Snapshot struct {
+			Data []priceDelta `json:"Data"`
+		} `json:"Snapshot"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		mh.client.logger.Warn("Failed to parse price subscription snapshot",
+			"function", "publishPriceSnapshot",
+			"error", err)
+		return
+	}
+	mh.publishPrices(response.Snapshot.Data, assetType, true, mh.client.clock.Now())
+}
+
+// publishPrices delivers parsed quotes to Subscribe handles and the shared price channel
+// receivedAt is the message read time - RecvTime of every update in it; assetType is the subscription's
+func (mh *MessageHandler) publishPrices(priceUpdates []priceDelta, assetType string, snapshot bool, receivedAt time.Time) {
 	//mh.client.logger.Printf("🔍 PARSED: Received %d price updates", len(priceUpdates))
 
 	// Process each price update in the array
-	for _, priceData := range priceUpdates {
+	now := mh.client.clock.Now()
+	for i := range priceUpdates {
+		priceData := &priceUpdates[i]
+		// Any message for the UIC proves its feed alive (see StaleInstruments), even an all-zero one
+		mh.client.instrumentActivity.record(priceData.Uic, assetType, now)
+
 		// DEBUG: Log structured data from Saxo
-		//mh.client.logger.Printf("🔍 UPDATE[%d]: UIC=%d, Bid=%.5f, Ask=%.5f, Mid=%.5f, LastUpdated=%s", i, priceData.Uic, priceData.Quote.Bid, priceData.Quote.Ask, priceData.Quote.Mid, priceData.LastUpdated)
+		//mh.client.logger.Printf("🔍 UPDATE[%d]: UIC=%d, Bid=%.5f, Ask=%.5f, Mid=%.5f, LastUpdated=%v", i, priceData.Uic, priceData.Quote.Bid, priceData.Quote.Ask, priceData.Quote.Mid, priceData.LastUpdated)
 
 		// Create PriceUpdate directly from Saxo data - no conversion needed!
 		// Use Saxo's native UIC for signal matching
+		// UICs with extra field groups are merged across deltas (see priceDetailsState)
+		quote, details := mh.client.priceDetails.apply(priceData.StreamingPriceUpdate)
+		exchangeTime := time.Time(priceData.LastUpdated)
 		priceUpdate := saxo.PriceUpdate{
-			Uic:       priceData.Uic,
-			Bid:       priceData.Quote.Bid,
-			Ask:       priceData.Quote.Ask,
-			Mid:       priceData.Quote.Mid,
-			Timestamp: time.Now(),
+			Uic:          priceData.Uic,
+			Bid:          quote.Bid,
+			Ask:          quote.Ask,
+			Mid:          quote.Mid,
+			Timestamp:    mh.client.priceTimestamps.stamp(exchangeTime, receivedAt, snapshot),
+			ExchangeTime: exchangeTime,
+			RecvTime:     receivedAt,
+			Snapshot:     snapshot,
+			Details:      details,
 		}
 
 		//mh.client.logger.Printf("🔍 CREATED: UIC=%d, bid=%.5f, ask=%.5f, mid=%.5f",	priceUpdate.Uic, priceUpdate.Bid, priceUpdate.Ask, priceUpdate.Mid)
@@ -160,19 +270,45 @@ func (mh *MessageHandler) handlePriceUpdate(payload []byte) error {
 			//mh.client.logger.Printf("Skipping all-zero price update for UIC %d", priceUpdate.Uic)
 			continue
 		}
+		mh.client.decoratePriceUpdate(&priceUpdate)
+		if formatter := mh.client.priceFormatter(priceUpdate.Uic); formatter != nil {
+			priceUpdate = formatter.FormatPriceUpdate(priceUpdate)
+		}
 
-		// Send to strategy_manager via channel following legacy coordination patterns
-		select {
-		case mh.client.priceUpdateChan <- priceUpdate:
-			//mh.client.logger.Printf("🔍 SENT TO CHANNEL: UIC=%d", priceUpdate.Uic)
-		default:
-			mh.client.logger.Warn("Price update channel full, dropping update",
-				"function", "handlePriceUpdate",
-				"uic", priceUpdate.Uic)
+		// Per-consumer handles (Subscribe) get their own copy
+		mh.client.priceRouter.deliver(priceUpdate)
+		if !mh.client.priceRouter.wantsShared(priceUpdate.Uic) {
+			continue
 		}
+
+		// Throttled shared stream (SetPriceThrottle) - held-back updates are published by the sampler
+		if sampler := mh.client.priceSampler.Load(); sampler != nil && !sampler.offer(priceUpdate) {
+			continue
+		}
+		mh.publishShared(priceUpdate)
 	}
+}
 
-	return nil
+// publishShared delivers a price update to the unified stream, or else the shared price channel
+// Called by publishPrices and by the SetPriceThrottle sampler
+func (mh *MessageHandler) publishShared(priceUpdate saxo.PriceUpdate) {
+	// Unified stream (Events) replaces the price channel when enabled
+	// The event gets its own copy, so priceUpdate stays off the heap without Events
+	if mh.client.eventsEnabled() {
+		event := priceUpdate
+		if mh.client.emitEvent(saxo.StreamEvent{Kind: saxo.PriceEvent, Price: &event}) {
+			return
+		}
+	}
+
+	// Send to strategy_manager via channel following legacy coordination patterns
+	// Full-channel behavior is decided by the backpressure policy (SetPriceBackpressure)
+	if !mh.client.priceDispatcher.Dispatch(priceUpdate) {
+		mh.client.logger.Warn("Price update channel full, dropping update",
+			"function", "publishPrices",
+			"uic", priceUpdate.Uic,
+			"dropped_total", mh.client.priceDispatcher.dropped.Load())
+	}
 }
 
 // handleOrderUpdate processes order status messages following legacy order coordination patterns
@@ -181,8 +317,8 @@ func (mh *MessageHandler) handlePriceUpdate(payload []byte) error {
 // Following same pattern as handlePriceUpdate which correctly uses array
 func (mh *MessageHandler) handleOrderUpdate(payload []byte) error {
 	// Parse JSON payload AS ARRAY (matching legacy pattern)
-	var orderDataArray []map[string]interface{}
-	if err := json.Unmarshal(payload, &orderDataArray); err != nil {
+	var orders []StreamingOrder
+	if err := json.Unmarshal(payload, &orders); err != nil {
 		return fmt.Errorf("failed to unmarshal order data: %w", err)
 	}
 
@@ -190,8 +326,8 @@ func (mh *MessageHandler) handleOrderUpdate(payload []byte) error {
 	// Legacy: strategy_manager/streaming_orders.go:86-88
 	// if hasStatusUpdates(streamingOrders) { log.Printf("UpdateOrderStatus: Incoming payload: %s", string(incoming)) }
 	// CRITICAL: Also log __meta_deleted messages (they often have NO Status field)
-	hasStatus := hasStatusUpdates(orderDataArray)
-	hasDeleted := hasMetaDeletedUpdates(orderDataArray)
+	hasStatus := hasStatusUpdates(orders)
+	hasDeleted := hasMetaDeletedUpdates(orders)
 
 	if hasStatus || hasDeleted {
 		logLevel := "INFO"
@@ -208,9 +344,9 @@ func (mh *MessageHandler) handleOrderUpdate(payload []byte) error {
 	}
 
 	// Process each order update in the array
-	for _, orderData := range orderDataArray {
+	for _, order := range orders {
 		// Convert to OrderUpdate
-		orderUpdate, err := mh.parseOrderData(orderData)
+		orderUpdate, err := toOrderUpdate(order)
 		if err != nil {
 			// Log error but continue with other orders
 			mh.client.logger.Warn("Failed to parse order data, skipping",
@@ -218,6 +354,11 @@ func (mh *MessageHandler) handleOrderUpdate(payload []byte) error {
 				"error", err)
 			continue
 		}
+		mh.mergeOrder(orderUpdate)
+
+		if mh.client.emitEvent(saxo.StreamEvent{Kind: saxo.OrderEvent, Order: orderUpdate}) {
+			continue
+		}
 
 		// Send to channel (non-blocking)
 		select {
@@ -252,9 +393,9 @@ func (mh *MessageHandler) handleOrderUpdate(payload []byte) error {
 
 // hasStatusUpdates checks if any order in the array has a Status field
 // Following legacy pivot-web/strategy_manager/streaming_orders.go:107-113 pattern
-func hasStatusUpdates(orderDataArray []map[string]interface{}) bool {
-	for _, orderData := range orderDataArray {
-		if status, exists := orderData["Status"]; exists && status != nil {
+func hasStatusUpdates(orders []StreamingOrder) bool {
+	for _, order := range orders {
+		if order.Status != nil {
 			return true
 		}
 	}
@@ -265,152 +406,130 @@ func hasStatusUpdates(orderDataArray []map[string]interface{}) bool {
 // CRITICAL: Fill messages often have ONLY __meta_deleted with NO Status field
 // Example: {"OrderId": "5269510038", "__meta_deleted": true}
 // Also checks RelatedOpenOrders for nested __meta_deleted (exit orders referencing filled entry)
-func hasMetaDeletedUpdates(orderDataArray []map[string]interface{}) bool {
-	for _, orderData := range orderDataArray {
-		// Check top-level __meta_deleted
-		if metaDeleted, exists := orderData["__meta_deleted"].(bool); exists && metaDeleted {
+func hasMetaDeletedUpdates(orders []StreamingOrder) bool {
+	for _, order := range orders {
+		if isTrue(order.MetaDeleted) {
 			return true
 		}
-
-		// Check RelatedOpenOrders for __meta_deleted
-		if relatedOrdersRaw, exists := orderData["RelatedOpenOrders"]; exists {
-			if relatedOrdersArray, ok := relatedOrdersRaw.([]interface{}); ok {
-				for _, relatedRaw := range relatedOrdersArray {
-					if relatedMap, ok := relatedRaw.(map[string]interface{}); ok {
-						if metaDeleted, exists := relatedMap["__meta_deleted"].(bool); exists && metaDeleted {
-							return true
-						}
-					}
-				}
+		for _, related := range order.RelatedOpenOrders {
+			if isTrue(related.MetaDeleted) {
+				return true
 			}
 		}
 	}
 	return false
 }
 
-// parseOrderData extracts order information from Saxo streaming format
+// toOrderUpdate converts a streaming order into the broker-agnostic OrderUpdate
 // Handles both Phase 1 (entry with RelatedOpenOrders) and Phase 2 (flat structure)
-// Following legacy pivot-web/strategy_manager/streaming_orders.go:13-75 StreamingOrders struct
-func (mh *MessageHandler) parseOrderData(orderData map[string]interface{}) (*saxo.OrderUpdate, error) {
-	// Extract order ID (required)
-	orderIdRaw, exists := orderData["OrderId"]
-	if !exists {
+// Fields missing from a delta stay zero/nil in the OrderUpdate
+func toOrderUpdate(order StreamingOrder) (*saxo.OrderUpdate, error) {
+	if order.OrderID == "" {
 		return nil, fmt.Errorf("missing OrderId in order data")
 	}
 
-	orderId := fmt.Sprintf("%v", orderIdRaw)
-
 	orderUpdate := &saxo.OrderUpdate{
-		OrderId:   orderId,
-		UpdatedAt: time.Now(),
+		OrderId:       order.OrderID,
+		Status:        deref(order.Status),
+		FilledSize:    deref(order.FilledAmount),
+		OpenOrderType: deref(order.OpenOrderType),
+		OrderPrice:    deref(order.Price),
+		Uic:           order.Uic,
+		BuySell:       deref(order.BuySell),
+		OrderRelation: deref(order.OrderRelation),
+		MetaDeleted:   order.MetaDeleted,
+		AssetType:     deref(order.AssetType),
+		AccountKey:    deref(order.AccountKey),
+		UpdatedAt:     time.Now(),
+	}
+	if order.DisplayAndFormat != nil {
+		orderUpdate.Symbol = order.DisplayAndFormat.Symbol
+		orderUpdate.Description = order.DisplayAndFormat.Description
+	}
+	if order.Amount != nil {
+		amount := int(*order.Amount)
+		orderUpdate.Amount = &amount
+	}
+
+	for _, related := range order.RelatedOpenOrders {
+		orderUpdate.RelatedOpenOrders = append(orderUpdate.RelatedOpenOrders, saxo.RelatedOrder{
+			OrderID:       related.OrderID,
+			OpenOrderType: deref(related.OpenOrderType),
+			OrderPrice:    deref(related.OrderPrice),
+			Amount:        deref(related.Amount),
+			Status:        deref(related.Status),
+			MetaDeleted:   related.MetaDeleted,
+		})
 	}
 
-	// Extract order status (may be missing in some updates)
-	if status, exists := orderData["Status"].(string); exists {
-		orderUpdate.Status = status
-	}
+	return orderUpdate, nil
+}
 
-	// Extract filled size if available
-	if filled, exists := orderData["FilledAmount"]; exists {
-		if filledFloat, err := mh.convertToFloat64(filled); err == nil {
-			orderUpdate.FilledSize = filledFloat
+// mergeOrder fills the instrument and account context left out of an order delta from earlier
+// messages of the same order, then resolves ticker and symbol of the UIC. Status, FilledSize and the deletion
+// marker always come from the delta itself. Terminal and deleted orders are forgotten
+func (mh *MessageHandler) mergeOrder(update *saxo.OrderUpdate) {
+	mh.ordersMu.Lock()
+	if last, ok := mh.orders[update.OrderId]; ok {
+		if update.Uic == nil {
+			update.Uic = last.Uic
 		}
-	}
-
-	// Extract OpenOrderType (Phase 1 & 2)
-	if openOrderType, exists := orderData["OpenOrderType"].(string); exists {
-		orderUpdate.OpenOrderType = openOrderType
-	}
-
-	// Extract OrderPrice (Price field in JSON)
-	if price, exists := orderData["Price"]; exists {
-		if priceFloat, err := mh.convertToFloat64(price); err == nil {
-			orderUpdate.OrderPrice = priceFloat
+		if update.Amount == nil {
+			update.Amount = last.Amount
 		}
+		fillZero(&update.OpenOrderType, last.OpenOrderType)
+		fillZero(&update.OrderPrice, last.OrderPrice)
+		fillZero(&update.BuySell, last.BuySell)
+		fillZero(&update.OrderRelation, last.OrderRelation)
+		fillZero(&update.AssetType, last.AssetType)
+		fillZero(&update.AccountKey, last.AccountKey)
+		fillZero(&update.Symbol, last.Symbol)
+		fillZero(&update.Description, last.Description)
+	}
+	if isTrue(update.MetaDeleted) || update.State().IsTerminal() {
+		delete(mh.orders, update.OrderId)
+	} else {
+		known := *update
+		known.RelatedOpenOrders = nil
+		mh.orders[update.OrderId] = known
 	}
+	mh.ordersMu.Unlock()
 
-	// Extract Uic
-	if uic, exists := orderData["Uic"]; exists {
-		if uicFloat, err := mh.convertToFloat64(uic); err == nil {
-			uicInt := int(uicFloat)
-			orderUpdate.Uic = &uicInt
-		}
+	if update.Uic == nil {
+		return
 	}
-
-	// Extract Amount
-	if amount, exists := orderData["Amount"]; exists {
-		if amountFloat, err := mh.convertToFloat64(amount); err == nil {
-			amountInt := int(amountFloat)
-			orderUpdate.Amount = &amountInt
+	if update.Ticker == "" && mh.client.instrumentStore != nil {
+		if meta, ok := mh.client.instrumentStore.Get(*update.Uic); ok {
+			update.Ticker = meta.Ticker
 		}
 	}
-
-	// Extract __meta_deleted (Phase 2: order cancellation/deletion)
-	if metaDeleted, exists := orderData["__meta_deleted"].(bool); exists {
-		orderUpdate.MetaDeleted = &metaDeleted
-	}
-
-	// Phase 1: Extract RelatedOpenOrders (entry order with nested exit orders)
-	if relatedOrdersRaw, exists := orderData["RelatedOpenOrders"]; exists {
-		if relatedOrdersArray, ok := relatedOrdersRaw.([]interface{}); ok {
-			for _, relatedRaw := range relatedOrdersArray {
-				if relatedMap, ok := relatedRaw.(map[string]interface{}); ok {
-					relatedOrder := saxo.RelatedOrder{}
-
-					// Extract related order fields
-					if relOrderId, exists := relatedMap["OrderId"]; exists {
-						relatedOrder.OrderID = fmt.Sprintf("%v", relOrderId)
-					}
-					if openOrderType, exists := relatedMap["OpenOrderType"].(string); exists {
-						relatedOrder.OpenOrderType = openOrderType
-					}
-					if orderPrice, exists := relatedMap["OrderPrice"]; exists {
-						if priceFloat, err := mh.convertToFloat64(orderPrice); err == nil {
-							relatedOrder.OrderPrice = priceFloat
-						}
-					}
-					if amount, exists := relatedMap["Amount"]; exists {
-						if amountFloat, err := mh.convertToFloat64(amount); err == nil {
-							relatedOrder.Amount = amountFloat
-						}
-					}
-					if status, exists := relatedMap["Status"].(string); exists {
-						relatedOrder.Status = status
-					}
-					if metaDeleted, exists := relatedMap["__meta_deleted"].(bool); exists {
-						relatedOrder.MetaDeleted = &metaDeleted
-					}
-
-					orderUpdate.RelatedOpenOrders = append(orderUpdate.RelatedOpenOrders, relatedOrder)
-				}
-			}
+	if update.Symbol == "" {
+		if meta, ok := mh.client.InstrumentInfo(*update.Uic); ok {
+			update.Symbol, update.Description = meta.Symbol, meta.Description
 		}
 	}
-
-	return orderUpdate, nil
 }
 
 // handlePortfolioUpdate processes portfolio balance messages following legacy portfolio coordination patterns
-func (mh *MessageHandler) handlePortfolioUpdate(payload []byte) error {
+func (mh *MessageHandler) handlePortfolioUpdate(referenceID string, payload []byte) error {
 	mh.client.logger.Debug("Portfolio update received",
 		"function", "handlePortfolioUpdate",
 		"payload_size", len(payload))
 
-	// Parse JSON payload
-	var portfolioData map[string]interface{}
-	if err := json.Unmarshal(payload, &portfolioData); err != nil {
+	var balance StreamingBalance
+	if err := json.Unmarshal(payload, &balance); err != nil {
 		return fmt.Errorf("failed to unmarshal portfolio data: %w", err)
 	}
 
-	// Convert to PortfolioUpdate
-	portfolioUpdate, err := mh.parsePortfolioData(portfolioData)
-	if err != nil {
-		return fmt.Errorf("failed to parse portfolio data: %w", err)
+	// Merge the delta into the subscription's last balance so unchanged fields keep their values
+	portfolioUpdate := mh.mergeBalance(referenceID, balance)
+	if mh.client.emitEvent(saxo.StreamEvent{Kind: saxo.PortfolioEvent, Portfolio: &portfolioUpdate}) {
+		return nil
 	}
 
 	// Send to channel (non-blocking)
 	select {
-	case mh.client.portfolioUpdateChan <- *portfolioUpdate:
+	case mh.client.portfolioUpdateChan <- portfolioUpdate:
 		mh.client.logger.Debug("Portfolio update sent",
 			"function", "handlePortfolioUpdate",
 			"balance", portfolioUpdate.Balance,
@@ -423,56 +542,55 @@ func (mh *MessageHandler) handlePortfolioUpdate(payload []byte) error {
 	return nil
 }
 
-// parsePortfolioData extracts balance information from Saxo streaming format
-func (mh *MessageHandler) parsePortfolioData(portfolioData map[string]interface{}) (*saxo.PortfolioUpdate, error) {
-	// Extract balance information following legacy balance patterns
-	balance, err := mh.extractFloat64(portfolioData, "TotalValue")
-	if err != nil {
-		balance = 0.0 // Default if not available
+// mergeBalance applies a balance snapshot or delta to the last known state of the subscription
+// referenceID and returns the result - each balance subscription (e.g. per account) has its own state
+func (mh *MessageHandler) mergeBalance(referenceID string, balance StreamingBalance) saxo.PortfolioUpdate {
+	mh.balanceMu.Lock()
+	defer mh.balanceMu.Unlock()
+
+	current, known := mh.balances[referenceID]
+	if !known {
+		// A new subscription (or a resubscription after reconnect) - forget unsubscribed ones
+		for previous := range mh.balances {
+			if handler, timed := mh.client.subscriptionManager.routeFor(previous); handler == nil && timed == nil {
+				delete(mh.balances, previous)
+			}
+		}
 	}
+	mergeField(&current.Balance, balance.TotalValue)
+	mergeField(&current.MarginUsed, balance.MarginUsedByCurrentPositions)
+	mergeField(&current.MarginFree, balance.MarginAvailableForTrading)
+	mergeField(&current.CashBalance, balance.CashBalance)
+	mergeField(&current.UnrealizedProfitLoss, balance.UnrealizedMarginProfitLoss)
+	mergeField(&current.MarginUtilizationPct, balance.MarginUtilizationPct)
+	mergeField(&current.Currency, balance.Currency)
+	current.UpdatedAt = mh.client.clock.Now()
+	mh.balances[referenceID] = current
+	return current
+}
 
-	marginUsed, err := mh.extractFloat64(portfolioData, "MarginUsed")
-	if err != nil {
-		marginUsed = 0.0
+func mergeField[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
 	}
+}
 
-	marginFree, err := mh.extractFloat64(portfolioData, "MarginAvailable")
-	if err != nil {
-		marginFree = 0.0
+// fillZero sets *dst to src when *dst is the zero value
+func fillZero[T comparable](dst *T, src T) {
+	var zero T
+	if *dst == zero {
+		*dst = src
 	}
-
-	return &saxo.PortfolioUpdate{
-		Balance:    balance,
-		MarginUsed: marginUsed,
-		MarginFree: marginFree,
-		UpdatedAt:  time.Now(),
-	}, nil
 }
 
-// Helper methods for data extraction and conversion
-
-func (mh *MessageHandler) extractFloat64(data map[string]interface{}, key string) (float64, error) {
-	value, exists := data[key]
-	if !exists {
-		return 0, fmt.Errorf("key %s not found", key)
+func deref[T any](value *T) T {
+	var zero T
+	if value == nil {
+		return zero
 	}
-
-	return mh.convertToFloat64(value)
+	return *value
 }
 
-func (mh *MessageHandler) convertToFloat64(value interface{}) (float64, error) {
-	switch v := value.(type) {
-	case float64:
-		return v, nil
-	case float32:
-		return float64(v), nil
-	case int:
-		return float64(v), nil
-	case int64:
-		return float64(v), nil
-	case string:
-		return strconv.ParseFloat(v, 64)
-	default:
-		return 0, fmt.Errorf("cannot convert %T to float64", value)
-	}
+func isTrue(value *bool) bool {
+	return value != nil && *value
 }
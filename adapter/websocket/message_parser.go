@@ -5,10 +5,59 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"time"
+	"sync"
 )
 
-// parseMessage processes incoming Saxo WebSocket binary messages
+// maxCachedReferenceIDs bounds referenceIDCache - resubscriptions mint new IDs over a long session
+const maxCachedReferenceIDs = 1024
+
+// referenceIDCache interns reference IDs so parsing a message does not allocate its string
+type referenceIDCache struct {
+	mu  sync.RWMutex
+	ids map[string]string
+}
+
+// intern returns raw as a string, shared with earlier messages carrying the same ID
+func (c *referenceIDCache) intern(raw []byte) string {
+	c.mu.RLock()
+	id, ok := c.ids[string(raw)]
+	c.mu.RUnlock()
+	if ok {
+		return id
+	}
+
+	id = string(raw)
+	c.mu.Lock()
+	if c.ids == nil || len(c.ids) >= maxCachedReferenceIDs {
+		c.ids = make(map[string]string)
+	}
+	c.ids[id] = id
+	c.mu.Unlock()
+	return id
+}
+
+// parseMessages appends the Saxo messages of a WebSocket frame to messages
+// Saxo may pack several messages into one frame, back to back. Messages parsed before a malformed
+// one are returned together with the error, so a bad tail does not drop the good head.
+// Payloads are slices of frame; ids may be nil
+func parseMessages(frame []byte, messages []ParsedMessage, ids *referenceIDCache) ([]ParsedMessage, error) {
+	first := len(messages)
+	for offset := 0; offset < len(frame); {
+		parsed, size, err := parseMessage(frame[offset:], ids)
+		if err != nil {
+			return messages, fmt.Errorf("message %d at offset %d: %w", len(messages)-first, offset, err)
+		}
+		messages = append(messages, parsed)
+		offset += size
+	}
+	if len(messages) == first {
+		return messages, fmt.Errorf("empty frame")
+	}
+	return messages, nil
+}
+
+// parseMessage parses the first Saxo WebSocket binary message of message and returns its size
+// The reference ID is interned through ids unless nil
 // Following exact legacy broker_websocket.go binary protocol parsing
 //
 // Saxo WebSocket Binary Protocol:
@@ -19,9 +68,9 @@ import (
 // - Byte after Reference ID: Payload Format (0 = JSON)
 // - Next 4 bytes: Payload Size (uint32, little-endian)
 // - Remaining bytes: Payload (JSON)
-func parseMessage(message []byte) (*ParsedMessage, error) {
+func parseMessage(message []byte, ids *referenceIDCache) (ParsedMessage, int, error) {
 	if len(message) < 16 {
-		return nil, fmt.Errorf("message too short: %d bytes (minimum 16 required)", len(message))
+		return ParsedMessage{}, 0, fmt.Errorf("message too short: %d bytes (minimum 16 required)", len(message))
 	}
 
 	// Byte index 0-8: Message Identifier
@@ -34,21 +83,26 @@ func parseMessage(message []byte) (*ParsedMessage, error) {
 
 	// Byte index 11: Reference ID
 	if len(message) < 11+srefid {
-		return nil, fmt.Errorf("message too short for reference ID: %d bytes", len(message))
+		return ParsedMessage{}, 0, fmt.Errorf("message too short for reference ID: %d bytes", len(message))
+	}
+	var refID string
+	if ids != nil {
+		refID = ids.intern(message[11 : 11+srefid])
+	} else {
+		refID = string(message[11 : 11+srefid])
 	}
-	refID := string(message[11 : 11+srefid])
 
 	// Byte after Reference ID: Payload Format
 	payloadFormatOffset := 11 + srefid
 	if len(message) <= payloadFormatOffset {
-		return nil, fmt.Errorf("message too short for payload format")
+		return ParsedMessage{}, 0, fmt.Errorf("message too short for payload format")
 	}
 	payloadFormat := message[payloadFormatOffset]
 
 	// Next 4 bytes: Payload Size
 	payloadSizeOffset := payloadFormatOffset + 1
 	if len(message) < payloadSizeOffset+4 {
-		return nil, fmt.Errorf("message too short for payload size")
+		return ParsedMessage{}, 0, fmt.Errorf("message too short for payload size")
 	}
 	payloadSize := binary.LittleEndian.Uint32(message[payloadSizeOffset : payloadSizeOffset+4])
 
@@ -56,16 +110,16 @@ func parseMessage(message []byte) (*ParsedMessage, error) {
 	payloadStart := payloadSizeOffset + 4
 	payloadEnd := payloadStart + int(payloadSize)
 	if len(message) < payloadEnd {
-		return nil, fmt.Errorf("message too short for payload: expected %d, got %d", payloadEnd, len(message))
+		return ParsedMessage{}, 0, fmt.Errorf("message too short for payload: expected %d, got %d", payloadEnd, len(message))
 	}
 	payload := message[payloadStart:payloadEnd]
 
-	return &ParsedMessage{
+	return ParsedMessage{
 		MessageID:     messid,
 		ReferenceID:   refID,
 		PayloadFormat: payloadFormat,
 		Payload:       payload,
-	}, nil
+	}, payloadEnd, nil
 }
 
 // ParsedMessage represents a parsed Saxo WebSocket binary message
@@ -88,6 +142,7 @@ func (pm *ParsedMessage) String() string {
 }
 
 // handleHeartbeat processes heartbeat control messages
+// Reasons become per-subscription health and recovery actions (see handleSubscriptionHeartbeat)
 // Following legacy pattern for updating subscription timestamps
 func handleHeartbeat(payload []byte, ws *SaxoWebSocketClient) error {
 	var heartbeat []HeartbeatMessage
@@ -96,25 +151,10 @@ func handleHeartbeat(payload []byte, ws *SaxoWebSocketClient) error {
 		return fmt.Errorf("failed to parse heartbeat message: %w", err)
 	}
 
-	// Process each heartbeat and update timestamps
-	for i, h := range heartbeat {
-		if len(h.Heartbeats) <= i {
-			continue
-		}
-
-		hb := h.Heartbeats[i]
-		switch hb.Reason {
-		case "NoNewData":
-			// Normal heartbeat - update timestamp
-			ws.lastMessageTimestampsMu.Lock()
-			ws.lastMessageTimestamps[hb.OriginatingReferenceID] = time.Now()
-			ws.lastMessageTimestampsMu.Unlock()
-		case "SubscriptionTemporarilyDisabled":
-			log.Printf("Subscription %s temporarily disabled", hb.OriginatingReferenceID)
-		case "SubscriptionPermanentlyDisabled":
-			log.Printf("Subscription %s permanently disabled", hb.OriginatingReferenceID)
-		default:
-			log.Printf("Unknown heartbeat reason: %s", hb.Reason)
+	// Process every heartbeat of every message - each names one subscription
+	for _, h := range heartbeat {
+		for _, hb := range h.Heartbeats {
+			ws.handleSubscriptionHeartbeat(hb.OriginatingReferenceID, hb.Reason)
 		}
 	}
 
@@ -122,10 +162,9 @@ func handleHeartbeat(payload []byte, ws *SaxoWebSocketClient) error {
 }
 
 // handleDisconnect processes disconnect control messages
-func handleDisconnect(ws *SaxoWebSocketClient) error {
-	log.Println("Received disconnect message from Saxo - user needs to log in again")
-	// Trigger graceful shutdown
-	ws.Close()
+// Reconnects after the advised delay or closes the client, per DisconnectPolicy
+func handleDisconnect(payload []byte, ws *SaxoWebSocketClient) error {
+	ws.handleServerDisconnect(parseDisconnectMessage(payload))
 	return nil
 }
 
@@ -0,0 +1,132 @@
+package websocket
+
+import (
+	"context"
+	"encoding/binary"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+// encodeTestMessage encodes one message in the Saxo binary format
+func encodeTestMessage(messageID uint64, referenceID, payload string) []byte {
+	message := make([]byte, 8+2+1+len(referenceID)+1+4+len(payload))
+	binary.LittleEndian.PutUint64(message[0:8], messageID)
+	message[10] = byte(len(referenceID))
+	offset := 11 + copy(message[11:], referenceID)
+	message[offset] = 0 // JSON
+	binary.LittleEndian.PutUint32(message[offset+1:offset+5], uint32(len(payload)))
+	copy(message[offset+5:], payload)
+	return message
+}
+
+func TestParseMessages_ConcatenatedFrame(t *testing.T) {
+	frame := append(encodeTestMessage(7, "prices", `[{"Uic":21}]`), encodeTestMessage(8, "_heartbeat", `[]`)...)
+	frame = append(frame, encodeTestMessage(9, "orders", `[{"OrderId":"1"}]`)...)
+
+	var ids referenceIDCache
+	messages, err := parseMessages(frame, nil, &ids)
+	if err != nil {
+		t.Fatalf("parseMessages failed: %v", err)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(messages))
+	}
+	want := []struct {
+		id      uint64
+		ref     string
+		payload string
+	}{{7, "prices", `[{"Uic":21}]`}, {8, "_heartbeat", `[]`}, {9, "orders", `[{"OrderId":"1"}]`}}
+	for i, w := range want {
+		if messages[i].MessageID != w.id || messages[i].ReferenceID != w.ref || string(messages[i].Payload) != w.payload {
+			t.Errorf("Message %d: expected %d/%s/%s, got %s", i, w.id, w.ref, w.payload, messages[i].String())
+		}
+	}
+
+	// A truncated tail keeps the messages before it
+	truncated := frame[:len(frame)-3]
+	messages, err = parseMessages(truncated, messages[:0], nil)
+	if err == nil {
+		t.Error("Expected an error for a truncated frame")
+	}
+	if len(messages) != 2 || messages[1].MessageID != 8 {
+		t.Errorf("Expected the 2 complete messages, got %v", messages)
+	}
+
+	// Known reference IDs are interned - parsing into a reused slice does not allocate
+	scratch := make([]ParsedMessage, 0, 3)
+	allocs := testing.AllocsPerRun(100, func() {
+		scratch, _ = parseMessages(frame, scratch[:0], &ids)
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations parsing a known frame, got %v", allocs)
+	}
+
+	if _, err := parseMessages(nil, nil, nil); err == nil {
+		t.Error("Expected an error for an empty frame")
+	}
+}
+
+func TestSaxoWebSocketClient_MultiMessageFrame(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SubscribeToPrices(ctx, []string{"21", "31"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+
+	var batch []mocktesting.MockMessage
+	for _, uic := range []int{21, 31, 21} {
+		msg, err := mockServer.PriceMessage(uic, 1.1, 1.2)
+		if err != nil {
+			t.Fatalf("PriceMessage failed: %v", err)
+		}
+		batch = append(batch, msg)
+	}
+	if err := mockServer.SendBatch(batch...); err != nil {
+		t.Fatalf("SendBatch failed: %v", err)
+	}
+
+	// All three streamed updates arrive - snapshots are skipped
+	var streamed []saxo.PriceUpdate
+	timeout := time.After(2 * time.Second)
+	for len(streamed) < 3 {
+		select {
+		case update := <-client.GetPriceUpdateChannel():
+			if !update.Snapshot {
+				streamed = append(streamed, update)
+			}
+		case <-timeout:
+			t.Fatalf("Expected 3 price updates from one frame, got %d", len(streamed))
+		}
+	}
+	if streamed[0].Uic != 21 || streamed[1].Uic != 31 || streamed[2].Uic != 21 {
+		t.Errorf("Expected updates in frame order 21, 31, 21, got %d, %d, %d", streamed[0].Uic, streamed[1].Uic, streamed[2].Uic)
+	}
+
+	// The sequence number is the frame's last message, so a reconnect does not replay the frame
+	if got, want := client.lastSequenceNumber.Load(), mockServer.LastMessageID(); got != want {
+		t.Errorf("Expected last sequence number %d, got %d", want, got)
+	}
+}
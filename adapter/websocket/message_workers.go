@@ -0,0 +1,210 @@
+package websocket
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMessageQueueSize is the per-worker queue capacity when MessageWorkerConfig.QueueSize is 0
+const DefaultMessageQueueSize = 1000
+
+// MessageWorkerConfig configures parallel processing of data messages (SetMessageWorkers)
+type MessageWorkerConfig struct {
+	Workers   int // Worker goroutines; 0 or 1 keeps all processing on the processor goroutine (default)
+	QueueSize int // Per-worker queue capacity, DefaultMessageQueueSize when 0
+}
+
+// MessageWorkerStats reports the load of the worker pool
+type MessageWorkerStats struct {
+	Workers     int           // 0 when the pool is disabled
+	QueueDepths []int         // Messages waiting, per worker
+	Processed   uint64        // Data messages handled by workers
+	Blocked     uint64        // Dispatches that found their worker's queue full
+	BlockedTime time.Duration // Total time the processor waited on full queues
+}
+
+// messageJob is one data message queued to a worker, holding a reference to its frame
+type messageJob struct {
+	message    ParsedMessage
+	receivedAt time.Time
+	frame      *frameBuffer
+}
+
+// messageWorkers fans data messages out to a fixed set of workers by ReferenceId
+// A ReferenceId always maps to the same worker, so a subscription's messages keep their order
+// while different subscriptions proceed in parallel. Queues are bounded: a full queue blocks the
+// processor, which in turn backs up the reader - the same backpressure as a slow inline handler,
+// confined to the subscriptions sharing that worker
+type messageWorkers struct {
+	handler *MessageHandler
+	queues  []chan messageJob
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	once    sync.Once
+
+	processed    atomic.Uint64
+	blocked      atomic.Uint64
+	blockedNanos atomic.Int64
+}
+
+func newMessageWorkers(handler *MessageHandler, config MessageWorkerConfig) *messageWorkers {
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultMessageQueueSize
+	}
+	w := &messageWorkers{
+		handler: handler,
+		queues:  make([]chan messageJob, config.Workers),
+		stop:    make(chan struct{}),
+	}
+	for i := range w.queues {
+		w.queues[i] = make(chan messageJob, config.QueueSize)
+		w.wg.Add(1)
+		go w.run(w.queues[i])
+	}
+	return w
+}
+
+// queueFor picks the worker of referenceID (FNV-1a, no allocation)
+func (w *messageWorkers) queueFor(referenceID string) chan messageJob {
+	hash := uint32(2166136261)
+	for i := 0; i < len(referenceID); i++ {
+		hash ^= uint32(referenceID[i])
+		hash *= 16777619
+	}
+	return w.queues[hash%uint32(len(w.queues))]
+}
+
+// dispatch queues message for its worker, waiting while the queue is full
+// Returns false if the pool was stopped - the message was not queued and the caller handles it
+func (w *messageWorkers) dispatch(message ParsedMessage, receivedAt time.Time, frame *frameBuffer) bool {
+	select {
+	case <-w.stop:
+		return false
+	default:
+	}
+
+	frame.retain()
+	job := messageJob{message: message, receivedAt: receivedAt, frame: frame}
+	queue := w.queueFor(message.ReferenceID)
+	select {
+	case queue <- job:
+		return true
+	default:
+	}
+
+	w.blocked.Add(1)
+	start := time.Now()
+	defer func() { w.blockedNanos.Add(int64(time.Since(start))) }()
+	select {
+	case queue <- job:
+		return true
+	case <-w.stop:
+		releaseFrame(frame)
+		return false
+	}
+}
+
+// run handles the jobs of one queue until Stop
+func (w *messageWorkers) run(queue chan messageJob) {
+	defer w.wg.Done()
+	for {
+		select {
+		case job := <-queue:
+			w.handle(job)
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// handle processes one job and drops its frame reference
+func (w *messageWorkers) handle(job messageJob) {
+	defer releaseFrame(job.frame)
+	if err := w.handler.handleDataMessage(&job.message, job.receivedAt); err != nil {
+		w.handler.client.logger.Error("Message handling error",
+			"function", "messageWorkers",
+			"reference_id", job.message.ReferenceID,
+			"error", err)
+	}
+	w.processed.Add(1)
+}
+
+// Stop ends the workers after their current message; queued messages are discarded
+func (w *messageWorkers) Stop() {
+	w.once.Do(func() {
+		close(w.stop)
+		w.wg.Wait()
+		for _, queue := range w.queues {
+			for len(queue) > 0 {
+				releaseFrame((<-queue).frame)
+			}
+		}
+	})
+}
+
+// stats snapshots the pool metrics
+func (w *messageWorkers) stats() MessageWorkerStats {
+	stats := MessageWorkerStats{
+		Workers:     len(w.queues),
+		QueueDepths: make([]int, len(w.queues)),
+		Processed:   w.processed.Load(),
+		Blocked:     w.blocked.Load(),
+		BlockedTime: time.Duration(w.blockedNanos.Load()),
+	}
+	for i, queue := range w.queues {
+		stats.QueueDepths[i] = len(queue)
+	}
+	return stats
+}
+
+// SetMessageWorkers processes data messages on a pool of workers instead of the processor goroutine,
+// so a slow handler (e.g. portfolio) no longer delays price delivery. Messages of one subscription
+// stay in order; across subscriptions, and on the Events stream, order is no longer guaranteed.
+// Control messages stay on the processor. Workers <= 1 restores single-threaded processing.
+// Close stops the pool and Connect starts it again; replacing a running pool discards the
+// messages it still had queued
+func (ws *SaxoWebSocketClient) SetMessageWorkers(config MessageWorkerConfig) {
+	ws.messageWorkerConfig.Store(&config)
+
+	var workers *messageWorkers
+	if config.Workers > 1 {
+		workers = newMessageWorkers(ws.messageHandler, config)
+	}
+	if previous := ws.messageWorkers.Swap(workers); previous != nil {
+		previous.Stop()
+	}
+
+	ws.logger.Info("Message workers set",
+		"function", "SetMessageWorkers",
+		"workers", config.Workers,
+		"queue_size", config.QueueSize)
+}
+
+// startMessageWorkers recreates the pool configured by SetMessageWorkers after Close stopped it
+func (ws *SaxoWebSocketClient) startMessageWorkers() {
+	config := ws.messageWorkerConfig.Load()
+	if config == nil || config.Workers <= 1 || ws.messageWorkers.Load() != nil {
+		return
+	}
+	workers := newMessageWorkers(ws.messageHandler, *config)
+	if !ws.messageWorkers.CompareAndSwap(nil, workers) {
+		workers.Stop() // SetMessageWorkers won the race
+	}
+}
+
+// stopMessageWorkers ends the pool's goroutines - call once the processor no longer dispatches
+func (ws *SaxoWebSocketClient) stopMessageWorkers() {
+	if workers := ws.messageWorkers.Swap(nil); workers != nil {
+		workers.Stop()
+	}
+}
+
+// MessageWorkers returns the worker pool metrics, zero when SetMessageWorkers is not in effect
+func (ws *SaxoWebSocketClient) MessageWorkers() MessageWorkerStats {
+	workers := ws.messageWorkers.Load()
+	if workers == nil {
+		return MessageWorkerStats{}
+	}
+	return workers.stats()
+}
@@ -0,0 +1,197 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+// workerTestClient returns a connected client with a price subscription on UIC 21
+func workerTestClient(t *testing.T) (*SaxoWebSocketClient, *mocktesting.MockSaxoWebSocketServer) {
+	t.Helper()
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	t.Cleanup(mockServer.Close)
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	if err := client.SubscribeToPrices(ctx, []string{"21"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+	return client, mockServer
+}
+
+// registerTestSubscription registers a custom subscription and returns its ReferenceId
+func registerTestSubscription(t *testing.T, client *SaxoWebSocketClient, mockServer *mocktesting.MockSaxoWebSocketServer, endpoint string, handler DataHandler) string {
+	t.Helper()
+	if _, err := client.RegisterSubscription(context.Background(), "slow", endpoint, nil, handler); err != nil {
+		t.Fatalf("RegisterSubscription failed: %v", err)
+	}
+	for refID, sub := range mockServer.GetActiveSubscriptions() {
+		if sub.Endpoint == endpoint {
+			return refID
+		}
+	}
+	t.Fatal("Custom subscription not created on the server")
+	return ""
+}
+
+// priceReferenceID returns the ReferenceId of the client's FxSpot price subscription
+func priceReferenceID(client *SaxoWebSocketClient) string {
+	for refID, key := range client.subscriptionManager.subscriptionKeysByReferenceId() {
+		if key == "price_feed_FxSpot" {
+			return refID
+		}
+	}
+	return ""
+}
+
+func TestSaxoWebSocketClient_MessageWorkersIsolateSlowHandler(t *testing.T) {
+	client, mockServer := workerTestClient(t)
+
+	release := make(chan struct{})
+	defer close(release)
+	slowRef := registerTestSubscription(t, client, mockServer, "/port/v1/positions/subscriptions",
+		func(referenceID string, payload []byte) error {
+			<-release
+			return nil
+		})
+
+	// Pick a pool size that puts the two subscriptions on different workers
+	priceRef := priceReferenceID(client)
+	for workers := 2; ; workers++ {
+		client.SetMessageWorkers(MessageWorkerConfig{Workers: workers})
+		pool := client.messageWorkers.Load()
+		if pool.queueFor(slowRef) != pool.queueFor(priceRef) {
+			break
+		}
+	}
+	for len(client.GetPriceUpdateChannel()) > 0 {
+		<-client.GetPriceUpdateChannel() // Subscription snapshot
+	}
+
+	if err := mockServer.SendDataMessage(slowRef, []int{1}); err != nil {
+		t.Fatalf("SendDataMessage failed: %v", err)
+	}
+	if err := mockServer.SendPriceUpdate("21", 1.1, 1.2); err != nil {
+		t.Fatalf("SendPriceUpdate failed: %v", err)
+	}
+
+	// The price arrives while the slow handler is still blocked
+	select {
+	case update := <-client.GetPriceUpdateChannel():
+		if update.Uic != 21 {
+			t.Errorf("Unexpected update %+v", update)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Price update delayed by the slow handler")
+	}
+}
+
+func TestSaxoWebSocketClient_MessageWorkersKeepOrder(t *testing.T) {
+	client, mockServer := workerTestClient(t)
+	client.SetMessageWorkers(MessageWorkerConfig{Workers: 4, QueueSize: 1})
+
+	const messages = 50
+	received := make(chan int, messages)
+	gate := make(chan struct{})
+	refID := registerTestSubscription(t, client, mockServer, "/port/v1/positions/subscriptions",
+		func(referenceID string, payload []byte) error {
+			<-gate
+			var seq []int
+			if err := json.Unmarshal(payload, &seq); err != nil {
+				return err
+			}
+			received <- seq[0]
+			return nil
+		})
+
+	for i := 0; i < messages; i++ {
+		if err := mockServer.SendDataMessage(refID, []int{i}); err != nil {
+			t.Fatalf("SendDataMessage failed: %v", err)
+		}
+	}
+	// Let the queue fill before releasing the handler
+	if !waitFor(t, 2*time.Second, func() bool { return client.MessageWorkers().Blocked > 0 }) {
+		t.Fatal("Expected the processor to block on the full queue")
+	}
+	close(gate)
+
+	for i := 0; i < messages; i++ {
+		select {
+		case seq := <-received:
+			if seq != i {
+				t.Fatalf("Expected message %d, got %d", i, seq)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Message %d not handled", i)
+		}
+	}
+
+	stats := client.MessageWorkers()
+	if stats.Workers != 4 || len(stats.QueueDepths) != 4 || stats.Processed < messages || stats.BlockedTime <= 0 {
+		t.Errorf("Unexpected worker stats %+v", stats)
+	}
+
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if stats := client.MessageWorkers(); stats.Workers != 0 {
+		t.Errorf("Expected the pool stopped by Shutdown, got %+v", stats)
+	}
+}
+
+func TestSaxoWebSocketClient_MessageWorkersStoppedPoolHandlesInline(t *testing.T) {
+	client, mockServer := workerTestClient(t)
+	client.SetMessageWorkers(MessageWorkerConfig{Workers: 2})
+
+	received := make(chan struct{}, 1)
+	refID := registerTestSubscription(t, client, mockServer, "/port/v1/positions/subscriptions",
+		func(referenceID string, payload []byte) error {
+			received <- struct{}{}
+			return nil
+		})
+
+	// A pool stopped while still installed hands messages back to the processor
+	client.messageWorkers.Load().Stop()
+	if err := mockServer.SendDataMessage(refID, []int{1}); err != nil {
+		t.Fatalf("SendDataMessage failed: %v", err)
+	}
+	select {
+	case <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Message dropped by the stopped pool")
+	}
+
+	// Close stops the workers, Connect starts the configured pool again
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if stats := client.MessageWorkers(); stats.Workers != 0 {
+		t.Errorf("Expected the pool stopped by Close, got %+v", stats)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Reconnect failed: %v", err)
+	}
+	if stats := client.MessageWorkers(); stats.Workers != 2 {
+		t.Errorf("Expected the pool restarted by Connect, got %+v", stats)
+	}
+}
@@ -0,0 +1,97 @@
+package mocktesting
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FakeClock is a manually advanced clock satisfying saxo.Clock
+// Pass it with saxo.WithClock; Advance fires every timer that falls due, so
+// 18-minute token refreshes or 100s subscription timeouts run in microseconds
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewFakeClock creates a clock standing at start
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the fake current time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives once the clock is advanced by d
+// d <= 0 fires immediately like time.After
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward by d and fires every timer due by then
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.fireLocked()
+	c.mu.Unlock()
+}
+
+// Set moves the clock to t (never backwards) and fires every timer due by then
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	if t.After(c.now) {
+		c.now = t
+	}
+	c.fireLocked()
+	c.mu.Unlock()
+}
+
+func (c *FakeClock) fireLocked() {
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now // Buffered, never blocks
+	}
+	c.waiters = pending
+}
+
+// Waiters returns the number of pending timers
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// WaitForWaiters blocks (in real time) until at least n timers are pending
+// Call before Advance so the goroutine under test is parked on its timer
+func (c *FakeClock) WaitForWaiters(n int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for c.Waiters() < n {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for %d timers, have %d", n, c.Waiters())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package mocktesting
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// maxReplayFrames bounds the frames kept for replay on reconnect
+const maxReplayFrames = 1000
+
+// sentFrame is one broadcast WebSocket frame, replayed to clients reconnecting with an older messageid
+type sentFrame struct {
+	lastID uint64 // ID of the last message in the frame
+	data   []byte
+}
+
+// MockConnection records one WebSocket connect for reconnect assertions
+type MockConnection struct {
+	ContextID   string
+	MessageID   uint64 // ?messageid= sent by the client, 0 = fresh connection
+	ConnectedAt time.Time
+}
+
+// MockMessage is one message of a multi-message frame (SendBatch)
+type MockMessage struct {
+	ReferenceID string
+	Payload     interface{}
+}
+
+// SendBatch packs several messages into one WebSocket frame, each with its own message ID
+// Saxo does this under load - a client that only parses the first message loses the rest
+func (m *MockSaxoWebSocketServer) SendBatch(messages ...MockMessage) error {
+	if len(messages) == 0 {
+		return fmt.Errorf("empty batch")
+	}
+
+	var frame []byte
+	var lastID uint64
+	for _, msg := range messages {
+		lastID = atomic.AddUint64(&m.messageIDCounter, 1)
+		encoded, err := encodeSaxoMessage(lastID, msg.ReferenceID, msg.Payload)
+		if err != nil {
+			return err
+		}
+		frame = append(frame, encoded...)
+	}
+	return m.broadcastFrame(frame, lastID)
+}
+
+// PriceMessage builds a price message for SendBatch on the active price subscription
+func (m *MockSaxoWebSocketServer) PriceMessage(uic int, bid, ask float64) (MockMessage, error) {
+	refID := m.findReferenceID("/trade/v1/infoprices/subscriptions")
+	if refID == "" {
+		return MockMessage{}, fmt.Errorf("no price subscription found")
+	}
+	return MockMessage{
+		ReferenceID: refID,
+		Payload: []interface{}{
+			map[string]interface{}{
+				"Uic":         uic,
+				"Quote":       map[string]interface{}{"Bid": bid, "Ask": ask, "Mid": (bid + ask) / 2},
+				"LastUpdated": time.Now().Format(time.RFC3339),
+			},
+		},
+	}, nil
+}
+
+// SendPriceDelta streams a partial quote for uic - only the given Quote fields, like Saxo deltas
+// e.g. SendPriceDelta(21, map[string]interface{}{"Bid": 1.1001}) leaves Ask and Mid out
+func (m *MockSaxoWebSocketServer) SendPriceDelta(uic int, quote map[string]interface{}) error {
+	refID := m.findReferenceID("/trade/v1/infoprices/subscriptions")
+	if refID == "" {
+		return fmt.Errorf("no price subscription found")
+	}
+	return m.SendDataMessage(refID, []interface{}{
+		map[string]interface{}{"Uic": uic, "Quote": quote},
+	})
+}
+
+// SendOrderDelta streams only the changed fields of an order (OrderId is always included)
+func (m *MockSaxoWebSocketServer) SendOrderDelta(orderID string, fields map[string]interface{}) error {
+	refID := m.findReferenceID("/port/v1/orders/subscriptions")
+	if refID == "" {
+		return fmt.Errorf("no order subscription found")
+	}
+	delta := map[string]interface{}{"OrderId": orderID}
+	for key, value := range fields {
+		delta[key] = value
+	}
+	return m.SendDataMessage(refID, []interface{}{delta})
+}
+
+// SetSessionTradeLevel sets the levels reported by session event snapshots and SendSessionEvent
+func (m *MockSaxoWebSocketServer) SetSessionTradeLevel(tradeLevel, dataLevel string) {
+	m.subscMu.Lock()
+	defer m.subscMu.Unlock()
+	m.tradeLevel = tradeLevel
+	m.dataLevel = dataLevel
+}
+
+// SendSessionEvent streams the current session capabilities on the session events subscription
+// (e.g. after SetSessionTradeLevel("OrdersOnly", ...) to simulate a downgrade by another login)
+func (m *MockSaxoWebSocketServer) SendSessionEvent() error {
+	refID := m.findReferenceID("/root/v1/sessions/events/subscriptions/active")
+	if refID == "" {
+		return fmt.Errorf("no session events subscription found")
+	}
+	m.subscMu.RLock()
+	payload := m.sessionPayloadLocked()
+	m.subscMu.RUnlock()
+	return m.SendDataMessage(refID, payload)
+}
+
+// SkipMessageIDs advances the message ID counter by n, so the next message leaves a gap
+func (m *MockSaxoWebSocketServer) SkipMessageIDs(n uint64) {
+	atomic.AddUint64(&m.messageIDCounter, n)
+}
+
+// LastMessageID returns the ID of the most recently built message
+func (m *MockSaxoWebSocketServer) LastMessageID() uint64 {
+	return atomic.LoadUint64(&m.messageIDCounter)
+}
+
+// DropConnections closes every client TCP connection without a close frame (network failure)
+// Unlike SendDisconnect, the client is expected to reconnect
+func (m *MockSaxoWebSocketServer) DropConnections() {
+	m.clientsMu.Lock()
+	defer m.clientsMu.Unlock()
+	for conn := range m.clients {
+		conn.UnderlyingConn().Close()
+	}
+}
+
+// Connections returns every WebSocket connect so far, oldest first
+func (m *MockSaxoWebSocketServer) Connections() []MockConnection {
+	m.clientsMu.RLock()
+	defer m.clientsMu.RUnlock()
+	return append([]MockConnection(nil), m.connections...)
+}
+
+// ActiveConnections returns the number of open client connections (1 after a clean reconnect)
+func (m *MockSaxoWebSocketServer) ActiveConnections() int {
+	m.clientsMu.RLock()
+	defer m.clientsMu.RUnlock()
+	return len(m.clients)
+}
+
+// dropReplacedLocked removes the subscription named by ReplaceReferenceId, like Saxo's atomic replace
+func (m *MockSaxoWebSocketServer) dropReplacedLocked(subscriptionReq map[string]interface{}) {
+	if replaced, _ := subscriptionReq["ReplaceReferenceId"].(string); replaced != "" {
+		delete(m.subscriptions, replaced)
+	}
+}
+
+// handleSessionSubscription handles HTTP POST /root/v1/sessions/events/subscriptions/active
+func (m *MockSaxoWebSocketServer) handleSessionSubscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
+		return
+	}
+
+	var subscriptionReq map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&subscriptionReq); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	referenceID, _ := subscriptionReq["ReferenceId"].(string)
+	contextID, _ := subscriptionReq["ContextId"].(string)
+	m.subscMu.Lock()
+	m.dropReplacedLocked(subscriptionReq)
+	m.subscriptions[referenceID] = MockSubscription{
+		ContextId:   contextID,
+		ReferenceId: referenceID,
+		Arguments:   map[string]interface{}{},
+		State:       "Active",
+		Endpoint:    r.URL.Path,
+	}
+	snapshot := m.sessionPayloadLocked()
+	m.subscMu.Unlock()
+
+	snapshot["ReferenceId"] = referenceID
+	w.Header().Set("Location", fmt.Sprintf("/root/v1/sessions/events/subscriptions/%s/%s", contextID, referenceID))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// handleSessionCapabilities handles HTTP PATCH /root/v1/sessions/capabilities
+// Applies the requested TradeLevel and streams the change like Saxo does
+func (m *MockSaxoWebSocketServer) handleSessionCapabilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "PATCH" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		TradeLevel string `json:"TradeLevel"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.TradeLevel == "" {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	m.subscMu.Lock()
+	m.tradeLevel = req.TradeLevel
+	m.subscMu.Unlock()
+
+	w.WriteHeader(http.StatusAccepted)
+	m.SendSessionEvent() // No-op error when nobody subscribed
+}
+
+// sessionPayloadLocked builds a session event in the shape SaxoSessionCapabilities parses
+func (m *MockSaxoWebSocketServer) sessionPayloadLocked() map[string]interface{} {
+	return map[string]interface{}{
+		"InactivityTimeout": 120,
+		"RefreshRate":       1000,
+		"State":             "Active",
+		"Snapshot": map[string]interface{}{
+			"AuthenticationLevel": "Authenticated",
+			"DataLevel":           m.dataLevel,
+			"TradeLevel":          m.tradeLevel,
+		},
+	}
+}
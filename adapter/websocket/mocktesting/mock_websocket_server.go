@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -27,8 +28,23 @@ type MockSaxoWebSocketServer struct {
 	subscriptions map[string]MockSubscription
 	subscMu       sync.RWMutex
 
+	// Quotes returned in price subscription snapshots, keyed by UIC (SetSnapshotQuote)
+	snapshotQuotes map[int][2]float64
+
 	// Message ID counter (must be unique per message)
 	messageIDCounter uint64
+
+	// Sent frames for replay on reconnect with ?messageid= (see mock_protocol.go)
+	history     []sentFrame
+	connections []MockConnection
+
+	// Session capabilities reported by the session events subscription
+	tradeLevel string
+	dataLevel  string
+
+	// Upgrade requests still to be rejected and the status to reject them with (FailHandshakes)
+	failHandshakes      int
+	failHandshakeStatus int
 }
 
 // MockSubscription tracks subscription state for testing following Saxo patterns
@@ -37,6 +53,7 @@ type MockSubscription struct {
 	ReferenceId string                 `json:"ReferenceId"`
 	Arguments   map[string]interface{} `json:"Arguments"`
 	State       string                 `json:"State"`
+	Endpoint    string                 `json:"-"` // Subscription endpoint, used to find the ReferenceId to stream on
 }
 
 // NewMockSaxoWebSocketServer creates a new mock WebSocket server for testing
@@ -47,7 +64,10 @@ func NewMockSaxoWebSocketServer() *MockSaxoWebSocketServer {
 		},
 		clients:          make(map[*websocket.Conn]bool),
 		subscriptions:    make(map[string]MockSubscription),
+		snapshotQuotes:   make(map[int][2]float64),
 		messageIDCounter: 1,
+		tradeLevel:       "FullTradingAndChat",
+		dataLevel:        "Premium",
 	}
 
 	// Create HTTPS test server for WebSocket Secure (wss://) connections
@@ -58,6 +78,19 @@ func NewMockSaxoWebSocketServer() *MockSaxoWebSocketServer {
 	mux.HandleFunc("/trade/v1/infoprices/subscriptions", mock.handlePriceSubscription)
 	mux.HandleFunc("/port/v1/orders/subscriptions", mock.handleOrderSubscription)
 	mux.HandleFunc("/port/v1/balances/subscriptions", mock.handleBalanceSubscription)
+	mux.HandleFunc("/trade/v1/prices/subscriptions", mock.handleDepthSubscription)
+	mux.HandleFunc("/port/v1/users/me", mock.handleUsersMe)
+	mux.HandleFunc("/port/v1/positions/subscriptions", mock.handleGenericSubscription)
+	mux.HandleFunc("/root/v1/sessions/events/subscriptions/active", mock.handleSessionSubscription)
+	mux.HandleFunc("/root/v1/sessions/capabilities", mock.handleSessionCapabilities)
+
+	// DELETE {endpoint}/{ContextId}/{ReferenceId} removes a subscription
+	mux.HandleFunc("/trade/v1/infoprices/subscriptions/", mock.handleSubscriptionDelete)
+	mux.HandleFunc("/port/v1/orders/subscriptions/", mock.handleSubscriptionDelete)
+	mux.HandleFunc("/port/v1/balances/subscriptions/", mock.handleSubscriptionDelete)
+	mux.HandleFunc("/trade/v1/prices/subscriptions/", mock.handleSubscriptionDelete)
+	mux.HandleFunc("/port/v1/positions/subscriptions/", mock.handleSubscriptionDelete)
+	mux.HandleFunc("/root/v1/sessions/events/subscriptions/", mock.handleSubscriptionDelete)
 
 	mock.server = httptest.NewTLSServer(mux)
 	return mock
@@ -117,7 +150,11 @@ func (m *MockSaxoWebSocketServer) Close() {
 func (m *MockSaxoWebSocketServer) buildSaxoBinaryMessage(referenceID string, payloadJSON interface{}) ([]byte, error) {
 	// Get next message ID (atomic increment for thread safety)
 	messageID := atomic.AddUint64(&m.messageIDCounter, 1)
+	return encodeSaxoMessage(messageID, referenceID, payloadJSON)
+}
 
+// encodeSaxoMessage encodes one message with an explicit ID (frames may concatenate several)
+func encodeSaxoMessage(messageID uint64, referenceID string, payloadJSON interface{}) ([]byte, error) {
 	// Marshal payload to JSON
 	payload, err := json.Marshal(payloadJSON)
 	if err != nil {
@@ -173,6 +210,10 @@ func (m *MockSaxoWebSocketServer) handleWebSocket(w http.ResponseWriter, r *http
 		http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
 		return
 	}
+	if status := m.takeHandshakeFailure(); status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
 
 	// Upgrade connection to WebSocket
 	conn, err := m.upgrader.Upgrade(w, r, nil)
@@ -182,7 +223,21 @@ func (m *MockSaxoWebSocketServer) handleWebSocket(w http.ResponseWriter, r *http
 	defer conn.Close()
 
 	// Track connection with thread safety
+	// A reconnect with ?messageid= first receives every newer frame (Saxo resumes the stream)
 	m.clientsMu.Lock()
+	lastSeen, _ := strconv.ParseUint(r.URL.Query().Get("messageid"), 10, 64)
+	m.connections = append(m.connections, MockConnection{
+		ContextID:   r.URL.Query().Get("contextid"),
+		MessageID:   lastSeen,
+		ConnectedAt: time.Now(),
+	})
+	if lastSeen > 0 {
+		for _, frame := range m.history {
+			if frame.lastID > lastSeen {
+				conn.WriteMessage(websocket.BinaryMessage, frame.data)
+			}
+		}
+	}
 	m.clients[conn] = true
 	m.clientsMu.Unlock()
 
@@ -228,12 +283,15 @@ func (m *MockSaxoWebSocketServer) handlePriceSubscription(w http.ResponseWriter,
 	// Store subscription
 	referenceID := subscriptionReq["ReferenceId"].(string)
 	m.subscMu.Lock()
+	m.dropReplacedLocked(subscriptionReq)
 	m.subscriptions[referenceID] = MockSubscription{
 		ContextId:   subscriptionReq["ContextId"].(string),
 		ReferenceId: referenceID,
 		Arguments:   subscriptionReq["Arguments"].(map[string]interface{}),
 		State:       "Active",
+		Endpoint:    r.URL.Path,
 	}
+	snapshot := m.priceSnapshotLocked(subscriptionReq["Arguments"].(map[string]interface{}))
 	m.subscMu.Unlock()
 
 	// Return 201 Created following Saxo API pattern
@@ -242,9 +300,64 @@ func (m *MockSaxoWebSocketServer) handlePriceSubscription(w http.ResponseWriter,
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"State":       "Active",
 		"ReferenceId": referenceID,
+		"Snapshot":    map[string]interface{}{"Data": snapshot},
 	})
 }
 
+// SetSnapshotQuote sets the quote returned for uic in price subscription snapshots
+// UICs without a quote are left out of the snapshot
+func (m *MockSaxoWebSocketServer) SetSnapshotQuote(uic int, bid, ask float64) {
+	m.subscMu.Lock()
+	defer m.subscMu.Unlock()
+	m.snapshotQuotes[uic] = [2]float64{bid, ask}
+}
+
+// FailHandshakes rejects the next count WebSocket upgrade requests with status,
+// e.g. 503 to simulate the streaming gateway during maintenance
+func (m *MockSaxoWebSocketServer) FailHandshakes(status, count int) {
+	m.subscMu.Lock()
+	defer m.subscMu.Unlock()
+	m.failHandshakes = count
+	m.failHandshakeStatus = status
+}
+
+// takeHandshakeFailure returns the status to reject an upgrade with, 0 to accept it
+func (m *MockSaxoWebSocketServer) takeHandshakeFailure() int {
+	m.subscMu.Lock()
+	defer m.subscMu.Unlock()
+	if m.failHandshakes == 0 {
+		return 0
+	}
+	m.failHandshakes--
+	return m.failHandshakeStatus
+}
+
+// priceSnapshotLocked builds Snapshot.Data for the requested "Uics" (comma-separated)
+func (m *MockSaxoWebSocketServer) priceSnapshotLocked(arguments map[string]interface{}) []interface{} {
+	data := []interface{}{}
+	uics, _ := arguments["Uics"].(string)
+	for _, field := range strings.Split(uics, ",") {
+		uic, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			continue
+		}
+		quote, ok := m.snapshotQuotes[uic]
+		if !ok {
+			continue
+		}
+		data = append(data, map[string]interface{}{
+			"Uic":         uic,
+			"LastUpdated": time.Now().UTC().Format(time.RFC3339),
+			"Quote": map[string]interface{}{
+				"Bid": quote[0],
+				"Ask": quote[1],
+				"Mid": (quote[0] + quote[1]) / 2,
+			},
+		})
+	}
+	return data
+}
+
 // handleOrderSubscription handles HTTP POST /port/v1/orders/subscriptions
 func (m *MockSaxoWebSocketServer) handleOrderSubscription(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
@@ -269,11 +382,13 @@ func (m *MockSaxoWebSocketServer) handleOrderSubscription(w http.ResponseWriter,
 	// Store subscription
 	referenceID := subscriptionReq["ReferenceId"].(string)
 	m.subscMu.Lock()
+	m.dropReplacedLocked(subscriptionReq)
 	m.subscriptions[referenceID] = MockSubscription{
 		ContextId:   subscriptionReq["ContextId"].(string),
 		ReferenceId: referenceID,
 		Arguments:   subscriptionReq["Arguments"].(map[string]interface{}),
 		State:       "Active",
+		Endpoint:    r.URL.Path,
 	}
 	m.subscMu.Unlock()
 
@@ -286,6 +401,30 @@ func (m *MockSaxoWebSocketServer) handleOrderSubscription(w http.ResponseWriter,
 	})
 }
 
+// MockClientKey is the ClientKey returned by GET /port/v1/users/me
+const MockClientKey = "mock-client-key"
+
+// handleUsersMe handles HTTP GET /port/v1/users/me (ClientKey lookup for order/portfolio subscriptions)
+func (m *MockSaxoWebSocketServer) handleUsersMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		http.Error(w, "Missing or invalid Authorization header", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"ClientKey": MockClientKey,
+		"UserKey":   "mock-user-key",
+		"Name":      "Mock User",
+		"Active":    true,
+	})
+}
+
 // handleBalanceSubscription handles HTTP POST /port/v1/balances/subscriptions
 func (m *MockSaxoWebSocketServer) handleBalanceSubscription(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
@@ -310,11 +449,13 @@ func (m *MockSaxoWebSocketServer) handleBalanceSubscription(w http.ResponseWrite
 	// Store subscription
 	referenceID := subscriptionReq["ReferenceId"].(string)
 	m.subscMu.Lock()
+	m.dropReplacedLocked(subscriptionReq)
 	m.subscriptions[referenceID] = MockSubscription{
 		ContextId:   subscriptionReq["ContextId"].(string),
 		ReferenceId: referenceID,
 		Arguments:   subscriptionReq["Arguments"].(map[string]interface{}),
 		State:       "Active",
+		Endpoint:    r.URL.Path,
 	}
 	m.subscMu.Unlock()
 
@@ -327,21 +468,118 @@ func (m *MockSaxoWebSocketServer) handleBalanceSubscription(w http.ResponseWrite
 	})
 }
 
-// SendPriceUpdate simulates price feed message following Saxo streaming binary protocol
-// CRITICAL: Saxo sends price array directly, NOT wrapped in {"Data": [...]}
-// Legacy pattern: json.Unmarshal(incoming, &priceUpdates) where priceUpdates is []StreamingPriceUpdate
-func (m *MockSaxoWebSocketServer) SendPriceUpdate(ticker string, bid, ask float64) error {
-	// Find the price subscription reference ID (human-readable like "prices-20251119-132651")
+// MockDepthSnapshot is the MarketDepth returned in every depth subscription snapshot
+// Two bid and two ask levels, best price first
+var MockDepthSnapshot = map[string]interface{}{
+	"Bid":        []float64{1.1000, 1.0999},
+	"BidSize":    []float64{1000000, 2000000},
+	"BidOrders":  []int{3, 5},
+	"Ask":        []float64{1.1002, 1.1003},
+	"AskSize":    []float64{500000, 1500000},
+	"AskOrders":  []int{1, 4},
+	"NoOfBids":   2,
+	"NoOfOffers": 2,
+}
+
+// handleDepthSubscription handles HTTP POST /trade/v1/prices/subscriptions
+// Responds with a snapshot carrying Quote and MarketDepth like Saxo does for the MarketDepth field group
+func (m *MockSaxoWebSocketServer) handleDepthSubscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var subscriptionReq map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&subscriptionReq); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	referenceID := subscriptionReq["ReferenceId"].(string)
+	arguments := subscriptionReq["Arguments"].(map[string]interface{})
 	m.subscMu.Lock()
-	var priceRefId string
-	for refId := range m.subscriptions {
-		if refId == "prices" || refId == "price_feed" || len(refId) > 7 && refId[:7] == "prices-" {
-			priceRefId = refId
+	m.dropReplacedLocked(subscriptionReq)
+	m.subscriptions[referenceID] = MockSubscription{
+		ContextId:   subscriptionReq["ContextId"].(string),
+		ReferenceId: referenceID,
+		Arguments:   arguments,
+		State:       "Active",
+		Endpoint:    r.URL.Path,
+	}
+	m.subscMu.Unlock()
+
+	w.Header().Set("Location", fmt.Sprintf("/trade/v1/prices/subscriptions/%s", referenceID))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"State":       "Active",
+		"ReferenceId": referenceID,
+		"Snapshot": map[string]interface{}{
+			"Uic":       arguments["Uic"],
+			"AssetType": arguments["AssetType"],
+			"Quote": map[string]interface{}{
+				"Bid":     1.1000,
+				"Ask":     1.1002,
+				"BidSize": 1000000,
+				"AskSize": 500000,
+			},
+			"MarketDepth": MockDepthSnapshot,
+		},
+	})
+}
+
+// SendDepthDelta sends a /trade/v1/prices delta for the depth subscription of uic
+// Like Saxo deltas, the payload carries only changed fields (no Uic)
+func (m *MockSaxoWebSocketServer) SendDepthDelta(uic int, delta map[string]interface{}) error {
+	m.subscMu.RLock()
+	var refID string
+	for id, sub := range m.subscriptions {
+		if _, isDepth := sub.Arguments["FieldGroups"]; isDepth && int(sub.Arguments["Uic"].(float64)) == uic {
+			refID = id
 			break
 		}
 	}
+	m.subscMu.RUnlock()
+
+	if refID == "" {
+		return fmt.Errorf("no depth subscription for UIC %d", uic)
+	}
+
+	binaryMsg, err := m.buildSaxoBinaryMessage(refID, delta)
+	if err != nil {
+		return err
+	}
+	return m.broadcastBinaryMessage(binaryMsg)
+}
+
+// handleSubscriptionDelete handles HTTP DELETE {endpoint}/{ContextId}/{ReferenceId}
+// Following Saxo API pattern: Returns 202 Accepted, 404 if the subscription is unknown
+func (m *MockSaxoWebSocketServer) handleSubscriptionDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "DELETE" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	referenceID := parts[len(parts)-1]
+
+	m.subscMu.Lock()
+	_, exists := m.subscriptions[referenceID]
+	delete(m.subscriptions, referenceID)
 	m.subscMu.Unlock()
 
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// SendPriceUpdate simulates price feed message following Saxo streaming binary protocol
+// CRITICAL: Saxo sends price array directly, NOT wrapped in {"Data": [...]}
+// Legacy pattern: json.Unmarshal(incoming, &priceUpdates) where priceUpdates is []StreamingPriceUpdate
+func (m *MockSaxoWebSocketServer) SendPriceUpdate(ticker string, bid, ask float64) error {
+	// Stream on the ReferenceId the client created (e.g. "FxSpot-prices-20251119-132651")
+	priceRefId := m.findReferenceID("/trade/v1/infoprices/subscriptions")
 	if priceRefId == "" {
 		return fmt.Errorf("no price subscription found")
 	}
@@ -370,58 +608,100 @@ func (m *MockSaxoWebSocketServer) SendPriceUpdate(ticker string, bid, ask float6
 }
 
 // SendOrderUpdate simulates order status message following Saxo binary protocol
+// Saxo streams orders as a JSON array of (partial) order objects on the order subscription's ReferenceId
 func (m *MockSaxoWebSocketServer) SendOrderUpdate(orderId, status string) error {
-	// Saxo streaming format has a "Data" array
-	payloadJSON := map[string]interface{}{
-		"Data": []interface{}{
-			map[string]interface{}{
-				"OrderId":      orderId,
-				"Status":       status,
-				"FilledAmount": 0.0,
-				"BuySell":      "Buy",
-				"AssetType":    "FxSpot",
-			},
-		},
+	refID := m.findReferenceID("/port/v1/orders/subscriptions")
+	if refID == "" {
+		return fmt.Errorf("no order subscription found")
 	}
 
-	binaryMsg, err := m.buildSaxoBinaryMessage("order_updates", payloadJSON)
-	if err != nil {
-		return err
+	payloadJSON := []interface{}{
+		map[string]interface{}{
+			"OrderId":      orderId,
+			"Status":       status,
+			"FilledAmount": 0.0,
+			"BuySell":      "Buy",
+			"AssetType":    "FxSpot",
+		},
 	}
 
-	return m.broadcastBinaryMessage(binaryMsg)
+	return m.SendDataMessage(refID, payloadJSON)
 }
 
 // SendPortfolioUpdate simulates balance message following Saxo binary protocol
+// Saxo streams balances as a single (partial) object on the balance subscription's ReferenceId
 func (m *MockSaxoWebSocketServer) SendPortfolioUpdate(balance, marginUsed, marginFree float64) error {
-	// Saxo streaming format has a "Data" array
+	refID := m.findReferenceID("/port/v1/balances/subscriptions")
+	if refID == "" {
+		return fmt.Errorf("no balance subscription found")
+	}
+
 	payloadJSON := map[string]interface{}{
-		"Data": []interface{}{
-			map[string]interface{}{
-				"TotalValue":      balance,
-				"MarginUsed":      marginUsed,
-				"MarginAvailable": marginFree,
-				"Currency":        "USD",
-			},
-		},
+		"TotalValue":                   balance,
+		"MarginUsedByCurrentPositions": marginUsed,
+		"MarginAvailableForTrading":    marginFree,
+		"Currency":                     "USD",
 	}
 
-	binaryMsg, err := m.buildSaxoBinaryMessage("portfolio_balance", payloadJSON)
+	return m.SendDataMessage(refID, payloadJSON)
+}
+
+// SendDataMessage streams an arbitrary JSON payload on referenceID
+func (m *MockSaxoWebSocketServer) SendDataMessage(referenceID string, payloadJSON interface{}) error {
+	binaryMsg, err := m.buildSaxoBinaryMessage(referenceID, payloadJSON)
 	if err != nil {
 		return err
 	}
-
 	return m.broadcastBinaryMessage(binaryMsg)
 }
 
+// handleGenericSubscription handles HTTP POST for endpoints without special behavior
+// (e.g. /port/v1/positions/subscriptions used by custom subscriptions)
+func (m *MockSaxoWebSocketServer) handleGenericSubscription(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var subscriptionReq map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&subscriptionReq); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	referenceID := subscriptionReq["ReferenceId"].(string)
+	arguments, _ := subscriptionReq["Arguments"].(map[string]interface{})
+	m.subscMu.Lock()
+	m.dropReplacedLocked(subscriptionReq)
+	m.subscriptions[referenceID] = MockSubscription{
+		ContextId:   subscriptionReq["ContextId"].(string),
+		ReferenceId: referenceID,
+		Arguments:   arguments,
+		State:       "Active",
+		Endpoint:    r.URL.Path,
+	}
+	m.subscMu.Unlock()
+
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", r.URL.Path, referenceID))
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"State":       "Active",
+		"ReferenceId": referenceID,
+		"Snapshot":    map[string]interface{}{"Data": []interface{}{}},
+	})
+}
+
 // SendHeartbeat sends a heartbeat control message following Saxo protocol
 func (m *MockSaxoWebSocketServer) SendHeartbeat(originatingRefID, reason string) error {
-	payloadJSON := map[string]interface{}{
-		"ReferenceId": "_heartbeat",
-		"Heartbeats": []map[string]interface{}{
-			{
-				"OriginatingReferenceId": originatingRefID,
-				"Reason":                 reason, // "NoNewData", "SubscriptionTemporarilyDisabled", etc.
+	// Saxo sends _heartbeat payloads as an array of messages
+	payloadJSON := []map[string]interface{}{
+		{
+			"ReferenceId": "_heartbeat",
+			"Heartbeats": []map[string]interface{}{
+				{
+					"OriginatingReferenceId": originatingRefID,
+					"Reason":                 reason, // "NoNewData", "SubscriptionTemporarilyDisabled", etc.
+				},
 			},
 		},
 	}
@@ -452,8 +732,18 @@ func (m *MockSaxoWebSocketServer) SendResetSubscriptions(targetRefIDs []string)
 
 // SendDisconnect sends a disconnect control message
 func (m *MockSaxoWebSocketServer) SendDisconnect() error {
+	return m.SendDisconnectWithDelay(0)
+}
+
+// SendDisconnectWithDelay sends a disconnect control message advising a reconnect after delay
+// (whole seconds, 0 = no advice)
+func (m *MockSaxoWebSocketServer) SendDisconnectWithDelay(delay time.Duration) error {
 	payloadJSON := map[string]interface{}{
 		"ReferenceId": "_disconnect",
+		"Reason":      "Maintenance",
+	}
+	if seconds := int(delay / time.Second); seconds > 0 {
+		payloadJSON["ReconnectDelaySeconds"] = seconds
 	}
 
 	binaryMsg, err := m.buildSaxoBinaryMessage("_disconnect", payloadJSON)
@@ -480,14 +770,38 @@ func (m *MockSaxoWebSocketServer) GetActiveSubscriptions() map[string]MockSubscr
 
 // Private helper methods
 
+// findReferenceID returns the ReferenceId of an active subscription on endpoint ("" if none)
+func (m *MockSaxoWebSocketServer) findReferenceID(endpoint string) string {
+	m.subscMu.RLock()
+	defer m.subscMu.RUnlock()
+
+	for refID, sub := range m.subscriptions {
+		if sub.Endpoint == endpoint {
+			return refID
+		}
+	}
+	return ""
+}
+
 // broadcastBinaryMessage sends binary message to all connected test clients
 func (m *MockSaxoWebSocketServer) broadcastBinaryMessage(binaryMsg []byte) error {
-	m.clientsMu.RLock()
-	defer m.clientsMu.RUnlock()
+	return m.broadcastFrame(binaryMsg, binary.LittleEndian.Uint64(binaryMsg[0:8]))
+}
+
+// broadcastFrame sends a frame whose last message has lastID and keeps it for replay
+// Writes are serialized - gorilla connections allow one concurrent writer
+func (m *MockSaxoWebSocketServer) broadcastFrame(frame []byte, lastID uint64) error {
+	m.clientsMu.Lock()
+	defer m.clientsMu.Unlock()
+
+	m.history = append(m.history, sentFrame{lastID: lastID, data: frame})
+	if len(m.history) > maxReplayFrames {
+		m.history = m.history[len(m.history)-maxReplayFrames:]
+	}
 
 	for conn := range m.clients {
 		// Send as binary WebSocket frame (not text/JSON)
-		if err := conn.WriteMessage(websocket.BinaryMessage, binaryMsg); err != nil {
+		if err := conn.WriteMessage(websocket.BinaryMessage, frame); err != nil {
 			return fmt.Errorf("failed to send binary test message: %w", err)
 		}
 	}
@@ -495,7 +809,12 @@ func (m *MockSaxoWebSocketServer) broadcastBinaryMessage(binaryMsg []byte) error
 }
 
 // getUicForTicker returns test UIC mapping following Saxo instrument patterns
+// Numeric tickers are taken as UICs directly (the client subscribes with UIC strings)
 func (m *MockSaxoWebSocketServer) getUicForTicker(ticker string) int {
+	if uic, err := strconv.Atoi(ticker); err == nil {
+		return uic
+	}
+
 	// Test UIC mapping following legacy broker patterns
 	uicMap := map[string]int{
 		"EURUSD": 21,
@@ -0,0 +1,121 @@
+package mocktesting
+
+import (
+	"encoding/binary"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// dialMock connects like the client does, optionally resuming after messageID
+func dialMock(t *testing.T, m *MockSaxoWebSocketServer, messageID uint64) *websocket.Conn {
+	t.Helper()
+	url := strings.Replace(m.GetWebSocketURL(), "https://", "wss://", 1) + "/connect?contextid=ctx1"
+	if messageID > 0 {
+		url += "&messageid=" + strconv.FormatUint(messageID, 10)
+	}
+	dialer := websocket.Dialer{TLSClientConfig: m.GetHTTPClient().Transport.(*http.Transport).TLSClientConfig}
+	conn, _, err := dialer.Dial(url, http.Header{"Authorization": {"Bearer test"}})
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+// readMessageIDs reads one frame and returns the IDs of every message packed in it
+func readMessageIDs(t *testing.T, conn *websocket.Conn) []uint64 {
+	t.Helper()
+	_, frame, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	var ids []uint64
+	for len(frame) > 0 {
+		ids = append(ids, binary.LittleEndian.Uint64(frame[0:8]))
+		refSize := int(frame[10])
+		sizeOffset := 11 + refSize + 1
+		payloadSize := int(binary.LittleEndian.Uint32(frame[sizeOffset : sizeOffset+4]))
+		frame = frame[sizeOffset+4+payloadSize:]
+	}
+	return ids
+}
+
+// waitForClients blocks until n connections are registered for broadcasts
+func waitForClients(t *testing.T, m *MockSaxoWebSocketServer, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		m.clientsMu.RLock()
+		count := len(m.clients)
+		m.clientsMu.RUnlock()
+		if count >= n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Expected %d connected clients", n)
+}
+
+func TestMockServer_BatchAndGap(t *testing.T) {
+	m := NewMockSaxoWebSocketServer()
+	defer m.Close()
+	conn := dialMock(t, m, 0)
+	waitForClients(t, m, 1)
+
+	if err := m.SendBatch(
+		MockMessage{ReferenceID: "ref1", Payload: []int{1}},
+		MockMessage{ReferenceID: "ref2", Payload: []int{2}},
+	); err != nil {
+		t.Fatalf("SendBatch failed: %v", err)
+	}
+	ids := readMessageIDs(t, conn)
+	if len(ids) != 2 || ids[1] != ids[0]+1 {
+		t.Fatalf("Expected 2 consecutive messages in one frame, got %v", ids)
+	}
+
+	m.SkipMessageIDs(5)
+	if err := m.SendDataMessage("ref1", []int{3}); err != nil {
+		t.Fatalf("SendDataMessage failed: %v", err)
+	}
+	if next := readMessageIDs(t, conn); next[0] != ids[1]+6 {
+		t.Errorf("Expected gap to ID %d, got %v", ids[1]+6, next)
+	}
+}
+
+func TestMockServer_ReconnectReplaysAfterMessageID(t *testing.T) {
+	m := NewMockSaxoWebSocketServer()
+	defer m.Close()
+	first := dialMock(t, m, 0)
+	waitForClients(t, m, 1)
+
+	var sent []uint64
+	for i := 0; i < 3; i++ {
+		if err := m.SendDataMessage("ref1", []int{i}); err != nil {
+			t.Fatalf("SendDataMessage failed: %v", err)
+		}
+		sent = append(sent, readMessageIDs(t, first)[0])
+	}
+
+	// Network drop after the first message was processed - resume from it
+	m.DropConnections()
+	if _, _, err := first.ReadMessage(); err == nil {
+		t.Fatal("Expected dropped connection to fail reads")
+	}
+	resumed := dialMock(t, m, sent[0])
+	for _, want := range sent[1:] {
+		if got := readMessageIDs(t, resumed); got[0] != want {
+			t.Errorf("Expected replayed message %d, got %v", want, got)
+		}
+	}
+
+	connections := m.Connections()
+	if len(connections) != 2 || connections[0].MessageID != 0 || connections[1].MessageID != sent[0] {
+		t.Errorf("Unexpected connections %+v", connections)
+	}
+}
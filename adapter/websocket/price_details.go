@@ -0,0 +1,178 @@
+package websocket
+
+import (
+	"sync"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// Price subscription field groups (Arguments.FieldGroups of /trade/v1/infoprices/subscriptions)
+const (
+	PriceFieldGroupQuote                  = "Quote"
+	PriceFieldGroupInstrumentPriceDetails = "InstrumentPriceDetails" // Open interest, last traded, market open
+	PriceFieldGroupPriceInfo              = "PriceInfo"              // High, low, net change
+	PriceFieldGroupPriceInfoDetails       = "PriceInfoDetails"       // Open, volume, last close
+	PriceFieldGroupCommissions            = "Commissions"            // Needs Amount
+)
+
+// PriceSubscriptionOptions are extra arguments of the price subscription for one asset type
+type PriceSubscriptionOptions struct {
+	// FieldGroups beyond Quote, e.g. PriceFieldGroupInstrumentPriceDetails for open interest
+	// Quote is always requested. The values arrive in PriceUpdate.Details
+	FieldGroups []string
+	// Amount prices the quote (and commissions) for this order size instead of the default, 0 = default
+	// Matters for futures and for FX where spreads widen with size
+	Amount float64
+}
+
+// SetPriceSubscriptionOptions sets the extra arguments for assetType's price subscription
+// Applied on the next subscribe of that asset type (SubscribeToPrices, Subscribe), and kept across resubscribes
+func (ws *SaxoWebSocketClient) SetPriceSubscriptionOptions(assetType string, opts PriceSubscriptionOptions) {
+	ws.priceOptionsMu.Lock()
+	defer ws.priceOptionsMu.Unlock()
+	ws.priceOptions[assetType] = opts
+}
+
+// priceArguments adds the configured FieldGroups and Amount for assetType to arguments
+func (ws *SaxoWebSocketClient) priceArguments(assetType string, arguments map[string]interface{}) {
+	ws.priceOptionsMu.RLock()
+	opts, ok := ws.priceOptions[assetType]
+	ws.priceOptionsMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	if len(opts.FieldGroups) > 0 {
+		groups := []string{PriceFieldGroupQuote}
+		for _, group := range opts.FieldGroups {
+			if group != PriceFieldGroupQuote {
+				groups = append(groups, group)
+			}
+		}
+		arguments["FieldGroups"] = groups
+	}
+	if opts.Amount > 0 {
+		arguments["Amount"] = opts.Amount
+	}
+}
+
+// Optional field groups of StreamingPriceUpdate - pointers, because deltas only carry changed fields
+
+// StreamingInstrumentPriceDetails is the InstrumentPriceDetails field group
+type StreamingInstrumentPriceDetails struct {
+	OpenInterest   *float64 `json:"OpenInterest"`
+	LastTraded     *float64 `json:"LastTraded"`
+	LastTradedSize *float64 `json:"LastTradedSize"`
+	LastClose      *float64 `json:"LastClose"`
+	IsMarketOpen   *bool    `json:"IsMarketOpen"`
+}
+
+// StreamingPriceInfo is the PriceInfo field group
+type StreamingPriceInfo struct {
+	High          *float64 `json:"High"`
+	Low           *float64 `json:"Low"`
+	NetChange     *float64 `json:"NetChange"`
+	PercentChange *float64 `json:"PercentChange"`
+}
+
+// StreamingPriceInfoDetails is the PriceInfoDetails field group
+type StreamingPriceInfoDetails struct {
+	Open           *float64 `json:"Open"`
+	Volume         *float64 `json:"Volume"`
+	LastClose      *float64 `json:"LastClose"`
+	LastTraded     *float64 `json:"LastTraded"`
+	LastTradedSize *float64 `json:"LastTradedSize"`
+}
+
+// StreamingCommissions is the Commissions field group
+type StreamingCommissions struct {
+	CostBuy  *float64 `json:"CostBuy"`
+	CostSell *float64 `json:"CostSell"`
+}
+
+// hasDetails reports whether the update carries any optional field group
+func (u StreamingPriceUpdate) hasDetails() bool {
+	return u.InstrumentPriceDetails != nil || u.PriceInfo != nil || u.PriceInfoDetails != nil || u.Commissions != nil
+}
+
+// priceDetailsState merges price deltas per UIC for subscriptions with extra field groups
+// Quote-only UICs are never tracked, so plain price streams keep their pass-through behaviour
+type priceDetailsState struct {
+	mu    sync.Mutex
+	byUic map[int]*mergedPrice
+}
+
+type mergedPrice struct {
+	quote   PriceQuote
+	details saxo.PriceDetails
+}
+
+func newPriceDetailsState() *priceDetailsState {
+	return &priceDetailsState{byUic: make(map[int]*mergedPrice)}
+}
+
+// apply merges update into the UIC's state and returns the merged quote and details
+// details is nil for UICs that never carried a field group
+func (s *priceDetailsState) apply(update StreamingPriceUpdate) (PriceQuote, *saxo.PriceDetails) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	merged, tracked := s.byUic[update.Uic]
+	if !tracked {
+		if !update.hasDetails() {
+			return update.Quote, nil
+		}
+		merged = &mergedPrice{}
+		s.byUic[update.Uic] = merged
+	}
+
+	// Zero quote fields are absent from the delta, not a zero price
+	setIfNonZero(&merged.quote.Bid, update.Quote.Bid)
+	setIfNonZero(&merged.quote.Ask, update.Quote.Ask)
+	setIfNonZero(&merged.quote.Mid, update.Quote.Mid)
+	setIfNonZero(&merged.quote.BidSize, update.Quote.BidSize)
+	setIfNonZero(&merged.quote.AskSize, update.Quote.AskSize)
+
+	d := &merged.details
+	if ipd := update.InstrumentPriceDetails; ipd != nil {
+		setIfPresent(&d.OpenInterest, ipd.OpenInterest)
+		setIfPresent(&d.LastTraded, ipd.LastTraded)
+		setIfPresent(&d.LastTradedSize, ipd.LastTradedSize)
+		setIfPresent(&d.LastClose, ipd.LastClose)
+		if ipd.IsMarketOpen != nil {
+			d.IsMarketOpen = *ipd.IsMarketOpen
+		}
+	}
+	if info := update.PriceInfo; info != nil {
+		setIfPresent(&d.High, info.High)
+		setIfPresent(&d.Low, info.Low)
+		setIfPresent(&d.NetChange, info.NetChange)
+		setIfPresent(&d.PercentChange, info.PercentChange)
+	}
+	if info := update.PriceInfoDetails; info != nil {
+		setIfPresent(&d.Open, info.Open)
+		setIfPresent(&d.Volume, info.Volume)
+		setIfPresent(&d.LastClose, info.LastClose)
+		setIfPresent(&d.LastTraded, info.LastTraded)
+		setIfPresent(&d.LastTradedSize, info.LastTradedSize)
+	}
+	if commissions := update.Commissions; commissions != nil {
+		setIfPresent(&d.CommissionBuy, commissions.CostBuy)
+		setIfPresent(&d.CommissionSell, commissions.CostSell)
+	}
+
+	details := merged.details
+	return merged.quote, &details
+}
+
+func setIfNonZero(field *float64, value float64) {
+	if value != 0 {
+		*field = value
+	}
+}
+
+func setIfPresent(field *float64, value *float64) {
+	if value != nil {
+		*field = *value
+	}
+}
@@ -0,0 +1,157 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+// priceBenchClient returns a client with a price subscription for uics
+func priceBenchClient(b *testing.B, uics int) (*SaxoWebSocketClient, string) {
+	b.Helper()
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	b.Cleanup(mockServer.Close)
+	mockAuth := &MockAuthClient{authenticated: true, accessToken: "token", httpClient: mockServer.GetHTTPClient()}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+	if err := client.Connect(context.Background()); err != nil {
+		b.Fatalf("Failed to connect: %v", err)
+	}
+	b.Cleanup(func() { client.Close() })
+
+	tickers := make([]string, uics)
+	for i := range tickers {
+		tickers[i] = strconv.Itoa(i + 1)
+	}
+	if err := client.SubscribeToPrices(context.Background(), tickers, "FxSpot"); err != nil {
+		b.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+	referenceID := ""
+	for refID, key := range client.subscriptionManager.subscriptionKeysByReferenceId() {
+		if key == "price_feed_FxSpot" {
+			referenceID = refID
+		}
+	}
+	if referenceID == "" {
+		b.Fatal("No price subscription")
+	}
+	return client, referenceID
+}
+
+// priceFrame encodes one price message carrying a delta for each of uics
+func priceFrame(referenceID string, uics int) []byte {
+	updates := make([]string, uics)
+	for i := range updates {
+		updates[i] = fmt.Sprintf(`{"Uic":%d,"LastUpdated":"2026-10-16T09:30:00.123Z","Quote":{"Bid":1.1%03d,"Ask":1.2%03d,"Mid":1.15}}`, i+1, i, i)
+	}
+	return encodeTestMessage(1, referenceID, "["+strings.Join(updates, ",")+"]")
+}
+
+func BenchmarkProcessMessage_Price(b *testing.B) {
+	for _, uics := range []int{1, 50} {
+		b.Run(strconv.Itoa(uics), func(b *testing.B) {
+			client, referenceID := priceBenchClient(b, uics)
+			frame := priceFrame(referenceID, uics)
+			updates := client.GetPriceUpdateChannel()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := client.messageHandler.ProcessMessage(frame); err != nil {
+					b.Fatal(err)
+				}
+				// Drain like a synchronous consumer - a full channel would measure the drop path
+				for len(updates) > 0 {
+					<-updates
+				}
+			}
+		})
+	}
+}
+
+func TestHandlePriceUpdate_PooledBatchIsCleared(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoWebSocketClient(&MockAuthClient{authenticated: true, accessToken: "token"}, "http://localhost", "ws://localhost", logger)
+	if err := client.SubscribeToPrices(context.Background(), []string{"21", "31"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+
+	full := []byte(`[{"Uic":21,"LastUpdated":"2026-10-16T09:30:00Z","Quote":{"Bid":1.1,"Ask":1.2,"Mid":1.15}}]`)
+	if err := client.messageHandler.handlePriceUpdate(full); err != nil {
+		t.Fatalf("handlePriceUpdate failed: %v", err)
+	}
+	<-client.GetPriceUpdateChannel()
+
+	// A delta for another UIC must not inherit fields decoded into the reused batch
+	delta := []byte(`[{"Uic":31,"Quote":{"Bid":1.3}}]`)
+	if err := client.messageHandler.handlePriceUpdate(delta); err != nil {
+		t.Fatalf("handlePriceUpdate failed: %v", err)
+	}
+	update := <-client.GetPriceUpdateChannel()
+	if update.Uic != 31 || update.Ask != 0 || update.Mid != 0 || !update.ExchangeTime.IsZero() {
+		t.Errorf("Expected only Bid on the delta, got %+v", update)
+	}
+}
+
+func TestSaxoWebSocketClient_CustomHandlerKeepsPayload(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	// The handler keeps the slices - frame buffers are reused after it returns
+	received := make(chan []byte, 10)
+	_, err := client.RegisterSubscription(ctx, "positions", "/port/v1/positions/subscriptions", nil,
+		func(referenceID string, payload []byte) error {
+			received <- payload
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("RegisterSubscription failed: %v", err)
+	}
+	var referenceID string
+	for refID, sub := range mockServer.GetActiveSubscriptions() {
+		if sub.Endpoint == "/port/v1/positions/subscriptions" {
+			referenceID = refID
+		}
+	}
+
+	var kept [][]byte
+	for i := 0; i < 5; i++ {
+		if err := mockServer.SendDataMessage(referenceID, []map[string]interface{}{{"PositionId": fmt.Sprintf("p%d", i)}}); err != nil {
+			t.Fatalf("SendDataMessage failed: %v", err)
+		}
+		select {
+		case payload := <-received:
+			kept = append(kept, payload)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Custom handler not called")
+		}
+	}
+	for i, payload := range kept {
+		if want := fmt.Sprintf(`[{"PositionId":"p%d"}]`, i); string(payload) != want {
+			t.Errorf("Kept payload %d changed: got %s, want %s", i, payload, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package websocket
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// priceSampler limits a price stream to one update per UIC per interval, latest wins
+// The first update of a UIC after a quiet interval passes straight through (offer returns true);
+// later ones wait in pending, replacing each other, and a flush goroutine emits them once the
+// UIC's interval has passed
+type priceSampler struct {
+	interval time.Duration
+	clock    saxo.Clock
+	emit     func(saxo.PriceUpdate) // Delivers flushed updates - called without mu held
+
+	mu       sync.Mutex
+	lastSent map[int]time.Time
+	pending  map[int]saxo.PriceUpdate
+
+	signal   chan struct{} // Wakes the flush goroutine, buffer 1
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+
+	sampled atomic.Uint64 // Updates replaced by a newer one before delivery
+}
+
+func newPriceSampler(interval time.Duration, clock saxo.Clock, emit func(saxo.PriceUpdate)) *priceSampler {
+	s := &priceSampler{
+		interval: interval,
+		clock:    clock,
+		emit:     emit,
+		lastSent: make(map[int]time.Time),
+		pending:  make(map[int]saxo.PriceUpdate),
+		signal:   make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// offer takes an update and reports whether the caller should deliver it now
+// false = held back (or replaced a held-back update); the flush goroutine delivers it later
+func (s *priceSampler) offer(update saxo.PriceUpdate) bool {
+	now := s.clock.Now()
+	s.mu.Lock()
+	if _, held := s.pending[update.Uic]; held {
+		s.pending[update.Uic] = update
+		s.mu.Unlock()
+		s.sampled.Add(1)
+		return false
+	}
+	if last, ok := s.lastSent[update.Uic]; !ok || now.Sub(last) >= s.interval {
+		s.lastSent[update.Uic] = now
+		s.mu.Unlock()
+		return true
+	}
+	s.pending[update.Uic] = update
+	s.mu.Unlock()
+
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+	return false
+}
+
+// run emits held-back updates as their intervals pass, until Stop
+func (s *priceSampler) run() {
+	defer close(s.done)
+	for {
+		var due <-chan time.Time
+		if wait := s.flush(); wait > 0 {
+			due = s.clock.After(wait)
+		}
+		select {
+		case <-due:
+		case <-s.signal:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// flush emits the pending updates that are due and returns the wait until the next one (0 = none)
+func (s *priceSampler) flush() time.Duration {
+	now := s.clock.Now()
+	var ready []saxo.PriceUpdate
+	var wait time.Duration
+
+	s.mu.Lock()
+	for uic, update := range s.pending {
+		remaining := s.lastSent[uic].Add(s.interval).Sub(now)
+		if remaining <= 0 {
+			ready = append(ready, update)
+			s.lastSent[uic] = now
+			delete(s.pending, uic)
+			continue
+		}
+		if wait == 0 || remaining < wait {
+			wait = remaining
+		}
+	}
+	s.mu.Unlock()
+
+	for _, update := range ready {
+		s.emit(update)
+	}
+	return wait
+}
+
+// Stop ends the flush goroutine; held-back updates are discarded. Idempotent
+// Must not be called while holding a lock emit takes
+func (s *priceSampler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+}
+
+// SetThrottle limits this handle to at most one update per instrument per interval, latest wins,
+// whatever the Saxo refresh rate. Updates in between are replaced (counted in Sampled), not queued.
+// interval <= 0 delivers every update (default)
+func (ps *PriceSubscription) SetThrottle(interval time.Duration) {
+	var sampler *priceSampler
+	if interval > 0 {
+		sampler = newPriceSampler(interval, ps.client.clock, func(update saxo.PriceUpdate) {
+			ps.client.priceRouter.deliverTo(ps, update)
+		})
+	}
+	if previous := ps.sampler.Swap(sampler); previous != nil {
+		previous.Stop()
+		ps.sampledTotal.Add(previous.sampled.Load())
+	}
+}
+
+// Sampled returns the number of updates this handle's throttle replaced before delivery
+func (ps *PriceSubscription) Sampled() uint64 {
+	if sampler := ps.sampler.Load(); sampler != nil {
+		return ps.sampledTotal.Load() + sampler.sampled.Load()
+	}
+	return ps.sampledTotal.Load()
+}
+
+// SetPriceThrottle limits the shared price stream (GetPriceUpdateChannel, or Events) to at most one
+// update per instrument per interval, latest wins. Subscribe handles have their own SetThrottle.
+// interval <= 0 delivers every update (default)
+func (ws *SaxoWebSocketClient) SetPriceThrottle(interval time.Duration) {
+	var sampler *priceSampler
+	if interval > 0 {
+		sampler = newPriceSampler(interval, ws.clock, ws.messageHandler.publishShared)
+	}
+	if previous := ws.priceSampler.Swap(sampler); previous != nil {
+		previous.Stop()
+		ws.priceSampled.Add(previous.sampled.Load())
+	}
+
+	ws.logger.Info("Price throttle set",
+		"function", "SetPriceThrottle",
+		"interval", interval)
+}
+
+// SampledPriceUpdates returns the number of shared-stream updates the throttle replaced before delivery
+func (ws *SaxoWebSocketClient) SampledPriceUpdates() uint64 {
+	total := ws.priceSampled.Load()
+	if sampler := ws.priceSampler.Load(); sampler != nil {
+		total += sampler.sampled.Load()
+	}
+	return total
+}
@@ -0,0 +1,125 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+// throttleTestClient returns an unconnected client on a fake clock with UICs 21 and 31 subscribed
+func throttleTestClient(t *testing.T) (*SaxoWebSocketClient, *mocktesting.FakeClock) {
+	t.Helper()
+	clock := mocktesting.NewFakeClock(time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC))
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoWebSocketClient(&MockAuthClient{authenticated: true, accessToken: "token"}, "http://localhost", "ws://localhost", logger, saxo.WithClock(clock))
+	if err := client.SubscribeToPrices(context.Background(), []string{"21", "31"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+	return client, clock
+}
+
+// sendQuote feeds one price message for uic through the message handler
+func sendQuote(t *testing.T, client *SaxoWebSocketClient, uic int, bid float64) {
+	t.Helper()
+	payload := fmt.Sprintf(`[{"Uic":%d,"Quote":{"Bid":%g,"Ask":%g,"Mid":%g}}]`, uic, bid, bid+0.0002, bid+0.0001)
+	if err := client.messageHandler.handlePriceUpdate([]byte(payload)); err != nil {
+		t.Fatalf("handlePriceUpdate failed: %v", err)
+	}
+}
+
+// expectQuote reads the next update from updates and checks its UIC and bid
+func expectQuote(t *testing.T, updates <-chan saxo.PriceUpdate, uic int, bid float64) {
+	t.Helper()
+	select {
+	case update := <-updates:
+		if update.Uic != uic || update.Bid != bid {
+			t.Fatalf("Expected UIC %d at %g, got %+v", uic, bid, update)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("No update for UIC %d", uic)
+	}
+}
+
+func TestSaxoWebSocketClient_PriceThrottle(t *testing.T) {
+	client, clock := throttleTestClient(t)
+	client.SetPriceThrottle(time.Second)
+	defer client.SetPriceThrottle(0)
+	updates := client.GetPriceUpdateChannel()
+
+	// First update passes, the next ones for the UIC are held back - latest wins
+	sendQuote(t, client, 21, 1.1)
+	expectQuote(t, updates, 21, 1.1)
+	sendQuote(t, client, 21, 1.2)
+	sendQuote(t, client, 21, 1.3)
+	if len(updates) != 0 {
+		t.Fatalf("Expected held-back updates, got %d on the channel", len(updates))
+	}
+
+	// Other instruments have their own interval
+	sendQuote(t, client, 31, 1.5)
+	expectQuote(t, updates, 31, 1.5)
+
+	if err := clock.WaitForWaiters(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Second)
+	expectQuote(t, updates, 21, 1.3)
+	if got := client.SampledPriceUpdates(); got != 1 {
+		t.Errorf("Expected 1 sampled update, got %d", got)
+	}
+	if got := client.GetChannelStats()["priceUpdatesSampled"]; got != 1 {
+		t.Errorf("Expected priceUpdatesSampled 1, got %d", got)
+	}
+
+	// Disabled - every update is delivered
+	client.SetPriceThrottle(0)
+	sendQuote(t, client, 21, 1.4)
+	sendQuote(t, client, 21, 1.5)
+	expectQuote(t, updates, 21, 1.4)
+	expectQuote(t, updates, 21, 1.5)
+}
+
+func TestPriceSubscription_SetThrottle(t *testing.T) {
+	client, clock := throttleTestClient(t)
+	handle, err := client.Subscribe(context.Background(), []string{"21"}, "FxSpot", 10)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	handle.SetThrottle(500 * time.Millisecond)
+
+	sendQuote(t, client, 21, 1.1)
+	expectQuote(t, handle.Updates(), 21, 1.1)
+	sendQuote(t, client, 21, 1.2)
+	sendQuote(t, client, 21, 1.3)
+	sendQuote(t, client, 21, 1.4)
+
+	// The shared channel is not throttled
+	for _, bid := range []float64{1.1, 1.2, 1.3, 1.4} {
+		expectQuote(t, client.GetPriceUpdateChannel(), 21, bid)
+	}
+
+	if err := clock.WaitForWaiters(1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(500 * time.Millisecond)
+	expectQuote(t, handle.Updates(), 21, 1.4)
+	if got := handle.Sampled(); got != 2 {
+		t.Errorf("Expected 2 sampled updates, got %d", got)
+	}
+
+	// A held-back update is discarded with the handle
+	sendQuote(t, client, 21, 1.5)
+	if err := handle.Unsubscribe(context.Background()); err != nil {
+		t.Fatalf("Unsubscribe failed: %v", err)
+	}
+	clock.Advance(time.Second)
+	if _, open := <-handle.Updates(); open {
+		t.Error("Expected the channel closed without the held-back update")
+	}
+}
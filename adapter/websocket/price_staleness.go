@@ -0,0 +1,119 @@
+package websocket
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StaleInstrument is a subscribed instrument whose prices stopped while its subscription stays alive
+type StaleInstrument struct {
+	Uic         int
+	AssetType   string
+	ReferenceID string        // Price subscription carrying the instrument
+	LastUpdate  time.Time     // Last quote or delta for the UIC, zero if none since subscribing
+	Silence     time.Duration // Since LastUpdate, or since the subscription was created
+}
+
+// instrumentActivity tracks the last price message per UIC and AssetType
+// lastMessageTimestamps is per ReferenceId, so one busy instrument hides a dead one in the same subscription.
+// A UIC is only unique within its AssetType (e.g. an FxSpot and an FxForwards quote share it)
+type instrumentActivity struct {
+	mu         sync.Mutex
+	lastUpdate map[instrumentKey]time.Time
+}
+
+type instrumentKey struct {
+	uic       int
+	assetType string
+}
+
+func newInstrumentActivity() *instrumentActivity {
+	return &instrumentActivity{lastUpdate: make(map[instrumentKey]time.Time)}
+}
+
+func (ia *instrumentActivity) record(uic int, assetType string, now time.Time) {
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+	ia.lastUpdate[instrumentKey{uic, assetType}] = now
+}
+
+func (ia *instrumentActivity) last(uic int, assetType string) time.Time {
+	ia.mu.Lock()
+	defer ia.mu.Unlock()
+	return ia.lastUpdate[instrumentKey{uic, assetType}]
+}
+
+// subscribedPriceInstrument is one UIC of a tracked price subscription
+type subscribedPriceInstrument struct {
+	uic          int
+	assetType    string
+	referenceID  string
+	subscribedAt time.Time
+}
+
+// priceInstruments lists the UICs of every tracked price subscription
+func (sm *SubscriptionManager) priceInstruments() []subscribedPriceInstrument {
+	sm.subscriptionMu.RLock()
+	defer sm.subscriptionMu.RUnlock()
+
+	var instruments []subscribedPriceInstrument
+	for _, sub := range sm.subscriptions {
+		if sub.EndpointPath != EndpointPrices {
+			continue
+		}
+		uics, _ := sub.Arguments["Uics"].(string)
+		assetType, _ := sub.Arguments["AssetType"].(string)
+		for _, field := range strings.Split(uics, ",") {
+			uic, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				continue
+			}
+			instruments = append(instruments, subscribedPriceInstrument{
+				uic:          uic,
+				assetType:    assetType,
+				referenceID:  sub.ReferenceId,
+				subscribedAt: sub.SubscribedAt,
+			})
+		}
+	}
+	return instruments
+}
+
+// StaleInstruments returns subscribed instruments without a price update for longer than threshold
+// Instruments that never updated count from their subscription time. Subscriptions Saxo paused
+// (SubscriptionTemporarilyDisabled, recovery in progress) are skipped. Sorted by UIC, then AssetType
+// Use it to resubscribe or stop trading a single dead feed; closed markets also go quiet, so
+// combine with IsMarketOpen before acting
+func (ws *SaxoWebSocketClient) StaleInstruments(threshold time.Duration) []StaleInstrument {
+	now := ws.clock.Now()
+	var stale []StaleInstrument
+	for _, instrument := range ws.subscriptionManager.priceInstruments() {
+		if ws.subscriptionHealth.paused(instrument.referenceID) {
+			continue
+		}
+		last := ws.instrumentActivity.last(instrument.uic, instrument.assetType)
+		since := instrument.subscribedAt
+		if last.After(since) {
+			since = last
+		}
+		if silence := now.Sub(since); silence > threshold {
+			stale = append(stale, StaleInstrument{
+				Uic:         instrument.uic,
+				AssetType:   instrument.assetType,
+				ReferenceID: instrument.referenceID,
+				LastUpdate:  last,
+				Silence:     silence,
+			})
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		if stale[i].Uic != stale[j].Uic {
+			return stale[i].Uic < stale[j].Uic
+		}
+		return stale[i].AssetType < stale[j].AssetType
+	})
+	return stale
+}
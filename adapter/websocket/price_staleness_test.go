@@ -0,0 +1,83 @@
+package websocket
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestStaleInstruments_OneDeadFeedInsideActiveSubscription(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	clock := mocktesting.NewFakeClock(time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC))
+	client := newReconnectTestClient(t, mockServer, saxo.WithClock(clock))
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+	if err := client.SubscribeToPrices(ctx, []string{"21", "31"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+	if stale := client.StaleInstruments(time.Second); len(stale) != 0 {
+		t.Fatalf("Expected nothing stale right after subscribing, got %+v", stale)
+	}
+
+	// UIC 21 keeps quoting, UIC 31 goes silent - the subscription itself stays active
+	clock.Advance(30 * time.Second)
+	if err := mockServer.SendPriceDelta(21, map[string]interface{}{"Bid": 1.1001}); err != nil {
+		t.Fatalf("SendPriceDelta failed: %v", err)
+	}
+	if !waitFor(t, 2*time.Second, func() bool { return client.instrumentActivity.last(21, "FxSpot").Equal(clock.Now()) }) {
+		t.Fatal("Price delta for UIC 21 not recorded")
+	}
+	clock.Advance(30 * time.Second)
+
+	stale := client.StaleInstruments(45 * time.Second)
+	if len(stale) != 1 || stale[0].Uic != 31 || stale[0].AssetType != "FxSpot" {
+		t.Fatalf("Expected only UIC 31 stale, got %+v", stale)
+	}
+	if stale[0].Silence != 60*time.Second {
+		t.Errorf("Silence = %v, want 60s", stale[0].Silence)
+	}
+	if stale := client.StaleInstruments(time.Minute); len(stale) != 0 {
+		t.Errorf("Expected nothing stale at a 1 minute threshold, got %+v", stale)
+	}
+}
+
+func TestStaleInstruments_SameUicInTwoAssetTypes(t *testing.T) {
+	clock := mocktesting.NewFakeClock(time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC))
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoWebSocketClient(&MockAuthClient{authenticated: true}, "https://localhost", "wss://localhost", logger, saxo.WithClock(clock))
+	mh, sm := client.messageHandler, client.subscriptionManager
+	sm.subscriptionMu.Lock()
+	for _, assetType := range []string{"FxSpot", "FxForwards"} {
+		sm.registerLocked("prices_"+assetType, &Subscription{
+			ReferenceId:  assetType + "-prices",
+			EndpointPath: EndpointPrices,
+			Arguments:    map[string]interface{}{"Uics": "21", "AssetType": assetType},
+			SubscribedAt: clock.Now(),
+			timedHandler: mh.routeTimedPriceUpdate,
+		})
+	}
+	sm.subscriptionMu.Unlock()
+
+	// Only the FxSpot quote for UIC 21 ticks
+	clock.Advance(30 * time.Second)
+	payload := []byte(`[{"Uic":21,"Quote":{"Bid":1.1,"Ask":1.2,"Mid":1.15}}]`)
+	if err := mh.routeTimedPriceUpdate("FxSpot-prices", payload, clock.Now()); err != nil {
+		t.Fatalf("routeTimedPriceUpdate failed: %v", err)
+	}
+	clock.Advance(30 * time.Second)
+
+	stale := client.StaleInstruments(45 * time.Second)
+	if len(stale) != 1 || stale[0].Uic != 21 || stale[0].AssetType != "FxForwards" {
+		t.Fatalf("Expected only the FxForwards quote stale, got %+v", stale)
+	}
+}
@@ -0,0 +1,448 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// PriceSubscription is a consumer's handle on a set of instruments, possibly spanning asset types
+// Updates() only carries prices for the handle's UICs. The underlying Saxo subscriptions (one per
+// asset type) are shared and reference-counted per UIC - Unsubscribe only narrows them once no
+// other handle (or SubscribeToPrices) still needs an instrument
+type PriceSubscription struct {
+	id          uint64
+	byAssetType map[string][]int // Sorted UICs per asset type
+	uics        []int            // Sorted union, used for routing
+	updates     chan saxo.PriceUpdate
+	client      *SaxoWebSocketClient
+	dropped     atomic.Uint64
+
+	sampler      atomic.Pointer[priceSampler] // SetThrottle, nil = every update
+	sampledTotal atomic.Uint64                // Sampled by throttles replaced or stopped
+
+	unsubscribeOnce sync.Once
+	unsubscribeErr  error
+}
+
+// Updates returns the handle's price channel, closed by Unsubscribe or client Shutdown
+func (ps *PriceSubscription) Updates() <-chan saxo.PriceUpdate {
+	return ps.updates
+}
+
+// Uics returns the instruments this handle receives
+func (ps *PriceSubscription) Uics() []int {
+	return append([]int(nil), ps.uics...)
+}
+
+// AssetType returns the handle's asset type, or "" when it spans several (see Instruments)
+func (ps *PriceSubscription) AssetType() string {
+	if len(ps.byAssetType) != 1 {
+		return ""
+	}
+	for assetType := range ps.byAssetType {
+		return assetType
+	}
+	return ""
+}
+
+// Instruments returns the handle's instruments ordered by asset type, then UIC
+func (ps *PriceSubscription) Instruments() []saxo.InstrumentRef {
+	var refs []saxo.InstrumentRef
+	for _, assetType := range ps.assetTypes() {
+		for _, uic := range ps.byAssetType[assetType] {
+			refs = append(refs, saxo.InstrumentRef{Uic: uic, AssetType: assetType})
+		}
+	}
+	return refs
+}
+
+// assetTypes returns the handle's asset types, sorted for a stable subscription order
+func (ps *PriceSubscription) assetTypes() []string {
+	types := make([]string, 0, len(ps.byAssetType))
+	for assetType := range ps.byAssetType {
+		types = append(types, assetType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Dropped returns the number of updates discarded because this handle's channel was full
+func (ps *PriceSubscription) Dropped() uint64 {
+	return ps.dropped.Load()
+}
+
+// Unsubscribe releases the handle and closes its channel. Idempotent
+// The Saxo subscription is re-posted without UICs no other consumer needs (deleted when none remain)
+func (ps *PriceSubscription) Unsubscribe(ctx context.Context) error {
+	ps.unsubscribeOnce.Do(func() {
+		ps.unsubscribeErr = ps.client.unsubscribePriceHandle(ctx, ps)
+	})
+	return ps.unsubscribeErr
+}
+
+// priceRouter fans price updates out to PriceSubscription handles
+// Reference counts decide which UICs the shared Saxo subscription per asset type must carry
+type priceRouter struct {
+	// Serializes Subscribe/Unsubscribe/SubscribeToPrices so the Saxo subscription matches the refcounts
+	changeMu sync.Mutex
+
+	mu      sync.RWMutex
+	nextID  uint64
+	handles map[uint64]*PriceSubscription
+	byUic   map[int][]*PriceSubscription
+	shared  map[string][]int // assetType -> UICs requested via SubscribeToPrices (shared channel)
+}
+
+func newPriceRouter() *priceRouter {
+	return &priceRouter{
+		handles: make(map[uint64]*PriceSubscription),
+		byUic:   make(map[int][]*PriceSubscription),
+		shared:  make(map[string][]int),
+	}
+}
+
+// add registers a handle
+func (pr *priceRouter) add(ps *PriceSubscription) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	pr.nextID++
+	ps.id = pr.nextID
+	pr.handles[ps.id] = ps
+	for _, uic := range ps.uics {
+		pr.byUic[uic] = append(pr.byUic[uic], ps)
+	}
+}
+
+// remove unregisters a handle, closes its channel and stops its throttle
+// Closing under the write lock guarantees deliver is not sending to it
+func (pr *priceRouter) remove(ps *PriceSubscription) {
+	if !pr.unregister(ps) {
+		return
+	}
+	// The throttle's flush takes the read lock - stop it only after releasing the write lock
+	if sampler := ps.sampler.Swap(nil); sampler != nil {
+		sampler.Stop()
+		ps.sampledTotal.Add(sampler.sampled.Load())
+	}
+}
+
+// unregister drops a handle from the routing tables and closes its channel, false if not registered
+func (pr *priceRouter) unregister(ps *PriceSubscription) bool {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	if _, ok := pr.handles[ps.id]; !ok {
+		return false
+	}
+	delete(pr.handles, ps.id)
+	for _, uic := range ps.uics {
+		list := pr.byUic[uic]
+		for i, h := range list {
+			if h == ps {
+				list = append(list[:i], list[i+1:]...)
+				break
+			}
+		}
+		if len(list) == 0 {
+			delete(pr.byUic, uic)
+		} else {
+			pr.byUic[uic] = list
+		}
+	}
+	close(ps.updates)
+	return true
+}
+
+// setShared records the UICs of a SubscribeToPrices call (replaces the asset type's previous set)
+func (pr *priceRouter) setShared(assetType string, uics []int) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	pr.shared[assetType] = uics
+}
+
+// uicsFor returns the union of UICs needed for assetType, sorted
+func (pr *priceRouter) uicsFor(assetType string) []int {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+
+	set := make(map[int]bool)
+	for _, uic := range pr.shared[assetType] {
+		set[uic] = true
+	}
+	for _, h := range pr.handles {
+		for _, uic := range h.byAssetType[assetType] {
+			set[uic] = true
+		}
+	}
+
+	uics := make([]int, 0, len(set))
+	for uic := range set {
+		uics = append(uics, uic)
+	}
+	sort.Ints(uics)
+	return uics
+}
+
+// wantsShared reports whether the shared price channel should receive this UIC
+// Without any handles every update goes to the shared channel (legacy behavior)
+func (pr *priceRouter) wantsShared(uic int) bool {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+
+	if len(pr.handles) == 0 {
+		return true
+	}
+	for _, uics := range pr.shared {
+		for _, u := range uics {
+			if u == uic {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deliver sends the update to every handle holding its UIC (non-blocking per handle)
+// Price messages are routed by UIC only - a UIC subscribed under two asset types reaches both handles
+func (pr *priceRouter) deliver(update saxo.PriceUpdate) {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+
+	for _, h := range pr.byUic[update.Uic] {
+		if sampler := h.sampler.Load(); sampler != nil && !sampler.offer(update) {
+			continue
+		}
+		h.send(update)
+	}
+}
+
+// deliverTo sends a throttled update to ps unless it was removed meanwhile
+func (pr *priceRouter) deliverTo(ps *PriceSubscription, update saxo.PriceUpdate) {
+	pr.mu.RLock()
+	defer pr.mu.RUnlock()
+	if pr.handles[ps.id] == ps {
+		ps.send(update)
+	}
+}
+
+// send is the non-blocking delivery to the handle's channel - callers hold the router's read lock
+func (ps *PriceSubscription) send(update saxo.PriceUpdate) {
+	select {
+	case ps.updates <- update:
+	default:
+		ps.dropped.Add(1)
+	}
+}
+
+// closeAll removes every handle - used by Shutdown after producers have exited
+func (pr *priceRouter) closeAll() {
+	pr.mu.RLock()
+	handles := make([]*PriceSubscription, 0, len(pr.handles))
+	for _, h := range pr.handles {
+		handles = append(handles, h)
+	}
+	pr.mu.RUnlock()
+
+	for _, h := range handles {
+		pr.remove(h)
+	}
+}
+
+// Subscribe returns a handle whose channel only carries prices for instruments
+// Multiple handles may overlap - the Saxo subscription for assetType carries the union of all of them
+// bufferSize <= 0 uses the shared price channel's default of 100
+func (ws *SaxoWebSocketClient) Subscribe(ctx context.Context, instruments []string, assetType string, bufferSize int) (*PriceSubscription, error) {
+	uics := ws.subscriptionManager.getUicsForInstruments(instruments)
+	if len(uics) == 0 {
+		return nil, fmt.Errorf("no valid UICs found for instruments")
+	}
+
+	refs := make([]saxo.InstrumentRef, len(uics))
+	for i, uic := range uics {
+		refs[i] = saxo.InstrumentRef{Uic: uic, AssetType: assetType}
+	}
+	return ws.SubscribeInstruments(ctx, refs, bufferSize)
+}
+
+// SubscribeInstruments returns one handle for instruments of mixed asset types
+// Instruments are grouped into one Saxo subscription per asset type; the handle's Updates()
+// merges them and Unsubscribe releases all of them
+func (ws *SaxoWebSocketClient) SubscribeInstruments(ctx context.Context, instruments []saxo.InstrumentRef, bufferSize int) (*PriceSubscription, error) {
+	if ws.isShutdown() {
+		return nil, errClientShutdown
+	}
+
+	byAssetType, uics, err := groupInstruments(instruments)
+	if err != nil {
+		return nil, err
+	}
+
+	if bufferSize <= 0 {
+		bufferSize = cap(ws.priceUpdateChan)
+	}
+
+	handle := &PriceSubscription{
+		byAssetType: byAssetType,
+		uics:        uics,
+		updates:     make(chan saxo.PriceUpdate, bufferSize),
+		client:      ws,
+	}
+
+	ws.priceRouter.changeMu.Lock()
+	defer ws.priceRouter.changeMu.Unlock()
+
+	before := make(map[string][]int, len(byAssetType))
+	for assetType := range byAssetType {
+		before[assetType] = ws.priceRouter.uicsFor(assetType)
+	}
+	ws.priceRouter.add(handle)
+
+	// Only touch the Saxo subscriptions this handle adds new instruments to
+	var changed, deferred []string
+	for _, assetType := range handle.assetTypes() {
+		after := ws.priceRouter.uicsFor(assetType)
+		if equalUics(before[assetType], after) {
+			continue
+		}
+		// Not connected yet - the handle is live, Saxo is subscribed once the context ID exists
+		if ws.deferPriceSync(assetType) {
+			deferred = append(deferred, assetType)
+			continue
+		}
+		body, err := ws.subscriptionManager.SubscribeToInstrumentPricesContext(ctx, uicStrings(after), assetType)
+		if err != nil {
+			ws.priceRouter.remove(handle)
+			ws.restorePriceSubscriptions(ctx, changed)
+			return nil, fmt.Errorf("failed to subscribe to %s prices: %w", assetType, err)
+		}
+		changed = append(changed, assetType)
+		ws.messageHandler.publishPriceSnapshot(body, assetType)
+	}
+
+	ws.logger.Info("Price subscription handle created",
+		"function", "SubscribeInstruments",
+		"handle_id", handle.id,
+		"asset_types", handle.assetTypes(),
+		"uics", uics,
+		"updated_subscriptions", changed,
+		"deferred_subscriptions", deferred)
+	return handle, nil
+}
+
+// restorePriceSubscriptions re-posts asset types widened by a failed SubscribeInstruments
+// Best effort - the refcounts are already correct, a failure only leaves extra UICs streaming
+func (ws *SaxoWebSocketClient) restorePriceSubscriptions(ctx context.Context, assetTypes []string) {
+	for _, assetType := range assetTypes {
+		if err := ws.syncPriceSubscription(ctx, assetType, ws.priceRouter.uicsFor(assetType)); err != nil {
+			ws.logger.Warn("Failed to roll back price subscription",
+				"function", "restorePriceSubscriptions",
+				"asset_type", assetType,
+				"error", err)
+		}
+	}
+}
+
+// syncPriceSubscription re-posts the Saxo subscription for assetType with uics (deletes it when empty)
+func (ws *SaxoWebSocketClient) syncPriceSubscription(ctx context.Context, assetType string, uics []int) error {
+	if len(uics) == 0 {
+		return ws.subscriptionManager.UnsubscribeInstrumentPrices(ctx, assetType)
+	}
+	if _, err := ws.subscriptionManager.SubscribeToInstrumentPricesContext(ctx, uicStrings(uics), assetType); err != nil {
+		return fmt.Errorf("failed to narrow price subscription: %w", err)
+	}
+	return nil
+}
+
+// unsubscribePriceHandle releases a handle and narrows or deletes the Saxo subscription of each asset type
+func (ws *SaxoWebSocketClient) unsubscribePriceHandle(ctx context.Context, handle *PriceSubscription) error {
+	ws.priceRouter.changeMu.Lock()
+	defer ws.priceRouter.changeMu.Unlock()
+
+	assetTypes := handle.assetTypes()
+	before := make(map[string][]int, len(assetTypes))
+	for _, assetType := range assetTypes {
+		before[assetType] = ws.priceRouter.uicsFor(assetType)
+	}
+	ws.priceRouter.remove(handle)
+
+	var errs []error
+	for _, assetType := range assetTypes {
+		after := ws.priceRouter.uicsFor(assetType)
+
+		ws.logger.Info("Price subscription handle released",
+			"function", "Unsubscribe",
+			"handle_id", handle.id,
+			"asset_type", assetType,
+			"remaining_uics", after)
+
+		if equalUics(before[assetType], after) || ws.isShutdown() || ws.deferPriceSync(assetType) {
+			continue
+		}
+		if err := ws.syncPriceSubscription(ctx, assetType, after); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", assetType, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// groupInstruments validates refs and groups them per asset type (sorted, deduplicated)
+func groupInstruments(instruments []saxo.InstrumentRef) (map[string][]int, []int, error) {
+	byAssetType := make(map[string][]int)
+	seen := make(map[saxo.InstrumentRef]bool)
+	union := make(map[int]bool)
+
+	for _, ref := range instruments {
+		if ref.Uic <= 0 || ref.AssetType == "" {
+			return nil, nil, fmt.Errorf("invalid instrument reference %+v: UIC and asset type are required", ref)
+		}
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		byAssetType[ref.AssetType] = append(byAssetType[ref.AssetType], ref.Uic)
+		union[ref.Uic] = true
+	}
+	if len(byAssetType) == 0 {
+		return nil, nil, fmt.Errorf("no instruments to subscribe")
+	}
+
+	for _, uics := range byAssetType {
+		sort.Ints(uics)
+	}
+	uics := make([]int, 0, len(union))
+	for uic := range union {
+		uics = append(uics, uic)
+	}
+	sort.Ints(uics)
+	return byAssetType, uics, nil
+}
+
+// equalUics compares two sorted UIC lists
+func equalUics(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// uicStrings converts UICs to the instrument strings accepted by SubscribeToInstrumentPrices
+func uicStrings(uics []int) []string {
+	out := make([]string, len(uics))
+	for i, uic := range uics {
+		out[i] = strconv.Itoa(uic)
+	}
+	return out
+}
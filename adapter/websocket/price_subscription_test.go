@@ -0,0 +1,328 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+// activePriceUics returns the Uics argument of the mock server's only price subscription ("" if none)
+func activePriceUics(t *testing.T, mockServer *mocktesting.MockSaxoWebSocketServer) string {
+	t.Helper()
+	var uics []string
+	for _, sub := range mockServer.GetActiveSubscriptions() {
+		if _, ok := sub.Arguments["Uics"]; ok {
+			uics = append(uics, sub.Arguments["Uics"].(string))
+		}
+	}
+	if len(uics) > 1 {
+		t.Fatalf("Expected at most 1 price subscription, got %d: %v", len(uics), uics)
+	}
+	if len(uics) == 0 {
+		return ""
+	}
+	return uics[0]
+}
+
+func TestSaxoWebSocketClient_PriceSubscriptionHandles(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	eurusd, err := client.Subscribe(ctx, []string{"21", "31"}, "FxSpot", 10)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	eurusdOnly, err := client.Subscribe(ctx, []string{"21"}, "FxSpot", 10)
+	if err != nil {
+		t.Fatalf("Second Subscribe failed: %v", err)
+	}
+	if got := activePriceUics(t, mockServer); got != "21,31" {
+		t.Errorf("Expected Saxo subscription for 21,31, got %q", got)
+	}
+
+	// Each handle only sees its own instruments
+	payload := []byte(`[{"Uic":21,"Quote":{"Bid":1.1,"Ask":1.2,"Mid":1.15}},{"Uic":31,"Quote":{"Bid":1.3,"Ask":1.4,"Mid":1.35}}]`)
+	if err := client.messageHandler.handlePriceUpdate(payload); err != nil {
+		t.Fatalf("handlePriceUpdate failed: %v", err)
+	}
+	if got := len(eurusd.Updates()); got != 2 {
+		t.Errorf("Expected 2 updates on first handle, got %d", got)
+	}
+	if got := len(eurusdOnly.Updates()); got != 1 {
+		t.Errorf("Expected 1 update on second handle, got %d", got)
+	}
+	if got := len(client.GetPriceUpdateChannel()); got != 0 {
+		t.Errorf("Expected shared channel untouched by handle-only consumers, got %d", got)
+	}
+
+	// Releasing one handle keeps the UIC the other still uses
+	if err := eurusd.Unsubscribe(ctx); err != nil {
+		t.Fatalf("Unsubscribe failed: %v", err)
+	}
+	if got := activePriceUics(t, mockServer); got != "21" {
+		t.Errorf("Expected Saxo subscription narrowed to 21, got %q", got)
+	}
+	for range eurusd.Updates() {
+		// Drain buffered updates - loop ends once the channel is closed
+	}
+	if err := eurusd.Unsubscribe(ctx); err != nil {
+		t.Errorf("Second Unsubscribe failed: %v", err)
+	}
+
+	// Last handle gone - Saxo subscription deleted
+	if err := eurusdOnly.Unsubscribe(ctx); err != nil {
+		t.Fatalf("Unsubscribe failed: %v", err)
+	}
+	if got := activePriceUics(t, mockServer); got != "" {
+		t.Errorf("Expected no price subscription, got %q", got)
+	}
+}
+
+// activePriceUicsByAssetType returns the Uics argument of each mock price subscription keyed by asset type
+func activePriceUicsByAssetType(mockServer *mocktesting.MockSaxoWebSocketServer) map[string]string {
+	result := make(map[string]string)
+	for _, sub := range mockServer.GetActiveSubscriptions() {
+		if uics, ok := sub.Arguments["Uics"].(string); ok {
+			result[sub.Arguments["AssetType"].(string)] = uics
+		}
+	}
+	return result
+}
+
+func TestSaxoWebSocketClient_SubscribeInstrumentsMixedAssetTypes(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.SubscribeInstruments(ctx, []saxo.InstrumentRef{{Uic: 21}}, 0); err == nil {
+		t.Error("Expected error for instrument without asset type")
+	}
+
+	handle, err := client.SubscribeInstruments(ctx, []saxo.InstrumentRef{
+		{Uic: 31, AssetType: "FxSpot"},
+		{Uic: 4500, AssetType: "ContractFutures"},
+		{Uic: 21, AssetType: "FxSpot"},
+		{Uic: 21, AssetType: "FxSpot"}, // duplicate ignored
+	}, 10)
+	if err != nil {
+		t.Fatalf("SubscribeInstruments failed: %v", err)
+	}
+
+	subs := activePriceUicsByAssetType(mockServer)
+	if subs["FxSpot"] != "21,31" || subs["ContractFutures"] != "4500" {
+		t.Errorf("Expected one Saxo subscription per asset type, got %v", subs)
+	}
+	if handle.AssetType() != "" || len(handle.Instruments()) != 3 {
+		t.Errorf("Expected mixed handle with 3 instruments, got %q %v", handle.AssetType(), handle.Instruments())
+	}
+
+	payload := []byte(`[{"Uic":21,"Quote":{"Bid":1.1,"Ask":1.2,"Mid":1.15}},{"Uic":4500,"Quote":{"Bid":5000,"Ask":5001,"Mid":5000.5}}]`)
+	if err := client.messageHandler.handlePriceUpdate(payload); err != nil {
+		t.Fatalf("handlePriceUpdate failed: %v", err)
+	}
+	if got := len(handle.Updates()); got != 2 {
+		t.Errorf("Expected updates from both asset types on one handle, got %d", got)
+	}
+
+	if err := handle.Unsubscribe(ctx); err != nil {
+		t.Fatalf("Unsubscribe failed: %v", err)
+	}
+	if subs := activePriceUicsByAssetType(mockServer); len(subs) != 0 {
+		t.Errorf("Expected all price subscriptions deleted, got %v", subs)
+	}
+}
+
+func TestSaxoWebSocketClient_PriceSubscriptionSnapshot(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+	mockServer.SetSnapshotQuote(21, 1.1000, 1.1002)
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	// UIC 31 has no snapshot quote - only 21 is published before any delta arrives
+	if err := client.SubscribeToPrices(ctx, []string{"21", "31"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+	select {
+	case update := <-client.GetPriceUpdateChannel():
+		if !update.Snapshot || update.Uic != 21 || update.Bid != 1.1000 || update.Ask != 1.1002 {
+			t.Errorf("Expected snapshot quote for UIC 21, got %+v", update)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Snapshot not published on the price channel")
+	}
+	if got := len(client.GetPriceUpdateChannel()); got != 0 {
+		t.Errorf("Expected only the quoted UIC in the snapshot, got %d more", got)
+	}
+
+	// Subscribe handles get the snapshot for instruments that widen the Saxo subscription
+	mockServer.SetSnapshotQuote(22, 0.8600, 0.8602)
+	handle, err := client.Subscribe(ctx, []string{"22"}, "FxSpot", 10)
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	select {
+	case update := <-handle.Updates():
+		if !update.Snapshot || update.Uic != 22 {
+			t.Errorf("Expected snapshot quote on handle, got %+v", update)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Snapshot not delivered to Subscribe handle")
+	}
+
+	// Streamed deltas are not flagged
+	payload := []byte(`[{"Uic":21,"Quote":{"Bid":1.1001,"Ask":1.1003,"Mid":1.1002}}]`)
+	if err := client.messageHandler.handlePriceUpdate(payload); err != nil {
+		t.Fatalf("handlePriceUpdate failed: %v", err)
+	}
+	for len(client.GetPriceUpdateChannel()) > 0 {
+		if update := <-client.GetPriceUpdateChannel(); update.Bid == 1.1001 && update.Snapshot {
+			t.Errorf("Expected streamed update without Snapshot flag, got %+v", update)
+		}
+	}
+}
+
+func TestSaxoWebSocketClient_SubscribeResolvesTickersFromInstrumentStore(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	store := saxo.NewInstrumentStore(nil)
+	store.Put(saxo.InstrumentMetadata{Uic: 21, Ticker: "EURUSD", AssetType: "FxSpot"})
+	store.Put(saxo.InstrumentMetadata{Uic: 31, Ticker: "GBPUSD", AssetType: "FxSpot"})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger, saxo.WithInstrumentStore(store))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SubscribeToPrices(ctx, []string{"gbpusd", "EURUSD", "UNKNOWN"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+	if got := activePriceUics(t, mockServer); got != "21,31" {
+		t.Errorf("Expected tickers resolved to 21,31, got %q", got)
+	}
+}
+
+func TestSaxoWebSocketClient_PriceFieldGroupsMergeAcrossDeltas(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+	client.SetPriceSubscriptionOptions("ContractFutures", PriceSubscriptionOptions{
+		FieldGroups: []string{PriceFieldGroupInstrumentPriceDetails},
+		Amount:      2,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SubscribeToPrices(ctx, []string{"3002"}, "ContractFutures"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+	var arguments map[string]interface{}
+	for _, sub := range mockServer.GetActiveSubscriptions() {
+		if sub.Arguments["AssetType"] == "ContractFutures" {
+			arguments = sub.Arguments
+		}
+	}
+	groups, _ := arguments["FieldGroups"].([]interface{})
+	if len(groups) != 2 || groups[0] != "Quote" || groups[1] != "InstrumentPriceDetails" || arguments["Amount"] != float64(2) {
+		t.Fatalf("Expected Quote,InstrumentPriceDetails and Amount 2 in arguments, got %v", arguments)
+	}
+	for len(client.GetPriceUpdateChannel()) > 0 {
+		<-client.GetPriceUpdateChannel() // subscription snapshot
+	}
+
+	full := []byte(`[{"Uic":3002,"Quote":{"Bid":1.1,"Ask":1.2,"Mid":1.15},"InstrumentPriceDetails":{"OpenInterest":1500,"IsMarketOpen":true}}]`)
+	openInterestOnly := []byte(`[{"Uic":3002,"InstrumentPriceDetails":{"OpenInterest":1510}}]`)
+	for _, payload := range [][]byte{full, openInterestOnly} {
+		if err := client.messageHandler.handlePriceUpdate(payload); err != nil {
+			t.Fatalf("handlePriceUpdate failed: %v", err)
+		}
+	}
+
+	first, second := <-client.GetPriceUpdateChannel(), <-client.GetPriceUpdateChannel()
+	if first.Details == nil || first.Details.OpenInterest != 1500 || !first.Details.IsMarketOpen {
+		t.Errorf("Expected open interest 1500 on first update, got %+v", first.Details)
+	}
+	if second.Bid != 1.1 || second.Ask != 1.2 || second.Details == nil || second.Details.OpenInterest != 1510 || !second.Details.IsMarketOpen {
+		t.Errorf("Expected open-interest delta merged with last quote, got %+v / %+v", second, second.Details)
+	}
+}
@@ -0,0 +1,108 @@
+package websocket
+
+import (
+	"sync"
+	"time"
+)
+
+// PriceTimestampSource selects what PriceUpdate.Timestamp carries
+type PriceTimestampSource int
+
+const (
+	// TimestampExchange uses Saxo's LastUpdated, the receive time when a message has none (default)
+	TimestampExchange PriceTimestampSource = iota
+	// TimestampReceive uses the time the adapter received the message
+	TimestampReceive
+)
+
+// String returns the source name for logging
+func (s PriceTimestampSource) String() string {
+	if s == TimestampReceive {
+		return "receive"
+	}
+	return "exchange"
+}
+
+// PriceLatencyStats summarizes receive time minus Saxo's LastUpdated over streamed price updates
+// Snapshots and updates without LastUpdated are not counted. Includes clock skew between the hosts
+type PriceLatencyStats struct {
+	Count uint64
+	Last  time.Duration
+	Mean  time.Duration
+	Max   time.Duration
+}
+
+// priceTimestamps holds the timestamp source and the latency statistics
+type priceTimestamps struct {
+	mu      sync.Mutex
+	source  PriceTimestampSource
+	stats   PriceLatencyStats
+	sumNano float64
+}
+
+// SetPriceTimestampSource selects what PriceUpdate.Timestamp carries; ExchangeTime and RecvTime
+// are always set
+func (ws *SaxoWebSocketClient) SetPriceTimestampSource(source PriceTimestampSource) {
+	ws.priceTimestamps.mu.Lock()
+	defer ws.priceTimestamps.mu.Unlock()
+	ws.priceTimestamps.source = source
+}
+
+// PriceLatency returns the latency statistics of streamed price updates since the client was created or ResetPriceLatency
+func (ws *SaxoWebSocketClient) PriceLatency() PriceLatencyStats {
+	ws.priceTimestamps.mu.Lock()
+	defer ws.priceTimestamps.mu.Unlock()
+	return ws.priceTimestamps.stats
+}
+
+// ResetPriceLatency clears the latency statistics
+func (ws *SaxoWebSocketClient) ResetPriceLatency() {
+	ws.priceTimestamps.mu.Lock()
+	defer ws.priceTimestamps.mu.Unlock()
+	ws.priceTimestamps.stats = PriceLatencyStats{}
+	ws.priceTimestamps.sumNano = 0
+}
+
+// lastUpdated is Saxo's LastUpdated decoded in place - no intermediate string per update
+// Absent or unreadable values decode to the zero time instead of failing the message
+type lastUpdated time.Time
+
+// UnmarshalJSON parses the RFC3339 timestamp, leaving the zero time when it is unreadable
+func (t *lastUpdated) UnmarshalJSON(data []byte) error {
+	var parsed time.Time
+	if err := parsed.UnmarshalJSON(data); err != nil {
+		parsed = time.Time{}
+	}
+	*t = lastUpdated(parsed)
+	return nil
+}
+
+// priceDelta is the decode target of price messages: a StreamingPriceUpdate whose LastUpdated is
+// parsed during the decode (the outer field shadows the embedded string)
+type priceDelta struct {
+	StreamingPriceUpdate
+	LastUpdated lastUpdated `json:"LastUpdated"`
+}
+
+// stamp returns the Timestamp for an update per the source; streamed updates with an exchange
+// time are added to the latency statistics
+func (p *priceTimestamps) stamp(exchangeTime, recvTime time.Time, snapshot bool) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !exchangeTime.IsZero() && !snapshot {
+		latency := recvTime.Sub(exchangeTime)
+		p.stats.Count++
+		p.stats.Last = latency
+		p.sumNano += float64(latency)
+		p.stats.Mean = time.Duration(p.sumNano / float64(p.stats.Count))
+		if p.stats.Count == 1 || latency > p.stats.Max {
+			p.stats.Max = latency
+		}
+	}
+
+	if p.source == TimestampReceive || exchangeTime.IsZero() {
+		return recvTime
+	}
+	return exchangeTime
+}
@@ -0,0 +1,61 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+func TestSaxoWebSocketClient_PriceTimestamps(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(&MockAuthClient{authenticated: true, accessToken: "token"}, "http://localhost", "ws://localhost", logger)
+	if err := client.SubscribeToPrices(context.Background(), []string{"21"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+
+	exchangeTime := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	received := exchangeTime.Add(40 * time.Millisecond)
+	publish := func(payload string) saxo.PriceUpdate {
+		t.Helper()
+		if err := client.messageHandler.handlePriceUpdateAt([]byte(payload), "", received); err != nil {
+			t.Fatalf("handlePriceUpdate failed: %v", err)
+		}
+		return <-client.GetPriceUpdateChannel()
+	}
+
+	// Default - exchange time
+	update := publish(`[{"Uic":21,"LastUpdated":"2026-10-16T09:30:00.000Z","Quote":{"Bid":1.1,"Ask":1.2,"Mid":1.15}}]`)
+	if !update.Timestamp.Equal(exchangeTime) || !update.ExchangeTime.Equal(exchangeTime) || !update.RecvTime.Equal(received) {
+		t.Errorf("Expected exchange timestamp with both times set, got %+v", update)
+	}
+	if stats := client.PriceLatency(); stats.Count != 1 || stats.Last != 40*time.Millisecond || stats.Max != 40*time.Millisecond {
+		t.Errorf("Expected one 40ms latency sample, got %+v", stats)
+	}
+
+	// No LastUpdated - falls back to the receive time, not counted
+	update = publish(`[{"Uic":21,"Quote":{"Bid":1.1,"Ask":1.2,"Mid":1.15}}]`)
+	if !update.Timestamp.Equal(received) || !update.ExchangeTime.IsZero() {
+		t.Errorf("Expected receive time fallback, got %+v", update)
+	}
+	if client.PriceLatency().Count != 1 {
+		t.Errorf("Updates without LastUpdated must not count, got %+v", client.PriceLatency())
+	}
+
+	client.SetPriceTimestampSource(TimestampReceive)
+	update = publish(`[{"Uic":21,"LastUpdated":"2026-10-16T09:30:00.020Z","Quote":{"Bid":1.1,"Ask":1.2,"Mid":1.15}}]`)
+	if !update.Timestamp.Equal(received) || !update.ExchangeTime.Equal(exchangeTime.Add(20*time.Millisecond)) {
+		t.Errorf("Expected receive timestamp, got %+v", update)
+	}
+	if stats := client.PriceLatency(); stats.Count != 2 || stats.Mean != 30*time.Millisecond || stats.Max != 40*time.Millisecond {
+		t.Errorf("Expected mean 30ms over two samples, got %+v", stats)
+	}
+
+	client.ResetPriceLatency()
+	if stats := client.PriceLatency(); stats.Count != 0 || stats.Mean != 0 {
+		t.Errorf("Expected cleared statistics, got %+v", stats)
+	}
+}
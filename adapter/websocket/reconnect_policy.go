@@ -0,0 +1,114 @@
+package websocket
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// ReconnectPolicy controls the backoff between reconnection attempts
+// Used by every reconnection path (reconnection handler and ConnectionManager)
+type ReconnectPolicy struct {
+	InitialDelay time.Duration // Delay before the first attempt
+	Multiplier   float64       // Delay growth per attempt (<1 treated as 1 = constant delay)
+	MaxDelay     time.Duration // Upper bound for a single delay (0 = no bound)
+	MaxAttempts  int           // Attempts before giving up (0 = retry until Close)
+	Jitter       float64       // Randomizes each delay by +/- this fraction (0-1), spreads reconnect storms
+
+	// OnGiveUp is called once when MaxAttempts is exhausted, with the last attempt's error
+	// The client stays disconnected - the application decides whether to Connect again
+	OnGiveUp func(attempts int, lastErr error)
+}
+
+// DefaultReconnectPolicy returns the policy used unless SetReconnectPolicy is called
+// 2s, 4s, 8s ... capped at 5 minutes, 10 attempts, +/-20% jitter
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		InitialDelay: 2 * time.Second,
+		Multiplier:   2,
+		MaxDelay:     5 * time.Minute,
+		MaxAttempts:  10,
+		Jitter:       0.2,
+	}
+}
+
+// Delay returns the wait before attempt (1-based), including jitter
+func (p ReconnectPolicy) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if jitter := math.Min(p.Jitter, 1); jitter > 0 {
+		delay *= 1 + jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
+// errReconnectAborted is returned when the client shuts down between attempts
+var errReconnectAborted = errors.New("reconnection aborted: client shut down")
+
+// SetReconnectPolicy replaces the reconnection backoff policy
+// Takes effect for the next reconnection sequence
+func (ws *SaxoWebSocketClient) SetReconnectPolicy(policy ReconnectPolicy) {
+	ws.reconnectPolicyMu.Lock()
+	defer ws.reconnectPolicyMu.Unlock()
+	ws.reconnectPolicy = policy
+}
+
+// ReconnectPolicy returns the current reconnection backoff policy
+func (ws *SaxoWebSocketClient) ReconnectPolicy() ReconnectPolicy {
+	ws.reconnectPolicyMu.RLock()
+	defer ws.reconnectPolicyMu.RUnlock()
+	return ws.reconnectPolicy
+}
+
+// retryWithPolicy waits Delay(n) and runs attempt until it succeeds, the policy gives up
+// or the client shuts down. function names the caller in logs
+func (ws *SaxoWebSocketClient) retryWithPolicy(function string, attempt func(n int) error) error {
+	policy := ws.ReconnectPolicy()
+
+	var lastErr error
+	for n := 1; policy.MaxAttempts <= 0 || n <= policy.MaxAttempts; n++ {
+		delay := policy.Delay(n)
+		ws.logger.Info("Reconnection attempt scheduled",
+			"function", function,
+			"attempt", n,
+			"max_attempts", policy.MaxAttempts,
+			"delay", delay)
+
+		select {
+		case <-ws.done():
+			return errReconnectAborted
+		case <-ws.clock.After(delay):
+		}
+		if ws.isShutdown() {
+			return errReconnectAborted
+		}
+
+		if lastErr = attempt(n); lastErr == nil {
+			return nil
+		}
+		ws.logger.Warn("Reconnection attempt failed",
+			"function", function,
+			"attempt", n,
+			"error", lastErr)
+	}
+
+	ws.logger.Error("Max reconnection attempts reached, giving up",
+		"function", function,
+		"max_attempts", policy.MaxAttempts,
+		"error", lastErr)
+	if policy.OnGiveUp != nil {
+		policy.OnGiveUp(policy.MaxAttempts, lastErr)
+	}
+	return lastErr
+}
@@ -0,0 +1,84 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestReconnectPolicy_Delay(t *testing.T) {
+	policy := ReconnectPolicy{InitialDelay: time.Second, Multiplier: 2, MaxDelay: 5 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for i, expected := range want {
+		if got := policy.Delay(i + 1); got != expected {
+			t.Errorf("Attempt %d: expected %v, got %v", i+1, expected, got)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.Delay(1); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("Jittered delay %v outside +/-50%%", got)
+		}
+	}
+}
+
+func TestSaxoWebSocketClient_ReconnectPolicy(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	gaveUp := make(chan int, 1)
+	client.SetReconnectPolicy(ReconnectPolicy{
+		InitialDelay: 10 * time.Millisecond,
+		Multiplier:   1,
+		MaxAttempts:  2,
+		OnGiveUp: func(attempts int, lastErr error) {
+			gaveUp <- attempts
+		},
+	})
+	contextIDs := make(chan string, 10)
+	client.SetStateChannels(nil, contextIDs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+	<-contextIDs
+
+	// Reconnection follows the policy delay instead of fixed 15s + 10s waits
+	client.reconnectionTrigger <- errors.New("test reconnect")
+	select {
+	case <-contextIDs:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Reconnection did not complete within policy delay")
+	}
+
+	// Unreachable server - gives up after MaxAttempts and calls OnGiveUp
+	mockServer.Close()
+	client.reconnectionTrigger <- errors.New("test reconnect")
+	select {
+	case attempts := <-gaveUp:
+		if attempts != 2 {
+			t.Errorf("Expected give up after 2 attempts, got %d", attempts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnGiveUp not called")
+	}
+}
@@ -3,26 +3,58 @@ package websocket
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
+	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	saxo "github.com/bjoelf/saxo-adapter/adapter"
 	"github.com/gorilla/websocket"
 )
 
+// errClientShutdown is returned by subscription calls after Shutdown
+var errClientShutdown = errors.New("websocket client is shut down")
+
+// closedChan is returned by done() when there is no connection context
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
 // SaxoWebSocketClient implements real-time data streaming following legacy broker_websocket.go patterns
 type SaxoWebSocketClient struct {
 	// Connection management - following legacy WebSocket patterns
-	conn         *websocket.Conn
-	apiBaseURL   string // For HTTP API calls (subscriptions, etc.) - https://gateway.saxobank.com/sim/openapi
-	websocketURL string // For WebSocket connection - https://sim-streaming.saxobank.com/sim/oapi
+	conn         *websocket.Conn // Guarded by connMu - use connection()
+	apiBaseURL   string          // For HTTP API calls (subscriptions, etc.) - https://gateway.saxobank.com/sim/openapi
+	websocketURL string          // For WebSocket connection - https://sim-streaming.saxobank.com/sim/oapi
 	authClient   saxo.AuthClient
 	logger       *slog.Logger
 
+	// Construction options (see saxo.ClientOptions)
+	httpClient     *http.Client          // nil = authClient.GetHTTPClient
+	transport      *saxo.TransportConfig // Proxy, TLS and dial settings of the WebSocket dial (saxo.WithTransport), nil with WithHTTPClient
+	requestTimeout time.Duration         // Bounds subscription POSTs and the handshake
+	userAgent      string
+	clock          saxo.Clock // Reconnect backoff, subscription timeouts and token expiry (saxo.WithClock)
+
+	instrumentStore *saxo.InstrumentStore // Ticker -> UIC for SubscribeToPrices (saxo.WithInstrumentStore), nil = UICs only
+
+	// Extra price subscription arguments per asset type (SetPriceSubscriptionOptions)
+	priceOptions   map[string]PriceSubscriptionOptions
+	priceOptionsMu sync.RWMutex
+	priceDetails   *priceDetailsState
+
+	// Timeout detection per subscription (SetSubscriptionOptions)
+	subscriptionOptions   map[string]SubscriptionOptions
+	subscriptionOptionsMu sync.RWMutex
+
 	// Component managers - following clean architecture separation
 	subscriptionManager *SubscriptionManager
 	connectionManager   *ConnectionManager
@@ -33,6 +65,34 @@ type SaxoWebSocketClient struct {
 	orderUpdateChan     chan saxo.OrderUpdate
 	portfolioUpdateChan chan saxo.PortfolioUpdate
 	sessionEventChan    chan saxo.SessionUpdate // Session state events (snapshot + live)
+	depthUpdateChan     chan saxo.DepthUpdate   // Order books from SubscribeToDepth
+
+	// Price delivery policy when the consumer falls behind (see SetPriceBackpressure)
+	priceDispatcher *priceDispatcher
+
+	// Per-consumer price handles with reference-counted UICs (see Subscribe)
+	priceRouter *priceRouter
+
+	// Merged order book state per UIC for depth deltas (see SubscribeToDepth)
+	depthBooks *depthBooks
+
+	// Optional display formatting per UIC (see SetPriceFormatter)
+	priceFormatters   map[int]*saxo.PriceFormatter
+	priceFormattersMu sync.RWMutex
+
+	// Symbol and Description of price updates (InstrumentStore, SetSymbolResolver)
+	symbols *symbolResolver
+
+	// PriceUpdate.Timestamp source and latency statistics (SetPriceTimestampSource, PriceLatency)
+	priceTimestamps priceTimestamps
+
+	// Data message worker pool, nil = processor goroutine only (SetMessageWorkers)
+	messageWorkers      atomic.Pointer[messageWorkers]
+	messageWorkerConfig atomic.Pointer[MessageWorkerConfig] // Restarts the pool on Connect after Close
+
+	// Shared price stream throttle, nil = every update (SetPriceThrottle)
+	priceSampler atomic.Pointer[priceSampler]
+	priceSampled atomic.Uint64 // Sampled by throttles replaced or stopped
 
 	// NEW: Separated reader/processor architecture channels (CRITICAL FIX)
 	// Following legacy broker_websocket.go breakthrough pattern
@@ -43,14 +103,31 @@ type SaxoWebSocketClient struct {
 	// Message tracking - following legacy timeout detection patterns
 	lastMessageTimestamps   map[string]time.Time
 	lastMessageTimestampsMu sync.RWMutex
-	lastSequenceNumber      uint64
+	lastSequenceNumber      atomic.Uint64 // Written by the processor, reset on (re)connect
+
+	// Per-ReferenceId health from data messages and _heartbeat reasons (see SubscriptionHealth)
+	subscriptionHealth *subscriptionHealthTracker
 
-	// Context ID for this WebSocket connection session
+	// Last price message per UIC inside price subscriptions (see StaleInstruments)
+	instrumentActivity *instrumentActivity
+
+	// Context ID for this WebSocket connection session (guarded by connMu - use currentContextID())
 	contextID string
 
+	// Context ID prefix, "websocket" unless set with SetContextName (see ContextPool)
+	contextName   string
+	contextNameMu sync.RWMutex
+
+	// Subscribe calls made while disconnected, flushed once the context ID exists (see deferUntilConnected)
+	deferred   []deferredSubscription
+	deferredMu sync.Mutex
+
 	// Lifecycle management - 22:00 UTC patterns
+	// CRITICAL: connMu guards conn, contextID, ctx and cancel - (re)connects swap them while the
+	// reader, processor, monitoring and subscription goroutines read them
 	ctx    context.Context
 	cancel context.CancelFunc
+	connMu sync.RWMutex
 
 	// NEW: Goroutine lifecycle tracking (CRITICAL for clean shutdown)
 	// Following legacy pattern from broker_websocket.go
@@ -69,24 +146,66 @@ type SaxoWebSocketClient struct {
 	monitoringDone             chan struct{} // Signals when monitoring goroutine exits
 	monitoringMu               sync.Mutex    // Protects monitoring goroutine state
 
-	// Reconnection logic - exponential backoff following legacy patterns
-	maxReconnectAttempts int
-	baseReconnectDelay   time.Duration
+	// Reconnection backoff for every reconnection path (see SetReconnectPolicy)
+	reconnectPolicy    ReconnectPolicy
+	connectRetryPolicy ConnectRetryPolicy // Initial Connect only (see SetConnectRetryPolicy)
+	disconnectPolicy   DisconnectPolicy   // Reaction to _disconnect (see SetDisconnectPolicy)
+	reconnectPolicyMu  sync.RWMutex
+
+	// Set between a _disconnect control message and the scheduled reconnect (see IsDraining)
+	draining atomic.Bool
 
 	// ClientKey for order and portfolio subscriptions (fetched from /port/v1/users/me)
 	// CRITICAL: Saxo API requires ClientKey for order/portfolio subscriptions
-	clientKey   string       // Cached ClientKey from GetClientInfo
-	clientKeyMu sync.RWMutex // Protects ClientKey access
+	clientKey          string                  // Cached ClientKey from GetClientInfo
+	clientKeyMu        sync.RWMutex            // Protects ClientKey access
+	clientInfoProvider saxo.ClientInfoProvider // nil = fetch /port/v1/users/me directly
 
-	// Token refresh timer - following legacy broker_websocket.go pattern
-	// Timer fires ~18 minutes (2 min before token expires) to reauthorize WebSocket
-	tokenRefreshTimer *time.Timer
+	// Token rotation subscription - the auth client's TokenCoordinator owns the only refresh timer
+	// and calls reauthorizeOnRotation after every rotation (nil until the first connect)
+	tokenUnsubscribe func()
+	tokenMu          sync.Mutex
+
+	// Shutdown state - once set, no reconnects or new subscriptions
+	shutdown          bool
+	shutdownMu        sync.Mutex
+	closeChannelsOnce sync.Once
+
+	// Serializes Connect/Close so both are idempotent and safe in any order
+	lifecycleMu sync.Mutex
+
+	// State channels registered via SetStateChannels (nil = not published)
+	stateChannel     chan<- bool
+	contextIDChannel chan<- string
+	stateMu          sync.Mutex
+
+	// Unified ordered stream, enabled by the first Events call
+	events eventBus
+
+	// Persisted subscription intents (SetSubscriptionStore)
+	intents subscriptionIntents
 }
 
+// Compile-time checks that the concrete client satisfies its interfaces
+var _ saxo.WebSocketClient = (*SaxoWebSocketClient)(nil)
+var _ saxo.DepthStreamer = (*SaxoWebSocketClient)(nil)
+var _ saxo.EventStreamer = (*SaxoWebSocketClient)(nil)
+
 // NewSaxoWebSocketClient creates WebSocket client following legacy broker_websocket.go patterns
 // apiBaseURL: For HTTP API calls (e.g., https://gateway.saxobank.com/sim/openapi)
 // websocketURL: For WebSocket connection (e.g., https://sim-streaming.saxobank.com/sim/oapi)
-func NewSaxoWebSocketClient(authClient saxo.AuthClient, apiBaseURL string, websocketURL string, logger *slog.Logger) *SaxoWebSocketClient {
+// opts: saxo.WithHTTPClient, saxo.WithTransport (also used for the dial), saxo.WithTimeout (per request), saxo.WithUserAgent, saxo.WithBaseURL (apiBaseURL), saxo.WithLogger
+func NewSaxoWebSocketClient(authClient saxo.AuthClient, apiBaseURL string, websocketURL string, logger *slog.Logger, opts ...saxo.Option) *SaxoWebSocketClient {
+	o := saxo.NewClientOptions(apiBaseURL, logger, opts...)
+	apiBaseURL = o.BaseURL
+	logger = o.Logger
+
+	// WithHTTPClient wins over WithTransport for the dial too, like ResolveHTTPClient for REST
+	transport := o.Transport
+	if o.HTTPClient != nil {
+		transport = nil
+	}
+
 	// NOTE: Context will be created in EstablishConnection(), not here
 	// Following legacy broker_websocket.go pattern where context is created in startWebSocket()
 	// This prevents context lifecycle issues during reconnections
@@ -96,21 +215,37 @@ func NewSaxoWebSocketClient(authClient saxo.AuthClient, apiBaseURL string, webso
 		websocketURL:          websocketURL,
 		authClient:            authClient,
 		logger:                logger,
+		httpClient:            o.ResolveHTTPClient(),
+		transport:             transport,
+		requestTimeout:        o.Timeout,
+		userAgent:             o.UserAgent,
+		clock:                 o.Clock,
+		instrumentStore:       o.InstrumentStore,
+		priceOptions:          make(map[string]PriceSubscriptionOptions),
+		priceDetails:          newPriceDetailsState(),
 		lastMessageTimestamps: make(map[string]time.Time),
+		subscriptionHealth:    newSubscriptionHealthTracker(),
+		instrumentActivity:    newInstrumentActivity(),
+		subscriptionOptions:   make(map[string]SubscriptionOptions),
 		priceUpdateChan:       make(chan saxo.PriceUpdate, 100),
 		orderUpdateChan:       make(chan saxo.OrderUpdate, 1000), // HARDENED: 10x buffer to prevent deadlock during OCO floods
 		portfolioUpdateChan:   make(chan saxo.PortfolioUpdate, 100),
 		sessionEventChan:      make(chan saxo.SessionUpdate, 10),
+		depthUpdateChan:       make(chan saxo.DepthUpdate, 100),
+		depthBooks:            newDepthBooks(),
+		contextName:           defaultContextName,
+		priceFormatters:       make(map[int]*saxo.PriceFormatter),
+		symbols:               newSymbolResolver(),
 		// NEW: Initialize separated reader/processor channels (CRITICAL FIX)
 		// Following legacy broker_websocket.go breakthrough pattern
-		incomingMessages:     make(chan websocketMessage, 100), // Buffer 100 messages - prevents blocking
-		connectionErrors:     make(chan error, 10),             // Buffer 10 errors
-		reconnectionTrigger:  make(chan error, 5),              // Buffer 5 reconnection requests
-		ctx:                  nil,                              // Will be created in EstablishConnection
-		cancel:               nil,                              // Will be created in EstablishConnection
-		maxReconnectAttempts: 10,
-		baseReconnectDelay:   time.Second * 2,
-		lastSequenceNumber:   0,
+		incomingMessages:    make(chan websocketMessage, 100), // Buffer 100 messages - prevents blocking
+		connectionErrors:    make(chan error, 10),             // Buffer 10 errors
+		reconnectionTrigger: make(chan error, 5),              // Buffer 5 reconnection requests
+		ctx:                 nil,                              // Will be created in EstablishConnection
+		cancel:              nil,                              // Will be created in EstablishConnection
+		reconnectPolicy:     DefaultReconnectPolicy(),
+		connectRetryPolicy:  DefaultConnectRetryPolicy(),
+		disconnectPolicy:    DefaultDisconnectPolicy(),
 	}
 
 	// Initialize component managers following clean architecture patterns
@@ -119,6 +254,8 @@ func NewSaxoWebSocketClient(authClient saxo.AuthClient, apiBaseURL string, webso
 	getTokenFunc := func() (string, error) {
 		return authClient.GetAccessToken()
 	}
+	client.priceRouter = newPriceRouter()
+	client.priceDispatcher = newPriceDispatcher(client.priceUpdateChan, BackpressurePolicy{Strategy: BackpressureDrop})
 	client.subscriptionManager = NewSubscriptionManager(client, apiBaseURL, getTokenFunc)
 	client.connectionManager = NewConnectionManager(client)
 	client.messageHandler = NewMessageHandler(client)
@@ -126,28 +263,146 @@ func NewSaxoWebSocketClient(authClient saxo.AuthClient, apiBaseURL string, webso
 	return client
 }
 
+// getHTTPClient returns the client used for subscription HTTP calls
+// Uses the injected saxo.WithHTTPClient client when set, otherwise the auth client's
+func (ws *SaxoWebSocketClient) getHTTPClient(ctx context.Context) (*http.Client, error) {
+	if ws.httpClient != nil {
+		return ws.httpClient, nil
+	}
+	return ws.authClient.GetHTTPClient(ctx)
+}
+
 // Connect establishes WebSocket connection following 22:00 UTC lifecycle pattern
+// Idempotent: returns nil without reconnecting when already connected
 func (ws *SaxoWebSocketClient) Connect(ctx context.Context) error {
+	ws.lifecycleMu.Lock()
+	defer ws.lifecycleMu.Unlock()
+
+	if ws.isShutdown() {
+		return errClientShutdown
+	}
+
+	if ws.connectionManager.IsConnected() {
+		ws.logger.Debug("Already connected (no-op)",
+			"function", "Connect",
+			"context_id", ws.currentContextID())
+		return nil
+	}
+
+	// Saved subscriptions of the previous run (SetSubscriptionStore) are queued with the deferred ones
+	ws.restoreSubscriptions(ctx)
+
 	// Delegate to connection manager - following legacy startWebSocket() pattern
 	// EstablishConnection will start ALL goroutines with unified lifecycle
-	return ws.connectionManager.EstablishConnection(ctx)
+	// Transient handshake failures are retried per ConnectRetryPolicy
+	if err := ws.establishWithRetry(ctx); err != nil {
+		return err
+	}
+
+	// Subscriptions requested before Connect - the connection stays up even if some fail
+	if err := ws.flushDeferredSubscriptions(ctx); err != nil {
+		ws.logger.Error("Some deferred subscriptions failed",
+			"function", "Connect",
+			"error", err)
+	}
+	return nil
+}
+
+// IsConnected implements saxo.WebSocketClient - false before Connect, while reconnecting and after Close
+func (ws *SaxoWebSocketClient) IsConnected() bool {
+	return ws.connectionManager.IsConnected()
+}
+
+// SetStateChannels registers channels that receive connection state and context ID changes
+// Publishes true + contextID on every (re)connect and false on disconnect or when the server
+// announces one (_disconnect, see IsDraining). Sends are non-blocking, so use buffered channels (size 1)
+func (ws *SaxoWebSocketClient) SetStateChannels(stateChannel chan<- bool, contextIDChannel chan<- string) {
+	ws.stateMu.Lock()
+	ws.stateChannel = stateChannel
+	ws.contextIDChannel = contextIDChannel
+	ws.stateMu.Unlock()
+
+	// Registered after Connect - publish current state so the consumer is not left waiting
+	if ws.connectionManager.IsConnected() {
+		ws.publishStateChannels(true, ws.currentContextID())
+	}
+}
+
+// publishConnectionState reports a connection change to the Events stream and the state channels
+func (ws *SaxoWebSocketClient) publishConnectionState(connected bool, contextID string) {
+	ws.emitEvent(saxo.StreamEvent{Kind: saxo.ConnectionEvent, Connection: &saxo.ConnectionUpdate{Connected: connected, ContextID: contextID}})
+	ws.publishStateChannels(connected, contextID)
+}
+
+// publishStateChannels sends connection state and context ID to the registered state channels
+// Non-blocking - a full channel means the consumer has not read the previous value yet
+func (ws *SaxoWebSocketClient) publishStateChannels(connected bool, contextID string) {
+	ws.stateMu.Lock()
+	defer ws.stateMu.Unlock()
+
+	if ws.contextIDChannel != nil && connected && contextID != "" {
+		select {
+		case ws.contextIDChannel <- contextID:
+		default:
+			ws.logger.Warn("Context ID channel full, dropping update",
+				"function", "publishStateChannels",
+				"context_id", contextID)
+		}
+	}
+
+	if ws.stateChannel != nil {
+		select {
+		case ws.stateChannel <- connected:
+		default:
+			ws.logger.Warn("State channel full, dropping update",
+				"function", "publishStateChannels",
+				"connected", connected)
+		}
+	}
 }
 
 // SubscribeToPrices delegates to subscription manager following clean architecture
 // assetType: "FxSpot", "ContractFutures", "CfdOnFutures", etc.
 func (ws *SaxoWebSocketClient) SubscribeToPrices(ctx context.Context, instruments []string, assetType string) error {
+	if ws.isShutdown() {
+		return errClientShutdown
+	}
+
 	ws.logger.Info("Subscribing to price feeds",
 		"function", "SubscribeToPrices",
 		"instrument_count", len(instruments),
 		"asset_type", assetType,
 		"instruments", instruments)
-	err := ws.subscriptionManager.SubscribeToInstrumentPrices(instruments, assetType)
+
+	uics := ws.subscriptionManager.getUicsForInstruments(instruments)
+	if len(uics) == 0 {
+		return fmt.Errorf("no valid UICs found for instruments")
+	}
+
+	// Saxo subscription carries these UICs plus any still held by Subscribe handles
+	ws.priceRouter.changeMu.Lock()
+	defer ws.priceRouter.changeMu.Unlock()
+	previous := ws.priceRouter.shared[assetType]
+	ws.priceRouter.setShared(assetType, uics)
+
+	// Not connected yet - subscribe with the router's UICs once the context ID exists
+	if ws.deferPriceSync(assetType) {
+		ws.saveSubscriptionState()
+		return nil
+	}
+
+	body, err := ws.subscriptionManager.SubscribeToInstrumentPricesContext(ctx, uicStrings(ws.priceRouter.uicsFor(assetType)), assetType)
 	if err != nil {
+		ws.priceRouter.setShared(assetType, previous)
 		ws.logger.Error("Price subscription failed",
 			"function", "SubscribeToPrices",
 			"error", err)
 		return err
 	}
+	// Current quotes first, flagged Snapshot - illiquid instruments may not tick for minutes
+	ws.messageHandler.publishPriceSnapshot(body, assetType)
+	ws.saveSubscriptionState()
+
 	ws.logger.Info("Price subscription successful",
 		"function", "SubscribeToPrices",
 		"instrument_count", len(instruments),
@@ -157,6 +412,15 @@ func (ws *SaxoWebSocketClient) SubscribeToPrices(ctx context.Context, instrument
 
 // SubscribeToOrders delegates to subscription manager
 func (ws *SaxoWebSocketClient) SubscribeToOrders(ctx context.Context) error {
+	if ws.isShutdown() {
+		return errClientShutdown
+	}
+
+	if ws.deferUntilConnected("orders", ws.SubscribeToOrders) {
+		ws.recordIntent(func(i *subscriptionIntents) { i.orders = true })
+		return nil
+	}
+
 	ws.logger.Info("Subscribing to order status updates",
 		"function", "SubscribeToOrders")
 
@@ -175,13 +439,14 @@ func (ws *SaxoWebSocketClient) SubscribeToOrders(ctx context.Context) error {
 	ws.logger.Debug("Using ClientKey for orders",
 		"function", "SubscribeToOrders",
 		"client_key", clientKey)
-	err := ws.subscriptionManager.SubscribeToOrderUpdates(clientKey)
+	err := ws.subscriptionManager.SubscribeToOrderUpdatesContext(ctx, clientKey)
 	if err != nil {
 		ws.logger.Error("Order subscription failed",
 			"function", "SubscribeToOrders",
 			"error", err)
 		return err
 	}
+	ws.recordIntent(func(i *subscriptionIntents) { i.orders = true })
 	ws.logger.Info("Order subscription successful",
 		"function", "SubscribeToOrders")
 	return nil
@@ -189,6 +454,15 @@ func (ws *SaxoWebSocketClient) SubscribeToOrders(ctx context.Context) error {
 
 // SubscribeToPortfolio delegates to subscription manager
 func (ws *SaxoWebSocketClient) SubscribeToPortfolio(ctx context.Context) error {
+	if ws.isShutdown() {
+		return errClientShutdown
+	}
+
+	if ws.deferUntilConnected("portfolio", ws.SubscribeToPortfolio) {
+		ws.recordIntent(func(i *subscriptionIntents) { i.portfolio = true })
+		return nil
+	}
+
 	ws.logger.Info("Subscribing to portfolio balance updates",
 		"function", "SubscribeToPortfolio")
 
@@ -207,26 +481,77 @@ func (ws *SaxoWebSocketClient) SubscribeToPortfolio(ctx context.Context) error {
 	ws.logger.Debug("Using ClientKey for portfolio",
 		"function", "SubscribeToPortfolio",
 		"client_key", clientKey)
-	err := ws.subscriptionManager.SubscribeToPortfolioUpdates(clientKey)
+	err := ws.subscriptionManager.SubscribeToPortfolioUpdatesContext(ctx, clientKey)
 	if err != nil {
 		ws.logger.Error("Portfolio subscription failed",
 			"function", "SubscribeToPortfolio",
 			"error", err)
 		return err
 	}
+	ws.recordIntent(func(i *subscriptionIntents) { i.portfolio = true })
 	ws.logger.Info("Portfolio subscription successful",
 		"function", "SubscribeToPortfolio")
 	return nil
 }
 
+// RegisterSubscription subscribes to any Saxo streaming endpoint and routes its data messages
+// to handler - e.g. "/port/v1/positions/subscriptions" or "/trade/v1/messages/subscriptions".
+// The subscription is restored on reconnect like the built-in ones. Re-registering name
+// replaces the subscription. Returns the subscription snapshot (HTTP POST response body),
+// nil when called before Connect - the subscription is then made once connected
+func (ws *SaxoWebSocketClient) RegisterSubscription(ctx context.Context, name, endpoint string, arguments map[string]interface{}, handler DataHandler) ([]byte, error) {
+	if ws.isShutdown() {
+		return nil, errClientShutdown
+	}
+	// Validated up front - a deferred intent would otherwise only fail on Connect
+	if err := validateCustomSubscription(name, endpoint, handler); err != nil {
+		return nil, err
+	}
+
+	if ws.deferUntilConnected(customSubscriptionKey(name), func(ctx context.Context) error {
+		_, err := ws.RegisterSubscription(ctx, name, endpoint, arguments, handler)
+		return err
+	}) {
+		return nil, nil
+	}
+
+	_, snapshot, err := ws.subscriptionManager.SubscribeCustomContext(ctx, name, endpoint, arguments, handler)
+	if err != nil {
+		ws.logger.Error("Custom subscription failed",
+			"function", "RegisterSubscription",
+			"name", name,
+			"endpoint", endpoint,
+			"error", err)
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// UnregisterSubscription deletes a subscription created by RegisterSubscription
+func (ws *SaxoWebSocketClient) UnregisterSubscription(ctx context.Context, name string) error {
+	if ws.cancelDeferred(customSubscriptionKey(name)) {
+		return nil
+	}
+	return ws.subscriptionManager.UnsubscribeCustom(ctx, name)
+}
+
 // SubscribeToSessionEvents delegates to subscription manager
 // Reference: pivot-web/broker/broker_websocket.go:63 - sessionsSubscriptionPath
 // Following legacy TestForRealtime pattern: the HTTP POST response snapshot is pushed
 // as the first event to GetSessionEventChannel() so consumers can check TradeLevel immediately.
 func (ws *SaxoWebSocketClient) SubscribeToSessionEvents(ctx context.Context) error {
+	if ws.isShutdown() {
+		return errClientShutdown
+	}
+
+	if ws.deferUntilConnected("session_events", ws.SubscribeToSessionEvents) {
+		ws.recordIntent(func(i *subscriptionIntents) { i.sessionEvents = true })
+		return nil
+	}
+
 	ws.logger.Info("Subscribing to session events",
 		"function", "SubscribeToSessionEvents")
-	body, err := ws.subscriptionManager.SubscribeToSessionEvents()
+	body, err := ws.subscriptionManager.SubscribeToSessionEventsContext(ctx)
 	if err != nil {
 		ws.logger.Error("Session events subscription failed",
 			"function", "SubscribeToSessionEvents",
@@ -235,6 +560,7 @@ func (ws *SaxoWebSocketClient) SubscribeToSessionEvents(ctx context.Context) err
 	}
 	// Push snapshot as first event - equivalent to legacy TestForRealtime(body) pattern
 	ws.pushSessionSnapshot(body)
+	ws.recordIntent(func(i *subscriptionIntents) { i.sessionEvents = true })
 	ws.logger.Info("Session events subscription successful",
 		"function", "SubscribeToSessionEvents")
 	return nil
@@ -265,6 +591,9 @@ func (ws *SaxoWebSocketClient) pushSessionSnapshot(body []byte) {
 		"trade_level", update.TradeLevel,
 		"data_level", update.DataLevel,
 		"state", update.State)
+	if ws.emitEvent(saxo.StreamEvent{Kind: saxo.SessionEvent, Session: &update}) {
+		return
+	}
 	select {
 	case ws.sessionEventChan <- update:
 	default:
@@ -274,7 +603,7 @@ func (ws *SaxoWebSocketClient) pushSessionSnapshot(body []byte) {
 }
 
 // GetSessionEventChannel returns the session event channel
-// Consumers should read this channel and call broker.SetSessionCapabilities("FullTradingAndChat")
+// Consumers should read this channel and decide whether to call broker.SetTradeLevel("FullTradingAndChat")
 // when TradeLevel != "FullTradingAndChat"
 func (ws *SaxoWebSocketClient) GetSessionEventChannel() <-chan saxo.SessionUpdate {
 	return ws.sessionEventChan
@@ -285,6 +614,70 @@ func (ws *SaxoWebSocketClient) GetPriceUpdateChannel() <-chan saxo.PriceUpdate {
 	return ws.priceUpdateChan
 }
 
+// SetClientInfoProvider sets the source of the ClientKey (typically the existing BrokerClient)
+// Without a provider the client fetches /port/v1/users/me itself
+func (ws *SaxoWebSocketClient) SetClientInfoProvider(provider saxo.ClientInfoProvider) {
+	ws.clientKeyMu.Lock()
+	defer ws.clientKeyMu.Unlock()
+	ws.clientInfoProvider = provider
+}
+
+// SetClientKey sets a known ClientKey so order/portfolio subscriptions skip the lookup
+func (ws *SaxoWebSocketClient) SetClientKey(clientKey string) {
+	ws.clientKeyMu.Lock()
+	defer ws.clientKeyMu.Unlock()
+	ws.clientKey = clientKey
+}
+
+// defaultContextName prefixes context IDs of clients not created by a ContextPool
+const defaultContextName = "websocket"
+
+// maxContextNameLength keeps generated context IDs within Saxo's 50 character limit
+const maxContextNameLength = 24
+
+// SetContextName sets the prefix of the context IDs this client connects with
+// Call before Connect; names must be unique per process when several clients share one token
+// Allowed characters are letters, digits and '-' (Saxo context ID rules)
+func (ws *SaxoWebSocketClient) SetContextName(name string) error {
+	if name == "" || len(name) > maxContextNameLength {
+		return fmt.Errorf("context name must be 1-%d characters, got %q", maxContextNameLength, name)
+	}
+	for _, r := range name {
+		if !(r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return fmt.Errorf("context name %q contains invalid character %q", name, r)
+		}
+	}
+	ws.contextNameMu.Lock()
+	defer ws.contextNameMu.Unlock()
+	ws.contextName = name
+	return nil
+}
+
+// ContextName returns the context ID prefix (see SetContextName)
+func (ws *SaxoWebSocketClient) ContextName() string {
+	ws.contextNameMu.RLock()
+	defer ws.contextNameMu.RUnlock()
+	return ws.contextName
+}
+
+// SetPriceFormatter makes price updates for uic carry display strings (PriceUpdate.Display)
+// nil removes the formatter; prices themselves are never changed
+func (ws *SaxoWebSocketClient) SetPriceFormatter(uic int, formatter *saxo.PriceFormatter) {
+	ws.priceFormattersMu.Lock()
+	defer ws.priceFormattersMu.Unlock()
+	if formatter == nil {
+		delete(ws.priceFormatters, uic)
+		return
+	}
+	ws.priceFormatters[uic] = formatter
+}
+
+func (ws *SaxoWebSocketClient) priceFormatter(uic int) *saxo.PriceFormatter {
+	ws.priceFormattersMu.RLock()
+	defer ws.priceFormattersMu.RUnlock()
+	return ws.priceFormatters[uic]
+}
+
 // ensureClientKey fetches and caches ClientKey from broker if not already available
 // CRITICAL: Saxo API requires ClientKey for order and portfolio subscriptions
 // ClientKey identifies the client account and is required per API documentation:
@@ -315,25 +708,17 @@ func (ws *SaxoWebSocketClient) ensureClientKey(ctx context.Context) error {
 		return nil
 	}
 
-	// Fetch from broker via authClient's broker client
-	// The authClient should provide access to the broker client
-	// We need to create a temporary broker client or use a different approach
-
-	// CRITICAL FIX: We need to access the broker client through the auth client
-	// The saxo-adapter pattern is: authClient -> brokerClient -> GetClientInfo()
-	// Since SaxoWebSocketClient only has authClient, we need to create a broker client
-
 	ws.logger.Debug("Fetching ClientKey from /port/v1/users/me",
-		"function", "ensureClientKey")
+		"function", "ensureClientKey",
+		"injected_provider", ws.clientInfoProvider != nil)
 
-	// Create a temporary broker client to fetch client info
-	// Following saxo-adapter pattern: CreateBrokerServices(authClient, logger)
-	brokerClient, err := saxo.CreateBrokerServices(ws.authClient, ws.logger)
-	if err != nil {
-		return fmt.Errorf("failed to create broker client for ClientKey fetch: %w", err)
+	var clientInfo *saxo.ClientInfo
+	var err error
+	if ws.clientInfoProvider != nil {
+		clientInfo, err = ws.clientInfoProvider.GetClientInfo(ctx)
+	} else {
+		clientInfo, err = ws.fetchClientInfo(ctx)
 	}
-
-	clientInfo, err := brokerClient.GetClientInfo(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get client info: %w", err)
 	}
@@ -351,10 +736,56 @@ func (ws *SaxoWebSocketClient) ensureClientKey(ctx context.Context) error {
 	return nil
 }
 
+// fetchClientInfo calls GET /port/v1/users/me with the streaming client's own HTTP settings
+// Used when no ClientInfoProvider is set - avoids constructing a BrokerClient (and its auth keeper)
+func (ws *SaxoWebSocketClient) fetchClientInfo(ctx context.Context) (*saxo.ClientInfo, error) {
+	token, err := ws.authClient.GetAccessToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	ctx, cancel := saxo.RequestContext(ctx, ws.requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", ws.apiBaseURL+"/port/v1/users/me", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if ws.userAgent != "" {
+		req.Header.Set("User-Agent", ws.userAgent)
+	}
+
+	httpClient, err := ws.getHTTPClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HTTP client: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("client info request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var clientInfo saxo.ClientInfo
+	if err := json.NewDecoder(resp.Body).Decode(&clientInfo); err != nil {
+		return nil, fmt.Errorf("failed to decode client info: %w", err)
+	}
+	return &clientInfo, nil
+}
+
 func (ws *SaxoWebSocketClient) GetOrderUpdateChannel() <-chan saxo.OrderUpdate {
 	return ws.orderUpdateChan
 }
 
+// GetPortfolioUpdateChannel implements saxo.WebSocketClient - balance updates
+// Silent once Events is called: consumers such as risk.MarginMonitor.Run then need the
+// PortfolioEvents of Events instead
 func (ws *SaxoWebSocketClient) GetPortfolioUpdateChannel() <-chan saxo.PortfolioUpdate {
 	return ws.portfolioUpdateChan
 }
@@ -362,11 +793,24 @@ func (ws *SaxoWebSocketClient) GetPortfolioUpdateChannel() <-chan saxo.Portfolio
 // GetChannelStats returns channel utilization statistics for monitoring
 // Used for health checks and circuit breaker logic in consuming applications
 func (ws *SaxoWebSocketClient) GetChannelStats() map[string]int {
+	workers := ws.MessageWorkers()
+	queued := 0
+	for _, depth := range workers.QueueDepths {
+		queued += depth
+	}
 	return map[string]int{
 		"orderUpdateQueueLength":   len(ws.orderUpdateChan),
 		"orderUpdateQueueCapacity": cap(ws.orderUpdateChan),
 		"priceUpdateQueueLength":   len(ws.priceUpdateChan),
 		"priceUpdateQueueCapacity": cap(ws.priceUpdateChan),
+		"priceUpdatePending":       ws.priceDispatcher.PendingLen(),
+		"priceUpdatesDropped":      int(ws.priceDispatcher.dropped.Load()),
+		"priceUpdatesConflated":    int(ws.priceDispatcher.conflated.Load()),
+		"priceUpdatesSampled":      int(ws.SampledPriceUpdates()),
+		"depthUpdateQueueLength":   len(ws.depthUpdateChan),
+		"messageWorkers":           workers.Workers,
+		"messageWorkerQueued":      queued,
+		"messageWorkerBlocked":     int(workers.Blocked),
 	}
 }
 
@@ -375,7 +819,7 @@ func (ws *SaxoWebSocketClient) GetChannelStats() map[string]int {
 func (ws *SaxoWebSocketClient) UpdateLastMessageTimestamp(referenceID string) {
 	ws.lastMessageTimestampsMu.Lock()
 	defer ws.lastMessageTimestampsMu.Unlock()
-	ws.lastMessageTimestamps[referenceID] = time.Now()
+	ws.lastMessageTimestamps[referenceID] = ws.clock.Now()
 }
 
 // GetLastMessageTimestamp retrieves the last message timestamp for a subscription
@@ -419,10 +863,18 @@ func (ws *SaxoWebSocketClient) readMessages() {
 	ws.logger.Info("Reader goroutine started",
 		"function", "readMessages")
 
+	// Snapshot the connection - Close/reconnect may nil out ws.conn while we are blocked reading
+	conn := ws.connection()
+	if conn == nil {
+		ws.logger.Warn("No connection, exiting reader",
+			"function", "readMessages")
+		return
+	}
+
 	for {
 		// Check for context cancellation (clean shutdown)
 		select {
-		case <-ws.ctx.Done():
+		case <-ws.done():
 			ws.logger.Info("Context canceled, exiting reader",
 				"function", "readMessages")
 			return
@@ -432,14 +884,15 @@ func (ws *SaxoWebSocketClient) readMessages() {
 
 		// Set read deadline (1 minute - aligns with Saxo's _heartbeat every ~60s)
 		deadline := time.Now().Add(1 * time.Minute)
-		if err := ws.conn.SetReadDeadline(deadline); err != nil {
+		if err := conn.SetReadDeadline(deadline); err != nil {
 			ws.logger.Warn("Failed to set read deadline",
 				"function", "readMessages",
 				"error", err)
 		}
 
 		// BLOCKING READ - but that's OK, this goroutine ONLY reads
-		messageType, message, err := ws.conn.ReadMessage()
+		// The frame lands in a pooled buffer the processor releases (no per-message copy)
+		messageType, frame, err := readFrame(conn)
 
 		if err != nil {
 			// Log detailed error information
@@ -464,12 +917,21 @@ func (ws *SaxoWebSocketClient) readMessages() {
 					"temporary", netErr.Temporary())
 			}
 
+			// Errors caused by Close/reconnect teardown are expected - don't queue a reconnect for them
+			select {
+			case <-ws.done():
+				ws.logger.Debug("Context canceled, not reporting read error",
+					"function", "readMessages")
+				return
+			default:
+			}
+
 			// Don't process error here - just report it to processor
 			select {
 			case ws.connectionErrors <- err:
 				ws.logger.Debug("Error sent to processor channel",
 					"function", "readMessages")
-			case <-ws.ctx.Done():
+			case <-ws.done():
 				ws.logger.Debug("Context canceled while sending error",
 					"function", "readMessages")
 				return
@@ -483,15 +945,13 @@ func (ws *SaxoWebSocketClient) readMessages() {
 			return
 		}
 
-		// Copy message data (ReadMessage may reuse buffer)
-		messageCopy := make([]byte, len(message))
-		copy(messageCopy, message)
-
 		// Send to processor - non-blocking with timeout
+		message := frame.Bytes()
 		msg := websocketMessage{
 			MessageType: messageType,
-			Data:        messageCopy,
-			ReceivedAt:  time.Now(),
+			Data:        message,
+			ReceivedAt:  ws.clock.Now(),
+			buffer:      frame,
 		}
 
 		select {
@@ -506,7 +966,8 @@ func (ws *SaxoWebSocketClient) readMessages() {
 					"message_type", messageType,
 					"message_size", len(message))
 			}
-		case <-ws.ctx.Done():
+		case <-ws.done():
+			releaseFrame(frame)
 			return
 		case <-time.After(1 * time.Second):
 			// Channel full - this is a problem, always log
@@ -515,6 +976,7 @@ func (ws *SaxoWebSocketClient) readMessages() {
 				"message_type", messageType,
 				"message_size", len(message),
 				"queue_length", len(ws.incomingMessages))
+			releaseFrame(frame)
 		}
 	}
 }
@@ -553,7 +1015,7 @@ func (ws *SaxoWebSocketClient) processMessages() {
 
 	for {
 		select {
-		case <-ws.ctx.Done():
+		case <-ws.done():
 			ws.logger.Info("Context canceled, exiting processor",
 				"function", "processMessages")
 			return
@@ -572,13 +1034,14 @@ func (ws *SaxoWebSocketClient) processMessages() {
 // processOneMessage handles a single WebSocket message
 // Following legacy broker_websocket.go pattern
 func (ws *SaxoWebSocketClient) processOneMessage(msg websocketMessage) {
+	defer releaseFrame(msg.buffer)
 	//ws.logger.Printf("📥 WebSocket message received: type=%d, size=%d bytes", msg.MessageType, len(msg.Data))
 
 	switch msg.MessageType {
 	case websocket.BinaryMessage:
 		//ws.logger.Printf("Processing binary message (size=%d bytes)", len(msg.Data))
 		// Delegate to message handler
-		if err := ws.messageHandler.ProcessMessage(msg.Data); err != nil {
+		if err := ws.messageHandler.processFrame(msg.Data, msg.ReceivedAt, msg.buffer); err != nil {
 			ws.logger.Error("Message handling error",
 				"function", "processOneMessage",
 				"message_type", "binary",
@@ -629,6 +1092,14 @@ func (ws *SaxoWebSocketClient) handleConnectionError(err error) {
 		"function", "handleConnectionError",
 		"error", err)
 
+	// The server announced this disconnect - the reconnect is already scheduled (handleServerDisconnect)
+	if ws.draining.Load() {
+		ws.logger.Info("Connection closed while draining, waiting for scheduled reconnect",
+			"function", "handleConnectionError")
+		ws.connectionManager.handleConnectionClosed()
+		return
+	}
+
 	// Classify error and decide strategy
 	if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
 		ws.logger.Info("Normal closure, no reconnect needed",
@@ -683,10 +1154,26 @@ func (ws *SaxoWebSocketClient) handleConnectionError(err error) {
 }
 
 // Close terminates WebSocket connection following 21:00 UTC shutdown pattern
+// Idempotent and safe to call before Connect
 func (ws *SaxoWebSocketClient) Close() error {
+	ws.lifecycleMu.Lock()
+	defer ws.lifecycleMu.Unlock()
+
+	conn := ws.connection()
+	if ws.connContext() == nil && conn == nil {
+		ws.logger.Debug("Never connected (no-op)",
+			"function", "Close")
+		ws.stopSymbolResolver() // Price updates handled before Connect may have started it
+		return nil
+	}
+
 	// Cancel context to stop goroutines (if context exists)
-	if ws.cancel != nil {
-		ws.cancel()
+	ws.cancelContext()
+
+	// Unblock the reader's pending ReadMessage so it observes the canceled context now
+	// instead of waiting for the 1-minute read deadline
+	if conn != nil {
+		conn.SetReadDeadline(time.Now())
 	}
 
 	// CRITICAL: Wait for READER goroutine to exit cleanly
@@ -748,10 +1235,206 @@ func (ws *SaxoWebSocketClient) Close() error {
 		}
 	}
 
+	// The processor has exited, nothing dispatches to the workers or requests symbols any more
+	ws.stopMessageWorkers()
+	ws.stopSymbolResolver()
+
 	// Delegate to connection manager for actual connection cleanup
 	return ws.connectionManager.CloseConnection()
 }
 
+// Shutdown implements saxo.Shutdowner - full teardown of the streaming client
+// Deletes server-side subscriptions, stops the token timer and all goroutines, then closes
+// the update channels so consumers ranging over them terminate. Channels are left open if
+// goroutines fail to exit before ctx expires (closing them would risk a send on closed channel)
+func (ws *SaxoWebSocketClient) Shutdown(ctx context.Context) error {
+	ws.shutdownMu.Lock()
+	ws.shutdown = true
+	ws.shutdownMu.Unlock()
+
+	ws.logger.Info("Shutting down WebSocket client",
+		"function", "Shutdown")
+
+	var errs []error
+
+	// Delete subscriptions first - needs a valid token and context ID
+	if err := ws.subscriptionManager.DeleteAllSubscriptions(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to delete subscriptions: %w", err))
+	}
+
+	ws.stopTokenRefresh()
+
+	if err := ws.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close connection: %w", err))
+	}
+
+	if err := ws.waitForGoroutines(ctx); err != nil {
+		errs = append(errs, err)
+	} else {
+		// Workers still send to the update channels - stop them before closing
+		ws.stopMessageWorkers()
+		if sampler := ws.priceSampler.Swap(nil); sampler != nil {
+			sampler.Stop()
+			ws.priceSampled.Add(sampler.sampled.Load())
+		}
+		ws.priceDispatcher.Stop()
+		ws.priceRouter.closeAll()
+		ws.closeUpdateChannels()
+	}
+
+	ws.logger.Info("WebSocket client shut down",
+		"function", "Shutdown",
+		"errors", len(errs))
+	return errors.Join(errs...)
+}
+
+// done returns the Done channel of the current connection context
+// A nil context (never connected) yields a closed channel so goroutines exit instead of panicking
+func (ws *SaxoWebSocketClient) done() <-chan struct{} {
+	ctx := ws.connContext()
+	if ctx == nil {
+		return closedChan
+	}
+	return ctx.Done()
+}
+
+// connection returns the current WebSocket connection, nil while disconnected
+func (ws *SaxoWebSocketClient) connection() *websocket.Conn {
+	ws.connMu.RLock()
+	defer ws.connMu.RUnlock()
+	return ws.conn
+}
+
+// ContextID returns the Saxo streaming context ID of the current connection, "" before Connect
+func (ws *SaxoWebSocketClient) ContextID() string {
+	return ws.currentContextID()
+}
+
+// currentContextID returns the context ID of the current connection session
+func (ws *SaxoWebSocketClient) currentContextID() string {
+	ws.connMu.RLock()
+	defer ws.connMu.RUnlock()
+	return ws.contextID
+}
+
+// connContext returns the current connection context, nil before the first connect
+func (ws *SaxoWebSocketClient) connContext() context.Context {
+	ws.connMu.RLock()
+	defer ws.connMu.RUnlock()
+	return ws.ctx
+}
+
+// setConnection installs a freshly dialed connection and its context ID
+func (ws *SaxoWebSocketClient) setConnection(conn *websocket.Conn, contextID string) {
+	ws.connMu.Lock()
+	defer ws.connMu.Unlock()
+	ws.conn = conn
+	ws.contextID = contextID
+}
+
+// takeConnection detaches the current connection so exactly one caller closes it
+func (ws *SaxoWebSocketClient) takeConnection() *websocket.Conn {
+	ws.connMu.Lock()
+	defer ws.connMu.Unlock()
+	conn := ws.conn
+	ws.conn = nil
+	return conn
+}
+
+// newConnContext replaces the connection context and returns it
+// cancelPrevious stops goroutines still bound to the old context
+func (ws *SaxoWebSocketClient) newConnContext(cancelPrevious bool) context.Context {
+	ws.connMu.Lock()
+	defer ws.connMu.Unlock()
+	if cancelPrevious && ws.cancel != nil {
+		ws.cancel()
+	}
+	ws.ctx, ws.cancel = context.WithCancel(context.Background())
+	return ws.ctx
+}
+
+// cancelContext cancels the current connection context (no-op before the first connect)
+func (ws *SaxoWebSocketClient) cancelContext() {
+	ws.connMu.RLock()
+	cancel := ws.cancel
+	ws.connMu.RUnlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// isShutdown reports whether Shutdown has been called
+func (ws *SaxoWebSocketClient) isShutdown() bool {
+	ws.shutdownMu.Lock()
+	defer ws.shutdownMu.Unlock()
+	return ws.shutdown
+}
+
+// stopTokenRefresh unsubscribes from token rotations (no-op if never subscribed)
+func (ws *SaxoWebSocketClient) stopTokenRefresh() {
+	ws.tokenMu.Lock()
+	defer ws.tokenMu.Unlock()
+	if ws.tokenUnsubscribe != nil {
+		ws.tokenUnsubscribe()
+		ws.tokenUnsubscribe = nil
+	}
+}
+
+// waitForGoroutines blocks until reader, processor, reconnection handler and monitoring have exited
+func (ws *SaxoWebSocketClient) waitForGoroutines(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if !ws.anyGoroutineRunning() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("goroutines still running at shutdown deadline: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// anyGoroutineRunning checks the lifecycle flags of all long-lived goroutines
+func (ws *SaxoWebSocketClient) anyGoroutineRunning() bool {
+	ws.readerMu.Lock()
+	reader := ws.readerRunning
+	ws.readerMu.Unlock()
+
+	ws.processorMu.Lock()
+	processor := ws.processorRunning
+	ws.processorMu.Unlock()
+
+	ws.reconnectionHandlerMu.Lock()
+	reconnection := ws.reconnectionHandlerRunning
+	ws.reconnectionHandlerMu.Unlock()
+
+	ws.monitoringMu.Lock()
+	monitoring := ws.monitoringRunning
+	ws.monitoringMu.Unlock()
+
+	ws.symbols.mu.Lock()
+	symbols := ws.symbols.running
+	ws.symbols.mu.Unlock()
+
+	return reader || processor || reconnection || monitoring || symbols
+}
+
+// closeUpdateChannels closes consumer-facing channels exactly once
+// CRITICAL: Only call after all producer goroutines have exited
+func (ws *SaxoWebSocketClient) closeUpdateChannels() {
+	ws.closeChannelsOnce.Do(func() {
+		close(ws.priceUpdateChan)
+		close(ws.orderUpdateChan)
+		close(ws.portfolioUpdateChan)
+		close(ws.sessionEventChan)
+		close(ws.depthUpdateChan)
+		ws.closeEvents()
+	})
+}
+
 // handleReconnectionRequests runs in a separate goroutine to handle reconnection requests
 // Following legacy broker_websocket.go breakthrough pattern - CRITICAL FIX
 // This prevents deadlock where processor goroutine tries to reconnect while needing to exit
@@ -785,7 +1468,7 @@ func (ws *SaxoWebSocketClient) handleReconnectionRequests() {
 		"function", "handleReconnectionRequests")
 	for {
 		select {
-		case <-ws.ctx.Done():
+		case <-ws.done():
 			ws.logger.Info("Context canceled, exiting reconnection handler",
 				"function", "handleReconnectionRequests")
 			return
@@ -794,12 +1477,15 @@ func (ws *SaxoWebSocketClient) handleReconnectionRequests() {
 				"function", "handleReconnectionRequests",
 				"error", err)
 
-			// Wait 15 seconds before attempting reconnection (gives time for cleanup)
-			// Following legacy pattern - prevents rapid reconnection spam
-			time.Sleep(15 * time.Second)
-
-			// Attempt reconnection
-			reconnectErr := ws.reconnectWebSocket()
+			// Backoff and attempt limit come from the ReconnectPolicy (see SetReconnectPolicy)
+			reconnectErr := ws.retryWithPolicy("handleReconnectionRequests", func(int) error {
+				return ws.reconnectWebSocket()
+			})
+			if errors.Is(reconnectErr, errReconnectAborted) {
+				ws.logger.Info("Reconnection aborted, exiting reconnection handler",
+					"function", "handleReconnectionRequests")
+				return
+			}
 			if reconnectErr != nil {
 				ws.logger.Error("Reconnection failed",
 					"function", "handleReconnectionRequests",
@@ -808,6 +1494,20 @@ func (ws *SaxoWebSocketClient) handleReconnectionRequests() {
 				ws.logger.Info("Reconnection completed successfully",
 					"function", "handleReconnectionRequests")
 			}
+
+			// Requests queued while this sequence ran refer to the old connection
+			ws.drainReconnectionTriggers()
+		}
+	}
+}
+
+// drainReconnectionTriggers discards queued reconnection requests without blocking
+func (ws *SaxoWebSocketClient) drainReconnectionTriggers() {
+	for {
+		select {
+		case <-ws.reconnectionTrigger:
+		default:
+			return
 		}
 	}
 }
@@ -831,15 +1531,22 @@ func (ws *SaxoWebSocketClient) reconnectWebSocket() error {
 		ws.reconnectMu.Unlock()
 	}()
 
+	if ws.isShutdown() {
+		ws.logger.Info("Client shut down, skipping reconnection",
+			"function", "reconnectWebSocket")
+		return nil
+	}
+
 	ws.logger.Info("Reconnecting WebSocket",
 		"function", "reconnectWebSocket")
 
 	// CRITICAL: Close existing connection and wait for goroutines to exit
-	if ws.conn != nil {
+	if conn := ws.connection(); conn != nil {
 		// Cancel context to signal goroutines to stop (if context exists)
-		if ws.cancel != nil {
-			ws.cancel()
-		}
+		ws.cancelContext()
+
+		// Unblock the reader's pending ReadMessage (same as Close)
+		conn.SetReadDeadline(time.Now())
 
 		// Wait for reader to exit
 		ws.readerMu.Lock()
@@ -882,37 +1589,40 @@ func (ws *SaxoWebSocketClient) reconnectWebSocket() error {
 	// NOTE: Context will be created in EstablishConnection, not here
 	// Following legacy pattern where startWebSocket creates context right before goroutines
 
-	// Wait before reconnecting (exponential backoff)
-	backoffDuration := time.Second * 10
-	ws.logger.Info("Waiting before reconnection attempt",
-		"function", "reconnectWebSocket",
-		"backoff_duration", backoffDuration)
-	time.Sleep(backoffDuration)
+	// NOTE: No backoff here - callers wait ReconnectPolicy.Delay between attempts
 
 	// CRITICAL: Create fresh context AFTER old goroutines have exited
 	// The old ws.ctx was cancelled above to stop goroutines
 	// Now that they've exited, create a new context for the new connection
 	// This prevents DNS/connection failures on slow networks while avoiding race conditions
-	ws.ctx, ws.cancel = context.WithCancel(context.Background())
+	// A failed previous attempt (no connection to tear down) leaves its context behind - release it
+	ctx := ws.newConnContext(true)
 	ws.logger.Debug("Created fresh context for reconnection after goroutines exited",
 		"function", "reconnectWebSocket")
 
 	// Attempt to establish new connection
-	if err := ws.connectionManager.EstablishConnection(ws.ctx); err != nil {
+	if err := ws.connectionManager.EstablishConnection(ctx); err != nil {
 		ws.logger.Error("Failed to establish connection",
 			"function", "reconnectWebSocket",
 			"error", err)
 		return err
 	}
 
-	// Resubscribe to all previous subscriptions with new context ID and new reference IDs
-	if err := ws.subscriptionManager.HandleSubscriptions(nil); err != nil {
+	// Resubscribe to all previous subscriptions on the new context ID
+	if err := ws.subscriptionManager.ResubscribeAll(ws.connContext()); err != nil {
 		ws.logger.Error("Failed to resubscribe",
 			"function", "reconnectWebSocket",
 			"error", err)
 		return err
 	}
 
+	// Subscribe calls made while the connection was down
+	if err := ws.flushDeferredSubscriptions(ws.connContext()); err != nil {
+		ws.logger.Error("Some deferred subscriptions failed",
+			"function", "reconnectWebSocket",
+			"error", err)
+	}
+
 	ws.logger.Info("Reconnection completed successfully",
 		"function", "reconnectWebSocket")
 	return nil
@@ -920,7 +1630,7 @@ func (ws *SaxoWebSocketClient) reconnectWebSocket() error {
 
 // handleSessionEvent processes session event messages from the WebSocket stream
 // Pushes the event to sessionEventChan for the consumer (pivot-web2) to handle
-// Consumer is responsible for calling SetSessionCapabilities("FullTradingAndChat") if needed
+// Consumer decides whether to call SetTradeLevel("FullTradingAndChat") - the adapter never does
 func (ws *SaxoWebSocketClient) handleSessionEvent(payload []byte) {
 	var session SaxoSessionCapabilities
 	err := json.Unmarshal(payload, &session)
@@ -941,6 +1651,9 @@ func (ws *SaxoWebSocketClient) handleSessionEvent(payload []byte) {
 		"trade_level", update.TradeLevel,
 		"state", update.State)
 
+	if ws.emitEvent(saxo.StreamEvent{Kind: saxo.SessionEvent, Session: &update}) {
+		return
+	}
 	select {
 	case ws.sessionEventChan <- update:
 	default:
@@ -949,154 +1662,68 @@ func (ws *SaxoWebSocketClient) handleSessionEvent(payload []byte) {
 	}
 }
 
-// startTokenRefreshTimer sets up the initial token refresh timer
-// Returns the time until token expiry
-// Following legacy broker_websocket.go pattern (lines 213-261)
-func (c *SaxoWebSocketClient) startTokenRefreshTimer() time.Duration {
-	c.logger.Debug("Setting up token refresh timer",
-		"function", "startTokenRefreshTimer")
-
-	// Get current token to check expiry
-	// CRITICAL: We can't call getValidToken as that's in oauth package
-	// Instead, we rely on authClient being authenticated before WebSocket connection
+// startTokenRefresh subscribes to the auth client's token rotations (once per client)
+// Returns the time until token expiry, negative when no access token is available
+// Following legacy broker_websocket.go pattern (lines 213-261), minus the private timer
+func (c *SaxoWebSocketClient) startTokenRefresh() time.Duration {
 	accessToken, err := c.authClient.GetAccessToken()
 	if err != nil {
 		c.logger.Error("Failed to get access token",
-			"function", "startTokenRefreshTimer",
+			"function", "startTokenRefresh",
 			"error", err)
 		return -1 * time.Second
 	}
-
-	// Get token expiry - we need to call a method that gives us expiry time
-	// For now, assume standard 20-minute expiry from connection time
-	// TODO: Enhance AuthClient interface to expose token expiry time
-	expiryTime := 20 * time.Minute
-	c.logger.Debug("Token expiry estimated",
-		"function", "startTokenRefreshTimer",
-		"expiry_time", expiryTime)
-
-	// Stop any existing timer before creating a new one
-	if c.tokenRefreshTimer != nil {
-		if !c.tokenRefreshTimer.Stop() {
-			// Timer already fired or was stopped, drain the channel if needed
-			select {
-			case <-c.tokenRefreshTimer.C:
-			default:
-			}
-		}
-		c.logger.Debug("Stopped existing token refresh timer",
-			"function", "startTokenRefreshTimer")
+	if len(accessToken) == 0 {
+		c.logger.Warn("Access token is empty",
+			"function", "startTokenRefresh")
+		return -1 * time.Second
 	}
 
-	// Calculate when to fire: 2 minutes before token expires
-	// Following legacy pattern: fireIn = expiryTime - 2*time.Minute (~18 minutes)
-	fireIn := expiryTime - 2*time.Minute
-	if fireIn < 0 {
-		fireIn = 30 * time.Second // Token expires very soon, try again in 30s
-		c.logger.Warn("Token expires in less than 2 minutes, scheduling immediate retry",
-			"function", "startTokenRefreshTimer")
+	c.tokenMu.Lock()
+	if c.tokenUnsubscribe == nil {
+		c.tokenUnsubscribe = c.authClient.SubscribeTokenRefresh(c.ContextName(), c.reauthorizeOnRotation)
 	}
+	c.tokenMu.Unlock()
 
-	// Create timer that will call refreshTokenAndReschedule
-	// Following legacy pattern: time.AfterFunc with method reference
-	c.tokenRefreshTimer = time.AfterFunc(fireIn, c.refreshTokenAndReschedule)
-	c.logger.Debug("Timer set to fire before token expiry",
-		"function", "startTokenRefreshTimer",
-		"fire_in", fireIn)
+	return c.tokenTimeToExpiry()
+}
 
-	// Verify we have a valid token
-	if len(accessToken) == 0 {
-		c.logger.Warn("Access token is empty",
-			"function", "startTokenRefreshTimer")
-		return -1 * time.Second
+// tokenTimeToExpiry returns time until the access token expires
+// Falls back to the standard 20-minute Saxo token lifetime if the auth client has no expiry
+func (c *SaxoWebSocketClient) tokenTimeToExpiry() time.Duration {
+	expiry := c.authClient.GetTokenExpiry()
+	if expiry.IsZero() {
+		return 20 * time.Minute
 	}
-
-	return expiryTime
+	return expiry.Sub(c.clock.Now())
 }
 
-// refreshTokenAndReschedule is the callback that refreshes token and ALWAYS reschedules itself
+// reauthorizeOnRotation re-authorizes the stream with the token the coordinator just rotated
 // Following legacy broker_websocket.go pattern (lines 263-308)
-func (c *SaxoWebSocketClient) refreshTokenAndReschedule() {
-	// CRITICAL: Always reschedule timer at the end, regardless of success/failure
-	// Following legacy pattern with defer
-	defer func() {
-		if r := recover(); r != nil {
-			c.logger.Error("Panic in refreshTokenAndReschedule",
-				"function", "refreshTokenAndReschedule",
-				"panic", r)
-			// Even on panic, try to reschedule
-			c.scheduleNextRefresh()
-			return
-		}
-		// Normal path: reschedule at the end
-		c.scheduleNextRefresh()
-	}()
-
-	c.logger.Debug("Timer fired, checking if refresh needed",
-		"function", "refreshTokenAndReschedule")
-
-	// Check if WebSocket connection exists
+func (c *SaxoWebSocketClient) reauthorizeOnRotation(ctx context.Context, token saxo.TokenInfo) {
 	// Following legacy pattern: if ws.Connection == nil (line 293)
-	if c.conn == nil {
+	if c.connection() == nil {
 		c.logger.Debug("No WebSocket connection to reauthorize",
-			"function", "refreshTokenAndReschedule")
-		return // Still reschedules via defer
+			"function", "reauthorizeOnRotation")
+		return
 	}
-
-	// Check if we have a context ID
-	if c.contextID == "" {
+	contextID := c.currentContextID()
+	if contextID == "" {
 		c.logger.Debug("No context ID available",
-			"function", "refreshTokenAndReschedule")
+			"function", "reauthorizeOnRotation")
 		return
 	}
 
-	// Perform the token refresh via WebSocket reauthorization
-	// Following legacy pattern: ws.reAuthoriseWebSocket() (line 300)
+	// Token is already fresh - ReauthorizeWebSocket does not rotate it again
 	c.logger.Info("Attempting to reauthorize WebSocket connection",
-		"function", "refreshTokenAndReschedule")
-	err := c.authClient.ReauthorizeWebSocket(context.Background(), c.contextID)
-	if err != nil {
+		"function", "reauthorizeOnRotation",
+		"expiry", token.Expiry)
+	if err := c.authClient.ReauthorizeWebSocket(ctx, contextID); err != nil {
 		c.logger.Error("Reauthorization failed",
-			"function", "refreshTokenAndReschedule",
+			"function", "reauthorizeOnRotation",
 			"error", err)
 		return
 	}
-	c.logger.Info("Token refreshed successfully",
-		"function", "refreshTokenAndReschedule")
-}
-
-// scheduleNextRefresh calculates when the next refresh should occur and schedules it
-// Following legacy broker_websocket.go pattern (lines 310-344)
-func (c *SaxoWebSocketClient) scheduleNextRefresh() {
-	// Assume standard 20-minute token expiry
-	// In production, token was just refreshed, so we have fresh 20 minutes
-	expiryTime := 20 * time.Minute
-
-	// Calculate next fire time: 2 minutes before expiry
-	// Following legacy pattern: nextFire = expiryTime - 2*time.Minute
-	nextFire := expiryTime - 2*time.Minute
-
-	// If token expires in less than 2 minutes, try again soon
-	if nextFire < 0 {
-		nextFire = 30 * time.Second
-		c.logger.Warn("Token expires soon, will retry in 30s",
-			"function", "scheduleNextRefresh")
-	}
-
-	// Reset the timer
-	// Following legacy pattern: ws.tokenRefreshTimer.Reset(nextFire)
-	if c.tokenRefreshTimer != nil {
-		c.tokenRefreshTimer.Reset(nextFire)
-		c.logger.Debug("Timer rescheduled",
-			"function", "scheduleNextRefresh",
-			"next_fire", nextFire)
-	} else {
-		// Timer was nil (shouldn't happen, but handle it)
-		c.logger.Warn("Timer was nil, creating new timer",
-			"function", "scheduleNextRefresh")
-		c.tokenRefreshTimer = time.AfterFunc(nextFire, c.refreshTokenAndReschedule)
-		c.logger.Debug("New timer created",
-			"function", "scheduleNextRefresh",
-			"next_fire", nextFire)
-	}
+	c.logger.Info("WebSocket reauthorized successfully",
+		"function", "reauthorizeOnRotation")
 }
@@ -2,12 +2,17 @@ package websocket
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"runtime"
+	"sync"
 	"testing"
 	"time"
 
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
 	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
 )
 
@@ -16,6 +21,10 @@ type MockAuthClient struct {
 	authenticated bool
 	accessToken   string
 	httpClient    *http.Client
+
+	mu             sync.Mutex
+	tokenListeners []saxo.TokenListener
+	reauthorized   []string
 }
 
 func (m *MockAuthClient) IsAuthenticated() bool           { return m.authenticated }
@@ -33,6 +42,9 @@ func (m *MockAuthClient) StartAuthenticationKeeper(provider string) {}
 func (m *MockAuthClient) StartTokenEarlyRefresh(ctx context.Context, wsConnected <-chan bool, wsContextID <-chan string) {
 }
 func (m *MockAuthClient) ReauthorizeWebSocket(ctx context.Context, contextID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reauthorized = append(m.reauthorized, contextID)
 	return nil
 }
 
@@ -67,6 +79,23 @@ func (m *MockAuthClient) ExchangeCodeForToken(ctx context.Context, code string,
 	return nil
 }
 
+// GetTokenExpiry returns a fixed 20-minute expiry (mock implementation)
+func (m *MockAuthClient) GetTokenExpiry() time.Time { return time.Now().Add(20 * time.Minute) }
+
+// GetRefreshExpiry returns a fixed 1-hour refresh expiry (mock implementation)
+func (m *MockAuthClient) GetRefreshExpiry() time.Time { return time.Now().Add(time.Hour) }
+
+// TokenEvents returns a channel that never fires (mock implementation)
+func (m *MockAuthClient) TokenEvents() <-chan saxo.TokenEvent { return make(chan saxo.TokenEvent) }
+
+// SubscribeTokenRefresh keeps the listener so tests can simulate a rotation (mock implementation)
+func (m *MockAuthClient) SubscribeTokenRefresh(name string, listener saxo.TokenListener) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokenListeners = append(m.tokenListeners, listener)
+	return func() {}
+}
+
 func TestSaxoWebSocketClient_Connect(t *testing.T) {
 	// Setup mock server following legacy WebSocket testing patterns
 	mockServer := mocktesting.NewMockSaxoWebSocketServer()
@@ -103,6 +132,70 @@ func TestSaxoWebSocketClient_Connect(t *testing.T) {
 	}
 }
 
+func TestSaxoWebSocketClient_HTTPClientOverridesTransportForDial(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	// The proxy is unreachable - the dial only succeeds when WithHTTPClient wins
+	deadProxy, _ := url.Parse("http://127.0.0.1:1")
+	mockAuth := &MockAuthClient{authenticated: true, accessToken: "test_token_123"}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger,
+		saxo.WithHTTPClient(mockServer.GetHTTPClient()),
+		saxo.WithTransport(saxo.TransportConfig{ProxyURL: deadProxy}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Expected the dial to use the injected HTTP client, got %v", err)
+	}
+	client.Close()
+}
+
+func TestSaxoWebSocketClient_ReauthorizesOnTokenRotation(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	// Reconnects must not add listeners - the coordinator would re-authorize twice per rotation
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Reconnect failed: %v", err)
+	}
+
+	mockAuth.mu.Lock()
+	listeners := append([]saxo.TokenListener(nil), mockAuth.tokenListeners...)
+	mockAuth.mu.Unlock()
+	if len(listeners) != 1 {
+		t.Fatalf("Expected exactly 1 token listener, got %d", len(listeners))
+	}
+
+	// Simulate the coordinator rotating the token
+	listeners[0](ctx, saxo.TokenInfo{AccessToken: "rotated", Expiry: time.Now().Add(20 * time.Minute)})
+
+	mockAuth.mu.Lock()
+	defer mockAuth.mu.Unlock()
+	if len(mockAuth.reauthorized) != 1 || mockAuth.reauthorized[0] != client.currentContextID() {
+		t.Errorf("Expected one re-authorization for context %s, got %v", client.currentContextID(), mockAuth.reauthorized)
+	}
+}
+
 func TestSaxoWebSocketClient_PriceSubscription(t *testing.T) {
 	// Setup mock server and client
 	mockServer := mocktesting.NewMockSaxoWebSocketServer()
@@ -263,6 +356,50 @@ func TestSaxoWebSocketClient_OrderUpdates(t *testing.T) {
 	}
 }
 
+func TestSaxoWebSocketClient_SessionEvents(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+	mockServer.SetSessionTradeLevel("OrdersOnly", "Premium")
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SubscribeToSessionEvents(ctx); err != nil {
+		t.Fatalf("Failed to subscribe to session events: %v", err)
+	}
+
+	// Snapshot first, then the streamed upgrade
+	for _, want := range []string{"OrdersOnly", "FullTradingAndChat"} {
+		if want == "FullTradingAndChat" {
+			mockServer.SetSessionTradeLevel(want, "Premium")
+			if err := mockServer.SendSessionEvent(); err != nil {
+				t.Fatalf("SendSessionEvent failed: %v", err)
+			}
+		}
+		select {
+		case update := <-client.GetSessionEventChannel():
+			if update.TradeLevel != want {
+				t.Errorf("Expected TradeLevel %s, got %+v", want, update)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timeout waiting for %s session event", want)
+		}
+	}
+}
+
 // Benchmark WebSocket message processing performance
 func BenchmarkMessageProcessing(b *testing.B) {
 	mockServer := mocktesting.NewMockSaxoWebSocketServer()
@@ -290,3 +427,215 @@ func BenchmarkMessageProcessing(b *testing.B) {
 		mockServer.SendPriceUpdate("21", 1.1000+float64(i)*0.0001, 1.1002+float64(i)*0.0001)
 	}
 }
+
+func TestSaxoWebSocketClient_Shutdown(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	baseline := runtime.NumGoroutine()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if err := client.SubscribeToPrices(ctx, []string{"21"}, "FxSpot"); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	if got := len(mockServer.GetActiveSubscriptions()); got != 1 {
+		t.Fatalf("Expected 1 server-side subscription, got %d", got)
+	}
+
+	if err := saxo.Shutdown(ctx, client); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	// Server-side subscriptions deleted
+	if got := len(mockServer.GetActiveSubscriptions()); got != 0 {
+		t.Errorf("Expected 0 server-side subscriptions after shutdown, got %d", got)
+	}
+
+	// Update channels closed - consumers ranging over them terminate
+	select {
+	case _, ok := <-client.GetPriceUpdateChannel():
+		if ok {
+			t.Error("Expected price channel to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Error("Price channel not closed after shutdown")
+	}
+
+	// No new subscriptions after shutdown
+	if err := client.SubscribeToPrices(ctx, []string{"21"}, "FxSpot"); err == nil {
+		t.Error("Expected subscription after shutdown to fail")
+	}
+
+	// No goroutine leaks (allow the HTTP transport's idle connections to wind down)
+	mockServer.GetHTTPClient().CloseIdleConnections()
+	deadline := time.Now().Add(3 * time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if leaked := runtime.NumGoroutine() - baseline; leaked > 0 {
+		t.Errorf("Goroutine leak after shutdown: %d extra goroutines", leaked)
+	}
+
+	// Idempotent
+	if err := client.Shutdown(ctx); err != nil {
+		t.Errorf("Second Shutdown failed: %v", err)
+	}
+}
+
+func TestSaxoWebSocketClient_CloseBeforeConnect(t *testing.T) {
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, "http://unused.invalid", "http://unused.invalid", logger)
+
+	// Never connected - must not panic, and repeated calls are no-ops
+	for i := 0; i < 2; i++ {
+		if err := client.Close(); err != nil {
+			t.Errorf("Close call %d failed: %v", i+1, err)
+		}
+	}
+}
+
+func TestSaxoWebSocketClient_IdempotentLifecycle(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	stateChannel := make(chan bool, 1)
+	contextIDChannel := make(chan string, 1)
+	client.SetStateChannels(stateChannel, contextIDChannel)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	select {
+	case connected := <-stateChannel:
+		if !connected {
+			t.Error("Expected connected=true after Connect")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No connection state published")
+	}
+	select {
+	case contextID := <-contextIDChannel:
+		if contextID == "" || contextID != client.currentContextID() {
+			t.Errorf("Expected context ID %q, got %q", client.currentContextID(), contextID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No context ID published")
+	}
+
+	// Second Connect is a no-op - same session, no new state published
+	contextID := client.currentContextID()
+	if err := client.Connect(ctx); err != nil {
+		t.Errorf("Second Connect failed: %v", err)
+	}
+	if client.currentContextID() != contextID {
+		t.Errorf("Second Connect replaced context ID %q with %q", contextID, client.currentContextID())
+	}
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	select {
+	case connected := <-stateChannel:
+		if connected {
+			t.Error("Expected connected=false after Close")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No disconnect state published")
+	}
+
+	if err := client.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+}
+
+// stubClientInfoProvider counts GetClientInfo calls (stands in for an existing BrokerClient)
+type stubClientInfoProvider struct {
+	calls int
+}
+
+func (s *stubClientInfoProvider) GetClientInfo(ctx context.Context) (*saxo.ClientInfo, error) {
+	s.calls++
+	return &saxo.ClientInfo{ClientKey: "injected-client-key"}, nil
+}
+
+func TestSaxoWebSocketClient_ClientKeyLookup(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ctx := context.Background()
+
+	// Injected provider is used once, then cached
+	provider := &stubClientInfoProvider{}
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+	client.SetClientInfoProvider(provider)
+	for i := 0; i < 2; i++ {
+		if err := client.ensureClientKey(ctx); err != nil {
+			t.Fatalf("ensureClientKey failed: %v", err)
+		}
+	}
+	if provider.calls != 1 {
+		t.Errorf("Expected 1 provider call, got %d", provider.calls)
+	}
+	if client.clientKey != "injected-client-key" {
+		t.Errorf("Expected injected ClientKey, got %q", client.clientKey)
+	}
+
+	// Without a provider the client looks up /port/v1/users/me itself
+	client = NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+	if err := client.ensureClientKey(ctx); err != nil {
+		t.Fatalf("ensureClientKey without provider failed: %v", err)
+	}
+	if client.clientKey != mocktesting.MockClientKey {
+		t.Errorf("Expected ClientKey %q, got %q", mocktesting.MockClientKey, client.clientKey)
+	}
+
+	// Preset ClientKey skips the lookup entirely
+	provider = &stubClientInfoProvider{}
+	client = NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+	client.SetClientInfoProvider(provider)
+	client.SetClientKey("preset-client-key")
+	if err := client.ensureClientKey(ctx); err != nil {
+		t.Fatalf("ensureClientKey with preset key failed: %v", err)
+	}
+	if provider.calls != 0 {
+		t.Errorf("Expected no provider calls with preset ClientKey, got %d", provider.calls)
+	}
+}
@@ -0,0 +1,109 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Typed payloads of the /port/v1 streaming subscriptions, mirroring the REST structs in saxo/types.go
+// CRITICAL: after the snapshot Saxo only sends the fields that changed, so every field is a pointer
+// (nil = unchanged) and consumers must merge deltas into the last known state
+
+// StreamingOrder is one element of a /port/v1/orders message (mirrors saxo.SaxoOpenOrder)
+// Following legacy pivot-web/strategy_manager/streaming_orders.go:13-75 StreamingOrders struct
+type StreamingOrder struct {
+	OrderID       string   `json:"OrderId"`
+	Status        *string  `json:"Status,omitempty"`
+	FilledAmount  *float64 `json:"FilledAmount,omitempty"`
+	OpenOrderType *string  `json:"OpenOrderType,omitempty"`
+	Price         *float64 `json:"Price,omitempty"`
+	Uic           *int     `json:"Uic,omitempty"`
+	Amount        *float64 `json:"Amount,omitempty"`
+	BuySell       *string  `json:"BuySell,omitempty"`
+	AssetType     *string  `json:"AssetType,omitempty"`
+	AccountKey    *string  `json:"AccountKey,omitempty"`
+	OrderRelation *string  `json:"OrderRelation,omitempty"` // "IfDoneMaster", "IfDoneSlaveOco", "Oco", "StandAlone"
+
+	// Requested with FieldGroups DisplayAndFormat, usually only on the first message of an order
+	DisplayAndFormat *StreamingDisplayAndFormat `json:"DisplayAndFormat,omitempty"`
+
+	// Phase 1: entry order with nested exit orders
+	RelatedOpenOrders []StreamingRelatedOrder `json:"RelatedOpenOrders,omitempty"`
+
+	// Order deletion marker - fill messages often carry only OrderId and this flag
+	MetaDeleted *bool `json:"__meta_deleted,omitempty"`
+}
+
+// StreamingDisplayAndFormat is the DisplayAndFormat field group of a streamed order
+type StreamingDisplayAndFormat struct {
+	Symbol      string `json:"Symbol"`
+	Description string `json:"Description"`
+}
+
+// StreamingRelatedOrder is an exit order nested in StreamingOrder (mirrors saxo.SaxoRelatedOrder)
+type StreamingRelatedOrder struct {
+	OrderID       string   `json:"OrderId"`
+	OpenOrderType *string  `json:"OpenOrderType,omitempty"`
+	OrderPrice    *float64 `json:"OrderPrice,omitempty"`
+	Amount        *float64 `json:"Amount,omitempty"`
+	Status        *string  `json:"Status,omitempty"`
+	MetaDeleted   *bool    `json:"__meta_deleted,omitempty"`
+}
+
+// StreamingBalance is a /port/v1/balances message (mirrors saxo.SaxoBalance)
+type StreamingBalance struct {
+	CashBalance                  *float64 `json:"CashBalance,omitempty"`
+	Currency                     *string  `json:"Currency,omitempty"`
+	MarginAvailableForTrading    *float64 `json:"MarginAvailableForTrading,omitempty"`
+	MarginUsedByCurrentPositions *float64 `json:"MarginUsedByCurrentPositions,omitempty"`
+	MarginUtilizationPct         *float64 `json:"MarginUtilizationPct,omitempty"`
+	NetEquityForMargin           *float64 `json:"NetEquityForMargin,omitempty"`
+	TotalValue                   *float64 `json:"TotalValue,omitempty"`
+	UnrealizedMarginProfitLoss   *float64 `json:"UnrealizedMarginProfitLoss,omitempty"`
+	OpenPositionsCount           *int     `json:"OpenPositionsCount,omitempty"`
+	OrdersCount                  *int     `json:"OrdersCount,omitempty"`
+}
+
+// StreamingPosition is one element of a /port/v1/positions message (mirrors saxo.SaxoOpenPosition)
+// Decode custom positions subscriptions (RegisterSubscription) with DecodePositions
+type StreamingPosition struct {
+	PositionID    string                 `json:"PositionId"`
+	NetPositionID *string                `json:"NetPositionId,omitempty"`
+	PositionBase  *StreamingPositionBase `json:"PositionBase,omitempty"`
+	PositionView  *StreamingPositionView `json:"PositionView,omitempty"`
+	MetaDeleted   *bool                  `json:"__meta_deleted,omitempty"`
+}
+
+// StreamingPositionBase mirrors SaxoOpenPosition.PositionBase
+type StreamingPositionBase struct {
+	AccountKey    *string  `json:"AccountKey,omitempty"`
+	Amount        *float64 `json:"Amount,omitempty"`
+	AssetType     *string  `json:"AssetType,omitempty"`
+	OpenPrice     *float64 `json:"OpenPrice,omitempty"`
+	SourceOrderID *string  `json:"SourceOrderId,omitempty"`
+	Status        *string  `json:"Status,omitempty"`
+	Uic           *int     `json:"Uic,omitempty"`
+}
+
+// StreamingPositionView mirrors SaxoOpenPosition.PositionView
+type StreamingPositionView struct {
+	Ask                             *float64 `json:"Ask,omitempty"`
+	Bid                             *float64 `json:"Bid,omitempty"`
+	CurrentPrice                    *float64 `json:"CurrentPrice,omitempty"`
+	Exposure                        *float64 `json:"Exposure,omitempty"`
+	ProfitLossOnTrade               *float64 `json:"ProfitLossOnTrade,omitempty"`
+	ProfitLossOnTradeInBaseCurrency *float64 `json:"ProfitLossOnTradeInBaseCurrency,omitempty"`
+}
+
+// DecodePositions parses a /port/v1/positions payload - an array, or a single object
+func DecodePositions(payload []byte) ([]StreamingPosition, error) {
+	var positions []StreamingPosition
+	if err := json.Unmarshal(payload, &positions); err == nil {
+		return positions, nil
+	}
+	var position StreamingPosition
+	if err := json.Unmarshal(payload, &position); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal position data: %w", err)
+	}
+	return []StreamingPosition{position}, nil
+}
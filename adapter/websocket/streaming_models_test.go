@@ -0,0 +1,165 @@
+package websocket
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+func newModelTestClient() *SaxoWebSocketClient {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewSaxoWebSocketClient(&MockAuthClient{authenticated: true}, "https://localhost", "wss://localhost", logger)
+}
+
+func TestMessageHandler_BalanceDeltasMerge(t *testing.T) {
+	client := newModelTestClient()
+
+	snapshot := `{"TotalValue":10500,"CashBalance":10000,"MarginUsedByCurrentPositions":250,
+		"MarginAvailableForTrading":10250,"UnrealizedMarginProfitLoss":500,"Currency":"EUR"}`
+	if err := client.messageHandler.handlePortfolioUpdate("balance_1", []byte(snapshot)); err != nil {
+		t.Fatalf("handlePortfolioUpdate failed: %v", err)
+	}
+	first := <-client.GetPortfolioUpdateChannel()
+	if first.Balance != 10500 || first.MarginUsed != 250 || first.MarginFree != 10250 || first.Currency != "EUR" {
+		t.Errorf("Unexpected snapshot %+v", first)
+	}
+
+	// Delta carries only the changed fields
+	if err := client.messageHandler.handlePortfolioUpdate("balance_1", []byte(`{"MarginUsedByCurrentPositions":300}`)); err != nil {
+		t.Fatalf("handlePortfolioUpdate failed: %v", err)
+	}
+	second := <-client.GetPortfolioUpdateChannel()
+	if second.MarginUsed != 300 {
+		t.Errorf("Expected margin used 300, got %v", second.MarginUsed)
+	}
+	if second.Balance != 10500 || second.MarginFree != 10250 || second.CashBalance != 10000 || second.UnrealizedProfitLoss != 500 {
+		t.Errorf("Expected unchanged fields kept, got %+v", second)
+	}
+}
+
+func TestMessageHandler_BalancesMergePerSubscription(t *testing.T) {
+	client := newModelTestClient()
+	mh := client.messageHandler
+	sm := client.subscriptionManager
+	sm.subscriptionMu.Lock()
+	sm.registerLocked("balance_a", &Subscription{ReferenceId: "balance_a", Handler: mh.routePortfolioUpdate})
+	sm.registerLocked("balance_b", &Subscription{ReferenceId: "balance_b", Handler: mh.routePortfolioUpdate})
+	sm.subscriptionMu.Unlock()
+
+	// One snapshot per account, then a delta for the first
+	updates := []struct{ referenceID, payload string }{
+		{"balance_a", `{"TotalValue":10500,"CashBalance":10000,"Currency":"EUR"}`},
+		{"balance_b", `{"TotalValue":2000,"CashBalance":1800,"Currency":"USD"}`},
+		{"balance_a", `{"TotalValue":10600}`},
+	}
+	for _, update := range updates {
+		if err := mh.routePortfolioUpdate(update.referenceID, []byte(update.payload)); err != nil {
+			t.Fatalf("routePortfolioUpdate failed: %v", err)
+		}
+	}
+	<-client.GetPortfolioUpdateChannel()
+	second := <-client.GetPortfolioUpdateChannel()
+	if second.Balance != 2000 || second.CashBalance != 1800 || second.Currency != "USD" {
+		t.Errorf("Expected the second account's own balance, got %+v", second)
+	}
+	third := <-client.GetPortfolioUpdateChannel()
+	if third.Balance != 10600 || third.CashBalance != 10000 || third.Currency != "EUR" {
+		t.Errorf("Expected the delta merged into the first account's balance, got %+v", third)
+	}
+}
+
+func TestMessageHandler_TypedOrderUpdates(t *testing.T) {
+	client := newModelTestClient()
+
+	payload := `[
+		{"OrderId":"100","Status":"Working","OpenOrderType":"Limit","Price":1.085,"Uic":21,"Amount":1000,
+		 "OrderRelation":"IfDoneMaster","RelatedOpenOrders":[{"OrderId":"101","OpenOrderType":"StopIfTraded","OrderPrice":1.08,"Amount":1000}]},
+		{"OrderId":"102","__meta_deleted":true},
+		{"Status":"Working"}
+	]`
+	if err := client.messageHandler.handleOrderUpdate([]byte(payload)); err != nil {
+		t.Fatalf("handleOrderUpdate failed: %v", err)
+	}
+
+	entry := <-client.GetOrderUpdateChannel()
+	if entry.OrderId != "100" || entry.Status != "Working" || entry.OrderPrice != 1.085 || entry.OrderRelation != "IfDoneMaster" {
+		t.Errorf("Unexpected entry update %+v", entry)
+	}
+	if entry.Uic == nil || *entry.Uic != 21 || entry.Amount == nil || *entry.Amount != 1000 {
+		t.Errorf("Expected Uic and Amount set, got %v %v", entry.Uic, entry.Amount)
+	}
+	if len(entry.RelatedOpenOrders) != 1 || entry.RelatedOpenOrders[0].OrderPrice != 1.08 {
+		t.Errorf("Unexpected related orders %+v", entry.RelatedOpenOrders)
+	}
+
+	deleted := <-client.GetOrderUpdateChannel()
+	if deleted.OrderId != "102" || deleted.MetaDeleted == nil || !*deleted.MetaDeleted || deleted.Uic != nil {
+		t.Errorf("Expected bare deletion for 102, got %+v", deleted)
+	}
+
+	// Element without OrderId is skipped
+	select {
+	case extra := <-client.GetOrderUpdateChannel():
+		t.Errorf("Expected order without OrderId skipped, got %+v", extra)
+	default:
+	}
+}
+
+func TestMessageHandler_OrderUpdateContext(t *testing.T) {
+	store := saxo.NewInstrumentStore(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err := store.Put(saxo.InstrumentMetadata{Uic: 21, Ticker: "EURUSD", Symbol: "EURUSD", Description: "Euro/US Dollar", AssetType: "FxSpot"}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoWebSocketClient(&MockAuthClient{authenticated: true}, "https://localhost", "wss://localhost", logger, saxo.WithInstrumentStore(store))
+
+	// The first message carries the context, the fill that follows only status and amount
+	messages := []string{
+		`[{"OrderId":"200","Status":"Working","OpenOrderType":"Limit","Price":1.085,"Uic":21,"Amount":1000,"BuySell":"Buy",
+		   "AssetType":"FxSpot","AccountKey":"acc-1","DisplayAndFormat":{"Symbol":"EURUSD","Description":"Euro/US Dollar"}}]`,
+		`[{"OrderId":"200","Status":"Filled","FilledAmount":1000}]`,
+		`[{"OrderId":"200","__meta_deleted":true}]`,
+	}
+	var updates []saxo.OrderUpdate
+	for _, message := range messages {
+		if err := client.messageHandler.handleOrderUpdate([]byte(message)); err != nil {
+			t.Fatalf("handleOrderUpdate failed: %v", err)
+		}
+		updates = append(updates, <-client.GetOrderUpdateChannel())
+	}
+
+	working, fill, deleted := updates[0], updates[1], updates[2]
+	if working.AssetType != "FxSpot" || working.AccountKey != "acc-1" || working.Symbol != "EURUSD" || working.Ticker != "EURUSD" {
+		t.Errorf("Unexpected context on the working order %+v", working)
+	}
+	if fill.Status != "Filled" || fill.FilledSize != 1000 || fill.Uic == nil || *fill.Uic != 21 || fill.BuySell != "Buy" ||
+		fill.OrderPrice != 1.085 || fill.OpenOrderType != "Limit" || fill.AccountKey != "acc-1" ||
+		fill.Symbol != "EURUSD" || fill.Description != "Euro/US Dollar" || fill.Ticker != "EURUSD" {
+		t.Errorf("Expected the fill to carry the order's context, got %+v", fill)
+	}
+
+	// The filled order was forgotten - the deletion after it is bare
+	if deleted.MetaDeleted == nil || deleted.Uic != nil || deleted.AccountKey != "" {
+		t.Errorf("Expected a bare deletion after the fill, got %+v", deleted)
+	}
+}
+
+func TestDecodePositions(t *testing.T) {
+	positions, err := DecodePositions([]byte(`[{"PositionId":"p1","PositionBase":{"Uic":21,"Amount":-5000},"PositionView":{"ProfitLossOnTrade":12.5}}]`))
+	if err != nil {
+		t.Fatalf("DecodePositions failed: %v", err)
+	}
+	if len(positions) != 1 || positions[0].PositionBase == nil || *positions[0].PositionBase.Amount != -5000 {
+		t.Fatalf("Unexpected positions %+v", positions)
+	}
+	if positions[0].PositionBase.OpenPrice != nil {
+		t.Error("Expected fields missing from the delta to stay nil")
+	}
+
+	single, err := DecodePositions([]byte(`{"PositionId":"p2","__meta_deleted":true}`))
+	if err != nil || len(single) != 1 || single[0].PositionID != "p2" || single[0].MetaDeleted == nil {
+		t.Errorf("Expected single object decoded, got %+v (%v)", single, err)
+	}
+}
@@ -0,0 +1,217 @@
+package websocket
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Heartbeat reasons sent by Saxo in _heartbeat control messages
+// Reference: https://www.developer.saxo/openapi/learn/plain-websocket-streaming
+const (
+	HeartbeatReasonNoNewData           = "NoNewData"
+	HeartbeatReasonTemporarilyDisabled = "SubscriptionTemporarilyDisabled"
+	HeartbeatReasonPermanentlyDisabled = "SubscriptionPermanentlyDisabled"
+)
+
+// SubscriptionHealthState is the health of one subscription as seen from data and heartbeats
+type SubscriptionHealthState string
+
+const (
+	// HealthActive - data messages are arriving
+	HealthActive SubscriptionHealthState = "Active"
+	// HealthIdle - Saxo reports NoNewData (healthy, market quiet or closed)
+	HealthIdle SubscriptionHealthState = "Idle"
+	// HealthTemporarilyDisabled - Saxo paused the subscription and resumes it itself
+	// Timeout detection is paused until data or NoNewData arrives again
+	HealthTemporarilyDisabled SubscriptionHealthState = "TemporarilyDisabled"
+	// HealthPermanentlyDisabled - Saxo stopped the subscription; it is resubscribed automatically
+	HealthPermanentlyDisabled SubscriptionHealthState = "PermanentlyDisabled"
+	// HealthRecovering - targeted resubscription in progress
+	HealthRecovering SubscriptionHealthState = "Recovering"
+)
+
+// SubscriptionHealth is a point-in-time view of one subscription
+type SubscriptionHealth struct {
+	ReferenceID     string
+	SubscriptionKey string // SubscriptionManager map key, e.g. "price_feed_FxSpot" ("" if no longer tracked)
+	State           SubscriptionHealthState
+	LastReason      string    // Last heartbeat reason received ("" if none yet)
+	LastMessage     time.Time // Last data message or heartbeat
+	StateSince      time.Time
+	Recoveries      int // Automatic resubscriptions after permanent disable
+}
+
+// subscriptionHealthTracker keeps per-ReferenceId health, fed by data messages and heartbeats
+type subscriptionHealthTracker struct {
+	mu      sync.Mutex
+	entries map[string]*SubscriptionHealth
+}
+
+func newSubscriptionHealthTracker() *subscriptionHealthTracker {
+	return &subscriptionHealthTracker{entries: make(map[string]*SubscriptionHealth)}
+}
+
+// entryLocked returns the entry for referenceID, creating it as Active
+func (ht *subscriptionHealthTracker) entryLocked(referenceID string, now time.Time) *SubscriptionHealth {
+	entry, ok := ht.entries[referenceID]
+	if !ok {
+		entry = &SubscriptionHealth{ReferenceID: referenceID, State: HealthActive, StateSince: now}
+		ht.entries[referenceID] = entry
+	}
+	return entry
+}
+
+func (entry *SubscriptionHealth) setState(state SubscriptionHealthState, now time.Time) {
+	if entry.State != state {
+		entry.State = state
+		entry.StateSince = now
+	}
+}
+
+// recordData marks the subscription active - any data means Saxo resumed it
+func (ht *subscriptionHealthTracker) recordData(referenceID string, now time.Time) {
+	ht.mu.Lock()
+	defer ht.mu.Unlock()
+	entry := ht.entryLocked(referenceID, now)
+	entry.LastMessage = now
+	entry.setState(HealthActive, now)
+}
+
+// recordHeartbeat applies a heartbeat reason
+// Returns true when the caller should start a targeted recovery (first permanent disable only)
+func (ht *subscriptionHealthTracker) recordHeartbeat(referenceID, reason string, now time.Time) bool {
+	ht.mu.Lock()
+	defer ht.mu.Unlock()
+	entry := ht.entryLocked(referenceID, now)
+	entry.LastMessage = now
+	entry.LastReason = reason
+
+	switch reason {
+	case HeartbeatReasonNoNewData:
+		entry.setState(HealthIdle, now)
+	case HeartbeatReasonTemporarilyDisabled:
+		entry.setState(HealthTemporarilyDisabled, now)
+	case HeartbeatReasonPermanentlyDisabled:
+		if entry.State == HealthRecovering {
+			return false
+		}
+		entry.setState(HealthRecovering, now)
+		return true
+	}
+	return false
+}
+
+// finishRecovery leaves a still-pending entry PermanentlyDisabled so the next heartbeat retries
+// After a successful resubscription the entry was already renamed, so this is a no-op
+func (ht *subscriptionHealthTracker) finishRecovery(referenceID string, now time.Time) {
+	ht.mu.Lock()
+	defer ht.mu.Unlock()
+	if entry, ok := ht.entries[referenceID]; ok && entry.State == HealthRecovering {
+		entry.setState(HealthPermanentlyDisabled, now)
+	}
+}
+
+// rename moves health from a replaced ReferenceId to its successor (see SubscriptionManager.Resubscribe)
+// A pending recovery completes here: the new subscription starts Active
+func (ht *subscriptionHealthTracker) rename(oldReferenceID, newReferenceID string, now time.Time) {
+	ht.mu.Lock()
+	defer ht.mu.Unlock()
+	entry, ok := ht.entries[oldReferenceID]
+	if !ok {
+		return
+	}
+	delete(ht.entries, oldReferenceID)
+	entry.ReferenceID = newReferenceID
+	if entry.State == HealthRecovering || entry.State == HealthPermanentlyDisabled {
+		entry.Recoveries++
+		entry.setState(HealthActive, now)
+	}
+	ht.entries[newReferenceID] = entry
+}
+
+// remove forgets a subscription that was unsubscribed
+func (ht *subscriptionHealthTracker) remove(referenceID string) {
+	ht.mu.Lock()
+	defer ht.mu.Unlock()
+	delete(ht.entries, referenceID)
+}
+
+// paused reports whether timeout detection should skip referenceID
+func (ht *subscriptionHealthTracker) paused(referenceID string) bool {
+	ht.mu.Lock()
+	defer ht.mu.Unlock()
+	entry, ok := ht.entries[referenceID]
+	return ok && (entry.State == HealthTemporarilyDisabled || entry.State == HealthRecovering)
+}
+
+// snapshot copies all entries sorted by ReferenceId
+func (ht *subscriptionHealthTracker) snapshot() []SubscriptionHealth {
+	ht.mu.Lock()
+	defer ht.mu.Unlock()
+	result := make([]SubscriptionHealth, 0, len(ht.entries))
+	for _, entry := range ht.entries {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ReferenceID < result[j].ReferenceID })
+	return result
+}
+
+// SubscriptionHealth returns the health of every subscription that has received data or heartbeats
+// SubscriptionKey is filled from the SubscriptionManager so callers can tell prices from orders etc.
+func (ws *SaxoWebSocketClient) SubscriptionHealth() []SubscriptionHealth {
+	health := ws.subscriptionHealth.snapshot()
+	keys := ws.subscriptionManager.subscriptionKeysByReferenceId()
+	for i := range health {
+		health[i].SubscriptionKey = keys[health[i].ReferenceID]
+	}
+	return health
+}
+
+// handleSubscriptionHeartbeat turns a heartbeat reason into health state and recovery actions
+func (ws *SaxoWebSocketClient) handleSubscriptionHeartbeat(referenceID, reason string) {
+	now := ws.clock.Now()
+	switch reason {
+	case HeartbeatReasonNoNewData:
+		// Normal heartbeat - subscription alive, update timestamp
+		ws.UpdateLastMessageTimestamp(referenceID)
+		ws.subscriptionHealth.recordHeartbeat(referenceID, reason, now)
+
+	case HeartbeatReasonTemporarilyDisabled:
+		// Saxo resumes the subscription itself - only pause timeout detection
+		ws.subscriptionHealth.recordHeartbeat(referenceID, reason, now)
+		ws.logger.Warn("Subscription temporarily disabled",
+			"function", "handleSubscriptionHeartbeat",
+			"reference_id", referenceID)
+
+	case HeartbeatReasonPermanentlyDisabled:
+		if !ws.subscriptionHealth.recordHeartbeat(referenceID, reason, now) {
+			ws.logger.Debug("Recovery already in progress",
+				"function", "handleSubscriptionHeartbeat",
+				"reference_id", referenceID)
+			return
+		}
+		ws.logger.Warn("Subscription permanently disabled, resubscribing",
+			"function", "handleSubscriptionHeartbeat",
+			"reference_id", referenceID)
+
+		// Resubscribe asynchronously - the processor goroutine must not block on HTTP
+		ctx := ws.connContext()
+		go func() {
+			err := ws.subscriptionManager.Resubscribe(ctx, []string{referenceID}, false)
+			ws.subscriptionHealth.finishRecovery(referenceID, ws.clock.Now())
+			if err != nil {
+				ws.logger.Error("Targeted resubscription failed",
+					"function", "handleSubscriptionHeartbeat",
+					"reference_id", referenceID,
+					"error", err)
+			}
+		}()
+
+	default:
+		ws.logger.Warn("Unknown heartbeat reason",
+			"function", "handleSubscriptionHeartbeat",
+			"reference_id", referenceID,
+			"reason", reason)
+	}
+}
@@ -0,0 +1,89 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestSaxoWebSocketClient_HeartbeatHealth(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SubscribeToPortfolio(ctx); err != nil {
+		t.Fatalf("SubscribeToPortfolio failed: %v", err)
+	}
+	var refID string
+	for id, sub := range mockServer.GetActiveSubscriptions() {
+		if sub.Endpoint == EndpointBalance {
+			refID = id
+		}
+	}
+	if refID == "" {
+		t.Fatal("Balance subscription not created on the server")
+	}
+
+	if err := mockServer.SendHeartbeat(refID, HeartbeatReasonNoNewData); err != nil {
+		t.Fatalf("SendHeartbeat failed: %v", err)
+	}
+	health := waitForHealth(t, client, func(h SubscriptionHealth) bool { return h.State == HealthIdle })
+	if health.ReferenceID != refID || health.SubscriptionKey != "portfolio_balance" {
+		t.Errorf("Unexpected health entry %+v", health)
+	}
+
+	// Temporarily disabled pauses timeout detection
+	if err := mockServer.SendHeartbeat(refID, HeartbeatReasonTemporarilyDisabled); err != nil {
+		t.Fatalf("SendHeartbeat failed: %v", err)
+	}
+	waitForHealth(t, client, func(h SubscriptionHealth) bool { return h.State == HealthTemporarilyDisabled })
+	if !client.subscriptionHealth.paused(refID) {
+		t.Error("Expected timeout detection paused while temporarily disabled")
+	}
+
+	// Permanently disabled triggers a targeted resubscription (Resubscribe with this ReferenceId)
+	if err := mockServer.SendHeartbeat(refID, HeartbeatReasonPermanentlyDisabled); err != nil {
+		t.Fatalf("SendHeartbeat failed: %v", err)
+	}
+	health = waitForHealth(t, client, func(h SubscriptionHealth) bool { return h.Recoveries == 1 })
+	if health.State != HealthActive || health.SubscriptionKey != "portfolio_balance" {
+		t.Errorf("Expected recovered balance subscription, got %+v", health)
+	}
+	if client.subscriptionManager.handlerFor(health.ReferenceID) == nil {
+		t.Error("Expected handler routed to the resubscribed ReferenceId")
+	}
+}
+
+// waitForHealth polls the single health entry until match returns true
+func waitForHealth(t *testing.T, client *SaxoWebSocketClient, match func(SubscriptionHealth) bool) SubscriptionHealth {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if health := client.SubscriptionHealth(); len(health) == 1 && match(health[0]) {
+			return health[0]
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for subscription health, last %+v", client.SubscriptionHealth())
+	return SubscriptionHealth{}
+}
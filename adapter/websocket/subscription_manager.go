@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
 )
 
 // Saxo streaming API endpoint constants
@@ -20,6 +24,7 @@ const (
 	EndpointOrders        = "/port/v1/orders/subscriptions"
 	EndpointBalance       = "/port/v1/balances/subscriptions"
 	EndpointSessionEvents = "/root/v1/sessions/events/subscriptions/active"
+	EndpointDepth         = "/trade/v1/prices/subscriptions" // Supports the MarketDepth field group, one UIC per subscription
 )
 
 const (
@@ -27,8 +32,14 @@ const (
 	OrderUpdatesSubscriptionKey     = "orders"
 	PortfolioBalanceSubscriptionKey = "balance"
 	SessionEventsSubscriptionKey    = "session"
+	DepthSubscriptionKey            = "depth"
 )
 
+// DataHandler processes the payload of a data message for one subscription
+// referenceID is passed because resubscription replaces it (handlers must not capture it).
+// Custom handlers (SubscribeCustom, RegisterSubscription) get a payload copy they may keep
+type DataHandler func(referenceID string, payload []byte) error
+
 // SubscriptionManager handles WebSocket subscription lifecycle following Saxo streaming API
 // Per documentation: Subscriptions are sent via HTTP POST, WebSocket is read-only
 type SubscriptionManager struct {
@@ -36,6 +47,10 @@ type SubscriptionManager struct {
 	subscriptionMu sync.RWMutex
 	client         *SaxoWebSocketClient
 
+	// Routing registry: ReferenceId -> subscription (its handlers), kept in sync with subscriptions
+	// Replaces prefix matching on reference IDs, which misrouted or dropped messages
+	handlers map[string]*Subscription
+
 	// HTTP client for subscription requests (WebSocket is read-only!)
 	baseURL      string
 	getAuthToken func() (string, error) // Function to get access token
@@ -52,6 +67,7 @@ type SubscriptionManager struct {
 func NewSubscriptionManager(client *SaxoWebSocketClient, baseURL string, getAuthToken func() (string, error)) *SubscriptionManager {
 	return &SubscriptionManager{
 		subscriptions: make(map[string]*Subscription),
+		handlers:      make(map[string]*Subscription),
 		client:        client,
 		baseURL:       baseURL,
 		getAuthToken:  getAuthToken,
@@ -62,7 +78,15 @@ func NewSubscriptionManager(client *SaxoWebSocketClient, baseURL string, getAuth
 // Per documentation: Subscriptions are sent via HTTP POST, NOT via WebSocket!
 // Endpoint: POST /trade/v1/infoprices/subscriptions
 // assetType: "FxSpot", "ContractFutures", "CfdOnFutures", etc.
-func (sm *SubscriptionManager) SubscribeToInstrumentPrices(instruments []string, assetType string) error {
+// Returns the raw response body so the caller can publish its Snapshot quotes
+//
+// Deprecated: use SubscribeToInstrumentPricesContext - the POST is not canceled with the caller
+func (sm *SubscriptionManager) SubscribeToInstrumentPrices(instruments []string, assetType string) ([]byte, error) {
+	return sm.SubscribeToInstrumentPricesContext(context.Background(), instruments, assetType)
+}
+
+// SubscribeToInstrumentPricesContext is SubscribeToInstrumentPrices with the POST bounded by ctx
+func (sm *SubscriptionManager) SubscribeToInstrumentPricesContext(ctx context.Context, instruments []string, assetType string) ([]byte, error) {
 	sm.client.logger.Info("Starting price subscription",
 		"function", "SubscribeToInstrumentPrices",
 		"count", len(instruments),
@@ -84,13 +108,13 @@ func (sm *SubscriptionManager) SubscribeToInstrumentPrices(instruments []string,
 		sm.client.logger.Error("No valid UICs found for instruments",
 			"function", "SubscribeToInstrumentPrices",
 			"instruments", instruments)
-		return fmt.Errorf("no valid UICs found for instruments")
+		return nil, fmt.Errorf("no valid UICs found for instruments")
 	}
 
 	// Get WebSocket Context ID (already established during connection)
-	contextId := sm.client.contextID
+	contextId := sm.client.currentContextID()
 	if contextId == "" {
-		return fmt.Errorf("WebSocket not connected - no context ID")
+		return nil, fmt.Errorf("WebSocket not connected - no context ID")
 	}
 	sm.client.logger.Debug("Using WebSocket Context ID",
 		"function", "SubscribeToInstrumentPrices",
@@ -106,9 +130,18 @@ func (sm *SubscriptionManager) SubscribeToInstrumentPrices(instruments []string,
 		uicStrings[i] = strconv.Itoa(uic)
 	}
 
+	// Use asset type in map key to support multiple price subscriptions
+	// Example keys: "price_feed_FxSpot", "price_feed_ContractFutures"
+	mapKey := "price_feed_" + assetType
+
 	// Generate human-readable reference ID following legacy pattern
+	// Re-subscribing an asset type on the same context reuses its ReferenceId:
+	// Saxo replaces the existing subscription instead of leaving the old UIC set streaming
 	feedReferenceId := assetType + "-" + PricesSubscriptionKey
 	referenceId := generateHumanReadableID(feedReferenceId)
+	if existing, ok := sm.subscriptions[mapKey]; ok && existing.ContextId == contextId {
+		referenceId = existing.ReferenceId
+	}
 
 	subscriptionReq := map[string]interface{}{
 		"ContextId":   contextId,
@@ -119,17 +152,20 @@ func (sm *SubscriptionManager) SubscribeToInstrumentPrices(instruments []string,
 			"AssetType": assetType,                     // Use parameter from caller (FxSpot, ContractFutures, etc.)
 		},
 	}
+	// FieldGroups and Amount from SetPriceSubscriptionOptions
+	sm.client.priceArguments(assetType, subscriptionReq["Arguments"].(map[string]interface{}))
 
 	sm.client.logger.Debug("Sending subscription via HTTP POST",
 		"function", "SubscribeToInstrumentPrices",
 		"subscription_request", subscriptionReq)
 
 	// Send subscription request via HTTP POST (NOT WebSocket!)
-	if _, err := sm.sendSubscriptionRequest(EndpointPrices, subscriptionReq); err != nil {
+	body, err := sm.sendSubscriptionRequest(ctx, EndpointPrices, subscriptionReq)
+	if err != nil {
 		sm.client.logger.Error("Failed to send HTTP POST",
 			"function", "SubscribeToInstrumentPrices",
 			"error", err)
-		return fmt.Errorf("failed to send price subscription: %w", err)
+		return nil, fmt.Errorf("failed to send price subscription: %w", err)
 	}
 	sm.client.logger.Debug("HTTP POST successful, subscription created",
 		"function", "SubscribeToInstrumentPrices")
@@ -139,15 +175,14 @@ func (sm *SubscriptionManager) SubscribeToInstrumentPrices(instruments []string,
 		ContextId:    contextId,
 		ReferenceId:  referenceId,
 		State:        "Active",
-		SubscribedAt: time.Now(),
+		SubscribedAt: sm.client.clock.Now(),
 		Arguments:    subscriptionReq["Arguments"].(map[string]interface{}),
 		EndpointPath: EndpointPrices,
+		Handler:      sm.client.messageHandler.routePriceUpdate,
+		timedHandler: sm.client.messageHandler.routeTimedPriceUpdate,
 	}
 
-	// Use asset type in map key to support multiple price subscriptions
-	// Example keys: "price_feed_FxSpot", "price_feed_ContractFutures"
-	mapKey := "price_feed_" + assetType
-	sm.subscriptions[mapKey] = subscription
+	sm.registerLocked(mapKey, subscription)
 
 	sm.client.logger.Info("Successfully subscribed to prices",
 		"function", "SubscribeToInstrumentPrices",
@@ -157,17 +192,291 @@ func (sm *SubscriptionManager) SubscribeToInstrumentPrices(instruments []string,
 		"uics", uics,
 		"context_id", contextId)
 
+	return body, nil
+}
+
+// UnsubscribeInstrumentPrices deletes the price subscription for assetType server-side
+// No-op if the asset type has no subscription
+func (sm *SubscriptionManager) UnsubscribeInstrumentPrices(ctx context.Context, assetType string) error {
+	sm.subscriptionMu.Lock()
+	defer sm.subscriptionMu.Unlock()
+
+	mapKey := "price_feed_" + assetType
+	sub, ok := sm.subscriptions[mapKey]
+	if !ok {
+		return nil
+	}
+
+	if err := sm.sendDeleteRequest(ctx, sub); err != nil {
+		return fmt.Errorf("failed to delete price subscription: %w", err)
+	}
+	sm.unregisterLocked(mapKey)
+
+	sm.client.logger.Info("Unsubscribed from prices",
+		"function", "UnsubscribeInstrumentPrices",
+		"subscription_key", mapKey,
+		"reference_id", sub.ReferenceId)
 	return nil
 }
 
+// SubscribeToMarketDepth establishes an order book subscription for one instrument
+// Per Saxo API: POST /trade/v1/prices/subscriptions with FieldGroups Quote,MarketDepth
+// Returns the raw response body (snapshot) so the caller can push it as the first depth update
+//
+// Deprecated: use SubscribeToMarketDepthContext - the POST is not canceled with the caller
+func (sm *SubscriptionManager) SubscribeToMarketDepth(uic int, assetType string) ([]byte, error) {
+	return sm.SubscribeToMarketDepthContext(context.Background(), uic, assetType)
+}
+
+// SubscribeToMarketDepthContext is SubscribeToMarketDepth with the POST bounded by ctx
+func (sm *SubscriptionManager) SubscribeToMarketDepthContext(ctx context.Context, uic int, assetType string) ([]byte, error) {
+	sm.subscriptionMu.Lock()
+	defer sm.subscriptionMu.Unlock()
+
+	contextId := sm.client.currentContextID()
+	if contextId == "" {
+		return nil, fmt.Errorf("WebSocket not connected - no context ID")
+	}
+
+	// One subscription per instrument - re-subscribing replaces it in place (same as prices)
+	mapKey := fmt.Sprintf("depth_%s_%d", assetType, uic)
+	referenceId := generateHumanReadableID(fmt.Sprintf("%s-%d-%s", assetType, uic, DepthSubscriptionKey))
+	if existing, ok := sm.subscriptions[mapKey]; ok && existing.ContextId == contextId {
+		referenceId = existing.ReferenceId
+	}
+
+	subscriptionReq := map[string]interface{}{
+		"ContextId":   contextId,
+		"ReferenceId": referenceId,
+		"RefreshRate": 1000,
+		"Format":      "application/json",
+		"Arguments": map[string]interface{}{
+			"Uic":         uic,
+			"AssetType":   assetType,
+			"FieldGroups": []string{"Quote", "MarketDepth"},
+		},
+	}
+
+	body, err := sm.sendSubscriptionRequest(ctx, EndpointDepth, subscriptionReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send market depth subscription: %w", err)
+	}
+
+	sm.registerLocked(mapKey, &Subscription{
+		ContextId:    contextId,
+		ReferenceId:  referenceId,
+		State:        "Active",
+		SubscribedAt: sm.client.clock.Now(),
+		Arguments:    subscriptionReq["Arguments"].(map[string]interface{}),
+		EndpointPath: EndpointDepth,
+		Handler:      sm.client.messageHandler.handleDepthUpdate,
+	})
+
+	sm.client.logger.Info("Subscribed to market depth via HTTP POST",
+		"function", "SubscribeToMarketDepth",
+		"subscription_key", mapKey,
+		"reference_id", referenceId)
+	return body, nil
+}
+
+// SubscribeCustom creates an application-defined streaming subscription on any Saxo endpoint
+// Data messages for it are routed to handler. name keys the subscription: subscribing the same
+// name again replaces it in place. Returns the ReferenceId and the response body (snapshot)
+//
+// Deprecated: use SubscribeCustomContext - the POST is not canceled with the caller
+func (sm *SubscriptionManager) SubscribeCustom(name, endpoint string, arguments map[string]interface{}, handler DataHandler) (string, []byte, error) {
+	return sm.SubscribeCustomContext(context.Background(), name, endpoint, arguments, handler)
+}
+
+// validateCustomSubscription checks the arguments of a custom subscription before it is sent or deferred
+func validateCustomSubscription(name, endpoint string, handler DataHandler) error {
+	if name == "" || endpoint == "" || handler == nil {
+		return fmt.Errorf("custom subscription requires name, endpoint and handler")
+	}
+	return nil
+}
+
+// SubscribeCustomContext is SubscribeCustom with the POST bounded by ctx
+func (sm *SubscriptionManager) SubscribeCustomContext(ctx context.Context, name, endpoint string, arguments map[string]interface{}, handler DataHandler) (string, []byte, error) {
+	if err := validateCustomSubscription(name, endpoint, handler); err != nil {
+		return "", nil, err
+	}
+
+	sm.subscriptionMu.Lock()
+	defer sm.subscriptionMu.Unlock()
+
+	contextId := sm.client.currentContextID()
+	if contextId == "" {
+		return "", nil, fmt.Errorf("WebSocket not connected - no context ID")
+	}
+
+	mapKey := customSubscriptionKey(name)
+	referenceId := generateHumanReadableID(name)
+	if existing, ok := sm.subscriptions[mapKey]; ok && existing.ContextId == contextId {
+		referenceId = existing.ReferenceId
+	}
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+
+	subscriptionReq := map[string]interface{}{
+		"ContextId":   contextId,
+		"ReferenceId": referenceId,
+		"RefreshRate": 1000,
+		"Format":      "application/json",
+		"Arguments":   arguments,
+	}
+
+	body, err := sm.sendSubscriptionRequest(ctx, endpoint, subscriptionReq)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to send %s subscription: %w", name, err)
+	}
+
+	sm.registerLocked(mapKey, &Subscription{
+		ContextId:    contextId,
+		ReferenceId:  referenceId,
+		State:        "Active",
+		SubscribedAt: sm.client.clock.Now(),
+		Arguments:    arguments,
+		EndpointPath: endpoint,
+		Handler:      retainablePayload(handler),
+	})
+
+	sm.client.logger.Info("Subscribed to custom stream via HTTP POST",
+		"function", "SubscribeCustom",
+		"name", name,
+		"endpoint", endpoint,
+		"reference_id", referenceId)
+	return referenceId, body, nil
+}
+
+// retainablePayload wraps a caller's handler so it gets its own copy of the payload
+// Frames are read into pooled buffers; built-in handlers decode synchronously, but a custom
+// handler may keep the slice after returning
+func retainablePayload(handler DataHandler) DataHandler {
+	return func(referenceID string, payload []byte) error {
+		return handler(referenceID, bytes.Clone(payload))
+	}
+}
+
+// UnsubscribeCustom deletes a subscription created by SubscribeCustom. No-op if unknown
+func (sm *SubscriptionManager) UnsubscribeCustom(ctx context.Context, name string) error {
+	sm.subscriptionMu.Lock()
+	defer sm.subscriptionMu.Unlock()
+
+	mapKey := customSubscriptionKey(name)
+	sub, ok := sm.subscriptions[mapKey]
+	if !ok {
+		return nil
+	}
+	if err := sm.sendDeleteRequest(ctx, sub); err != nil {
+		return fmt.Errorf("failed to delete %s subscription: %w", name, err)
+	}
+	sm.unregisterLocked(mapKey)
+	return nil
+}
+
+// customSubscriptionKey namespaces custom subscriptions away from the built-in map keys
+func customSubscriptionKey(name string) string {
+	return "custom_" + name
+}
+
+// registerLocked tracks a subscription under mapKey and routes its ReferenceId to its handler
+// Replaces a previous subscription under the same key (its ReferenceId stops routing)
+func (sm *SubscriptionManager) registerLocked(mapKey string, subscription *Subscription) {
+	if previous, ok := sm.subscriptions[mapKey]; ok && previous.ReferenceId != subscription.ReferenceId {
+		delete(sm.handlers, previous.ReferenceId)
+	}
+	sm.subscriptions[mapKey] = subscription
+	if subscription.Handler != nil || subscription.timedHandler != nil {
+		sm.handlers[subscription.ReferenceId] = subscription
+	}
+}
+
+// unregisterLocked stops tracking and routing the subscription under mapKey
+func (sm *SubscriptionManager) unregisterLocked(mapKey string) {
+	if subscription, ok := sm.subscriptions[mapKey]; ok {
+		delete(sm.handlers, subscription.ReferenceId)
+		delete(sm.subscriptions, mapKey)
+		sm.client.subscriptionHealth.remove(subscription.ReferenceId)
+	}
+}
+
+// handlerFor returns the handler registered for a data message's ReferenceId (nil if unknown)
+func (sm *SubscriptionManager) handlerFor(referenceId string) DataHandler {
+	sm.subscriptionMu.RLock()
+	defer sm.subscriptionMu.RUnlock()
+	if subscription, ok := sm.handlers[referenceId]; ok {
+		return subscription.Handler
+	}
+	return nil
+}
+
+// routeFor returns the handlers of a data message's ReferenceId - timed is preferred when set
+func (sm *SubscriptionManager) routeFor(referenceId string) (DataHandler, timedDataHandler) {
+	sm.subscriptionMu.RLock()
+	defer sm.subscriptionMu.RUnlock()
+	subscription, ok := sm.handlers[referenceId]
+	if !ok {
+		return nil, nil
+	}
+	return subscription.Handler, subscription.timedHandler
+}
+
+// subscriptionKeysByReferenceId maps each tracked ReferenceId to its subscription map key
+func (sm *SubscriptionManager) subscriptionKeysByReferenceId() map[string]string {
+	sm.subscriptionMu.RLock()
+	defer sm.subscriptionMu.RUnlock()
+
+	keys := make(map[string]string, len(sm.subscriptions))
+	for mapKey, sub := range sm.subscriptions {
+		keys[sub.ReferenceId] = mapKey
+	}
+	return keys
+}
+
+// depthInstrument returns the UIC and asset type of the depth subscription with referenceId
+func (sm *SubscriptionManager) depthInstrument(referenceId string) (int, string) {
+	sm.subscriptionMu.RLock()
+	defer sm.subscriptionMu.RUnlock()
+
+	for _, sub := range sm.subscriptions {
+		if sub.ReferenceId != referenceId || sub.EndpointPath != EndpointDepth {
+			continue
+		}
+		uic, _ := sub.Arguments["Uic"].(int)
+		assetType, _ := sub.Arguments["AssetType"].(string)
+		return uic, assetType
+	}
+	return 0, ""
+}
+
+// priceAssetType returns the AssetType of the price subscription with ReferenceId ("" if unknown)
+func (sm *SubscriptionManager) priceAssetType(referenceId string) string {
+	sm.subscriptionMu.RLock()
+	defer sm.subscriptionMu.RUnlock()
+	if subscription, ok := sm.handlers[referenceId]; ok {
+		assetType, _ := subscription.Arguments["AssetType"].(string)
+		return assetType
+	}
+	return ""
+}
+
 // SubscribeToOrderUpdates establishes order status subscription for signal management
 // Per Saxo API: POST /port/v1/orders/subscriptions
+//
+// Deprecated: use SubscribeToOrderUpdatesContext - the POST is not canceled with the caller
 func (sm *SubscriptionManager) SubscribeToOrderUpdates(clientKey string) error {
+	return sm.SubscribeToOrderUpdatesContext(context.Background(), clientKey)
+}
+
+// SubscribeToOrderUpdatesContext is SubscribeToOrderUpdates with the POST bounded by ctx
+func (sm *SubscriptionManager) SubscribeToOrderUpdatesContext(ctx context.Context, clientKey string) error {
 	sm.subscriptionMu.Lock()
 	defer sm.subscriptionMu.Unlock()
 
 	// Get WebSocket Context ID
-	contextId := sm.client.contextID
+	contextId := sm.client.currentContextID()
 	if contextId == "" {
 		return fmt.Errorf("WebSocket not connected - no context ID")
 	}
@@ -182,11 +491,12 @@ func (sm *SubscriptionManager) SubscribeToOrderUpdates(clientKey string) error {
 		"RefreshRate": 1000,
 		"Format":      "application/json",
 		"Arguments": map[string]interface{}{
-			"ClientKey": clientKey,
+			"ClientKey":   clientKey,
+			"FieldGroups": []string{"DisplayAndFormat"}, // Symbol and Description for OrderUpdate
 		},
 	}
 
-	if _, err := sm.sendSubscriptionRequest(EndpointOrders, subscriptionReq); err != nil {
+	if _, err := sm.sendSubscriptionRequest(ctx, EndpointOrders, subscriptionReq); err != nil {
 		return fmt.Errorf("failed to send order subscription: %w", err)
 	}
 
@@ -194,12 +504,13 @@ func (sm *SubscriptionManager) SubscribeToOrderUpdates(clientKey string) error {
 		ContextId:    contextId,
 		ReferenceId:  referenceId,
 		State:        "Active",
-		SubscribedAt: time.Now(),
+		SubscribedAt: sm.client.clock.Now(),
 		Arguments:    subscriptionReq["Arguments"].(map[string]interface{}),
 		EndpointPath: EndpointOrders,
+		Handler:      sm.client.messageHandler.routeOrderUpdate,
 	}
 
-	sm.subscriptions["order_updates"] = subscription
+	sm.registerLocked("order_updates", subscription)
 	sm.client.logger.Info("Subscribed to order status updates via HTTP POST",
 		"function", "SubscribeToOrderUpdates",
 		"reference_id", referenceId,
@@ -210,12 +521,19 @@ func (sm *SubscriptionManager) SubscribeToOrderUpdates(clientKey string) error {
 
 // SubscribeToPortfolioUpdates establishes balance and margin subscription
 // Per Saxo API: POST /port/v1/balances/subscriptions
+//
+// Deprecated: use SubscribeToPortfolioUpdatesContext - the POST is not canceled with the caller
 func (sm *SubscriptionManager) SubscribeToPortfolioUpdates(clientKey string) error {
+	return sm.SubscribeToPortfolioUpdatesContext(context.Background(), clientKey)
+}
+
+// SubscribeToPortfolioUpdatesContext is SubscribeToPortfolioUpdates with the POST bounded by ctx
+func (sm *SubscriptionManager) SubscribeToPortfolioUpdatesContext(ctx context.Context, clientKey string) error {
 	sm.subscriptionMu.Lock()
 	defer sm.subscriptionMu.Unlock()
 
 	// Get WebSocket Context ID
-	contextId := sm.client.contextID
+	contextId := sm.client.currentContextID()
 	if contextId == "" {
 		return fmt.Errorf("WebSocket not connected - no context ID")
 	}
@@ -234,7 +552,7 @@ func (sm *SubscriptionManager) SubscribeToPortfolioUpdates(clientKey string) err
 		},
 	}
 
-	if _, err := sm.sendSubscriptionRequest(EndpointBalance, subscriptionReq); err != nil {
+	if _, err := sm.sendSubscriptionRequest(ctx, EndpointBalance, subscriptionReq); err != nil {
 		return fmt.Errorf("failed to send portfolio subscription: %w", err)
 	}
 
@@ -242,12 +560,13 @@ func (sm *SubscriptionManager) SubscribeToPortfolioUpdates(clientKey string) err
 		ContextId:    contextId,
 		ReferenceId:  referenceId,
 		State:        "Active",
-		SubscribedAt: time.Now(),
+		SubscribedAt: sm.client.clock.Now(),
 		Arguments:    subscriptionReq["Arguments"].(map[string]interface{}),
 		EndpointPath: EndpointBalance,
+		Handler:      sm.client.messageHandler.routePortfolioUpdate,
 	}
 
-	sm.subscriptions["portfolio_balance"] = subscription
+	sm.registerLocked("portfolio_balance", subscription)
 	sm.client.logger.Info("Subscribed to portfolio balance updates via HTTP POST",
 		"function", "SubscribeToPortfolioUpdates",
 		"reference_id", referenceId,
@@ -260,12 +579,19 @@ func (sm *SubscriptionManager) SubscribeToPortfolioUpdates(clientKey string) err
 // Per Saxo API: POST /root/v1/sessions/events/subscriptions/active
 // Reference: pivot-web/broker/broker_websocket.go:63 - sessionsSubscriptionPath
 // Returns the raw response body (snapshot) so the caller can push it as the first session event
+//
+// Deprecated: use SubscribeToSessionEventsContext - the POST is not canceled with the caller
 func (sm *SubscriptionManager) SubscribeToSessionEvents() ([]byte, error) {
+	return sm.SubscribeToSessionEventsContext(context.Background())
+}
+
+// SubscribeToSessionEventsContext is SubscribeToSessionEvents with the POST bounded by ctx
+func (sm *SubscriptionManager) SubscribeToSessionEventsContext(ctx context.Context) ([]byte, error) {
 	sm.subscriptionMu.Lock()
 	defer sm.subscriptionMu.Unlock()
 
 	// Get WebSocket Context ID
-	contextId := sm.client.contextID
+	contextId := sm.client.currentContextID()
 	if contextId == "" {
 		return nil, fmt.Errorf("WebSocket not connected - no context ID")
 	}
@@ -286,7 +612,7 @@ func (sm *SubscriptionManager) SubscribeToSessionEvents() ([]byte, error) {
 		"function", "SubscribeToSessionEvents",
 		"subscription_request", subscriptionReq)
 
-	body, err := sm.sendSubscriptionRequest(EndpointSessionEvents, subscriptionReq)
+	body, err := sm.sendSubscriptionRequest(ctx, EndpointSessionEvents, subscriptionReq)
 	if err != nil {
 		sm.client.logger.Error("Failed to send HTTP POST",
 			"function", "SubscribeToSessionEvents",
@@ -298,12 +624,13 @@ func (sm *SubscriptionManager) SubscribeToSessionEvents() ([]byte, error) {
 		ContextId:    contextId,
 		ReferenceId:  referenceId,
 		State:        "Active",
-		SubscribedAt: time.Now(),
+		SubscribedAt: sm.client.clock.Now(),
 		Arguments:    map[string]interface{}{}, // No special arguments for session events
 		EndpointPath: EndpointSessionEvents,
+		Handler:      sm.client.messageHandler.routeSessionEvent,
 	}
 
-	sm.subscriptions["session_events"] = subscription
+	sm.registerLocked("session_events", subscription)
 	sm.client.logger.Info("Subscribed to session events via HTTP POST",
 		"function", "SubscribeToSessionEvents",
 		"reference_id", referenceId)
@@ -314,7 +641,8 @@ func (sm *SubscriptionManager) SubscribeToSessionEvents() ([]byte, error) {
 // sendSubscriptionRequest sends HTTP POST subscription request following Saxo streaming API
 // Per documentation: Subscriptions are ALWAYS sent via HTTP POST, never via WebSocket
 // Reference: https://www.developer.saxo/openapi/learn/streaming#Subscription-example
-func (sm *SubscriptionManager) sendSubscriptionRequest(endpoint string, subscriptionReq map[string]interface{}) ([]byte, error) {
+// Bounded by ctx as well as the request timeout
+func (sm *SubscriptionManager) sendSubscriptionRequest(ctx context.Context, endpoint string, subscriptionReq map[string]interface{}) ([]byte, error) {
 	// Get access token
 	token, err := sm.getAuthToken()
 	if err != nil {
@@ -334,7 +662,8 @@ func (sm *SubscriptionManager) sendSubscriptionRequest(endpoint string, subscrip
 
 	// Create HTTP POST request
 	url := sm.baseURL + endpoint
-	ctx := context.Background()
+	ctx, cancel := saxo.RequestContext(ctx, sm.client.requestTimeout)
+	defer cancel()
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
@@ -343,9 +672,12 @@ func (sm *SubscriptionManager) sendSubscriptionRequest(endpoint string, subscrip
 	// Set headers per Saxo API requirements
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
+	if sm.client.userAgent != "" {
+		req.Header.Set("User-Agent", sm.client.userAgent)
+	}
 
 	// Get HTTP client from auth client (for TLS configuration in tests)
-	httpClient, err := sm.client.authClient.GetHTTPClient(ctx)
+	httpClient, err := sm.client.getHTTPClient(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get HTTP client: %w", err)
 	}
@@ -392,14 +724,92 @@ func (sm *SubscriptionManager) sendSubscriptionRequest(endpoint string, subscrip
 	return bodyBytes, nil
 }
 
+// DeleteAllSubscriptions removes every tracked subscription server-side
+// Per Saxo API: DELETE {endpoint}/{ContextId}/{ReferenceId}
+// Subscriptions that fail to delete stay tracked so a retry is possible
+func (sm *SubscriptionManager) DeleteAllSubscriptions(ctx context.Context) error {
+	sm.subscriptionMu.Lock()
+	defer sm.subscriptionMu.Unlock()
+
+	var errs []error
+	for key, sub := range sm.subscriptions {
+		if err := sm.sendDeleteRequest(ctx, sub); err != nil {
+			sm.client.logger.Warn("Failed to delete subscription",
+				"function", "DeleteAllSubscriptions",
+				"subscription_key", key,
+				"reference_id", sub.ReferenceId,
+				"error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		sm.unregisterLocked(key)
+		sm.client.logger.Debug("Subscription deleted",
+			"function", "DeleteAllSubscriptions",
+			"subscription_key", key,
+			"reference_id", sub.ReferenceId)
+	}
+
+	return errors.Join(errs...)
+}
+
+// sendDeleteRequest sends HTTP DELETE for a single subscription
+// Session events subscribe on ".../subscriptions/active" but delete on ".../subscriptions/{ContextId}/{ReferenceId}"
+func (sm *SubscriptionManager) sendDeleteRequest(ctx context.Context, sub *Subscription) error {
+	token, err := sm.getAuthToken()
+	if err != nil {
+		return fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	endpoint := strings.TrimSuffix(sub.EndpointPath, "/active")
+	url := fmt.Sprintf("%s%s/%s/%s", sm.baseURL, endpoint, sub.ContextId, sub.ReferenceId)
+
+	ctx, cancel := saxo.RequestContext(ctx, sm.client.requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if sm.client.userAgent != "" {
+		req.Header.Set("User-Agent", sm.client.userAgent)
+	}
+
+	httpClient, err := sm.client.getHTTPClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get HTTP client: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 404 = already gone server-side (e.g. context expired) - nothing left to clean up
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("subscription delete failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+}
+
 // generateNewReferenceId creates a new reference ID by replacing the timestamp suffix
 // This preserves asset type prefixes like "FxSpotprices", "ContractFuturesprices", etc.
 // Old: FxSpotprices-20251220-152651 -> New: FxSpotprices-20251220-153045
+// A replacement within the same second advances the old timestamp, so the IDs always differ
 func (sm *SubscriptionManager) generateNewReferenceId(oldReferenceId string) string {
 	if len(oldReferenceId) > 15 {
 		// Extract prefix (everything except last 15 chars) and add new timestamp
 		prefix := oldReferenceId[:len(oldReferenceId)-15]
+		oldTimestamp := oldReferenceId[len(oldReferenceId)-15:]
 		newTimestamp := time.Now().Format("20060102-150405")
+		if newTimestamp <= oldTimestamp {
+			if previous, err := time.ParseInLocation("20060102-150405", oldTimestamp, time.Local); err == nil {
+				newTimestamp = previous.Add(time.Second).Format("20060102-150405")
+			}
+		}
 		return prefix + newTimestamp
 	}
 	// Fallback for malformed IDs: append timestamp
@@ -407,134 +817,160 @@ func (sm *SubscriptionManager) generateNewReferenceId(oldReferenceId string) str
 	return fmt.Sprintf("%s-%s", oldReferenceId, newTimestamp)
 }
 
-// HandleSubscriptions handles subscription restoration/reset following Saxo streaming API
+// ResubscribeAll re-creates every subscription on a new connection (after a reconnect)
+// ReferenceIds are scoped to the context ID, so the new context reuses them and handlers,
+// health entries and consumer bookkeeping keyed by ReferenceId stay valid
+func (sm *SubscriptionManager) ResubscribeAll(ctx context.Context) error {
+	return sm.Resubscribe(ctx, nil, true)
+}
+
+// Resubscribe re-creates subscriptions via HTTP POST following the Saxo streaming API
 // Per documentation: Subscriptions MUST be sent via HTTP POST, NOT WebSocket writes
 // Reference: https://www.developer.saxo/openapi/learn/streaming
 //
 // Parameters:
-//   - targetReferenceIds: Specific reference IDs to resubscribe (empty = all subscriptions)
+//   - referenceIDs: Saxo reference IDs to resubscribe (empty = all subscriptions)
 //     CRITICAL: These are actual Saxo reference IDs (e.g., "FxSpotprices-20251220-145408"),
 //     NOT internal subscription type keys (e.g., "price_feed")
+//   - keepIDs: true re-posts under the same ReferenceId - only valid on a new context ID;
+//     false posts a new ReferenceId with ReplaceReferenceId, an atomic swap within the current context
 //
 // Usage scenarios:
-//   - Full reconnection: HandleSubscriptions(nil) - new IDs, all subscriptions
-//   - Subscription reset: HandleSubscriptions([]string{"FxSpotprices-20251220-145408"}) - new ID for specific subscription
-func (sm *SubscriptionManager) HandleSubscriptions(targetReferenceIds []string) error {
+//   - Full reconnection: ResubscribeAll(ctx)
+//   - Subscription reset: Resubscribe(ctx, []string{"FxSpotprices-20251220-145408"}, false)
+func (sm *SubscriptionManager) Resubscribe(ctx context.Context, referenceIDs []string, keepIDs bool) error {
 	sm.subscriptionMu.Lock()
 	defer sm.subscriptionMu.Unlock()
 
 	// Determine which subscriptions to resubscribe
 	var subsToProcess map[string]*Subscription
-	if len(targetReferenceIds) == 0 {
+	if len(referenceIDs) == 0 {
 		// Resubscribe all subscriptions
 		subsToProcess = sm.subscriptions
 		sm.client.logger.Info("Resubscribing to ALL subscriptions via HTTP POST",
-			"function", "HandleSubscriptions",
-			"count", len(sm.subscriptions))
+			"function", "Resubscribe",
+			"count", len(sm.subscriptions),
+			"keep_ids", keepIDs)
 	} else {
 		// Resubscribe only specific subscriptions by matching ReferenceId field
-		// CRITICAL: targetReferenceIds contains actual Saxo reference IDs (e.g., "FxSpotprices-20251220-145408")
 		subsToProcess = make(map[string]*Subscription)
-		for mapKey, sub := range sm.subscriptions {
-			for _, targetRefId := range targetReferenceIds {
+		for _, targetRefId := range referenceIDs {
+			found := false
+			for mapKey, sub := range sm.subscriptions {
 				if sub.ReferenceId == targetRefId {
 					subsToProcess[mapKey] = sub
+					found = true
 					sm.client.logger.Debug("Matched ReferenceId to subscription type",
-						"function", "HandleSubscriptions",
+						"function", "Resubscribe",
 						"reference_id", targetRefId,
 						"subscription_key", mapKey)
 					break
 				}
 			}
-		}
-
-		// Log any unmatched reference IDs
-		for _, targetRefId := range targetReferenceIds {
-			found := false
-			for _, sub := range subsToProcess {
-				if sub.ReferenceId == targetRefId {
-					found = true
-					break
-				}
-			}
 			if !found {
 				sm.client.logger.Warn("ReferenceId not found in active subscriptions",
-					"function", "HandleSubscriptions",
+					"function", "Resubscribe",
 					"reference_id", targetRefId)
 			}
 		}
 
 		sm.client.logger.Info("Resubscribing to specific subscriptions via HTTP POST",
-			"function", "HandleSubscriptions",
-			"count", len(subsToProcess))
+			"function", "Resubscribe",
+			"count", len(subsToProcess),
+			"keep_ids", keepIDs)
 	}
 
 	if len(subsToProcess) == 0 {
 		sm.client.logger.Debug("No subscriptions to resubscribe",
-			"function", "HandleSubscriptions")
+			"function", "Resubscribe")
 		return nil
 	}
 
-	// Reestablish subscriptions via HTTP POST with new reference IDs
+	contextID := sm.client.currentContextID()
+	first := true
 	for refId, subscription := range subsToProcess {
-		oldReferenceId := subscription.ReferenceId
-
-		// Generate new reference ID by replacing timestamp
-		newReferenceId := sm.generateNewReferenceId(oldReferenceId)
-		subscriptionReq := map[string]interface{}{
-			"ContextId":          sm.client.contextID,
-			"ReferenceId":        newReferenceId,
-			"ReplaceReferenceId": oldReferenceId, // Atomic replacement per Saxo docs
-			"RefreshRate":        1000,
-			"Format":             "application/json",
-			"Arguments":          subscription.Arguments,
+		// Small delay between resubscriptions to avoid overwhelming server
+		// A canceled ctx (Close, Connect timeout) stops before the next POST
+		if !first {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(500 * time.Millisecond):
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
 		}
-		sm.client.logger.Debug("Resubscribing with new reference ID",
-			"function", "HandleSubscriptions",
-			"subscription_key", refId,
-			"old_reference_id", oldReferenceId,
-			"new_reference_id", newReferenceId)
+		first = false
 
 		// Use stored endpoint path (single source of truth)
 		endpoint := subscription.EndpointPath
 		if endpoint == "" {
 			sm.client.logger.Error("Subscription has no endpoint path stored, skipping",
-				"function", "HandleSubscriptions",
+				"function", "Resubscribe",
 				"subscription_key", refId)
 			continue
 		}
 
+		oldReferenceId := subscription.ReferenceId
+		newReferenceId := oldReferenceId
+		subscriptionReq := map[string]interface{}{
+			"ContextId":   contextID,
+			"RefreshRate": 1000,
+			"Format":      "application/json",
+			"Arguments":   subscription.Arguments,
+		}
+		if !keepIDs {
+			// Generate new reference ID by replacing timestamp
+			newReferenceId = sm.generateNewReferenceId(oldReferenceId)
+			subscriptionReq["ReplaceReferenceId"] = oldReferenceId // Atomic replacement per Saxo docs
+		}
+		subscriptionReq["ReferenceId"] = newReferenceId
+
+		sm.client.logger.Debug("Resubscribing",
+			"function", "Resubscribe",
+			"subscription_key", refId,
+			"old_reference_id", oldReferenceId,
+			"new_reference_id", newReferenceId)
+
 		// Send HTTP POST subscription request (correct per Saxo API documentation)
-		if _, err := sm.sendSubscriptionRequest(endpoint, subscriptionReq); err != nil {
+		if _, err := sm.sendSubscriptionRequest(ctx, endpoint, subscriptionReq); err != nil {
 			return fmt.Errorf("failed to resubscribe %s: %w", refId, err)
 		}
 
 		// Update subscription tracking
-		// CRITICAL: Map key (refId) stays stable, only ReferenceId field changes
-		subscription.ReferenceId = newReferenceId
+		// CRITICAL: Map key (refId) stays stable, only ContextId and ReferenceId change
+		subscription.ContextId = contextID
 		subscription.State = "Active"
-		subscription.SubscribedAt = time.Now()
-
-		// Clean up old subscription's lastMessageTimestamps
-		sm.client.lastMessageTimestampsMu.Lock()
-		if timestamp, exists := sm.client.lastMessageTimestamps[oldReferenceId]; exists {
-			sm.client.lastMessageTimestamps[newReferenceId] = timestamp
-			delete(sm.client.lastMessageTimestamps, oldReferenceId)
-		}
-		sm.client.lastMessageTimestampsMu.Unlock()
-
-		// Add small delay between resubscriptions to avoid overwhelming server
-		if len(subsToProcess) > 1 {
-			time.Sleep(500 * time.Millisecond)
+		subscription.SubscribedAt = sm.client.clock.Now()
+		if newReferenceId != oldReferenceId {
+			subscription.ReferenceId = newReferenceId
+			delete(sm.handlers, oldReferenceId)
+			sm.handlers[newReferenceId] = subscription
+
+			// Clean up old subscription's lastMessageTimestamps
+			sm.client.lastMessageTimestampsMu.Lock()
+			if timestamp, exists := sm.client.lastMessageTimestamps[oldReferenceId]; exists {
+				sm.client.lastMessageTimestamps[newReferenceId] = timestamp
+				delete(sm.client.lastMessageTimestamps, oldReferenceId)
+			}
+			sm.client.lastMessageTimestampsMu.Unlock()
 		}
+		// Also marks a recovering subscription active again when the ID is kept
+		sm.client.subscriptionHealth.rename(oldReferenceId, newReferenceId, sm.client.clock.Now())
 	}
 
 	sm.client.logger.Info("Successfully resubscribed subscriptions",
-		"function", "HandleSubscriptions",
+		"function", "Resubscribe",
 		"count", len(subsToProcess))
 	return nil
 }
 
+// HandleSubscriptions resubscribes with new reference IDs (nil = all subscriptions)
+// Deprecated: use ResubscribeAll after a reconnect or Resubscribe(ctx, ids, false) for resets
+func (sm *SubscriptionManager) HandleSubscriptions(targetReferenceIds []string) error {
+	return sm.Resubscribe(context.Background(), targetReferenceIds, false)
+}
+
 // HandleSubscriptionReset handles subscription reset requests from Saxo
 // Following legacy handleSubscriptionsResets() pattern with CRITICAL protection logic
 func (sm *SubscriptionManager) HandleSubscriptionReset(targetReferenceIds []string) error {
@@ -562,7 +998,7 @@ func (sm *SubscriptionManager) HandleSubscriptionReset(targetReferenceIds []stri
 
 	// CRITICAL: Throttle full resets (30s cooldown) to prevent cascading reset storms
 	// Increased from 10s to 30s following legacy pattern after debugging
-	if len(targetReferenceIds) == 0 && time.Since(sm.lastSubscriptionResetTime) < 30*time.Second {
+	if len(targetReferenceIds) == 0 && sm.client.clock.Now().Sub(sm.lastSubscriptionResetTime) < 30*time.Second {
 		sm.subscriptionMu.Unlock()
 		sm.client.logger.Debug("Recent full reset detected, skipping to avoid storm",
 			"function", "HandleSubscriptionReset")
@@ -574,12 +1010,13 @@ func (sm *SubscriptionManager) HandleSubscriptionReset(targetReferenceIds []stri
 	sm.subscriptionMu.Unlock()
 
 	// Perform reset asynchronously to avoid blocking reader goroutine
+	ctx := sm.client.connContext()
 	go func(timedOutSubs []string) {
 		defer func() {
 			sm.subscriptionMu.Lock()
 			sm.subscriptionUpdateInProgress = false
 			// CRITICAL: Update timestamp AFTER work completes, not before starting
-			sm.lastSubscriptionResetTime = time.Now()
+			sm.lastSubscriptionResetTime = sm.client.clock.Now()
 			sm.subscriptionMu.Unlock()
 		}()
 
@@ -603,10 +1040,11 @@ func (sm *SubscriptionManager) HandleSubscriptionReset(targetReferenceIds []stri
 				"function", "HandleSubscriptionReset",
 				"subscriptions", timedOutSubs)
 
-			// Use ResubscribeAll with specific targets and generate new IDs
+			// Same context - swap to new IDs with ReplaceReferenceId
 			// Following Saxo API documentation: subscriptions via HTTP POST, not WebSocket writes
-			if err := sm.HandleSubscriptions(timedOutSubs); err != nil {
-				sm.client.logger.Error("ResubscribeAll failed",
+			// Bound to the connection - Close or a reconnect cancels a reset still in flight
+			if err := sm.Resubscribe(ctx, timedOutSubs, false); err != nil {
+				sm.client.logger.Error("Resubscribe failed",
 					"function", "HandleSubscriptionReset",
 					"error", err)
 			}
@@ -617,9 +1055,8 @@ func (sm *SubscriptionManager) HandleSubscriptionReset(targetReferenceIds []stri
 }
 
 // getUicsForInstruments extracts UICs from ticker list using dynamic mapping
-// CRITICAL FIX: No more hardcoded UICs - uses RegisterInstruments() mapping from fx.json
+// CRITICAL FIX: No more hardcoded UICs - tickers are resolved through the shared saxo.InstrumentStore
 // Also supports direct UIC strings (e.g., "21", "31") for simple examples
-// When UICs are passed directly, creates bidirectional mapping: UIC → "21" (ticker is UIC string)
 func (sm *SubscriptionManager) getUicsForInstruments(instruments []string) []int {
 	// Use map to deduplicate UICs (CRITICAL FIX for Saxo API requirement)
 	// Saxo API requires: "The UICs in the list must be unique"
@@ -633,18 +1070,25 @@ func (sm *SubscriptionManager) getUicsForInstruments(instruments []string) []int
 				"function", "getUicsForInstruments",
 				"instrument", instrument,
 				"uic", uic)
+		} else if meta, ok := sm.lookupTicker(instrument); ok {
+			uicMap[meta.Uic] = true
+			sm.client.logger.Debug("Resolved ticker from instrument store",
+				"function", "getUicsForInstruments",
+				"instrument", instrument,
+				"uic", meta.Uic)
 		} else {
-			sm.client.logger.Warn("Could not parse instrument as UIC",
+			sm.client.logger.Warn("Could not parse instrument as UIC or known ticker",
 				"function", "getUicsForInstruments",
 				"instrument", instrument)
 		}
 	}
 
-	// Convert map to slice (deduplicated UICs)
+	// Convert map to slice (deduplicated UICs), sorted so the subscription arguments are stable
 	uics := make([]int, 0, len(uicMap))
 	for uic := range uicMap {
 		uics = append(uics, uic)
 	}
+	sort.Ints(uics)
 
 	if len(uics) > 0 {
 		sm.client.logger.Debug("Mapped instruments to unique UICs",
@@ -656,3 +1100,11 @@ func (sm *SubscriptionManager) getUicsForInstruments(instruments []string) []int
 
 	return uics
 }
+
+// lookupTicker resolves ticker through the client's InstrumentStore, if any
+func (sm *SubscriptionManager) lookupTicker(ticker string) (saxo.InstrumentMetadata, bool) {
+	if sm.client.instrumentStore == nil {
+		return saxo.InstrumentMetadata{}, false
+	}
+	return sm.client.instrumentStore.Lookup(ticker)
+}
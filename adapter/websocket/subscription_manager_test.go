@@ -0,0 +1,178 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+// subscriptionsOn returns the mock's active subscriptions for endpoint
+func subscriptionsOn(mockServer *mocktesting.MockSaxoWebSocketServer, endpoint string) []mocktesting.MockSubscription {
+	var subs []mocktesting.MockSubscription
+	for _, sub := range mockServer.GetActiveSubscriptions() {
+		if sub.Endpoint == endpoint {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// expectPrice waits for a streamed quote for uic with the given bid
+func expectPrice(t *testing.T, client *SaxoWebSocketClient, uic int, bid float64) {
+	t.Helper()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case update := <-client.GetPriceUpdateChannel():
+			if update.Uic == uic && update.Bid == bid {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("No price update for UIC %d with bid %v", uic, bid)
+		}
+	}
+}
+
+func TestSubscriptionManager_ReconnectResubscribeCycle(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	client := newReconnectTestClient(t, mockServer)
+	contextIDs := make(chan string, 10)
+	client.SetStateChannels(nil, contextIDs)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+	firstContext := <-contextIDs
+
+	if err := client.SubscribeToPrices(ctx, []string{"21"}, "FxSpot"); err != nil {
+		t.Fatalf("Failed to subscribe to prices: %v", err)
+	}
+	if err := client.SubscribeToSessionEvents(ctx); err != nil {
+		t.Fatalf("Failed to subscribe to session events: %v", err)
+	}
+	prices := subscriptionsOn(mockServer, EndpointPrices)
+	if len(prices) != 1 {
+		t.Fatalf("Expected 1 price subscription, got %d", len(prices))
+	}
+	priceRef := prices[0].ReferenceId
+
+	// Network failure - the client reconnects on a new context and resubscribes everything
+	mockServer.DropConnections()
+
+	var secondContext string
+	select {
+	case secondContext = <-contextIDs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Client did not reconnect")
+	}
+	if secondContext == firstContext {
+		t.Fatalf("Expected a new context ID after reconnect, got %q again", secondContext)
+	}
+
+	resubscribed := waitFor(t, 5*time.Second, func() bool {
+		for _, endpoint := range []string{EndpointPrices, EndpointSessionEvents} {
+			subs := subscriptionsOn(mockServer, endpoint)
+			if len(subs) != 1 || subs[0].ContextId != secondContext {
+				return false
+			}
+		}
+		return true
+	})
+	if !resubscribed {
+		t.Fatalf("Subscriptions not restored on context %s: %+v", secondContext, mockServer.GetActiveSubscriptions())
+	}
+
+	// ReferenceIds are kept on the new context, so the existing handlers still route data
+	if got := subscriptionsOn(mockServer, EndpointPrices)[0].ReferenceId; got != priceRef {
+		t.Errorf("Expected price ReferenceId %s kept across reconnect, got %s", priceRef, got)
+	}
+	if err := mockServer.SendPriceUpdate("21", 1.2000, 1.2002); err != nil {
+		t.Fatalf("SendPriceUpdate failed: %v", err)
+	}
+	expectPrice(t, client, 21, 1.2000)
+}
+
+func TestSubscriptionManager_ResubscribeReplacesIDs(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	client := newReconnectTestClient(t, mockServer)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SubscribeToPrices(ctx, []string{"21"}, "FxSpot"); err != nil {
+		t.Fatalf("Failed to subscribe to prices: %v", err)
+	}
+	oldRef := subscriptionsOn(mockServer, EndpointPrices)[0].ReferenceId
+
+	// Same context: a new ReferenceId atomically replaces the old one (ReplaceReferenceId)
+	if err := client.subscriptionManager.Resubscribe(ctx, []string{oldRef}, false); err != nil {
+		t.Fatalf("Resubscribe failed: %v", err)
+	}
+	prices := subscriptionsOn(mockServer, EndpointPrices)
+	if len(prices) != 1 || prices[0].ReferenceId == oldRef {
+		t.Fatalf("Expected the old ReferenceId %s replaced, got %+v", oldRef, prices)
+	}
+	if client.subscriptionManager.handlerFor(oldRef) != nil {
+		t.Error("Handler for the replaced ReferenceId should be removed")
+	}
+
+	if err := mockServer.SendPriceUpdate("21", 1.1010, 1.1012); err != nil {
+		t.Fatalf("SendPriceUpdate failed: %v", err)
+	}
+	expectPrice(t, client, 21, 1.1010)
+
+	// A cancelled context stops before posting
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if err := client.subscriptionManager.ResubscribeAll(cancelled); err == nil {
+		t.Error("Expected ResubscribeAll to fail with a cancelled context")
+	}
+}
+
+func TestSubscriptionManager_SubscribeHonoursCallerContext(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	client := newReconnectTestClient(t, mockServer)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	// The caller's ctx reaches the subscription POST - a cancelled one sends nothing
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	err := client.SubscribeToPrices(cancelled, []string{"21"}, "FxSpot")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if err := client.SubscribeToSessionEvents(cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled for session events, got %v", err)
+	}
+	if subs := mockServer.GetActiveSubscriptions(); len(subs) != 0 {
+		t.Fatalf("Expected no subscriptions, got %+v", subs)
+	}
+
+	// The same call with a live ctx goes through
+	if err := client.SubscribeToPrices(ctx, []string{"21"}, "FxSpot"); err != nil {
+		t.Fatalf("Failed to subscribe to prices: %v", err)
+	}
+	if len(subscriptionsOn(mockServer, EndpointPrices)) != 1 {
+		t.Fatal("Expected the price subscription after the live call")
+	}
+}
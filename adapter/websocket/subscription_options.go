@@ -0,0 +1,118 @@
+package websocket
+
+import (
+	"strings"
+	"time"
+)
+
+// Default subscription monitoring thresholds, following legacy broker_websocket.go
+const (
+	DefaultInactivityTimeout = 100 * time.Second
+	DefaultMonitorInterval   = 55 * time.Second
+)
+
+// SubscriptionOptions tunes timeout detection for one kind of subscription
+// Data messages and NoNewData heartbeats both count as activity, so a quiet market is never a timeout
+type SubscriptionOptions struct {
+	// InactivityTimeout is how long the subscription may stay silent before it is reset
+	// 0 = DefaultInactivityTimeout, negative = never times out (e.g. session events)
+	InactivityTimeout time.Duration
+	// CheckInterval is how often the subscription is checked, 0 = DefaultMonitorInterval
+	// The monitor wakes at the smallest interval of all subscriptions
+	CheckInterval time.Duration
+}
+
+// resolved fills zero fields with the defaults
+func (o SubscriptionOptions) resolved() SubscriptionOptions {
+	if o.InactivityTimeout == 0 {
+		o.InactivityTimeout = DefaultInactivityTimeout
+	}
+	if o.CheckInterval <= 0 {
+		o.CheckInterval = DefaultMonitorInterval
+	}
+	return o
+}
+
+// monitored reports whether the subscription can time out at all
+func (o SubscriptionOptions) monitored() bool {
+	return o.InactivityTimeout > 0
+}
+
+// SetSubscriptionOptions sets timeout detection for key, applied from the next monitoring pass
+// key is a subscription kind (PricesSubscriptionKey, OrderUpdatesSubscriptionKey, PortfolioBalanceSubscriptionKey,
+// SessionEventsSubscriptionKey, DepthSubscriptionKey, or a SubscribeCustom name) or an exact
+// SubscriptionHealth.SubscriptionKey such as "price_feed_FxSpot"; an exact key wins over its kind.
+// key "" sets the client-wide default for everything else
+func (ws *SaxoWebSocketClient) SetSubscriptionOptions(key string, opts SubscriptionOptions) {
+	ws.subscriptionOptionsMu.Lock()
+	defer ws.subscriptionOptionsMu.Unlock()
+	ws.subscriptionOptions[key] = opts
+}
+
+// SubscriptionOptionsFor returns the effective options for a subscription map key
+func (ws *SaxoWebSocketClient) SubscriptionOptionsFor(mapKey string) SubscriptionOptions {
+	ws.subscriptionOptionsMu.RLock()
+	defer ws.subscriptionOptionsMu.RUnlock()
+	if opts, ok := ws.subscriptionOptions[mapKey]; ok {
+		return opts.resolved()
+	}
+	if opts, ok := ws.subscriptionOptions[subscriptionKind(mapKey)]; ok {
+		return opts.resolved()
+	}
+	return ws.subscriptionOptions[""].resolved()
+}
+
+// subscriptionKind maps a SubscriptionManager map key to the kind accepted by SetSubscriptionOptions
+func subscriptionKind(mapKey string) string {
+	switch {
+	case strings.HasPrefix(mapKey, "price_feed_"):
+		return PricesSubscriptionKey
+	case strings.HasPrefix(mapKey, "depth_"):
+		return DepthSubscriptionKey
+	case strings.HasPrefix(mapKey, "custom_"):
+		return strings.TrimPrefix(mapKey, "custom_")
+	case mapKey == "order_updates":
+		return OrderUpdatesSubscriptionKey
+	case mapKey == "portfolio_balance":
+		return PortfolioBalanceSubscriptionKey
+	case mapKey == "session_events":
+		return SessionEventsSubscriptionKey
+	}
+	return mapKey
+}
+
+// monitorOptions returns the effective options per ReferenceId with a tracked timestamp,
+// and the interval until the next monitoring pass
+// ReferenceIds no longer known to the SubscriptionManager get the defaults
+func (ws *SaxoWebSocketClient) monitorOptions() (map[string]SubscriptionOptions, time.Duration) {
+	keys := ws.subscriptionManager.subscriptionKeysByReferenceId()
+
+	ws.lastMessageTimestampsMu.RLock()
+	referenceIDs := make([]string, 0, len(ws.lastMessageTimestamps))
+	for refID := range ws.lastMessageTimestamps {
+		referenceIDs = append(referenceIDs, refID)
+	}
+	ws.lastMessageTimestampsMu.RUnlock()
+
+	options := make(map[string]SubscriptionOptions, len(referenceIDs))
+	interval := time.Duration(0)
+	for _, refID := range referenceIDs {
+		opts := ws.SubscriptionOptionsFor(keys[refID])
+		options[refID] = opts
+		if opts.monitored() && (interval == 0 || opts.CheckInterval < interval) {
+			interval = opts.CheckInterval
+		}
+	}
+	if interval == 0 {
+		// Nothing to check yet - wake early enough for the fastest configured subscription
+		interval = ws.SubscriptionOptionsFor("").CheckInterval
+		ws.subscriptionOptionsMu.RLock()
+		for _, opts := range ws.subscriptionOptions {
+			if opts = opts.resolved(); opts.monitored() && opts.CheckInterval < interval {
+				interval = opts.CheckInterval
+			}
+		}
+		ws.subscriptionOptionsMu.RUnlock()
+	}
+	return options, interval
+}
@@ -0,0 +1,100 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestSubscriptionOptionsFor_Resolution(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+	client := newReconnectTestClient(t, mockServer)
+
+	if got := client.SubscriptionOptionsFor("price_feed_FxSpot"); got.InactivityTimeout != DefaultInactivityTimeout || got.CheckInterval != DefaultMonitorInterval {
+		t.Errorf("Expected defaults, got %+v", got)
+	}
+
+	client.SetSubscriptionOptions("", SubscriptionOptions{InactivityTimeout: 200 * time.Second})
+	client.SetSubscriptionOptions(PricesSubscriptionKey, SubscriptionOptions{InactivityTimeout: 10 * time.Second, CheckInterval: 5 * time.Second})
+	client.SetSubscriptionOptions("price_feed_ContractFutures", SubscriptionOptions{InactivityTimeout: 30 * time.Second})
+	client.SetSubscriptionOptions("news", SubscriptionOptions{InactivityTimeout: -1})
+
+	tests := []struct {
+		mapKey  string
+		timeout time.Duration
+	}{
+		{"price_feed_FxSpot", 10 * time.Second},          // kind
+		{"price_feed_ContractFutures", 30 * time.Second}, // exact key wins
+		{"custom_news", -1},                              // SubscribeCustom name
+		{"order_updates", 200 * time.Second},             // client-wide default
+	}
+	for _, tt := range tests {
+		if got := client.SubscriptionOptionsFor(tt.mapKey); got.InactivityTimeout != tt.timeout {
+			t.Errorf("%s: InactivityTimeout = %v, want %v", tt.mapKey, got.InactivityTimeout, tt.timeout)
+		}
+	}
+}
+
+func TestSubscriptionMonitoring_PerSubscriptionThresholds(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	clock := mocktesting.NewFakeClock(time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC))
+	client := newReconnectTestClient(t, mockServer, saxo.WithClock(clock))
+	client.SetSubscriptionOptions(PricesSubscriptionKey, SubscriptionOptions{InactivityTimeout: 10 * time.Second, CheckInterval: 5 * time.Second})
+	client.SetSubscriptionOptions(SessionEventsSubscriptionKey, SubscriptionOptions{InactivityTimeout: -1})
+
+	ctx := context.Background()
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+	for _, subscribe := range []func(context.Context) error{
+		func(ctx context.Context) error { return client.SubscribeToPrices(ctx, []string{"21"}, "FxSpot") },
+		client.SubscribeToPortfolio,
+		client.SubscribeToSessionEvents,
+	} {
+		if err := subscribe(ctx); err != nil {
+			t.Fatalf("Subscribe failed: %v", err)
+		}
+	}
+	priceRef := subscriptionsOn(mockServer, EndpointPrices)[0].ReferenceId
+	for refID := range client.subscriptionManager.subscriptionKeysByReferenceId() {
+		client.UpdateLastMessageTimestamp(refID)
+	}
+
+	options, interval := client.monitorOptions()
+	if interval != 5*time.Second {
+		t.Errorf("Expected the monitor to wake every 5s for prices, got %v", interval)
+	}
+	monitored := 0
+	for _, opts := range options {
+		if opts.monitored() {
+			monitored++
+		}
+	}
+	if monitored != 2 {
+		t.Errorf("Expected prices and balance monitored (session never times out), got %d", monitored)
+	}
+
+	// 15s of silence: only prices exceed their 10s threshold - a targeted reset, not a reconnect
+	for i := 0; i < 3; i++ {
+		if err := clock.WaitForWaiters(1, 2*time.Second); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(5 * time.Second)
+	}
+	if !waitFor(t, 3*time.Second, func() bool {
+		prices := subscriptionsOn(mockServer, EndpointPrices)
+		return len(prices) == 1 && prices[0].ReferenceId != priceRef
+	}) {
+		t.Fatalf("Expected the price subscription to be reset, still %+v", subscriptionsOn(mockServer, EndpointPrices))
+	}
+	if got := len(mockServer.Connections()); got != 1 {
+		t.Errorf("Expected no reconnect for a partial timeout, server saw %d connects", got)
+	}
+}
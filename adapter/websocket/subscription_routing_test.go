@@ -0,0 +1,96 @@
+package websocket
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/bjoelf/saxo-adapter/adapter/websocket/mocktesting"
+)
+
+func TestSaxoWebSocketClient_ReferenceIDRouting(t *testing.T) {
+	mockServer := mocktesting.NewMockSaxoWebSocketServer()
+	defer mockServer.Close()
+
+	mockAuth := &MockAuthClient{
+		authenticated: true,
+		accessToken:   "test_token_123",
+		httpClient:    mockServer.GetHTTPClient(),
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(mockAuth, mockServer.GetBaseURL(), mockServer.GetWebSocketURL(), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.SubscribeToPortfolio(ctx); err != nil {
+		t.Fatalf("SubscribeToPortfolio failed: %v", err)
+	}
+
+	// Custom subscription - payloads reach the registered handler under its ReferenceId
+	received := make(chan string, 1)
+	_, err := client.RegisterSubscription(ctx, "positions", "/port/v1/positions/subscriptions",
+		map[string]interface{}{"ClientKey": mocktesting.MockClientKey},
+		func(referenceID string, payload []byte) error {
+			received <- string(payload)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("RegisterSubscription failed: %v", err)
+	}
+
+	var positionsRefID string
+	for refID, sub := range mockServer.GetActiveSubscriptions() {
+		if sub.Endpoint == "/port/v1/positions/subscriptions" {
+			positionsRefID = refID
+		}
+	}
+	if positionsRefID == "" {
+		t.Fatal("Custom subscription not created on the server")
+	}
+
+	if err := mockServer.SendDataMessage(positionsRefID, []map[string]interface{}{{"PositionId": "p1"}}); err != nil {
+		t.Fatalf("SendDataMessage failed: %v", err)
+	}
+	select {
+	case payload := <-received:
+		if payload != `[{"PositionId":"p1"}]` {
+			t.Errorf("Unexpected payload %s", payload)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Custom handler not called")
+	}
+
+	// Built-in subscription routed by its exact ReferenceId
+	if err := mockServer.SendPortfolioUpdate(1000, 100, 900); err != nil {
+		t.Fatalf("SendPortfolioUpdate failed: %v", err)
+	}
+	select {
+	case update := <-client.GetPortfolioUpdateChannel():
+		if update.Balance != 1000 || update.MarginFree != 900 {
+			t.Errorf("Unexpected portfolio update %+v", update)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Portfolio update not routed")
+	}
+
+	// Unknown reference IDs are not routed by prefix any more
+	if handler := client.subscriptionManager.handlerFor("orders-20250101-000000"); handler != nil {
+		t.Error("Expected no handler for a reference ID that was never subscribed")
+	}
+
+	if err := client.UnregisterSubscription(ctx, "positions"); err != nil {
+		t.Fatalf("UnregisterSubscription failed: %v", err)
+	}
+	if handler := client.subscriptionManager.handlerFor(positionsRefID); handler != nil {
+		t.Error("Expected handler removed after UnregisterSubscription")
+	}
+}
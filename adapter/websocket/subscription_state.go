@@ -0,0 +1,235 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SubscriptionStateVersion is bumped when SubscriptionState changes incompatibly
+const SubscriptionStateVersion = 1
+
+// SubscriptionState is what the client re-subscribes after a process restart
+// Price handles (Subscribe), depth and RegisterSubscription are not included: they need the caller's handles
+type SubscriptionState struct {
+	Version       int              `json:"version"`
+	SavedAt       time.Time        `json:"saved_at"`
+	Prices        map[string][]int `json:"prices,omitempty"` // Asset type -> UICs of SubscribeToPrices
+	Orders        bool             `json:"orders,omitempty"`
+	Portfolio     bool             `json:"portfolio,omitempty"`
+	SessionEvents bool             `json:"session_events,omitempty"`
+}
+
+// SubscriptionStateStore persists SubscriptionState (see SetSubscriptionStore)
+type SubscriptionStateStore interface {
+	// LoadSubscriptionState returns the saved state, nil when nothing was saved yet
+	LoadSubscriptionState() (*SubscriptionState, error)
+	SaveSubscriptionState(state SubscriptionState) error
+}
+
+// FileSubscriptionStore keeps SubscriptionState in a JSON file
+type FileSubscriptionStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSubscriptionStore stores the state at path (a missing file is an empty state)
+func NewFileSubscriptionStore(path string) *FileSubscriptionStore {
+	return &FileSubscriptionStore{path: path}
+}
+
+// LoadSubscriptionState implements SubscriptionStateStore
+func (s *FileSubscriptionStore) LoadSubscriptionState() (*SubscriptionState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read subscription state: %w", err)
+	}
+	var state SubscriptionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse subscription state %s: %w", s.path, err)
+	}
+	if state.Version != SubscriptionStateVersion {
+		return nil, fmt.Errorf("unsupported subscription state version %d", state.Version)
+	}
+	return &state, nil
+}
+
+// SaveSubscriptionState implements SubscriptionStateStore, replacing the file atomically
+func (s *FileSubscriptionStore) SaveSubscriptionState(state SubscriptionState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal subscription state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create subscription state directory: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write subscription state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace subscription state: %w", err)
+	}
+	return nil
+}
+
+// subscriptionIntents tracks the persisted subscriptions besides prices (kept by priceRouter)
+type subscriptionIntents struct {
+	mu            sync.Mutex
+	store         SubscriptionStateStore
+	restored      bool
+	orders        bool
+	portfolio     bool
+	sessionEvents bool
+}
+
+// SetSubscriptionStore makes the client save its price, order, portfolio and session subscriptions
+// to store on every change, and re-create the saved ones on the first Connect. Call before Connect.
+// Subscriptions made before Connect take precedence over saved ones of the same kind and asset type
+func (ws *SaxoWebSocketClient) SetSubscriptionStore(store SubscriptionStateStore) {
+	ws.intents.mu.Lock()
+	defer ws.intents.mu.Unlock()
+	ws.intents.store = store
+}
+
+// recordIntent marks a subscription kind as wanted and saves the state
+func (ws *SaxoWebSocketClient) recordIntent(mark func(*subscriptionIntents)) {
+	ws.intents.mu.Lock()
+	mark(&ws.intents)
+	ws.intents.mu.Unlock()
+	ws.saveSubscriptionState()
+}
+
+// subscriptionState snapshots the current subscription intents
+func (ws *SaxoWebSocketClient) subscriptionState() SubscriptionState {
+	ws.intents.mu.Lock()
+	state := SubscriptionState{
+		Version:       SubscriptionStateVersion,
+		SavedAt:       ws.clock.Now().UTC(),
+		Orders:        ws.intents.orders,
+		Portfolio:     ws.intents.portfolio,
+		SessionEvents: ws.intents.sessionEvents,
+	}
+	ws.intents.mu.Unlock()
+
+	ws.priceRouter.mu.RLock()
+	defer ws.priceRouter.mu.RUnlock()
+	for assetType, uics := range ws.priceRouter.shared {
+		if len(uics) == 0 {
+			continue
+		}
+		if state.Prices == nil {
+			state.Prices = make(map[string][]int)
+		}
+		sorted := append([]int(nil), uics...)
+		sort.Ints(sorted)
+		state.Prices[assetType] = sorted
+	}
+	return state
+}
+
+// saveSubscriptionState writes the current state to the store, if one is set
+// Nothing is written before the saved state was restored, so subscribing before Connect cannot erase it.
+// Failures are logged only - a subscription never fails because its state could not be saved
+func (ws *SaxoWebSocketClient) saveSubscriptionState() {
+	ws.intents.mu.Lock()
+	store, restored := ws.intents.store, ws.intents.restored
+	ws.intents.mu.Unlock()
+	if store == nil || !restored {
+		return
+	}
+	if err := store.SaveSubscriptionState(ws.subscriptionState()); err != nil {
+		ws.logger.Warn("Failed to save subscription state",
+			"function", "saveSubscriptionState",
+			"error", err)
+	}
+}
+
+// restoreSubscriptions queues the saved subscriptions, once per client, before the first connection
+// Runs while disconnected, so each subscribe is deferred and flushed by Connect
+func (ws *SaxoWebSocketClient) restoreSubscriptions(ctx context.Context) {
+	ws.intents.mu.Lock()
+	store := ws.intents.store
+	if store == nil || ws.intents.restored {
+		ws.intents.mu.Unlock()
+		return
+	}
+	ws.intents.restored = true
+	ws.intents.mu.Unlock()
+	// Subscriptions made before Connect are written even when nothing was restored
+	defer ws.saveSubscriptionState()
+
+	state, err := store.LoadSubscriptionState()
+	if err != nil {
+		ws.logger.Warn("Failed to load subscription state, starting without it",
+			"function", "restoreSubscriptions",
+			"error", err)
+		return
+	}
+	if state == nil {
+		return
+	}
+
+	assetTypes := make([]string, 0, len(state.Prices))
+	for assetType := range state.Prices {
+		assetTypes = append(assetTypes, assetType)
+	}
+	sort.Strings(assetTypes)
+	for _, assetType := range assetTypes {
+		ws.priceRouter.mu.RLock()
+		explicit := len(ws.priceRouter.shared[assetType]) > 0
+		ws.priceRouter.mu.RUnlock()
+		if explicit {
+			continue
+		}
+		if err := ws.SubscribeToPrices(ctx, uicStrings(state.Prices[assetType]), assetType); err != nil {
+			ws.logger.Warn("Failed to restore price subscription",
+				"function", "restoreSubscriptions",
+				"asset_type", assetType,
+				"error", err)
+		}
+	}
+
+	restore := []struct {
+		name      string
+		wanted    bool
+		subscribe func(ctx context.Context) error
+	}{
+		{"orders", state.Orders, ws.SubscribeToOrders},
+		{"portfolio", state.Portfolio, ws.SubscribeToPortfolio},
+		{"session_events", state.SessionEvents, ws.SubscribeToSessionEvents},
+	}
+	for _, r := range restore {
+		if !r.wanted {
+			continue
+		}
+		if err := r.subscribe(ctx); err != nil {
+			ws.logger.Warn("Failed to restore subscription",
+				"function", "restoreSubscriptions",
+				"subscription", r.name,
+				"error", err)
+		}
+	}
+
+	ws.logger.Info("Subscription state restored",
+		"function", "restoreSubscriptions",
+		"price_asset_types", len(assetTypes),
+		"orders", state.Orders,
+		"portfolio", state.Portfolio,
+		"session_events", state.SessionEvents)
+}
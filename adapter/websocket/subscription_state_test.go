@@ -0,0 +1,65 @@
+package websocket
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSaxoWebSocketClient_SubscriptionStateSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state", "subscriptions.json")
+
+	// First run: nothing saved yet, subscriptions made before Connect are written on restore
+	first := newModelTestClient()
+	first.SetSubscriptionStore(NewFileSubscriptionStore(path))
+	if err := first.SubscribeToPrices(ctx, []string{"22", "21"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+	if err := first.SubscribeToOrders(ctx); err != nil {
+		t.Fatalf("SubscribeToOrders failed: %v", err)
+	}
+	first.restoreSubscriptions(ctx)
+	if err := first.SubscribeToSessionEvents(ctx); err != nil {
+		t.Fatalf("SubscribeToSessionEvents failed: %v", err)
+	}
+
+	saved, err := NewFileSubscriptionStore(path).LoadSubscriptionState()
+	if err != nil || saved == nil {
+		t.Fatalf("LoadSubscriptionState failed: %v, %v", saved, err)
+	}
+	if !reflect.DeepEqual(saved.Prices, map[string][]int{"FxSpot": {21, 22}}) || !saved.Orders || saved.Portfolio || !saved.SessionEvents {
+		t.Fatalf("Unexpected saved state %+v", saved)
+	}
+
+	// Second run: saved subscriptions are queued for Connect, explicit ones win per asset type
+	second := newModelTestClient()
+	second.SetSubscriptionStore(NewFileSubscriptionStore(path))
+	if err := second.SubscribeToPrices(ctx, []string{"31"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+	second.restoreSubscriptions(ctx)
+
+	pending := second.PendingSubscriptions()
+	want := []string{"prices:FxSpot", "orders", "session_events"}
+	if !reflect.DeepEqual(pending, want) {
+		t.Errorf("Expected pending %v, got %v", want, pending)
+	}
+	if uics := second.priceRouter.uicsFor("FxSpot"); !reflect.DeepEqual(uics, []int{31}) {
+		t.Errorf("Explicit FxSpot subscription should win over the saved one, got %v", uics)
+	}
+
+	// Restore happens once per client
+	second.restoreSubscriptions(ctx)
+	if len(second.PendingSubscriptions()) != len(want) {
+		t.Errorf("Second restore should be a no-op, got %v", second.PendingSubscriptions())
+	}
+}
+
+func TestFileSubscriptionStore_MissingFileIsEmpty(t *testing.T) {
+	state, err := NewFileSubscriptionStore(filepath.Join(t.TempDir(), "none.json")).LoadSubscriptionState()
+	if state != nil || err != nil {
+		t.Errorf("Expected nil state without error, got %+v, %v", state, err)
+	}
+}
@@ -0,0 +1,175 @@
+package websocket
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// symbolRetryDelay is how long a UIC whose lookup failed is left unresolved before the next attempt
+const symbolRetryDelay = time.Minute
+
+// instrumentSymbol is the display identity of one UIC
+type instrumentSymbol struct {
+	symbol      string
+	description string
+	failedAt    time.Time // Set when the lookup failed - retried after symbolRetryDelay
+}
+
+// symbolResolver attaches Symbol and Description to price updates
+// Known UICs come from the InstrumentStore; others are looked up through the provider on first
+// sight, batched on one goroutine so the processor never waits on HTTP
+type symbolResolver struct {
+	mu       sync.Mutex
+	provider saxo.InstrumentDetailsProvider // nil = InstrumentStore only
+	symbols  map[int]instrumentSymbol
+	pending  map[int]bool
+	running  bool
+
+	// Lookup goroutine lifecycle - Close cancels it and waits on done
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newSymbolResolver() *symbolResolver {
+	return &symbolResolver{
+		symbols: make(map[int]instrumentSymbol),
+		pending: make(map[int]bool),
+	}
+}
+
+// SetSymbolResolver makes price updates carry Symbol and Description for UICs missing from the
+// InstrumentStore: the first update of a new UIC triggers a GetInstrumentDetails lookup (typically
+// the existing BrokerClient), later updates carry the result. nil = InstrumentStore only
+func (ws *SaxoWebSocketClient) SetSymbolResolver(provider saxo.InstrumentDetailsProvider) {
+	ws.symbols.mu.Lock()
+	defer ws.symbols.mu.Unlock()
+	ws.symbols.provider = provider
+}
+
+// InstrumentInfo returns what the client knows about uic: the InstrumentStore entry, else the
+// symbol resolved for its price updates. false when neither knows the UIC yet
+func (ws *SaxoWebSocketClient) InstrumentInfo(uic int) (saxo.InstrumentMetadata, bool) {
+	if ws.instrumentStore != nil {
+		if meta, ok := ws.instrumentStore.Get(uic); ok && meta.Symbol != "" {
+			return meta, true
+		}
+	}
+	ws.symbols.mu.Lock()
+	resolved, ok := ws.symbols.symbols[uic]
+	ws.symbols.mu.Unlock()
+	if !ok || resolved.symbol == "" {
+		return saxo.InstrumentMetadata{}, false
+	}
+	return saxo.InstrumentMetadata{Uic: uic, Symbol: resolved.symbol, Description: resolved.description}, true
+}
+
+// decoratePriceUpdate fills Symbol and Description of update when known, scheduling a lookup otherwise
+func (ws *SaxoWebSocketClient) decoratePriceUpdate(update *saxo.PriceUpdate) {
+	if meta, ok := ws.InstrumentInfo(update.Uic); ok {
+		update.Symbol = meta.Symbol
+		update.Description = meta.Description
+		return
+	}
+	ws.requestSymbol(update.Uic)
+}
+
+// requestSymbol queues uic for lookup unless it is pending, resolved or failed recently
+func (ws *SaxoWebSocketClient) requestSymbol(uic int) {
+	r := ws.symbols
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.provider == nil || r.pending[uic] || ws.isShutdown() {
+		return
+	}
+	if resolved, ok := r.symbols[uic]; ok && (resolved.failedAt.IsZero() || ws.clock.Now().Sub(resolved.failedAt) < symbolRetryDelay) {
+		return
+	}
+	r.pending[uic] = true
+	if !r.running {
+		ctx, cancel := context.WithCancel(context.Background())
+		r.running = true
+		r.cancel = cancel
+		r.done = make(chan struct{})
+		go ws.resolveSymbols(ctx, r.provider, r.done)
+	}
+}
+
+// resolveSymbols looks up pending UICs in batches until none are left or ctx is cancelled
+func (ws *SaxoWebSocketClient) resolveSymbols(ctx context.Context, provider saxo.InstrumentDetailsProvider, done chan struct{}) {
+	defer close(done)
+
+	r := ws.symbols
+	for {
+		r.mu.Lock()
+		if ctx.Err() != nil {
+			// Stopped by Close - dropped UICs are requested again by their next update
+			clear(r.pending)
+		}
+		if len(r.pending) == 0 {
+			r.running = false
+			r.cancel = nil
+			r.mu.Unlock()
+			return
+		}
+		uics := make([]int, 0, len(r.pending))
+		for uic := range r.pending {
+			uics = append(uics, uic)
+		}
+		r.mu.Unlock()
+		sort.Ints(uics)
+
+		requestCtx, cancel := saxo.RequestContext(ctx, ws.requestTimeout)
+		details, err := provider.GetInstrumentDetails(requestCtx, uics)
+		cancel()
+		if ctx.Err() != nil {
+			continue
+		}
+		if err != nil {
+			// Partial results still count - the missing UICs are retried later
+			ws.logger.Warn("Symbol lookup failed",
+				"function", "resolveSymbols",
+				"uics", uics,
+				"error", err)
+		}
+
+		now := ws.clock.Now()
+		r.mu.Lock()
+		for _, uic := range uics {
+			delete(r.pending, uic)
+			r.symbols[uic] = instrumentSymbol{failedAt: now}
+		}
+		for _, detail := range details {
+			r.symbols[detail.Uic] = instrumentSymbol{symbol: detail.Symbol, description: detail.Description}
+		}
+		r.mu.Unlock()
+
+		ws.logger.Debug("Resolved instrument symbols",
+			"function", "resolveSymbols",
+			"requested", len(uics),
+			"resolved", len(details))
+	}
+}
+
+// stopSymbolResolver cancels a running lookup and waits for its goroutine to exit
+func (ws *SaxoWebSocketClient) stopSymbolResolver() {
+	r := ws.symbols
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		ws.logger.Warn("Symbol resolver exit timeout (forced shutdown)",
+			"function", "stopSymbolResolver")
+	}
+}
@@ -0,0 +1,139 @@
+package websocket
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+// detailsProvider resolves UICs from a fixed map and records the lookups
+type detailsProvider struct {
+	details map[int]saxo.InstrumentDetail
+
+	mu      sync.Mutex
+	lookups [][]int
+}
+
+func (p *detailsProvider) GetInstrumentDetails(ctx context.Context, uics []int) ([]saxo.InstrumentDetail, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lookups = append(p.lookups, uics)
+	var details []saxo.InstrumentDetail
+	for _, uic := range uics {
+		if detail, ok := p.details[uic]; ok {
+			details = append(details, detail)
+		}
+	}
+	return details, nil
+}
+
+func (p *detailsProvider) lookupCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.lookups)
+}
+
+func TestSaxoWebSocketClient_PriceUpdateSymbols(t *testing.T) {
+	store := saxo.NewInstrumentStore(slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	if err := store.Put(saxo.InstrumentMetadata{Uic: 21, Ticker: "EURUSD", Symbol: "EURUSD", Description: "Euro/US Dollar", AssetType: "FxSpot"}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	provider := &detailsProvider{details: map[int]saxo.InstrumentDetail{
+		31: {Uic: 31, Symbol: "GBPUSD", Description: "British Pound/US Dollar"},
+	}}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := NewSaxoWebSocketClient(&MockAuthClient{authenticated: true, accessToken: "token"}, "http://localhost", "ws://localhost", logger, saxo.WithInstrumentStore(store))
+	client.SetSymbolResolver(provider)
+
+	// Not connected - the subscription is deferred, but the shared channel takes the UICs
+	if err := client.SubscribeToPrices(context.Background(), []string{"21", "31"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+
+	payload := []byte(`[{"Uic":21,"Quote":{"Bid":1.1,"Ask":1.2,"Mid":1.15}},{"Uic":31,"Quote":{"Bid":1.3,"Ask":1.4,"Mid":1.35}}]`)
+	if err := client.messageHandler.handlePriceUpdate(payload); err != nil {
+		t.Fatalf("handlePriceUpdate failed: %v", err)
+	}
+	first := map[int]saxo.PriceUpdate{}
+	for i := 0; i < 2; i++ {
+		update := <-client.GetPriceUpdateChannel()
+		first[update.Uic] = update
+	}
+	if first[21].Symbol != "EURUSD" || first[21].Description != "Euro/US Dollar" {
+		t.Errorf("Expected the store's symbol for UIC 21, got %+v", first[21])
+	}
+	if first[31].Symbol != "" {
+		t.Errorf("Expected UIC 31 unresolved on first sight, got %q", first[31].Symbol)
+	}
+
+	if !waitFor(t, 2*time.Second, func() bool { _, ok := client.InstrumentInfo(31); return ok }) {
+		t.Fatal("UIC 31 was not resolved")
+	}
+	if err := client.messageHandler.handlePriceUpdate(payload); err != nil {
+		t.Fatalf("handlePriceUpdate failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		update := <-client.GetPriceUpdateChannel()
+		if update.Uic == 31 && (update.Symbol != "GBPUSD" || update.Description != "British Pound/US Dollar") {
+			t.Errorf("Expected the resolved symbol for UIC 31, got %+v", update)
+		}
+	}
+
+	// One lookup for the unknown UIC only - the store covers UIC 21
+	if got := provider.lookupCount(); got != 1 || provider.lookups[0][0] != 31 {
+		t.Errorf("Expected a single lookup of UIC 31, got %v", provider.lookups)
+	}
+}
+
+// blockingDetailsProvider blocks every lookup until its context is cancelled
+type blockingDetailsProvider struct {
+	started   chan struct{}
+	cancelled chan struct{}
+}
+
+func (p *blockingDetailsProvider) GetInstrumentDetails(ctx context.Context, uics []int) ([]saxo.InstrumentDetail, error) {
+	close(p.started)
+	<-ctx.Done()
+	close(p.cancelled)
+	return nil, ctx.Err()
+}
+
+func TestSaxoWebSocketClient_CloseStopsSymbolResolver(t *testing.T) {
+	provider := &blockingDetailsProvider{started: make(chan struct{}), cancelled: make(chan struct{})}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewSaxoWebSocketClient(&MockAuthClient{authenticated: true, accessToken: "token"}, "http://localhost", "ws://localhost", logger,
+		saxo.WithTimeout(time.Hour))
+	client.SetSymbolResolver(provider)
+	if err := client.SubscribeToPrices(context.Background(), []string{"31"}, "FxSpot"); err != nil {
+		t.Fatalf("SubscribeToPrices failed: %v", err)
+	}
+
+	if err := client.messageHandler.handlePriceUpdate([]byte(`[{"Uic":31,"Quote":{"Bid":1.3,"Ask":1.4,"Mid":1.35}}]`)); err != nil {
+		t.Fatalf("handlePriceUpdate failed: %v", err)
+	}
+	select {
+	case <-provider.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a symbol lookup")
+	}
+	if !client.anyGoroutineRunning() {
+		t.Error("Expected the symbol resolver to count as running")
+	}
+
+	client.Close()
+	select {
+	case <-provider.cancelled:
+	default:
+		t.Fatal("Expected Close to cancel the lookup")
+	}
+	if client.anyGoroutineRunning() {
+		t.Error("Expected no goroutines after Close")
+	}
+}
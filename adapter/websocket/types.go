@@ -6,8 +6,10 @@ import "time"
 // Following legacy pattern from broker_websocket.go - enables async message processing
 type websocketMessage struct {
 	MessageType int       // WebSocket message type (Binary, Text, Close, Ping, Pong)
-	Data        []byte    // Message payload (copied to prevent buffer reuse issues)
+	Data        []byte    // Message payload - valid until processOneMessage returns
 	ReceivedAt  time.Time // Timestamp when message was received
+
+	buffer *frameBuffer // Pooled buffer behind Data, released after processing (nil = not pooled)
 }
 
 // Subscription represents a WebSocket subscription following Saxo streaming API patterns
@@ -21,9 +23,16 @@ type Subscription struct {
 	Arguments           map[string]interface{} `json:"Arguments"`
 	SubscriptionMessage map[string]interface{} // Original subscription message for resubscription
 	EndpointPath        string                 // Saxo API endpoint path for this subscription
+	Handler             DataHandler            // Processes data messages for ReferenceId (see SubscriptionManager.handlerFor)
 	LastMessageTime     time.Time              // Track last message for timeout detection
+
+	timedHandler timedDataHandler // Built-in handler taking the frame's read time, preferred over Handler
 }
 
+// timedDataHandler is a DataHandler that also gets the read time of the frame carrying payload
+// Messages may be handled on worker goroutines (SetMessageWorkers), so the time travels with them
+type timedDataHandler func(referenceID string, payload []byte, receivedAt time.Time) error
+
 // ResetMessage represents a subscription reset control message from Saxo
 // Following legacy pattern for handling _resetsubscriptions control messages
 type ResetMessage struct {
@@ -31,6 +40,14 @@ type ResetMessage struct {
 	TargetReferenceIds []string `json:"TargetReferenceIds"`
 }
 
+// DisconnectMessage represents a _disconnect control message from Saxo
+// Sent ahead of planned maintenance; ReconnectDelaySeconds, when present, is the advised wait
+type DisconnectMessage struct {
+	ReferenceID           string `json:"ReferenceId"`
+	Reason                string `json:"Reason,omitempty"`
+	ReconnectDelaySeconds int    `json:"ReconnectDelaySeconds,omitempty"`
+}
+
 // HeartbeatMessage represents a heartbeat control message from Saxo
 // Following legacy pattern for _heartbeat control messages
 type HeartbeatMessage struct {
@@ -2,6 +2,7 @@ package websocket
 
 import (
 	"fmt"
+	"sync"
 	"time"
 )
 
@@ -14,6 +15,47 @@ func generateHumanReadableID(subscriptionType string) string {
 	return fmt.Sprintf("%s-%s", subscriptionType, timestamp)
 }
 
+// contextIDs makes context IDs unique across every client in the process (see ContextPool)
+// issued holds every ID generated within contextIDRetention, keyed to its generation time
+var contextIDs struct {
+	mu     sync.Mutex
+	issued map[string]time.Time
+}
+
+// contextIDRetention is how long generated IDs are remembered - IDs carry their generation
+// time, so only IDs from the same millisecond (or a clock stepped back) can collide
+const contextIDRetention = time.Minute
+
+// generateContextID returns a WebSocket context ID that differs from previous
+// Format "{name}-{YYYYMMDD-HHMMSS}-{millis}": a reconnect within the same second must not reuse
+// the old context, since ResubscribeAll keeps ReferenceIds and those are scoped to the context.
+// An ID already issued to any client gets a "-N" suffix
+func generateContextID(name, previous string) string {
+	now := time.Now()
+	base := fmt.Sprintf("%s-%s-%03d", name, now.Format("20060102-150405"), now.Nanosecond()/int(time.Millisecond))
+
+	contextIDs.mu.Lock()
+	defer contextIDs.mu.Unlock()
+	if contextIDs.issued == nil {
+		contextIDs.issued = make(map[string]time.Time)
+	}
+	for id, issuedAt := range contextIDs.issued {
+		if now.Sub(issuedAt) > contextIDRetention {
+			delete(contextIDs.issued, id)
+		}
+	}
+
+	contextID := base
+	for count := 1; ; count++ {
+		if _, taken := contextIDs.issued[contextID]; !taken && contextID != previous {
+			break
+		}
+		contextID = fmt.Sprintf("%s-%d", base, count)
+	}
+	contextIDs.issued[contextID] = now
+	return contextID
+}
+
 // isControlMessage determines if a message is a control message from Saxo
 // Following legacy patterns for _heartbeat, _disconnect, _resetsubscriptions
 func isControlMessage(refID string) bool {
@@ -0,0 +1,495 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+	"github.com/bjoelf/saxo-adapter/adapter/server"
+	"github.com/bjoelf/saxo-adapter/adapter/websocket"
+)
+
+// app holds the clients created lazily by the commands
+type app struct {
+	stdout io.Writer
+	logger *slog.Logger
+	config saxo.SaxoConfig
+
+	auth   *saxo.SaxoAuthClient
+	broker saxo.BrokerClient
+	ws     *websocket.SaxoWebSocketClient
+}
+
+// authClient creates the auth client from the environment
+func (a *app) authClient() (*saxo.SaxoAuthClient, error) {
+	if a.auth != nil {
+		return a.auth, nil
+	}
+	a.config = saxo.FromEnv()
+	a.config.ManualOrders = true // Every order is typed in by the user
+	authClient, err := saxo.CreateSaxoAuthClient(a.config, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	a.auth = authClient
+	return a.auth, nil
+}
+
+// brokerClient requires a stored token - it never opens the browser
+func (a *app) brokerClient() (saxo.BrokerClient, error) {
+	if a.broker != nil {
+		return a.broker, nil
+	}
+	authClient, err := a.authClient()
+	if err != nil {
+		return nil, err
+	}
+	if !authClient.IsAuthenticated() {
+		return nil, fmt.Errorf("not logged in - run 'saxo login' first")
+	}
+	broker, err := saxo.CreateBrokerServices(authClient, a.config, a.logger)
+	if err != nil {
+		return nil, err
+	}
+	a.broker = broker
+	return a.broker, nil
+}
+
+// shutdown stops the clients in streaming, broker, auth order (see saxo.Shutdown)
+func (a *app) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var broker saxo.Shutdowner
+	if s, ok := a.broker.(saxo.Shutdowner); ok {
+		broker = s
+	}
+	var components []saxo.Shutdowner
+	if a.ws != nil {
+		components = append(components, a.ws)
+	}
+	if broker != nil {
+		components = append(components, broker)
+	}
+	if a.auth != nil {
+		components = append(components, a.auth)
+	}
+	if err := saxo.Shutdown(ctx, components...); err != nil {
+		a.logger.Warn("Shutdown incomplete", "error", err)
+	}
+}
+
+// parseArgs parses flags anywhere among the positional arguments
+// (flag.Parse stops at the first positional, so "orders cancel 123 -account X" would ignore -account)
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// resolveInstrument accepts a UIC or a symbol searched within assetType
+func resolveInstrument(ctx context.Context, broker saxo.BrokerClient, arg, assetType string) (saxo.Instrument, error) {
+	if uic, err := strconv.Atoi(arg); err == nil {
+		return saxo.Instrument{Ticker: arg, Identifier: uic, Uic: uic, AssetType: assetType}, nil
+	}
+
+	found, err := broker.SearchInstruments(ctx, saxo.InstrumentSearchParams{Keywords: arg, AssetType: assetType})
+	if err != nil {
+		return saxo.Instrument{}, fmt.Errorf("instrument search failed: %w", err)
+	}
+	return pickInstrument(found.Instruments, arg, assetType)
+}
+
+// pickInstrument prefers an exact symbol match (EURUSD matches "EURUSD" and "EURUSD:xidealpro")
+func pickInstrument(found []saxo.Instrument, arg, assetType string) (saxo.Instrument, error) {
+	for _, instrument := range found {
+		symbol, _, _ := strings.Cut(instrument.Symbol, ":")
+		if strings.EqualFold(symbol, arg) {
+			instrument.Ticker = arg
+			return instrument, nil
+		}
+	}
+	if len(found) == 1 {
+		found[0].Ticker = arg
+		return found[0], nil
+	}
+	if len(found) == 0 {
+		return saxo.Instrument{}, fmt.Errorf("no %s instrument matches %q", assetType, arg)
+	}
+	var candidates []string
+	for _, instrument := range found {
+		candidates = append(candidates, fmt.Sprintf("%s (%d)", instrument.Symbol, instrument.Identifier))
+	}
+	return saxo.Instrument{}, fmt.Errorf("%q is ambiguous, use a UIC: %s", arg, strings.Join(candidates, ", "))
+}
+
+func runLogin(ctx context.Context, a *app, args []string) error {
+	authClient, err := a.authClient()
+	if err != nil {
+		return err
+	}
+	if err := authClient.Login(ctx); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Logged in to %s (%s), token valid until %s, refresh until %s\n",
+		authClient.Provider(), a.config.Environment,
+		authClient.GetTokenExpiry().Format(time.RFC3339),
+		authClient.GetRefreshExpiry().Format(time.RFC3339))
+	return nil
+}
+
+func runLogout(ctx context.Context, a *app, args []string) error {
+	authClient, err := a.authClient()
+	if err != nil {
+		return err
+	}
+	if err := authClient.Logout(); err != nil {
+		return err
+	}
+	fmt.Fprintln(a.stdout, "Logged out")
+	return nil
+}
+
+func runBalance(ctx context.Context, a *app, args []string) error {
+	broker, err := a.brokerClient()
+	if err != nil {
+		return err
+	}
+	balance, err := broker.GetBalance(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Currency\t%s\n", balance.Currency)
+	fmt.Fprintf(w, "Cash balance\t%.2f\n", balance.CashBalance)
+	fmt.Fprintf(w, "Total value\t%.2f\n", balance.TotalValue)
+	fmt.Fprintf(w, "Margin available\t%.2f\n", balance.MarginAvailableForTrading)
+	fmt.Fprintf(w, "Margin utilization\t%.1f%%\n", balance.MarginUtilizationPct)
+	fmt.Fprintf(w, "Unrealized P/L\t%.2f\n", balance.UnrealizedMarginOpenProfitLoss)
+	fmt.Fprintf(w, "Open positions\t%d\n", balance.OpenPositionsCount)
+	fmt.Fprintf(w, "Orders\t%d\n", balance.OrdersCount)
+	return w.Flush()
+}
+
+func runAccounts(ctx context.Context, a *app, args []string) error {
+	broker, err := a.brokerClient()
+	if err != nil {
+		return err
+	}
+	accounts, err := broker.GetAccounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACCOUNT KEY\tTYPE\tCURRENCY\tCLIENT KEY")
+	for _, account := range accounts.Data {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", account.AccountKey, account.AccountType, account.Currency, account.ClientKey)
+	}
+	return w.Flush()
+}
+
+func runOrdersList(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("orders list", flag.ContinueOnError)
+	status := fs.String("status", "", "Order status filter, e.g. Working or All")
+	account := fs.String("account", "", "Only orders on this account key")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	broker, err := a.brokerClient()
+	if err != nil {
+		return err
+	}
+	params := saxo.OpenOrdersParams{AccountKey: *account}
+	if *status != "" {
+		params.Status = []string{*status}
+	}
+	orders, err := broker.GetOpenOrdersFiltered(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ORDER ID\tUIC\tTICKER\tSIDE\tTYPE\tAMOUNT\tPRICE\tSTATUS\tDURATION")
+	for _, order := range orders {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%g\t%g\t%s\t%s\n",
+			order.OrderID, order.Uic, order.Ticker, order.BuySell, order.OrderType,
+			order.Amount, order.Price, order.Status, order.OrderDuration)
+	}
+	return w.Flush()
+}
+
+func runOrdersCancel(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("orders cancel", flag.ContinueOnError)
+	account := fs.String("account", "", "Account key (default: first account)")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	broker, err := a.brokerClient()
+	if err != nil {
+		return err
+	}
+	accountKey, err := a.accountKey(ctx, *account)
+	if err != nil {
+		return err
+	}
+	if err := broker.CancelOrder(ctx, saxo.CancelOrderRequest{OrderID: positional[0], AccountKey: accountKey}); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Cancelled order %s\n", positional[0])
+	return nil
+}
+
+func runOrdersPlace(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("orders place", flag.ContinueOnError)
+	assetType := fs.String("asset-type", "FxSpot", "Asset type of the instrument")
+	orderType := fs.String("type", "Market", "Order type: Market, Limit, StopIfTraded")
+	price := fs.Float64("price", 0, "Order price (required unless Market)")
+	duration := fs.String("duration", "DayOrder", "Order duration")
+	expiry := fs.String("expiry", "", "GoodTillDate expiry: 2006-01-02 (whole day) or RFC3339 time")
+	account := fs.String("account", "", "Account key (default: first account)")
+	confirm := fs.Bool("yes", false, "Confirm placing an order on LIVE")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 3 {
+		return errUsage
+	}
+	side, err := parseSide(positional[1])
+	if err != nil {
+		return err
+	}
+	size, err := strconv.Atoi(positional[2])
+	if err != nil || size <= 0 {
+		return fmt.Errorf("invalid size %q", positional[2])
+	}
+	if *orderType != "Market" && *price <= 0 {
+		return fmt.Errorf("-price is required for %s orders", *orderType)
+	}
+	orderDuration, err := parseOrderDuration(*duration, *expiry)
+	if err != nil {
+		return err
+	}
+
+	broker, err := a.brokerClient()
+	if err != nil {
+		return err
+	}
+	// CRITICAL: never place LIVE orders from a typo - require explicit confirmation
+	if a.config.Environment == saxo.SaxoLive && !*confirm {
+		return fmt.Errorf("refusing to place a LIVE order without -yes")
+	}
+	instrument, err := resolveInstrument(ctx, broker, positional[0], *assetType)
+	if err != nil {
+		return err
+	}
+	accountKey, err := a.accountKey(ctx, *account)
+	if err != nil {
+		return err
+	}
+
+	resp, err := broker.PlaceOrder(ctx, saxo.OrderRequest{
+		Instrument: instrument,
+		AccountKey: accountKey,
+		Side:       side,
+		Size:       size,
+		Price:      *price,
+		OrderType:  *orderType,
+		Duration:   orderDuration,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "Placed %s %s %d %s (UIC %d): order %s, status %s\n",
+		*orderType, side, size, instrument.Ticker, instrument.Identifier, resp.OrderID, resp.Status)
+	return nil
+}
+
+// parseOrderDuration builds the order duration; an expiry implies GoodTillDate
+func parseOrderDuration(durationType, expiry string) (saxo.OrderDuration, error) {
+	if expiry == "" {
+		return saxo.Duration(durationType), nil
+	}
+	if durationType != saxo.DurationDayOrder && durationType != saxo.DurationGoodTillDate {
+		return saxo.OrderDuration{}, fmt.Errorf("-expiry only applies to %s", saxo.DurationGoodTillDate)
+	}
+	if day, err := time.Parse("2006-01-02", expiry); err == nil {
+		return saxo.GoodTillDate(day), nil
+	}
+	at, err := time.Parse(time.RFC3339, expiry)
+	if err != nil {
+		return saxo.OrderDuration{}, fmt.Errorf("invalid -expiry %q: want 2006-01-02 or RFC3339", expiry)
+	}
+	return saxo.GoodTillTime(at), nil
+}
+
+func parseSide(arg string) (string, error) {
+	switch strings.ToLower(arg) {
+	case "buy":
+		return "Buy", nil
+	case "sell":
+		return "Sell", nil
+	}
+	return "", fmt.Errorf("side must be buy or sell, got %q", arg)
+}
+
+// accountKey returns explicit, or the first account's key
+func (a *app) accountKey(ctx context.Context, explicit string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+	accounts, err := a.broker.GetAccounts(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get accounts: %w", err)
+	}
+	if len(accounts.Data) == 0 {
+		return "", fmt.Errorf("no accounts found")
+	}
+	return accounts.Data[0].AccountKey, nil
+}
+
+func runPrice(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("price", flag.ContinueOnError)
+	assetType := fs.String("asset-type", "FxSpot", "Asset type of the instrument")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		return errUsage
+	}
+
+	broker, err := a.brokerClient()
+	if err != nil {
+		return err
+	}
+	instrument, err := resolveInstrument(ctx, broker, positional[0], *assetType)
+	if err != nil {
+		return err
+	}
+	price, err := broker.GetInstrumentPrice(ctx, instrument)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "%s (UIC %d)  bid %g  ask %g  mid %g  spread %g  at %s\n",
+		instrument.Ticker, instrument.Identifier, price.Bid, price.Ask, price.Mid, price.Spread, price.Timestamp)
+	return nil
+}
+
+func runStream(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("stream", flag.ContinueOnError)
+	assetType := fs.String("asset-type", "FxSpot", "Asset type of the instruments")
+	duration := fs.Duration("for", 0, "Stop after this long (0 = until Ctrl+C)")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		return errUsage
+	}
+
+	broker, err := a.brokerClient()
+	if err != nil {
+		return err
+	}
+	tickers := make(map[int]string)
+	var uics []string
+	for _, arg := range positional {
+		instrument, err := resolveInstrument(ctx, broker, arg, *assetType)
+		if err != nil {
+			return err
+		}
+		tickers[instrument.Identifier] = instrument.Ticker
+		uics = append(uics, strconv.Itoa(instrument.Identifier))
+	}
+
+	a.ws = websocket.NewSaxoWebSocketClient(a.auth, a.auth.GetBaseURL(), a.auth.GetWebSocketURL(), a.logger)
+	if err := a.ws.Connect(ctx); err != nil {
+		return fmt.Errorf("connect failed: %w", err)
+	}
+	if err := a.ws.SubscribeToPrices(ctx, uics, *assetType); err != nil {
+		return fmt.Errorf("subscribe failed: %w", err)
+	}
+
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	prices := a.ws.GetPriceUpdateChannel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case price, ok := <-prices:
+			if !ok {
+				return fmt.Errorf("price stream closed")
+			}
+			fmt.Fprintf(a.stdout, "%s  %-8s  bid %-10g  ask %-10g  spread %g\n",
+				price.Timestamp.Format("15:04:05.000"), tickers[price.Uic], price.Bid, price.Ask, price.Ask-price.Bid)
+		}
+	}
+}
+
+// runServe exposes the logged-in session as the local REST + WebSocket gateway (adapter/server)
+func runServe(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", server.DefaultAddr, "Listen address")
+	token := fs.String("token", os.Getenv("GATEWAY_TOKEN"), "Bearer token required from clients (default $GATEWAY_TOKEN)")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	broker, err := a.brokerClient()
+	if err != nil {
+		return err
+	}
+	a.ws = websocket.NewSaxoWebSocketClient(a.auth, a.auth.GetBaseURL(), a.auth.GetWebSocketURL(), a.logger)
+	if err := a.ws.Connect(ctx); err != nil {
+		return fmt.Errorf("connect failed: %w", err)
+	}
+
+	gateway, err := server.New(a.auth, broker, a.ws, server.Config{Addr: *addr, Token: *token}, a.logger)
+	if err != nil {
+		return err
+	}
+	errs := make(chan error, 1)
+	go func() { errs <- gateway.ListenAndServe() }()
+	fmt.Fprintf(a.stdout, "Serving on http://%s - Ctrl+C to stop\n", *addr)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	// Gateway before the clients it serves - a.shutdown stops those
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return gateway.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,125 @@
+// Command saxo wraps the adapter for operations and issue reproduction:
+// log in, inspect the account and orders, fetch and stream prices without writing a Go program
+//
+// Configuration comes from the environment (see saxo.FromEnv): SAXO_CLIENT_ID, SAXO_CLIENT_SECRET,
+// SAXO_ENVIRONMENT, PROVIDER, TOKEN_STORAGE_PATH, TOKEN_PASSPHRASE
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// command is one CLI subcommand, e.g. "orders list"
+type command struct {
+	usage string // Arguments shown in help
+	help  string
+	run   func(ctx context.Context, app *app, args []string) error
+}
+
+var commands = map[string]command{
+	"login":         {"", "Log in via the browser and store the token", runLogin},
+	"logout":        {"", "Delete the stored token", runLogout},
+	"balance":       {"", "Show cash, margin and P/L of the default account", runBalance},
+	"accounts":      {"", "List accounts", runAccounts},
+	"orders list":   {"[-status Working] [-account KEY]", "List open orders", runOrdersList},
+	"orders cancel": {"<order-id> [-account KEY]", "Cancel an order", runOrdersCancel},
+	"orders place":  {"<instrument> <buy|sell> <size> [-type Market] [-price P] [-expiry DATE] [-yes]", "Place a quick order", runOrdersPlace},
+	"price":         {"<instrument> [-asset-type FxSpot]", "Show the latest quote", runPrice},
+	"stream":        {"<instrument>... [-asset-type FxSpot] [-for 30s]", "Stream quotes until Ctrl+C", runStream},
+	"serve":         {"[-addr 127.0.0.1:8090] [-token T]", "Serve the session as a local REST/WebSocket gateway", runServe},
+}
+
+// errUsage makes main print the usage and exit 2
+var errUsage = errors.New("usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	switch {
+	case errors.Is(err, errUsage):
+		usage(os.Stderr)
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "saxo:", err)
+		os.Exit(1)
+	}
+}
+
+// run parses global flags, resolves the subcommand and executes it
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	global := flag.NewFlagSet("saxo", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	verbose := global.Bool("v", false, "Log adapter activity to stderr")
+	global.Usage = func() { usage(stderr) }
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return errUsage
+	}
+
+	name, cmd, rest, ok := lookup(global.Args())
+	if !ok {
+		return errUsage
+	}
+
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelInfo
+	}
+	a := &app{
+		stdout: stdout,
+		logger: slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: level})),
+	}
+	defer a.shutdown()
+
+	if err := cmd.run(ctx, a, rest); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// lookup matches the longest command name ("orders list" before "orders")
+func lookup(args []string) (string, command, []string, bool) {
+	if len(args) >= 2 {
+		if cmd, ok := commands[args[0]+" "+args[1]]; ok {
+			return args[0] + " " + args[1], cmd, args[2:], true
+		}
+	}
+	if len(args) >= 1 {
+		if cmd, ok := commands[args[0]]; ok {
+			return args[0], cmd, args[1:], true
+		}
+	}
+	return "", command{}, nil, false
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: saxo [-v] <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd := commands[name]
+		fmt.Fprintf(w, "  %-46s %s\n", strings.TrimSpace(name+" "+cmd.usage), cmd.help)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Instruments are a UIC (21) or a symbol searched in the asset type (EURUSD).")
+	fmt.Fprintln(w, "Configuration: SAXO_CLIENT_ID, SAXO_CLIENT_SECRET, SAXO_ENVIRONMENT (sim|live), see docs/AUTHENTICATION.md")
+}
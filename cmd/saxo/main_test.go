@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	saxo "github.com/bjoelf/saxo-adapter/adapter"
+)
+
+func TestLookup(t *testing.T) {
+	name, _, rest, ok := lookup([]string{"orders", "cancel", "123"})
+	if !ok || name != "orders cancel" || len(rest) != 1 || rest[0] != "123" {
+		t.Errorf("lookup(orders cancel 123) = %q %v %v", name, rest, ok)
+	}
+	name, _, rest, ok = lookup([]string{"price", "EURUSD"})
+	if !ok || name != "price" || rest[0] != "EURUSD" {
+		t.Errorf("lookup(price EURUSD) = %q %v %v", name, rest, ok)
+	}
+	if _, _, _, ok := lookup([]string{"orders"}); ok {
+		t.Error("Bare 'orders' should not match a command")
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := run(context.Background(), []string{"bogus"}, &stdout, &stderr); !errors.Is(err, errUsage) {
+		t.Errorf("Unknown command: got %v, want errUsage", err)
+	}
+	if err := run(context.Background(), []string{"orders", "cancel"}, &stdout, &stderr); !errors.Is(err, errUsage) {
+		t.Errorf("Missing order ID: got %v, want errUsage", err)
+	}
+
+	usage(&stdout)
+	for _, want := range []string{"login", "orders list", "stream <instrument>"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Usage missing %q", want)
+		}
+	}
+}
+
+func TestParseArgs_FlagsAfterPositionals(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	account := fs.String("account", "", "")
+	positional, err := parseArgs(fs, []string{"123", "-account", "ACC1"})
+	if err != nil {
+		t.Fatalf("parseArgs failed: %v", err)
+	}
+	if len(positional) != 1 || positional[0] != "123" || *account != "ACC1" {
+		t.Errorf("Positional=%v account=%s", positional, *account)
+	}
+}
+
+func TestPickInstrument(t *testing.T) {
+	found := []saxo.Instrument{
+		{Identifier: 21, Symbol: "EURUSD"},
+		{Identifier: 1311, Symbol: "EURUSDSPECIAL"},
+	}
+	instrument, err := pickInstrument(found, "eurusd", "FxSpot")
+	if err != nil || instrument.Identifier != 21 || instrument.Ticker != "eurusd" {
+		t.Errorf("pickInstrument exact = %+v, %v", instrument, err)
+	}
+
+	if _, err := pickInstrument(found, "EUR", "FxSpot"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected ambiguous error, got %v", err)
+	}
+	if _, err := pickInstrument(nil, "XYZ", "FxSpot"); err == nil {
+		t.Error("Expected error for no match")
+	}
+}
@@ -21,9 +21,11 @@ func main() {
 
 	// Step 1: Create auth client
 	logger.Info("Creating authentication client...")
+	// Configuration from SAXO_CLIENT_ID, SAXO_CLIENT_SECRET and SAXO_ENVIRONMENT
+	config := saxo.FromEnv()
 	var authClient saxo.AuthClient
 	var err error
-	authClient, err = saxo.CreateSaxoAuthClient(logger)
+	authClient, err = saxo.CreateSaxoAuthClient(config, logger)
 	if err != nil {
 		logger.Error("Failed to create auth client: %v", "error", err)
 		os.Exit(1)
@@ -43,7 +45,7 @@ func main() {
 	logger.Info("Creating broker services...")
 
 	// CreateBrokerServices returns BrokerClient interface
-	brokerClient, err := saxo.CreateBrokerServices(authClient, logger)
+	brokerClient, err := saxo.CreateBrokerServices(authClient, config, logger)
 	if err != nil {
 		logger.Error("Failed to create broker services: %v", "error", err)
 		os.Exit(1)
@@ -20,9 +20,11 @@ func main() {
 
 	// Step 1: Create auth client
 	logger.Info("Creating authentication client...")
+	// Configuration from SAXO_CLIENT_ID, SAXO_CLIENT_SECRET and SAXO_ENVIRONMENT
+	config := saxo.FromEnv()
 	var authClient saxo.AuthClient
 	var err error
-	authClient, err = saxo.CreateSaxoAuthClient(logger)
+	authClient, err = saxo.CreateSaxoAuthClient(config, logger)
 	if err != nil {
 		logger.Error("Failed to create auth client: %v", "error", err)
 		os.Exit(1)
@@ -42,7 +44,7 @@ func main() {
 	logger.Info("Creating broker services...")
 
 	// CreateBrokerServices returns BrokerClient interface
-	brokerClient, err := saxo.CreateBrokerServices(authClient, logger)
+	brokerClient, err := saxo.CreateBrokerServices(authClient, config, logger)
 	if err != nil {
 		logger.Error("Failed to create broker services: %v", "error", err)
 		os.Exit(1)
@@ -85,7 +87,7 @@ func main() {
 		Side:      "Buy",
 		Size:      1000,     // Small test size (1,000 units)
 		OrderType: "Market", // Market order for immediate execution
-		Duration:  "DayOrder",
+		Duration:  saxo.Duration(saxo.DurationDayOrder),
 	}
 
 	logger.Info("Order Details:")